@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/crypto-bank/analytics-service/internal/aml"
+	"github.com/crypto-bank/analytics-service/internal/service"
+	"github.com/crypto-bank/analytics-service/pkg/logger"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/replay"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// newHandler decodes bank events and applies them to the statistics and, for
+// live events, to AML monitoring. It serves the live and the replay queue.
+func newHandler(analyticsService *service.AnalyticsService, amlEngine *aml.Engine, rebuild *replay.Rebuild) broker.Handler {
+	return func(ctx context.Context, msg amqp.Delivery) error {
+		replayed := replay.IsReplay(msg)
+		span := trace.SpanFromContext(ctx)
+		logger.Debug("Received message",
+			zap.String("routing_key", msg.RoutingKey),
+			zap.Bool("replayed", replayed),
+			zap.String("trace_id", span.SpanContext().TraceID().String()),
+		)
+
+		envelope, err := events.FromAMQP(msg)
+		if err != nil {
+			return broker.Permanent(err)
+		}
+		span.SetAttributes(
+			attribute.String("cloudevents.event_id", envelope.ID),
+			attribute.String("cloudevents.event_type", envelope.Type),
+			attribute.String("cloudevents.event_subject", envelope.Subject),
+		)
+		event, err := envelope.Event()
+		if errors.Is(err, events.ErrUnknownType) {
+			logger.Warn("Unknown event type", zap.String("type", envelope.Type))
+			return nil
+		}
+		if err != nil {
+			return broker.Permanent(err)
+		}
+
+		// AML monitoring only sees live events: its alerts outlive a rebuild
+		// and replaying would raise them again
+		if !replayed {
+			switch event := event.(type) {
+			case *events.TransactionEvent:
+				amlEngine.ProcessTransactionEvent(event)
+			case *events.ExchangeEvent:
+				amlEngine.ProcessExchangeEvent(event)
+			}
+		}
+
+		applied := rebuild.Apply(envelope.ID, replayed, func() {
+			switch event := event.(type) {
+			case *events.UserEvent:
+				analyticsService.ProcessUserEvent(envelope.Type, event)
+			case *events.TransactionEvent:
+				analyticsService.ProcessTransactionEvent(event)
+			case *events.ExchangeEvent:
+				analyticsService.ProcessExchangeEvent(event)
+			case *events.AccountEvent:
+				analyticsService.ProcessAccountEvent(event)
+			case *events.WalletEvent:
+				analyticsService.ProcessWalletEvent(event)
+			case *events.AccountBalanceEvent:
+				analyticsService.ProcessAccountBalanceEvent(event)
+			case *events.WalletBalanceEvent:
+				analyticsService.ProcessWalletBalanceEvent(event)
+			default:
+				logger.Debug("Ignoring event", zap.String("type", envelope.Type))
+			}
+		})
+		if !applied {
+			logger.Debug("Skipping event already in the statistics",
+				zap.String("event_id", envelope.ID),
+				zap.Bool("replayed", replayed),
+			)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-bank/analytics-service/internal/aml"
+	"github.com/crypto-bank/analytics-service/internal/service"
+	"github.com/crypto-bank/analytics-service/pkg/logger"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/events/eventstest"
+	"github.com/crypto-bank/shared/replay"
+	"go.uber.org/zap"
+)
+
+// counted is the statistic each event type moves, and by how much
+var counted = map[string]struct {
+	count func(*service.Statistics) int64
+	delta int64
+}{
+	events.UserCreated:          {func(s *service.Statistics) int64 { return s.TotalUsers }, 1},
+	events.UserDeleted:          {func(s *service.Statistics) int64 { return s.TotalUsers }, -1},
+	events.TransactionCompleted: {func(s *service.Statistics) int64 { return s.TotalTransactions }, 1},
+	events.TransactionFailed:    {func(s *service.Statistics) int64 { return s.FailedTransactions }, 1},
+	events.ExchangeCompleted:    {func(s *service.Statistics) int64 { return s.TotalExchanges }, 1},
+	events.ExchangeFailed:       {func(s *service.Statistics) int64 { return s.FailedExchanges }, 1},
+	events.AccountCreated:       {func(s *service.Statistics) int64 { return s.TotalAccounts }, 1},
+	events.WalletCreated:        {func(s *service.Statistics) int64 { return s.TotalWallets }, 1},
+}
+
+func TestHandlerDecodesGoldenEvents(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	for name, msg := range eventstest.Deliveries(t, consumedEvents...) {
+		t.Run(name, func(t *testing.T) {
+			analyticsService := service.NewAnalyticsService(logger.Log)
+			store, err := aml.NewCaseStore("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			amlEngine := aml.NewEngine(aml.DefaultRulesConfig(), store, logger.Log)
+			rebuild := replay.NewRebuild("analytics", analyticsService.Reset)
+			handle := newHandler(analyticsService, amlEngine, rebuild)
+
+			if err := handle(context.Background(), msg); err != nil {
+				t.Fatalf("handler rejected the golden payload: %v", err)
+			}
+
+			want, ok := counted[msg.RoutingKey]
+			if !ok {
+				return
+			}
+			if got := want.count(analyticsService.GetStatistics()); got != want.delta {
+				t.Errorf("statistic = %d, want %d", got, want.delta)
+			}
+		})
+	}
+}
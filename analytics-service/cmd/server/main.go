@@ -5,17 +5,120 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/crypto-bank/analytics-service/internal/aml"
 	"github.com/crypto-bank/analytics-service/internal/config"
+	"github.com/crypto-bank/analytics-service/internal/handlers"
 	"github.com/crypto-bank/analytics-service/internal/service"
 	"github.com/crypto-bank/analytics-service/pkg/logger"
 	"github.com/crypto-bank/analytics-service/pkg/metrics"
 	"github.com/crypto-bank/analytics-service/pkg/tracing"
+	"github.com/crypto-bank/shared/authz"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/dedup"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/replay"
 	"github.com/gofiber/fiber/v2"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+const (
+	queueName          = "analytics.queue"
+	replayQueueName    = "analytics.replay"
+	deadLetterExchange = "analytics.dlx"
+	deadLetterQueue    = "analytics.dlq"
+	replayDeadLetters  = "analytics.replay.dlq"
+)
+
+// routingKeys cover every bank event the service aggregates
+var routingKeys = []string{
+	"user.#",
+	"transaction.#",
+	"exchange.#",
+	"account.#",
+	"wallet.#",
+}
+
+// consumedEvents are the event types the service decodes
+var consumedEvents = []string{
+	events.UserCreated,
+	events.UserUpdated,
+	events.UserDeleted,
+	events.TransactionCreated,
+	events.TransactionCompleted,
+	events.TransactionFailed,
+	events.ExchangeCreated,
+	events.ExchangeCompleted,
+	events.ExchangeFailed,
+	events.AccountCreated,
+	events.AccountBalanceChanged,
+	events.WalletCreated,
+	events.WalletBalanceChanged,
+}
+
+// declareTopology declares the work queue bound to bank.events and the replay
+// queue bound to bank.events.replay, each with its retry and dead-letter
+// queues. The broker client runs it again after every reconnect.
+func declareTopology(ch *amqp.Channel, consumers ...broker.ConsumerConfig) error {
+	sources := map[string]string{
+		queueName:       "bank.events",
+		replayQueueName: replay.Exchange,
+	}
+
+	for _, consumer := range consumers {
+		exchange := sources[consumer.Queue]
+
+		// Declare exchange
+		err := ch.ExchangeDeclare(
+			exchange, // name
+			"topic",  // type
+			true,     // durable
+			false,    // auto-deleted
+			false,    // internal
+			false,    // no-wait
+			nil,      // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+
+		// Declare queue
+		q, err := ch.QueueDeclare(
+			consumer.Queue, // name
+			true,           // durable
+			false,          // delete when unused
+			false,          // exclusive
+			false,          // no-wait
+			nil,            // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", consumer.Queue, err)
+		}
+
+		// Bind queue to exchange with routing keys
+		for _, key := range routingKeys {
+			err = ch.QueueBind(
+				q.Name,   // queue name
+				key,      // routing key
+				exchange, // exchange
+				false,
+				nil,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to bind queue %s to %s: %w", q.Name, key, err)
+			}
+		}
+
+		if err := broker.DeclareConsumerTopology(ch, consumer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
@@ -41,98 +144,89 @@ func main() {
 	// Initialize analytics service
 	analyticsService := service.NewAnalyticsService(logger.Log)
 
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(cfg.RabbitMQ.GetRabbitMQURL())
+	// Initialize AML transaction monitoring
+	rulesConfig, err := aml.LoadRulesConfig(cfg.AML.RulesFile)
 	if err != nil {
-		logger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
+		logger.Fatal("Failed to load AML rules", zap.Error(err))
 	}
-	defer conn.Close()
 
-	ch, err := conn.Channel()
-	if err != nil {
-		logger.Fatal("Failed to open channel", zap.Error(err))
-	}
-	defer ch.Close()
-
-	// Declare exchange
-	err = ch.ExchangeDeclare(
-		"bank.events", // name
-		"topic",       // type
-		true,          // durable
-		false,         // auto-deleted
-		false,         // internal
-		false,         // no-wait
-		nil,           // arguments
-	)
+	caseStore, err := aml.NewCaseStore(cfg.AML.CaseStoreFile)
 	if err != nil {
-		logger.Fatal("Failed to declare exchange", zap.Error(err))
+		logger.Fatal("Failed to open AML case store", zap.Error(err))
 	}
 
-	// Declare queue
-	q, err := ch.QueueDeclare(
-		"analytics.queue", // name
-		true,              // durable
-		false,             // delete when unused
-		false,             // exclusive
-		false,             // no-wait
-		nil,               // arguments
-	)
-	if err != nil {
-		logger.Fatal("Failed to declare queue", zap.Error(err))
-	}
+	amlEngine := aml.NewEngine(rulesConfig, caseStore, logger.Log)
+	alertHandler := handlers.NewAlertHandler(caseStore)
+
+	// Load the bank-service token verification keys for the operator endpoints
+	keySet := authz.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.Timeout)
+	verifier := authz.NewVerifier(keySet, cfg.Auth.Issuer)
+	stopKeyRefresh := make(chan struct{})
+	go keySet.Watch(cfg.Auth.RefreshInterval, stopKeyRefresh, logger.Log)
+	defer close(stopKeyRefresh)
 
-	// Bind queue to exchange with routing keys
-	routingKeys := []string{
-		"transaction.#",
-		"exchange.#",
-		"account.#",
-		"wallet.#",
+	consumer := broker.ConsumerConfig{
+		Queue:              queueName,
+		DeadLetterExchange: deadLetterExchange,
+		DeadLetterQueue:    deadLetterQueue,
+		RetryDelays:        cfg.Consumer.RetryDelays,
+		Prefetch:           cfg.Consumer.Prefetch,
+		Concurrency:        cfg.Consumer.Concurrency,
 	}
+	// Replayed events that keep failing are parked apart from live ones;
+	// replaying them again from the event store takes the place of a redrive
+	replayConsumer := consumer
+	replayConsumer.Queue = replayQueueName
+	replayConsumer.DeadLetterQueue = replayDeadLetters
 
-	for _, key := range routingKeys {
-		err = ch.QueueBind(
-			q.Name,        // queue name
-			key,           // routing key
-			"bank.events", // exchange
-			false,
-			nil,
-		)
-		if err != nil {
-			logger.Fatal("Failed to bind queue", zap.Error(err), zap.String("routing_key", key))
-		}
-		logger.Info("Queue bound", zap.String("routing_key", key))
+	// The statistics are rebuilt from replayed events on demand
+	rebuild := replay.NewRebuild("analytics", analyticsService.Reset)
+
+	// Remember processed events to drop redeliveries
+	processedEvents, err := dedup.Open(dedup.Config{
+		Path:       cfg.Consumer.DedupFile,
+		TTL:        cfg.Consumer.DedupTTL,
+		MaxEntries: cfg.Consumer.DedupMaxEntries,
+	}, logger.Log)
+	if err != nil {
+		logger.Fatal("Failed to open dedup store", zap.Error(err))
 	}
+	defer processedEvents.Close()
 
-	// Start consuming messages
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
+	// Connect to RabbitMQ
+	rabbitMQClient, err := broker.Dial(broker.Config{
+		URL:  cfg.RabbitMQ.GetRabbitMQURL(),
+		Name: "analytics-service",
+		Topology: func(ch *amqp.Channel) error {
+			return declareTopology(ch, consumer, replayConsumer)
+		},
+		ReconnectMinDelay: cfg.RabbitMQ.ReconnectMinDelay,
+		ReconnectMaxDelay: cfg.RabbitMQ.ReconnectMaxDelay,
+	}, logger.Log)
 	if err != nil {
-		logger.Fatal("Failed to register consumer", zap.Error(err))
+		logger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
 	}
+	defer rabbitMQClient.Close()
 
-	// Process messages in goroutine
+	// Start consuming messages
+	handle := newHandler(analyticsService, amlEngine, rebuild)
+	// Replayed events are deduplicated by the rebuild instead: they carry the
+	// IDs of events processed before
+	rabbitMQClient.Consume(consumer, dedup.Wrap(queueName, processedEvents, handle))
+	rabbitMQClient.Consume(replayConsumer, handle)
+
+	// Forget users whose activity fell out of the AML lookback
+	stopAMLPrune := make(chan struct{})
+	defer close(stopAMLPrune)
 	go func() {
-		for msg := range msgs {
-			logger.Debug("Received message", zap.String("routing_key", msg.RoutingKey))
-
-			switch msg.RoutingKey {
-			case "transaction.created", "transaction.completed":
-				analyticsService.ProcessTransactionEvent(msg.Body)
-			case "exchange.created", "exchange.completed":
-				analyticsService.ProcessExchangeEvent(msg.Body)
-			case "account.created":
-				analyticsService.ProcessAccountEvent(msg.Body)
-			case "wallet.created":
-				analyticsService.ProcessWalletEvent(msg.Body)
-			default:
-				logger.Warn("Unknown routing key", zap.String("routing_key", msg.RoutingKey))
+		ticker := time.NewTicker(cfg.AML.PruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				amlEngine.Prune()
+			case <-stopAMLPrune:
+				return
 			}
 		}
 	}()
@@ -159,6 +253,25 @@ func main() {
 		})
 	})
 
+	// Fraud alerts are worked by the compliance team
+	alerts := app.Group("/api/v1/alerts", authz.RequireRole(verifier, logger.Log, authz.RoleCompliance, authz.RoleAdmin))
+	alerts.Get("/", alertHandler.GetAlerts)
+	alerts.Get("/:id", alertHandler.GetAlert)
+	alerts.Patch("/:id", alertHandler.UpdateAlert)
+
+	requireAdmin := authz.RequireRole(verifier, logger.Log, authz.RoleAdmin)
+
+	deadLetterHandler := handlers.NewDeadLetterHandler(rabbitMQClient, consumer)
+	deadLetters := app.Group("/admin/v1/dead-letters", requireAdmin)
+	deadLetters.Get("/", deadLetterHandler.GetDeadLetters)
+	deadLetters.Post("/redrive", deadLetterHandler.Redrive)
+
+	rebuildHandler := handlers.NewRebuildHandler(rebuild)
+	rebuilds := app.Group("/admin/v1/rebuild", requireAdmin)
+	rebuilds.Get("/", rebuildHandler.GetRebuild)
+	rebuilds.Post("/", rebuildHandler.StartRebuild)
+	rebuilds.Post("/finish", rebuildHandler.FinishRebuild)
+
 	// Start HTTP server
 	go func() {
 		logger.Info("HTTP server started", zap.String("port", cfg.Server.Port))
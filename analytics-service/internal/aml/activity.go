@@ -0,0 +1,91 @@
+package aml
+
+import (
+	"sort"
+	"time"
+)
+
+// ActivityKind classifies a money movement for the rules
+type ActivityKind string
+
+const (
+	ActivityDeposit    ActivityKind = "deposit"
+	ActivityWithdrawal ActivityKind = "withdrawal"
+	ActivityTransfer   ActivityKind = "transfer"
+	ActivityExchange   ActivityKind = "exchange"
+)
+
+// Activity is a completed money movement of a user. For exchanges Currency and
+// Amount are the sold side and ToCurrency and ToAmount the bought side.
+type Activity struct {
+	Reference  string       `json:"reference"`
+	UserID     string       `json:"user_id"`
+	Kind       ActivityKind `json:"kind"`
+	Currency   string       `json:"currency"`
+	Amount     float64      `json:"amount"`
+	ToCurrency string       `json:"to_currency,omitempty"`
+	ToAmount   float64      `json:"to_amount,omitempty"`
+	USDValue   float64      `json:"usd_value"`
+	At         time.Time    `json:"at"`
+}
+
+// Inflow reports whether the activity brings money in from outside the bank
+func (a *Activity) Inflow() bool {
+	return a.Kind == ActivityDeposit
+}
+
+// Outflow reports whether the activity takes money away from the user
+func (a *Activity) Outflow() bool {
+	return a.Kind == ActivityWithdrawal || a.Kind == ActivityTransfer
+}
+
+// history is the activity of one user over the longest lookback of the rules,
+// oldest first. firstSeen outlives pruning so that baselines know how long the
+// user has been active.
+type history struct {
+	activities []*Activity
+	firstSeen  time.Time
+}
+
+func (h *history) add(activity *Activity) {
+	if h.firstSeen.IsZero() || activity.At.Before(h.firstSeen) {
+		h.firstSeen = activity.At
+	}
+	h.activities = append(h.activities, activity)
+	// Events usually arrive in order; keep the slice sorted when they do not
+	if n := len(h.activities); n > 1 && h.activities[n-2].At.After(activity.At) {
+		sort.SliceStable(h.activities, func(i, j int) bool {
+			return h.activities[i].At.Before(h.activities[j].At)
+		})
+	}
+}
+
+// prune drops the activities older than cutoff
+func (h *history) prune(cutoff time.Time) {
+	i := sort.Search(len(h.activities), func(i int) bool {
+		return !h.activities[i].At.Before(cutoff)
+	})
+	if i > 0 {
+		h.activities = append(h.activities[:0], h.activities[i:]...)
+	}
+}
+
+// since returns the activities at or after from, oldest first
+func (h *history) since(from time.Time) []*Activity {
+	i := sort.Search(len(h.activities), func(i int) bool {
+		return !h.activities[i].At.Before(from)
+	})
+	return h.activities[i:]
+}
+
+// between returns the activities in [from, to), oldest first
+func (h *history) between(from, to time.Time) []*Activity {
+	var result []*Activity
+	for _, activity := range h.since(from) {
+		if !activity.At.Before(to) {
+			break
+		}
+		result = append(result, activity)
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package aml
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Severity ranks how urgently an alert needs an analyst
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Valid reports whether the severity is one of the known levels
+func (s Severity) Valid() bool {
+	switch s {
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// Duration is a time.Duration written as "24h" in the rules file
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"24h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// RulesConfig configures the AML rules. Amounts are in USD; movements in other
+// currencies are valued with ReferenceRates, which the engine keeps current from
+// exchanges against USD.
+type RulesConfig struct {
+	FiatCurrencies []string           `json:"fiat_currencies"`
+	ReferenceRates map[string]float64 `json:"reference_rates"`
+	Structuring    StructuringConfig  `json:"structuring"`
+	RapidInOut     RapidInOutConfig   `json:"rapid_in_out"`
+	RoundTrip      RoundTripConfig    `json:"round_trip"`
+	VolumeSpike    VolumeSpikeConfig  `json:"volume_spike"`
+}
+
+// StructuringConfig flags repeated movements kept just under a reporting
+// threshold: MinCount movements in Window each within Margin below ThresholdUSD
+type StructuringConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Severity     Severity `json:"severity"`
+	Window       Duration `json:"window"`
+	ThresholdUSD float64  `json:"threshold_usd"`
+	Margin       float64  `json:"margin"`
+	MinCount     int      `json:"min_count"`
+}
+
+// RapidInOutConfig flags deposits that leave again within Window: at least
+// MinInflowUSD deposited and OutflowRatio of it withdrawn or transferred away
+type RapidInOutConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Severity     Severity `json:"severity"`
+	Window       Duration `json:"window"`
+	MinInflowUSD float64  `json:"min_inflow_usd"`
+	OutflowRatio float64  `json:"outflow_ratio"`
+}
+
+// RoundTripConfig flags fiat exchanged into crypto and back into the same fiat
+// within Window, getting at least ReturnRatio of the amount back
+type RoundTripConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Severity     Severity `json:"severity"`
+	Window       Duration `json:"window"`
+	MinAmountUSD float64  `json:"min_amount_usd"`
+	ReturnRatio  float64  `json:"return_ratio"`
+}
+
+// VolumeSpikeConfig flags a user moving more than Multiplier times their usual
+// volume within Window. The usual volume is the average per Window over the
+// Baseline before it; users seen for less than MinHistory have no baseline yet.
+type VolumeSpikeConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Severity     Severity `json:"severity"`
+	Window       Duration `json:"window"`
+	Baseline     Duration `json:"baseline"`
+	MinHistory   Duration `json:"min_history"`
+	Multiplier   float64  `json:"multiplier"`
+	MinVolumeUSD float64  `json:"min_volume_usd"`
+}
+
+// DefaultRulesConfig returns the rules used when no rules file is configured.
+// The reference rates match the seeded asset registry of the bank.
+func DefaultRulesConfig() *RulesConfig {
+	return &RulesConfig{
+		FiatCurrencies: []string{"USD", "EUR", "RUB", "GBP"},
+		ReferenceRates: map[string]float64{
+			"USD":  1,
+			"EUR":  1.09,
+			"RUB":  0.0108,
+			"GBP":  1.27,
+			"BTC":  43500,
+			"ETH":  2280.50,
+			"USDT": 1,
+			"BNB":  315.75,
+			"SOL":  98.30,
+		},
+		Structuring: StructuringConfig{
+			Enabled:      true,
+			Severity:     SeverityHigh,
+			Window:       Duration{24 * time.Hour},
+			ThresholdUSD: 10000,
+			Margin:       0.1,
+			MinCount:     3,
+		},
+		RapidInOut: RapidInOutConfig{
+			Enabled:      true,
+			Severity:     SeverityMedium,
+			Window:       Duration{48 * time.Hour},
+			MinInflowUSD: 5000,
+			OutflowRatio: 0.8,
+		},
+		RoundTrip: RoundTripConfig{
+			Enabled:      true,
+			Severity:     SeverityMedium,
+			Window:       Duration{72 * time.Hour},
+			MinAmountUSD: 1000,
+			ReturnRatio:  0.9,
+		},
+		VolumeSpike: VolumeSpikeConfig{
+			Enabled:      true,
+			Severity:     SeverityLow,
+			Window:       Duration{24 * time.Hour},
+			Baseline:     Duration{30 * 24 * time.Hour},
+			MinHistory:   Duration{7 * 24 * time.Hour},
+			Multiplier:   5,
+			MinVolumeUSD: 10000,
+		},
+	}
+}
+
+// LoadRulesConfig reads the rules file at path over the defaults, so the file
+// only needs the settings it changes. An empty path returns the defaults.
+func LoadRulesConfig(path string) (*RulesConfig, error) {
+	config := DefaultRulesConfig()
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AML rules file: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse AML rules file: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AML rules file: %w", err)
+	}
+
+	return config, nil
+}
+
+// Validate checks that the rule settings make sense
+func (c *RulesConfig) Validate() error {
+	for currency, rate := range c.ReferenceRates {
+		if rate <= 0 {
+			return fmt.Errorf("reference rate of %s must be positive", currency)
+		}
+	}
+
+	checks := []struct {
+		rule     string
+		severity Severity
+		window   time.Duration
+	}{
+		{"structuring", c.Structuring.Severity, c.Structuring.Window.Duration},
+		{"rapid_in_out", c.RapidInOut.Severity, c.RapidInOut.Window.Duration},
+		{"round_trip", c.RoundTrip.Severity, c.RoundTrip.Window.Duration},
+		{"volume_spike", c.VolumeSpike.Severity, c.VolumeSpike.Window.Duration},
+	}
+	for _, check := range checks {
+		if !check.severity.Valid() {
+			return fmt.Errorf("%s: unknown severity %q", check.rule, check.severity)
+		}
+		if check.window <= 0 {
+			return fmt.Errorf("%s: window must be positive", check.rule)
+		}
+	}
+
+	if c.Structuring.ThresholdUSD <= 0 || c.Structuring.Margin <= 0 || c.Structuring.Margin >= 1 {
+		return fmt.Errorf("structuring: threshold must be positive and margin between 0 and 1")
+	}
+	if c.Structuring.MinCount < 2 {
+		return fmt.Errorf("structuring: min_count must be at least 2")
+	}
+	if c.RapidInOut.OutflowRatio <= 0 || c.RapidInOut.OutflowRatio > 1 {
+		return fmt.Errorf("rapid_in_out: outflow_ratio must be between 0 and 1")
+	}
+	if c.RoundTrip.ReturnRatio <= 0 || c.RoundTrip.ReturnRatio > 1 {
+		return fmt.Errorf("round_trip: return_ratio must be between 0 and 1")
+	}
+	if c.VolumeSpike.Multiplier <= 1 {
+		return fmt.Errorf("volume_spike: multiplier must be greater than 1")
+	}
+	if c.VolumeSpike.Baseline.Duration <= c.VolumeSpike.Window.Duration {
+		return fmt.Errorf("volume_spike: baseline must be longer than the window")
+	}
+
+	return nil
+}
+
+func (c *RulesConfig) fiatSet() map[string]bool {
+	fiat := make(map[string]bool, len(c.FiatCurrencies))
+	for _, currency := range c.FiatCurrencies {
+		fiat[strings.ToUpper(currency)] = true
+	}
+	return fiat
+}
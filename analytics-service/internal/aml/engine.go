@@ -0,0 +1,230 @@
+package aml
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crypto-bank/shared/events"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	// AML metrics
+	AlertsRaised = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_aml_alerts_raised_total",
+			Help: "Total number of AML alerts raised",
+		},
+		[]string{"rule", "severity"},
+	)
+
+	ActivitiesEvaluated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_aml_activities_evaluated_total",
+			Help: "Total number of money movements evaluated by the AML rules",
+		},
+		[]string{"kind"},
+	)
+
+	MonitoredUsers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "analytics_aml_monitored_users",
+			Help: "Number of users with activity inside the AML lookback",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(AlertsRaised)
+	prometheus.MustRegister(ActivitiesEvaluated)
+	prometheus.MustRegister(MonitoredUsers)
+}
+
+// Engine evaluates the AML rules over a sliding window of the activity of each
+// user and raises alerts into the case store
+type Engine struct {
+	logger    *zap.Logger
+	store     *CaseStore
+	rules     []Rule
+	lookback  time.Duration
+	rates     map[string]float64
+	histories map[string]*history
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+func NewEngine(config *RulesConfig, store *CaseStore, logger *zap.Logger) *Engine {
+	rules := NewRules(config)
+
+	var lookback time.Duration
+	for _, rule := range rules {
+		if rule.Lookback() > lookback {
+			lookback = rule.Lookback()
+		}
+	}
+
+	rates := make(map[string]float64, len(config.ReferenceRates))
+	for currency, rate := range config.ReferenceRates {
+		rates[strings.ToUpper(currency)] = rate
+	}
+
+	return &Engine{
+		logger:    logger,
+		store:     store,
+		rules:     rules,
+		lookback:  lookback,
+		rates:     rates,
+		histories: make(map[string]*history),
+		now:       time.Now,
+	}
+}
+
+// ProcessTransactionEvent evaluates a completed deposit, withdrawal or transfer
+func (e *Engine) ProcessTransactionEvent(event *events.TransactionEvent) {
+	if event.Status != "COMPLETED" {
+		return
+	}
+
+	var kind ActivityKind
+	switch event.Type {
+	case "DEPOSIT":
+		kind = ActivityDeposit
+	case "WITHDRAW":
+		kind = ActivityWithdrawal
+	case "TRANSFER":
+		kind = ActivityTransfer
+	default:
+		return
+	}
+
+	e.Observe(&Activity{
+		Reference: event.TransactionID,
+		UserID:    event.UserID,
+		Kind:      kind,
+		Currency:  strings.ToUpper(event.Currency),
+		Amount:    event.Amount,
+		At:        e.now().UTC(),
+	})
+}
+
+// ProcessExchangeEvent evaluates a completed currency exchange
+func (e *Engine) ProcessExchangeEvent(event *events.ExchangeEvent) {
+	if event.Status != "COMPLETED" {
+		return
+	}
+
+	e.Observe(&Activity{
+		Reference:  event.ExchangeID,
+		UserID:     event.UserID,
+		Kind:       ActivityExchange,
+		Currency:   strings.ToUpper(event.FromCurrency),
+		Amount:     event.FromAmount,
+		ToCurrency: strings.ToUpper(event.ToCurrency),
+		ToAmount:   event.ToAmount,
+		At:         e.now().UTC(),
+	})
+}
+
+// Observe adds an activity to the history of its user, runs the rules and
+// returns the alerts raised
+func (e *Engine) Observe(activity *Activity) []*Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.updateRates(activity)
+	activity.USDValue = e.usdValue(activity.Currency, activity.Amount)
+
+	h, ok := e.histories[activity.UserID]
+	if !ok {
+		h = &history{}
+		e.histories[activity.UserID] = h
+		MonitoredUsers.Set(float64(len(e.histories)))
+	}
+	h.add(activity)
+	h.prune(activity.At.Add(-e.lookback))
+
+	ActivitiesEvaluated.WithLabelValues(string(activity.Kind)).Inc()
+
+	var raised []*Alert
+	for _, rule := range e.rules {
+		finding := rule.Evaluate(h, activity)
+		if finding == nil {
+			continue
+		}
+		if e.store.HasUnresolved(activity.UserID, rule.Name(), e.now().Add(-rule.Window())) {
+			continue
+		}
+
+		// Copy the evidence: the history reuses its backing array when pruning
+		evidence := make([]Activity, len(finding.Evidence))
+		for i, item := range finding.Evidence {
+			evidence[i] = *item
+		}
+
+		alert := &Alert{
+			UserID:   activity.UserID,
+			Rule:     rule.Name(),
+			Severity: rule.Severity(),
+			Summary:  finding.Summary,
+			Evidence: evidence,
+		}
+		if err := e.store.Create(alert); err != nil {
+			e.logger.Error("Failed to store AML alert", zap.String("rule", rule.Name()), zap.Error(err))
+			continue
+		}
+
+		AlertsRaised.WithLabelValues(alert.Rule, string(alert.Severity)).Inc()
+		e.logger.Warn("AML alert raised",
+			zap.String("alert_id", alert.ID),
+			zap.String("user_id", alert.UserID),
+			zap.String("rule", alert.Rule),
+			zap.String("severity", string(alert.Severity)),
+			zap.String("summary", alert.Summary),
+		)
+		raised = append(raised, alert)
+	}
+
+	return raised
+}
+
+// Prune forgets the users without activity inside the lookback
+func (e *Engine) Prune() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cutoff := e.now().UTC().Add(-e.lookback)
+	for userID, h := range e.histories {
+		h.prune(cutoff)
+		if len(h.activities) == 0 {
+			delete(e.histories, userID)
+		}
+	}
+	MonitoredUsers.Set(float64(len(e.histories)))
+}
+
+// updateRates takes the rate of a currency from exchanges against USD, so that
+// valuations follow the market instead of the configured reference rates
+func (e *Engine) updateRates(activity *Activity) {
+	if activity.Kind != ActivityExchange || activity.Amount <= 0 || activity.ToAmount <= 0 {
+		return
+	}
+	switch {
+	case activity.Currency == "USD" && activity.ToCurrency != "USD":
+		e.rates[activity.ToCurrency] = activity.Amount / activity.ToAmount
+	case activity.ToCurrency == "USD" && activity.Currency != "USD":
+		e.rates[activity.Currency] = activity.ToAmount / activity.Amount
+	}
+}
+
+// usdValue values an amount in USD. Currencies without a rate are valued at
+// zero, which keeps them out of the amount based rules.
+func (e *Engine) usdValue(currency string, amount float64) float64 {
+	rate, ok := e.rates[currency]
+	if !ok {
+		e.logger.Warn("No reference rate for currency, not valued", zap.String("currency", currency))
+		return 0
+	}
+	return amount * rate
+}
@@ -0,0 +1,338 @@
+package aml
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/shared/events"
+	"go.uber.org/zap"
+)
+
+const testUser = "7b1d6f0e-4c1a-4f57-9f0e-2d8c5b7a9e31"
+
+var day = 24 * time.Hour
+
+// start is far enough back that the longest lookback fits before now
+var start = time.Now().UTC().Add(-90 * day)
+
+func move(kind ActivityKind, currency string, amount float64, at time.Duration) *Activity {
+	return &Activity{UserID: testUser, Kind: kind, Currency: currency, Amount: amount, At: start.Add(at)}
+}
+
+func exchange(from string, amount float64, to string, toAmount float64, at time.Duration) *Activity {
+	return &Activity{
+		UserID:     testUser,
+		Kind:       ActivityExchange,
+		Currency:   from,
+		Amount:     amount,
+		ToCurrency: to,
+		ToAmount:   toAmount,
+		At:         start.Add(at),
+	}
+}
+
+// daily returns one movement a day from day 0 up to but excluding day n
+func daily(kind ActivityKind, amount float64, n int) []*Activity {
+	activities := make([]*Activity, n)
+	for i := range activities {
+		activities[i] = move(kind, "USD", amount, time.Duration(i)*day)
+	}
+	return activities
+}
+
+// newTestEngine returns an engine running only the named rule with the
+// default settings, changed by configure when given
+func newTestEngine(t *testing.T, rule string, configure func(*RulesConfig)) (*Engine, *CaseStore) {
+	t.Helper()
+
+	config := DefaultRulesConfig()
+	config.Structuring.Enabled = rule == "structuring"
+	config.RapidInOut.Enabled = rule == "rapid_in_out"
+	config.RoundTrip.Enabled = rule == "round_trip"
+	config.VolumeSpike.Enabled = rule == "volume_spike"
+	if configure != nil {
+		configure(config)
+	}
+
+	store, err := NewCaseStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEngine(config, store, zap.NewNop()), store
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       string
+		configure  func(*RulesConfig)
+		activities []*Activity
+		// raised lists the rule of each alert over the whole sequence
+		raised []string
+	}{
+		{
+			name: "structuring fires on three deposits just under the threshold",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 9500, 0),
+				move(ActivityDeposit, "USD", 9200, 3*time.Hour),
+				move(ActivityDeposit, "USD", 9900, 6*time.Hour),
+			},
+			raised: []string{"structuring"},
+		},
+		{
+			name: "structuring counts outflows and values other currencies",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityWithdrawal, "USD", 9500, 0),
+				move(ActivityTransfer, "EUR", 8500, time.Hour),
+				move(ActivityDeposit, "USD", 9500, 2*time.Hour),
+			},
+			raised: []string{"structuring"},
+		},
+		{
+			name: "structuring needs the minimum count",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 9500, 0),
+				move(ActivityDeposit, "USD", 9500, time.Hour),
+			},
+		},
+		{
+			name: "structuring ignores amounts at the threshold",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 10000, 0),
+				move(ActivityDeposit, "USD", 10000, time.Hour),
+				move(ActivityDeposit, "USD", 10000, 2*time.Hour),
+			},
+		},
+		{
+			name: "structuring ignores amounts below the margin",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 8999, 0),
+				move(ActivityDeposit, "USD", 8999, time.Hour),
+				move(ActivityDeposit, "USD", 8999, 2*time.Hour),
+			},
+		},
+		{
+			name: "structuring only counts inside the window",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 9500, 0),
+				move(ActivityDeposit, "USD", 9500, 12*time.Hour),
+				move(ActivityDeposit, "USD", 9500, 25*time.Hour),
+			},
+		},
+		{
+			name: "structuring does not value currencies without a rate",
+			rule: "structuring",
+			activities: []*Activity{
+				move(ActivityDeposit, "XYZ", 9500, 0),
+				move(ActivityDeposit, "XYZ", 9500, time.Hour),
+				move(ActivityDeposit, "XYZ", 9500, 2*time.Hour),
+			},
+		},
+		{
+			name: "structuring follows a configured threshold",
+			rule: "structuring",
+			configure: func(c *RulesConfig) {
+				c.Structuring.ThresholdUSD = 5000
+			},
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 4800, 0),
+				move(ActivityDeposit, "USD", 4600, time.Hour),
+				move(ActivityDeposit, "USD", 4900, 2*time.Hour),
+			},
+			raised: []string{"structuring"},
+		},
+		{
+			name: "rapid in out fires when most of a deposit leaves",
+			rule: "rapid_in_out",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 6000, 0),
+				move(ActivityWithdrawal, "USD", 3000, time.Hour),
+				move(ActivityTransfer, "USD", 1800, 2*time.Hour),
+			},
+			raised: []string{"rapid_in_out"},
+		},
+		{
+			name: "rapid in out ignores outflow under the ratio",
+			rule: "rapid_in_out",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 6000, 0),
+				move(ActivityWithdrawal, "USD", 4000, time.Hour),
+			},
+		},
+		{
+			name: "rapid in out ignores inflow under the minimum",
+			rule: "rapid_in_out",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 4000, 0),
+				move(ActivityWithdrawal, "USD", 4000, time.Hour),
+			},
+		},
+		{
+			name: "rapid in out ignores outflow before the deposit",
+			rule: "rapid_in_out",
+			activities: []*Activity{
+				move(ActivityWithdrawal, "USD", 5000, 0),
+				move(ActivityDeposit, "USD", 6000, time.Hour),
+				move(ActivityTransfer, "USD", 1000, 2*time.Hour),
+			},
+		},
+		{
+			name: "rapid in out only counts inside the window",
+			rule: "rapid_in_out",
+			activities: []*Activity{
+				move(ActivityDeposit, "USD", 6000, 0),
+				move(ActivityWithdrawal, "USD", 6000, 49*time.Hour),
+			},
+		},
+		{
+			name: "round trip fires on fiat coming back through other crypto",
+			rule: "round_trip",
+			activities: []*Activity{
+				exchange("USD", 5000, "BTC", 0.115, 0),
+				exchange("BTC", 0.115, "ETH", 2.19, time.Hour),
+				exchange("ETH", 2.19, "USD", 4600, 2*time.Hour),
+			},
+			raised: []string{"round_trip"},
+		},
+		{
+			name: "round trip ignores a return under the ratio",
+			rule: "round_trip",
+			activities: []*Activity{
+				exchange("USD", 5000, "BTC", 0.115, 0),
+				exchange("BTC", 0.115, "USD", 4000, time.Hour),
+			},
+		},
+		{
+			name: "round trip ignores amounts under the minimum",
+			rule: "round_trip",
+			activities: []*Activity{
+				exchange("USD", 500, "BTC", 0.0115, 0),
+				exchange("BTC", 0.0115, "USD", 500, time.Hour),
+			},
+		},
+		{
+			name: "round trip ignores a return into other fiat",
+			rule: "round_trip",
+			activities: []*Activity{
+				exchange("USD", 5000, "BTC", 0.115, 0),
+				exchange("BTC", 0.115, "EUR", 4600, time.Hour),
+			},
+		},
+		{
+			name: "round trip only counts inside the window",
+			rule: "round_trip",
+			activities: []*Activity{
+				exchange("USD", 5000, "BTC", 0.115, 0),
+				exchange("BTC", 0.115, "USD", 5000, 73*time.Hour),
+			},
+		},
+		{
+			name:       "volume spike fires far above the usual volume",
+			rule:       "volume_spike",
+			activities: append(daily(ActivityDeposit, 1000, 10), move(ActivityDeposit, "USD", 20000, 10*day+time.Hour)),
+			raised:     []string{"volume_spike"},
+		},
+		{
+			name:       "volume spike ignores volume within the multiplier",
+			rule:       "volume_spike",
+			activities: append(daily(ActivityDeposit, 3000, 10), move(ActivityDeposit, "USD", 12000, 10*day+time.Hour)),
+		},
+		{
+			name:       "volume spike ignores volume under the minimum",
+			rule:       "volume_spike",
+			activities: append(daily(ActivityDeposit, 100, 10), move(ActivityDeposit, "USD", 5000, 10*day+time.Hour)),
+		},
+		{
+			name:       "volume spike waits for the minimum history",
+			rule:       "volume_spike",
+			activities: append(daily(ActivityDeposit, 1000, 3), move(ActivityDeposit, "USD", 20000, 3*day+time.Hour)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _ := newTestEngine(t, tt.rule, tt.configure)
+
+			var raised []string
+			for _, activity := range tt.activities {
+				for _, alert := range engine.Observe(activity) {
+					raised = append(raised, alert.Rule)
+				}
+			}
+
+			if !reflect.DeepEqual(raised, tt.raised) {
+				t.Errorf("raised %v, want %v", raised, tt.raised)
+			}
+		})
+	}
+}
+
+func TestEngineOpensOneAlertPerCase(t *testing.T) {
+	engine, store := newTestEngine(t, "structuring", nil)
+
+	for i := 0; i < 3; i++ {
+		engine.Observe(move(ActivityDeposit, "USD", 9500, time.Duration(i)*time.Hour))
+	}
+
+	alerts := store.List(AlertFilter{UserID: testUser})
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Rule != "structuring" || alert.Severity != SeverityHigh || alert.Status != AlertOpen {
+		t.Errorf("alert = %s %s %s, want structuring high open", alert.Rule, alert.Severity, alert.Status)
+	}
+	if len(alert.Evidence) != 3 {
+		t.Errorf("got %d pieces of evidence, want 3", len(alert.Evidence))
+	}
+
+	// The open case already covers further movements
+	if raised := engine.Observe(move(ActivityDeposit, "USD", 9500, 3*time.Hour)); len(raised) != 0 {
+		t.Errorf("raised %d alerts while the case is open, want 0", len(raised))
+	}
+
+	if _, err := store.Update(alert.ID, AlertClosed, "analyst", "explained by payroll"); err != nil {
+		t.Fatal(err)
+	}
+	if raised := engine.Observe(move(ActivityDeposit, "USD", 9500, 4*time.Hour)); len(raised) != 1 {
+		t.Errorf("raised %d alerts after the case closed, want 1", len(raised))
+	}
+}
+
+func TestEngineEvaluatesCompletedTransactions(t *testing.T) {
+	tests := []struct {
+		status string
+		alerts int
+	}{
+		{"COMPLETED", 1},
+		{"PENDING", 0},
+		{"FAILED", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			engine, store := newTestEngine(t, "structuring", nil)
+
+			for i := 0; i < 3; i++ {
+				engine.ProcessTransactionEvent(&events.TransactionEvent{
+					UserID:   testUser,
+					Type:     "DEPOSIT",
+					Status:   tt.status,
+					Amount:   9500,
+					Currency: "usd",
+				})
+			}
+
+			if got := len(store.List(AlertFilter{UserID: testUser})); got != tt.alerts {
+				t.Errorf("got %d alerts, want %d", got, tt.alerts)
+			}
+		})
+	}
+}
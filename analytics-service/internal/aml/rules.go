@@ -0,0 +1,217 @@
+package aml
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rule is an AML scenario evaluated over the recent activity of one user each
+// time a new activity of that user arrives
+type Rule interface {
+	Name() string
+	Severity() Severity
+	// Window is how far back the rule looks; an open alert raised by the rule
+	// within the window is not raised again
+	Window() time.Duration
+	// Lookback is how much history the rule needs
+	Lookback() time.Duration
+	// Evaluate returns a finding when latest completes the scenario
+	Evaluate(h *history, latest *Activity) *Finding
+}
+
+// Finding is what a rule saw; the engine turns it into an alert
+type Finding struct {
+	Summary  string
+	Evidence []*Activity
+}
+
+// NewRules builds the enabled rules of the configuration
+func NewRules(config *RulesConfig) []Rule {
+	var rules []Rule
+	if config.Structuring.Enabled {
+		rules = append(rules, &structuringRule{cfg: config.Structuring})
+	}
+	if config.RapidInOut.Enabled {
+		rules = append(rules, &rapidInOutRule{cfg: config.RapidInOut})
+	}
+	if config.RoundTrip.Enabled {
+		rules = append(rules, &roundTripRule{cfg: config.RoundTrip, fiat: config.fiatSet()})
+	}
+	if config.VolumeSpike.Enabled {
+		rules = append(rules, &volumeSpikeRule{cfg: config.VolumeSpike})
+	}
+	return rules
+}
+
+type structuringRule struct {
+	cfg StructuringConfig
+}
+
+func (r *structuringRule) Name() string            { return "structuring" }
+func (r *structuringRule) Severity() Severity      { return r.cfg.Severity }
+func (r *structuringRule) Window() time.Duration   { return r.cfg.Window.Duration }
+func (r *structuringRule) Lookback() time.Duration { return r.cfg.Window.Duration }
+
+func (r *structuringRule) Evaluate(h *history, latest *Activity) *Finding {
+	if !r.justUnder(latest) {
+		return nil
+	}
+
+	var evidence []*Activity
+	var total float64
+	for _, activity := range h.since(latest.At.Add(-r.cfg.Window.Duration)) {
+		if r.justUnder(activity) {
+			evidence = append(evidence, activity)
+			total += activity.USDValue
+		}
+	}
+	if len(evidence) < r.cfg.MinCount {
+		return nil
+	}
+
+	return &Finding{
+		Summary: fmt.Sprintf("%d movements of %.2f to %.2f USD within %s, just under the %.2f USD threshold, totalling %.2f USD",
+			len(evidence), r.floor(), r.cfg.ThresholdUSD, r.cfg.Window.Duration, r.cfg.ThresholdUSD, total),
+		Evidence: evidence,
+	}
+}
+
+func (r *structuringRule) floor() float64 {
+	return r.cfg.ThresholdUSD * (1 - r.cfg.Margin)
+}
+
+func (r *structuringRule) justUnder(activity *Activity) bool {
+	if !activity.Inflow() && !activity.Outflow() {
+		return false
+	}
+	return activity.USDValue >= r.floor() && activity.USDValue < r.cfg.ThresholdUSD
+}
+
+type rapidInOutRule struct {
+	cfg RapidInOutConfig
+}
+
+func (r *rapidInOutRule) Name() string            { return "rapid_in_out" }
+func (r *rapidInOutRule) Severity() Severity      { return r.cfg.Severity }
+func (r *rapidInOutRule) Window() time.Duration   { return r.cfg.Window.Duration }
+func (r *rapidInOutRule) Lookback() time.Duration { return r.cfg.Window.Duration }
+
+func (r *rapidInOutRule) Evaluate(h *history, latest *Activity) *Finding {
+	if !latest.Outflow() {
+		return nil
+	}
+
+	// Only money leaving after it came in counts as passing through
+	var evidence []*Activity
+	var inflow, outflow float64
+	for _, activity := range h.since(latest.At.Add(-r.cfg.Window.Duration)) {
+		switch {
+		case activity.Inflow():
+			inflow += activity.USDValue
+			evidence = append(evidence, activity)
+		case activity.Outflow() && inflow > 0:
+			outflow += activity.USDValue
+			evidence = append(evidence, activity)
+		}
+	}
+	if inflow < r.cfg.MinInflowUSD || outflow < inflow*r.cfg.OutflowRatio {
+		return nil
+	}
+
+	return &Finding{
+		Summary: fmt.Sprintf("%.2f USD deposited and %.2f USD (%.0f%%) moved out again within %s",
+			inflow, outflow, outflow/inflow*100, r.cfg.Window.Duration),
+		Evidence: evidence,
+	}
+}
+
+type roundTripRule struct {
+	cfg  RoundTripConfig
+	fiat map[string]bool
+}
+
+func (r *roundTripRule) Name() string            { return "round_trip" }
+func (r *roundTripRule) Severity() Severity      { return r.cfg.Severity }
+func (r *roundTripRule) Window() time.Duration   { return r.cfg.Window.Duration }
+func (r *roundTripRule) Lookback() time.Duration { return r.cfg.Window.Duration }
+
+func (r *roundTripRule) Evaluate(h *history, latest *Activity) *Finding {
+	if latest.Kind != ActivityExchange || !r.fiat[latest.ToCurrency] || r.fiat[latest.Currency] {
+		return nil
+	}
+
+	// Look for the most recent purchase of crypto with the fiat that came back.
+	// The crypto bought need not be the one sold, so hops through other crypto
+	// still count.
+	activities := h.since(latest.At.Add(-r.cfg.Window.Duration))
+	for i := len(activities) - 1; i >= 0; i-- {
+		out := activities[i]
+		if out == latest || out.Kind != ActivityExchange {
+			continue
+		}
+		if out.Currency != latest.ToCurrency || r.fiat[out.ToCurrency] {
+			continue
+		}
+		if out.USDValue < r.cfg.MinAmountUSD || latest.ToAmount < out.Amount*r.cfg.ReturnRatio {
+			continue
+		}
+
+		return &Finding{
+			Summary: fmt.Sprintf("%.2f %s exchanged into %s and %.2f %s exchanged back from %s within %s",
+				out.Amount, out.Currency, out.ToCurrency, latest.ToAmount, latest.ToCurrency, latest.Currency,
+				latest.At.Sub(out.At).Round(time.Minute)),
+			Evidence: []*Activity{out, latest},
+		}
+	}
+
+	return nil
+}
+
+type volumeSpikeRule struct {
+	cfg VolumeSpikeConfig
+}
+
+func (r *volumeSpikeRule) Name() string            { return "volume_spike" }
+func (r *volumeSpikeRule) Severity() Severity      { return r.cfg.Severity }
+func (r *volumeSpikeRule) Window() time.Duration   { return r.cfg.Window.Duration }
+func (r *volumeSpikeRule) Lookback() time.Duration { return r.cfg.Baseline.Duration }
+
+func (r *volumeSpikeRule) Evaluate(h *history, latest *Activity) *Finding {
+	if latest.At.Sub(h.firstSeen) < r.cfg.MinHistory.Duration {
+		return nil
+	}
+
+	windowStart := latest.At.Add(-r.cfg.Window.Duration)
+	current := h.since(windowStart)
+	var volume float64
+	for _, activity := range current {
+		volume += activity.USDValue
+	}
+	if volume < r.cfg.MinVolumeUSD {
+		return nil
+	}
+
+	baselineStart := latest.At.Add(-r.cfg.Baseline.Duration)
+	if h.firstSeen.After(baselineStart) {
+		baselineStart = h.firstSeen
+	}
+	var baseline float64
+	for _, activity := range h.between(baselineStart, windowStart) {
+		baseline += activity.USDValue
+	}
+	windows := float64(windowStart.Sub(baselineStart)) / float64(r.cfg.Window.Duration)
+	if windows < 1 {
+		windows = 1
+	}
+	average := baseline / windows
+
+	if volume <= average*r.cfg.Multiplier {
+		return nil
+	}
+
+	return &Finding{
+		Summary: fmt.Sprintf("%.2f USD moved within %s against a usual %.2f USD per %s",
+			volume, r.cfg.Window.Duration, average, r.cfg.Window.Duration),
+		Evidence: current,
+	}
+}
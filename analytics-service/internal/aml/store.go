@@ -0,0 +1,266 @@
+package aml
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlertStatus tracks an alert through the investigation of an analyst
+type AlertStatus string
+
+const (
+	AlertOpen          AlertStatus = "open"
+	AlertInvestigating AlertStatus = "investigating"
+	AlertEscalated     AlertStatus = "escalated"
+	AlertClosed        AlertStatus = "closed"
+)
+
+// Valid reports whether the status is one of the known statuses
+func (s AlertStatus) Valid() bool {
+	switch s {
+	case AlertOpen, AlertInvestigating, AlertEscalated, AlertClosed:
+		return true
+	}
+	return false
+}
+
+// ErrAlertNotFound is returned for unknown alert IDs
+var ErrAlertNotFound = errors.New("alert not found")
+
+// Alert is a case raised by a rule for one user
+type Alert struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	Rule      string      `json:"rule"`
+	Severity  Severity    `json:"severity"`
+	Status    AlertStatus `json:"status"`
+	Summary   string      `json:"summary"`
+	Evidence  []Activity  `json:"evidence"`
+	Notes     []Note      `json:"notes"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Note records a step of the investigation
+type Note struct {
+	Author string      `json:"author"`
+	Text   string      `json:"text"`
+	Status AlertStatus `json:"status"`
+	At     time.Time   `json:"at"`
+}
+
+// AlertFilter selects alerts; empty fields match everything
+type AlertFilter struct {
+	Status   AlertStatus
+	Severity Severity
+	UserID   string
+	Rule     string
+}
+
+func (f *AlertFilter) matches(alert *Alert) bool {
+	return (f.Status == "" || alert.Status == f.Status) &&
+		(f.Severity == "" || alert.Severity == f.Severity) &&
+		(f.UserID == "" || alert.UserID == f.UserID) &&
+		(f.Rule == "" || alert.Rule == f.Rule)
+}
+
+// CaseStore keeps the alerts. With a path every change is written to a JSON
+// file so that cases survive restarts; without one they only live in memory.
+type CaseStore struct {
+	mu     sync.RWMutex
+	path   string
+	alerts map[string]*Alert
+}
+
+// NewCaseStore opens the case store, loading the cases saved at path
+func NewCaseStore(path string) (*CaseStore, error) {
+	store := &CaseStore{
+		path:   path,
+		alerts: make(map[string]*Alert),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create case store directory: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case store: %w", err)
+	}
+
+	var alerts []*Alert
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to parse case store: %w", err)
+	}
+	for _, alert := range alerts {
+		store.alerts[alert.ID] = alert
+	}
+
+	return store, nil
+}
+
+// Create stores a new open alert
+func (s *CaseStore) Create(alert *Alert) error {
+	id, err := newAlertID()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alert.ID = id
+	alert.Status = AlertOpen
+	alert.Notes = []Note{}
+	alert.CreatedAt = time.Now().UTC()
+	alert.UpdatedAt = alert.CreatedAt
+	s.alerts[id] = alert
+
+	if err := s.save(); err != nil {
+		delete(s.alerts, id)
+		return err
+	}
+	return nil
+}
+
+// Get returns a copy of an alert
+func (s *CaseStore) Get(id string) (*Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	alert, ok := s.alerts[id]
+	if !ok {
+		return nil, ErrAlertNotFound
+	}
+	return alert.clone(), nil
+}
+
+// List returns copies of the alerts matching the filter, newest first
+func (s *CaseStore) List(filter AlertFilter) []*Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	alerts := make([]*Alert, 0)
+	for _, alert := range s.alerts {
+		if filter.matches(alert) {
+			alerts = append(alerts, alert.clone())
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.After(alerts[j].CreatedAt)
+	})
+	return alerts
+}
+
+// HasUnresolved reports whether the rule already has an alert for the user
+// raised since the given time that is not closed yet
+func (s *CaseStore) HasUnresolved(userID, rule string, since time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, alert := range s.alerts {
+		if alert.UserID == userID && alert.Rule == rule &&
+			alert.Status != AlertClosed && !alert.CreatedAt.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update moves an alert to a new status and records the note of the analyst
+func (s *CaseStore) Update(id string, status AlertStatus, author, text string) (*Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alert, ok := s.alerts[id]
+	if !ok {
+		return nil, ErrAlertNotFound
+	}
+	// Reopening is allowed, but only with a reason on record
+	if alert.Status == AlertClosed && status != AlertClosed && text == "" {
+		return nil, fmt.Errorf("a note is required to reopen a closed alert")
+	}
+
+	previous := *alert
+	now := time.Now().UTC()
+	alert.Status = status
+	alert.Notes = append(alert.Notes, Note{Author: author, Text: text, Status: status, At: now})
+	alert.UpdatedAt = now
+
+	if err := s.save(); err != nil {
+		*alert = previous
+		return nil, err
+	}
+	return alert.clone(), nil
+}
+
+// save writes all alerts to the store file, through a temporary file so that a
+// crash never leaves a truncated store behind. Callers hold the lock.
+func (s *CaseStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	alerts := make([]*Alert, 0, len(s.alerts))
+	for _, alert := range s.alerts {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(alerts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal case store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write case store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write case store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write case store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write case store: %w", err)
+	}
+
+	return nil
+}
+
+func (a *Alert) clone() *Alert {
+	clone := *a
+	clone.Evidence = append([]Activity(nil), a.Evidence...)
+	clone.Notes = append([]Note{}, a.Notes...)
+	return &clone
+}
+
+func newAlertID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate alert ID: %w", err)
+	}
+	// Random (version 4) UUID, the ID format used across the services
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/crypto-bank/analytics-service/internal/aml"
+	"github.com/crypto-bank/shared/authz"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AlertHandler struct {
+	store *aml.CaseStore
+}
+
+func NewAlertHandler(store *aml.CaseStore) *AlertHandler {
+	return &AlertHandler{
+		store: store,
+	}
+}
+
+// UpdateAlertRequest moves an alert along its investigation. The note is
+// signed with the user ID of the caller.
+type UpdateAlertRequest struct {
+	Status aml.AlertStatus `json:"status"`
+	Note   string          `json:"note"`
+}
+
+// GetAlerts lists the alerts, newest first. They can be filtered by status,
+// severity, user_id and rule.
+func (h *AlertHandler) GetAlerts(c *fiber.Ctx) error {
+	filter := aml.AlertFilter{
+		Status:   aml.AlertStatus(c.Query("status")),
+		Severity: aml.Severity(c.Query("severity")),
+		UserID:   c.Query("user_id"),
+		Rule:     c.Query("rule"),
+	}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("unknown status: %s", filter.Status))
+	}
+	if filter.Severity != "" && !filter.Severity.Valid() {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("unknown severity: %s", filter.Severity))
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    h.store.List(filter),
+	})
+}
+
+// GetAlert returns an alert with its evidence and notes
+func (h *AlertHandler) GetAlert(c *fiber.Ctx) error {
+	alert, err := h.store.Get(c.Params("id"))
+	if err != nil {
+		return fail(c, fiber.StatusNotFound, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    alert,
+	})
+}
+
+// UpdateAlert changes the status of an alert and records a note
+func (h *AlertHandler) UpdateAlert(c *fiber.Ctx) error {
+	var req UpdateAlertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fail(c, fiber.StatusBadRequest, err)
+	}
+	if !req.Status.Valid() {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("unknown status: %s", req.Status))
+	}
+	if len(req.Note) > 1000 {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("note must be at most 1000 characters"))
+	}
+
+	caller, ok := authz.Caller(c)
+	if !ok {
+		return fail(c, fiber.StatusUnauthorized, fmt.Errorf("unauthenticated"))
+	}
+
+	alert, err := h.store.Update(c.Params("id"), req.Status, caller.Subject, req.Note)
+	if errors.Is(err, aml.ErrAlertNotFound) {
+		return fail(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return fail(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    alert,
+	})
+}
+
+func fail(c *fiber.Ctx, status int, err error) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
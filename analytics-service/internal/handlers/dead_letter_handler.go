@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/crypto-bank/shared/broker"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+type DeadLetterHandler struct {
+	client   *broker.Client
+	consumer broker.ConsumerConfig
+}
+
+func NewDeadLetterHandler(client *broker.Client, consumer broker.ConsumerConfig) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		client:   client,
+		consumer: consumer,
+	}
+}
+
+// RedriveRequest picks the dead-lettered messages to send back. Without
+// message IDs the first limit messages are sent.
+type RedriveRequest struct {
+	MessageIDs []string `json:"message_ids"`
+	Limit      int      `json:"limit"`
+}
+
+// GetDeadLetters shows the messages at the head of the dead-letter queue
+// without removing them
+func (h *DeadLetterHandler) GetDeadLetters(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultDeadLetterLimit)
+	if limit <= 0 || limit > maxDeadLetterLimit {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxDeadLetterLimit))
+	}
+
+	letters, err := h.client.DeadLetters(c.Context(), h.consumer, limit)
+	if err != nil {
+		return fail(c, fiber.StatusServiceUnavailable, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    letters,
+		"count":   len(letters),
+	})
+}
+
+// Redrive sends dead-lettered messages back to the queue for another round of
+// retries
+func (h *DeadLetterHandler) Redrive(c *fiber.Ctx) error {
+	var req RedriveRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fail(c, fiber.StatusBadRequest, err)
+		}
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultDeadLetterLimit
+	}
+	if req.Limit < 0 || req.Limit > maxDeadLetterLimit {
+		return fail(c, fiber.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxDeadLetterLimit))
+	}
+
+	redriven, err := h.client.Redrive(c.Context(), h.consumer, req.MessageIDs, req.Limit)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success":  false,
+			"error":    err.Error(),
+			"redriven": redriven,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"redriven": redriven,
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/crypto-bank/shared/replay"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RebuildHandler rebuilds the statistics from events replayed by the bank
+// service: start a rebuild, replay the events there, and finish the rebuild
+// once the replay queue is drained
+type RebuildHandler struct {
+	rebuild *replay.Rebuild
+}
+
+func NewRebuildHandler(rebuild *replay.Rebuild) *RebuildHandler {
+	return &RebuildHandler{
+		rebuild: rebuild,
+	}
+}
+
+// GetRebuild shows the current or last rebuild
+func (h *RebuildHandler) GetRebuild(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    h.rebuild.Status(),
+	})
+}
+
+// StartRebuild resets the statistics and starts applying replayed events
+func (h *RebuildHandler) StartRebuild(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    h.rebuild.Start(),
+	})
+}
+
+// FinishRebuild stops applying replayed events
+func (h *RebuildHandler) FinishRebuild(c *fiber.Ctx) error {
+	status, err := h.rebuild.Finish()
+	if errors.Is(err, replay.ErrNotRebuilding) {
+		return fail(c, fiber.StatusConflict, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
@@ -1,9 +1,9 @@
 package service
 
 import (
-	"encoding/json"
 	"sync"
 
+	"github.com/crypto-bank/shared/events"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -47,6 +47,22 @@ var (
 		},
 		[]string{"currency"},
 	)
+
+	UserEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_user_events_total",
+			Help: "Total number of user lifecycle events processed",
+		},
+		[]string{"event"},
+	)
+
+	BalanceChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_balance_changes_total",
+			Help: "Total number of account and wallet balance changes",
+		},
+		[]string{"kind", "direction"},
+	)
 )
 
 func init() {
@@ -55,6 +71,8 @@ func init() {
 	prometheus.MustRegister(AccountsCreated)
 	prometheus.MustRegister(WalletsCreated)
 	prometheus.MustRegister(TransactionVolume)
+	prometheus.MustRegister(UserEvents)
+	prometheus.MustRegister(BalanceChanges)
 	
 	// Initialize metrics with zero values to make them visible
 	TransactionsProcessed.WithLabelValues("TRANSFER", "completed").Add(0)
@@ -72,9 +90,12 @@ type AnalyticsService struct {
 
 type Statistics struct {
 	TotalTransactions   int64              `json:"total_transactions"`
+	FailedTransactions  int64              `json:"failed_transactions"`
 	TotalExchanges      int64              `json:"total_exchanges"`
+	FailedExchanges     int64              `json:"failed_exchanges"`
 	TotalAccounts       int64              `json:"total_accounts"`
 	TotalWallets        int64              `json:"total_wallets"`
+	TotalUsers          int64              `json:"total_users"`
 	TransactionsByType  map[string]int64   `json:"transactions_by_type"`
 	ExchangesByType     map[string]int64   `json:"exchanges_by_type"`
 	VolumesByCurrency   map[string]float64 `json:"volumes_by_currency"`
@@ -91,25 +112,37 @@ func NewAnalyticsService(logger *zap.Logger) *AnalyticsService {
 	}
 }
 
-// ProcessTransactionEvent processes transaction events
-func (s *AnalyticsService) ProcessTransactionEvent(body []byte) error {
-	var event struct {
-		TransactionID string  `json:"transaction_id"`
-		UserID        string  `json:"user_id"`
-		Type          string  `json:"type"`
-		Amount        float64 `json:"amount"`
-		Currency      string  `json:"currency"`
-		Status        string  `json:"status"`
-	}
+// Reset empties the statistics before they are rebuilt from replayed events.
+// The counters keep counting; they describe what was processed.
+func (s *AnalyticsService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal transaction event", zap.Error(err))
-		return err
+	s.stats = &Statistics{
+		TransactionsByType: make(map[string]int64),
+		ExchangesByType:    make(map[string]int64),
+		VolumesByCurrency:  make(map[string]float64),
 	}
+	TransactionVolume.Reset()
+
+	s.logger.Info("Statistics reset")
+}
 
+// ProcessTransactionEvent processes transaction events. Totals and volumes
+// count completed transactions; created and failed ones only show in the
+// metrics by status.
+func (s *AnalyticsService) ProcessTransactionEvent(event *events.TransactionEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if event.Status != "COMPLETED" {
+		if event.Status == "FAILED" {
+			s.stats.FailedTransactions++
+		}
+		TransactionsProcessed.WithLabelValues(event.Type, event.Status).Inc()
+		return
+	}
+
 	s.stats.TotalTransactions++
 	s.stats.TransactionsByType[event.Type]++
 	s.stats.VolumesByCurrency[event.Currency] += event.Amount
@@ -124,32 +157,24 @@ func (s *AnalyticsService) ProcessTransactionEvent(body []byte) error {
 		zap.Float64("amount", event.Amount),
 		zap.String("currency", event.Currency),
 	)
-
-	return nil
 }
 
-// ProcessExchangeEvent processes exchange events
-func (s *AnalyticsService) ProcessExchangeEvent(body []byte) error {
-	var event struct {
-		ExchangeID   string  `json:"exchange_id"`
-		UserID       string  `json:"user_id"`
-		FromCurrency string  `json:"from_currency"`
-		ToCurrency   string  `json:"to_currency"`
-		FromAmount   float64 `json:"from_amount"`
-		ToAmount     float64 `json:"to_amount"`
-		Status       string  `json:"status"`
-	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal exchange event", zap.Error(err))
-		return err
-	}
-
+// ProcessExchangeEvent processes exchange events. Totals count completed
+// exchanges; created and failed ones only show in the metrics by status.
+func (s *AnalyticsService) ProcessExchangeEvent(event *events.ExchangeEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.stats.TotalExchanges++
 	exchangeType := event.FromCurrency + "_to_" + event.ToCurrency
+	if event.Status != "COMPLETED" {
+		if event.Status == "FAILED" {
+			s.stats.FailedExchanges++
+		}
+		ExchangesProcessed.WithLabelValues(exchangeType, event.Status).Inc()
+		return
+	}
+
+	s.stats.TotalExchanges++
 	s.stats.ExchangesByType[exchangeType]++
 
 	// Update metrics
@@ -161,23 +186,10 @@ func (s *AnalyticsService) ProcessExchangeEvent(body []byte) error {
 		zap.String("to", event.ToCurrency),
 		zap.Float64("amount", event.FromAmount),
 	)
-
-	return nil
 }
 
 // ProcessAccountEvent processes account creation events
-func (s *AnalyticsService) ProcessAccountEvent(body []byte) error {
-	var event struct {
-		AccountID string `json:"account_id"`
-		UserID    string `json:"user_id"`
-		Currency  string `json:"currency"`
-	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal account event", zap.Error(err))
-		return err
-	}
-
+func (s *AnalyticsService) ProcessAccountEvent(event *events.AccountEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -188,23 +200,10 @@ func (s *AnalyticsService) ProcessAccountEvent(body []byte) error {
 		zap.String("account_id", event.AccountID),
 		zap.String("currency", event.Currency),
 	)
-
-	return nil
 }
 
 // ProcessWalletEvent processes wallet creation events
-func (s *AnalyticsService) ProcessWalletEvent(body []byte) error {
-	var event struct {
-		WalletID   string `json:"wallet_id"`
-		UserID     string `json:"user_id"`
-		CryptoType string `json:"crypto_type"`
-	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal wallet event", zap.Error(err))
-		return err
-	}
-
+func (s *AnalyticsService) ProcessWalletEvent(event *events.WalletEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -215,8 +214,54 @@ func (s *AnalyticsService) ProcessWalletEvent(body []byte) error {
 		zap.String("wallet_id", event.WalletID),
 		zap.String("crypto_type", event.CryptoType),
 	)
+}
 
-	return nil
+// ProcessUserEvent processes user lifecycle events
+func (s *AnalyticsService) ProcessUserEvent(eventType string, event *events.UserEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch eventType {
+	case events.UserCreated:
+		s.stats.TotalUsers++
+	case events.UserDeleted:
+		s.stats.TotalUsers--
+	}
+	UserEvents.WithLabelValues(eventType).Inc()
+
+	s.logger.Info("User event processed",
+		zap.String("event", eventType),
+		zap.String("user_id", event.UserID),
+	)
+}
+
+// ProcessAccountBalanceEvent processes account balance changes
+func (s *AnalyticsService) ProcessAccountBalanceEvent(event *events.AccountBalanceEvent) {
+	BalanceChanges.WithLabelValues("account", direction(event.Delta)).Inc()
+
+	s.logger.Debug("Account balance event processed",
+		zap.String("account_id", event.AccountID),
+		zap.Float64("delta", event.Delta),
+		zap.String("currency", event.Currency),
+	)
+}
+
+// ProcessWalletBalanceEvent processes wallet balance changes
+func (s *AnalyticsService) ProcessWalletBalanceEvent(event *events.WalletBalanceEvent) {
+	BalanceChanges.WithLabelValues("wallet", direction(event.Delta)).Inc()
+
+	s.logger.Debug("Wallet balance event processed",
+		zap.String("wallet_id", event.WalletID),
+		zap.Float64("delta", event.Delta),
+		zap.String("crypto_type", event.CryptoType),
+	)
+}
+
+func direction(delta float64) string {
+	if delta < 0 {
+		return "debit"
+	}
+	return "credit"
 }
 
 // GetStatistics returns current statistics
@@ -227,9 +272,12 @@ func (s *AnalyticsService) GetStatistics() *Statistics {
 	// Create a copy of statistics
 	statsCopy := &Statistics{
 		TotalTransactions:  s.stats.TotalTransactions,
+		FailedTransactions: s.stats.FailedTransactions,
 		TotalExchanges:     s.stats.TotalExchanges,
+		FailedExchanges:    s.stats.FailedExchanges,
 		TotalAccounts:      s.stats.TotalAccounts,
 		TotalWallets:       s.stats.TotalWallets,
+		TotalUsers:         s.stats.TotalUsers,
 		TransactionsByType: make(map[string]int64),
 		ExchangesByType:    make(map[string]int64),
 		VolumesByCurrency:  make(map[string]float64),
@@ -0,0 +1,105 @@
+// Command audit-verifier checks exported audit trail entries offline.
+//
+// It accepts bodies returned by GET /admin/v1/audit, one file per page or a
+// JSON array of entries, recomputes every entry hash and checks that
+// consecutive entries link. Filtered exports skip entries, so links are only
+// checked between neighbours; -complete requires an unfiltered export from the
+// first entry on, which also catches deleted entries.
+//
+//	audit-verifier -complete -head <recorded head hash> page1.json page2.json
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/crypto-bank/bank-service/pkg/auditchain"
+)
+
+func main() {
+	complete := flag.Bool("complete", false, "require the full chain from the first entry without gaps")
+	expectedHead := flag.String("head", "", "previously recorded head hash the chain must contain (optional)")
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	var entries []*auditchain.Entry
+	for _, path := range paths {
+		page, err := readEntries(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			os.Exit(2)
+		}
+		entries = append(entries, page...)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "no entries found")
+		os.Exit(2)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+
+	verifier := auditchain.NewVerifier(*complete)
+	headFound := *expectedHead == ""
+	for _, entry := range entries {
+		if err := verifier.Add(entry); err != nil {
+			var broken *auditchain.BreakError
+			if errors.As(err, &broken) {
+				fmt.Printf("FAIL entry %d (%s %s %s): %s\n", broken.Seq, entry.Action, entry.EntityType, entry.EntityID, broken.Reason)
+			} else {
+				fmt.Printf("FAIL %v\n", err)
+			}
+			os.Exit(1)
+		}
+		if entry.Hash == *expectedHead {
+			headFound = true
+		}
+	}
+
+	result := verifier.Result()
+	if !headFound {
+		fmt.Printf("FAIL recorded head %s is not part of the chain up to entry %d\n", *expectedHead, result.HeadSeq)
+		os.Exit(1)
+	}
+
+	fmt.Printf("OK   %d entries from %d to %d, head %s\n", result.Entries, result.FirstSeq, result.HeadSeq, result.HeadHash)
+	if result.Gaps > 0 {
+		fmt.Printf("     %d gaps between exported entries were not checked\n", result.Gaps)
+	}
+}
+
+// readEntries decodes an API response envelope or a list of entries
+func readEntries(path string) ([]*auditchain.Entry, error) {
+	var (
+		raw []byte
+		err error
+	)
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
+		raw = envelope.Data
+	}
+
+	var entries []*auditchain.Entry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,165 @@
+// Command authctl manages the token signing keys of the bank service.
+//
+//	authctl keygen -dir keys           add a signing key; it signs new tokens after the next key reload
+//	authctl keys -dir keys             list the keys and the active one
+//	authctl token -dir keys -user <id> mint an access token, e.g. for scripts and smoke tests;
+//	                                   -role admin mints an operator token to assign the first staff roles
+//	authctl sign -secret <s> -method POST -path /api/v1/... -body req.json
+//	                                   print the X-API-* signature headers of an API key request
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "keys":
+		err = keys(os.Args[2:])
+	case "token":
+		err = token(os.Args[2:])
+	case "sign":
+		err = sign(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: authctl keygen|keys|token|sign [flags]")
+	os.Exit(2)
+}
+
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	dir := fs.String("dir", "./keys", "key directory")
+	fs.Parse(args)
+
+	kid, err := auth.GenerateKey(*dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(kid)
+	return nil
+}
+
+func keys(args []string) error {
+	fs := flag.NewFlagSet("keys", flag.ExitOnError)
+	dir := fs.String("dir", "./keys", "key directory")
+	activeKID := fs.String("active", os.Getenv("AUTH_ACTIVE_KEY_ID"), "configured active key ID")
+	fs.Parse(args)
+
+	store := auth.NewKeyStore(*dir, *activeKID)
+	if err := store.Reload(); err != nil {
+		return err
+	}
+
+	active := store.ActiveKeyID()
+	for _, kid := range store.KeyIDs() {
+		marker := " "
+		if kid == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, kid)
+	}
+	return nil
+}
+
+func token(args []string) error {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	dir := fs.String("dir", "./keys", "key directory")
+	activeKID := fs.String("active", os.Getenv("AUTH_ACTIVE_KEY_ID"), "configured active key ID")
+	issuer := fs.String("issuer", "crypto-bank", "token issuer, must match AUTH_ISSUER")
+	user := fs.String("user", "", "user ID")
+	role := fs.String("role", string(models.RoleCustomer), "role carried by the token")
+	ttl := fs.Duration("ttl", 15*time.Minute, "token lifetime")
+	fs.Parse(args)
+
+	userID, err := uuid.Parse(*user)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	switch models.Role(*role) {
+	case models.RoleCustomer, models.RoleSupport, models.RoleCompliance, models.RoleAdmin:
+	default:
+		return fmt.Errorf("unknown role %q", *role)
+	}
+
+	store := auth.NewKeyStore(*dir, *activeKID)
+	if err := store.Reload(); err != nil {
+		return err
+	}
+
+	tokens := auth.NewTokenManager(store, *issuer)
+	claims := tokens.NewClaims(userID, auth.TokenTypeAccess, *ttl)
+	claims.Role = models.Role(*role)
+
+	signed, err := tokens.Sign(claims)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(signed)
+	return nil
+}
+
+func sign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyID := fs.String("key", "", "API key ID")
+	secret := fs.String("secret", os.Getenv("API_KEY_SECRET"), "API key secret")
+	method := fs.String("method", "GET", "HTTP method")
+	path := fs.String("path", "", "request path including the query string")
+	bodyFile := fs.String("body", "", "file holding the request body")
+	fs.Parse(args)
+
+	if *secret == "" || *path == "" {
+		return fmt.Errorf("-secret and -path are required")
+	}
+
+	var body []byte
+	if *bodyFile != "" {
+		var err error
+		if body, err = os.ReadFile(*bodyFile); err != nil {
+			return err
+		}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	encodedNonce := hex.EncodeToString(nonce)
+
+	fmt.Printf("%s: %s\n", middleware.HeaderAPIKey, *keyID)
+	fmt.Printf("%s: %s\n", middleware.HeaderAPITimestamp, timestamp)
+	fmt.Printf("%s: %s\n", middleware.HeaderAPINonce, encodedNonce)
+	fmt.Printf("%s: %s\n", middleware.HeaderAPISignature, auth.SignRequest(*secret, timestamp, encodedNonce, *method, *path, body))
+	return nil
+}
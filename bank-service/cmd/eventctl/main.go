@@ -0,0 +1,206 @@
+// Command eventctl reads the event store of the bank service and replays
+// stored events to bank.events.replay. It connects with the configuration of
+// the service, taken from the same environment variables.
+//
+//	eventctl list -user <id> -type transaction.completed    list matching events
+//	eventctl replay -from 2024-01-01T00:00:00Z               replay events since a time
+//	eventctl replay -subject <id>                            replay the events of an entity
+//
+// Consumers only apply replayed events while they rebuild: start the rebuild
+// on each consumer, replay, and finish the rebuild once the replay queues are
+// drained. Unlike the admin API the command replays without a limit.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/config"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "list":
+		err = list(os.Args[2:])
+	case "replay":
+		err = replay(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: eventctl list|replay [flags]")
+	os.Exit(2)
+}
+
+// selection holds the flags both commands select events with
+type selection struct {
+	from, to, subject, userID, types *string
+	after                            *int64
+	limit                            *uint64
+}
+
+func selectionFlags(fs *flag.FlagSet, defaultLimit uint64) *selection {
+	return &selection{
+		from:    fs.String("from", "", "RFC 3339 start time"),
+		to:      fs.String("to", "", "RFC 3339 end time"),
+		subject: fs.String("subject", "", "ID of the entity the events are about"),
+		userID:  fs.String("user", "", "user the events concern"),
+		types:   fs.String("type", "", "comma-separated event types"),
+		after:   fs.Int64("after", 0, "only events after this position"),
+		limit:   fs.Uint64("limit", defaultLimit, "number of events, 0 for all"),
+	}
+}
+
+func (s *selection) request() (*models.ReplayRequest, error) {
+	req := &models.ReplayRequest{
+		Subject:       *s.subject,
+		UserID:        *s.userID,
+		AfterPosition: *s.after,
+		Limit:         *s.limit,
+	}
+	if req.Limit == 0 {
+		req.Limit = math.MaxInt64
+	}
+
+	if *s.types != "" {
+		req.Types = strings.Split(*s.types, ",")
+		for _, eventType := range req.Types {
+			if events.Version(eventType) == 0 {
+				return nil, fmt.Errorf("%w: %s", events.ErrUnknownType, eventType)
+			}
+		}
+	}
+
+	if *s.from != "" {
+		from, err := time.Parse(time.RFC3339, *s.from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from time: %w", err)
+		}
+		req.From = &from
+	}
+	if *s.to != "" {
+		to, err := time.Parse(time.RFC3339, *s.to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to time: %w", err)
+		}
+		req.To = &to
+	}
+
+	return req, nil
+}
+
+func list(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	sel := selectionFlags(fs, 100)
+	fs.Parse(args)
+
+	req, err := sel.request()
+	if err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	db, err := repositories.NewDatabase(cfg.Database.GetDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	filter := &models.EventFilter{
+		From:          req.From,
+		To:            req.To,
+		Subject:       req.Subject,
+		UserID:        req.UserID,
+		Types:         req.Types,
+		AfterPosition: req.AfterPosition,
+		Limit:         req.Limit,
+	}
+
+	stored, err := repositories.NewEventStoreRepository(db.DB).List(filter)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range stored {
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\n", event.Position, event.OccurredAt.Format(time.RFC3339), event.Type,
+			event.Subject, event.EventID)
+	}
+	return nil
+}
+
+func replay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	sel := selectionFlags(fs, 0)
+	fs.Parse(args)
+
+	req, err := sel.request()
+	if err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	if err := logger.InitLogger(cfg.Server.Environment); err != nil {
+		return err
+	}
+	defer logger.Sync()
+
+	contentMode := events.ContentMode(cfg.Outbox.ContentMode)
+	if !contentMode.Valid() {
+		return fmt.Errorf("unknown outbox content mode %q", cfg.Outbox.ContentMode)
+	}
+
+	db, err := repositories.NewDatabase(cfg.Database.GetDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rabbitMQClient, err := broker.Dial(broker.Config{
+		URL:               cfg.RabbitMQ.GetRabbitMQURL(),
+		Name:              "eventctl",
+		Topology:          rabbitmq.DeclareTopology,
+		ReconnectMinDelay: cfg.RabbitMQ.ReconnectMinDelay,
+		ReconnectMaxDelay: cfg.RabbitMQ.ReconnectMaxDelay,
+	}, logger.Log)
+	if err != nil {
+		return err
+	}
+	defer rabbitMQClient.Close()
+
+	eventStoreService := services.NewEventStoreService(repositories.NewEventStoreRepository(db.DB), rabbitMQClient, services.EventStoreSettings{
+		ReplayBatchSize: cfg.Events.ReplayBatchSize,
+		PublishTimeout:  cfg.Outbox.PublishTimeout,
+		ContentMode:     contentMode,
+	})
+
+	result, err := eventStoreService.Replay(context.Background(), req)
+	if result != nil {
+		fmt.Printf("replay %s: %d published, %d unroutable, positions %d to %d, complete %t\n",
+			result.ReplayID, result.Published, result.Unroutable, result.FirstPosition, result.LastPosition, result.Complete)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+// Command reserves-verifier checks proof-of-reserves inclusion proofs offline.
+//
+// It accepts the body returned by GET /api/v1/users/:user_id/wallets/reserves-proof,
+// a JSON array of proofs or a single proof, and recomputes every Merkle sum tree root.
+//
+//	reserves-verifier -proof proof.json -root <published root hash>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/crypto-bank/bank-service/pkg/merkle"
+)
+
+type proof struct {
+	SnapshotID string `json:"snapshot_id"`
+	CryptoType string `json:"crypto_type"`
+	merkle.Proof
+}
+
+func main() {
+	proofPath := flag.String("proof", "-", "path to the proof JSON, - for stdin")
+	expectedRoot := flag.String("root", "", "published root hash to compare against (optional)")
+	expectedUser := flag.String("user", "", "user ID the proofs must belong to (optional)")
+	flag.Parse()
+
+	proofs, err := readProofs(*proofPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read proofs: %v\n", err)
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, p := range proofs {
+		label := fmt.Sprintf("%s snapshot=%s", p.CryptoType, p.SnapshotID)
+
+		if err := p.Verify(); err != nil {
+			fmt.Printf("FAIL %s: %v\n", label, err)
+			failed = true
+			continue
+		}
+		if *expectedRoot != "" && p.RootHash != *expectedRoot {
+			fmt.Printf("FAIL %s: root %s does not match published root %s\n", label, p.RootHash, *expectedRoot)
+			failed = true
+			continue
+		}
+		if *expectedUser != "" && p.UserID != *expectedUser {
+			fmt.Printf("FAIL %s: proof belongs to user %s\n", label, p.UserID)
+			failed = true
+			continue
+		}
+
+		fmt.Printf("OK   %s: balance %.*f included in root %s (total %.*f)\n",
+			label, merkle.Decimals, merkle.FromUnits(p.Balance), p.RootHash,
+			merkle.Decimals, merkle.FromUnits(p.RootSum))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// readProofs decodes an API response envelope, a list of proofs or a single proof
+func readProofs(path string) ([]proof, error) {
+	var (
+		raw []byte
+		err error
+	)
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
+		raw = envelope.Data
+	}
+
+	var proofs []proof
+	if err := json.Unmarshal(raw, &proofs); err == nil {
+		if len(proofs) == 0 {
+			return nil, fmt.Errorf("no proofs found")
+		}
+		return proofs, nil
+	}
+
+	var single proof
+	if err := json.Unmarshal(raw, &single); err != nil {
+		return nil, err
+	}
+	return []proof{single}, nil
+}
@@ -5,16 +5,25 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/crypto-bank/bank-service/internal/auth"
 	"github.com/crypto-bank/bank-service/internal/config"
 	"github.com/crypto-bank/bank-service/internal/handlers"
+	"github.com/crypto-bank/bank-service/internal/kyc"
 	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/quotes"
 	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/internal/risk"
+	"github.com/crypto-bank/bank-service/internal/screening"
 	"github.com/crypto-bank/bank-service/internal/services"
 	"github.com/crypto-bank/bank-service/pkg/logger"
 	"github.com/crypto-bank/bank-service/pkg/metrics"
 	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
 	"github.com/crypto-bank/bank-service/pkg/tracing"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"go.uber.org/zap"
@@ -61,18 +70,48 @@ func main() {
 	logger.Info("Migrations completed successfully")
 
 	// Connect to RabbitMQ
-	rabbitMQClient, err := rabbitmq.NewClient(cfg.RabbitMQ.GetRabbitMQURL())
+	rabbitMQClient, err := broker.Dial(broker.Config{
+		URL:               cfg.RabbitMQ.GetRabbitMQURL(),
+		Name:              "bank-service",
+		Topology:          rabbitmq.DeclareTopology,
+		PublisherChannels: cfg.RabbitMQ.PublisherChannels,
+		ReconnectMinDelay: cfg.RabbitMQ.ReconnectMinDelay,
+		ReconnectMaxDelay: cfg.RabbitMQ.ReconnectMaxDelay,
+	}, logger.Log)
 	if err != nil {
 		logger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
 	}
 	defer rabbitMQClient.Close()
 
-	// Declare exchange
-	if err := rabbitMQClient.DeclareExchange(rabbitmq.ExchangeEvents, "topic"); err != nil {
-		logger.Fatal("Failed to declare exchange", zap.Error(err))
+	logger.Info("Connected to RabbitMQ")
+
+	// Load token signing keys
+	keyStore := auth.NewKeyStore(cfg.Auth.KeysDir, cfg.Auth.ActiveKeyID)
+	generatedKey, err := keyStore.LoadOrGenerate()
+	if err != nil {
+		logger.Fatal("Failed to load signing keys", zap.Error(err))
+	}
+	if generatedKey != "" {
+		logger.Warn("No signing keys found, generated a new one", zap.String("kid", generatedKey))
 	}
+	logger.Info("Signing keys loaded",
+		zap.Strings("kids", keyStore.KeyIDs()),
+		zap.String("active_kid", keyStore.ActiveKeyID()),
+	)
+	tokenManager := auth.NewTokenManager(keyStore, cfg.Auth.Issuer)
 
-	logger.Info("Connected to RabbitMQ")
+	// Load the key that encrypts API key secrets at rest
+	apiKeySecretKey, generatedSecretKey, err := auth.LoadOrGenerateSecretKey(cfg.APIKeys.EncryptionKeyFile)
+	if err != nil {
+		logger.Fatal("Failed to load API key encryption key", zap.Error(err))
+	}
+	if generatedSecretKey {
+		logger.Warn("No API key encryption key found, generated a new one", zap.String("path", cfg.APIKeys.EncryptionKeyFile))
+	}
+	apiKeySecrets, err := auth.NewSecretBox(apiKeySecretKey)
+	if err != nil {
+		logger.Fatal("Invalid API key encryption key", zap.Error(err))
+	}
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db.DB)
@@ -80,23 +119,303 @@ func main() {
 	walletRepo := repositories.NewCryptoWalletRepository(db.DB)
 	txRepo := repositories.NewTransactionRepository(db.DB)
 	exchangeRepo := repositories.NewExchangeRepository(db.DB)
+	approvalRepo := repositories.NewApprovalRepository(db.DB)
+	treasuryRepo := repositories.NewTreasuryRepository(db.DB)
+	reservesRepo := repositories.NewReservesRepository(db.DB)
+	assetRepo := repositories.NewAssetRepository(db.DB)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db.DB)
+	credentialRepo := repositories.NewCredentialRepository(db.DB)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db.DB)
+	kycRepo := repositories.NewKYCRepository(db.DB)
+	limitRepo := repositories.NewLimitRepository(db.DB)
+	screeningRepo := repositories.NewScreeningRepository(db.DB)
+	riskRepo := repositories.NewRiskRepository(db.DB)
+	auditRepo := repositories.NewAuditRepository(db.DB)
+	outboxRepo := repositories.NewOutboxRepository(db.DB)
+	eventStoreRepo := repositories.NewEventStoreRepository(db.DB)
+	sagaRepo := repositories.NewSagaRepository(db.DB)
+	quoteLockRepo := repositories.NewQuoteLockRepository(db.DB)
+
+	// Identity verification provider
+	kycProvider, err := newKYCProvider(cfg.KYC)
+	if err != nil {
+		logger.Fatal("Failed to initialize KYC provider", zap.Error(err))
+	}
+
+	// Sanctions and watchlist screening
+	screener, err := screening.NewScreener(cfg.Screening.WatchlistsDir, cfg.Screening.MatchThreshold)
+	if err != nil {
+		logger.Fatal("Failed to initialize watchlist screening", zap.Error(err))
+	}
+
+	// Fraud scoring before money movements
+	riskScorer, closeRiskScorer, err := newRiskScorer(cfg.Risk)
+	if err != nil {
+		logger.Fatal("Failed to initialize risk scorer", zap.Error(err))
+	}
+	defer closeRiskScorer()
+
+	contentMode := events.ContentMode(cfg.Outbox.ContentMode)
+	if !contentMode.Valid() {
+		logger.Fatal("Unknown outbox content mode", zap.String("mode", cfg.Outbox.ContentMode))
+	}
 
 	// Initialize services
-	userService := services.NewUserService(userRepo)
-	accountService := services.NewAccountService(accountRepo, userRepo, rabbitMQClient)
-	walletService := services.NewCryptoWalletService(walletRepo, userRepo, rabbitMQClient)
-	transactionService := services.NewTransactionService(txRepo, accountRepo, db.DB, rabbitMQClient)
-	exchangeService := services.NewExchangeService(exchangeRepo, accountRepo, walletRepo, txRepo, db.DB, rabbitMQClient)
+	auditService := services.NewAuditService(auditRepo)
+	outboxService := services.NewOutboxService(outboxRepo, rabbitMQClient, services.OutboxSettings{
+		BatchSize:      cfg.Outbox.BatchSize,
+		ClaimTimeout:   cfg.Outbox.ClaimTimeout,
+		PublishTimeout: cfg.Outbox.PublishTimeout,
+		RetryBaseDelay: cfg.Outbox.RetryBaseDelay,
+		RetryMaxDelay:  cfg.Outbox.RetryMaxDelay,
+		Retention:      cfg.Outbox.Retention,
+		ContentMode:    contentMode,
+	})
+	eventStoreService := services.NewEventStoreService(eventStoreRepo, rabbitMQClient, services.EventStoreSettings{
+		ReplayBatchSize: cfg.Events.ReplayBatchSize,
+		PublishTimeout:  cfg.Outbox.PublishTimeout,
+		ContentMode:     contentMode,
+	})
+	screeningService := services.NewScreeningService(screeningRepo, userRepo, screener, auditService)
+	// Without watchlists every screened operation would fail
+	if _, err := screeningService.ReloadWatchlists(); err != nil {
+		logger.Fatal("Failed to load watchlists",
+			zap.String("dir", cfg.Screening.WatchlistsDir), zap.Error(err))
+	}
+	userService := services.NewUserService(userRepo, credentialRepo, screeningService)
+	lockout := services.NewLockout(credentialRepo, cfg.Auth.MaxFailedLogins, cfg.Auth.LockoutDuration)
+	twoFactorService := services.NewTwoFactorService(credentialRepo, userRepo, lockout, cfg.Auth.TOTPIssuer)
+	authService := services.NewAuthService(tokenManager, refreshTokenRepo, credentialRepo, userRepo, twoFactorService, lockout, services.AuthSettings{
+		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+		StepUpWindow:    cfg.Auth.StepUpWindow,
+	})
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, apiKeySecrets, services.APIKeySettings{
+		MaxClockSkew:   cfg.APIKeys.MaxClockSkew,
+		MaxKeysPerUser: cfg.APIKeys.MaxKeysPerUser,
+	})
+	approvalService := services.NewApprovalService(approvalRepo, walletRepo, accountRepo, userRepo, auditService)
+	treasuryService := services.NewTreasuryService(treasuryRepo, auditService)
+	reservesService := services.NewReservesService(reservesRepo, treasuryRepo)
+	assetService := services.NewAssetService(assetRepo, exchangeRepo, treasuryService)
+	kycService := services.NewKYCService(kycRepo, userRepo, kycProvider, cfg.KYC.ProviderTimeout, auditService)
+	limitService := services.NewLimitService(limitRepo, kycRepo, userRepo, txRepo, exchangeRepo, assetService, auditService)
+	riskService := services.NewRiskService(riskRepo, userRepo, credentialRepo, txRepo, exchangeRepo, assetService, riskScorer, cfg.Risk.Timeout, riskOperations(cfg.Risk.FailClosedOperations))
+	accountService := services.NewAccountService(accountRepo, userRepo, db.DB, outboxRepo, assetService)
+	walletService := services.NewCryptoWalletService(walletRepo, userRepo, txRepo, db.DB, outboxRepo, approvalService, treasuryService, assetService, limitService, screeningService, riskService)
+	transactionService := services.NewTransactionService(txRepo, accountRepo, db.DB, outboxRepo, approvalService, assetService, limitService, screeningService, riskService, cfg.Auth.StepUpTransferThresholdUSD)
+	// Exchanges lock the bank's own rates until a remote quote locker is configured
+	quoteLocker := quotes.NewRateLocker(exchangeRepo.GetExchangeRate, quoteLockRepo, cfg.Saga.QuoteTTL)
+	sagaService := services.NewExchangeSagaService(sagaRepo, exchangeRepo, accountRepo, walletRepo, txRepo, outboxRepo, db.DB, assetService, limitService, quoteLocker, services.ExchangeSagaSettings{
+		Lease:             cfg.Saga.Lease,
+		StepTimeout:       cfg.Saga.StepTimeout,
+		RetryBaseDelay:    cfg.Saga.RetryBaseDelay,
+		RetryMaxDelay:     cfg.Saga.RetryMaxDelay,
+		RecoveryBatchSize: cfg.Saga.RecoveryBatchSize,
+	})
+	exchangeService := services.NewExchangeService(exchangeRepo, accountRepo, walletRepo, sagaService, approvalService, limitService, riskService)
+
+	// Load the asset registry
+	if err := assetService.Reload(); err != nil {
+		logger.Fatal("Failed to load asset registry", zap.Error(err))
+	}
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userService)
-	accountHandler := handlers.NewAccountHandler(accountService)
-	walletHandler := handlers.NewCryptoWalletHandler(walletService)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
-	exchangeHandler := handlers.NewExchangeHandler(exchangeService)
+	userHandler := handlers.NewUserHandler(userService, authService, auditService)
+	authHandler := handlers.NewAuthHandler(authService, twoFactorService, keyStore, auditService)
+	accountHandler := handlers.NewAccountHandler(accountService, auditService)
+	walletHandler := handlers.NewCryptoWalletHandler(walletService, auditService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, auditService)
+	exchangeHandler := handlers.NewExchangeHandler(exchangeService, auditService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	treasuryHandler := handlers.NewTreasuryHandler(treasuryService)
+	reservesHandler := handlers.NewReservesHandler(reservesService)
+	assetHandler := handlers.NewAssetHandler(assetService, auditService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, auditService)
+	kycHandler := handlers.NewKYCHandler(kycService)
+	limitHandler := handlers.NewLimitHandler(limitService)
+	screeningHandler := handlers.NewScreeningHandler(screeningService)
+	riskHandler := handlers.NewRiskHandler(riskService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	eventHandler := handlers.NewEventHandler(eventStoreService, cfg.Events.ReplayMaxEvents)
+	sagaHandler := handlers.NewSagaHandler(sagaService)
+	adminHandler := handlers.NewAdminHandler(userService, authService, accountService, walletService, transactionService, auditService)
+
+	// Publish events committed to the outbox
+	stopOutboxRelay := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Outbox.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				outboxService.Relay()
+			case <-stopOutboxRelay:
+				return
+			}
+		}
+	}()
+	defer close(stopOutboxRelay)
+
+	// Drop published outbox messages past the retention period
+	stopOutboxPurge := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Outbox.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				outboxService.PurgeSent()
+			case <-stopOutboxPurge:
+				return
+			}
+		}
+	}()
+	defer close(stopOutboxPurge)
+
+	// Resume exchange sagas left in flight by a crash and retry failed steps
+	stopSagaRecovery := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Saga.RecoveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sagaService.Recover()
+			case <-stopSagaRecovery:
+				return
+			}
+		}
+	}()
+	defer close(stopSagaRecovery)
+
+	// Expire approval requests that did not reach quorum in time
+	stopApprovalExpiry := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Approval.ExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				approvalService.ExpirePending()
+			case <-stopApprovalExpiry:
+				return
+			}
+		}
+	}()
+	defer close(stopApprovalExpiry)
+
+	// Propose sweeps to cold storage when hot wallets exceed their policy
+	stopTreasurySweep := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Treasury.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := treasuryService.ProposeSweeps(); err != nil {
+					logger.Error("Failed to propose treasury sweeps", zap.Error(err))
+				}
+			case <-stopTreasurySweep:
+				return
+			}
+		}
+	}()
+	defer close(stopTreasurySweep)
+
+	// Publish proof-of-reserves snapshots
+	stopReservesSnapshot := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Reserves.SnapshotInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := reservesService.CreateSnapshot(); err != nil {
+					logger.Error("Failed to create reserve snapshot", zap.Error(err))
+				}
+			case <-stopReservesSnapshot:
+				return
+			}
+		}
+	}()
+	defer close(stopReservesSnapshot)
+
+	// Pick up asset registry changes made by other instances
+	stopAssetRefresh := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Assets.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := assetService.Reload(); err != nil {
+					logger.Error("Failed to refresh asset registry", zap.Error(err))
+				}
+			case <-stopAssetRefresh:
+				return
+			}
+		}
+	}()
+	defer close(stopAssetRefresh)
+
+	// Pick up rotated signing keys and drop refresh tokens past their expiry
+	stopKeyReload := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Auth.KeyReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := keyStore.Reload(); err != nil {
+					logger.Error("Failed to reload signing keys", zap.Error(err))
+				}
+				authService.PurgeExpired()
+			case <-stopKeyReload:
+				return
+			}
+		}
+	}()
+	defer close(stopKeyReload)
+
+	// Drop API request nonces that are past the replay window
+	stopNoncePurge := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.APIKeys.MaxClockSkew)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				apiKeyService.PurgeNonces()
+			case <-stopNoncePurge:
+				return
+			}
+		}
+	}()
+	defer close(stopNoncePurge)
+
+	// Pick up updated sanctions lists
+	stopWatchlistReload := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.Screening.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := screeningService.ReloadWatchlists(); err != nil {
+					logger.Error("Failed to reload watchlists", zap.Error(err))
+				}
+			case <-stopWatchlistReload:
+				return
+			}
+		}
+	}()
+	defer close(stopWatchlistReload)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		// KYC submissions carry up to five base64 encoded documents
+		BodyLimit: 16 * 1024 * 1024,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -112,6 +431,7 @@ func main() {
 
 	// Middleware
 	app.Use(cors.New())
+	app.Use(middleware.RequestID())
 	app.Use(middleware.Tracing("bank-service"))
 	app.Use(middleware.Recovery())
 	app.Use(middleware.Logger())
@@ -127,45 +447,192 @@ func main() {
 	// Metrics endpoint
 	app.Get("/metrics", metrics.MetricsHandler())
 
+	// Token verification keys for other services
+	app.Get("/.well-known/jwks.json", authHandler.GetJWKS)
+
 	// API routes
 	api := app.Group("/api/v1")
 
+	// Public routes
+	api.Get("/assets", assetHandler.GetEnabledAssets)
+	api.Post("/users", userHandler.CreateUser)
+	api.Post("/auth/login", authHandler.Login)
+	api.Post("/auth/refresh", authHandler.Refresh)
+	api.Post("/auth/logout", authHandler.Logout)
+
+	// Everything below requires a valid access token
+	requireAuth := middleware.Auth(authService)
+	self := middleware.RequireSelf("id")
+	owner := middleware.RequireSelf("user_id")
+	stepUp := middleware.RequireStepUp()
+
+	// Session and second factor routes
+	session := api.Group("/auth", requireAuth)
+	session.Post("/logout-all", authHandler.LogoutAll)
+	session.Post("/step-up", authHandler.StepUp)
+	session.Put("/password", authHandler.ChangePassword)
+	session.Get("/2fa", authHandler.GetTwoFactorStatus)
+	session.Post("/2fa/enroll", authHandler.EnrollTwoFactor)
+	session.Post("/2fa/confirm", authHandler.ConfirmTwoFactor)
+	session.Post("/2fa/disable", authHandler.DisableTwoFactor)
+	session.Post("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+
+	// API key management routes
+	apiKeys := api.Group("/api-keys", requireAuth)
+	apiKeys.Get("/", apiKeyHandler.GetKeys)
+	apiKeys.Post("/", apiKeyHandler.CreateKey)
+	apiKeys.Delete("/:id", apiKeyHandler.RevokeKey)
+	apiKeys.Get("/:id/usage", apiKeyHandler.GetUsage)
+
+	// Identity verification routes
+	kycRoutes := api.Group("/kyc", requireAuth)
+	kycRoutes.Get("/", kycHandler.GetStatus)
+	kycRoutes.Post("/applications", kycHandler.SubmitApplication)
+	kycRoutes.Get("/applications/:id", kycHandler.GetApplication)
+
+	// Routes below also accept signed API key requests. Each route declares the
+	// scope an API key needs, or keeps API keys out.
+	apiAuth := middleware.AuthOrAPIKey(authService, apiKeyService)
+	scope := middleware.RequireScope
+	sessionOnly := middleware.SessionOnly()
+
 	// User routes
-	users := api.Group("/users")
-	users.Post("/", userHandler.CreateUser)
-	users.Get("/", userHandler.GetAllUsers)
-	users.Get("/:id", userHandler.GetUser)
-	users.Put("/:id", userHandler.UpdateUser)
-	users.Delete("/:id", userHandler.DeleteUser)
-	users.Get("/:user_id/accounts", accountHandler.GetUserAccounts)
-	users.Get("/:user_id/wallets", walletHandler.GetUserWallets)
-	users.Get("/:user_id/transactions", transactionHandler.GetUserTransactions)
-	users.Get("/:user_id/exchanges", exchangeHandler.GetUserExchanges)
+	users := api.Group("/users", apiAuth)
+	users.Get("/:id", sessionOnly, self, userHandler.GetUser)
+	users.Put("/:id", sessionOnly, self, userHandler.UpdateUser)
+	users.Get("/:user_id/accounts", scope(models.ScopeReadBalances), owner, accountHandler.GetUserAccounts)
+	users.Get("/:user_id/wallets", scope(models.ScopeReadBalances), owner, walletHandler.GetUserWallets)
+	users.Get("/:user_id/wallets/reserves-proof", scope(models.ScopeReadBalances), owner, reservesHandler.GetUserProof)
+	users.Get("/:user_id/transactions", scope(models.ScopeReadBalances), owner, transactionHandler.GetUserTransactions)
+	users.Get("/:user_id/exchanges", scope(models.ScopeReadBalances), owner, exchangeHandler.GetUserExchanges)
+	users.Get("/:user_id/limits", scope(models.ScopeReadBalances), owner, limitHandler.GetUserLimits)
+	users.Get("/:user_id/approvals", sessionOnly, owner, approvalHandler.GetUserApprovals)
+	users.Get("/:user_id/approvals/pending", sessionOnly, owner, approvalHandler.GetPendingApprovals)
 
 	// Account routes
-	accounts := api.Group("/accounts")
-	accounts.Post("/", accountHandler.CreateAccount)
-	accounts.Get("/:id", accountHandler.GetAccount)
-	accounts.Get("/:id/balance", accountHandler.GetAccountBalance)
+	accounts := api.Group("/accounts", apiAuth)
+	accounts.Post("/", sessionOnly, accountHandler.CreateAccount)
+	accounts.Get("/:id", scope(models.ScopeReadBalances), accountHandler.GetAccount)
+	accounts.Get("/:id/balance", scope(models.ScopeReadBalances), accountHandler.GetAccountBalance)
+	accounts.Get("/:id/approval-policy", sessionOnly, approvalHandler.GetAccountPolicy)
+	accounts.Put("/:id/approval-policy", sessionOnly, stepUp, approvalHandler.SetAccountPolicy)
 
 	// Wallet routes
-	wallets := api.Group("/wallets")
-	wallets.Post("/", walletHandler.CreateWallet)
-	wallets.Get("/:id", walletHandler.GetWallet)
-	wallets.Get("/:id/balance", walletHandler.GetWalletBalance)
+	wallets := api.Group("/wallets", apiAuth)
+	wallets.Post("/", sessionOnly, walletHandler.CreateWallet)
+	wallets.Get("/:id", scope(models.ScopeReadBalances), walletHandler.GetWallet)
+	wallets.Get("/:id/balance", scope(models.ScopeReadBalances), walletHandler.GetWalletBalance)
+	wallets.Post("/:id/withdraw", scope(models.ScopeWithdraw), stepUp, walletHandler.WithdrawCrypto)
+	wallets.Get("/:id/whitelist", sessionOnly, walletHandler.GetWhitelist)
+	wallets.Post("/:id/whitelist", sessionOnly, stepUp, walletHandler.AddWhitelistedAddress)
+	wallets.Delete("/:id/whitelist/:entry_id", sessionOnly, stepUp, walletHandler.RemoveWhitelistedAddress)
+	wallets.Get("/:id/approval-policy", sessionOnly, approvalHandler.GetWalletPolicy)
+	wallets.Put("/:id/approval-policy", sessionOnly, stepUp, approvalHandler.SetWalletPolicy)
 
 	// Transaction routes
-	transactions := api.Group("/transactions")
-	transactions.Post("/transfer", transactionHandler.CreateTransfer)
-	transactions.Post("/deposit", transactionHandler.Deposit)
-	transactions.Post("/withdraw", transactionHandler.Withdraw)
-	transactions.Get("/:id", transactionHandler.GetTransaction)
+	transactions := api.Group("/transactions", apiAuth)
+	transactions.Post("/transfer", scope(models.ScopeTransfer), transactionHandler.CreateTransfer)
+	transactions.Post("/deposit", scope(models.ScopeTransfer), transactionHandler.Deposit)
+	transactions.Post("/withdraw", scope(models.ScopeWithdraw), stepUp, transactionHandler.Withdraw)
+	transactions.Get("/:id", scope(models.ScopeReadBalances), transactionHandler.GetTransaction)
 
 	// Exchange routes
-	exchanges := api.Group("/exchanges")
-	exchanges.Post("/crypto-to-fiat", exchangeHandler.ExchangeCryptoToFiat)
-	exchanges.Post("/fiat-to-crypto", exchangeHandler.ExchangeFiatToCrypto)
-	exchanges.Get("/:id", exchangeHandler.GetExchange)
+	exchanges := api.Group("/exchanges", apiAuth)
+	exchanges.Post("/crypto-to-fiat", scope(models.ScopeTrade), exchangeHandler.ExchangeCryptoToFiat)
+	exchanges.Post("/fiat-to-crypto", scope(models.ScopeTrade), exchangeHandler.ExchangeFiatToCrypto)
+	exchanges.Get("/:id", scope(models.ScopeReadBalances), exchangeHandler.GetExchange)
+
+	// Approval routes
+	approvals := api.Group("/approvals", requireAuth)
+	approvals.Get("/:id", approvalHandler.GetRequest)
+	approvals.Post("/:id/approve", approvalHandler.Approve)
+	approvals.Post("/:id/reject", approvalHandler.Reject)
+
+	// Admin routes, only reachable by staff roles
+	admin := app.Group("/admin/v1", requireAuth, middleware.RequireStaff())
+	can := middleware.RequirePermission
+
+	// User administration routes
+	adminUsers := admin.Group("/users")
+	adminUsers.Get("/", can(auth.PermUsersRead), adminHandler.GetUsers)
+	adminUsers.Get("/:id", can(auth.PermUsersRead), adminHandler.GetUser)
+	adminUsers.Get("/:id/accounts", can(auth.PermUsersRead), adminHandler.GetUserAccounts)
+	adminUsers.Get("/:id/wallets", can(auth.PermUsersRead), adminHandler.GetUserWallets)
+	adminUsers.Get("/:id/transactions", can(auth.PermUsersRead), adminHandler.GetUserTransactions)
+	adminUsers.Get("/:user_id/limits", can(auth.PermLimitsRead), limitHandler.GetUserLimits)
+	adminUsers.Put("/:id/role", can(auth.PermRolesManage), adminHandler.UpdateUserRole)
+	adminUsers.Delete("/:id", can(auth.PermUsersDelete), adminHandler.DeleteUser)
+
+	// KYC review routes
+	kycReview := admin.Group("/kyc")
+	kycReview.Get("/applications", can(auth.PermKYCRead), kycHandler.GetReviewQueue)
+	kycReview.Get("/applications/:id", can(auth.PermKYCRead), kycHandler.GetApplicationForReview)
+	kycReview.Get("/applications/:id/documents/:document_id", can(auth.PermKYCRead), kycHandler.GetDocument)
+	kycReview.Post("/applications/:id/approve", can(auth.PermKYCReview), kycHandler.ApproveApplication)
+	kycReview.Post("/applications/:id/reject", can(auth.PermKYCReview), kycHandler.RejectApplication)
+
+	// Limit rule routes
+	limits := admin.Group("/limits")
+	limits.Get("/", can(auth.PermLimitsRead), limitHandler.GetRules)
+	limits.Put("/", can(auth.PermLimitsManage), limitHandler.SetRule)
+	limits.Delete("/:id", can(auth.PermLimitsManage), limitHandler.DeleteRule)
+
+	// Sanctions screening review routes
+	screeningReview := admin.Group("/screening")
+	screeningReview.Get("/holds", can(auth.PermScreeningRead), screeningHandler.GetHolds)
+	screeningReview.Get("/holds/:id", can(auth.PermScreeningRead), screeningHandler.GetHold)
+	screeningReview.Post("/holds/:id/release", can(auth.PermScreeningReview), screeningHandler.ReleaseHold)
+	screeningReview.Post("/holds/:id/reject", can(auth.PermScreeningReview), screeningHandler.RejectHold)
+	screeningReview.Get("/watchlists", can(auth.PermScreeningRead), screeningHandler.GetWatchlists)
+	screeningReview.Post("/watchlists/reload", can(auth.PermScreeningReview), screeningHandler.ReloadWatchlists)
+
+	// Fraud scoring decision log
+	riskReview := admin.Group("/risk")
+	riskReview.Get("/decisions", can(auth.PermRiskRead), riskHandler.GetDecisions)
+
+	// Audit trail routes
+	audit := admin.Group("/audit")
+	audit.Get("/", can(auth.PermAuditRead), auditHandler.GetEntries)
+	audit.Get("/verify", can(auth.PermAuditRead), auditHandler.VerifyChain)
+	// The exchange service reports the rates admins set through it
+	audit.Post("/rates", can(auth.PermAssetsManage), auditHandler.RecordRateChange)
+
+	// Event store routes
+	eventStore := admin.Group("/events")
+	eventStore.Get("/", can(auth.PermEventsRead), eventHandler.GetEvents)
+	eventStore.Post("/replay", can(auth.PermEventsReplay), eventHandler.Replay)
+
+	// Exchange saga routes
+	sagas := admin.Group("/sagas")
+	sagas.Get("/:id", can(auth.PermSagasRead), sagaHandler.GetSaga)
+
+	// Asset registry routes
+	assets := admin.Group("/assets")
+	assets.Get("/", can(auth.PermAssetsRead), assetHandler.GetAllAssets)
+	assets.Post("/", can(auth.PermAssetsManage), assetHandler.CreateAsset)
+	assets.Get("/:code", can(auth.PermAssetsRead), assetHandler.GetAsset)
+	assets.Put("/:code", can(auth.PermAssetsManage), assetHandler.UpdateAsset)
+	assets.Post("/:code/enable", can(auth.PermAssetsManage), assetHandler.EnableAsset)
+	assets.Post("/:code/disable", can(auth.PermAssetsManage), assetHandler.DisableAsset)
+
+	// Treasury routes
+	treasury := admin.Group("/treasury")
+	treasury.Get("/wallets", can(auth.PermTreasuryRead), treasuryHandler.GetWallets)
+	treasury.Post("/wallets/:crypto_type/deposit", can(auth.PermTreasuryManage), treasuryHandler.Deposit)
+	treasury.Get("/policies", can(auth.PermTreasuryRead), treasuryHandler.GetPolicies)
+	treasury.Put("/policies/:crypto_type", can(auth.PermTreasuryManage), treasuryHandler.UpdatePolicy)
+	treasury.Get("/report", can(auth.PermTreasuryRead), treasuryHandler.GetReport)
+	treasury.Get("/movements", can(auth.PermTreasuryRead), treasuryHandler.GetMovements)
+	treasury.Post("/movements/sweep-check", can(auth.PermTreasuryManage), treasuryHandler.ProposeSweeps)
+	treasury.Post("/movements/:id/execute", can(auth.PermTreasuryManage), treasuryHandler.ExecuteMovement)
+	treasury.Post("/movements/:id/cancel", can(auth.PermTreasuryManage), treasuryHandler.CancelMovement)
+
+	// Proof-of-reserves routes
+	reserves := admin.Group("/reserves")
+	reserves.Post("/snapshots", can(auth.PermReservesManage), reservesHandler.CreateSnapshot)
+	reserves.Get("/snapshots", can(auth.PermReservesRead), reservesHandler.GetSnapshots)
+	reserves.Get("/snapshots/latest", can(auth.PermReservesRead), reservesHandler.GetLatestSnapshot)
+	reserves.Get("/snapshots/:id", can(auth.PermReservesRead), reservesHandler.GetSnapshot)
 
 	// Start server in goroutine
 	go func() {
@@ -188,3 +655,38 @@ func main() {
 
 	logger.Info("Server stopped")
 }
+
+// newKYCProvider builds the configured identity verification provider
+func newKYCProvider(cfg config.KYCConfig) (kyc.Provider, error) {
+	switch cfg.Provider {
+	case "fake":
+		logger.Warn("Using the fake KYC provider", zap.String("outcome", cfg.FakeOutcome))
+		return kyc.NewFakeProvider(kyc.Outcome(cfg.FakeOutcome))
+	}
+	return nil, fmt.Errorf("unknown KYC provider: %s", cfg.Provider)
+}
+
+// newRiskScorer returns the configured fraud scorer and a function releasing it
+func newRiskScorer(cfg config.RiskConfig) (risk.Scorer, func(), error) {
+	switch cfg.Scorer {
+	case "rules":
+		scorer, err := risk.NewRuleScorer(cfg.ChallengeScore, cfg.DenyScore)
+		return scorer, func() {}, err
+	case "grpc":
+		scorer, err := risk.NewGRPCScorer(cfg.ScorerAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("Using external risk scorer", zap.String("addr", cfg.ScorerAddr))
+		return scorer, func() { scorer.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown risk scorer: %s", cfg.Scorer)
+}
+
+func riskOperations(names []string) []models.RiskOperation {
+	operations := make([]models.RiskOperation, 0, len(names))
+	for _, name := range names {
+		operations = append(operations, models.RiskOperation(name))
+	}
+	return operations
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	apiKeyIDPrefix   = "ak_"
+	apiKeyIDSize     = 12
+	apiKeySecretSize = 32
+	secretKeySize    = 32
+)
+
+// GenerateAPIKey returns a new public key ID and signing secret
+func GenerateAPIKey() (string, string, error) {
+	id := make([]byte, apiKeyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key ID: %w", err)
+	}
+
+	secret := make([]byte, apiKeySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key secret: %w", err)
+	}
+
+	return apiKeyIDPrefix + hex.EncodeToString(id), base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// SignRequest computes the HMAC-SHA256 signature of an API request. Clients sign
+//
+//	timestamp \n nonce \n METHOD \n path?query \n hex(sha256(body))
+//
+// and send the hex encoded result in the X-API-Signature header.
+func SignRequest(secret, timestamp, nonce, method, path string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		path,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature checks a signature produced by SignRequest in constant time
+func VerifyRequestSignature(secret, signature, timestamp, nonce, method, path string, body []byte) bool {
+	expected := SignRequest(secret, timestamp, nonce, method, path, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// SecretBox encrypts API key secrets at rest with AES-256-GCM
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", secretKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts plaintext; the random nonce is prepended to the ciphertext
+func (b *SecretBox) Seal(plaintext string) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return b.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// Open decrypts a ciphertext produced by Seal
+func (b *SecretBox) Open(ciphertext []byte) (string, error) {
+	size := b.aead.NonceSize()
+	if len(ciphertext) < size {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := b.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// LoadOrGenerateSecretKey reads a base64 encoded 32-byte key from path, creating
+// the file with a random key when it does not exist yet. It reports whether
+// the key was generated.
+func LoadOrGenerateSecretKey(path string) ([]byte, bool, error) {
+	encoded, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decode secret key %s: %w", path, err)
+		}
+		return key, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("failed to read secret key %s: %w", path, err)
+	}
+
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, false, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, false, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return nil, false, fmt.Errorf("failed to write secret key %s: %w", path, err)
+	}
+
+	return key, true, nil
+}
+
+// SignedRequest is an API request authenticated with an API key signature
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+	IP        string
+}
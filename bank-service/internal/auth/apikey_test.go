@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSignRequest(t *testing.T) {
+	// Computed independently from the documented string to sign
+	const want = "80509df5d7f36a499dc23118358005fd2822324a35036255a3b89cd790ca67f0"
+
+	got := SignRequest("secret", "1700000000", "n-1", "post", "/api/v1/transactions?dry_run=true", []byte(`{"amount":100}`))
+	if got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyRequestSignature(t *testing.T) {
+	const (
+		secret    = "secret"
+		timestamp = "1700000000"
+		nonce     = "n-1"
+		method    = "POST"
+		path      = "/api/v1/transactions?dry_run=true"
+	)
+	body := []byte(`{"amount":100}`)
+	signature := SignRequest(secret, timestamp, nonce, method, path, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		nonce     string
+		method    string
+		path      string
+		body      []byte
+		want      bool
+	}{
+		{"valid", secret, signature, timestamp, nonce, method, path, body, true},
+		{"upper case signature", secret, strings.ToUpper(signature), timestamp, nonce, method, path, body, true},
+		{"lower case method", secret, signature, timestamp, nonce, "post", path, body, true},
+		{"other secret", "other", signature, timestamp, nonce, method, path, body, false},
+		{"other timestamp", secret, signature, "1700000001", nonce, method, path, body, false},
+		{"other nonce", secret, signature, timestamp, "n-2", method, path, body, false},
+		{"other method", secret, signature, timestamp, nonce, "PUT", path, body, false},
+		{"other path", secret, signature, timestamp, nonce, method, "/api/v1/transactions", body, false},
+		{"other body", secret, signature, timestamp, nonce, method, path, []byte(`{"amount":1000}`), false},
+		{"no body", secret, signature, timestamp, nonce, method, path, nil, false},
+		{"truncated signature", secret, signature[:63], timestamp, nonce, method, path, body, false},
+		{"empty signature", secret, "", timestamp, nonce, method, path, body, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyRequestSignature(tt.secret, tt.signature, tt.timestamp, tt.nonce, tt.method, tt.path, tt.body)
+			if got != tt.want {
+				t.Errorf("VerifyRequestSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	id, secret, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id, apiKeyIDPrefix) || len(id) != len(apiKeyIDPrefix)+2*apiKeyIDSize {
+		t.Errorf("key ID %q is malformed", id)
+	}
+	if len(secret) < apiKeySecretSize {
+		t.Errorf("secret %q is too short", secret)
+	}
+
+	otherID, otherSecret, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherID == id || otherSecret == secret {
+		t.Error("two keys are the same")
+	}
+}
+
+func TestSecretBox(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, secretKeySize)
+	box, err := NewSecretBox(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := box.Seal("api-key-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherBox, err := NewSecretBox(bytes.Repeat([]byte{2}, secretKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name       string
+		box        *SecretBox
+		ciphertext []byte
+		want       string
+		wantErr    bool
+	}{
+		{name: "sealed secret", box: box, ciphertext: sealed, want: "api-key-secret"},
+		{name: "other key", box: otherBox, ciphertext: sealed, wantErr: true},
+		{name: "tampered ciphertext", box: box, ciphertext: tampered, wantErr: true},
+		{name: "shorter than the nonce", box: box, ciphertext: sealed[:4], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.box.Open(tt.ciphertext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Open = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if again, _ := box.Seal("api-key-secret"); bytes.Equal(again, sealed) {
+		t.Error("sealing twice gave the same ciphertext")
+	}
+	if _, err := NewSecretBox(key[:16]); err == nil {
+		t.Error("accepted a 16 byte key")
+	}
+}
+
+func TestLoadOrGenerateSecretKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "secret.key")
+
+	key, generated, err := LoadOrGenerateSecretKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !generated || len(key) != secretKeySize {
+		t.Fatalf("generated = %v with %d bytes, want a new %d byte key", generated, len(key), secretKeySize)
+	}
+
+	loaded, generated, err := LoadOrGenerateSecretKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated || !bytes.Equal(loaded, key) {
+		t.Error("the stored key was not loaded again")
+	}
+}
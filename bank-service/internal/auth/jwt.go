@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// TokenType distinguishes short-lived access tokens from refresh tokens
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+const (
+	signingAlgorithm = "EdDSA"
+	// clockSkew tolerates small clock differences between instances
+	clockSkew = 30 * time.Second
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Claims is the payload of a signed token
+type Claims struct {
+	Issuer    string    `json:"iss"`
+	Subject   string    `json:"sub"`
+	ID        string    `json:"jti"`
+	TokenType TokenType `json:"token_type"`
+	IssuedAt  int64     `json:"iat"`
+	ExpiresAt int64     `json:"exp"`
+	// Role is carried by access tokens; role changes apply from the next login
+	Role models.Role `json:"role,omitempty"`
+	// StepUpUntil is set on access tokens issued after a second factor check
+	// and marks until when sensitive operations are allowed
+	StepUpUntil int64 `json:"step_up_until,omitempty"`
+}
+
+// SteppedUp reports whether the token carries a second factor check that is still fresh
+func (c *Claims) SteppedUp(now time.Time) bool {
+	return c.StepUpUntil > now.Unix()
+}
+
+// UserRole returns the role of the token holder. Tokens without a role belong to customers.
+func (c *Claims) UserRole() models.Role {
+	if c.Role == "" {
+		return models.RoleCustomer
+	}
+	return c.Role
+}
+
+// UserID returns the subject of the token as a user ID
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// TokenManager signs and verifies JWTs (RFC 7519) with the keys of a KeyStore
+type TokenManager struct {
+	keys   *KeyStore
+	issuer string
+}
+
+func NewTokenManager(keys *KeyStore, issuer string) *TokenManager {
+	return &TokenManager{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+// NewClaims returns the claims of a new token of the given type for a user
+func (m *TokenManager) NewClaims(userID uuid.UUID, tokenType TokenType, ttl time.Duration) *Claims {
+	now := time.Now()
+	return &Claims{
+		Issuer:    m.issuer,
+		Subject:   userID.String(),
+		ID:        uuid.New().String(),
+		TokenType: tokenType,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+}
+
+// Issue signs a token of the given type for a user
+func (m *TokenManager) Issue(userID uuid.UUID, tokenType TokenType, ttl time.Duration) (string, *Claims, error) {
+	claims := m.NewClaims(userID, tokenType, ttl)
+
+	token, err := m.Sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Sign encodes and signs claims with the active key
+func (m *TokenManager) Sign(claims *Claims) (string, error) {
+	kid, key, err := m.keys.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	headerJSON, err := json.Marshal(header{Algorithm: signingAlgorithm, Type: "JWT", KeyID: kid})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token header: %w", err)
+	}
+
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signingInput := encodeSegment(headerJSON) + "." + encodeSegment(claimsJSON)
+	signature := ed25519.Sign(key, []byte(signingInput))
+
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+// Verify checks the signature, issuer, type and lifetime of a token
+func (m *TokenManager) Verify(token string, tokenType TokenType) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if h.Algorithm != signingAlgorithm {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := m.keys.verificationKey(h.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Issuer != m.issuer || claims.TokenType != tokenType || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) {
+		return nil, ErrExpiredToken
+	}
+	if now.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
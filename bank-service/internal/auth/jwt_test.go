@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testIssuer = "crypto-bank"
+
+// newTestKeys generates a key under each ID into one directory
+func newTestKeys(t *testing.T, kids ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, kid := range kids {
+		scratch := t.TempDir()
+		generated, err := GenerateKey(scratch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(scratch, generated+keyFileExt), filepath.Join(dir, kid+keyFileExt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newTestTokenManager(t *testing.T, dir, activeKID string) *TokenManager {
+	t.Helper()
+
+	keys := NewKeyStore(dir, activeKID)
+	if err := keys.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenManager(keys, testIssuer)
+}
+
+func TestVerify(t *testing.T) {
+	dir := newTestKeys(t, "current", "other")
+	manager := newTestTokenManager(t, dir, "current")
+	// The same key ID and issuer on keys the verifier does not hold
+	stranger := newTestTokenManager(t, newTestKeys(t, "current"), "current")
+
+	now := time.Now()
+	claims := func(change func(c *Claims)) *Claims {
+		c := manager.NewClaims(uuid.New(), TokenTypeAccess, 15*time.Minute)
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	sign := func(m *TokenManager, c *Claims) string {
+		token, err := m.Sign(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	// resign replaces the header of a token and signs it with the active key
+	resign := func(token string, h header) string {
+		_, key, err := manager.keys.signingKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		headerJSON, _ := json.Marshal(h)
+		input := encodeSegment(headerJSON) + "." + strings.Split(token, ".")[1]
+		return input + "." + encodeSegment(ed25519.Sign(key, []byte(input)))
+	}
+	valid := sign(manager, claims(nil))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name      string
+		token     string
+		tokenType TokenType
+		wantErr   error
+	}{
+		{name: "valid", token: valid, tokenType: TokenTypeAccess},
+		{name: "signed by a retired key still loaded", token: sign(newTestTokenManager(t, dir, "other"), claims(nil)), tokenType: TokenTypeAccess},
+		{name: "refresh token used as access token", token: sign(manager, claims(func(c *Claims) { c.TokenType = TokenTypeRefresh })), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "access token used as refresh token", token: valid, tokenType: TokenTypeRefresh, wantErr: ErrInvalidToken},
+		{name: "other issuer", token: sign(manager, claims(func(c *Claims) { c.Issuer = "elsewhere" })), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "no token ID", token: sign(manager, claims(func(c *Claims) { c.ID = "" })), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "subject not a user ID", token: sign(manager, claims(func(c *Claims) { c.Subject = "admin" })), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "expired within the clock skew", token: sign(manager, claims(func(c *Claims) { c.ExpiresAt = now.Add(-clockSkew / 2).Unix() })), tokenType: TokenTypeAccess},
+		{name: "expired", token: sign(manager, claims(func(c *Claims) { c.ExpiresAt = now.Add(-2 * clockSkew).Unix() })), tokenType: TokenTypeAccess, wantErr: ErrExpiredToken},
+		{name: "issued in the future", token: sign(manager, claims(func(c *Claims) { c.IssuedAt = now.Add(2 * clockSkew).Unix() })), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "signed by an unknown key", token: sign(stranger, claims(nil)), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "unknown key ID", token: resign(valid, header{Algorithm: signingAlgorithm, Type: "JWT", KeyID: "missing"}), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "other algorithm", token: resign(valid, header{Algorithm: "HS256", Type: "JWT", KeyID: "current"}), tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "payload swapped", token: parts[0] + "." + strings.Split(sign(manager, claims(nil)), ".")[1] + "." + parts[2], tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "signature stripped", token: parts[0] + "." + parts[1] + ".", tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "signature not base64", token: parts[0] + "." + parts[1] + ".!!", tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "two segments", token: parts[0] + "." + parts[1], tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+		{name: "empty", token: "", tokenType: TokenTypeAccess, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.Verify(tt.token, tt.tokenType)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIssueRoundTrip(t *testing.T) {
+	manager := newTestTokenManager(t, newTestKeys(t, "current"), "")
+	userID := uuid.New()
+
+	token, issued, err := manager.Issue(userID, TokenTypeRefresh, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := manager.Verify(token, TokenTypeRefresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *claims != *issued {
+		t.Errorf("claims = %+v, want %+v", claims, issued)
+	}
+	if got, err := claims.UserID(); err != nil || got != userID {
+		t.Errorf("UserID = %v, %v, want %v", got, err, userID)
+	}
+}
+
+func TestClaims(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		claims    Claims
+		role      string
+		steppedUp bool
+	}{
+		{name: "customer without a role", claims: Claims{}, role: "customer"},
+		{name: "admin", claims: Claims{Role: "admin"}, role: "admin"},
+		{name: "fresh step up", claims: Claims{StepUpUntil: now.Add(time.Minute).Unix()}, role: "customer", steppedUp: true},
+		{name: "lapsed step up", claims: Claims{StepUpUntil: now.Add(-time.Minute).Unix()}, role: "customer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.claims.UserRole()); got != tt.role {
+				t.Errorf("UserRole = %s, want %s", got, tt.role)
+			}
+			if got := tt.claims.SteppedUp(now); got != tt.steppedUp {
+				t.Errorf("SteppedUp = %v, want %v", got, tt.steppedUp)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyFileExt = ".pem"
+
+// KeyStore holds the Ed25519 keys used to sign and verify tokens. Every
+// "<kid>.pem" file in the key directory is a PKCS#8 private key whose file
+// name is its key ID.
+//
+// Keys are rotated by adding a new key file: after the next Reload it signs new
+// tokens (it is the newest one unless an active key ID is configured) while the
+// old keys keep verifying tokens already issued. Old key files can be removed
+// once the refresh token TTL has passed.
+type KeyStore struct {
+	dir       string
+	activeKID string
+
+	mu     sync.RWMutex
+	keys   map[string]ed25519.PrivateKey
+	active string
+}
+
+func NewKeyStore(dir, activeKID string) *KeyStore {
+	return &KeyStore{
+		dir:       dir,
+		activeKID: activeKID,
+		keys:      make(map[string]ed25519.PrivateKey),
+	}
+}
+
+// Reload reads the key directory again
+func (s *KeyStore) Reload() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	keys := make(map[string]ed25519.PrivateKey)
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), keyFileExt)
+		key, err := readKey(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("key %s: %w", kid, err)
+		}
+		keys[kid] = key
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("key %s: %w", kid, err)
+		}
+		// Ties are broken by key ID so every instance picks the same key
+		if newest == "" || info.ModTime().After(newestTime) ||
+			(info.ModTime().Equal(newestTime) && kid > newest) {
+			newest, newestTime = kid, info.ModTime()
+		}
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("no signing keys found in %s", s.dir)
+	}
+
+	active := newest
+	if s.activeKID != "" {
+		if _, ok := keys[s.activeKID]; !ok {
+			return fmt.Errorf("active key %s not found in %s", s.activeKID, s.dir)
+		}
+		active = s.activeKID
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.active = active
+	s.mu.Unlock()
+
+	return nil
+}
+
+// LoadOrGenerate loads the key directory, generating a first key if it holds
+// none. It returns the ID of the generated key, if any.
+func (s *KeyStore) LoadOrGenerate() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*"+keyFileExt))
+	if err != nil {
+		return "", err
+	}
+
+	var generated string
+	if len(matches) == 0 {
+		if generated, err = GenerateKey(s.dir); err != nil {
+			return "", err
+		}
+	}
+
+	return generated, s.Reload()
+}
+
+// KeyIDs returns the IDs of the loaded keys
+func (s *KeyStore) KeyIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ActiveKeyID returns the ID of the key that signs new tokens
+func (s *KeyStore) ActiveKeyID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+// JWK is the public part of a signing key (RFC 8037)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517)
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys returns the verification keys so other services can check tokens
+func (s *KeyStore) PublicKeys() *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	for _, kid := range s.KeyIDs() {
+		key, ok := s.verificationKey(kid)
+		if !ok {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(key),
+			KeyID:     kid,
+			Algorithm: signingAlgorithm,
+			Use:       "sig",
+		})
+	}
+	return set
+}
+
+func (s *KeyStore) signingKey() (string, ed25519.PrivateKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[s.active]
+	if !ok {
+		return "", nil, errors.New("no signing key loaded")
+	}
+	return s.active, key, nil
+}
+
+func (s *KeyStore) verificationKey(kid string) (ed25519.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, false
+	}
+	return key.Public().(ed25519.PublicKey), true
+}
+
+// GenerateKey writes a new signing key to dir and returns its key ID
+func GenerateKey(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	path := filepath.Join(dir, kid+keyFileExt)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create key file: %w", err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return "", fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return kid, nil
+}
+
+func readKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("expected a PEM encoded PKCS#8 private key")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an Ed25519 private key")
+	}
+	return key, nil
+}
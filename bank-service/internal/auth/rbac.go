@@ -0,0 +1,95 @@
+package auth
+
+import "github.com/crypto-bank/bank-service/internal/models"
+
+// Permission names an operation on the admin API
+type Permission string
+
+const (
+	PermUsersRead       Permission = "users:read"
+	PermUsersReadPII    Permission = "users:read_pii"
+	PermUsersDelete     Permission = "users:delete"
+	PermRolesManage     Permission = "roles:manage"
+	PermAssetsRead      Permission = "assets:read"
+	PermAssetsManage    Permission = "assets:manage"
+	PermTreasuryRead    Permission = "treasury:read"
+	PermTreasuryManage  Permission = "treasury:manage"
+	PermReservesRead    Permission = "reserves:read"
+	PermReservesManage  Permission = "reserves:manage"
+	PermKYCRead         Permission = "kyc:read"
+	PermKYCReview       Permission = "kyc:review"
+	PermLimitsRead      Permission = "limits:read"
+	PermLimitsManage    Permission = "limits:manage"
+	PermScreeningRead   Permission = "screening:read"
+	PermScreeningReview Permission = "screening:review"
+	PermRiskRead        Permission = "risk:read"
+	PermAuditRead       Permission = "audit:read"
+	PermEventsRead      Permission = "events:read"
+	PermEventsReplay    Permission = "events:replay"
+	PermSagasRead       Permission = "sagas:read"
+)
+
+// rolePermissions grants permissions to the operator roles. Customers have none:
+// they only reach their own records through the customer API.
+var rolePermissions = map[models.Role][]Permission{
+	models.RoleSupport: {
+		PermUsersRead,
+		PermAssetsRead,
+		PermLimitsRead,
+	},
+	models.RoleCompliance: {
+		PermUsersRead,
+		PermUsersReadPII,
+		PermAssetsRead,
+		PermTreasuryRead,
+		PermReservesRead,
+		PermKYCRead,
+		PermKYCReview,
+		PermLimitsRead,
+		PermLimitsManage,
+		PermScreeningRead,
+		PermScreeningReview,
+		PermRiskRead,
+		PermAuditRead,
+		PermEventsRead,
+		PermSagasRead,
+	},
+	models.RoleAdmin: {
+		PermUsersRead,
+		PermUsersReadPII,
+		PermUsersDelete,
+		PermRolesManage,
+		PermAssetsRead,
+		PermAssetsManage,
+		PermTreasuryRead,
+		PermTreasuryManage,
+		PermReservesRead,
+		PermReservesManage,
+		PermKYCRead,
+		PermKYCReview,
+		PermLimitsRead,
+		PermLimitsManage,
+		PermScreeningRead,
+		PermScreeningReview,
+		PermRiskRead,
+		PermAuditRead,
+		PermEventsRead,
+		PermEventsReplay,
+		PermSagasRead,
+	},
+}
+
+// Can reports whether a role grants a permission
+func Can(role models.Role, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether a role may use the admin API at all
+func IsStaff(role models.Role) bool {
+	return len(rolePermissions[role]) > 0
+}
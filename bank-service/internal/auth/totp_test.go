@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTPVectors(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key, err := base32NoPadding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if got := hotp(key, tt.unix/totpPeriod); got != tt.code {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+	key, err := base32NoPadding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codeAt := func(offset int64) string { return hotp(key, step+offset) }
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfc6238Secret, code: codeAt(0), wantStep: step, wantOK: true},
+		{name: "previous step", secret: rfc6238Secret, code: codeAt(-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", secret: rfc6238Secret, code: codeAt(1), wantStep: step + 1, wantOK: true},
+		{name: "two steps behind", secret: rfc6238Secret, code: codeAt(-2)},
+		{name: "two steps ahead", secret: rfc6238Secret, code: codeAt(2)},
+		{name: "surrounding spaces", secret: rfc6238Secret, code: " " + codeAt(0) + "\n", wantStep: step, wantOK: true},
+		{name: "lower case secret", secret: strings.ToLower(rfc6238Secret), code: codeAt(0), wantStep: step, wantOK: true},
+		{name: "too short", secret: rfc6238Secret, code: codeAt(0)[:5]},
+		{name: "too long", secret: rfc6238Secret, code: codeAt(0) + "0"},
+		{name: "empty", secret: rfc6238Secret, code: ""},
+		{name: "secret not base32", secret: "not-base32!", code: codeAt(0)},
+		{name: "other secret", secret: "JBSWY3DPEHPK3PXP", code: codeAt(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret is not base32: %v", err)
+	}
+	if len(key) != totpSecretSize {
+		t.Errorf("secret holds %d bytes, want %d", len(key), totpSecretSize)
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, hotp(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("code of a generated secret was refused")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not formatted as xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+
+	hash := HashRecoveryCode("abcde-12345")
+	for _, typed := range []string{"ABCDE-12345", " abcde12345 ", "abcde-12345\n"} {
+		if got := HashRecoveryCode(typed); got != hash {
+			t.Errorf("%q hashes differently from the issued code", typed)
+		}
+	}
+	if HashRecoveryCode("abcde-12346") == hash {
+		t.Error("different codes hash the same")
+	}
+}
@@ -3,14 +3,29 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	RabbitMQ RabbitMQConfig
-	GRPC     GRPCConfig
-	Zipkin   ZipkinConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	RabbitMQ  RabbitMQConfig
+	GRPC      GRPCConfig
+	Zipkin    ZipkinConfig
+	Approval  ApprovalConfig
+	Treasury  TreasuryConfig
+	Reserves  ReservesConfig
+	Assets    AssetsConfig
+	Auth      AuthConfig
+	APIKeys   APIKeysConfig
+	KYC       KYCConfig
+	Screening ScreeningConfig
+	Risk      RiskConfig
+	Outbox    OutboxConfig
+	Events    EventStoreConfig
+	Saga      SagaConfig
 }
 
 type ServerConfig struct {
@@ -28,10 +43,13 @@ type DatabaseConfig struct {
 }
 
 type RabbitMQConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
+	Host              string
+	Port              string
+	User              string
+	Password          string
+	PublisherChannels int
+	ReconnectMinDelay time.Duration
+	ReconnectMaxDelay time.Duration
 }
 
 type GRPCConfig struct {
@@ -42,6 +60,112 @@ type ZipkinConfig struct {
 	Endpoint string
 }
 
+type ApprovalConfig struct {
+	ExpiryInterval time.Duration
+}
+
+type TreasuryConfig struct {
+	SweepInterval time.Duration
+}
+
+type ReservesConfig struct {
+	SnapshotInterval time.Duration
+}
+
+type AssetsConfig struct {
+	RefreshInterval time.Duration
+}
+
+type AuthConfig struct {
+	KeysDir           string
+	ActiveKeyID       string
+	Issuer            string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	KeyReloadInterval time.Duration
+	MaxFailedLogins   int
+	LockoutDuration   time.Duration
+	TOTPIssuer        string
+	StepUpWindow      time.Duration
+	// StepUpTransferThresholdUSD is the transfer value from which a second factor is required
+	StepUpTransferThresholdUSD float64
+}
+
+type APIKeysConfig struct {
+	EncryptionKeyFile string
+	// MaxClockSkew is how far a signed request timestamp may be from the server clock
+	MaxClockSkew   time.Duration
+	MaxKeysPerUser int
+}
+
+type KYCConfig struct {
+	// Provider selects the identity verification provider; only "fake" ships
+	Provider        string
+	ProviderTimeout time.Duration
+	// FakeOutcome is what the fake provider answers: approve, reject or review
+	FakeOutcome string
+}
+
+type ScreeningConfig struct {
+	// WatchlistsDir holds the sanctions lists and blocked address files
+	WatchlistsDir string
+	// MatchThreshold is the name similarity, between 0 and 1, that counts as a hit
+	MatchThreshold float64
+	ReloadInterval time.Duration
+}
+
+type RiskConfig struct {
+	// Scorer selects the fraud scorer: the built-in "rules" or an external "grpc" model
+	Scorer     string
+	ScorerAddr string
+	Timeout    time.Duration
+	// ChallengeScore and DenyScore are the rule scorer thresholds, out of 100
+	ChallengeScore float64
+	DenyScore      float64
+	// FailClosedOperations are denied when the scorer fails; all others are allowed
+	FailClosedOperations []string
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// ClaimTimeout is how long a relay owns a claimed batch before other
+	// instances may pick the messages up again
+	ClaimTimeout   time.Duration
+	PublishTimeout time.Duration
+	// RetryBaseDelay doubles with every failed attempt up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Retention is how long published messages are kept before they are purged
+	Retention     time.Duration
+	PurgeInterval time.Duration
+	// ContentMode is the CloudEvents content mode, binary or structured
+	ContentMode string
+}
+
+// EventStoreConfig configures replays of stored events. Replays publish with
+// the outbox timeout and content mode.
+type EventStoreConfig struct {
+	ReplayBatchSize int
+	// ReplayMaxEvents caps the events one replay request re-publishes
+	ReplayMaxEvents int
+}
+
+// SagaConfig configures exchange sagas and their recovery worker
+type SagaConfig struct {
+	// QuoteTTL is how long a locked quote holds
+	QuoteTTL time.Duration
+	// Lease is how long the instance running a saga owns it before the recovery
+	// worker of any instance may resume it
+	Lease       time.Duration
+	StepTimeout time.Duration
+	// RetryBaseDelay doubles with every failed attempt up to RetryMaxDelay
+	RetryBaseDelay    time.Duration
+	RetryMaxDelay     time.Duration
+	RecoveryInterval  time.Duration
+	RecoveryBatchSize int
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
@@ -58,10 +182,13 @@ func LoadConfig() *Config {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		RabbitMQ: RabbitMQConfig{
-			Host:     getEnv("RABBITMQ_HOST", "localhost"),
-			Port:     getEnv("RABBITMQ_PORT", "5672"),
-			User:     getEnv("RABBITMQ_USER", "guest"),
-			Password: getEnv("RABBITMQ_PASS", "guest"),
+			Host:              getEnv("RABBITMQ_HOST", "localhost"),
+			Port:              getEnv("RABBITMQ_PORT", "5672"),
+			User:              getEnv("RABBITMQ_USER", "guest"),
+			Password:          getEnv("RABBITMQ_PASS", "guest"),
+			PublisherChannels: getEnvInt("RABBITMQ_PUBLISHER_CHANNELS", 4),
+			ReconnectMinDelay: getEnvDuration("RABBITMQ_RECONNECT_MIN_DELAY", time.Second),
+			ReconnectMaxDelay: getEnvDuration("RABBITMQ_RECONNECT_MAX_DELAY", 30*time.Second),
 		},
 		GRPC: GRPCConfig{
 			ExchangeServiceAddr: getEnv("EXCHANGE_SERVICE_ADDR", "localhost:9090"),
@@ -69,6 +196,79 @@ func LoadConfig() *Config {
 		Zipkin: ZipkinConfig{
 			Endpoint: getEnv("ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
 		},
+		Approval: ApprovalConfig{
+			ExpiryInterval: getEnvDuration("APPROVAL_EXPIRY_INTERVAL", time.Minute),
+		},
+		Treasury: TreasuryConfig{
+			SweepInterval: getEnvDuration("TREASURY_SWEEP_INTERVAL", 5*time.Minute),
+		},
+		Reserves: ReservesConfig{
+			SnapshotInterval: getEnvDuration("RESERVES_SNAPSHOT_INTERVAL", 24*time.Hour),
+		},
+		Assets: AssetsConfig{
+			RefreshInterval: getEnvDuration("ASSET_REFRESH_INTERVAL", time.Minute),
+		},
+		Auth: AuthConfig{
+			KeysDir:           getEnv("AUTH_KEYS_DIR", "./keys"),
+			ActiveKeyID:       getEnv("AUTH_ACTIVE_KEY_ID", ""),
+			Issuer:            getEnv("AUTH_ISSUER", "crypto-bank"),
+			AccessTokenTTL:    getEnvDuration("AUTH_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:   getEnvDuration("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			KeyReloadInterval: getEnvDuration("AUTH_KEY_RELOAD_INTERVAL", time.Minute),
+			MaxFailedLogins:   getEnvInt("AUTH_MAX_FAILED_LOGINS", 5),
+			LockoutDuration:   getEnvDuration("AUTH_LOCKOUT_DURATION", 15*time.Minute),
+			TOTPIssuer:        getEnv("AUTH_TOTP_ISSUER", "Crypto Bank"),
+			StepUpWindow:      getEnvDuration("AUTH_STEP_UP_WINDOW", 5*time.Minute),
+
+			StepUpTransferThresholdUSD: getEnvFloat("AUTH_STEP_UP_TRANSFER_THRESHOLD_USD", 1000),
+		},
+		APIKeys: APIKeysConfig{
+			EncryptionKeyFile: getEnv("API_KEY_ENCRYPTION_KEY_FILE", "./keys/api-keys.secret"),
+			MaxClockSkew:      getEnvDuration("API_KEY_MAX_CLOCK_SKEW", 5*time.Minute),
+			MaxKeysPerUser:    getEnvInt("API_KEY_MAX_KEYS_PER_USER", 10),
+		},
+		KYC: KYCConfig{
+			Provider:        getEnv("KYC_PROVIDER", "fake"),
+			ProviderTimeout: getEnvDuration("KYC_PROVIDER_TIMEOUT", 30*time.Second),
+			FakeOutcome:     getEnv("KYC_FAKE_OUTCOME", "approve"),
+		},
+		Screening: ScreeningConfig{
+			WatchlistsDir:  getEnv("SCREENING_WATCHLISTS_DIR", "./watchlists"),
+			MatchThreshold: getEnvFloat("SCREENING_MATCH_THRESHOLD", 0.9),
+			ReloadInterval: getEnvDuration("SCREENING_RELOAD_INTERVAL", time.Hour),
+		},
+		Risk: RiskConfig{
+			Scorer:               getEnv("RISK_SCORER", "rules"),
+			ScorerAddr:           getEnv("RISK_SCORER_ADDR", "localhost:50052"),
+			Timeout:              getEnvDuration("RISK_SCORER_TIMEOUT", 500*time.Millisecond),
+			ChallengeScore:       getEnvFloat("RISK_CHALLENGE_SCORE", 40),
+			DenyScore:            getEnvFloat("RISK_DENY_SCORE", 80),
+			FailClosedOperations: getEnvList("RISK_FAIL_CLOSED_OPERATIONS", []string{"crypto_withdraw"}),
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:      getEnvInt("OUTBOX_BATCH_SIZE", 100),
+			ClaimTimeout:   getEnvDuration("OUTBOX_CLAIM_TIMEOUT", time.Minute),
+			PublishTimeout: getEnvDuration("OUTBOX_PUBLISH_TIMEOUT", 5*time.Second),
+			RetryBaseDelay: getEnvDuration("OUTBOX_RETRY_BASE_DELAY", time.Second),
+			RetryMaxDelay:  getEnvDuration("OUTBOX_RETRY_MAX_DELAY", 5*time.Minute),
+			Retention:      getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+			PurgeInterval:  getEnvDuration("OUTBOX_PURGE_INTERVAL", time.Hour),
+			ContentMode:    getEnv("OUTBOX_CONTENT_MODE", "binary"),
+		},
+		Events: EventStoreConfig{
+			ReplayBatchSize: getEnvInt("EVENT_REPLAY_BATCH_SIZE", 500),
+			ReplayMaxEvents: getEnvInt("EVENT_REPLAY_MAX_EVENTS", 10000),
+		},
+		Saga: SagaConfig{
+			QuoteTTL:          getEnvDuration("SAGA_QUOTE_TTL", 30*time.Second),
+			Lease:             getEnvDuration("SAGA_LEASE", time.Minute),
+			StepTimeout:       getEnvDuration("SAGA_STEP_TIMEOUT", 10*time.Second),
+			RetryBaseDelay:    getEnvDuration("SAGA_RETRY_BASE_DELAY", time.Second),
+			RetryMaxDelay:     getEnvDuration("SAGA_RETRY_MAX_DELAY", 5*time.Minute),
+			RecoveryInterval:  getEnvDuration("SAGA_RECOVERY_INTERVAL", 15*time.Second),
+			RecoveryBatchSize: getEnvInt("SAGA_RECOVERY_BATCH_SIZE", 50),
+		},
 	}
 }
 
@@ -96,3 +296,42 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList reads a comma separated list. A variable that is set but empty
+// gives an empty list.
+func getEnvList(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
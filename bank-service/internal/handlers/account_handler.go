@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/crypto-bank/bank-service/internal/middleware"
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/services"
 	"github.com/crypto-bank/bank-service/pkg/response"
@@ -11,11 +12,13 @@ import (
 
 type AccountHandler struct {
 	accountService *services.AccountService
+	audit          *services.AuditService
 }
 
-func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+func NewAccountHandler(accountService *services.AccountService, audit *services.AuditService) *AccountHandler {
 	return &AccountHandler{
 		accountService: accountService,
+		audit:          audit,
 	}
 }
 
@@ -32,16 +35,18 @@ func (h *AccountHandler) CreateAccount(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	account, err := h.accountService.CreateAccount(&req)
+	account, err := h.accountService.CreateAccount(c.UserContext(), &req)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to create account", err)
 	}
 
+	h.audit.Record(auditOrigin(c), models.AuditAccountCreated, models.AuditEntityAccount, account.ID.String(), nil, account)
 	return response.Created(c, account, "Account created successfully")
 }
 
@@ -63,6 +68,10 @@ func (h *AccountHandler) GetAccount(c *fiber.Ctx) error {
 		return response.NotFound(c, "Account not found")
 	}
 
+	if account.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
 	return response.Success(c, account, "")
 }
 
@@ -100,11 +109,15 @@ func (h *AccountHandler) GetAccountBalance(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid account ID", err)
 	}
 
-	balance, err := h.accountService.GetAccountBalance(id)
+	account, err := h.accountService.GetAccount(id)
 	if err != nil {
 		return response.NotFound(c, "Account not found")
 	}
 
-	return response.Success(c, map[string]interface{}{"balance": balance}, "")
+	if account.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
+	return response.Success(c, map[string]interface{}{"balance": account.Balance}, "")
 }
 
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AdminHandler serves the operator views of customer records. Staff without
+// access to personal data see users with masked contact details.
+type AdminHandler struct {
+	userService        *services.UserService
+	authService        *services.AuthService
+	accountService     *services.AccountService
+	walletService      *services.CryptoWalletService
+	transactionService *services.TransactionService
+	audit              *services.AuditService
+}
+
+func NewAdminHandler(
+	userService *services.UserService,
+	authService *services.AuthService,
+	accountService *services.AccountService,
+	walletService *services.CryptoWalletService,
+	transactionService *services.TransactionService,
+	audit *services.AuditService,
+) *AdminHandler {
+	return &AdminHandler{
+		userService:        userService,
+		authService:        authService,
+		accountService:     accountService,
+		walletService:      walletService,
+		transactionService: transactionService,
+		audit:              audit,
+	}
+}
+
+// GetUsers godoc
+// @Summary Get all users
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.User}
+// @Router /admin/v1/users [get]
+func (h *AdminHandler) GetUsers(c *fiber.Ctx) error {
+	users, err := h.userService.GetAllUsers()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get users", err)
+	}
+
+	if !auth.Can(middleware.Role(c), auth.PermUsersReadPII) {
+		for i, user := range users {
+			users[i] = user.Masked()
+		}
+	}
+
+	return response.Success(c, users, "")
+}
+
+// GetUser godoc
+// @Summary Get a user
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=models.User}
+// @Router /admin/v1/users/{id} [get]
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	user, err := h.userService.GetUser(id)
+	if err != nil {
+		return response.NotFound(c, "User not found")
+	}
+
+	if !auth.Can(middleware.Role(c), auth.PermUsersReadPII) {
+		user = user.Masked()
+	}
+
+	return response.Success(c, user, "")
+}
+
+// GetUserAccounts godoc
+// @Summary Get the accounts of a user
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.Account}
+// @Router /admin/v1/users/{id}/accounts [get]
+func (h *AdminHandler) GetUserAccounts(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	accounts, err := h.accountService.GetUserAccounts(id)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get accounts", err)
+	}
+
+	return response.Success(c, accounts, "")
+}
+
+// GetUserWallets godoc
+// @Summary Get the wallets of a user
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.CryptoWallet}
+// @Router /admin/v1/users/{id}/wallets [get]
+func (h *AdminHandler) GetUserWallets(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	wallets, err := h.walletService.GetUserWallets(id)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get wallets", err)
+	}
+
+	return response.Success(c, wallets, "")
+}
+
+// GetUserTransactions godoc
+// @Summary Get the transactions of a user
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.Transaction}
+// @Router /admin/v1/users/{id}/transactions [get]
+func (h *AdminHandler) GetUserTransactions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	transactions, err := h.transactionService.GetUserTransactions(id)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get transactions", err)
+	}
+
+	return response.Success(c, transactions, "")
+}
+
+// UpdateUserRole godoc
+// @Summary Change the role of a user and close their sessions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param role body models.UpdateUserRoleRequest true "Role"
+// @Success 200 {object} response.Response
+// @Router /admin/v1/users/{id}/role [put]
+func (h *AdminHandler) UpdateUserRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	// Keeps admins from locking themselves out of the admin API
+	if id == middleware.UserID(c) {
+		return response.Forbidden(c, "Cannot change your own role")
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	before, err := h.userService.GetUser(id)
+	if err != nil {
+		return response.NotFound(c, "User not found")
+	}
+
+	if err := h.userService.UpdateRole(c.UserContext(), id, req.Role); err != nil {
+		return response.NotFound(c, "User not found")
+	}
+
+	if after, err := h.userService.GetUser(id); err == nil {
+		h.audit.Record(auditOrigin(c), models.AuditUserRoleChanged, models.AuditEntityUser, id.String(), before, after)
+	}
+
+	// Access tokens carry the role, so the new role applies from the next login
+	if err := h.authService.LogoutAll(id); err != nil {
+		return response.InternalServerError(c, "Failed to close sessions", err)
+	}
+
+	return response.Success(c, nil, "User role updated")
+}
+
+// DeleteUser godoc
+// @Summary Delete a user and close their sessions
+// @Tags admin
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response
+// @Router /admin/v1/users/{id} [delete]
+func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	before, err := h.userService.GetUser(id)
+	if err != nil {
+		return response.NotFound(c, "User not found")
+	}
+
+	if err := h.userService.DeleteUser(c.UserContext(), id); err != nil {
+		return response.InternalServerError(c, "Failed to delete user", err)
+	}
+	h.audit.Record(auditOrigin(c), models.AuditUserDeleted, models.AuditEntityUser, id.String(), before, nil)
+
+	if err := h.authService.LogoutAll(id); err != nil {
+		return response.InternalServerError(c, "Failed to close sessions", err)
+	}
+
+	return response.Success(c, nil, "User deleted successfully")
+}
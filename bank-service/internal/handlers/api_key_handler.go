@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	audit         *services.AuditService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, audit *services.AuditService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		audit:         audit,
+	}
+}
+
+// CreateKey godoc
+// @Summary Create an API key; the signing secret is only returned once
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body models.CreateAPIKeyRequest true "Key data"
+// @Success 201 {object} response.Response{data=models.CreatedAPIKey}
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) CreateKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	// Keys that move money act without a second factor, so creating them needs one
+	for _, scope := range req.Scopes {
+		if (scope == models.ScopeTransfer || scope == models.ScopeWithdraw) && !middleware.SteppedUp(c) {
+			return middleware.StepUpChallenge(c)
+		}
+	}
+
+	key, err := h.apiKeyService.CreateKey(middleware.UserID(c), &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to create API key", err)
+	}
+
+	// The secret is left out of the trail
+	h.audit.Record(auditOrigin(c), models.AuditAPIKeyCreated, models.AuditEntityAPIKey, key.ID.String(), nil, key.APIKey)
+	return response.Created(c, key, "API key created, store the secret now")
+}
+
+// GetKeys godoc
+// @Summary Get the API keys of the authenticated user
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.APIKey}
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) GetKeys(c *fiber.Ctx) error {
+	keys, err := h.apiKeyService.GetUserKeys(middleware.UserID(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get API keys", err)
+	}
+
+	return response.Success(c, keys, "")
+}
+
+// RevokeKey godoc
+// @Summary Revoke an API key
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.Response
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid API key ID", err)
+	}
+
+	if err := h.apiKeyService.RevokeKey(middleware.UserID(c), id); err != nil {
+		return response.NotFound(c, "API key not found")
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditAPIKeyRevoked, models.AuditEntityAPIKey, id.String(), nil, nil)
+	return response.Success(c, nil, "API key revoked")
+}
+
+// GetUsage godoc
+// @Summary Get the daily request counts of an API key for the last 30 days
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.Response{data=[]models.APIKeyUsage}
+// @Router /api/v1/api-keys/{id}/usage [get]
+func (h *APIKeyHandler) GetUsage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid API key ID", err)
+	}
+
+	usage, err := h.apiKeyService.GetUsage(middleware.UserID(c), id)
+	if err != nil {
+		return response.NotFound(c, "API key not found")
+	}
+
+	return response.Success(c, usage, "")
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ApprovalHandler struct {
+	approvalService *services.ApprovalService
+}
+
+func NewApprovalHandler(approvalService *services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+// SetWalletPolicy godoc
+// @Summary Attach an M-of-N approval policy to a crypto wallet
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param policy body models.SetApprovalPolicyRequest true "Policy data"
+// @Success 200 {object} response.Response{data=models.ApprovalPolicy}
+// @Success 202 {object} response.Response{data=models.ApprovalRequest}
+// @Router /api/v1/wallets/{id}/approval-policy [put]
+func (h *ApprovalHandler) SetWalletPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	var req models.SetApprovalPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	policy, err := h.approvalService.SetWalletPolicy(middleware.UserID(c), middleware.Role(c), id, &req)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.BadRequest(c, "Failed to set approval policy", err)
+	}
+
+	return response.Success(c, policy, "Approval policy saved")
+}
+
+// GetWalletPolicy godoc
+// @Summary Get the approval policy of a crypto wallet
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} response.Response{data=models.ApprovalPolicy}
+// @Router /api/v1/wallets/{id}/approval-policy [get]
+func (h *ApprovalHandler) GetWalletPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	policy, err := h.approvalService.GetWalletPolicy(middleware.UserID(c), id)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "Approval policy not found")
+	}
+
+	return response.Success(c, policy, "")
+}
+
+// SetAccountPolicy godoc
+// @Summary Attach an M-of-N approval policy to a fiat account
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param policy body models.SetApprovalPolicyRequest true "Policy data"
+// @Success 200 {object} response.Response{data=models.ApprovalPolicy}
+// @Success 202 {object} response.Response{data=models.ApprovalRequest}
+// @Router /api/v1/accounts/{id}/approval-policy [put]
+func (h *ApprovalHandler) SetAccountPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid account ID", err)
+	}
+
+	var req models.SetApprovalPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	policy, err := h.approvalService.SetAccountPolicy(middleware.UserID(c), middleware.Role(c), id, &req)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.BadRequest(c, "Failed to set approval policy", err)
+	}
+
+	return response.Success(c, policy, "Approval policy saved")
+}
+
+// GetAccountPolicy godoc
+// @Summary Get the approval policy of a fiat account
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} response.Response{data=models.ApprovalPolicy}
+// @Router /api/v1/accounts/{id}/approval-policy [get]
+func (h *ApprovalHandler) GetAccountPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid account ID", err)
+	}
+
+	policy, err := h.approvalService.GetAccountPolicy(middleware.UserID(c), id)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "Approval policy not found")
+	}
+
+	return response.Success(c, policy, "")
+}
+
+// GetRequest godoc
+// @Summary Get an approval request with its approval trail
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Success 200 {object} response.Response{data=models.ApprovalRequest}
+// @Router /api/v1/approvals/{id} [get]
+func (h *ApprovalHandler) GetRequest(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid approval request ID", err)
+	}
+
+	approval, err := h.approvalService.GetRequestForUser(id, middleware.UserID(c))
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "Approval request not found")
+	}
+
+	return response.Success(c, approval, "")
+}
+
+// Approve godoc
+// @Summary Approve a pending operation
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Param decision body models.ApprovalDecisionRequest true "Decision data"
+// @Success 200 {object} response.Response{data=models.ApprovalRequest}
+// @Router /api/v1/approvals/{id}/approve [post]
+func (h *ApprovalHandler) Approve(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid approval request ID", err)
+	}
+
+	var req models.ApprovalDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+	req.ApproverID = middleware.UserID(c)
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	approval, err := h.approvalService.Approve(auditContext(c), id, &req)
+	if err != nil {
+		return response.Conflict(c, "Failed to approve request", err)
+	}
+
+	return response.Success(c, approval, "Approval recorded")
+}
+
+// Reject godoc
+// @Summary Reject a pending operation
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Param decision body models.ApprovalDecisionRequest true "Decision data"
+// @Success 200 {object} response.Response{data=models.ApprovalRequest}
+// @Router /api/v1/approvals/{id}/reject [post]
+func (h *ApprovalHandler) Reject(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid approval request ID", err)
+	}
+
+	var req models.ApprovalDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+	req.ApproverID = middleware.UserID(c)
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	approval, err := h.approvalService.Reject(id, &req)
+	if err != nil {
+		return response.Conflict(c, "Failed to reject request", err)
+	}
+
+	return response.Success(c, approval, "Rejection recorded")
+}
+
+// GetPendingApprovals godoc
+// @Summary Get pending approval requests awaiting the user's decision
+// @Tags approvals
+// @Produce json
+// @Param user_id path string true "Approver user ID"
+// @Success 200 {object} response.Response{data=[]models.ApprovalRequest}
+// @Router /api/v1/users/{user_id}/approvals/pending [get]
+func (h *ApprovalHandler) GetPendingApprovals(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	approvals, err := h.approvalService.GetPendingForApprover(userID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get approval requests", err)
+	}
+
+	return response.Success(c, approvals, "")
+}
+
+// GetUserApprovals godoc
+// @Summary Get approval requests initiated by a user
+// @Tags approvals
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.ApprovalRequest}
+// @Router /api/v1/users/{user_id}/approvals [get]
+func (h *ApprovalHandler) GetUserApprovals(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	approvals, err := h.approvalService.GetUserRequests(userID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get approval requests", err)
+	}
+
+	return response.Success(c, approvals, "")
+}
+
+// approvalRequired reports whether err means the operation was parked for approval
+// and, if so, writes the 202 response carrying the pending request
+func approvalRequired(c *fiber.Ctx, err error) (bool, error) {
+	var approvalErr *services.ApprovalRequiredError
+	if !errors.As(err, &approvalErr) {
+		return false, nil
+	}
+	return true, response.Accepted(c, approvalErr.Request, "Operation requires approval")
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AssetHandler struct {
+	assetService *services.AssetService
+	audit        *services.AuditService
+}
+
+func NewAssetHandler(assetService *services.AssetService, audit *services.AuditService) *AssetHandler {
+	return &AssetHandler{
+		assetService: assetService,
+		audit:        audit,
+	}
+}
+
+// GetEnabledAssets godoc
+// @Summary Get enabled fiat currencies and crypto assets
+// @Tags assets
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.Asset}
+// @Router /api/v1/assets [get]
+func (h *AssetHandler) GetEnabledAssets(c *fiber.Ctx) error {
+	assets, err := h.assetService.GetAssets(false)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get assets", err)
+	}
+
+	return response.Success(c, assets, "")
+}
+
+// GetAllAssets godoc
+// @Summary Get all registered assets including disabled ones
+// @Tags assets
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.Asset}
+// @Router /admin/v1/assets [get]
+func (h *AssetHandler) GetAllAssets(c *fiber.Ctx) error {
+	assets, err := h.assetService.GetAssets(true)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get assets", err)
+	}
+
+	return response.Success(c, assets, "")
+}
+
+// GetAsset godoc
+// @Summary Get an asset by code
+// @Tags assets
+// @Produce json
+// @Param code path string true "Asset code"
+// @Success 200 {object} response.Response{data=models.Asset}
+// @Router /admin/v1/assets/{code} [get]
+func (h *AssetHandler) GetAsset(c *fiber.Ctx) error {
+	asset, err := h.assetService.GetAsset(assetCodeParam(c))
+	if err != nil {
+		return response.NotFound(c, "Asset not found")
+	}
+
+	return response.Success(c, asset, "")
+}
+
+// CreateAsset godoc
+// @Summary Register a new fiat currency or crypto asset
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param asset body models.CreateAssetRequest true "Asset data"
+// @Success 201 {object} response.Response{data=models.Asset}
+// @Router /admin/v1/assets [post]
+func (h *AssetHandler) CreateAsset(c *fiber.Ctx) error {
+	var req models.CreateAssetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	asset, err := h.assetService.CreateAsset(&req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to create asset", err)
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditAssetCreated, models.AuditEntityAsset, asset.Code, nil, asset)
+	return response.Created(c, asset, "Asset registered successfully")
+}
+
+// UpdateAsset godoc
+// @Summary Update an asset
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param code path string true "Asset code"
+// @Param asset body models.UpdateAssetRequest true "Asset data"
+// @Success 200 {object} response.Response{data=models.Asset}
+// @Router /admin/v1/assets/{code} [put]
+func (h *AssetHandler) UpdateAsset(c *fiber.Ctx) error {
+	var req models.UpdateAssetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	before, err := h.assetService.GetAsset(assetCodeParam(c))
+	if err != nil {
+		return response.NotFound(c, "Asset not found")
+	}
+
+	asset, err := h.assetService.UpdateAsset(before.Code, &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to update asset", err)
+	}
+	h.audit.Record(auditOrigin(c), models.AuditAssetUpdated, models.AuditEntityAsset, asset.Code, before, asset)
+
+	return response.Success(c, asset, "Asset updated successfully")
+}
+
+// EnableAsset godoc
+// @Summary Enable an asset
+// @Tags assets
+// @Produce json
+// @Param code path string true "Asset code"
+// @Success 200 {object} response.Response{data=models.Asset}
+// @Router /admin/v1/assets/{code}/enable [post]
+func (h *AssetHandler) EnableAsset(c *fiber.Ctx) error {
+	before, err := h.assetService.GetAsset(assetCodeParam(c))
+	if err != nil {
+		return response.NotFound(c, "Asset not found")
+	}
+
+	asset, err := h.assetService.SetEnabled(before.Code, true)
+	if err != nil {
+		return response.BadRequest(c, "Failed to enable asset", err)
+	}
+	h.audit.Record(auditOrigin(c), models.AuditAssetEnabled, models.AuditEntityAsset, asset.Code, before, asset)
+
+	return response.Success(c, asset, "Asset enabled")
+}
+
+// DisableAsset godoc
+// @Summary Disable an asset
+// @Tags assets
+// @Produce json
+// @Param code path string true "Asset code"
+// @Success 200 {object} response.Response{data=models.Asset}
+// @Router /admin/v1/assets/{code}/disable [post]
+func (h *AssetHandler) DisableAsset(c *fiber.Ctx) error {
+	before, err := h.assetService.GetAsset(assetCodeParam(c))
+	if err != nil {
+		return response.NotFound(c, "Asset not found")
+	}
+
+	asset, err := h.assetService.SetEnabled(before.Code, false)
+	if err != nil {
+		return response.BadRequest(c, "Failed to disable asset", err)
+	}
+	h.audit.Record(auditOrigin(c), models.AuditAssetDisabled, models.AuditEntityAsset, asset.Code, before, asset)
+
+	return response.Success(c, asset, "Asset disabled")
+}
+
+func assetCodeParam(c *fiber.Ctx) string {
+	return strings.ToUpper(c.Params("code"))
+}
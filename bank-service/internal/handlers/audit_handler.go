@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetEntries godoc
+// @Summary List audit trail entries in chain order, paged with after_seq
+// @Tags admin
+// @Produce json
+// @Param actor_id query string false "User who made the change"
+// @Param entity_type query string false "user, account, wallet, transaction, exchange, asset, api_key, approval_request, screening_hold, kyc_application, limit_rule, treasury_movement or rate"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action, e.g. user.role_changed"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "RFC 3339 start time"
+// @Param to query string false "RFC 3339 end time"
+// @Param after_seq query int false "Only entries after this sequence number"
+// @Param limit query int false "Number of entries (default 100, max 1000)"
+// @Success 200 {object} response.Response{data=[]auditchain.Entry}
+// @Router /admin/v1/audit [get]
+func (h *AuditHandler) GetEntries(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	filter := &models.AuditFilter{
+		EntityType: models.AuditEntityType(c.Query("entity_type")),
+		EntityID:   c.Query("entity_id"),
+		Action:     models.AuditAction(c.Query("action")),
+		RequestID:  c.Query("request_id"),
+		AfterSeq:   int64(c.QueryInt("after_seq", 0)),
+		Limit:      uint64(limit),
+	}
+
+	if raw := c.Query("actor_id"); raw != "" {
+		actorID, err := uuid.Parse(raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid actor ID", err)
+		}
+		filter.ActorID = &actorID
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid from time", err)
+		}
+		filter.From = &from
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid to time", err)
+		}
+		filter.To = &to
+	}
+
+	entries, err := h.auditService.GetEntries(filter)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get audit entries", err)
+	}
+
+	return response.Success(c, entries, "")
+}
+
+// VerifyChain godoc
+// @Summary Recompute the audit hash chain from the first entry and report where it breaks
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response{data=models.AuditVerification}
+// @Router /admin/v1/audit/verify [get]
+func (h *AuditHandler) VerifyChain(c *fiber.Ctx) error {
+	verification, err := h.auditService.Verify()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to verify audit chain", err)
+	}
+
+	return response.Success(c, verification, "")
+}
+
+// RecordRateChange godoc
+// @Summary Record an exchange rate set in the exchange service
+// @Description Called by the exchange service with the token of the admin who set the rate
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param change body models.RateChange true "Rate change"
+// @Success 201 {object} response.Response
+// @Router /admin/v1/audit/rates [post]
+func (h *AuditHandler) RecordRateChange(c *fiber.Ctx) error {
+	var req models.RateChange
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.auditService.RecordRateChange(auditOrigin(c), &req); err != nil {
+		return response.InternalServerError(c, "Failed to record rate change", err)
+	}
+
+	return response.Created(c, nil, "Rate change recorded")
+}
+
+// auditOrigin describes the caller of a request for the audit trail. Staff
+// count as admins, API keys as their own actor type on behalf of their owner.
+func auditOrigin(c *fiber.Ctx) models.AuditOrigin {
+	origin := models.AuditOrigin{
+		ActorType: models.AuditActorUser,
+		RequestID: middleware.GetRequestID(c),
+		TraceID:   middleware.TraceID(c),
+		IP:        c.IP(),
+	}
+
+	if userID := middleware.UserID(c); userID != uuid.Nil {
+		origin.ActorID = &userID
+	}
+
+	if key := middleware.APIKey(c); key != nil {
+		origin.ActorType = models.AuditActorAPIKey
+		origin.APIKeyID = &key.ID
+	} else if claims := middleware.Claims(c); claims != nil {
+		role := claims.UserRole()
+		origin.ActorRole = string(role)
+		if auth.IsStaff(role) {
+			origin.ActorType = models.AuditActorAdmin
+		}
+	}
+
+	return origin
+}
+
+// auditContext is the context of a request for services that record changes
+// in the audit trail themselves
+func auditContext(c *fiber.Ctx) context.Context {
+	return services.WithAuditOrigin(c.UserContext(), auditOrigin(c))
+}
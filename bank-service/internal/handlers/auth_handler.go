@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler struct {
+	authService      *services.AuthService
+	twoFactorService *services.TwoFactorService
+	keys             *auth.KeyStore
+	audit            *services.AuditService
+}
+
+func NewAuthHandler(
+	authService *services.AuthService,
+	twoFactorService *services.TwoFactorService,
+	keys *auth.KeyStore,
+	audit *services.AuditService,
+) *AuthHandler {
+	return &AuthHandler{
+		authService:      authService,
+		twoFactorService: twoFactorService,
+		keys:             keys,
+		audit:            audit,
+	}
+}
+
+// GetJWKS godoc
+// @Summary Get the public keys that verify access tokens
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keys.PublicKeys())
+}
+
+// Login godoc
+// @Summary Log in with email, password and, when enabled, a two-factor code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body models.LoginRequest true "Credentials"
+// @Success 200 {object} response.Response{data=models.TokenPair}
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req models.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	tokens, err := h.authService.Login(&req)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to log in", err)
+	}
+
+	return response.Success(c, tokens, "")
+}
+
+// StepUp godoc
+// @Summary Confirm a two-factor code to unlock sensitive operations for a short time
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.Response{data=models.TokenPair}
+// @Router /api/v1/auth/step-up [post]
+func (h *AuthHandler) StepUp(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	tokens, err := h.authService.StepUp(middleware.UserID(c), req.Code)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to step up", err)
+	}
+
+	return response.Success(c, tokens, "")
+}
+
+// ChangePassword godoc
+// @Summary Change the password of the authenticated user and close all sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param password body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.Response
+// @Router /api/v1/auth/password [put]
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	err := h.authService.ChangePassword(middleware.UserID(c), &req)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to change password", err)
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditUserPasswordChanged, models.AuditEntityUser, middleware.UserID(c).String(), nil, nil)
+	return response.Success(c, nil, "Password changed, all sessions closed")
+}
+
+// GetTwoFactorStatus godoc
+// @Summary Get the two-factor status of the authenticated user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response{data=models.TwoFactorStatus}
+// @Router /api/v1/auth/2fa [get]
+func (h *AuthHandler) GetTwoFactorStatus(c *fiber.Ctx) error {
+	status, err := h.twoFactorService.Status(middleware.UserID(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get two-factor status", err)
+	}
+
+	return response.Success(c, status, "")
+}
+
+// EnrollTwoFactor godoc
+// @Summary Start TOTP enrollment for the authenticated user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response{data=models.TOTPEnrollment}
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactor(c *fiber.Ctx) error {
+	enrollment, err := h.twoFactorService.Enroll(middleware.UserID(c))
+	if err != nil {
+		return response.BadRequest(c, "Failed to start two-factor enrollment", err)
+	}
+
+	return response.Success(c, enrollment, "Confirm the enrollment with a code from your authenticator app")
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm TOTP enrollment and receive recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} response.Response{data=models.RecoveryCodes}
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	codes, err := h.twoFactorService.Confirm(middleware.UserID(c), req.Code)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.BadRequest(c, "Failed to confirm two-factor enrollment", err)
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditUserTwoFactorEnabled, models.AuditEntityUser, middleware.UserID(c).String(), fiber.Map{"two_factor_enabled": false}, fiber.Map{"two_factor_enabled": true})
+	return response.Success(c, codes, "Two-factor authentication enabled")
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication for the authenticated user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param confirmation body models.DisableTwoFactorRequest true "Password and TOTP or recovery code"
+// @Success 200 {object} response.Response
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	var req models.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	err := h.twoFactorService.Disable(middleware.UserID(c), req.Password, req.Code)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to disable two-factor authentication", err)
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditUserTwoFactorDisabled, models.AuditEntityUser, middleware.UserID(c).String(), fiber.Map{"two_factor_enabled": true}, fiber.Map{"two_factor_enabled": false})
+	return response.Success(c, nil, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Replace the recovery codes of the authenticated user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.Response{data=models.RecoveryCodes}
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(middleware.UserID(c), req.Code)
+	if handled, respErr := authFailed(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to regenerate recovery codes", err)
+	}
+
+	return response.Success(c, codes, "Recovery codes regenerated")
+}
+
+// Refresh godoc
+// @Summary Exchange a refresh token for a new token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} response.Response{data=models.TokenPair}
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			return response.Unauthorized(c, "Invalid refresh token")
+		}
+		return response.InternalServerError(c, "Failed to refresh session", err)
+	}
+
+	return response.Success(c, tokens, "")
+}
+
+// Logout godoc
+// @Summary Revoke the session of a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} response.Response
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			return response.Unauthorized(c, "Invalid refresh token")
+		}
+		return response.InternalServerError(c, "Failed to close session", err)
+	}
+
+	return response.Success(c, nil, "Logged out")
+}
+
+// LogoutAll godoc
+// @Summary Revoke every session of the authenticated user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	if err := h.authService.LogoutAll(middleware.UserID(c)); err != nil {
+		return response.InternalServerError(c, "Failed to close sessions", err)
+	}
+
+	return response.Success(c, nil, "All sessions closed")
+}
+
+// accessDenied reports whether err means the record belongs to another user and,
+// if so, writes the 403 response
+func accessDenied(c *fiber.Ctx, err error) (bool, error) {
+	if !errors.Is(err, services.ErrOwnershipMismatch) {
+		return false, nil
+	}
+	return true, response.Forbidden(c, "Access denied")
+}
+
+// authFailed reports whether err is a credential or second factor failure and,
+// if so, writes the matching response
+func authFailed(c *fiber.Ctx, err error) (bool, error) {
+	var locked *services.AccountLockedError
+	switch {
+	case errors.As(err, &locked):
+		c.Set(fiber.HeaderRetryAfter, fmt.Sprint(int(time.Until(locked.Until).Seconds())+1))
+		return true, response.Error(c, fiber.StatusLocked, "Account temporarily locked", err)
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return true, response.Unauthorized(c, "Invalid email or password")
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		return true, response.Unauthorized(c, "Two-factor code required")
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return true, response.Unauthorized(c, "Invalid two-factor code")
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		return true, response.Forbidden(c, "Two-factor authentication is not enabled")
+	}
+	return false, nil
+}
+
+// stepUpRequired reports whether err asks for a stepped-up access token and,
+// if so, writes the challenge
+func stepUpRequired(c *fiber.Ctx, err error) (bool, error) {
+	if !errors.Is(err, services.ErrStepUpRequired) {
+		return false, nil
+	}
+	return true, middleware.StepUpChallenge(c)
+}
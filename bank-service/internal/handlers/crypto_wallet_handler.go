@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"github.com/crypto-bank/bank-service/internal/middleware"
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
 	"github.com/crypto-bank/bank-service/pkg/response"
 	"github.com/crypto-bank/bank-service/pkg/validator"
 	"github.com/gofiber/fiber/v2"
@@ -11,11 +13,13 @@ import (
 
 type CryptoWalletHandler struct {
 	walletService *services.CryptoWalletService
+	audit         *services.AuditService
 }
 
-func NewCryptoWalletHandler(walletService *services.CryptoWalletService) *CryptoWalletHandler {
+func NewCryptoWalletHandler(walletService *services.CryptoWalletService, audit *services.AuditService) *CryptoWalletHandler {
 	return &CryptoWalletHandler{
 		walletService: walletService,
+		audit:         audit,
 	}
 }
 
@@ -32,16 +36,18 @@ func (h *CryptoWalletHandler) CreateWallet(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	wallet, err := h.walletService.CreateWallet(&req)
+	wallet, err := h.walletService.CreateWallet(c.UserContext(), &req)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to create wallet", err)
 	}
 
+	h.audit.Record(auditOrigin(c), models.AuditWalletCreated, models.AuditEntityWallet, wallet.ID.String(), nil, wallet)
 	return response.Created(c, wallet, "Wallet created successfully")
 }
 
@@ -63,6 +69,10 @@ func (h *CryptoWalletHandler) GetWallet(c *fiber.Ctx) error {
 		return response.NotFound(c, "Wallet not found")
 	}
 
+	if wallet.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
 	return response.Success(c, wallet, "")
 }
 
@@ -100,11 +110,164 @@ func (h *CryptoWalletHandler) GetWalletBalance(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid wallet ID", err)
 	}
 
-	balance, err := h.walletService.GetWalletBalance(id)
+	wallet, err := h.walletService.GetWallet(id)
 	if err != nil {
 		return response.NotFound(c, "Wallet not found")
 	}
 
-	return response.Success(c, map[string]interface{}{"balance": balance}, "")
+	if wallet.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
+	return response.Success(c, map[string]interface{}{"balance": wallet.Balance}, "")
+}
+
+// WithdrawCrypto godoc
+// @Summary Withdraw cryptocurrency to an external address
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param withdraw body models.WithdrawCryptoRequest true "Withdraw data"
+// @Success 201 {object} response.Response{data=models.Transaction}
+// @Success 202 {object} response.Response{data=models.ApprovalRequest}
+// @Success 202 {object} response.Response{data=models.ScreeningHoldReceipt}
+// @Router /api/v1/wallets/{id}/withdraw [post]
+func (h *CryptoWalletHandler) WithdrawCrypto(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	var req models.WithdrawCryptoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+	req.WalletID = id
+	req.UserID = middleware.UserID(c)
+	req.SteppedUp = middleware.SteppedUp(c)
+	req.Client = clientInfo(c)
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	transaction, err := h.walletService.WithdrawCrypto(c.UserContext(), &req)
+	if handled, respErr := screeningHeld(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := riskDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := stepUpRequired(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		metrics.TransactionsTotal.WithLabelValues("crypto_withdraw", "failed").Inc()
+		return response.InternalServerError(c, "Failed to withdraw", err)
+	}
+
+	metrics.TransactionsTotal.WithLabelValues("crypto_withdraw", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditCryptoWithdrawalCreated, models.AuditEntityTransaction, transaction.ID.String(), nil, transaction)
+	return response.Created(c, transaction, "Withdrawal successful")
 }
 
+// GetWhitelist godoc
+// @Summary Get the withdrawal address whitelist of a wallet
+// @Tags wallets
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} response.Response{data=[]models.WhitelistedAddress}
+// @Router /api/v1/wallets/{id}/whitelist [get]
+func (h *CryptoWalletHandler) GetWhitelist(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	addresses, err := h.walletService.GetWhitelistedAddresses(middleware.UserID(c), id)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "Wallet not found")
+	}
+
+	return response.Success(c, addresses, "")
+}
+
+// AddWhitelistedAddress godoc
+// @Summary Add an address to the withdrawal whitelist of a wallet
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param address body models.AddWhitelistedAddressRequest true "Address data"
+// @Success 201 {object} response.Response{data=models.WhitelistedAddress}
+// @Router /api/v1/wallets/{id}/whitelist [post]
+func (h *CryptoWalletHandler) AddWhitelistedAddress(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	var req models.AddWhitelistedAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	address, err := h.walletService.AddWhitelistedAddress(middleware.UserID(c), id, &req)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.BadRequest(c, "Failed to whitelist address", err)
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditWalletWhitelistAdded, models.AuditEntityWallet, id.String(), nil, address)
+	return response.Created(c, address, "Address whitelisted")
+}
+
+// RemoveWhitelistedAddress godoc
+// @Summary Remove an address from the withdrawal whitelist of a wallet
+// @Tags wallets
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param entry_id path string true "Whitelist entry ID"
+// @Success 200 {object} response.Response
+// @Router /api/v1/wallets/{id}/whitelist/{entry_id} [delete]
+func (h *CryptoWalletHandler) RemoveWhitelistedAddress(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid wallet ID", err)
+	}
+
+	entryID, err := uuid.Parse(c.Params("entry_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid whitelist entry ID", err)
+	}
+
+	err = h.walletService.RemoveWhitelistedAddress(middleware.UserID(c), id, entryID)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "Whitelist entry not found")
+	}
+
+	h.audit.Record(auditOrigin(c), models.AuditWalletWhitelistRemoved, models.AuditEntityWallet, id.String(), fiber.Map{"whitelist_entry_id": entryID}, nil)
+	return response.Success(c, nil, "Address removed from whitelist")
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/shared/events"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventHandler struct {
+	eventStoreService *services.EventStoreService
+	// maxReplayEvents caps the events one replay request re-publishes
+	maxReplayEvents uint64
+}
+
+func NewEventHandler(eventStoreService *services.EventStoreService, maxReplayEvents int) *EventHandler {
+	return &EventHandler{
+		eventStoreService: eventStoreService,
+		maxReplayEvents:   uint64(maxReplayEvents),
+	}
+}
+
+// GetEvents godoc
+// @Summary List stored events in the order they were published, paged with after_position
+// @Tags admin
+// @Produce json
+// @Param subject query string false "ID of the entity the events are about"
+// @Param user_id query string false "User the events concern"
+// @Param type query string false "Comma-separated event types, e.g. transaction.completed"
+// @Param from query string false "RFC 3339 start time"
+// @Param to query string false "RFC 3339 end time"
+// @Param after_position query int false "Only events after this position"
+// @Param limit query int false "Number of events (default 100, max 1000)"
+// @Success 200 {object} response.Response{data=[]models.StoredEvent}
+// @Router /admin/v1/events [get]
+func (h *EventHandler) GetEvents(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	filter := &models.EventFilter{
+		Subject:       c.Query("subject"),
+		UserID:        c.Query("user_id"),
+		AfterPosition: int64(c.QueryInt("after_position", 0)),
+		Limit:         uint64(limit),
+	}
+
+	if raw := c.Query("type"); raw != "" {
+		filter.Types = strings.Split(raw, ",")
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid from time", err)
+		}
+		filter.From = &from
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid to time", err)
+		}
+		filter.To = &to
+	}
+
+	stored, err := h.eventStoreService.GetEvents(filter)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get stored events", err)
+	}
+
+	return response.Success(c, stored, "")
+}
+
+// Replay godoc
+// @Summary Re-publish stored events to bank.events.replay for consumers rebuilding their state
+// @Description Selects events like GET /admin/v1/events. Consumers only apply replayed events while they rebuild.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.ReplayRequest true "Events to replay; limit defaults to the configured maximum"
+// @Success 200 {object} response.Response{data=models.ReplayResult}
+// @Failure 503 {object} response.Response{data=models.ReplayResult}
+// @Router /admin/v1/events/replay [post]
+func (h *EventHandler) Replay(c *fiber.Ctx) error {
+	var req models.ReplayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if req.Limit == 0 {
+		req.Limit = h.maxReplayEvents
+	}
+	if req.Limit > h.maxReplayEvents {
+		return response.BadRequest(c, "Limit too high", fmt.Errorf("limit must be at most %d", h.maxReplayEvents))
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return response.BadRequest(c, "Invalid time range", fmt.Errorf("from must be before to"))
+	}
+	for _, eventType := range req.Types {
+		if events.Version(eventType) == 0 {
+			return response.BadRequest(c, "Invalid event type", fmt.Errorf("%w: %s", events.ErrUnknownType, eventType))
+		}
+	}
+
+	result, err := h.eventStoreService.Replay(c.UserContext(), &req)
+	if err != nil {
+		// Events up to the last position were published; the result says so
+		return c.Status(fiber.StatusServiceUnavailable).JSON(response.Response{
+			Success: false,
+			Message: "Replay stopped",
+			Data:    result,
+			Error:   err.Error(),
+		})
+	}
+
+	return response.Success(c, result, "")
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/crypto-bank/bank-service/internal/middleware"
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/services"
 	"github.com/crypto-bank/bank-service/pkg/metrics"
@@ -12,11 +13,13 @@ import (
 
 type ExchangeHandler struct {
 	exchangeService *services.ExchangeService
+	audit           *services.AuditService
 }
 
-func NewExchangeHandler(exchangeService *services.ExchangeService) *ExchangeHandler {
+func NewExchangeHandler(exchangeService *services.ExchangeService, audit *services.AuditService) *ExchangeHandler {
 	return &ExchangeHandler{
 		exchangeService: exchangeService,
+		audit:           audit,
 	}
 }
 
@@ -33,18 +36,37 @@ func (h *ExchangeHandler) ExchangeCryptoToFiat(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
+	req.SteppedUp = middleware.SteppedUp(c)
+	req.Client = clientInfo(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	exchange, err := h.exchangeService.ExchangeCryptoToFiat(&req)
+	exchange, err := h.exchangeService.ExchangeCryptoToFiat(c.UserContext(), &req)
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := riskDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := stepUpRequired(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		metrics.ExchangesTotal.WithLabelValues("crypto_to_fiat", "failed").Inc()
 		return response.InternalServerError(c, "Failed to exchange crypto to fiat", err)
 	}
 
 	metrics.ExchangesTotal.WithLabelValues("crypto_to_fiat", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditExchangeCreated, models.AuditEntityExchange, exchange.ID.String(), nil, exchange)
 	return response.Created(c, exchange, "Exchange completed successfully")
 }
 
@@ -61,18 +83,37 @@ func (h *ExchangeHandler) ExchangeFiatToCrypto(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
+	req.SteppedUp = middleware.SteppedUp(c)
+	req.Client = clientInfo(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	exchange, err := h.exchangeService.ExchangeFiatToCrypto(&req)
+	exchange, err := h.exchangeService.ExchangeFiatToCrypto(c.UserContext(), &req)
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := riskDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := stepUpRequired(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		metrics.ExchangesTotal.WithLabelValues("fiat_to_crypto", "failed").Inc()
 		return response.InternalServerError(c, "Failed to exchange fiat to crypto", err)
 	}
 
 	metrics.ExchangesTotal.WithLabelValues("fiat_to_crypto", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditExchangeCreated, models.AuditEntityExchange, exchange.ID.String(), nil, exchange)
 	return response.Created(c, exchange, "Exchange completed successfully")
 }
 
@@ -94,6 +135,10 @@ func (h *ExchangeHandler) GetExchange(c *fiber.Ctx) error {
 		return response.NotFound(c, "Exchange not found")
 	}
 
+	if exchange.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
 	return response.Success(c, exchange, "")
 }
 
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KYCHandler struct {
+	kycService *services.KYCService
+}
+
+func NewKYCHandler(kycService *services.KYCService) *KYCHandler {
+	return &KYCHandler{
+		kycService: kycService,
+	}
+}
+
+// GetStatus godoc
+// @Summary Get the KYC tier, limits and applications of the authenticated user
+// @Tags kyc
+// @Produce json
+// @Success 200 {object} response.Response{data=models.KYCStatus}
+// @Router /api/v1/kyc [get]
+func (h *KYCHandler) GetStatus(c *fiber.Ctx) error {
+	status, err := h.kycService.GetStatus(middleware.UserID(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get KYC status", err)
+	}
+
+	return response.Success(c, status, "")
+}
+
+// SubmitApplication godoc
+// @Summary Submit identity documents to move up a KYC tier
+// @Tags kyc
+// @Accept json
+// @Produce json
+// @Param application body models.SubmitKYCRequest true "Application data, document content base64 encoded"
+// @Success 201 {object} response.Response{data=models.KYCApplication}
+// @Router /api/v1/kyc/applications [post]
+func (h *KYCHandler) SubmitApplication(c *fiber.Ctx) error {
+	var req models.SubmitKYCRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	app, err := h.kycService.Submit(c.UserContext(), middleware.UserID(c), &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to submit KYC application", err)
+	}
+
+	return response.Created(c, app, "KYC application submitted")
+}
+
+// GetApplication godoc
+// @Summary Get a KYC application of the authenticated user
+// @Tags kyc
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} response.Response{data=models.KYCApplication}
+// @Router /api/v1/kyc/applications/{id} [get]
+func (h *KYCHandler) GetApplication(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid application ID", err)
+	}
+
+	app, err := h.kycService.GetApplication(middleware.UserID(c), id)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if err != nil {
+		return response.NotFound(c, "KYC application not found")
+	}
+
+	return response.Success(c, app, "")
+}
+
+// GetReviewQueue godoc
+// @Summary List KYC applications by status, manual reviews by default
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending, in_review, approved or rejected"
+// @Success 200 {object} response.Response{data=[]models.KYCApplication}
+// @Router /admin/v1/kyc/applications [get]
+func (h *KYCHandler) GetReviewQueue(c *fiber.Ctx) error {
+	status := models.KYCApplicationStatus(c.Query("status", string(models.KYCStatusInReview)))
+	switch status {
+	case models.KYCStatusPending, models.KYCStatusInReview, models.KYCStatusApproved, models.KYCStatusRejected:
+	default:
+		return response.BadRequest(c, "Invalid status", fmt.Errorf("unknown status: %s", status))
+	}
+
+	apps, err := h.kycService.GetApplicationsByStatus(status)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get KYC applications", err)
+	}
+
+	return response.Success(c, apps, "")
+}
+
+// GetApplicationForReview godoc
+// @Summary Get any KYC application with its document list
+// @Tags admin
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} response.Response{data=models.KYCApplication}
+// @Router /admin/v1/kyc/applications/{id} [get]
+func (h *KYCHandler) GetApplicationForReview(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid application ID", err)
+	}
+
+	app, err := h.kycService.GetApplicationForReview(id)
+	if err != nil {
+		return response.NotFound(c, "KYC application not found")
+	}
+
+	return response.Success(c, app, "")
+}
+
+// GetDocument godoc
+// @Summary Download a submitted KYC document
+// @Tags admin
+// @Produce octet-stream
+// @Param id path string true "Application ID"
+// @Param document_id path string true "Document ID"
+// @Success 200 {file} file
+// @Router /admin/v1/kyc/applications/{id}/documents/{document_id} [get]
+func (h *KYCHandler) GetDocument(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid application ID", err)
+	}
+
+	documentID, err := uuid.Parse(c.Params("document_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid document ID", err)
+	}
+
+	doc, err := h.kycService.GetDocument(id, documentID)
+	if err != nil {
+		return response.NotFound(c, "KYC document not found")
+	}
+
+	c.Set(fiber.HeaderContentType, doc.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", doc.FileName))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(doc.Content)
+}
+
+// ApproveApplication godoc
+// @Summary Approve a KYC application waiting for manual review
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Param review body models.ReviewKYCRequest true "Review note"
+// @Success 200 {object} response.Response{data=models.KYCApplication}
+// @Router /admin/v1/kyc/applications/{id}/approve [post]
+func (h *KYCHandler) ApproveApplication(c *fiber.Ctx) error {
+	return h.review(c, h.kycService.Approve, "KYC application approved")
+}
+
+// RejectApplication godoc
+// @Summary Reject a KYC application waiting for manual review
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Param review body models.ReviewKYCRequest true "Review note"
+// @Success 200 {object} response.Response{data=models.KYCApplication}
+// @Router /admin/v1/kyc/applications/{id}/reject [post]
+func (h *KYCHandler) RejectApplication(c *fiber.Ctx) error {
+	return h.review(c, h.kycService.Reject, "KYC application rejected")
+}
+
+func (h *KYCHandler) review(c *fiber.Ctx, decide func(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.KYCApplication, error), message string) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid application ID", err)
+	}
+
+	var req models.ReviewKYCRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	app, err := decide(auditContext(c), middleware.UserID(c), id, req.Note)
+	if err != nil {
+		return response.BadRequest(c, "Failed to review KYC application", err)
+	}
+
+	return response.Success(c, app, message)
+}
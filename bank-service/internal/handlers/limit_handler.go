@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type LimitHandler struct {
+	limitService *services.LimitService
+}
+
+func NewLimitHandler(limitService *services.LimitService) *LimitHandler {
+	return &LimitHandler{
+		limitService: limitService,
+	}
+}
+
+// GetUserLimits godoc
+// @Summary Get the effective limits of a user with the remaining headroom
+// @Tags limits
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Response{data=models.UserLimits}
+// @Router /api/v1/users/{user_id}/limits [get]
+func (h *LimitHandler) GetUserLimits(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	limits, err := h.limitService.GetUserLimits(userID)
+	if err != nil {
+		return response.NotFound(c, "User not found")
+	}
+
+	return response.Success(c, limits, "")
+}
+
+// GetRules godoc
+// @Summary List limit rules
+// @Tags admin
+// @Produce json
+// @Param scope query string false "global, tier or user"
+// @Param kyc_tier query string false "KYC tier"
+// @Param user_id query string false "User ID"
+// @Success 200 {object} response.Response{data=[]models.LimitRule}
+// @Router /admin/v1/limits [get]
+func (h *LimitHandler) GetRules(c *fiber.Ctx) error {
+	filter := &models.LimitRuleFilter{
+		Scope: models.LimitScope(c.Query("scope")),
+	}
+
+	if tier := c.Query("kyc_tier"); tier != "" {
+		kycTier := models.KYCTier(tier)
+		filter.KYCTier = &kycTier
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid user ID", err)
+		}
+		filter.UserID = &userID
+	}
+
+	rules, err := h.limitService.GetRules(filter)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get limit rules", err)
+	}
+
+	return response.Success(c, rules, "")
+}
+
+// SetRule godoc
+// @Summary Create or replace the limit rule for a scope, kind and currency
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rule body models.SetLimitRuleRequest true "Rule data"
+// @Success 200 {object} response.Response{data=models.LimitRule}
+// @Router /admin/v1/limits [put]
+func (h *LimitHandler) SetRule(c *fiber.Ctx) error {
+	var req models.SetLimitRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	rule, err := h.limitService.SetRule(auditContext(c), &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to save limit rule", err)
+	}
+
+	return response.Success(c, rule, "Limit rule saved")
+}
+
+// DeleteRule godoc
+// @Summary Delete a limit rule
+// @Tags admin
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} response.Response
+// @Router /admin/v1/limits/{id} [delete]
+func (h *LimitHandler) DeleteRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid rule ID", err)
+	}
+
+	if err := h.limitService.DeleteRule(auditContext(c), id); err != nil {
+		return response.NotFound(c, "Limit rule not found")
+	}
+
+	return response.Success(c, nil, "Limit rule deleted")
+}
+
+// limitExceeded reports whether err is a KYC tier or velocity limit rejection
+// and, if so, writes the 403 response
+func limitExceeded(c *fiber.Ctx, err error) (bool, error) {
+	var tierErr *services.KYCLimitExceededError
+	var limitErr *services.LimitExceededError
+	switch {
+	case errors.As(err, &tierErr):
+		return true, response.Error(c, fiber.StatusForbidden, "KYC tier limit exceeded", err)
+	case errors.As(err, &limitErr):
+		return true, response.Error(c, fiber.StatusForbidden, "Transaction limit exceeded", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReservesHandler struct {
+	reservesService *services.ReservesService
+}
+
+func NewReservesHandler(reservesService *services.ReservesService) *ReservesHandler {
+	return &ReservesHandler{
+		reservesService: reservesService,
+	}
+}
+
+// CreateSnapshot godoc
+// @Summary Snapshot customer balances and publish new Merkle sum tree roots
+// @Tags reserves
+// @Produce json
+// @Success 201 {object} response.Response{data=models.ReserveSnapshot}
+// @Router /admin/v1/reserves/snapshots [post]
+func (h *ReservesHandler) CreateSnapshot(c *fiber.Ctx) error {
+	snapshot, err := h.reservesService.CreateSnapshot()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to create reserve snapshot", err)
+	}
+
+	return response.Created(c, snapshot, "Reserve snapshot created")
+}
+
+// GetSnapshots godoc
+// @Summary Get recent proof-of-reserves snapshots
+// @Tags reserves
+// @Produce json
+// @Param limit query int false "Number of snapshots (default 20)"
+// @Success 200 {object} response.Response{data=[]models.ReserveSnapshot}
+// @Router /admin/v1/reserves/snapshots [get]
+func (h *ReservesHandler) GetSnapshots(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 {
+		limit = 20
+	}
+
+	snapshots, err := h.reservesService.GetSnapshots(uint64(limit))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get reserve snapshots", err)
+	}
+
+	return response.Success(c, snapshots, "")
+}
+
+// GetLatestSnapshot godoc
+// @Summary Get the latest published roots and liability totals
+// @Tags reserves
+// @Produce json
+// @Success 200 {object} response.Response{data=models.ReserveSnapshot}
+// @Router /admin/v1/reserves/snapshots/latest [get]
+func (h *ReservesHandler) GetLatestSnapshot(c *fiber.Ctx) error {
+	snapshot, err := h.reservesService.GetLatestSnapshot()
+	if err != nil {
+		return response.NotFound(c, "Reserve snapshot not found")
+	}
+
+	return response.Success(c, snapshot, "")
+}
+
+// GetSnapshot godoc
+// @Summary Get the published roots and liability totals of a snapshot
+// @Tags reserves
+// @Produce json
+// @Param id path string true "Snapshot ID"
+// @Success 200 {object} response.Response{data=models.ReserveSnapshot}
+// @Router /admin/v1/reserves/snapshots/{id} [get]
+func (h *ReservesHandler) GetSnapshot(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid snapshot ID", err)
+	}
+
+	snapshot, err := h.reservesService.GetSnapshot(id)
+	if err != nil {
+		return response.NotFound(c, "Reserve snapshot not found")
+	}
+
+	return response.Success(c, snapshot, "")
+}
+
+// GetUserProof godoc
+// @Summary Get the user's inclusion proofs in the latest reserves snapshot
+// @Tags reserves
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.ReservesProof}
+// @Router /api/v1/users/{user_id}/wallets/reserves-proof [get]
+func (h *ReservesHandler) GetUserProof(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", err)
+	}
+
+	proofs, err := h.reservesService.GetUserProofs(userID)
+	if err != nil {
+		return response.NotFound(c, "Reserves proof not found")
+	}
+
+	return response.Success(c, proofs, "")
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RiskHandler struct {
+	riskService *services.RiskService
+}
+
+func NewRiskHandler(riskService *services.RiskService) *RiskHandler {
+	return &RiskHandler{
+		riskService: riskService,
+	}
+}
+
+// GetDecisions godoc
+// @Summary List fraud scoring decisions, newest first
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param operation query string false "transfer, withdraw, crypto_withdraw or exchange"
+// @Param decision query string false "allow, challenge or deny"
+// @Param limit query int false "Number of decisions (default 100)"
+// @Success 200 {object} response.Response{data=[]models.RiskEvaluation}
+// @Router /admin/v1/risk/decisions [get]
+func (h *RiskHandler) GetDecisions(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 {
+		limit = 100
+	}
+
+	filter := &models.RiskEvaluationFilter{
+		Operation: models.RiskOperation(c.Query("operation")),
+		Decision:  models.RiskDecision(c.Query("decision")),
+		Limit:     uint64(limit),
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid user ID", err)
+		}
+		filter.UserID = &userID
+	}
+
+	decisions, err := h.riskService.GetDecisions(filter)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get risk decisions", err)
+	}
+
+	return response.Success(c, decisions, "")
+}
+
+// clientInfo collects what fraud scoring needs to know about the caller
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	return models.ClientInfo{
+		IP:        c.IP(),
+		DeviceID:  c.Get("X-Device-ID"),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// riskDenied reports whether err is a fraud scoring decline and, if so, writes
+// the 403 with the decision reference
+func riskDenied(c *fiber.Ctx, err error) (bool, error) {
+	var deniedErr *services.RiskDeniedError
+	if !errors.As(err, &deniedErr) {
+		return false, nil
+	}
+	return true, response.Error(c, fiber.StatusForbidden, "Operation declined", err)
+}
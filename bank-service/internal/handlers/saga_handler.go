@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SagaHandler struct {
+	sagaService *services.ExchangeSagaService
+}
+
+func NewSagaHandler(sagaService *services.ExchangeSagaService) *SagaHandler {
+	return &SagaHandler{
+		sagaService: sagaService,
+	}
+}
+
+// GetSaga godoc
+// @Summary Get an exchange saga with the steps it ran or undid
+// @Tags admin
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Success 200 {object} response.Response{data=models.ExchangeSaga}
+// @Router /admin/v1/sagas/{id} [get]
+func (h *SagaHandler) GetSaga(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid saga ID", err)
+	}
+
+	saga, err := h.sagaService.GetSaga(id)
+	if err != nil {
+		return response.NotFound(c, "Saga not found")
+	}
+
+	return response.Success(c, saga, "")
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/crypto-bank/bank-service/internal/middleware"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ScreeningHandler struct {
+	screeningService *services.ScreeningService
+}
+
+func NewScreeningHandler(screeningService *services.ScreeningService) *ScreeningHandler {
+	return &ScreeningHandler{
+		screeningService: screeningService,
+	}
+}
+
+// GetHolds godoc
+// @Summary List operations held by watchlist screening, open holds by default
+// @Tags admin
+// @Produce json
+// @Param status query string false "held, released, rejected or failed"
+// @Success 200 {object} response.Response{data=[]models.ScreeningHold}
+// @Router /admin/v1/screening/holds [get]
+func (h *ScreeningHandler) GetHolds(c *fiber.Ctx) error {
+	status := models.ScreeningHoldStatus(c.Query("status", string(models.ScreeningHoldHeld)))
+	switch status {
+	case models.ScreeningHoldHeld, models.ScreeningHoldReleased, models.ScreeningHoldRejected, models.ScreeningHoldFailed:
+	default:
+		return response.BadRequest(c, "Invalid status", fmt.Errorf("unknown status: %s", status))
+	}
+
+	holds, err := h.screeningService.GetHolds(status)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get screening holds", err)
+	}
+
+	return response.Success(c, holds, "")
+}
+
+// GetHold godoc
+// @Summary Get a held operation with its watchlist matches
+// @Tags admin
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} response.Response{data=models.ScreeningHold}
+// @Router /admin/v1/screening/holds/{id} [get]
+func (h *ScreeningHandler) GetHold(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid hold ID", err)
+	}
+
+	hold, err := h.screeningService.GetHold(id)
+	if err != nil {
+		return response.NotFound(c, "Screening hold not found")
+	}
+
+	return response.Success(c, hold, "")
+}
+
+// ReleaseHold godoc
+// @Summary Clear a watchlist hit as a false positive and execute the held operation
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param review body models.ReviewScreeningHoldRequest true "Review note"
+// @Success 200 {object} response.Response{data=models.ScreeningHold}
+// @Router /admin/v1/screening/holds/{id}/release [post]
+func (h *ScreeningHandler) ReleaseHold(c *fiber.Ctx) error {
+	return h.review(c, h.screeningService.Release, "Operation released")
+}
+
+// RejectHold godoc
+// @Summary Confirm a watchlist hit; the held operation is not executed
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param review body models.ReviewScreeningHoldRequest true "Review note"
+// @Success 200 {object} response.Response{data=models.ScreeningHold}
+// @Router /admin/v1/screening/holds/{id}/reject [post]
+func (h *ScreeningHandler) RejectHold(c *fiber.Ctx) error {
+	return h.review(c, h.screeningService.Reject, "Operation rejected")
+}
+
+func (h *ScreeningHandler) review(c *fiber.Ctx, decide func(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.ScreeningHold, error), message string) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid hold ID", err)
+	}
+
+	var req models.ReviewScreeningHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	hold, err := decide(auditContext(c), middleware.UserID(c), id, req.Note)
+	if err != nil {
+		return response.BadRequest(c, "Failed to review screening hold", err)
+	}
+
+	return response.Success(c, hold, message)
+}
+
+// GetWatchlists godoc
+// @Summary Describe the loaded watchlists
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response{data=models.WatchlistStatus}
+// @Router /admin/v1/screening/watchlists [get]
+func (h *ScreeningHandler) GetWatchlists(c *fiber.Ctx) error {
+	return response.Success(c, h.screeningService.GetWatchlistStatus(), "")
+}
+
+// ReloadWatchlists godoc
+// @Summary Reload the watchlists from disk
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response{data=models.WatchlistStatus}
+// @Router /admin/v1/screening/watchlists/reload [post]
+func (h *ScreeningHandler) ReloadWatchlists(c *fiber.Ctx) error {
+	status, err := h.screeningService.ReloadWatchlists()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to reload watchlists", err)
+	}
+
+	return response.Success(c, status, "Watchlists reloaded")
+}
+
+// screeningHeld reports whether err means the operation was held by watchlist
+// screening. The customer only gets a receipt; the matches stay with compliance.
+func screeningHeld(c *fiber.Ctx, err error) (bool, error) {
+	var heldErr *services.ScreeningHeldError
+	if !errors.As(err, &heldErr) {
+		return false, nil
+	}
+	return true, response.Accepted(c, heldErr.Hold.Receipt(), "Operation held for compliance review")
+}
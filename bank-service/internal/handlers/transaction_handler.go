@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/crypto-bank/bank-service/internal/middleware"
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/services"
 	"github.com/crypto-bank/bank-service/pkg/metrics"
@@ -12,11 +13,13 @@ import (
 
 type TransactionHandler struct {
 	transactionService *services.TransactionService
+	audit              *services.AuditService
 }
 
-func NewTransactionHandler(transactionService *services.TransactionService) *TransactionHandler {
+func NewTransactionHandler(transactionService *services.TransactionService, audit *services.AuditService) *TransactionHandler {
 	return &TransactionHandler{
 		transactionService: transactionService,
+		audit:              audit,
 	}
 }
 
@@ -27,24 +30,44 @@ func NewTransactionHandler(transactionService *services.TransactionService) *Tra
 // @Produce json
 // @Param transaction body models.CreateTransactionRequest true "Transaction data"
 // @Success 201 {object} response.Response{data=models.Transaction}
+// @Success 202 {object} response.Response{data=models.ScreeningHoldReceipt}
 // @Router /api/v1/transactions/transfer [post]
 func (h *TransactionHandler) CreateTransfer(c *fiber.Ctx) error {
 	var req models.CreateTransactionRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
+	req.SteppedUp = middleware.SteppedUp(c)
+	req.Client = clientInfo(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	transaction, err := h.transactionService.CreateTransfer(&req)
+	transaction, err := h.transactionService.CreateTransfer(c.UserContext(), &req)
+	if handled, respErr := screeningHeld(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := riskDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := stepUpRequired(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		metrics.TransactionsTotal.WithLabelValues("transfer", "failed").Inc()
 		return response.InternalServerError(c, "Failed to create transfer", err)
 	}
 
 	metrics.TransactionsTotal.WithLabelValues("transfer", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditTransferCreated, models.AuditEntityTransaction, transaction.ID.String(), nil, transaction)
 	return response.Created(c, transaction, "Transfer created successfully")
 }
 
@@ -61,18 +84,26 @@ func (h *TransactionHandler) Deposit(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	transaction, err := h.transactionService.Deposit(&req)
+	transaction, err := h.transactionService.Deposit(c.UserContext(), &req)
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		metrics.TransactionsTotal.WithLabelValues("deposit", "failed").Inc()
 		return response.InternalServerError(c, "Failed to deposit", err)
 	}
 
 	metrics.TransactionsTotal.WithLabelValues("deposit", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditDepositCreated, models.AuditEntityTransaction, transaction.ID.String(), nil, transaction)
 	return response.Created(c, transaction, "Deposit successful")
 }
 
@@ -89,18 +120,37 @@ func (h *TransactionHandler) Withdraw(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
+	req.UserID = middleware.UserID(c)
+	req.SteppedUp = middleware.SteppedUp(c)
+	req.Client = clientInfo(c)
 
 	if err := validator.Validate(&req); err != nil {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	transaction, err := h.transactionService.Withdraw(&req)
+	transaction, err := h.transactionService.Withdraw(c.UserContext(), &req)
+	if handled, respErr := approvalRequired(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := accessDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := limitExceeded(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := riskDenied(c, err); handled {
+		return respErr
+	}
+	if handled, respErr := stepUpRequired(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		metrics.TransactionsTotal.WithLabelValues("withdraw", "failed").Inc()
 		return response.InternalServerError(c, "Failed to withdraw", err)
 	}
 
 	metrics.TransactionsTotal.WithLabelValues("withdraw", "success").Inc()
+	h.audit.Record(auditOrigin(c), models.AuditWithdrawalCreated, models.AuditEntityTransaction, transaction.ID.String(), nil, transaction)
 	return response.Created(c, transaction, "Withdrawal successful")
 }
 
@@ -122,6 +172,10 @@ func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
 		return response.NotFound(c, "Transaction not found")
 	}
 
+	if transaction.UserID != middleware.UserID(c) {
+		return response.Forbidden(c, "Access denied")
+	}
+
 	return response.Success(c, transaction, "")
 }
 
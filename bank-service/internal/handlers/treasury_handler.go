@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/services"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/crypto-bank/bank-service/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TreasuryHandler struct {
+	treasuryService *services.TreasuryService
+}
+
+func NewTreasuryHandler(treasuryService *services.TreasuryService) *TreasuryHandler {
+	return &TreasuryHandler{
+		treasuryService: treasuryService,
+	}
+}
+
+// GetWallets godoc
+// @Summary Get house hot and cold wallets
+// @Tags treasury
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.TreasuryWallet}
+// @Router /admin/v1/treasury/wallets [get]
+func (h *TreasuryHandler) GetWallets(c *fiber.Ctx) error {
+	wallets, err := h.treasuryService.GetWallets()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get treasury wallets", err)
+	}
+
+	return response.Success(c, wallets, "")
+}
+
+// Deposit godoc
+// @Summary Record a deposit into a house wallet
+// @Tags treasury
+// @Accept json
+// @Produce json
+// @Param crypto_type path string true "Crypto type"
+// @Param deposit body models.TreasuryDepositRequest true "Deposit data"
+// @Success 200 {object} response.Response{data=models.TreasuryWallet}
+// @Router /admin/v1/treasury/wallets/{crypto_type}/deposit [post]
+func (h *TreasuryHandler) Deposit(c *fiber.Ctx) error {
+	var req models.TreasuryDepositRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	wallet, err := h.treasuryService.Deposit(cryptoTypeParam(c), &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to record deposit", err)
+	}
+
+	return response.Success(c, wallet, "Deposit recorded")
+}
+
+// GetPolicies godoc
+// @Summary Get hot wallet policies
+// @Tags treasury
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.TreasuryPolicy}
+// @Router /admin/v1/treasury/policies [get]
+func (h *TreasuryHandler) GetPolicies(c *fiber.Ctx) error {
+	policies, err := h.treasuryService.GetPolicies()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get treasury policies", err)
+	}
+
+	return response.Success(c, policies, "")
+}
+
+// UpdatePolicy godoc
+// @Summary Update the hot wallet policy of a crypto type
+// @Tags treasury
+// @Accept json
+// @Produce json
+// @Param crypto_type path string true "Crypto type"
+// @Param policy body models.UpdateTreasuryPolicyRequest true "Policy data"
+// @Success 200 {object} response.Response{data=models.TreasuryPolicy}
+// @Router /admin/v1/treasury/policies/{crypto_type} [put]
+func (h *TreasuryHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req models.UpdateTreasuryPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err)
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	policy, err := h.treasuryService.UpdatePolicy(cryptoTypeParam(c), &req)
+	if err != nil {
+		return response.BadRequest(c, "Failed to update treasury policy", err)
+	}
+
+	return response.Success(c, policy, "Treasury policy updated")
+}
+
+// GetReport godoc
+// @Summary Compare customer liabilities with house holdings
+// @Tags treasury
+// @Produce json
+// @Success 200 {object} response.Response{data=models.TreasuryReport}
+// @Router /admin/v1/treasury/report [get]
+func (h *TreasuryHandler) GetReport(c *fiber.Ctx) error {
+	report, err := h.treasuryService.Report()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to build treasury report", err)
+	}
+
+	return response.Success(c, report, "")
+}
+
+// GetMovements godoc
+// @Summary Get sweep and replenishment movements
+// @Tags treasury
+// @Produce json
+// @Param status query string false "Filter by status (PROPOSED, EXECUTED, CANCELLED)"
+// @Success 200 {object} response.Response{data=[]models.TreasuryMovement}
+// @Router /admin/v1/treasury/movements [get]
+func (h *TreasuryHandler) GetMovements(c *fiber.Ctx) error {
+	status := models.TreasuryMovementStatus(strings.ToUpper(c.Query("status")))
+
+	movements, err := h.treasuryService.GetMovements(status)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get treasury movements", err)
+	}
+
+	return response.Success(c, movements, "")
+}
+
+// ProposeSweeps godoc
+// @Summary Check hot wallet ratios and propose sweeps to cold storage now
+// @Tags treasury
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.TreasuryMovement}
+// @Router /admin/v1/treasury/movements/sweep-check [post]
+func (h *TreasuryHandler) ProposeSweeps(c *fiber.Ctx) error {
+	movements, err := h.treasuryService.ProposeSweeps()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to propose sweeps", err)
+	}
+
+	return response.Success(c, movements, "")
+}
+
+// ExecuteMovement godoc
+// @Summary Mark a proposed movement as executed and move the funds
+// @Tags treasury
+// @Produce json
+// @Param id path string true "Movement ID"
+// @Success 200 {object} response.Response{data=models.TreasuryMovement}
+// @Router /admin/v1/treasury/movements/{id}/execute [post]
+func (h *TreasuryHandler) ExecuteMovement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid movement ID", err)
+	}
+
+	movement, err := h.treasuryService.ExecuteMovement(auditContext(c), id)
+	if err != nil {
+		return response.Conflict(c, "Failed to execute movement", err)
+	}
+
+	return response.Success(c, movement, "Movement executed")
+}
+
+// CancelMovement godoc
+// @Summary Cancel a proposed movement
+// @Tags treasury
+// @Produce json
+// @Param id path string true "Movement ID"
+// @Success 200 {object} response.Response{data=models.TreasuryMovement}
+// @Router /admin/v1/treasury/movements/{id}/cancel [post]
+func (h *TreasuryHandler) CancelMovement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid movement ID", err)
+	}
+
+	movement, err := h.treasuryService.CancelMovement(id)
+	if err != nil {
+		return response.Conflict(c, "Failed to cancel movement", err)
+	}
+
+	return response.Success(c, movement, "Movement cancelled")
+}
+
+func cryptoTypeParam(c *fiber.Ctx) models.CryptoType {
+	return models.CryptoType(strings.ToUpper(c.Params("crypto_type")))
+}
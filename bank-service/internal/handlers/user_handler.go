@@ -11,21 +11,26 @@ import (
 
 type UserHandler struct {
 	userService *services.UserService
+	authService *services.AuthService
+	audit       *services.AuditService
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService *services.UserService, authService *services.AuthService, audit *services.AuditService) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		authService: authService,
+		audit:       audit,
 	}
 }
 
 // CreateUser godoc
-// @Summary Create a new user
+// @Summary Create a new user and open a session for it
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body models.CreateUserRequest true "User data"
-// @Success 201 {object} response.Response{data=models.User}
+// @Success 201 {object} response.Response{data=models.AuthSession}
+// @Success 202 {object} response.Response{data=models.ScreeningHoldReceipt}
 // @Router /api/v1/users [post]
 func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	var req models.CreateUserRequest
@@ -37,12 +42,25 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	user, err := h.userService.CreateUser(&req)
+	user, err := h.userService.CreateUser(c.UserContext(), &req)
+	if handled, respErr := screeningHeld(c, err); handled {
+		return respErr
+	}
 	if err != nil {
 		return response.InternalServerError(c, "Failed to create user", err)
 	}
 
-	return response.Created(c, user, "User created successfully")
+	// Sign-ups have no session yet; the new user is the actor
+	origin := auditOrigin(c)
+	origin.ActorID = &user.ID
+	h.audit.Record(origin, models.AuditUserCreated, models.AuditEntityUser, user.ID.String(), nil, user)
+
+	tokens, err := h.authService.IssueTokens(user)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to open session", err)
+	}
+
+	return response.Created(c, &models.AuthSession{User: user, Tokens: tokens}, "User created successfully")
 }
 
 // GetUser godoc
@@ -66,21 +84,6 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 	return response.Success(c, user, "")
 }
 
-// GetAllUsers godoc
-// @Summary Get all users
-// @Tags users
-// @Produce json
-// @Success 200 {object} response.Response{data=[]models.User}
-// @Router /api/v1/users [get]
-func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
-	users, err := h.userService.GetAllUsers()
-	if err != nil {
-		return response.InternalServerError(c, "Failed to get users", err)
-	}
-
-	return response.Success(c, users, "")
-}
-
 // UpdateUser godoc
 // @Summary Update user
 // @Tags users
@@ -89,6 +92,7 @@ func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 // @Param id path string true "User ID"
 // @Param user body models.UpdateUserRequest true "User data"
 // @Success 200 {object} response.Response
+// @Success 202 {object} response.Response{data=models.ScreeningHoldReceipt}
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
@@ -101,29 +105,23 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", err)
 	}
 
-	if err := h.userService.UpdateUser(id, &req); err != nil {
-		return response.InternalServerError(c, "Failed to update user", err)
+	before, err := h.userService.GetUser(id)
+	if err != nil {
+		return response.NotFound(c, "User not found")
 	}
 
-	return response.Success(c, nil, "User updated successfully")
-}
-
-// DeleteUser godoc
-// @Summary Delete user
-// @Tags users
-// @Param id path string true "User ID"
-// @Success 200 {object} response.Response
-// @Router /api/v1/users/{id} [delete]
-func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
-	id, err := uuid.Parse(c.Params("id"))
+	err = h.userService.UpdateUser(c.UserContext(), id, &req)
+	if handled, respErr := screeningHeld(c, err); handled {
+		return respErr
+	}
 	if err != nil {
-		return response.BadRequest(c, "Invalid user ID", err)
+		return response.InternalServerError(c, "Failed to update user", err)
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
-		return response.InternalServerError(c, "Failed to delete user", err)
+	if after, err := h.userService.GetUser(id); err == nil {
+		h.audit.Record(auditOrigin(c), models.AuditUserUpdated, models.AuditEntityUser, id.String(), before, after)
 	}
 
-	return response.Success(c, nil, "User deleted successfully")
+	return response.Success(c, nil, "User updated successfully")
 }
 
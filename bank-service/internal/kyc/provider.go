@@ -0,0 +1,53 @@
+package kyc
+
+import (
+	"context"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// Outcome is the verdict of a verification provider
+type Outcome string
+
+const (
+	OutcomeApprove Outcome = "approve"
+	OutcomeReject  Outcome = "reject"
+	// OutcomeReview hands the application to compliance staff
+	OutcomeReview Outcome = "review"
+)
+
+// Document is a submitted document as sent to the provider
+type Document struct {
+	Type        models.KYCDocumentType
+	ContentType string
+	Content     []byte
+}
+
+// VerificationRequest carries everything a provider needs to check an applicant
+type VerificationRequest struct {
+	ApplicationID uuid.UUID
+	RequestedTier models.KYCTier
+	FirstName     string
+	LastName      string
+	Email         string
+	DateOfBirth   time.Time
+	Country       string
+	Documents     []Document
+}
+
+// Decision is the answer of a provider. Reference identifies the check on the
+// provider side, Reason explains rejections and reviews.
+type Decision struct {
+	Outcome   Outcome
+	Reference string
+	Reason    string
+}
+
+// Provider verifies the identity of an applicant. Implementations talk to an
+// identity verification vendor; errors mean the check could not be run.
+type Provider interface {
+	Name() string
+	Verify(ctx context.Context, req *VerificationRequest) (*Decision, error)
+}
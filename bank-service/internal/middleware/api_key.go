@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Headers of a signed API request
+const (
+	HeaderAPIKey       = "X-API-Key"
+	HeaderAPITimestamp = "X-API-Timestamp"
+	HeaderAPINonce     = "X-API-Nonce"
+	HeaderAPISignature = "X-API-Signature"
+)
+
+const apiKeyLocal = "auth_api_key"
+
+// APIKeyAuthenticator validates signed API key requests
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(req *auth.SignedRequest) (*models.APIKey, error)
+}
+
+// AuthOrAPIKey returns a Fiber middleware that accepts either a bearer access
+// token or a request signed with an API key. Routes behind it must declare
+// what API keys may do with RequireScope or SessionOnly.
+func AuthOrAPIKey(verifier TokenVerifier, keys APIKeyAuthenticator) fiber.Handler {
+	bearer := Auth(verifier)
+	return func(c *fiber.Ctx) error {
+		keyID := c.Get(HeaderAPIKey)
+		if keyID == "" {
+			return bearer(c)
+		}
+
+		key, err := keys.AuthenticateAPIKey(&auth.SignedRequest{
+			KeyID:     keyID,
+			Timestamp: c.Get(HeaderAPITimestamp),
+			Nonce:     c.Get(HeaderAPINonce),
+			Signature: c.Get(HeaderAPISignature),
+			Method:    c.Method(),
+			Path:      c.OriginalURL(),
+			Body:      c.Body(),
+			IP:        c.IP(),
+		})
+		if err != nil {
+			return response.Unauthorized(c, "Invalid API key signature")
+		}
+
+		c.Locals(userIDLocal, key.UserID)
+		c.Locals(apiKeyLocal, key)
+
+		return c.Next()
+	}
+}
+
+// RequireScope returns a Fiber middleware that only lets API keys granting the
+// scope through. Bearer token sessions are not limited by scopes.
+func RequireScope(scope models.APIKeyScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key := APIKey(c); key != nil && !key.HasScope(scope) {
+			return response.Forbidden(c, "API key lacks the "+string(scope)+" scope")
+		}
+		return c.Next()
+	}
+}
+
+// SessionOnly returns a Fiber middleware that keeps API keys out of a route
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if APIKey(c) != nil {
+			return response.Forbidden(c, "Not available to API keys")
+		}
+		return c.Next()
+	}
+}
+
+// APIKey returns the API key of a signed request, or nil for bearer token sessions
+func APIKey(c *fiber.Ctx) *models.APIKey {
+	key, _ := c.Locals(apiKeyLocal).(*models.APIKey)
+	return key
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	userIDLocal = "auth_user_id"
+	claimsLocal = "auth_claims"
+)
+
+// TokenVerifier validates bearer access tokens
+type TokenVerifier interface {
+	VerifyAccessToken(token string) (*auth.Claims, error)
+}
+
+// Auth returns a Fiber middleware that requires a valid bearer access token and
+// stores the authenticated user for the handlers
+func Auth(verifier TokenVerifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return response.Unauthorized(c, "Missing bearer token")
+		}
+
+		claims, err := verifier.VerifyAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, auth.ErrExpiredToken) {
+				return response.Unauthorized(c, "Token has expired")
+			}
+			return response.Unauthorized(c, "Invalid token")
+		}
+
+		userID, err := claims.UserID()
+		if err != nil {
+			return response.Unauthorized(c, "Invalid token")
+		}
+
+		c.Locals(userIDLocal, userID)
+		c.Locals(claimsLocal, claims)
+
+		return c.Next()
+	}
+}
+
+// RequireSelf returns a Fiber middleware that only lets users reach routes whose
+// user ID path parameter is their own
+func RequireSelf(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params(param))
+		if err != nil {
+			return response.BadRequest(c, "Invalid user ID", err)
+		}
+
+		if id != UserID(c) {
+			return response.Forbidden(c, "Access denied")
+		}
+
+		return c.Next()
+	}
+}
+
+// UserID returns the authenticated user, or uuid.Nil outside of Auth
+func UserID(c *fiber.Ctx) uuid.UUID {
+	userID, _ := c.Locals(userIDLocal).(uuid.UUID)
+	return userID
+}
+
+// Claims returns the verified token claims, or nil outside of Auth
+func Claims(c *fiber.Ctx) *auth.Claims {
+	claims, _ := c.Locals(claimsLocal).(*auth.Claims)
+	return claims
+}
+
+// RequireStepUp returns a Fiber middleware that only lets requests through whose
+// access token carries a recent second factor check
+func RequireStepUp() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !SteppedUp(c) {
+			return StepUpChallenge(c)
+		}
+		return c.Next()
+	}
+}
+
+// SteppedUp reports whether the access token carries a second factor check that
+// is still fresh. API keys count as stepped up: creating a key with money
+// moving scopes already required a second factor, and scopes limit the rest.
+func SteppedUp(c *fiber.Ctx) bool {
+	if APIKey(c) != nil {
+		return true
+	}
+	claims := Claims(c)
+	return claims != nil && claims.SteppedUp(time.Now())
+}
+
+// StepUpChallenge asks the client to repeat the request with a stepped-up
+// access token (RFC 9470)
+func StepUpChallenge(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_user_authentication", error_description="A second factor is required"`)
+	return response.Unauthorized(c, "Step-up authentication required")
+}
@@ -18,6 +18,7 @@ func Logger() fiber.Handler {
 
 		// Log request
 		logger.Info("HTTP Request",
+			zap.String("request_id", GetRequestID(c)),
 			zap.String("method", c.Method()),
 			zap.String("path", c.Path()),
 			zap.Int("status", c.Response().StatusCode()),
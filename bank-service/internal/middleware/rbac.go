@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequireStaff returns a Fiber middleware that keeps customers out of the admin API
+func RequireStaff() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !auth.IsStaff(Role(c)) {
+			return response.Forbidden(c, "Access denied")
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermission returns a Fiber middleware that only lets roles granting the
+// permission through
+func RequirePermission(permission auth.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := Role(c)
+		if !auth.Can(role, permission) {
+			logger.Warn("Permission denied",
+				zap.String("user_id", UserID(c).String()),
+				zap.String("role", string(role)),
+				zap.String("permission", string(permission)),
+				zap.String("path", c.Path()),
+			)
+			return response.Forbidden(c, "Insufficient permissions")
+		}
+		return c.Next()
+	}
+}
+
+// Role returns the role of the authenticated user, or customer outside of Auth
+func Role(c *fiber.Ctx) models.Role {
+	claims := Claims(c)
+	if claims == nil {
+		return models.RoleCustomer
+	}
+	return claims.UserRole()
+}
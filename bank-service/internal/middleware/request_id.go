@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+const requestIDLocal = "request_id"
+
+// RequestID returns a Fiber middleware that tags every request with an ID. A
+// caller's X-Request-ID is kept so the ID can be followed across services; the
+// ID is echoed back in the response.
+func RequestID() fiber.Handler {
+	return requestid.New(requestid.Config{
+		ContextKey: requestIDLocal,
+	})
+}
+
+// GetRequestID returns the ID of the request, or "" outside of RequestID
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDLocal).(string)
+	return id
+}
@@ -80,3 +80,12 @@ func (fc *fiberCarrier) Keys() []string {
 	})
 	return keys
 }
+
+// TraceID returns the trace of the request, or "" when it is not traced
+func TraceID(c *fiber.Ctx) string {
+	spanContext := trace.SpanContextFromContext(c.UserContext())
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
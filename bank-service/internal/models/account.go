@@ -6,16 +6,9 @@ import (
 	"github.com/google/uuid"
 )
 
-// CurrencyType represents fiat currency types
+// CurrencyType is the code of a fiat asset in the asset registry
 type CurrencyType string
 
-const (
-	CurrencyUSD CurrencyType = "USD"
-	CurrencyEUR CurrencyType = "EUR"
-	CurrencyRUB CurrencyType = "RUB"
-	CurrencyGBP CurrencyType = "GBP"
-)
-
 // Account represents a fiat currency account
 type Account struct {
 	ID        uuid.UUID    `json:"id" db:"id"`
@@ -28,7 +21,7 @@ type Account struct {
 
 type CreateAccountRequest struct {
 	UserID   uuid.UUID    `json:"user_id" validate:"required"`
-	Currency CurrencyType `json:"currency" validate:"required,oneof=USD EUR RUB GBP"`
+	Currency CurrencyType `json:"currency" validate:"required,uppercase,max=10"`
 }
 
 type AccountWithUser struct {
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope limits what an API key may do
+type APIKeyScope string
+
+const (
+	ScopeReadBalances APIKeyScope = "read:balances"
+	ScopeTrade        APIKeyScope = "trade"
+	ScopeTransfer     APIKeyScope = "transfer"
+	ScopeWithdraw     APIKeyScope = "withdraw"
+)
+
+// APIKey is a long-lived credential for programmatic access. The secret is only
+// shown once, when the key is created.
+type APIKey struct {
+	ID               uuid.UUID     `json:"id" db:"id"`
+	UserID           uuid.UUID     `json:"user_id" db:"user_id"`
+	Name             string        `json:"name" db:"name"`
+	KeyID            string        `json:"key_id" db:"key_id"`
+	SecretCiphertext []byte        `json:"-" db:"secret_ciphertext"`
+	Scopes           []APIKeyScope `json:"scopes" db:"scopes"`
+	AllowedIPs       []string      `json:"allowed_ips" db:"allowed_ips"`
+	ExpiresAt        *time.Time    `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt        *time.Time    `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt       *time.Time    `json:"last_used_at,omitempty" db:"last_used_at"`
+	LastUsedIP       *string       `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	UsageCount       int64         `json:"usage_count" db:"usage_count"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+}
+
+// HasScope reports whether the key grants a scope
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatedAPIKey is returned once on creation and carries the signing secret
+type CreatedAPIKey struct {
+	APIKey
+	Secret string `json:"secret"`
+}
+
+// APIKeyUsage is the number of requests made with a key on one day
+type APIKeyUsage struct {
+	Day      time.Time `json:"day" db:"day"`
+	Requests int64     `json:"requests" db:"requests"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string        `json:"name" validate:"required,max=100"`
+	Scopes     []APIKeyScope `json:"scopes" validate:"required,min=1,dive,oneof=read:balances trade transfer withdraw"`
+	AllowedIPs []string      `json:"allowed_ips" validate:"max=20,dive,cidr|ip"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalOperation represents the kind of operation guarded by an approval policy
+type ApprovalOperation string
+
+const (
+	ApprovalOpCryptoWithdraw ApprovalOperation = "CRYPTO_WITHDRAW"
+	ApprovalOpFiatWithdraw   ApprovalOperation = "FIAT_WITHDRAW"
+	ApprovalOpCryptoToFiat   ApprovalOperation = "CRYPTO_TO_FIAT"
+	ApprovalOpFiatToCrypto   ApprovalOperation = "FIAT_TO_CRYPTO"
+	ApprovalOpPolicyChange   ApprovalOperation = "POLICY_CHANGE"
+)
+
+// ApprovalStatus represents the status of an approval request
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "PENDING"
+	ApprovalStatusApproved ApprovalStatus = "APPROVED"
+	ApprovalStatusRejected ApprovalStatus = "REJECTED"
+	ApprovalStatusExpired  ApprovalStatus = "EXPIRED"
+	ApprovalStatusExecuted ApprovalStatus = "EXECUTED"
+	ApprovalStatusFailed   ApprovalStatus = "FAILED"
+)
+
+// ApprovalDecisionType represents a single approver's vote
+type ApprovalDecisionType string
+
+const (
+	ApprovalDecisionApprove ApprovalDecisionType = "APPROVE"
+	ApprovalDecisionReject  ApprovalDecisionType = "REJECT"
+)
+
+// ApprovalPolicy requires M distinct approvers for operations at or above Threshold
+type ApprovalPolicy struct {
+	ID                uuid.UUID   `json:"id" db:"id"`
+	WalletID          *uuid.UUID  `json:"wallet_id,omitempty" db:"wallet_id"`
+	AccountID         *uuid.UUID  `json:"account_id,omitempty" db:"account_id"`
+	Threshold         float64     `json:"threshold" db:"threshold"`
+	RequiredApprovals int         `json:"required_approvals" db:"required_approvals"`
+	TTLSeconds        int         `json:"ttl_seconds" db:"ttl_seconds"`
+	Enabled           bool        `json:"enabled" db:"enabled"`
+	Approvers         []uuid.UUID `json:"approvers"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// ApprovalRequest is an operation parked until the policy quorum is reached
+type ApprovalRequest struct {
+	ID                uuid.UUID          `json:"id" db:"id"`
+	PolicyID          uuid.UUID          `json:"policy_id" db:"policy_id"`
+	UserID            uuid.UUID          `json:"user_id" db:"user_id"`
+	Operation         ApprovalOperation  `json:"operation" db:"operation"`
+	Status            ApprovalStatus     `json:"status" db:"status"`
+	Amount            float64            `json:"amount" db:"amount"`
+	Currency          string             `json:"currency" db:"currency"`
+	Payload           json.RawMessage    `json:"payload" db:"payload"`
+	RequiredApprovals int                `json:"required_approvals" db:"required_approvals"`
+	ResultID          *uuid.UUID         `json:"result_id,omitempty" db:"result_id"`
+	FailureReason     *string            `json:"failure_reason,omitempty" db:"failure_reason"`
+	ExpiresAt         time.Time          `json:"expires_at" db:"expires_at"`
+	Decisions         []ApprovalDecision `json:"decisions,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// ApprovalDecision is one entry of the approval trail
+type ApprovalDecision struct {
+	ID         uuid.UUID            `json:"id" db:"id"`
+	RequestID  uuid.UUID            `json:"request_id" db:"request_id"`
+	ApproverID uuid.UUID            `json:"approver_id" db:"approver_id"`
+	Decision   ApprovalDecisionType `json:"decision" db:"decision"`
+	Comment    string               `json:"comment,omitempty" db:"comment"`
+	CreatedAt  time.Time            `json:"created_at" db:"created_at"`
+}
+
+type SetApprovalPolicyRequest struct {
+	Threshold         float64     `json:"threshold" validate:"required,gt=0"`
+	RequiredApprovals int         `json:"required_approvals" validate:"required,gt=0"`
+	TTLSeconds        int         `json:"ttl_seconds" validate:"omitempty,gt=0"`
+	Approvers         []uuid.UUID `json:"approvers" validate:"required,min=1,unique"`
+	Enabled           *bool       `json:"enabled"`
+}
+
+// ApprovalPolicyChange is the payload of a parked change to a policy in force
+type ApprovalPolicyChange struct {
+	WalletID  *uuid.UUID               `json:"wallet_id,omitempty"`
+	AccountID *uuid.UUID               `json:"account_id,omitempty"`
+	Policy    SetApprovalPolicyRequest `json:"policy"`
+}
+
+type ApprovalDecisionRequest struct {
+	ApproverID uuid.UUID `json:"approver_id" validate:"required"`
+	Comment    string    `json:"comment"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// AssetKind distinguishes fiat currencies from crypto assets
+type AssetKind string
+
+const (
+	AssetKindFiat   AssetKind = "FIAT"
+	AssetKindCrypto AssetKind = "CRYPTO"
+)
+
+// Asset is an entry of the asset registry
+type Asset struct {
+	Code             string    `json:"code" db:"code"`
+	Name             string    `json:"name" db:"name"`
+	Kind             AssetKind `json:"kind" db:"kind"`
+	Decimals         int       `json:"decimals" db:"decimals"`
+	Chain            string    `json:"chain,omitempty" db:"chain"`
+	AddressPrefix    string    `json:"address_prefix,omitempty" db:"address_prefix"`
+	AddressPattern   string    `json:"address_pattern,omitempty" db:"address_pattern"`
+	Enabled          bool      `json:"enabled" db:"enabled"`
+	MinAmount        float64   `json:"min_amount" db:"min_amount"`
+	MinWithdrawal    float64   `json:"min_withdrawal" db:"min_withdrawal"`
+	ReferenceUSDRate *float64  `json:"reference_usd_rate,omitempty" db:"reference_usd_rate"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ValidateAmount checks the amount against the asset minimum and precision
+func (a *Asset) ValidateAmount(amount float64) error {
+	if amount < a.MinAmount {
+		return fmt.Errorf("amount %f is below the %s minimum of %f", amount, a.Code, a.MinAmount)
+	}
+
+	scaled := amount * math.Pow10(a.Decimals)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+		return fmt.Errorf("%s supports at most %d decimal places", a.Code, a.Decimals)
+	}
+
+	return nil
+}
+
+// USDValue converts an amount to US dollars with the reference rate. It reports
+// false when the asset has no reference rate.
+func (a *Asset) USDValue(amount float64) (float64, bool) {
+	if a.Code == "USD" {
+		return amount, true
+	}
+	if a.ReferenceUSDRate == nil {
+		return 0, false
+	}
+	return amount * *a.ReferenceUSDRate, true
+}
+
+// ValidateWithdrawal checks an external withdrawal against the asset rules
+func (a *Asset) ValidateWithdrawal(address string, amount float64) error {
+	if err := a.ValidateAmount(amount); err != nil {
+		return err
+	}
+
+	if amount < a.MinWithdrawal {
+		return fmt.Errorf("withdrawal %f is below the %s minimum of %f", amount, a.Code, a.MinWithdrawal)
+	}
+
+	return a.ValidateAddress(address)
+}
+
+// ValidateAddress checks an external address against the address pattern of the asset
+func (a *Asset) ValidateAddress(address string) error {
+	if a.AddressPattern != "" {
+		matched, err := regexp.MatchString(a.AddressPattern, address)
+		if err != nil {
+			return fmt.Errorf("invalid %s address pattern: %w", a.Code, err)
+		}
+		if !matched {
+			return fmt.Errorf("invalid %s address on %s", a.Code, a.Chain)
+		}
+	}
+
+	return nil
+}
+
+type CreateAssetRequest struct {
+	Code             string    `json:"code" validate:"required,uppercase,alphanum,min=2,max=10"`
+	Name             string    `json:"name" validate:"required,max=100"`
+	Kind             AssetKind `json:"kind" validate:"required,oneof=FIAT CRYPTO"`
+	Decimals         int       `json:"decimals" validate:"gte=0,lte=8"`
+	Chain            string    `json:"chain" validate:"required_if=Kind CRYPTO,max=50"`
+	AddressPrefix    string    `json:"address_prefix" validate:"max=20"`
+	AddressPattern   string    `json:"address_pattern" validate:"max=255"`
+	MinAmount        float64   `json:"min_amount" validate:"gte=0"`
+	MinWithdrawal    float64   `json:"min_withdrawal" validate:"gte=0"`
+	ReferenceUSDRate *float64  `json:"reference_usd_rate" validate:"omitempty,gt=0"`
+	Enabled          *bool     `json:"enabled"`
+}
+
+type UpdateAssetRequest struct {
+	Name             *string  `json:"name" validate:"omitempty,max=100"`
+	Decimals         *int     `json:"decimals" validate:"omitempty,gte=0,lte=8"`
+	Chain            *string  `json:"chain" validate:"omitempty,max=50"`
+	AddressPrefix    *string  `json:"address_prefix" validate:"omitempty,max=20"`
+	AddressPattern   *string  `json:"address_pattern" validate:"omitempty,max=255"`
+	MinAmount        *float64 `json:"min_amount" validate:"omitempty,gte=0"`
+	MinWithdrawal    *float64 `json:"min_withdrawal" validate:"omitempty,gte=0"`
+	ReferenceUSDRate *float64 `json:"reference_usd_rate" validate:"omitempty,gt=0"`
+}
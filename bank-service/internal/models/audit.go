@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditActorType tells who made a change
+type AuditActorType string
+
+const (
+	AuditActorUser   AuditActorType = "user"
+	AuditActorAPIKey AuditActorType = "api_key"
+	AuditActorAdmin  AuditActorType = "admin"
+	AuditActorSystem AuditActorType = "system"
+)
+
+// AuditEntityType names the kind of entity a change applies to. Reference rates
+// are part of an asset; exchange rates set in the exchange service are rates,
+// identified by their pair.
+type AuditEntityType string
+
+const (
+	AuditEntityUser             AuditEntityType = "user"
+	AuditEntityAccount          AuditEntityType = "account"
+	AuditEntityWallet           AuditEntityType = "wallet"
+	AuditEntityTransaction      AuditEntityType = "transaction"
+	AuditEntityExchange         AuditEntityType = "exchange"
+	AuditEntityAsset            AuditEntityType = "asset"
+	AuditEntityAPIKey           AuditEntityType = "api_key"
+	AuditEntityApprovalRequest  AuditEntityType = "approval_request"
+	AuditEntityScreeningHold    AuditEntityType = "screening_hold"
+	AuditEntityKYCApplication   AuditEntityType = "kyc_application"
+	AuditEntityLimitRule        AuditEntityType = "limit_rule"
+	AuditEntityTreasuryMovement AuditEntityType = "treasury_movement"
+	AuditEntityRate             AuditEntityType = "rate"
+)
+
+// AuditAction names what was done to an entity
+type AuditAction string
+
+const (
+	AuditUserCreated             AuditAction = "user.created"
+	AuditUserUpdated             AuditAction = "user.updated"
+	AuditUserRoleChanged         AuditAction = "user.role_changed"
+	AuditUserDeleted             AuditAction = "user.deleted"
+	AuditUserPasswordChanged     AuditAction = "user.password_changed"
+	AuditUserTwoFactorEnabled    AuditAction = "user.two_factor_enabled"
+	AuditUserTwoFactorDisabled   AuditAction = "user.two_factor_disabled"
+	AuditAccountCreated          AuditAction = "account.created"
+	AuditWalletCreated           AuditAction = "wallet.created"
+	AuditWalletWhitelistAdded    AuditAction = "wallet.whitelist_added"
+	AuditWalletWhitelistRemoved  AuditAction = "wallet.whitelist_removed"
+	AuditTransferCreated         AuditAction = "transaction.transfer"
+	AuditDepositCreated          AuditAction = "transaction.deposit"
+	AuditWithdrawalCreated       AuditAction = "transaction.withdraw"
+	AuditCryptoWithdrawalCreated AuditAction = "transaction.crypto_withdraw"
+	AuditExchangeCreated         AuditAction = "exchange.created"
+	AuditAssetCreated            AuditAction = "asset.created"
+	AuditAssetUpdated            AuditAction = "asset.updated"
+	AuditAssetEnabled            AuditAction = "asset.enabled"
+	AuditAssetDisabled           AuditAction = "asset.disabled"
+	AuditAPIKeyCreated           AuditAction = "api_key.created"
+	AuditAPIKeyRevoked           AuditAction = "api_key.revoked"
+	AuditApprovalExecuted        AuditAction = "approval_request.executed"
+	AuditScreeningReleased       AuditAction = "screening_hold.released"
+	AuditScreeningRejected       AuditAction = "screening_hold.rejected"
+	AuditKYCApproved             AuditAction = "kyc_application.approved"
+	AuditKYCRejected             AuditAction = "kyc_application.rejected"
+	AuditLimitRuleSet            AuditAction = "limit_rule.set"
+	AuditLimitRuleDeleted        AuditAction = "limit_rule.deleted"
+	AuditTreasuryExecuted        AuditAction = "treasury_movement.executed"
+	AuditRateUpdated             AuditAction = "rate.updated"
+)
+
+// AuditOrigin is who made a change and the request it came with
+type AuditOrigin struct {
+	ActorType AuditActorType
+	ActorID   *uuid.UUID
+	APIKeyID  *uuid.UUID
+	ActorRole string
+	RequestID string
+	TraceID   string
+	IP        string
+}
+
+// System returns the origin of a change the system made while serving the
+// request, such as a decision of an outside provider
+func (o AuditOrigin) System() AuditOrigin {
+	return AuditOrigin{
+		ActorType: AuditActorSystem,
+		RequestID: o.RequestID,
+		TraceID:   o.TraceID,
+		IP:        o.IP,
+	}
+}
+
+// RateChange is an exchange rate set in the exchange service, which reports it
+// for the audit trail
+type RateChange struct {
+	FromCurrency string   `json:"from_currency" validate:"required,min=2,max=10"`
+	ToCurrency   string   `json:"to_currency" validate:"required,min=2,max=10"`
+	PreviousRate *float64 `json:"previous_rate" validate:"omitempty,gt=0"`
+	Rate         float64  `json:"rate" validate:"gt=0"`
+}
+
+// AuditFilter selects audit log entries, oldest first
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	EntityType AuditEntityType
+	EntityID   string
+	Action     AuditAction
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	// AfterSeq pages through the log: only entries after it are returned
+	AfterSeq int64
+	Limit    uint64
+}
+
+// AuditVerification is the outcome of checking the whole audit chain
+type AuditVerification struct {
+	Valid       bool      `json:"valid"`
+	Entries     int64     `json:"entries"`
+	HeadSeq     int64     `json:"head_seq"`
+	HeadHash    string    `json:"head_hash"`
+	BrokenAtSeq *int64    `json:"broken_at_seq,omitempty"`
+	Problem     string    `json:"problem,omitempty"`
+	VerifiedAt  time.Time `json:"verified_at"`
+}
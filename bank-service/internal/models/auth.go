@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is the server-side record of an issued refresh token
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty" db:"replaced_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TokenPair is returned to clients when a session is opened or refreshed. A
+// step-up returns only a new access token.
+type TokenPair struct {
+	AccessToken           string     `json:"access_token"`
+	RefreshToken          string     `json:"refresh_token,omitempty"`
+	TokenType             string     `json:"token_type"`
+	ExpiresAt             time.Time  `json:"expires_at"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	StepUpUntil           *time.Time `json:"step_up_until,omitempty"`
+}
+
+// AuthSession is a user together with the tokens of a new session
+type AuthSession struct {
+	User   *User      `json:"user"`
+	Tokens *TokenPair `json:"tokens"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// UserCredential holds the password hash and login lockout state of a user
+type UserCredential struct {
+	UserID            uuid.UUID  `json:"user_id" db:"user_id"`
+	PasswordHash      string     `json:"-" db:"password_hash"`
+	FailedAttempts    int        `json:"failed_attempts" db:"failed_attempts"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	PasswordChangedAt time.Time  `json:"password_changed_at" db:"password_changed_at"`
+}
+
+// UserTOTP is the TOTP enrollment of a user
+type UserTOTP struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty" db:"enabled_at"`
+}
+
+// TOTPEnrollment is returned when a user starts enrolling an authenticator app
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are shown to the user once; only their hashes are stored
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// TwoFactorStatus describes the second factor of a user
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RemainingRecoveryCodes int  `json:"remaining_recovery_codes"`
+}
+
+// LoginRequest carries the password and, for users with two-factor
+// authentication, a TOTP or recovery code
+type LoginRequest struct {
+	Email         string `json:"email" validate:"required,email"`
+	Password      string `json:"password" validate:"required"`
+	TwoFactorCode string `json:"two_factor_code"`
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=12,max=128"`
+}
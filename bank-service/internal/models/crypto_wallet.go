@@ -6,17 +6,9 @@ import (
 	"github.com/google/uuid"
 )
 
-// CryptoType represents cryptocurrency types
+// CryptoType is the code of a crypto asset in the asset registry
 type CryptoType string
 
-const (
-	CryptoBTC  CryptoType = "BTC"
-	CryptoETH  CryptoType = "ETH"
-	CryptoUSDT CryptoType = "USDT"
-	CryptoBNB  CryptoType = "BNB"
-	CryptoSOL  CryptoType = "SOL"
-)
-
 // CryptoWallet represents a cryptocurrency wallet
 type CryptoWallet struct {
 	ID        uuid.UUID  `json:"id" db:"id"`
@@ -30,7 +22,32 @@ type CryptoWallet struct {
 
 type CreateCryptoWalletRequest struct {
 	UserID     uuid.UUID  `json:"user_id" validate:"required"`
-	CryptoType CryptoType `json:"crypto_type" validate:"required,oneof=BTC ETH USDT BNB SOL"`
+	CryptoType CryptoType `json:"crypto_type" validate:"required,uppercase,max=10"`
+}
+
+type WithdrawCryptoRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	WalletID  uuid.UUID `json:"wallet_id" validate:"required"`
+	ToAddress string    `json:"to_address" validate:"required,min=10,max=255"`
+	Amount    float64   `json:"amount" validate:"required,gt=0"`
+
+	// Set by the handler for fraud scoring
+	SteppedUp bool       `json:"-"`
+	Client    ClientInfo `json:"-"`
+}
+
+// WhitelistedAddress is an external address crypto withdrawals of a wallet may go to
+type WhitelistedAddress struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	WalletID  uuid.UUID `json:"wallet_id" db:"wallet_id"`
+	Address   string    `json:"address" db:"address"`
+	Label     string    `json:"label" db:"label"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AddWhitelistedAddressRequest struct {
+	Address string `json:"address" validate:"required,min=10,max=255"`
+	Label   string `json:"label" validate:"max=100"`
 }
 
 type CryptoWalletWithUser struct {
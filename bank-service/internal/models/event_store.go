@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoredEvent is an event as it was published, kept in the event store.
+// Payload is the whole envelope.
+type StoredEvent struct {
+	Position      int64           `json:"position"`
+	EventID       uuid.UUID       `json:"event_id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Subject       string          `json:"subject,omitempty"`
+	CorrelationID string          `json:"correlation_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+	RecordedAt    time.Time       `json:"recorded_at"`
+}
+
+// EventFilter selects stored events, oldest first
+type EventFilter struct {
+	From *time.Time
+	To   *time.Time
+	// Subject is the ID of the entity the events are about
+	Subject string
+	// UserID selects the events about anything belonging to a user
+	UserID string
+	Types  []string
+	// Published leaves out events still waiting in the outbox; consumers get
+	// those live
+	Published bool
+	// AfterPosition pages through the store: only events after it are returned
+	AfterPosition int64
+	Limit         uint64
+}
+
+// ReplayRequest selects the events to re-publish to the replay exchange. Times
+// are RFC 3339; a replay stops after limit events.
+type ReplayRequest struct {
+	From          *time.Time `json:"from"`
+	To            *time.Time `json:"to"`
+	Subject       string     `json:"subject"`
+	UserID        string     `json:"user_id"`
+	Types         []string   `json:"types"`
+	AfterPosition int64      `json:"after_position"`
+	Limit         uint64     `json:"limit"`
+}
+
+// ReplayResult reports a replay. When Complete is false the limit stopped it;
+// another replay with AfterPosition set to LastPosition continues it.
+type ReplayResult struct {
+	ReplayID  uuid.UUID `json:"replay_id"`
+	Published int64     `json:"published"`
+	// Unroutable events had no replay queue bound for their type
+	Unroutable    int64     `json:"unroutable"`
+	FirstPosition int64     `json:"first_position,omitempty"`
+	LastPosition  int64     `json:"last_position,omitempty"`
+	Complete      bool      `json:"complete"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
@@ -50,6 +50,10 @@ type ExchangeCryptoToFiatRequest struct {
 	FromWalletID  uuid.UUID `json:"from_wallet_id" validate:"required"`
 	ToAccountID   uuid.UUID `json:"to_account_id" validate:"required"`
 	CryptoAmount  float64   `json:"crypto_amount" validate:"required,gt=0"`
+
+	// Set by the handler for fraud scoring
+	SteppedUp bool       `json:"-"`
+	Client    ClientInfo `json:"-"`
 }
 
 // ExchangeFiatToCryptoRequest represents request to exchange fiat to crypto
@@ -58,6 +62,10 @@ type ExchangeFiatToCryptoRequest struct {
 	FromAccountID uuid.UUID `json:"from_account_id" validate:"required"`
 	ToWalletID    uuid.UUID `json:"to_wallet_id" validate:"required"`
 	FiatAmount    float64   `json:"fiat_amount" validate:"required,gt=0"`
+
+	// Set by the handler for fraud scoring
+	SteppedUp bool       `json:"-"`
+	Client    ClientInfo `json:"-"`
 }
 
 // ExchangeRate represents current exchange rate
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KYCTier is the identity verification level of a user
+type KYCTier string
+
+const (
+	KYCTierUnverified KYCTier = "unverified"
+	KYCTierBasic      KYCTier = "basic"
+	KYCTierFull       KYCTier = "full"
+)
+
+// Rank orders tiers from least to most verified
+func (t KYCTier) Rank() int {
+	switch t {
+	case KYCTierBasic:
+		return 1
+	case KYCTierFull:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// KYCApplicationStatus represents the state of a KYC application
+type KYCApplicationStatus string
+
+const (
+	KYCStatusPending  KYCApplicationStatus = "pending"
+	KYCStatusInReview KYCApplicationStatus = "in_review"
+	KYCStatusApproved KYCApplicationStatus = "approved"
+	KYCStatusRejected KYCApplicationStatus = "rejected"
+)
+
+// KYCDocumentType names the kind of a submitted document
+type KYCDocumentType string
+
+const (
+	KYCDocPassport       KYCDocumentType = "passport"
+	KYCDocNationalID     KYCDocumentType = "national_id"
+	KYCDocDriversLicense KYCDocumentType = "drivers_license"
+	KYCDocProofOfAddress KYCDocumentType = "proof_of_address"
+	KYCDocSelfie         KYCDocumentType = "selfie"
+)
+
+// IsIdentity reports whether the document proves identity
+func (t KYCDocumentType) IsIdentity() bool {
+	return t == KYCDocPassport || t == KYCDocNationalID || t == KYCDocDriversLicense
+}
+
+// KYCApplication is a request of a user to move up a tier
+type KYCApplication struct {
+	ID                uuid.UUID            `json:"id" db:"id"`
+	UserID            uuid.UUID            `json:"user_id" db:"user_id"`
+	RequestedTier     KYCTier              `json:"requested_tier" db:"requested_tier"`
+	Status            KYCApplicationStatus `json:"status" db:"status"`
+	DateOfBirth       time.Time            `json:"date_of_birth" db:"date_of_birth"`
+	Country           string               `json:"country" db:"country"`
+	AddressLine       string               `json:"address_line" db:"address_line"`
+	City              string               `json:"city" db:"city"`
+	PostalCode        string               `json:"postal_code" db:"postal_code"`
+	Provider          string               `json:"provider" db:"provider"`
+	ProviderReference *string              `json:"provider_reference,omitempty" db:"provider_reference"`
+	ProviderReason    *string              `json:"provider_reason,omitempty" db:"provider_reason"`
+	ReviewerID        *uuid.UUID           `json:"reviewer_id,omitempty" db:"reviewer_id"`
+	ReviewNote        *string              `json:"review_note,omitempty" db:"review_note"`
+	ReviewedAt        *time.Time           `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt         time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at" db:"updated_at"`
+	Documents         []*KYCDocument       `json:"documents,omitempty"`
+}
+
+// KYCDocument describes a submitted document. The content is only served by
+// the review download endpoint.
+type KYCDocument struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	ApplicationID uuid.UUID       `json:"application_id" db:"application_id"`
+	Type          KYCDocumentType `json:"type" db:"type"`
+	FileName      string          `json:"file_name" db:"file_name"`
+	ContentType   string          `json:"content_type" db:"content_type"`
+	SizeBytes     int             `json:"size_bytes" db:"size_bytes"`
+	SHA256        string          `json:"sha256" db:"sha256"`
+	Content       []byte          `json:"-" db:"content"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// KYCTierLimits are the rolling 24 hour limits of a tier in US dollars
+type KYCTierLimits struct {
+	Tier               KYCTier `json:"tier" db:"tier"`
+	DepositDailyUSD    float64 `json:"deposit_daily_usd" db:"deposit_daily_usd"`
+	WithdrawalDailyUSD float64 `json:"withdrawal_daily_usd" db:"withdrawal_daily_usd"`
+	TransferDailyUSD   float64 `json:"transfer_daily_usd" db:"transfer_daily_usd"`
+	ExchangeDailyUSD   float64 `json:"exchange_daily_usd" db:"exchange_daily_usd"`
+}
+
+// Limit returns the daily limit of an operation
+func (l *KYCTierLimits) Limit(op LimitOperation) float64 {
+	switch op {
+	case LimitOpDeposit:
+		return l.DepositDailyUSD
+	case LimitOpWithdrawal:
+		return l.WithdrawalDailyUSD
+	case LimitOpTransfer:
+		return l.TransferDailyUSD
+	case LimitOpExchange:
+		return l.ExchangeDailyUSD
+	}
+	return 0
+}
+
+// KYCStatus is the verification state of a user as shown to the user
+type KYCStatus struct {
+	Tier         KYCTier           `json:"tier"`
+	Limits       *KYCTierLimits    `json:"limits"`
+	Applications []*KYCApplication `json:"applications"`
+}
+
+// SubmitKYCRequest represents a request to move up a tier. Document content is
+// base64 encoded in JSON.
+type SubmitKYCRequest struct {
+	RequestedTier KYCTier             `json:"requested_tier" validate:"required,oneof=basic full"`
+	DateOfBirth   string              `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Country       string              `json:"country" validate:"required,iso3166_1_alpha2"`
+	AddressLine   string              `json:"address_line" validate:"required,max=255"`
+	City          string              `json:"city" validate:"required,max=100"`
+	PostalCode    string              `json:"postal_code" validate:"required,max=20"`
+	Documents     []KYCDocumentUpload `json:"documents" validate:"required,min=1,max=5,dive"`
+}
+
+type KYCDocumentUpload struct {
+	Type        KYCDocumentType `json:"type" validate:"required,oneof=passport national_id drivers_license proof_of_address selfie"`
+	FileName    string          `json:"file_name" validate:"required,max=255"`
+	ContentType string          `json:"content_type" validate:"required,oneof=image/jpeg image/png application/pdf"`
+	Content     []byte          `json:"content" validate:"required,max=2097152"`
+}
+
+// ReviewKYCRequest carries the decision note of a compliance reviewer
+type ReviewKYCRequest struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LimitOperation names an operation that limits apply to
+type LimitOperation string
+
+const (
+	LimitOpDeposit    LimitOperation = "deposit"
+	LimitOpWithdrawal LimitOperation = "withdrawal"
+	LimitOpTransfer   LimitOperation = "transfer"
+	LimitOpExchange   LimitOperation = "exchange"
+)
+
+// LimitKind names a velocity limit
+type LimitKind string
+
+const (
+	LimitMaxSingleTransfer     LimitKind = "max_single_transfer"
+	LimitDailyOutflow          LimitKind = "daily_outflow"
+	LimitMonthlyOutflow        LimitKind = "monthly_outflow"
+	LimitDailyWithdrawalCount  LimitKind = "daily_withdrawal_count"
+	LimitDailyExchangeVolume   LimitKind = "daily_exchange_volume"
+	LimitMonthlyExchangeVolume LimitKind = "monthly_exchange_volume"
+)
+
+// AppliesTo reports whether the limit is evaluated for an operation. Outflow
+// covers transfers and withdrawals.
+func (k LimitKind) AppliesTo(op LimitOperation) bool {
+	switch k {
+	case LimitMaxSingleTransfer:
+		return op == LimitOpTransfer
+	case LimitDailyOutflow, LimitMonthlyOutflow:
+		return op == LimitOpTransfer || op == LimitOpWithdrawal
+	case LimitDailyWithdrawalCount:
+		return op == LimitOpWithdrawal
+	case LimitDailyExchangeVolume, LimitMonthlyExchangeVolume:
+		return op == LimitOpExchange
+	}
+	return false
+}
+
+// IsCount reports whether the limit counts operations rather than summing amounts
+func (k LimitKind) IsCount() bool {
+	return k == LimitDailyWithdrawalCount
+}
+
+// LimitScope says who a limit rule applies to
+type LimitScope string
+
+const (
+	LimitScopeGlobal LimitScope = "global"
+	LimitScopeTier   LimitScope = "tier"
+	LimitScopeUser   LimitScope = "user"
+)
+
+// Precedence orders scopes from least to most specific. The most specific rule
+// for a kind and currency wins.
+func (s LimitScope) Precedence() int {
+	switch s {
+	case LimitScopeTier:
+		return 1
+	case LimitScopeUser:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// LimitRule caps one kind of activity. Amounts are in Currency; a rule without
+// a currency caps the US dollar value across all currencies. Count rules without
+// a currency count operations in every currency.
+type LimitRule struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Scope     LimitScope `json:"scope" db:"scope"`
+	KYCTier   *KYCTier   `json:"kyc_tier,omitempty" db:"kyc_tier"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Kind      LimitKind  `json:"kind" db:"kind"`
+	Currency  *string    `json:"currency,omitempty" db:"currency"`
+	Value     float64    `json:"value" db:"value"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// LimitHeadroom is an effective limit of a user with its current usage
+type LimitHeadroom struct {
+	Kind      LimitKind  `json:"kind"`
+	Scope     LimitScope `json:"scope"`
+	Currency  *string    `json:"currency,omitempty"`
+	Limit     float64    `json:"limit"`
+	Used      float64    `json:"used"`
+	Remaining float64    `json:"remaining"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+// UserLimits lists the effective limits of a user
+type UserLimits struct {
+	UserID  uuid.UUID        `json:"user_id"`
+	KYCTier KYCTier          `json:"kyc_tier"`
+	Limits  []*LimitHeadroom `json:"limits"`
+}
+
+// SetLimitRuleRequest creates or replaces the rule for a scope, kind and currency
+type SetLimitRuleRequest struct {
+	Scope    LimitScope `json:"scope" validate:"required,oneof=global tier user"`
+	KYCTier  *KYCTier   `json:"kyc_tier" validate:"required_if=Scope tier,omitempty,oneof=unverified basic full"`
+	UserID   *uuid.UUID `json:"user_id" validate:"required_if=Scope user"`
+	Kind     LimitKind  `json:"kind" validate:"required,oneof=max_single_transfer daily_outflow monthly_outflow daily_withdrawal_count daily_exchange_volume monthly_exchange_volume"`
+	Currency *string    `json:"currency" validate:"omitempty,min=2,max=10"`
+	Value    float64    `json:"value" validate:"gte=0"`
+}
+
+// LimitRuleFilter narrows the rule listing of the admin API
+type LimitRuleFilter struct {
+	Scope   LimitScope
+	KYCTier *KYCTier
+	UserID  *uuid.UUID
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is an event stored with the change it announces until the
+// relay has published it
+type OutboxMessage struct {
+	ID         int64           `json:"id"`
+	MessageID  uuid.UUID       `json:"message_id"`
+	Exchange   string          `json:"exchange"`
+	RoutingKey string          `json:"routing_key"`
+	Payload    json.RawMessage `json:"payload"`
+	// TraceContext holds the traceparent and tracestate of the request that
+	// caused the event
+	TraceContext  map[string]string `json:"trace_context,omitempty"`
+	Attempts      int               `json:"attempts"`
+	LastError     *string           `json:"last_error,omitempty"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	SentAt        *time.Time        `json:"sent_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// OutboxStats describes the messages still waiting to be published
+type OutboxStats struct {
+	Pending       int64
+	OldestPending *time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// QuoteLock is a rate the bank locked for an exchange under the key of the
+// exchange. ReleasedAt is set once the exchange settled on it or gave up.
+type QuoteLock struct {
+	Key          string     `json:"key"`
+	QuoteID      string     `json:"quote_id"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Amount       float64    `json:"amount"`
+	Rate         float64    `json:"rate"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	ReleasedAt   *time.Time `json:"released_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
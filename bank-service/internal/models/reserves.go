@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/crypto-bank/bank-service/pkg/merkle"
+	"github.com/google/uuid"
+)
+
+// ReserveSnapshot is a point-in-time proof-of-reserves snapshot
+type ReserveSnapshot struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	Roots     []ReserveRoot `json:"roots"`
+}
+
+// ReserveRoot is the published Merkle sum tree root of one crypto type
+type ReserveRoot struct {
+	CryptoType       CryptoType `json:"crypto_type" db:"crypto_type"`
+	RootHash         string     `json:"root_hash" db:"root_hash"`
+	RootSum          uint64     `json:"root_sum" db:"root_sum"`
+	TotalLiabilities float64    `json:"total_liabilities" db:"total_liabilities"`
+	HouseHoldings    float64    `json:"house_holdings" db:"house_holdings"`
+	FullyCovered     bool       `json:"fully_covered"`
+	LeafCount        int        `json:"leaf_count" db:"leaf_count"`
+}
+
+// ReserveLeaf is a salted user balance in a snapshot
+type ReserveLeaf struct {
+	CryptoType CryptoType `json:"crypto_type" db:"crypto_type"`
+	LeafIndex  int        `json:"leaf_index" db:"leaf_index"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Salt       string     `json:"salt" db:"salt"`
+	Balance    uint64     `json:"balance" db:"balance"`
+	LeafHash   string     `json:"leaf_hash" db:"leaf_hash"`
+}
+
+// ReservesProof is a user's inclusion proof for one crypto type
+type ReservesProof struct {
+	SnapshotID uuid.UUID  `json:"snapshot_id"`
+	SnapshotAt time.Time  `json:"snapshot_at"`
+	CryptoType CryptoType `json:"crypto_type"`
+	Decimals   int        `json:"decimals"`
+	merkle.Proof
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiskOperation names a money movement scored for fraud before it runs
+type RiskOperation string
+
+const (
+	RiskOpTransfer       RiskOperation = "transfer"
+	RiskOpWithdraw       RiskOperation = "withdraw"
+	RiskOpCryptoWithdraw RiskOperation = "crypto_withdraw"
+	RiskOpExchange       RiskOperation = "exchange"
+)
+
+// RiskDecision is what the bank does with a scored operation
+type RiskDecision string
+
+const (
+	RiskAllow RiskDecision = "allow"
+	// RiskChallenge lets the operation run only with a recent second factor
+	RiskChallenge RiskDecision = "challenge"
+	RiskDeny      RiskDecision = "deny"
+)
+
+// ClientInfo describes the client a request comes from. DeviceID is the
+// X-Device-ID header of the app; clients without one are told apart by their
+// user agent.
+type ClientInfo struct {
+	IP        string
+	DeviceID  string
+	UserAgent string
+}
+
+// RiskFeatures describe the user and the context of an operation for scoring
+type RiskFeatures struct {
+	AccountAgeHours    float64 `json:"account_age_hours"`
+	OperationsLastHour int     `json:"operations_last_hour"`
+	OperationsLastDay  int     `json:"operations_last_day"`
+	VolumeLastDayUSD   float64 `json:"volume_last_day_usd"`
+	NewDevice          bool    `json:"new_device"`
+	NewIP              bool    `json:"new_ip"`
+	KnownDevices       int     `json:"known_devices"`
+	HasBeneficiary     bool    `json:"has_beneficiary"`
+	NewBeneficiary     bool    `json:"new_beneficiary"`
+	TwoFactorEnabled   bool    `json:"two_factor_enabled"`
+}
+
+// RiskAssessment is the outcome of scoring an operation. Score runs from 0 (no
+// risk) to 100.
+type RiskAssessment struct {
+	Decision RiskDecision `json:"decision"`
+	Score    float64      `json:"score"`
+	Reasons  []string     `json:"reasons"`
+}
+
+// RiskEvaluation is the logged decision on an operation. ScorerError is set when
+// the scorer failed or timed out and the configured fail mode decided instead.
+type RiskEvaluation struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	UserID      uuid.UUID     `json:"user_id" db:"user_id"`
+	Operation   RiskOperation `json:"operation" db:"operation"`
+	Amount      float64       `json:"amount" db:"amount"`
+	Currency    string        `json:"currency" db:"currency"`
+	AmountUSD   *float64      `json:"amount_usd,omitempty" db:"amount_usd"`
+	Decision    RiskDecision  `json:"decision" db:"decision"`
+	Score       float64       `json:"score" db:"score"`
+	Reasons     []string      `json:"reasons" db:"reasons"`
+	Features    RiskFeatures  `json:"features" db:"features"`
+	Scorer      string        `json:"scorer" db:"scorer"`
+	ScorerError *string       `json:"scorer_error,omitempty" db:"scorer_error"`
+	SteppedUp   bool          `json:"stepped_up" db:"stepped_up"`
+	IP          string        `json:"ip" db:"ip"`
+	LatencyMs   int64         `json:"latency_ms" db:"latency_ms"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// RiskEvaluationFilter selects logged decisions, newest first
+type RiskEvaluationFilter struct {
+	UserID    *uuid.UUID
+	Operation RiskOperation
+	Decision  RiskDecision
+	Limit     uint64
+}
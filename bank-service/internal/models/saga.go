@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SagaStatus represents the state of an exchange saga
+type SagaStatus string
+
+const (
+	SagaStatusRunning      SagaStatus = "RUNNING"
+	SagaStatusCompensating SagaStatus = "COMPENSATING"
+	SagaStatusCompleted    SagaStatus = "COMPLETED"
+	SagaStatusCompensated  SagaStatus = "COMPENSATED"
+)
+
+// SagaStep names a step of an exchange saga, in the order they run
+type SagaStep string
+
+const (
+	SagaStepLockQuote    SagaStep = "LOCK_QUOTE"
+	SagaStepReserveFunds SagaStep = "RESERVE_FUNDS"
+	SagaStepSettle       SagaStep = "SETTLE"
+	SagaStepRelease      SagaStep = "RELEASE"
+	SagaStepDone         SagaStep = "DONE"
+)
+
+// SagaAction tells whether a step ran or was undone
+type SagaAction string
+
+const (
+	SagaActionExecute    SagaAction = "EXECUTE"
+	SagaActionCompensate SagaAction = "COMPENSATE"
+)
+
+// SagaOutcome is the result of running or undoing a step
+type SagaOutcome string
+
+const (
+	SagaOutcomeSucceeded SagaOutcome = "SUCCEEDED"
+	SagaOutcomeFailed    SagaOutcome = "FAILED"
+)
+
+// ExchangeSaga tracks an exchange across the quote lock and the balance
+// changes. Step is the next step to run, or while compensating the next step to
+// undo. The quote fields are set once the quote is locked; the exchange and
+// transaction once the funds are reserved.
+type ExchangeSaga struct {
+	ID             uuid.UUID    `json:"id"`
+	UserID         uuid.UUID    `json:"user_id"`
+	Type           ExchangeType `json:"exchange_type"`
+	Status         SagaStatus   `json:"status"`
+	Step           SagaStep     `json:"step"`
+	FromCurrency   string       `json:"from_currency"`
+	ToCurrency     string       `json:"to_currency"`
+	FromAmount     float64      `json:"from_amount"`
+	FromAccountID  *uuid.UUID   `json:"from_account_id,omitempty"`
+	ToAccountID    *uuid.UUID   `json:"to_account_id,omitempty"`
+	FromWalletID   *uuid.UUID   `json:"from_wallet_id,omitempty"`
+	ToWalletID     *uuid.UUID   `json:"to_wallet_id,omitempty"`
+	QuoteID        *string      `json:"quote_id,omitempty"`
+	ExchangeRate   *float64     `json:"exchange_rate,omitempty"`
+	QuoteExpiresAt *time.Time   `json:"quote_expires_at,omitempty"`
+	ExchangeID     *uuid.UUID   `json:"exchange_id,omitempty"`
+	TransactionID  *uuid.UUID   `json:"transaction_id,omitempty"`
+	// FailureReason is why the saga is being compensated
+	FailureReason *string `json:"failure_reason,omitempty"`
+	// Attempts counts the failed attempts at the current step
+	Attempts      int               `json:"attempts"`
+	LastError     *string           `json:"last_error,omitempty"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	FinishedAt    *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	History       []*SagaStepRecord `json:"history,omitempty"`
+}
+
+// Settled reports whether the funds of the exchange moved. A settled saga is
+// only ever driven forward.
+func (s *ExchangeSaga) Settled() bool {
+	return s.Status == SagaStatusCompleted || (s.Status == SagaStatusRunning && s.Step == SagaStepRelease)
+}
+
+// SagaStepRecord logs a step a saga ran or undid
+type SagaStepRecord struct {
+	ID        int64       `json:"id"`
+	Step      SagaStep    `json:"step"`
+	Action    SagaAction  `json:"action"`
+	Outcome   SagaOutcome `json:"outcome"`
+	Error     *string     `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScreeningOperation names an operation that is screened against watchlists
+type ScreeningOperation string
+
+const (
+	ScreeningOpCreateUser     ScreeningOperation = "create_user"
+	ScreeningOpUpdateUser     ScreeningOperation = "update_user"
+	ScreeningOpTransfer       ScreeningOperation = "transfer"
+	ScreeningOpCryptoWithdraw ScreeningOperation = "crypto_withdraw"
+)
+
+// ScreeningHoldStatus represents the state of a held operation
+type ScreeningHoldStatus string
+
+const (
+	ScreeningHoldHeld     ScreeningHoldStatus = "held"
+	ScreeningHoldReleased ScreeningHoldStatus = "released"
+	ScreeningHoldRejected ScreeningHoldStatus = "rejected"
+	// ScreeningHoldFailed marks a released operation that could not be executed
+	ScreeningHoldFailed ScreeningHoldStatus = "failed"
+)
+
+// ScreeningMatch is a watchlist entry a name or address was matched with.
+// MatchedName is the name or alias of the entry that scored highest.
+type ScreeningMatch struct {
+	Source      string   `json:"source"`
+	EntryID     string   `json:"entry_id"`
+	ListedName  string   `json:"listed_name,omitempty"`
+	MatchedName string   `json:"matched_name,omitempty"`
+	Address     string   `json:"address,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Programs    []string `json:"programs,omitempty"`
+	Score       float64  `json:"score"`
+}
+
+// ScreeningHold is an operation parked for compliance review after a watchlist
+// hit. Payload holds the operation as it would have been executed.
+type ScreeningHold struct {
+	ID            uuid.UUID           `json:"id" db:"id"`
+	Operation     ScreeningOperation  `json:"operation" db:"operation"`
+	Status        ScreeningHoldStatus `json:"status" db:"status"`
+	UserID        *uuid.UUID          `json:"user_id,omitempty" db:"user_id"`
+	Subject       string              `json:"subject" db:"subject"`
+	Amount        *float64            `json:"amount,omitempty" db:"amount"`
+	Currency      *string             `json:"currency,omitempty" db:"currency"`
+	Matches       []ScreeningMatch    `json:"matches" db:"matches"`
+	Payload       json.RawMessage     `json:"-" db:"payload"`
+	ReviewerID    *uuid.UUID          `json:"reviewer_id,omitempty" db:"reviewer_id"`
+	ReviewNote    *string             `json:"review_note,omitempty" db:"review_note"`
+	ResultID      *uuid.UUID          `json:"result_id,omitempty" db:"result_id"`
+	FailureReason *string             `json:"failure_reason,omitempty" db:"failure_reason"`
+	ReviewedAt    *time.Time          `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// ScreeningHoldReceipt is what the customer learns about a held operation;
+// the matches stay with compliance
+type ScreeningHoldReceipt struct {
+	ID        uuid.UUID           `json:"id"`
+	Operation ScreeningOperation  `json:"operation"`
+	Status    ScreeningHoldStatus `json:"status"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// Receipt returns the customer facing view of the hold
+func (h *ScreeningHold) Receipt() *ScreeningHoldReceipt {
+	return &ScreeningHoldReceipt{
+		ID:        h.ID,
+		Operation: h.Operation,
+		Status:    h.Status,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+// WatchlistSource describes one loaded list
+type WatchlistSource struct {
+	Name      string `json:"name"`
+	Entries   int    `json:"entries"`
+	Addresses int    `json:"addresses"`
+}
+
+// WatchlistStatus describes the lists screening currently runs against
+type WatchlistStatus struct {
+	Sources   []WatchlistSource `json:"sources"`
+	Threshold float64           `json:"threshold"`
+	LoadedAt  *time.Time        `json:"loaded_at,omitempty"`
+}
+
+type ReviewScreeningHoldRequest struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
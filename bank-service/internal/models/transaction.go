@@ -36,6 +36,8 @@ type Transaction struct {
 	Currency          string            `json:"currency" db:"currency"`
 	FromAccountID     *uuid.UUID        `json:"from_account_id,omitempty" db:"from_account_id"`
 	ToAccountID       *uuid.UUID        `json:"to_account_id,omitempty" db:"to_account_id"`
+	FromWalletID      *uuid.UUID        `json:"from_wallet_id,omitempty" db:"from_wallet_id"`
+	ToAddress         *string           `json:"to_address,omitempty" db:"to_address"`
 	Description       string            `json:"description" db:"description"`
 	ExchangeID        *uuid.UUID        `json:"exchange_id,omitempty" db:"exchange_id"`
 	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
@@ -43,19 +45,30 @@ type Transaction struct {
 }
 
 type CreateTransactionRequest struct {
+	UserID        uuid.UUID `json:"user_id"`
 	FromAccountID uuid.UUID `json:"from_account_id" validate:"required"`
 	ToAccountID   uuid.UUID `json:"to_account_id" validate:"required"`
 	Amount        float64   `json:"amount" validate:"required,gt=0"`
 	Description   string    `json:"description"`
+	// SteppedUp is set by the handler when the access token carries a recent second factor check
+	SteppedUp bool `json:"-"`
+	// Client is set by the handler for fraud scoring
+	Client ClientInfo `json:"-"`
 }
 
 type DepositRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
 	AccountID uuid.UUID `json:"account_id" validate:"required"`
 	Amount    float64   `json:"amount" validate:"required,gt=0"`
 }
 
 type WithdrawRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
 	AccountID uuid.UUID `json:"account_id" validate:"required"`
 	Amount    float64   `json:"amount" validate:"required,gt=0"`
+
+	// Set by the handler for fraud scoring
+	SteppedUp bool       `json:"-"`
+	Client    ClientInfo `json:"-"`
 }
 
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TreasuryWalletKind distinguishes online (hot) from offline (cold) house wallets
+type TreasuryWalletKind string
+
+const (
+	TreasuryWalletHot  TreasuryWalletKind = "HOT"
+	TreasuryWalletCold TreasuryWalletKind = "COLD"
+)
+
+// TreasuryMovementType represents the direction of a house wallet movement
+type TreasuryMovementType string
+
+const (
+	TreasuryMovementSweep     TreasuryMovementType = "SWEEP"
+	TreasuryMovementReplenish TreasuryMovementType = "REPLENISH"
+)
+
+// TreasuryMovementStatus represents the status of a house wallet movement
+type TreasuryMovementStatus string
+
+const (
+	TreasuryMovementProposed  TreasuryMovementStatus = "PROPOSED"
+	TreasuryMovementExecuted  TreasuryMovementStatus = "EXECUTED"
+	TreasuryMovementCancelled TreasuryMovementStatus = "CANCELLED"
+)
+
+// TreasuryWallet represents a house-owned hot or cold wallet
+type TreasuryWallet struct {
+	ID         uuid.UUID          `json:"id" db:"id"`
+	CryptoType CryptoType         `json:"crypto_type" db:"crypto_type"`
+	Kind       TreasuryWalletKind `json:"kind" db:"kind"`
+	Address    string             `json:"address" db:"address"`
+	Balance    float64            `json:"balance" db:"balance"`
+	CreatedAt  time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// TreasuryPolicy holds hot wallet targets for a crypto type
+type TreasuryPolicy struct {
+	CryptoType     CryptoType `json:"crypto_type" db:"crypto_type"`
+	TargetHotRatio float64    `json:"target_hot_ratio" db:"target_hot_ratio"`
+	MaxHotRatio    float64    `json:"max_hot_ratio" db:"max_hot_ratio"`
+	MinHotBalance  float64    `json:"min_hot_balance" db:"min_hot_balance"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TreasuryMovement is a proposed or executed transfer between house wallets
+type TreasuryMovement struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	CryptoType CryptoType             `json:"crypto_type" db:"crypto_type"`
+	Type       TreasuryMovementType   `json:"type" db:"type"`
+	Status     TreasuryMovementStatus `json:"status" db:"status"`
+	Amount     float64                `json:"amount" db:"amount"`
+	Reason     string                 `json:"reason" db:"reason"`
+	ExecutedAt *time.Time             `json:"executed_at,omitempty" db:"executed_at"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// TreasuryReportLine compares customer liabilities with house holdings for one crypto type
+type TreasuryReportLine struct {
+	CryptoType          CryptoType `json:"crypto_type"`
+	CustomerLiabilities float64    `json:"customer_liabilities"`
+	HotBalance          float64    `json:"hot_balance"`
+	ColdBalance         float64    `json:"cold_balance"`
+	TotalHoldings       float64    `json:"total_holdings"`
+	Surplus             float64    `json:"surplus"`
+	CoverageRatio       float64    `json:"coverage_ratio"`
+	HotRatio            float64    `json:"hot_ratio"`
+	TargetHotRatio      float64    `json:"target_hot_ratio"`
+	MaxHotRatio         float64    `json:"max_hot_ratio"`
+	FullyCovered        bool       `json:"fully_covered"`
+	OpenSweepAmount     float64    `json:"open_sweep_amount"`
+	OpenReplenishAmount float64    `json:"open_replenish_amount"`
+}
+
+type TreasuryReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Lines       []TreasuryReportLine `json:"lines"`
+}
+
+type UpdateTreasuryPolicyRequest struct {
+	TargetHotRatio float64 `json:"target_hot_ratio" validate:"required,gt=0,lt=1"`
+	MaxHotRatio    float64 `json:"max_hot_ratio" validate:"required,gt=0,lte=1,gtefield=TargetHotRatio"`
+	MinHotBalance  float64 `json:"min_hot_balance" validate:"gte=0"`
+}
+
+type TreasuryDepositRequest struct {
+	Kind   TreasuryWalletKind `json:"kind" validate:"required,oneof=HOT COLD"`
+	Amount float64            `json:"amount" validate:"required,gt=0"`
+}
@@ -1,17 +1,29 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Role string
+
+const (
+	RoleCustomer   Role = "customer"
+	RoleSupport    Role = "support"
+	RoleCompliance Role = "compliance"
+	RoleAdmin      Role = "admin"
+)
+
 type User struct {
 	ID        uuid.UUID  `json:"id" db:"id"`
 	Email     string     `json:"email" db:"email" validate:"required,email"`
 	FirstName string     `json:"first_name" db:"first_name" validate:"required"`
 	LastName  string     `json:"last_name" db:"last_name" validate:"required"`
 	Phone     string     `json:"phone" db:"phone" validate:"required"`
+	Role      Role       `json:"role" db:"role"`
+	KYCTier   KYCTier    `json:"kyc_tier" db:"kyc_tier"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
@@ -19,6 +31,7 @@ type User struct {
 
 type CreateUserRequest struct {
 	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=12,max=128"`
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
 	Phone     string `json:"phone" validate:"required"`
@@ -30,3 +43,36 @@ type UpdateUserRequest struct {
 	Phone     string `json:"phone"`
 }
 
+
+type UpdateUserRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=customer support compliance admin"`
+}
+
+// Masked returns a copy of the user with contact details and last name masked
+// for staff without access to personal data
+func (u *User) Masked() *User {
+	masked := *u
+	masked.Email = maskEmail(u.Email)
+	masked.Phone = maskTail(u.Phone, 4)
+	if u.LastName != "" {
+		masked.LastName = u.LastName[:1] + "."
+	}
+	return &masked
+}
+
+// maskEmail keeps the first character of the local part and the domain
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return maskTail(email, 0)
+	}
+	return local[:1] + "***@" + domain
+}
+
+// maskTail replaces everything but the last n characters
+func maskTail(value string, n int) string {
+	if len(value) <= n {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-n) + value[len(value)-n:]
+}
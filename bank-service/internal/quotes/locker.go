@@ -0,0 +1,38 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrExpired is returned for a quote that is no longer honoured
+var ErrExpired = errors.New("quote expired")
+
+// Request asks for a rate to convert Amount of From into To. Key identifies the
+// exchange: locking again with the same key returns the same quote, so a saga
+// resumed after a crash does not lock twice.
+type Request struct {
+	Key    string
+	From   string
+	To     string
+	Amount float64
+}
+
+// Quote is a rate held for an exchange until it expires or is released
+type Quote struct {
+	ID        string
+	Rate      float64
+	ExpiresAt time.Time
+}
+
+// Locker holds exchange rates while an exchange moves the funds. Release frees
+// the quote locked under a key once the exchange settled on it or gave up. It
+// takes the key rather than the quote ID, so a lock whose reply was lost can be
+// released too; releasing twice, or a key that holds no quote, succeeds.
+type Locker interface {
+	// Name identifies the locker in logs
+	Name() string
+	Lock(ctx context.Context, req *Request) (*Quote, error)
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,80 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+// ErrReleased is returned when locking again under a key whose quote was
+// released
+var ErrReleased = errors.New("quote released")
+
+// RateSource returns the current rate between two currencies
+type RateSource func(fromCurrency, toCurrency string) (float64, error)
+
+// LockStore persists the quotes of a RateLocker
+type LockStore interface {
+	// Hold stores a lock unless its key holds one already, and returns the
+	// lock the key holds
+	Hold(lock *models.QuoteLock) (*models.QuoteLock, error)
+	Release(key string) error
+}
+
+// RateLocker quotes the bank's own exchange rates. A quote is the current rate,
+// honoured until the TTL runs out. It is stored under the key, so locking again
+// after a crash returns the rate locked before, and releasing it marks it
+// released for good.
+type RateLocker struct {
+	rates RateSource
+	store LockStore
+	ttl   time.Duration
+}
+
+func NewRateLocker(rates RateSource, store LockStore, ttl time.Duration) *RateLocker {
+	return &RateLocker{
+		rates: rates,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (l *RateLocker) Name() string {
+	return "rates"
+}
+
+func (l *RateLocker) Lock(_ context.Context, req *Request) (*Quote, error) {
+	rate, err := l.rates(req.From, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	lock, err := l.store.Hold(&models.QuoteLock{
+		Key:          req.Key,
+		QuoteID:      "rates:" + req.Key,
+		FromCurrency: req.From,
+		ToCurrency:   req.To,
+		Amount:       req.Amount,
+		Rate:         rate,
+		ExpiresAt:    time.Now().Add(l.ttl).UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lock.ReleasedAt != nil {
+		return nil, fmt.Errorf("%w: %s", ErrReleased, lock.QuoteID)
+	}
+
+	return &Quote{
+		ID:        lock.QuoteID,
+		Rate:      lock.Rate,
+		ExpiresAt: lock.ExpiresAt,
+	}, nil
+}
+
+func (l *RateLocker) Release(_ context.Context, key string) error {
+	return l.store.Release(key)
+}
@@ -0,0 +1,130 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+// memoryStore holds quote locks like the quote_locks table
+type memoryStore map[string]*models.QuoteLock
+
+func (s memoryStore) Hold(lock *models.QuoteLock) (*models.QuoteLock, error) {
+	if held, ok := s[lock.Key]; ok {
+		return held, nil
+	}
+	s[lock.Key] = lock
+	return lock, nil
+}
+
+func (s memoryStore) Release(key string) error {
+	if lock, ok := s[key]; ok && lock.ReleasedAt == nil {
+		now := time.Now()
+		lock.ReleasedAt = &now
+	}
+	return nil
+}
+
+func TestRateLocker(t *testing.T) {
+	request := &Request{Key: "saga-1", From: "BTC", To: "USD", Amount: 1}
+
+	tests := []struct {
+		name string
+		// run locks and releases against a source whose rate moves on every call
+		run      func(ctx context.Context, l *RateLocker) (*Quote, error)
+		wantRate float64
+		wantErr  error
+	}{
+		{
+			name: "first lock quotes the current rate",
+			run: func(ctx context.Context, l *RateLocker) (*Quote, error) {
+				return l.Lock(ctx, request)
+			},
+			wantRate: 100,
+		},
+		{
+			name: "locking again returns the rate locked before",
+			run: func(ctx context.Context, l *RateLocker) (*Quote, error) {
+				if _, err := l.Lock(ctx, request); err != nil {
+					return nil, err
+				}
+				return l.Lock(ctx, request)
+			},
+			wantRate: 100,
+		},
+		{
+			name: "another key quotes the rate of the moment",
+			run: func(ctx context.Context, l *RateLocker) (*Quote, error) {
+				if _, err := l.Lock(ctx, request); err != nil {
+					return nil, err
+				}
+				other := *request
+				other.Key = "saga-2"
+				return l.Lock(ctx, &other)
+			},
+			wantRate: 101,
+		},
+		{
+			name: "a released quote cannot be locked again",
+			run: func(ctx context.Context, l *RateLocker) (*Quote, error) {
+				if _, err := l.Lock(ctx, request); err != nil {
+					return nil, err
+				}
+				if err := l.Release(ctx, request.Key); err != nil {
+					return nil, err
+				}
+				return l.Lock(ctx, request)
+			},
+			wantErr: ErrReleased,
+		},
+		{
+			name: "releasing twice or a key without a quote succeeds",
+			run: func(ctx context.Context, l *RateLocker) (*Quote, error) {
+				for _, key := range []string{request.Key, request.Key, "unknown"} {
+					if err := l.Release(ctx, key); err != nil {
+						return nil, err
+					}
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := 99.0
+			source := func(from, to string) (float64, error) {
+				rate++
+				return rate, nil
+			}
+			locker := NewRateLocker(source, memoryStore{}, time.Minute)
+
+			quote, err := tt.run(context.Background(), locker)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if quote == nil {
+				return
+			}
+			if quote.Rate != tt.wantRate {
+				t.Errorf("rate = %v, want %v", quote.Rate, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestRateLockerSourceFailure(t *testing.T) {
+	store := memoryStore{}
+	failure := errors.New("no rate")
+	locker := NewRateLocker(func(from, to string) (float64, error) { return 0, failure }, store, time.Minute)
+
+	if _, err := locker.Lock(context.Background(), &Request{Key: "saga-1", From: "BTC", To: "USD", Amount: 1}); !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+	if len(store) != 0 {
+		t.Errorf("a failed lock stored %d quotes", len(store))
+	}
+}
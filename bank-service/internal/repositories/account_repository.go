@@ -10,7 +10,7 @@ import (
 )
 
 type AccountRepository struct {
-	db *sql.DB
+	db DBTX
 	qb sq.StatementBuilderType
 }
 
@@ -21,6 +21,11 @@ func NewAccountRepository(db *sql.DB) *AccountRepository {
 	}
 }
 
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *AccountRepository) WithTx(tx *sql.Tx) *AccountRepository {
+	return &AccountRepository{db: tx, qb: r.qb}
+}
+
 // Create creates a new account
 func (r *AccountRepository) Create(account *models.Account) error {
 	account.ID = uuid.New()
@@ -104,32 +109,27 @@ func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]*models.Account, er
 	return accounts, nil
 }
 
-// UpdateBalance updates account balance
-func (r *AccountRepository) UpdateBalance(id uuid.UUID, amount float64) error {
+// UpdateBalance updates account balance. It returns the balance after the change.
+func (r *AccountRepository) UpdateBalance(id uuid.UUID, amount float64) (float64, error) {
 	query := r.qb.Update("accounts").
 		Set("balance", sq.Expr("balance + ?", amount)).
-		Where(sq.Eq{"id": id})
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING balance")
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build query: %w", err)
-	}
-
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("account not found")
+	var balance float64
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("account not found")
+		}
+		return 0, fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	return nil
+	return balance, nil
 }
 
 // GetBalance retrieves account balance
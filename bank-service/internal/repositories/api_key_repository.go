@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var apiKeyColumns = []string{
+	"k.id", "k.user_id", "k.name", "k.key_id", "k.secret_ciphertext", "k.scopes", "k.allowed_ips",
+	"k.expires_at", "k.revoked_at", "k.last_used_at", "k.last_used_ip", "k.usage_count", "k.created_at",
+}
+
+type APIKeyRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	key.ID = uuid.New()
+
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	query := r.qb.Insert("api_keys").
+		Columns("id", "user_id", "name", "key_id", "secret_ciphertext", "scopes", "allowed_ips", "expires_at").
+		Values(key.ID, key.UserID, key.Name, key.KeyID, key.SecretCiphertext,
+			pq.Array(scopeStrings(key.Scopes)), pq.Array(allowedIPs), key.ExpiresAt).
+		Suffix("RETURNING created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByKeyID retrieves an API key by its public key ID. Keys of deleted users are not returned.
+func (r *APIKeyRepository) GetByKeyID(keyID string) (*models.APIKey, error) {
+	query := r.qb.Select(apiKeyColumns...).
+		From("api_keys k").
+		Join("users u ON u.id = k.user_id").
+		Where(sq.Eq{"k.key_id": keyID, "u.deleted_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("API key not found")
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByUserID retrieves the API keys of a user
+func (r *APIKeyRepository) GetByUserID(userID uuid.UUID) ([]*models.APIKey, error) {
+	query := r.qb.Select(apiKeyColumns...).
+		From("api_keys k").
+		Where(sq.Eq{"k.user_id": userID}).
+		OrderBy("k.created_at DESC")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke revokes an active API key of a user
+func (r *APIKeyRepository) Revoke(userID, id uuid.UUID) (bool, error) {
+	query := r.qb.Update("api_keys").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "user_id": userID, "revoked_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeAllForUser revokes every active API key of a user
+func (r *APIKeyRepository) RevokeAllForUser(userID uuid.UUID) (int64, error) {
+	query := r.qb.Update("api_keys").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// UseNonce records a request nonce. It returns false if the nonce was already used with the key.
+func (r *APIKeyRepository) UseNonce(id uuid.UUID, nonce string) (bool, error) {
+	query := r.qb.Insert("api_key_nonces").
+		Columns("api_key_id", "nonce").
+		Values(id, nonce).
+		Suffix("ON CONFLICT (api_key_id, nonce) DO NOTHING")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to record nonce: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteNoncesBefore removes nonces recorded before the given time
+func (r *APIKeyRepository) DeleteNoncesBefore(before time.Time) (int64, error) {
+	query := r.qb.Delete("api_key_nonces").
+		Where(sq.Lt{"created_at": before})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete nonces: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// RecordUsage counts a request made with a key
+func (r *APIKeyRepository) RecordUsage(id uuid.UUID, ip string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlQuery, args, err := r.qb.Update("api_keys").
+		Set("usage_count", sq.Expr("usage_count + 1")).
+		Set("last_used_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("last_used_ip", ip).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to record API key usage: %w", err)
+	}
+
+	sqlQuery, args, err = r.qb.Insert("api_key_usage").
+		Columns("api_key_id", "day", "requests").
+		Values(id, sq.Expr("CURRENT_DATE"), 1).
+		Suffix("ON CONFLICT (api_key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to record API key usage: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetUsage retrieves the daily request counts of a key since the given day
+func (r *APIKeyRepository) GetUsage(id uuid.UUID, since time.Time) ([]*models.APIKeyUsage, error) {
+	query := r.qb.Select("day", "requests").
+		From("api_key_usage").
+		Where(sq.Eq{"api_key_id": id}).
+		Where(sq.GtOrEq{"day": since}).
+		OrderBy("day DESC")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []*models.APIKeyUsage{}
+	for rows.Next() {
+		var day models.APIKeyUsage
+		if err := rows.Scan(&day.Day, &day.Requests); err != nil {
+			return nil, fmt.Errorf("failed to scan API key usage: %w", err)
+		}
+		usage = append(usage, &day)
+	}
+
+	return usage, rows.Err()
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes, allowedIPs []string
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.KeyID, &key.SecretCiphertext,
+		pq.Array(&scopes), pq.Array(&allowedIPs),
+		&key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt, &key.LastUsedIP, &key.UsageCount, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]models.APIKeyScope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = models.APIKeyScope(scope)
+	}
+	key.AllowedIPs = allowedIPs
+
+	return &key, nil
+}
+
+func scopeStrings(scopes []models.APIKeyScope) []string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return values
+}
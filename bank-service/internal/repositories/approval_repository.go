@@ -0,0 +1,439 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type ApprovalRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewApprovalRepository(db *sql.DB) *ApprovalRepository {
+	return &ApprovalRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var approvalRequestColumns = []string{
+	"id", "policy_id", "user_id", "operation", "status", "amount", "currency", "payload",
+	"required_approvals", "result_id", "failure_reason", "expires_at", "created_at", "updated_at",
+}
+
+// UpsertPolicy creates or replaces the approval policy of a wallet or account
+func (r *ApprovalRepository) UpsertPolicy(policy *models.ApprovalPolicy) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing := r.qb.Select("id").From("approval_policies")
+	if policy.WalletID != nil {
+		existing = existing.Where(sq.Eq{"wallet_id": *policy.WalletID})
+	} else {
+		existing = existing.Where(sq.Eq{"account_id": *policy.AccountID})
+	}
+
+	sqlQuery, args, err := existing.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var existingID uuid.UUID
+	err = tx.QueryRow(sqlQuery, args...).Scan(&existingID)
+	switch {
+	case err == sql.ErrNoRows:
+		policy.ID = uuid.New()
+		query := r.qb.Insert("approval_policies").
+			Columns("id", "wallet_id", "account_id", "threshold", "required_approvals", "ttl_seconds", "enabled").
+			Values(policy.ID, policy.WalletID, policy.AccountID, policy.Threshold,
+				policy.RequiredApprovals, policy.TTLSeconds, policy.Enabled).
+			Suffix("RETURNING created_at, updated_at")
+
+		sqlQuery, args, err = query.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+		if err := tx.QueryRow(sqlQuery, args...).Scan(&policy.CreatedAt, &policy.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to create approval policy: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get approval policy: %w", err)
+	default:
+		policy.ID = existingID
+		query := r.qb.Update("approval_policies").
+			Set("threshold", policy.Threshold).
+			Set("required_approvals", policy.RequiredApprovals).
+			Set("ttl_seconds", policy.TTLSeconds).
+			Set("enabled", policy.Enabled).
+			Where(sq.Eq{"id": policy.ID}).
+			Suffix("RETURNING created_at, updated_at")
+
+		sqlQuery, args, err = query.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+		if err := tx.QueryRow(sqlQuery, args...).Scan(&policy.CreatedAt, &policy.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to update approval policy: %w", err)
+		}
+
+		sqlQuery, args, err = r.qb.Delete("approval_policy_approvers").Where(sq.Eq{"policy_id": policy.ID}).ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+		if _, err := tx.Exec(sqlQuery, args...); err != nil {
+			return fmt.Errorf("failed to reset approvers: %w", err)
+		}
+	}
+
+	insert := r.qb.Insert("approval_policy_approvers").Columns("policy_id", "user_id")
+	for _, approverID := range policy.Approvers {
+		insert = insert.Values(policy.ID, approverID)
+	}
+
+	sqlQuery, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to add approvers: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetPolicyByWalletID retrieves the approval policy attached to a crypto wallet
+func (r *ApprovalRepository) GetPolicyByWalletID(walletID uuid.UUID) (*models.ApprovalPolicy, error) {
+	return r.getPolicy(sq.Eq{"wallet_id": walletID})
+}
+
+// GetPolicyByAccountID retrieves the approval policy attached to a fiat account
+func (r *ApprovalRepository) GetPolicyByAccountID(accountID uuid.UUID) (*models.ApprovalPolicy, error) {
+	return r.getPolicy(sq.Eq{"account_id": accountID})
+}
+
+// GetPolicyByID retrieves an approval policy by ID
+func (r *ApprovalRepository) GetPolicyByID(id uuid.UUID) (*models.ApprovalPolicy, error) {
+	return r.getPolicy(sq.Eq{"id": id})
+}
+
+func (r *ApprovalRepository) getPolicy(where sq.Eq) (*models.ApprovalPolicy, error) {
+	var policy models.ApprovalPolicy
+
+	query := r.qb.Select("id", "wallet_id", "account_id", "threshold", "required_approvals",
+		"ttl_seconds", "enabled", "created_at", "updated_at").
+		From("approval_policies").
+		Where(where)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(
+		&policy.ID, &policy.WalletID, &policy.AccountID, &policy.Threshold, &policy.RequiredApprovals,
+		&policy.TTLSeconds, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("approval policy not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get approval policy: %w", err)
+	}
+
+	approvers, err := r.getApprovers(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	policy.Approvers = approvers
+
+	return &policy, nil
+}
+
+func (r *ApprovalRepository) getApprovers(policyID uuid.UUID) ([]uuid.UUID, error) {
+	query := r.qb.Select("user_id").
+		From("approval_policy_approvers").
+		Where(sq.Eq{"policy_id": policyID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvers: %w", err)
+	}
+	defer rows.Close()
+
+	var approvers []uuid.UUID
+	for rows.Next() {
+		var approverID uuid.UUID
+		if err := rows.Scan(&approverID); err != nil {
+			return nil, fmt.Errorf("failed to scan approver: %w", err)
+		}
+		approvers = append(approvers, approverID)
+	}
+
+	return approvers, nil
+}
+
+// CreateRequest creates a new pending approval request
+func (r *ApprovalRepository) CreateRequest(req *models.ApprovalRequest) error {
+	req.ID = uuid.New()
+
+	query := r.qb.Insert("approval_requests").
+		Columns("id", "policy_id", "user_id", "operation", "status", "amount", "currency",
+			"payload", "required_approvals", "expires_at").
+		Values(req.ID, req.PolicyID, req.UserID, req.Operation, req.Status, req.Amount, req.Currency,
+			[]byte(req.Payload), req.RequiredApprovals, req.ExpiresAt).
+		Suffix("RETURNING created_at, updated_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&req.CreatedAt, &req.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	return nil
+}
+
+// GetRequestByID retrieves an approval request together with its decisions
+func (r *ApprovalRepository) GetRequestByID(id uuid.UUID) (*models.ApprovalRequest, error) {
+	query := r.qb.Select(approvalRequestColumns...).
+		From("approval_requests").
+		Where(sq.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	req, err := scanApprovalRequest(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("approval request not found")
+		}
+		return nil, fmt.Errorf("failed to get approval request: %w", err)
+	}
+
+	decisions, err := r.GetDecisions(id)
+	if err != nil {
+		return nil, err
+	}
+	req.Decisions = decisions
+
+	return req, nil
+}
+
+// GetPendingForApprover retrieves pending requests the user is allowed to decide on
+func (r *ApprovalRepository) GetPendingForApprover(approverID uuid.UUID) ([]*models.ApprovalRequest, error) {
+	query := r.qb.Select(prefixColumns("ar", approvalRequestColumns)...).
+		From("approval_requests ar").
+		Join("approval_policy_approvers apa ON apa.policy_id = ar.policy_id").
+		Where(sq.Eq{"apa.user_id": approverID, "ar.status": models.ApprovalStatusPending}).
+		Where(sq.Expr("ar.expires_at > NOW()")).
+		OrderBy("ar.created_at DESC")
+
+	return r.queryRequests(query)
+}
+
+// GetByUserID retrieves approval requests initiated by a user
+func (r *ApprovalRepository) GetByUserID(userID uuid.UUID) ([]*models.ApprovalRequest, error) {
+	query := r.qb.Select(approvalRequestColumns...).
+		From("approval_requests").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC")
+
+	return r.queryRequests(query)
+}
+
+func (r *ApprovalRepository) queryRequests(query sq.SelectBuilder) ([]*models.ApprovalRequest, error) {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.ApprovalRequest
+	for rows.Next() {
+		req, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
+
+// AddDecision records an approver's decision; an approver can decide only once
+func (r *ApprovalRepository) AddDecision(decision *models.ApprovalDecision) error {
+	decision.ID = uuid.New()
+
+	query := r.qb.Insert("approval_decisions").
+		Columns("id", "request_id", "approver_id", "decision", "comment").
+		Values(decision.ID, decision.RequestID, decision.ApproverID, decision.Decision, decision.Comment).
+		Suffix("ON CONFLICT (request_id, approver_id) DO NOTHING RETURNING created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&decision.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("approver has already decided on this request")
+		}
+		return fmt.Errorf("failed to record decision: %w", err)
+	}
+
+	return nil
+}
+
+// GetDecisions retrieves the approval trail of a request
+func (r *ApprovalRepository) GetDecisions(requestID uuid.UUID) ([]models.ApprovalDecision, error) {
+	query := r.qb.Select("id", "request_id", "approver_id", "decision", "COALESCE(comment, '')", "created_at").
+		From("approval_decisions").
+		Where(sq.Eq{"request_id": requestID}).
+		OrderBy("created_at ASC")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var decisions []models.ApprovalDecision
+	for rows.Next() {
+		var d models.ApprovalDecision
+		if err := rows.Scan(&d.ID, &d.RequestID, &d.ApproverID, &d.Decision, &d.Comment, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+
+	return decisions, nil
+}
+
+// TransitionStatus moves a request from one status to another; it fails if the
+// request is no longer in the expected status so concurrent deciders cannot both win
+func (r *ApprovalRepository) TransitionStatus(id uuid.UUID, from, to models.ApprovalStatus) error {
+	query := r.qb.Update("approval_requests").
+		Set("status", to).
+		Where(sq.Eq{"id": id, "status": from})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("approval request is no longer %s", from)
+	}
+
+	return nil
+}
+
+// MarkExecuted records the outcome of executing an approved request
+func (r *ApprovalRepository) MarkExecuted(id uuid.UUID, resultID *uuid.UUID, failure error) error {
+	query := r.qb.Update("approval_requests").Where(sq.Eq{"id": id})
+	if failure != nil {
+		query = query.Set("status", models.ApprovalStatusFailed).Set("failure_reason", failure.Error())
+	} else {
+		query = query.Set("status", models.ApprovalStatusExecuted).Set("result_id", resultID)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to update approval request: %w", err)
+	}
+
+	return nil
+}
+
+// ExpirePending marks all pending requests past their deadline as expired
+func (r *ApprovalRepository) ExpirePending(now time.Time) (int64, error) {
+	query := r.qb.Update("approval_requests").
+		Set("status", models.ApprovalStatusExpired).
+		Where(sq.Eq{"status": models.ApprovalStatusPending}).
+		Where(sq.LtOrEq{"expires_at": now})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire approval requests: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanApprovalRequest(row rowScanner) (*models.ApprovalRequest, error) {
+	var req models.ApprovalRequest
+	var payload []byte
+
+	err := row.Scan(
+		&req.ID, &req.PolicyID, &req.UserID, &req.Operation, &req.Status, &req.Amount, &req.Currency,
+		&payload, &req.RequiredApprovals, &req.ResultID, &req.FailureReason, &req.ExpiresAt,
+		&req.CreatedAt, &req.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Payload = payload
+
+	return &req, nil
+}
+
+func prefixColumns(alias string, columns []string) []string {
+	prefixed := make([]string, len(columns))
+	for i, column := range columns {
+		prefixed[i] = alias + "." + column
+	}
+	return prefixed
+}
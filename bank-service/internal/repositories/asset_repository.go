@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+type AssetRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewAssetRepository(db *sql.DB) *AssetRepository {
+	return &AssetRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var assetColumns = []string{
+	"code", "name", "kind", "decimals", "chain", "address_prefix", "address_pattern",
+	"enabled", "min_amount", "min_withdrawal", "reference_usd_rate", "created_at", "updated_at",
+}
+
+func scanAsset(row rowScanner) (*models.Asset, error) {
+	var asset models.Asset
+	err := row.Scan(
+		&asset.Code, &asset.Name, &asset.Kind, &asset.Decimals, &asset.Chain, &asset.AddressPrefix,
+		&asset.AddressPattern, &asset.Enabled, &asset.MinAmount, &asset.MinWithdrawal,
+		&asset.ReferenceUSDRate, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// Create registers a new asset
+func (r *AssetRepository) Create(asset *models.Asset) error {
+	query := r.qb.Insert("assets").
+		Columns("code", "name", "kind", "decimals", "chain", "address_prefix", "address_pattern",
+			"enabled", "min_amount", "min_withdrawal", "reference_usd_rate").
+		Values(asset.Code, asset.Name, asset.Kind, asset.Decimals, asset.Chain, asset.AddressPrefix,
+			asset.AddressPattern, asset.Enabled, asset.MinAmount, asset.MinWithdrawal, asset.ReferenceUSDRate).
+		Suffix("RETURNING created_at, updated_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&asset.CreatedAt, &asset.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	return nil
+}
+
+// GetByCode retrieves an asset by code
+func (r *AssetRepository) GetByCode(code string) (*models.Asset, error) {
+	query := r.qb.Select(assetColumns...).
+		From("assets").
+		Where(sq.Eq{"code": code})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	asset, err := scanAsset(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	return asset, nil
+}
+
+// GetAll retrieves every registered asset
+func (r *AssetRepository) GetAll() ([]*models.Asset, error) {
+	query := r.qb.Select(assetColumns...).
+		From("assets").
+		OrderBy("kind", "code")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets: %w", err)
+	}
+	defer rows.Close()
+
+	var assets []*models.Asset
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, nil
+}
+
+// Update updates the mutable attributes of an asset
+func (r *AssetRepository) Update(asset *models.Asset) error {
+	query := r.qb.Update("assets").
+		Set("name", asset.Name).
+		Set("decimals", asset.Decimals).
+		Set("chain", asset.Chain).
+		Set("address_prefix", asset.AddressPrefix).
+		Set("address_pattern", asset.AddressPattern).
+		Set("min_amount", asset.MinAmount).
+		Set("min_withdrawal", asset.MinWithdrawal).
+		Set("reference_usd_rate", asset.ReferenceUSDRate).
+		Where(sq.Eq{"code": asset.Code}).
+		Suffix("RETURNING updated_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&asset.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("asset not found")
+		}
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+
+	return nil
+}
+
+// SetEnabled enables or disables an asset
+func (r *AssetRepository) SetEnabled(code string, enabled bool) error {
+	query := r.qb.Update("assets").
+		Set("enabled", enabled).
+		Where(sq.Eq{"code": code})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("asset not found")
+	}
+
+	return nil
+}
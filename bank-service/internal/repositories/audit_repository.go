@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/pkg/auditchain"
+)
+
+// auditChainLock is the advisory lock key serialising appends to the chain
+// across all instances of the service
+const auditChainLock = 0x61756469
+
+type AuditRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var auditColumns = []string{
+	"seq", "id", "occurred_at", "actor_type", "actor_id", "api_key_id", "actor_role", "action", "entity_type",
+	"entity_id", "before", "after", "request_id", "trace_id", "ip", "prev_hash", "hash",
+}
+
+// Append seals the entry onto the head of the chain and stores it
+func (r *AuditRepository) Append(entry *auditchain.Entry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", auditChainLock); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	headSeq, headHash := int64(0), auditchain.GenesisHash
+	err = tx.QueryRow("SELECT seq, hash FROM audit_log ORDER BY seq DESC LIMIT 1").Scan(&headSeq, &headHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	if err := auditchain.Seal(entry, headSeq, headHash); err != nil {
+		return fmt.Errorf("failed to seal audit entry: %w", err)
+	}
+
+	sqlQuery, args, err := r.qb.Insert("audit_log").
+		Columns(auditColumns...).
+		Values(entry.Seq, entry.ID, entry.OccurredAt, entry.ActorType, entry.ActorID, entry.APIKeyID, entry.ActorRole,
+			entry.Action, entry.EntityType, entry.EntityID, nullJSON(entry.Before), nullJSON(entry.After),
+			entry.RequestID, entry.TraceID, entry.IP, entry.PrevHash, entry.Hash).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves entries matching the filter in chain order
+func (r *AuditRepository) List(filter *models.AuditFilter) ([]*auditchain.Entry, error) {
+	query := r.qb.Select(auditColumns...).
+		From("audit_log").
+		Where(sq.Gt{"seq": filter.AfterSeq}).
+		OrderBy("seq").
+		Limit(filter.Limit)
+
+	if filter.ActorID != nil {
+		query = query.Where(sq.Eq{"actor_id": *filter.ActorID})
+	}
+	if filter.EntityType != "" {
+		query = query.Where(sq.Eq{"entity_type": filter.EntityType})
+	}
+	if filter.EntityID != "" {
+		query = query.Where(sq.Eq{"entity_id": filter.EntityID})
+	}
+	if filter.Action != "" {
+		query = query.Where(sq.Eq{"action": filter.Action})
+	}
+	if filter.RequestID != "" {
+		query = query.Where(sq.Eq{"request_id": filter.RequestID})
+	}
+	if filter.From != nil {
+		query = query.Where(sq.GtOrEq{"occurred_at": *filter.From})
+	}
+	if filter.To != nil {
+		query = query.Where(sq.Lt{"occurred_at": *filter.To})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*auditchain.Entry
+	for rows.Next() {
+		var e auditchain.Entry
+		var before, after []byte
+		err := rows.Scan(&e.Seq, &e.ID, &e.OccurredAt, &e.ActorType, &e.ActorID, &e.APIKeyID, &e.ActorRole,
+			&e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.RequestID, &e.TraceID, &e.IP,
+			&e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// nullJSON stores a missing value as NULL rather than a JSON null
+func nullJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,356 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// CredentialRepository stores passwords, TOTP enrollments and recovery codes
+type CredentialRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewCredentialRepository(db *sql.DB) *CredentialRepository {
+	return &CredentialRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// CreateUser creates a user together with its password and the event
+// announcing it in one transaction. A user without an ID gets a new one.
+func (r *CredentialRepository) CreateUser(user *models.User, passwordHash string, event *models.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+
+	sqlQuery, args, err := r.qb.Insert("users").
+		Columns("id", "email", "first_name", "last_name", "phone", "role", "kyc_tier").
+		Values(user.ID, user.Email, user.FirstName, user.LastName, user.Phone, user.Role, user.KYCTier).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := tx.QueryRow(sqlQuery, args...).Scan(&user.CreatedAt, &user.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	sqlQuery, args, err = r.qb.Insert("user_credentials").
+		Columns("user_id", "password_hash").
+		Values(user.ID, passwordHash).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to create credentials: %w", err)
+	}
+
+	if err := insertOutboxMessage(tx, r.qb, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetCredential retrieves the password credential of a user
+func (r *CredentialRepository) GetCredential(userID uuid.UUID) (*models.UserCredential, error) {
+	query := r.qb.Select("user_id", "password_hash", "failed_attempts", "locked_until", "password_changed_at").
+		From("user_credentials").
+		Where(sq.Eq{"user_id": userID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var credential models.UserCredential
+	err = r.db.QueryRow(sqlQuery, args...).Scan(
+		&credential.UserID, &credential.PasswordHash, &credential.FailedAttempts,
+		&credential.LockedUntil, &credential.PasswordChangedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("credentials not found")
+		}
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// SetPassword replaces the password hash of a user
+func (r *CredentialRepository) SetPassword(userID uuid.UUID, passwordHash string) error {
+	query := r.qb.Update("user_credentials").
+		Set("password_hash", passwordHash).
+		Set("password_changed_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID})
+
+	return r.execOne(query, "credentials")
+}
+
+// RecordFailedLogin counts a failed login. Reaching maxAttempts locks the user
+// out for the lockout duration and starts a new count. It returns the lock
+// expiry if the user is now locked.
+func (r *CredentialRepository) RecordFailedLogin(userID uuid.UUID, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	lockedUntil := time.Now().Add(lockout)
+
+	query := r.qb.Update("user_credentials").
+		Set("locked_until", sq.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ?::timestamptz ELSE locked_until END", maxAttempts, lockedUntil)).
+		Set("failed_attempts", sq.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", maxAttempts)).
+		Where(sq.Eq{"user_id": userID}).
+		Suffix("RETURNING locked_until")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var locked *time.Time
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	if locked == nil || locked.Before(time.Now()) {
+		return nil, nil
+	}
+	return locked, nil
+}
+
+// ResetFailedLogins clears the failure count and lock of a user
+func (r *CredentialRepository) ResetFailedLogins(userID uuid.UUID) error {
+	query := r.qb.Update("user_credentials").
+		Set("failed_attempts", 0).
+		Set("locked_until", nil).
+		Where(sq.Eq{"user_id": userID})
+
+	return r.execOne(query, "credentials")
+}
+
+// UpsertTOTP stores a new, not yet confirmed TOTP secret
+func (r *CredentialRepository) UpsertTOTP(userID uuid.UUID, secret string) error {
+	query := r.qb.Insert("user_totp").
+		Columns("user_id", "secret").
+		Values(userID, secret).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, enabled = FALSE,
+			last_used_step = 0, created_at = CURRENT_TIMESTAMP, enabled_at = NULL`)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	return nil
+}
+
+// GetTOTP retrieves the TOTP enrollment of a user
+func (r *CredentialRepository) GetTOTP(userID uuid.UUID) (*models.UserTOTP, error) {
+	query := r.qb.Select("user_id", "secret", "enabled", "last_used_step", "created_at", "enabled_at").
+		From("user_totp").
+		Where(sq.Eq{"user_id": userID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var totp models.UserTOTP
+	err = r.db.QueryRow(sqlQuery, args...).Scan(
+		&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastUsedStep, &totp.CreatedAt, &totp.EnabledAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("TOTP enrollment not found")
+		}
+		return nil, fmt.Errorf("failed to get TOTP enrollment: %w", err)
+	}
+
+	return &totp, nil
+}
+
+// UseTOTPStep records the time step of an accepted code. It returns false if
+// that step or a later one was already used, which makes codes single use.
+func (r *CredentialRepository) UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	query := r.qb.Update("user_totp").
+		Set("last_used_step", step).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Lt{"last_used_step": step})
+
+	return r.execAffected(query)
+}
+
+// EnableTOTP confirms a TOTP enrollment and replaces the recovery codes
+func (r *CredentialRepository) EnableTOTP(userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlQuery, args, err := r.qb.Update("user_totp").
+		Set("enabled", true).
+		Set("enabled_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("last_used_step", step).
+		Where(sq.Eq{"user_id": userID, "enabled": false}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := tx.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("no pending TOTP enrollment")
+	}
+
+	if err := r.replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTOTP removes the TOTP enrollment and recovery codes of a user
+func (r *CredentialRepository) DeleteTOTP(userID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"user_recovery_codes", "user_totp"} {
+		sqlQuery, args, err := r.qb.Delete(table).Where(sq.Eq{"user_id": userID}).ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+		if _, err := tx.Exec(sqlQuery, args...); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes invalidates the recovery codes of a user and stores new ones
+func (r *CredentialRepository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false if the
+// code does not exist or was already used.
+func (r *CredentialRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	query := r.qb.Update("user_recovery_codes").
+		Set("used_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil})
+
+	return r.execAffected(query)
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of a user
+func (r *CredentialRepository) CountRecoveryCodes(userID uuid.UUID) (int, error) {
+	query := r.qb.Select("COUNT(*)").
+		From("user_recovery_codes").
+		Where(sq.Eq{"user_id": userID, "used_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *CredentialRepository) replaceRecoveryCodes(tx *sql.Tx, userID uuid.UUID, codeHashes []string) error {
+	sqlQuery, args, err := r.qb.Delete("user_recovery_codes").Where(sq.Eq{"user_id": userID}).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	insert := r.qb.Insert("user_recovery_codes").Columns("user_id", "code_hash")
+	for _, hash := range codeHashes {
+		insert = insert.Values(userID, hash)
+	}
+
+	sqlQuery, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CredentialRepository) execOne(query sq.UpdateBuilder, entity string) error {
+	updated, err := r.execAffected(query)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("%s not found", entity)
+	}
+	return nil
+}
+
+func (r *CredentialRepository) execAffected(query sq.UpdateBuilder) (bool, error) {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update credentials: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type CryptoWalletRepository struct {
-	db *sql.DB
+	db DBTX
 	qb sq.StatementBuilderType
 }
 
@@ -21,6 +21,11 @@ func NewCryptoWalletRepository(db *sql.DB) *CryptoWalletRepository {
 	}
 }
 
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *CryptoWalletRepository) WithTx(tx *sql.Tx) *CryptoWalletRepository {
+	return &CryptoWalletRepository{db: tx, qb: r.qb}
+}
+
 // Create creates a new crypto wallet
 func (r *CryptoWalletRepository) Create(wallet *models.CryptoWallet) error {
 	wallet.ID = uuid.New()
@@ -104,32 +109,27 @@ func (r *CryptoWalletRepository) GetByUserID(userID uuid.UUID) ([]*models.Crypto
 	return wallets, nil
 }
 
-// UpdateBalance updates crypto wallet balance
-func (r *CryptoWalletRepository) UpdateBalance(id uuid.UUID, amount float64) error {
+// UpdateBalance updates crypto wallet balance. It returns the balance after the change.
+func (r *CryptoWalletRepository) UpdateBalance(id uuid.UUID, amount float64) (float64, error) {
 	query := r.qb.Update("crypto_wallets").
 		Set("balance", sq.Expr("balance + ?", amount)).
-		Where(sq.Eq{"id": id})
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING balance")
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build query: %w", err)
-	}
-
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("crypto wallet not found")
+	var balance float64
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("crypto wallet not found")
+		}
+		return 0, fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	return nil
+	return balance, nil
 }
 
 // GetBalance retrieves crypto wallet balance
@@ -155,3 +155,80 @@ func (r *CryptoWalletRepository) GetBalance(id uuid.UUID) (float64, error) {
 
 	return balance, nil
 }
+
+// AddWhitelistedAddress adds an address to the withdrawal whitelist of a wallet
+func (r *CryptoWalletRepository) AddWhitelistedAddress(entry *models.WhitelistedAddress) error {
+	query := r.qb.Insert("withdrawal_whitelist").
+		Columns("wallet_id", "address", "label").
+		Values(entry.WalletID, entry.Address, entry.Label).
+		Suffix("RETURNING id, created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to whitelist address: %w", err)
+	}
+
+	return nil
+}
+
+// GetWhitelistedAddresses retrieves the withdrawal whitelist of a wallet
+func (r *CryptoWalletRepository) GetWhitelistedAddresses(walletID uuid.UUID) ([]*models.WhitelistedAddress, error) {
+	query := r.qb.Select("id", "wallet_id", "address", "label", "created_at").
+		From("withdrawal_whitelist").
+		Where(sq.Eq{"wallet_id": walletID}).
+		OrderBy("created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get whitelisted addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.WhitelistedAddress
+	for rows.Next() {
+		var entry models.WhitelistedAddress
+		if err := rows.Scan(&entry.ID, &entry.WalletID, &entry.Address, &entry.Label, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan whitelisted address: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// DeleteWhitelistedAddress removes an address from the withdrawal whitelist of a wallet
+func (r *CryptoWalletRepository) DeleteWhitelistedAddress(walletID, entryID uuid.UUID) error {
+	query := r.qb.Delete("withdrawal_whitelist").
+		Where(sq.Eq{"id": entryID, "wallet_id": walletID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete whitelisted address: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("whitelisted address not found")
+	}
+
+	return nil
+}
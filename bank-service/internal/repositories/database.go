@@ -8,6 +8,14 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
+// DBTX runs queries on the connection pool or inside a transaction, so
+// repositories can take part in a transaction started by a service
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type Database struct {
 	DB *sql.DB
 }
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/shared/events"
+)
+
+type EventStoreRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewEventStoreRepository(db *sql.DB) *EventStoreRepository {
+	return &EventStoreRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var eventStoreColumns = []string{
+	"position", "event_id", "event_type", "schema_version", "subject", "correlation_id", "occurred_at", "payload",
+	"recorded_at",
+}
+
+// appendStoredEvent keeps the event of an outbox message in the event store.
+// insertOutboxMessage calls it, so every event is stored in the transaction
+// that publishes it.
+func appendStoredEvent(db DBTX, qb sq.StatementBuilderType, msg *models.OutboxMessage) error {
+	envelope, err := events.Parse(msg.RoutingKey, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	payload := []byte(msg.Payload)
+	if envelope.ID == "" {
+		// Bare data is stored the way the relay publishes it
+		envelope.ID = msg.MessageID.String()
+		envelope.CorrelationID = envelope.ID
+		envelope.OccurredAt = time.Now().UTC()
+		envelope.Producer = "bank-service"
+		if payload, err = json.Marshal(envelope); err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+	}
+
+	sqlQuery, args, err := qb.Insert("event_store").
+		Columns("event_id", "event_type", "schema_version", "subject", "correlation_id", "occurred_at", "payload").
+		Values(msg.MessageID, envelope.Type, envelope.Version, envelope.Subject, envelope.CorrelationID,
+			envelope.OccurredAt, payload).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves stored events matching the filter in the order they were added
+func (r *EventStoreRepository) List(filter *models.EventFilter) ([]*models.StoredEvent, error) {
+	query := r.qb.Select(eventStoreColumns...).
+		From("event_store").
+		Where(sq.Gt{"position": filter.AfterPosition}).
+		OrderBy("position").
+		Limit(filter.Limit)
+
+	if filter.From != nil {
+		query = query.Where(sq.GtOrEq{"occurred_at": *filter.From})
+	}
+	if filter.To != nil {
+		query = query.Where(sq.Lt{"occurred_at": *filter.To})
+	}
+	if filter.Subject != "" {
+		query = query.Where(sq.Eq{"subject": filter.Subject})
+	}
+	if filter.UserID != "" {
+		query = query.Where(sq.Eq{"payload->'data'->>'user_id'": filter.UserID})
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where(sq.Eq{"event_type": filter.Types})
+	}
+	if filter.Published {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM outbox WHERE outbox.message_id = event_store.event_id AND outbox.sent_at IS NULL)")
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored events: %w", err)
+	}
+	defer rows.Close()
+
+	var stored []*models.StoredEvent
+	for rows.Next() {
+		var e models.StoredEvent
+		var payload []byte
+		err := rows.Scan(&e.Position, &e.EventID, &e.Type, &e.SchemaVersion, &e.Subject, &e.CorrelationID,
+			&e.OccurredAt, &payload, &e.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stored event: %w", err)
+		}
+		e.Payload = payload
+		stored = append(stored, &e)
+	}
+
+	return stored, rows.Err()
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/crypto-bank/bank-service/internal/models"
@@ -10,7 +11,7 @@ import (
 )
 
 type ExchangeRepository struct {
-	db *sql.DB
+	db DBTX
 	qb sq.StatementBuilderType
 }
 
@@ -21,6 +22,11 @@ func NewExchangeRepository(db *sql.DB) *ExchangeRepository {
 	}
 }
 
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *ExchangeRepository) WithTx(tx *sql.Tx) *ExchangeRepository {
+	return &ExchangeRepository{db: tx, qb: r.qb}
+}
+
 // Create creates a new exchange
 func (r *ExchangeRepository) Create(exchange *models.Exchange) error {
 	exchange.ID = uuid.New()
@@ -170,3 +176,67 @@ func (r *ExchangeRepository) GetExchangeRate(fromCurrency, toCurrency string) (f
 
 	return rate, nil
 }
+
+// CreateExchangeRateIfMissing seeds an exchange rate unless one is already configured
+func (r *ExchangeRepository) CreateExchangeRateIfMissing(fromCurrency, toCurrency string, rate float64) error {
+	query := r.qb.Insert("exchange_rates").
+		Columns("from_currency", "to_currency", "rate").
+		Values(fromCurrency, toCurrency, rate).
+		Suffix("ON CONFLICT (from_currency, to_currency) DO NOTHING")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to create exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+// SumFromAmountsSince totals the source amounts per currency of the exchanges
+// of a user created since the given time, leaving out failed exchanges
+func (r *ExchangeRepository) SumFromAmountsSince(userID uuid.UUID, since time.Time) (map[string]float64, error) {
+	query := r.qb.Select("from_currency", "COALESCE(SUM(from_amount), 0)").
+		From("exchanges").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"status": models.ExchangeStatusFailed}).
+		Where(sq.GtOrEq{"created_at": since}).
+		GroupBy("from_currency")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum exchanges: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCurrencyTotals(rows)
+}
+
+// CountSince counts the exchanges of a user created since the given time,
+// leaving out failed exchanges
+func (r *ExchangeRepository) CountSince(userID uuid.UUID, since time.Time) (int, error) {
+	sqlQuery, args, err := r.qb.Select("COUNT(*)").
+		From("exchanges").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"status": models.ExchangeStatusFailed}).
+		Where(sq.GtOrEq{"created_at": since}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count exchanges: %w", err)
+	}
+
+	return count, nil
+}
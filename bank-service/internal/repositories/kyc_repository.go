@@ -0,0 +1,330 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type KYCRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewKYCRepository(db *sql.DB) *KYCRepository {
+	return &KYCRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var kycApplicationColumns = []string{
+	"id", "user_id", "requested_tier", "status", "date_of_birth", "country", "address_line", "city",
+	"postal_code", "provider", "provider_reference", "provider_reason", "reviewer_id", "review_note",
+	"reviewed_at", "created_at", "updated_at",
+}
+
+var kycDocumentColumns = []string{
+	"id", "application_id", "type", "file_name", "content_type", "size_bytes", "sha256", "created_at",
+}
+
+// CreateApplication stores a pending application together with its documents
+func (r *KYCRepository) CreateApplication(app *models.KYCApplication, docs []*models.KYCDocument) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	app.ID = uuid.New()
+
+	sqlQuery, args, err := r.qb.Insert("kyc_applications").
+		Columns("id", "user_id", "requested_tier", "status", "date_of_birth", "country",
+			"address_line", "city", "postal_code", "provider").
+		Values(app.ID, app.UserID, app.RequestedTier, app.Status, app.DateOfBirth, app.Country,
+			app.AddressLine, app.City, app.PostalCode, app.Provider).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := tx.QueryRow(sqlQuery, args...).Scan(&app.CreatedAt, &app.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create KYC application: %w", err)
+	}
+
+	for _, doc := range docs {
+		doc.ID = uuid.New()
+		doc.ApplicationID = app.ID
+
+		sqlQuery, args, err := r.qb.Insert("kyc_documents").
+			Columns("id", "application_id", "type", "file_name", "content_type", "size_bytes", "sha256", "content").
+			Values(doc.ID, doc.ApplicationID, doc.Type, doc.FileName, doc.ContentType, doc.SizeBytes, doc.SHA256, doc.Content).
+			Suffix("RETURNING created_at").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		if err := tx.QueryRow(sqlQuery, args...).Scan(&doc.CreatedAt); err != nil {
+			return fmt.Errorf("failed to store KYC document: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	app.Documents = docs
+	return nil
+}
+
+// RecordProviderDecision stores the answer of the verification provider on a
+// pending application. Approved applications raise the tier of the user and
+// add the event announcing it to the outbox.
+func (r *KYCRepository) RecordProviderDecision(id uuid.UUID, status models.KYCApplicationStatus, reference, reason *string, event *models.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	update := r.qb.Update("kyc_applications").
+		Set("status", status).
+		Set("provider_reference", reference).
+		Set("provider_reason", reason).
+		Where(sq.Eq{"id": id, "status": models.KYCStatusPending})
+
+	if err := r.decide(tx, update, status, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Review stores the decision of a compliance reviewer on an application waiting
+// for manual review. Approved applications raise the tier of the user and add
+// the event announcing it to the outbox.
+func (r *KYCRepository) Review(id uuid.UUID, status models.KYCApplicationStatus, reviewerID uuid.UUID, note string, event *models.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	update := r.qb.Update("kyc_applications").
+		Set("status", status).
+		Set("reviewer_id", reviewerID).
+		Set("review_note", note).
+		Set("reviewed_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "status": models.KYCStatusInReview})
+
+	if err := r.decide(tx, update, status, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// decide runs a status update and grants the requested tier on approval
+func (r *KYCRepository) decide(tx *sql.Tx, update sq.UpdateBuilder, status models.KYCApplicationStatus, event *models.OutboxMessage) error {
+	sqlQuery, args, err := update.Suffix("RETURNING user_id, requested_tier").ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID uuid.UUID
+	var tier models.KYCTier
+	err = tx.QueryRow(sqlQuery, args...).Scan(&userID, &tier)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("KYC application not found or already decided")
+		}
+		return fmt.Errorf("failed to update KYC application: %w", err)
+	}
+
+	if status != models.KYCStatusApproved {
+		return nil
+	}
+
+	sqlQuery, args, err = r.qb.Update("users").
+		Set("kyc_tier", tier).
+		Where(sq.Eq{"id": userID, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to update KYC tier: %w", err)
+	}
+
+	if event == nil {
+		return nil
+	}
+	return insertOutboxMessage(tx, r.qb, event)
+}
+
+// GetApplication retrieves an application with the metadata of its documents
+func (r *KYCRepository) GetApplication(id uuid.UUID) (*models.KYCApplication, error) {
+	sqlQuery, args, err := r.qb.Select(kycApplicationColumns...).
+		From("kyc_applications").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	app, err := scanKYCApplication(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("KYC application not found")
+		}
+		return nil, fmt.Errorf("failed to get KYC application: %w", err)
+	}
+
+	app.Documents, err = r.getDocuments(app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// GetApplicationsByUserID retrieves the applications of a user, newest first
+func (r *KYCRepository) GetApplicationsByUserID(userID uuid.UUID) ([]*models.KYCApplication, error) {
+	return r.listApplications(sq.Eq{"user_id": userID})
+}
+
+// GetApplicationsByStatus retrieves applications in a status, oldest first so
+// that reviewers work through the queue in order
+func (r *KYCRepository) GetApplicationsByStatus(status models.KYCApplicationStatus) ([]*models.KYCApplication, error) {
+	return r.listApplications(sq.Eq{"status": status})
+}
+
+func (r *KYCRepository) listApplications(where sq.Eq) ([]*models.KYCApplication, error) {
+	order := "created_at DESC"
+	if _, byStatus := where["status"]; byStatus {
+		order = "created_at ASC"
+	}
+
+	sqlQuery, args, err := r.qb.Select(kycApplicationColumns...).
+		From("kyc_applications").
+		Where(where).
+		OrderBy(order).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC applications: %w", err)
+	}
+	defer rows.Close()
+
+	var apps []*models.KYCApplication
+	for rows.Next() {
+		app, err := scanKYCApplication(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan KYC application: %w", err)
+		}
+		apps = append(apps, app)
+	}
+
+	return apps, rows.Err()
+}
+
+func (r *KYCRepository) getDocuments(applicationID uuid.UUID) ([]*models.KYCDocument, error) {
+	sqlQuery, args, err := r.qb.Select(kycDocumentColumns...).
+		From("kyc_documents").
+		Where(sq.Eq{"application_id": applicationID}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*models.KYCDocument
+	for rows.Next() {
+		var doc models.KYCDocument
+		err := rows.Scan(&doc.ID, &doc.ApplicationID, &doc.Type, &doc.FileName, &doc.ContentType,
+			&doc.SizeBytes, &doc.SHA256, &doc.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan KYC document: %w", err)
+		}
+		docs = append(docs, &doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// GetDocument retrieves a document of an application including its content
+func (r *KYCRepository) GetDocument(applicationID, id uuid.UUID) (*models.KYCDocument, error) {
+	sqlQuery, args, err := r.qb.Select(append(kycDocumentColumns, "content")...).
+		From("kyc_documents").
+		Where(sq.Eq{"id": id, "application_id": applicationID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var doc models.KYCDocument
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&doc.ID, &doc.ApplicationID, &doc.Type, &doc.FileName,
+		&doc.ContentType, &doc.SizeBytes, &doc.SHA256, &doc.CreatedAt, &doc.Content)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("KYC document not found")
+		}
+		return nil, fmt.Errorf("failed to get KYC document: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// GetTierLimits retrieves the limits of a tier
+func (r *KYCRepository) GetTierLimits(tier models.KYCTier) (*models.KYCTierLimits, error) {
+	sqlQuery, args, err := r.qb.Select("tier", "deposit_daily_usd", "withdrawal_daily_usd",
+		"transfer_daily_usd", "exchange_daily_usd").
+		From("kyc_tier_limits").
+		Where(sq.Eq{"tier": tier}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var limits models.KYCTierLimits
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&limits.Tier, &limits.DepositDailyUSD,
+		&limits.WithdrawalDailyUSD, &limits.TransferDailyUSD, &limits.ExchangeDailyUSD)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no limits for KYC tier %s", tier)
+		}
+		return nil, fmt.Errorf("failed to get KYC tier limits: %w", err)
+	}
+
+	return &limits, nil
+}
+
+func scanKYCApplication(row rowScanner) (*models.KYCApplication, error) {
+	var app models.KYCApplication
+	err := row.Scan(
+		&app.ID, &app.UserID, &app.RequestedTier, &app.Status, &app.DateOfBirth, &app.Country,
+		&app.AddressLine, &app.City, &app.PostalCode, &app.Provider, &app.ProviderReference,
+		&app.ProviderReason, &app.ReviewerID, &app.ReviewNote, &app.ReviewedAt,
+		&app.CreatedAt, &app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// limitUsageLock is the advisory lock class serialising the limit checks of a
+// user across all instances of the service
+const limitUsageLock = 0x6c696d69
+
+type LimitRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewLimitRepository(db *sql.DB) *LimitRepository {
+	return &LimitRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var limitRuleColumns = []string{
+	"id", "scope", "kyc_tier", "user_id", "kind", "currency", "value", "created_at", "updated_at",
+}
+
+// Upsert creates or replaces the rule for its scope, kind and currency. It
+// returns the rule replaced, or nil if there was none.
+func (r *LimitRepository) Upsert(rule *models.LimitRule) (*models.LimitRule, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	match := sq.Eq{
+		"scope":    rule.Scope,
+		"kyc_tier": rule.KYCTier,
+		"user_id":  nil,
+		"kind":     rule.Kind,
+		"currency": rule.Currency,
+	}
+	// A nil *uuid.UUID would panic inside its driver.Valuer
+	if rule.UserID != nil {
+		match["user_id"] = *rule.UserID
+	}
+
+	existing := r.qb.Select(limitRuleColumns...).
+		From("limit_rules").
+		Where(match)
+
+	sqlQuery, args, err := existing.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	replaced, err := scanLimitRule(tx.QueryRow(sqlQuery, args...))
+	switch {
+	case err == sql.ErrNoRows:
+		replaced = nil
+		rule.ID = uuid.New()
+		sqlQuery, args, err = r.qb.Insert("limit_rules").
+			Columns("id", "scope", "kyc_tier", "user_id", "kind", "currency", "value").
+			Values(rule.ID, rule.Scope, rule.KYCTier, rule.UserID, rule.Kind, rule.Currency, rule.Value).
+			Suffix("RETURNING created_at, updated_at").
+			ToSql()
+	case err != nil:
+		return nil, fmt.Errorf("failed to get limit rule: %w", err)
+	default:
+		rule.ID = replaced.ID
+		sqlQuery, args, err = r.qb.Update("limit_rules").
+			Set("value", rule.Value).
+			Where(sq.Eq{"id": replaced.ID}).
+			Suffix("RETURNING created_at, updated_at").
+			ToSql()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := tx.QueryRow(sqlQuery, args...).Scan(&rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save limit rule: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit limit rule: %w", err)
+	}
+
+	return replaced, nil
+}
+
+// GetApplicable retrieves the global rules, the rules of a tier and the rules of
+// a user
+func (r *LimitRepository) GetApplicable(userID uuid.UUID, tier models.KYCTier) ([]*models.LimitRule, error) {
+	return r.list(sq.Or{
+		sq.Eq{"scope": models.LimitScopeGlobal},
+		sq.Eq{"scope": models.LimitScopeTier, "kyc_tier": tier},
+		sq.Eq{"scope": models.LimitScopeUser, "user_id": userID},
+	})
+}
+
+// GetAll retrieves the rules matching a filter
+func (r *LimitRepository) GetAll(filter *models.LimitRuleFilter) ([]*models.LimitRule, error) {
+	where := sq.Eq{}
+	if filter.Scope != "" {
+		where["scope"] = filter.Scope
+	}
+	if filter.KYCTier != nil {
+		where["kyc_tier"] = *filter.KYCTier
+	}
+	if filter.UserID != nil {
+		where["user_id"] = *filter.UserID
+	}
+	return r.list(where)
+}
+
+func (r *LimitRepository) list(where sq.Sqlizer) ([]*models.LimitRule, error) {
+	sqlQuery, args, err := r.qb.Select(limitRuleColumns...).
+		From("limit_rules").
+		Where(where).
+		OrderBy("scope", "kind", "currency").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.LimitRule
+	for rows.Next() {
+		rule, err := scanLimitRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan limit rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanLimitRule(row rowScanner) (*models.LimitRule, error) {
+	var rule models.LimitRule
+	err := row.Scan(&rule.ID, &rule.Scope, &rule.KYCTier, &rule.UserID, &rule.Kind,
+		&rule.Currency, &rule.Value, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Delete removes a rule and returns it
+func (r *LimitRepository) Delete(id uuid.UUID) (*models.LimitRule, error) {
+	sqlQuery, args, err := r.qb.Delete("limit_rules").
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING " + strings.Join(limitRuleColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rule, err := scanLimitRule(r.db.QueryRow(sqlQuery, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("limit rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete limit rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// LockUsage locks the limit usage of the user until the transaction ends
+func (r *LimitRepository) LockUsage(tx *sql.Tx, userID uuid.UUID) error {
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1, hashtext($2))", limitUsageLock, userID.String()); err != nil {
+		return fmt.Errorf("failed to lock limit usage: %w", err)
+	}
+	return nil
+}
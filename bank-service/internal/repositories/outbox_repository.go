@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type OutboxRepository struct {
+	db DBTX
+	qb sq.StatementBuilderType
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// WithTx returns a repository that stores messages inside the transaction
+func (r *OutboxRepository) WithTx(tx *sql.Tx) *OutboxRepository {
+	return &OutboxRepository{db: tx, qb: r.qb}
+}
+
+// Add stores a message for the relay to publish
+func (r *OutboxRepository) Add(msg *models.OutboxMessage) error {
+	return insertOutboxMessage(r.db, r.qb, msg)
+}
+
+// insertOutboxMessage stores a message on a connection or transaction and
+// appends its event to the event store. Other repositories use it to add
+// events to transactions they manage themselves.
+func insertOutboxMessage(db DBTX, qb sq.StatementBuilderType, msg *models.OutboxMessage) error {
+	if msg.MessageID == uuid.Nil {
+		msg.MessageID = uuid.New()
+	}
+
+	if err := appendStoredEvent(db, qb, msg); err != nil {
+		return err
+	}
+
+	var traceContext []byte
+	if len(msg.TraceContext) > 0 {
+		var err error
+		if traceContext, err = json.Marshal(msg.TraceContext); err != nil {
+			return fmt.Errorf("failed to marshal trace context: %w", err)
+		}
+	}
+
+	sqlQuery, args, err := qb.Insert("outbox").
+		Columns("message_id", "exchange", "routing_key", "payload", "trace_context").
+		Values(msg.MessageID, msg.Exchange, msg.RoutingKey, []byte(msg.Payload), traceContext).
+		Suffix("RETURNING id, next_attempt_at, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := db.QueryRow(sqlQuery, args...).Scan(&msg.ID, &msg.NextAttemptAt, &msg.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// Claim picks up to limit messages that are due and keeps other relays off
+// them until the claim expires. Messages come back in the order they were added.
+func (r *OutboxRepository) Claim(limit int, now, until time.Time) ([]*models.OutboxMessage, error) {
+	// SKIP LOCKED lets relays of several instances claim disjoint batches
+	sqlQuery := `UPDATE outbox SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE sent_at IS NULL AND next_attempt_at <= $2
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, message_id, exchange, routing_key, payload, trace_context, attempts, last_error,
+			next_attempt_at, sent_at, created_at`
+
+	rows, err := r.db.Query(sqlQuery, until, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*models.OutboxMessage
+	for rows.Next() {
+		var msg models.OutboxMessage
+		var payload, traceContext []byte
+		err := rows.Scan(
+			&msg.ID, &msg.MessageID, &msg.Exchange, &msg.RoutingKey, &payload, &traceContext, &msg.Attempts,
+			&msg.LastError, &msg.NextAttemptAt, &msg.SentAt, &msg.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		msg.Payload = payload
+		if len(traceContext) > 0 {
+			// A broken trace context only loses the link to the request
+			_ = json.Unmarshal(traceContext, &msg.TraceContext)
+		}
+		messages = append(messages, &msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// MarkSent records that the broker confirmed a message
+func (r *OutboxRepository) MarkSent(id int64, sentAt time.Time) error {
+	sqlQuery, args, err := r.qb.Update("outbox").
+		Set("sent_at", sentAt).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and when to try again
+func (r *OutboxRepository) MarkFailed(id int64, reason string, nextAttemptAt time.Time) error {
+	sqlQuery, args, err := r.qb.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", reason).
+		Set("next_attempt_at", nextAttemptAt).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+
+	return nil
+}
+
+// Release hands claimed messages back without counting an attempt
+func (r *OutboxRepository) Release(ids []int64, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sqlQuery, args, err := r.qb.Update("outbox").
+		Set("next_attempt_at", now).
+		Where(sq.Eq{"id": ids, "sent_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to release outbox messages: %w", err)
+	}
+
+	return nil
+}
+
+// Stats counts unpublished messages and finds the oldest of them
+func (r *OutboxRepository) Stats() (*models.OutboxStats, error) {
+	sqlQuery, args, err := r.qb.Select("COUNT(*)", "MIN(created_at)").
+		From("outbox").
+		Where(sq.Eq{"sent_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var stats models.OutboxStats
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&stats.Pending, &stats.OldestPending); err != nil {
+		return nil, fmt.Errorf("failed to get outbox stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// PurgeSent deletes messages published before the given time
+func (r *OutboxRepository) PurgeSent(before time.Time) (int64, error) {
+	sqlQuery, args, err := r.qb.Delete("outbox").
+		Where(sq.Lt{"sent_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+
+	return result.RowsAffected()
+}
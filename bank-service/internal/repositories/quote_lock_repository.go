@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+type QuoteLockRepository struct {
+	db DBTX
+	qb sq.StatementBuilderType
+}
+
+func NewQuoteLockRepository(db *sql.DB) *QuoteLockRepository {
+	return &QuoteLockRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var quoteLockColumns = []string{
+	"lock_key", "quote_id", "from_currency", "to_currency", "amount", "rate", "expires_at", "released_at",
+	"created_at",
+}
+
+// Hold stores a lock unless its key holds one already, and returns the lock
+// the key holds, released or not
+func (r *QuoteLockRepository) Hold(lock *models.QuoteLock) (*models.QuoteLock, error) {
+	query := r.qb.Insert("quote_locks").
+		Columns("lock_key", "quote_id", "from_currency", "to_currency", "amount", "rate", "expires_at").
+		Values(lock.Key, lock.QuoteID, lock.FromCurrency, lock.ToCurrency, lock.Amount, lock.Rate, lock.ExpiresAt).
+		Suffix("ON CONFLICT (lock_key) DO NOTHING")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to hold quote lock: %w", err)
+	}
+
+	return r.GetByKey(lock.Key)
+}
+
+// GetByKey retrieves the lock held under a key
+func (r *QuoteLockRepository) GetByKey(key string) (*models.QuoteLock, error) {
+	query := r.qb.Select(quoteLockColumns...).From("quote_locks").Where(sq.Eq{"lock_key": key})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var lock models.QuoteLock
+	err = r.db.QueryRow(sqlQuery, args...).Scan(
+		&lock.Key, &lock.QuoteID, &lock.FromCurrency, &lock.ToCurrency, &lock.Amount, &lock.Rate,
+		&lock.ExpiresAt, &lock.ReleasedAt, &lock.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("quote lock not found")
+		}
+		return nil, fmt.Errorf("failed to get quote lock: %w", err)
+	}
+
+	return &lock, nil
+}
+
+// Release marks the lock held under a key released. Releasing a released lock,
+// or a key that holds none, succeeds.
+func (r *QuoteLockRepository) Release(key string) error {
+	query := r.qb.Update("quote_locks").
+		Set("released_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"lock_key": key, "released_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to release quote lock: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create stores an issued refresh token
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	query := r.qb.Insert("refresh_tokens").
+		Columns("id", "user_id", "expires_at").
+		Values(token.ID, token.UserID, token.ExpiresAt).
+		Suffix("RETURNING created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a refresh token by its token ID
+func (r *RefreshTokenRepository) GetByID(id uuid.UUID) (*models.RefreshToken, error) {
+	query := r.qb.Select("id", "user_id", "expires_at", "revoked_at", "replaced_by", "created_at").
+		From("refresh_tokens").
+		Where(sq.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var token models.RefreshToken
+	err = r.db.QueryRow(sqlQuery, args...).Scan(
+		&token.ID, &token.UserID, &token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Rotate revokes an active refresh token and links its replacement. It returns
+// false if the token was already revoked, e.g. by a concurrent refresh.
+func (r *RefreshTokenRepository) Rotate(id, replacedBy uuid.UUID) (bool, error) {
+	query := r.qb.Update("refresh_tokens").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("replaced_by", replacedBy).
+		Where(sq.Eq{"id": id, "revoked_at": nil})
+
+	return r.execAffected(query)
+}
+
+// Revoke revokes a refresh token
+func (r *RefreshTokenRepository) Revoke(id uuid.UUID) (bool, error) {
+	query := r.qb.Update("refresh_tokens").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "revoked_at": nil})
+
+	return r.execAffected(query)
+}
+
+// RevokeAllForUser revokes every active refresh token of a user
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uuid.UUID) (int64, error) {
+	query := r.qb.Update("refresh_tokens").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpired removes refresh tokens that expired before the given time
+func (r *RefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	query := r.qb.Delete("refresh_tokens").
+		Where(sq.Lt{"expires_at": before})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *RefreshTokenRepository) execAffected(query sq.UpdateBuilder) (bool, error) {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
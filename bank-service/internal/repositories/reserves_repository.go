@@ -0,0 +1,268 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type ReservesRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewReservesRepository(db *sql.DB) *ReservesRepository {
+	return &ReservesRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// UserBalance is the total balance of one user in one crypto type
+type UserBalance struct {
+	UserID     uuid.UUID
+	CryptoType models.CryptoType
+	Balance    float64
+}
+
+// GetUserBalances sums crypto wallet balances per user and crypto type
+func (r *ReservesRepository) GetUserBalances() ([]UserBalance, error) {
+	query := r.qb.Select("user_id", "crypto_type", "SUM(balance)").
+		From("crypto_wallets").
+		GroupBy("user_id", "crypto_type")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []UserBalance
+	for rows.Next() {
+		var balance UserBalance
+		if err := rows.Scan(&balance.UserID, &balance.CryptoType, &balance.Balance); err != nil {
+			return nil, fmt.Errorf("failed to scan user balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+// CreateSnapshot stores a snapshot with its roots and leaves atomically
+func (r *ReservesRepository) CreateSnapshot(snapshot *models.ReserveSnapshot, leaves []models.ReserveLeaf) error {
+	snapshot.ID = uuid.New()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlQuery, args, err := r.qb.Insert("reserve_snapshots").
+		Columns("id").
+		Values(snapshot.ID).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := tx.QueryRow(sqlQuery, args...).Scan(&snapshot.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create reserve snapshot: %w", err)
+	}
+
+	for _, root := range snapshot.Roots {
+		sqlQuery, args, err := r.qb.Insert("reserve_roots").
+			Columns("snapshot_id", "crypto_type", "root_hash", "root_sum", "total_liabilities", "house_holdings", "leaf_count").
+			Values(snapshot.ID, root.CryptoType, root.RootHash, root.RootSum, root.TotalLiabilities, root.HouseHoldings, root.LeafCount).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		if _, err := tx.Exec(sqlQuery, args...); err != nil {
+			return fmt.Errorf("failed to create reserve root: %w", err)
+		}
+	}
+
+	// Insert leaves in batches to keep statements within parameter limits
+	const batchSize = 1000
+	for start := 0; start < len(leaves); start += batchSize {
+		end := start + batchSize
+		if end > len(leaves) {
+			end = len(leaves)
+		}
+
+		query := r.qb.Insert("reserve_leaves").
+			Columns("snapshot_id", "crypto_type", "leaf_index", "user_id", "salt", "balance", "leaf_hash")
+		for _, leaf := range leaves[start:end] {
+			query = query.Values(snapshot.ID, leaf.CryptoType, leaf.LeafIndex, leaf.UserID, leaf.Salt, leaf.Balance, leaf.LeafHash)
+		}
+
+		sqlQuery, args, err := query.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		if _, err := tx.Exec(sqlQuery, args...); err != nil {
+			return fmt.Errorf("failed to create reserve leaves: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshotByID retrieves a snapshot with its roots
+func (r *ReservesRepository) GetSnapshotByID(id uuid.UUID) (*models.ReserveSnapshot, error) {
+	return r.getSnapshot(r.qb.Select("id", "created_at").
+		From("reserve_snapshots").
+		Where(sq.Eq{"id": id}))
+}
+
+// GetLatestSnapshot retrieves the most recent snapshot with its roots
+func (r *ReservesRepository) GetLatestSnapshot() (*models.ReserveSnapshot, error) {
+	return r.getSnapshot(r.qb.Select("id", "created_at").
+		From("reserve_snapshots").
+		OrderBy("created_at DESC").
+		Limit(1))
+}
+
+func (r *ReservesRepository) getSnapshot(query sq.SelectBuilder) (*models.ReserveSnapshot, error) {
+	var snapshot models.ReserveSnapshot
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&snapshot.ID, &snapshot.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reserve snapshot not found")
+		}
+		return nil, fmt.Errorf("failed to get reserve snapshot: %w", err)
+	}
+
+	roots, err := r.getRoots(snapshot.ID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Roots = roots
+
+	return &snapshot, nil
+}
+
+func (r *ReservesRepository) getRoots(snapshotID uuid.UUID) ([]models.ReserveRoot, error) {
+	query := r.qb.Select("crypto_type", "root_hash", "root_sum", "total_liabilities", "house_holdings", "leaf_count").
+		From("reserve_roots").
+		Where(sq.Eq{"snapshot_id": snapshotID}).
+		OrderBy("crypto_type")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserve roots: %w", err)
+	}
+	defer rows.Close()
+
+	var roots []models.ReserveRoot
+	for rows.Next() {
+		var root models.ReserveRoot
+		err := rows.Scan(&root.CryptoType, &root.RootHash, &root.RootSum,
+			&root.TotalLiabilities, &root.HouseHoldings, &root.LeafCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reserve root: %w", err)
+		}
+		root.FullyCovered = root.HouseHoldings >= root.TotalLiabilities
+		roots = append(roots, root)
+	}
+
+	return roots, nil
+}
+
+// GetSnapshots retrieves the most recent snapshots without their roots
+func (r *ReservesRepository) GetSnapshots(limit uint64) ([]*models.ReserveSnapshot, error) {
+	query := r.qb.Select("id", "created_at").
+		From("reserve_snapshots").
+		OrderBy("created_at DESC").
+		Limit(limit)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserve snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*models.ReserveSnapshot
+	for rows.Next() {
+		var snapshot models.ReserveSnapshot
+		if err := rows.Scan(&snapshot.ID, &snapshot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reserve snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// GetLeaves retrieves all leaves of one crypto type in tree order
+func (r *ReservesRepository) GetLeaves(snapshotID uuid.UUID, cryptoType models.CryptoType) ([]models.ReserveLeaf, error) {
+	return r.getLeaves(sq.Eq{"snapshot_id": snapshotID, "crypto_type": cryptoType})
+}
+
+// GetUserLeaves retrieves the leaves of a user across crypto types
+func (r *ReservesRepository) GetUserLeaves(snapshotID, userID uuid.UUID) ([]models.ReserveLeaf, error) {
+	return r.getLeaves(sq.Eq{"snapshot_id": snapshotID, "user_id": userID})
+}
+
+func (r *ReservesRepository) getLeaves(where sq.Eq) ([]models.ReserveLeaf, error) {
+	query := r.qb.Select("crypto_type", "leaf_index", "user_id", "salt", "balance", "leaf_hash").
+		From("reserve_leaves").
+		Where(where).
+		OrderBy("crypto_type", "leaf_index")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserve leaves: %w", err)
+	}
+	defer rows.Close()
+
+	var leaves []models.ReserveLeaf
+	for rows.Next() {
+		var leaf models.ReserveLeaf
+		err := rows.Scan(&leaf.CryptoType, &leaf.LeafIndex, &leaf.UserID, &leaf.Salt, &leaf.Balance, &leaf.LeafHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reserve leaf: %w", err)
+		}
+		leaves = append(leaves, leaf)
+	}
+
+	return leaves, nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type RiskRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewRiskRepository(db *sql.DB) *RiskRepository {
+	return &RiskRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+const (
+	knownClientDevice = "device"
+	knownClientIP     = "ip"
+)
+
+var riskDecisionColumns = []string{
+	"id", "user_id", "operation", "amount", "currency", "amount_usd", "decision", "score", "reasons",
+	"features", "scorer", "scorer_error", "stepped_up", "ip", "latency_ms", "created_at",
+}
+
+// KnownClient reports whether the user already moved money from the device and
+// from the IP address, and from how many devices in total
+func (r *RiskRepository) KnownClient(userID uuid.UUID, deviceHash, ip string) (deviceKnown, ipKnown bool, devices int, err error) {
+	sqlQuery, args, err := r.qb.Select("COUNT(*) FILTER (WHERE kind = 'device')").
+		Column(sq.Expr("COUNT(*) FILTER (WHERE kind = 'device' AND value = ?)", deviceHash)).
+		Column(sq.Expr("COUNT(*) FILTER (WHERE kind = 'ip' AND value = ?)", ip)).
+		From("risk_known_clients").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return false, false, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var deviceMatches, ipMatches int
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&devices, &deviceMatches, &ipMatches); err != nil {
+		return false, false, 0, fmt.Errorf("failed to get known clients: %w", err)
+	}
+
+	return deviceMatches > 0, ipMatches > 0, devices, nil
+}
+
+// TrustClient records the device and IP address as known for the user
+func (r *RiskRepository) TrustClient(userID uuid.UUID, deviceHash, ip string) error {
+	sqlQuery, args, err := r.qb.Insert("risk_known_clients").
+		Columns("user_id", "kind", "value").
+		Values(userID, knownClientDevice, deviceHash).
+		Values(userID, knownClientIP, ip).
+		Suffix("ON CONFLICT (user_id, kind, value) DO UPDATE SET last_seen_at = CURRENT_TIMESTAMP").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to trust client: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDecision logs the decision on an operation
+func (r *RiskRepository) CreateDecision(evaluation *models.RiskEvaluation) error {
+	reasons, err := json.Marshal(evaluation.Reasons)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk reasons: %w", err)
+	}
+	features, err := json.Marshal(evaluation.Features)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk features: %w", err)
+	}
+
+	evaluation.ID = uuid.New()
+
+	sqlQuery, args, err := r.qb.Insert("risk_decisions").
+		Columns("id", "user_id", "operation", "amount", "currency", "amount_usd", "decision", "score", "reasons",
+			"features", "scorer", "scorer_error", "stepped_up", "ip", "latency_ms").
+		Values(evaluation.ID, evaluation.UserID, evaluation.Operation, evaluation.Amount, evaluation.Currency,
+			evaluation.AmountUSD, evaluation.Decision, evaluation.Score, reasons, features, evaluation.Scorer,
+			evaluation.ScorerError, evaluation.SteppedUp, evaluation.IP, evaluation.LatencyMs).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&evaluation.CreatedAt); err != nil {
+		return fmt.Errorf("failed to log risk decision: %w", err)
+	}
+
+	return nil
+}
+
+// GetDecisions retrieves logged decisions, newest first
+func (r *RiskRepository) GetDecisions(filter *models.RiskEvaluationFilter) ([]*models.RiskEvaluation, error) {
+	query := r.qb.Select(riskDecisionColumns...).
+		From("risk_decisions").
+		OrderBy("created_at DESC").
+		Limit(filter.Limit)
+
+	if filter.UserID != nil {
+		query = query.Where(sq.Eq{"user_id": *filter.UserID})
+	}
+	if filter.Operation != "" {
+		query = query.Where(sq.Eq{"operation": filter.Operation})
+	}
+	if filter.Decision != "" {
+		query = query.Where(sq.Eq{"decision": filter.Decision})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var evaluations []*models.RiskEvaluation
+	for rows.Next() {
+		var evaluation models.RiskEvaluation
+		var reasons, features []byte
+		err := rows.Scan(&evaluation.ID, &evaluation.UserID, &evaluation.Operation, &evaluation.Amount,
+			&evaluation.Currency, &evaluation.AmountUSD, &evaluation.Decision, &evaluation.Score, &reasons,
+			&features, &evaluation.Scorer, &evaluation.ScorerError, &evaluation.SteppedUp, &evaluation.IP,
+			&evaluation.LatencyMs, &evaluation.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk decision: %w", err)
+		}
+		if err := json.Unmarshal(reasons, &evaluation.Reasons); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal risk reasons: %w", err)
+		}
+		if err := json.Unmarshal(features, &evaluation.Features); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal risk features: %w", err)
+		}
+		evaluations = append(evaluations, &evaluation)
+	}
+
+	return evaluations, rows.Err()
+}
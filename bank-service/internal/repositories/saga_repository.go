@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrSagaMoved is returned when a saga is no longer at the status and step an
+// update expects, because another instance moved it on
+var ErrSagaMoved = errors.New("saga moved on")
+
+type SagaRepository struct {
+	db DBTX
+	qb sq.StatementBuilderType
+}
+
+func NewSagaRepository(db *sql.DB) *SagaRepository {
+	return &SagaRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *SagaRepository) WithTx(tx *sql.Tx) *SagaRepository {
+	return &SagaRepository{db: tx, qb: r.qb}
+}
+
+var sagaColumns = []string{
+	"id", "user_id", "exchange_type", "status", "step", "from_currency", "to_currency", "from_amount",
+	"from_account_id", "to_account_id", "from_wallet_id", "to_wallet_id", "quote_id", "exchange_rate",
+	"quote_expires_at", "exchange_id", "transaction_id", "failure_reason", "attempts", "last_error",
+	"next_attempt_at", "finished_at", "created_at", "updated_at",
+}
+
+// Create stores a new saga
+func (r *SagaRepository) Create(saga *models.ExchangeSaga) error {
+	saga.ID = uuid.New()
+
+	query := r.qb.Insert("exchange_sagas").
+		Columns("id", "user_id", "exchange_type", "status", "step", "from_currency", "to_currency",
+			"from_amount", "from_account_id", "to_account_id", "from_wallet_id", "to_wallet_id", "next_attempt_at").
+		Values(saga.ID, saga.UserID, saga.Type, saga.Status, saga.Step, saga.FromCurrency, saga.ToCurrency,
+			saga.FromAmount, saga.FromAccountID, saga.ToAccountID, saga.FromWalletID, saga.ToWalletID,
+			saga.NextAttemptAt).
+		Suffix("RETURNING created_at, updated_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&saga.CreatedAt, &saga.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a saga by ID
+func (r *SagaRepository) GetByID(id uuid.UUID) (*models.ExchangeSaga, error) {
+	return r.get(r.qb.Select(sagaColumns...).From("exchange_sagas").Where(sq.Eq{"id": id}))
+}
+
+// GetByIDForUpdate retrieves a saga and locks it until the transaction ends
+func (r *SagaRepository) GetByIDForUpdate(id uuid.UUID) (*models.ExchangeSaga, error) {
+	return r.get(r.qb.Select(sagaColumns...).From("exchange_sagas").Where(sq.Eq{"id": id}).Suffix("FOR UPDATE"))
+}
+
+func (r *SagaRepository) get(query sq.SelectBuilder) (*models.ExchangeSaga, error) {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	saga, err := scanSaga(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("saga not found")
+		}
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+
+	return saga, nil
+}
+
+// Update stores the state of a saga that is still at the given status and
+// step. It returns ErrSagaMoved if it is not, so two instances cannot both
+// run a step.
+func (r *SagaRepository) Update(saga *models.ExchangeSaga, status models.SagaStatus, step models.SagaStep) error {
+	query := r.qb.Update("exchange_sagas").
+		Set("status", saga.Status).
+		Set("step", saga.Step).
+		Set("quote_id", saga.QuoteID).
+		Set("exchange_rate", saga.ExchangeRate).
+		Set("quote_expires_at", saga.QuoteExpiresAt).
+		Set("exchange_id", saga.ExchangeID).
+		Set("transaction_id", saga.TransactionID).
+		Set("failure_reason", saga.FailureReason).
+		Set("attempts", saga.Attempts).
+		Set("last_error", saga.LastError).
+		Set("next_attempt_at", saga.NextAttemptAt).
+		Set("finished_at", saga.FinishedAt).
+		Where(sq.Eq{"id": saga.ID, "status": status, "step": step})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSagaMoved
+	}
+
+	return nil
+}
+
+// Claim picks up to limit running or compensating sagas whose claim lapsed and
+// keeps other instances off them until the new claim expires. Sagas come back
+// oldest first.
+func (r *SagaRepository) Claim(limit int, now, until time.Time) ([]*models.ExchangeSaga, error) {
+	// SKIP LOCKED lets workers of several instances claim disjoint batches
+	sqlQuery := `UPDATE exchange_sagas SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM exchange_sagas
+			WHERE status IN ('RUNNING', 'COMPENSATING') AND next_attempt_at <= $2
+			ORDER BY created_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + strings.Join(sagaColumns, ", ")
+
+	rows, err := r.db.Query(sqlQuery, until, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var sagas []*models.ExchangeSaga
+	for rows.Next() {
+		saga, err := scanSaga(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		sagas = append(sagas, saga)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim sagas: %w", err)
+	}
+
+	return sagas, nil
+}
+
+// AddStep logs a step a saga ran or undid
+func (r *SagaRepository) AddStep(sagaID uuid.UUID, record *models.SagaStepRecord) error {
+	query := r.qb.Insert("exchange_saga_steps").
+		Columns("saga_id", "step", "action", "outcome", "error").
+		Values(sagaID, record.Step, record.Action, record.Outcome, record.Error).
+		Suffix("RETURNING id, created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&record.ID, &record.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add saga step: %w", err)
+	}
+
+	return nil
+}
+
+// GetSteps retrieves the step log of a saga in order
+func (r *SagaRepository) GetSteps(sagaID uuid.UUID) ([]*models.SagaStepRecord, error) {
+	query := r.qb.Select("id", "step", "action", "outcome", "error", "created_at").
+		From("exchange_saga_steps").
+		Where(sq.Eq{"saga_id": sagaID}).
+		OrderBy("id")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga steps: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*models.SagaStepRecord, 0)
+	for rows.Next() {
+		var record models.SagaStepRecord
+		err := rows.Scan(&record.ID, &record.Step, &record.Action, &record.Outcome, &record.Error, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga step: %w", err)
+		}
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+func scanSaga(row rowScanner) (*models.ExchangeSaga, error) {
+	var saga models.ExchangeSaga
+
+	err := row.Scan(
+		&saga.ID, &saga.UserID, &saga.Type, &saga.Status, &saga.Step, &saga.FromCurrency, &saga.ToCurrency,
+		&saga.FromAmount, &saga.FromAccountID, &saga.ToAccountID, &saga.FromWalletID, &saga.ToWalletID,
+		&saga.QuoteID, &saga.ExchangeRate, &saga.QuoteExpiresAt, &saga.ExchangeID, &saga.TransactionID,
+		&saga.FailureReason, &saga.Attempts, &saga.LastError, &saga.NextAttemptAt, &saga.FinishedAt,
+		&saga.CreatedAt, &saga.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saga, nil
+}
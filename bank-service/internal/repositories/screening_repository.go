@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type ScreeningRepository struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewScreeningRepository(db *sql.DB) *ScreeningRepository {
+	return &ScreeningRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+var screeningHoldColumns = []string{
+	"id", "operation", "status", "user_id", "subject", "amount", "currency", "matches", "payload",
+	"reviewer_id", "review_note", "result_id", "failure_reason", "reviewed_at", "created_at", "updated_at",
+}
+
+// Create stores a new held operation
+func (r *ScreeningRepository) Create(hold *models.ScreeningHold) error {
+	matches, err := json.Marshal(hold.Matches)
+	if err != nil {
+		return fmt.Errorf("failed to marshal screening matches: %w", err)
+	}
+
+	hold.ID = uuid.New()
+
+	sqlQuery, args, err := r.qb.Insert("screening_holds").
+		Columns("id", "operation", "status", "user_id", "subject", "amount", "currency", "matches", "payload").
+		Values(hold.ID, hold.Operation, hold.Status, hold.UserID, hold.Subject, hold.Amount, hold.Currency,
+			matches, []byte(hold.Payload)).
+		Suffix("RETURNING created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&hold.CreatedAt, &hold.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create screening hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a held operation
+func (r *ScreeningRepository) GetByID(id uuid.UUID) (*models.ScreeningHold, error) {
+	sqlQuery, args, err := r.qb.Select(screeningHoldColumns...).
+		From("screening_holds").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	hold, err := scanScreeningHold(r.db.QueryRow(sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("screening hold not found")
+		}
+		return nil, fmt.Errorf("failed to get screening hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetByStatus retrieves held operations in a status, oldest first
+func (r *ScreeningRepository) GetByStatus(status models.ScreeningHoldStatus) ([]*models.ScreeningHold, error) {
+	sqlQuery, args, err := r.qb.Select(screeningHoldColumns...).
+		From("screening_holds").
+		Where(sq.Eq{"status": status}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screening holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []*models.ScreeningHold
+	for rows.Next() {
+		hold, err := scanScreeningHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan screening hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// Review records the decision of a compliance reviewer on a held operation. It
+// fails if the operation is no longer held so that it cannot be released twice.
+func (r *ScreeningRepository) Review(id uuid.UUID, status models.ScreeningHoldStatus, reviewerID uuid.UUID, note string) error {
+	sqlQuery, args, err := r.qb.Update("screening_holds").
+		Set("status", status).
+		Set("reviewer_id", reviewerID).
+		Set("review_note", note).
+		Set("reviewed_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "status": models.ScreeningHoldHeld}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update screening hold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("screening hold not found or already reviewed")
+	}
+
+	return nil
+}
+
+// MarkExecuted records the outcome of executing a released operation
+func (r *ScreeningRepository) MarkExecuted(id uuid.UUID, resultID *uuid.UUID, failure error) error {
+	query := r.qb.Update("screening_holds").Where(sq.Eq{"id": id})
+	if failure != nil {
+		query = query.Set("status", models.ScreeningHoldFailed).Set("failure_reason", failure.Error())
+	} else {
+		query = query.Set("result_id", resultID)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to update screening hold: %w", err)
+	}
+
+	return nil
+}
+
+func scanScreeningHold(row rowScanner) (*models.ScreeningHold, error) {
+	var hold models.ScreeningHold
+	var matches, payload []byte
+	err := row.Scan(&hold.ID, &hold.Operation, &hold.Status, &hold.UserID, &hold.Subject, &hold.Amount,
+		&hold.Currency, &matches, &payload, &hold.ReviewerID, &hold.ReviewNote, &hold.ResultID,
+		&hold.FailureReason, &hold.ReviewedAt, &hold.CreatedAt, &hold.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(matches, &hold.Matches); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal screening matches: %w", err)
+	}
+	hold.Payload = payload
+
+	return &hold, nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/crypto-bank/bank-service/internal/models"
@@ -10,7 +11,7 @@ import (
 )
 
 type TransactionRepository struct {
-	db *sql.DB
+	db DBTX
 	qb sq.StatementBuilderType
 }
 
@@ -21,15 +22,20 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	}
 }
 
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *TransactionRepository) WithTx(tx *sql.Tx) *TransactionRepository {
+	return &TransactionRepository{db: tx, qb: r.qb}
+}
+
 // Create creates a new transaction
 func (r *TransactionRepository) Create(tx *models.Transaction) error {
 	tx.ID = uuid.New()
 
 	query := r.qb.Insert("transactions").
 		Columns("id", "user_id", "type", "status", "amount", "currency",
-			"from_account_id", "to_account_id", "description", "exchange_id").
+			"from_account_id", "to_account_id", "from_wallet_id", "to_address", "description", "exchange_id").
 		Values(tx.ID, tx.UserID, tx.Type, tx.Status, tx.Amount, tx.Currency,
-			tx.FromAccountID, tx.ToAccountID, tx.Description, tx.ExchangeID).
+			tx.FromAccountID, tx.ToAccountID, tx.FromWalletID, tx.ToAddress, tx.Description, tx.ExchangeID).
 		Suffix("RETURNING created_at, updated_at")
 
 	sqlQuery, args, err := query.ToSql()
@@ -50,7 +56,8 @@ func (r *TransactionRepository) GetByID(id uuid.UUID) (*models.Transaction, erro
 	var tx models.Transaction
 
 	query := r.qb.Select("id", "user_id", "type", "status", "amount", "currency",
-		"from_account_id", "to_account_id", "description", "exchange_id", "created_at", "updated_at").
+		"from_account_id", "to_account_id", "from_wallet_id", "to_address", "description", "exchange_id",
+		"created_at", "updated_at").
 		From("transactions").
 		Where(sq.Eq{"id": id})
 
@@ -61,7 +68,7 @@ func (r *TransactionRepository) GetByID(id uuid.UUID) (*models.Transaction, erro
 
 	err = r.db.QueryRow(sqlQuery, args...).Scan(
 		&tx.ID, &tx.UserID, &tx.Type, &tx.Status, &tx.Amount, &tx.Currency,
-		&tx.FromAccountID, &tx.ToAccountID, &tx.Description, &tx.ExchangeID,
+		&tx.FromAccountID, &tx.ToAccountID, &tx.FromWalletID, &tx.ToAddress, &tx.Description, &tx.ExchangeID,
 		&tx.CreatedAt, &tx.UpdatedAt,
 	)
 	if err != nil {
@@ -77,7 +84,8 @@ func (r *TransactionRepository) GetByID(id uuid.UUID) (*models.Transaction, erro
 // GetByUserID retrieves all transactions for a user
 func (r *TransactionRepository) GetByUserID(userID uuid.UUID) ([]*models.Transaction, error) {
 	query := r.qb.Select("id", "user_id", "type", "status", "amount", "currency",
-		"from_account_id", "to_account_id", "description", "exchange_id", "created_at", "updated_at").
+		"from_account_id", "to_account_id", "from_wallet_id", "to_address", "description", "exchange_id",
+		"created_at", "updated_at").
 		From("transactions").
 		Where(sq.Eq{"user_id": userID}).
 		OrderBy("created_at DESC")
@@ -98,7 +106,7 @@ func (r *TransactionRepository) GetByUserID(userID uuid.UUID) ([]*models.Transac
 		var tx models.Transaction
 		err := rows.Scan(
 			&tx.ID, &tx.UserID, &tx.Type, &tx.Status, &tx.Amount, &tx.Currency,
-			&tx.FromAccountID, &tx.ToAccountID, &tx.Description, &tx.ExchangeID,
+			&tx.FromAccountID, &tx.ToAccountID, &tx.FromWalletID, &tx.ToAddress, &tx.Description, &tx.ExchangeID,
 			&tx.CreatedAt, &tx.UpdatedAt,
 		)
 		if err != nil {
@@ -137,3 +145,97 @@ func (r *TransactionRepository) UpdateStatus(id uuid.UUID, status models.Transac
 
 	return nil
 }
+
+// SumByTypeSince totals the amounts per currency of the transactions of a user
+// of one type created since the given time. Failed and cancelled transactions
+// moved no money and are left out.
+func (r *TransactionRepository) SumByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error) {
+	query := r.qb.Select("currency", "COALESCE(SUM(amount), 0)").
+		From("transactions").
+		Where(sq.Eq{"user_id": userID, "type": txType}).
+		Where(sq.NotEq{"status": []models.TransactionStatus{models.TransactionStatusFailed, models.TransactionStatusCancelled}}).
+		Where(sq.GtOrEq{"created_at": since}).
+		GroupBy("currency")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCurrencyTotals(rows)
+}
+
+// scanCurrencyTotals reads (currency, total) rows into a map
+func scanCurrencyTotals(rows *sql.Rows) (map[string]float64, error) {
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var currency string
+		var total float64
+		if err := rows.Scan(&currency, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan total: %w", err)
+		}
+		totals[currency] = total
+	}
+	return totals, rows.Err()
+}
+
+// CountByTypeSince counts per currency the transactions of a user of one type
+// created since the given time, leaving out failed and cancelled ones
+func (r *TransactionRepository) CountByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error) {
+	query := r.qb.Select("currency", "COUNT(*)").
+		From("transactions").
+		Where(sq.Eq{"user_id": userID, "type": txType}).
+		Where(sq.NotEq{"status": []models.TransactionStatus{models.TransactionStatusFailed, models.TransactionStatusCancelled}}).
+		Where(sq.GtOrEq{"created_at": since}).
+		GroupBy("currency")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCurrencyTotals(rows)
+}
+
+// HasPaidTo reports whether the user already completed a transfer to the
+// account or a withdrawal to the address; exactly one of them is set
+func (r *TransactionRepository) HasPaidTo(userID uuid.UUID, toAccountID *uuid.UUID, toAddress *string) (bool, error) {
+	query := r.qb.Select("1").
+		From("transactions").
+		Where(sq.Eq{"user_id": userID, "status": models.TransactionStatusCompleted}).
+		Limit(1)
+
+	if toAccountID != nil {
+		query = query.Where(sq.Eq{"type": models.TransactionTypeTransfer, "to_account_id": *toAccountID})
+	} else {
+		query = query.Where(sq.Eq{"type": models.TransactionTypeWithdraw, "to_address": *toAddress})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var found int
+	err = r.db.QueryRow(sqlQuery, args...).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check previous payments: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,453 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+type TreasuryRepository struct {
+	db *sql.DB
+	// exec runs the queries, on the pool or inside the transaction of WithTx.
+	// Methods that need several statements start their own transaction on db.
+	exec DBTX
+	qb   sq.StatementBuilderType
+}
+
+func NewTreasuryRepository(db *sql.DB) *TreasuryRepository {
+	return &TreasuryRepository{
+		db:   db,
+		exec: db,
+		qb:   sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// WithTx returns a repository that runs its queries inside the transaction
+func (r *TreasuryRepository) WithTx(tx *sql.Tx) *TreasuryRepository {
+	return &TreasuryRepository{db: r.db, exec: tx, qb: r.qb}
+}
+
+// GetWallets retrieves all house wallets
+func (r *TreasuryRepository) GetWallets() ([]*models.TreasuryWallet, error) {
+	query := r.qb.Select("id", "crypto_type", "kind", "address", "balance", "created_at", "updated_at").
+		From("treasury_wallets").
+		OrderBy("crypto_type", "kind DESC")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.exec.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury wallets: %w", err)
+	}
+	defer rows.Close()
+
+	var wallets []*models.TreasuryWallet
+	for rows.Next() {
+		var wallet models.TreasuryWallet
+		err := rows.Scan(
+			&wallet.ID, &wallet.CryptoType, &wallet.Kind, &wallet.Address, &wallet.Balance,
+			&wallet.CreatedAt, &wallet.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan treasury wallet: %w", err)
+		}
+		wallets = append(wallets, &wallet)
+	}
+
+	return wallets, nil
+}
+
+// GetWallet retrieves the house wallet of the given kind for a crypto type
+func (r *TreasuryRepository) GetWallet(cryptoType models.CryptoType, kind models.TreasuryWalletKind) (*models.TreasuryWallet, error) {
+	return r.getWallet(r.qb.Select("id", "crypto_type", "kind", "address", "balance", "created_at", "updated_at").
+		From("treasury_wallets").
+		Where(sq.Eq{"crypto_type": cryptoType, "kind": kind}))
+}
+
+// GetWalletForUpdate retrieves a house wallet and locks it until the
+// transaction ends
+func (r *TreasuryRepository) GetWalletForUpdate(cryptoType models.CryptoType, kind models.TreasuryWalletKind) (*models.TreasuryWallet, error) {
+	return r.getWallet(r.qb.Select("id", "crypto_type", "kind", "address", "balance", "created_at", "updated_at").
+		From("treasury_wallets").
+		Where(sq.Eq{"crypto_type": cryptoType, "kind": kind}).
+		Suffix("FOR UPDATE"))
+}
+
+func (r *TreasuryRepository) getWallet(query sq.SelectBuilder) (*models.TreasuryWallet, error) {
+	var wallet models.TreasuryWallet
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.exec.QueryRow(sqlQuery, args...).Scan(
+		&wallet.ID, &wallet.CryptoType, &wallet.Kind, &wallet.Address, &wallet.Balance,
+		&wallet.CreatedAt, &wallet.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("treasury wallet not found")
+		}
+		return nil, fmt.Errorf("failed to get treasury wallet: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// UpdateWalletBalance adjusts a house wallet balance by amount
+func (r *TreasuryRepository) UpdateWalletBalance(cryptoType models.CryptoType, kind models.TreasuryWalletKind, amount float64) error {
+	return r.updateWalletBalance(r.exec, cryptoType, kind, amount)
+}
+
+func (r *TreasuryRepository) updateWalletBalance(exec sq.BaseRunner, cryptoType models.CryptoType, kind models.TreasuryWalletKind, amount float64) error {
+	query := r.qb.Update("treasury_wallets").
+		Set("balance", sq.Expr("balance + ?", amount)).
+		Where(sq.Eq{"crypto_type": cryptoType, "kind": kind})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := exec.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update treasury balance: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("treasury wallet not found")
+	}
+
+	return nil
+}
+
+// GetPolicies retrieves hot wallet policies for all crypto types
+func (r *TreasuryRepository) GetPolicies() ([]*models.TreasuryPolicy, error) {
+	query := r.qb.Select("crypto_type", "target_hot_ratio", "max_hot_ratio", "min_hot_balance", "updated_at").
+		From("treasury_policies").
+		OrderBy("crypto_type")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.exec.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.TreasuryPolicy
+	for rows.Next() {
+		var policy models.TreasuryPolicy
+		err := rows.Scan(&policy.CryptoType, &policy.TargetHotRatio, &policy.MaxHotRatio,
+			&policy.MinHotBalance, &policy.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan treasury policy: %w", err)
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, nil
+}
+
+// GetPolicy retrieves the hot wallet policy of a crypto type
+func (r *TreasuryRepository) GetPolicy(cryptoType models.CryptoType) (*models.TreasuryPolicy, error) {
+	var policy models.TreasuryPolicy
+
+	query := r.qb.Select("crypto_type", "target_hot_ratio", "max_hot_ratio", "min_hot_balance", "updated_at").
+		From("treasury_policies").
+		Where(sq.Eq{"crypto_type": cryptoType})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.exec.QueryRow(sqlQuery, args...).Scan(&policy.CryptoType, &policy.TargetHotRatio,
+		&policy.MaxHotRatio, &policy.MinHotBalance, &policy.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("treasury policy not found")
+		}
+		return nil, fmt.Errorf("failed to get treasury policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// ProvisionAsset creates the house wallets and default policy of a newly registered
+// crypto asset, leaving existing ones untouched
+func (r *TreasuryRepository) ProvisionAsset(policy *models.TreasuryPolicy, hotAddress, coldAddress string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlQuery, args, err := r.qb.Insert("treasury_policies").
+		Columns("crypto_type", "target_hot_ratio", "max_hot_ratio", "min_hot_balance").
+		Values(policy.CryptoType, policy.TargetHotRatio, policy.MaxHotRatio, policy.MinHotBalance).
+		Suffix("ON CONFLICT (crypto_type) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to create treasury policy: %w", err)
+	}
+
+	sqlQuery, args, err = r.qb.Insert("treasury_wallets").
+		Columns("crypto_type", "kind", "address").
+		Values(policy.CryptoType, models.TreasuryWalletHot, hotAddress).
+		Values(policy.CryptoType, models.TreasuryWalletCold, coldAddress).
+		Suffix("ON CONFLICT (crypto_type, kind) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := tx.Exec(sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to create treasury wallets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePolicy updates the hot wallet policy of a crypto type
+func (r *TreasuryRepository) UpdatePolicy(policy *models.TreasuryPolicy) error {
+	query := r.qb.Update("treasury_policies").
+		Set("target_hot_ratio", policy.TargetHotRatio).
+		Set("max_hot_ratio", policy.MaxHotRatio).
+		Set("min_hot_balance", policy.MinHotBalance).
+		Where(sq.Eq{"crypto_type": policy.CryptoType}).
+		Suffix("RETURNING updated_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.exec.QueryRow(sqlQuery, args...).Scan(&policy.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("treasury policy not found")
+		}
+		return fmt.Errorf("failed to update treasury policy: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertOpenMovement creates a proposal, or refreshes the amount and reason of the
+// open proposal of the same type so repeated checks do not pile up duplicates
+func (r *TreasuryRepository) UpsertOpenMovement(movement *models.TreasuryMovement) error {
+	query := r.qb.Insert("treasury_movements").
+		Columns("id", "crypto_type", "type", "status", "amount", "reason").
+		Values(uuid.New(), movement.CryptoType, movement.Type, models.TreasuryMovementProposed,
+			movement.Amount, movement.Reason).
+		Suffix(`ON CONFLICT (crypto_type, type) WHERE status = 'PROPOSED'
+			DO UPDATE SET amount = EXCLUDED.amount, reason = EXCLUDED.reason
+			RETURNING id, status, created_at, updated_at`)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.exec.QueryRow(sqlQuery, args...).Scan(&movement.ID, &movement.Status, &movement.CreatedAt, &movement.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save treasury movement: %w", err)
+	}
+
+	return nil
+}
+
+// GetMovementByID retrieves a treasury movement by ID
+func (r *TreasuryRepository) GetMovementByID(id uuid.UUID) (*models.TreasuryMovement, error) {
+	var movement models.TreasuryMovement
+
+	query := r.qb.Select("id", "crypto_type", "type", "status", "amount", "reason", "executed_at",
+		"created_at", "updated_at").
+		From("treasury_movements").
+		Where(sq.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.exec.QueryRow(sqlQuery, args...).Scan(
+		&movement.ID, &movement.CryptoType, &movement.Type, &movement.Status, &movement.Amount,
+		&movement.Reason, &movement.ExecutedAt, &movement.CreatedAt, &movement.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("treasury movement not found")
+		}
+		return nil, fmt.Errorf("failed to get treasury movement: %w", err)
+	}
+
+	return &movement, nil
+}
+
+// GetMovements retrieves treasury movements, optionally filtered by status
+func (r *TreasuryRepository) GetMovements(status models.TreasuryMovementStatus) ([]*models.TreasuryMovement, error) {
+	query := r.qb.Select("id", "crypto_type", "type", "status", "amount", "reason", "executed_at",
+		"created_at", "updated_at").
+		From("treasury_movements").
+		OrderBy("created_at DESC")
+	if status != "" {
+		query = query.Where(sq.Eq{"status": status})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.exec.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []*models.TreasuryMovement
+	for rows.Next() {
+		var movement models.TreasuryMovement
+		err := rows.Scan(
+			&movement.ID, &movement.CryptoType, &movement.Type, &movement.Status, &movement.Amount,
+			&movement.Reason, &movement.ExecutedAt, &movement.CreatedAt, &movement.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan treasury movement: %w", err)
+		}
+		movements = append(movements, &movement)
+	}
+
+	return movements, nil
+}
+
+// ExecuteMovement marks a proposal executed and moves the funds between the hot and
+// cold wallet in a single database transaction
+func (r *TreasuryRepository) ExecuteMovement(movement *models.TreasuryMovement) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := r.qb.Update("treasury_movements").
+		Set("status", models.TreasuryMovementExecuted).
+		Set("executed_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": movement.ID, "status": models.TreasuryMovementProposed})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := tx.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update treasury movement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("treasury movement is not open")
+	}
+
+	from, to := models.TreasuryWalletHot, models.TreasuryWalletCold
+	if movement.Type == models.TreasuryMovementReplenish {
+		from, to = models.TreasuryWalletCold, models.TreasuryWalletHot
+	}
+
+	if err := r.updateWalletBalance(tx, movement.CryptoType, from, -movement.Amount); err != nil {
+		return err
+	}
+	if err := r.updateWalletBalance(tx, movement.CryptoType, to, movement.Amount); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelMovement cancels an open proposal
+func (r *TreasuryRepository) CancelMovement(id uuid.UUID) error {
+	query := r.qb.Update("treasury_movements").
+		Set("status", models.TreasuryMovementCancelled).
+		Where(sq.Eq{"id": id, "status": models.TreasuryMovementProposed})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.exec.Exec(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cancel treasury movement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("treasury movement is not open")
+	}
+
+	return nil
+}
+
+// GetCustomerLiabilities sums customer crypto wallet balances per crypto type
+func (r *TreasuryRepository) GetCustomerLiabilities() (map[models.CryptoType]float64, error) {
+	query := r.qb.Select("crypto_type", "COALESCE(SUM(balance), 0)").
+		From("crypto_wallets").
+		GroupBy("crypto_type")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.exec.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer liabilities: %w", err)
+	}
+	defer rows.Close()
+
+	liabilities := make(map[models.CryptoType]float64)
+	for rows.Next() {
+		var cryptoType models.CryptoType
+		var total float64
+		if err := rows.Scan(&cryptoType, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan customer liabilities: %w", err)
+		}
+		liabilities[cryptoType] = total
+	}
+
+	return liabilities, nil
+}
@@ -26,8 +26,8 @@ func (r *UserRepository) Create(user *models.User) error {
 	user.ID = uuid.New()
 
 	query := r.qb.Insert("users").
-		Columns("id", "email", "first_name", "last_name", "phone").
-		Values(user.ID, user.Email, user.FirstName, user.LastName, user.Phone).
+		Columns("id", "email", "first_name", "last_name", "phone", "role", "kyc_tier").
+		Values(user.ID, user.Email, user.FirstName, user.LastName, user.Phone, user.Role, user.KYCTier).
 		Suffix("RETURNING created_at, updated_at")
 
 	sqlQuery, args, err := query.ToSql()
@@ -47,7 +47,7 @@ func (r *UserRepository) Create(user *models.User) error {
 func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	var user models.User
 
-	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "created_at", "updated_at", "deleted_at").
+	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "role", "kyc_tier", "created_at", "updated_at", "deleted_at").
 		From("users").
 		Where(sq.Eq{"id": id, "deleted_at": nil})
 
@@ -57,7 +57,7 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	}
 
 	err = r.db.QueryRow(sqlQuery, args...).Scan(
-		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone,
+		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.Role, &user.KYCTier,
 		&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 	)
 	if err != nil {
@@ -74,7 +74,7 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
 
-	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "created_at", "updated_at", "deleted_at").
+	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "role", "kyc_tier", "created_at", "updated_at", "deleted_at").
 		From("users").
 		Where(sq.Eq{"email": email, "deleted_at": nil})
 
@@ -84,7 +84,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	}
 
 	err = r.db.QueryRow(sqlQuery, args...).Scan(
-		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone,
+		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.Role, &user.KYCTier,
 		&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 	)
 	if err != nil {
@@ -99,7 +99,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 // GetAll retrieves all users
 func (r *UserRepository) GetAll() ([]*models.User, error) {
-	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "created_at", "updated_at", "deleted_at").
+	query := r.qb.Select("id", "email", "first_name", "last_name", "phone", "role", "kyc_tier", "created_at", "updated_at", "deleted_at").
 		From("users").
 		Where(sq.Eq{"deleted_at": nil}).
 		OrderBy("created_at DESC")
@@ -119,7 +119,7 @@ func (r *UserRepository) GetAll() ([]*models.User, error) {
 	for rows.Next() {
 		var user models.User
 		err := rows.Scan(
-			&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone,
+			&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.Role, &user.KYCTier,
 			&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 		)
 		if err != nil {
@@ -131,8 +131,8 @@ func (r *UserRepository) GetAll() ([]*models.User, error) {
 	return users, nil
 }
 
-// Update updates a user
-func (r *UserRepository) Update(id uuid.UUID, req *models.UpdateUserRequest) error {
+// Update updates a user and stores the event announcing it in one transaction
+func (r *UserRepository) Update(id uuid.UUID, req *models.UpdateUserRequest, event *models.OutboxMessage) error {
 	updateMap := make(map[string]interface{})
 
 	if req.FirstName != "" {
@@ -158,25 +158,35 @@ func (r *UserRepository) Update(id uuid.UUID, req *models.UpdateUserRequest) err
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
+	if err := r.execWithEvent(sqlQuery, args, event); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return nil
+}
+
+// UpdateRole changes the role of a user and stores the event announcing it in
+// one transaction
+func (r *UserRepository) UpdateRole(id uuid.UUID, role models.Role, event *models.OutboxMessage) error {
+	query := r.qb.Update("users").
+		Set("role", role).
+		Where(sq.Eq{"id": id, "deleted_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to build query: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	if err := r.execWithEvent(sqlQuery, args, event); err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
 	}
 
 	return nil
 }
 
-// Delete soft deletes a user
-func (r *UserRepository) Delete(id uuid.UUID) error {
+// Delete soft deletes a user and stores the event announcing it in one
+// transaction
+func (r *UserRepository) Delete(id uuid.UUID, event *models.OutboxMessage) error {
 	query := r.qb.Update("users").
 		Set("deleted_at", sq.Expr("NOW()")).
 		Where(sq.Eq{"id": id, "deleted_at": nil})
@@ -186,11 +196,27 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
+	if err := r.execWithEvent(sqlQuery, args, event); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	return nil
+}
+
+// execWithEvent runs a statement that changes one user and stores the event
+// announcing the change in the same transaction
+func (r *UserRepository) execWithEvent(sqlQuery string, args []interface{}, event *models.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
@@ -200,5 +226,9 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("user not found")
 	}
 
-	return nil
+	if err := insertOutboxMessage(tx, r.qb, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
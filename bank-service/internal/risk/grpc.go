@@ -0,0 +1,89 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	pb "github.com/crypto-bank/bank-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// GRPCScorer asks an external fraud model implementing the RiskScorer service
+// of proto/risk.proto
+type GRPCScorer struct {
+	conn   *grpc.ClientConn
+	client pb.RiskScorerClient
+}
+
+// NewGRPCScorer connects lazily to the scorer at addr; the connection is set up
+// on the first call
+func NewGRPCScorer(addr string) (*GRPCScorer, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create risk scorer client: %w", err)
+	}
+	return &GRPCScorer{
+		conn:   conn,
+		client: pb.NewRiskScorerClient(conn),
+	}, nil
+}
+
+func (s *GRPCScorer) Name() string {
+	return "grpc"
+}
+
+func (s *GRPCScorer) Score(ctx context.Context, req *Request) (*models.RiskAssessment, error) {
+	f := req.Features
+	resp, err := s.client.Score(ctx, &pb.ScoreRequest{
+		Operation: string(req.Operation),
+		UserId:    req.UserID.String(),
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		AmountUsd: req.AmountUSD,
+		Features: &pb.Features{
+			AccountAgeHours:    f.AccountAgeHours,
+			OperationsLastHour: int32(f.OperationsLastHour),
+			OperationsLastDay:  int32(f.OperationsLastDay),
+			VolumeLastDayUsd:   f.VolumeLastDayUSD,
+			NewDevice:          f.NewDevice,
+			NewIp:              f.NewIP,
+			KnownDevices:       int32(f.KnownDevices),
+			HasBeneficiary:     f.HasBeneficiary,
+			NewBeneficiary:     f.NewBeneficiary,
+			TwoFactorEnabled:   f.TwoFactorEnabled,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("risk scorer call failed: %w", err)
+	}
+
+	var decision models.RiskDecision
+	switch resp.GetDecision() {
+	case pb.Decision_DECISION_ALLOW:
+		decision = models.RiskAllow
+	case pb.Decision_DECISION_CHALLENGE:
+		decision = models.RiskChallenge
+	case pb.Decision_DECISION_DENY:
+		decision = models.RiskDeny
+	default:
+		return nil, fmt.Errorf("risk scorer returned no decision")
+	}
+
+	reasons := resp.GetReasons()
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	return &models.RiskAssessment{
+		Decision: decision,
+		Score:    resp.GetScore(),
+		Reasons:  reasons,
+	}, nil
+}
+
+// Close closes the connection to the scorer
+func (s *GRPCScorer) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,115 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	pb "github.com/crypto-bank/bank-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+// fakeModel answers Score calls with a fixed response or error
+type fakeModel struct {
+	resp *pb.ScoreResponse
+	err  error
+	got  *pb.ScoreRequest
+}
+
+func (m *fakeModel) Score(_ context.Context, in *pb.ScoreRequest, _ ...grpc.CallOption) (*pb.ScoreResponse, error) {
+	m.got = in
+	return m.resp, m.err
+}
+
+func TestGRPCScorer(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *pb.ScoreResponse
+		err     error
+		want    *models.RiskAssessment
+		wantErr bool
+	}{
+		{
+			name: "allow",
+			resp: &pb.ScoreResponse{Decision: pb.Decision_DECISION_ALLOW, Score: 12, Reasons: []string{"known_device"}},
+			want: &models.RiskAssessment{Decision: models.RiskAllow, Score: 12, Reasons: []string{"known_device"}},
+		},
+		{
+			name: "challenge",
+			resp: &pb.ScoreResponse{Decision: pb.Decision_DECISION_CHALLENGE, Score: 55, Reasons: []string{"new_ip"}},
+			want: &models.RiskAssessment{Decision: models.RiskChallenge, Score: 55, Reasons: []string{"new_ip"}},
+		},
+		{
+			name: "deny",
+			resp: &pb.ScoreResponse{Decision: pb.Decision_DECISION_DENY, Score: 97, Reasons: []string{"mule_pattern"}},
+			want: &models.RiskAssessment{Decision: models.RiskDeny, Score: 97, Reasons: []string{"mule_pattern"}},
+		},
+		{
+			name: "no reasons",
+			resp: &pb.ScoreResponse{Decision: pb.Decision_DECISION_ALLOW},
+			want: &models.RiskAssessment{Decision: models.RiskAllow, Reasons: []string{}},
+		},
+		{
+			name:    "no decision",
+			resp:    &pb.ScoreResponse{Score: 10},
+			wantErr: true,
+		},
+		{
+			name:    "model unavailable",
+			err:     errors.New("connection refused"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeModel{resp: tt.resp, err: tt.err}
+			scorer := &GRPCScorer{client: model}
+
+			features := established
+			features.NewIP = true
+			req := &Request{
+				Operation: models.RiskOpTransfer,
+				UserID:    uuid.New(),
+				Amount:    250,
+				Currency:  "EUR",
+				AmountUSD: 272.5,
+				Features:  features,
+			}
+
+			got, err := scorer.Score(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Score error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Score = %+v, want %+v", got, tt.want)
+			}
+
+			sent := model.got
+			if sent.GetOperation() != "transfer" || sent.GetUserId() != req.UserID.String() ||
+				sent.GetAmountUsd() != 272.5 || !sent.GetFeatures().GetNewIp() || sent.GetFeatures().GetKnownDevices() != 2 {
+				t.Errorf("request sent = %v, want the operation and its features", sent)
+			}
+		})
+	}
+}
+
+func TestGRPCScorerUnreachable(t *testing.T) {
+	// Nothing listens on port 1, so the call fails instead of deciding
+	scorer, err := NewGRPCScorer("127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scorer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := scorer.Score(ctx, &Request{Operation: models.RiskOpTransfer, UserID: uuid.New()}); err == nil {
+		t.Error("Score succeeded without a model to call")
+	}
+}
@@ -0,0 +1,96 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+// RuleScorer is the built-in scorer. It adds up fixed weights for the risk
+// signals of an operation and compares the total with the challenge and deny
+// scores.
+type RuleScorer struct {
+	challengeScore float64
+	denyScore      float64
+}
+
+func NewRuleScorer(challengeScore, denyScore float64) (*RuleScorer, error) {
+	if challengeScore <= 0 || denyScore <= challengeScore || denyScore > 100 {
+		return nil, fmt.Errorf("risk scores must satisfy 0 < challenge < deny <= 100")
+	}
+	return &RuleScorer{
+		challengeScore: challengeScore,
+		denyScore:      denyScore,
+	}, nil
+}
+
+func (s *RuleScorer) Name() string {
+	return "rules"
+}
+
+func (s *RuleScorer) Score(_ context.Context, req *Request) (*models.RiskAssessment, error) {
+	f := req.Features
+	var score float64
+	reasons := []string{}
+	add := func(weight float64, reason string) {
+		score += weight
+		reasons = append(reasons, reason)
+	}
+
+	switch {
+	case f.AccountAgeHours < 24:
+		add(25, "new_account")
+	case f.AccountAgeHours < 7*24:
+		add(10, "young_account")
+	}
+
+	// Switching to an unknown device is more telling than the first device ever
+	switch {
+	case f.NewDevice && f.KnownDevices > 0:
+		add(20, "new_device")
+	case f.NewDevice:
+		add(10, "first_device")
+	}
+	if f.NewIP {
+		add(10, "new_ip")
+	}
+
+	if f.NewBeneficiary {
+		add(20, "new_beneficiary")
+		if req.Operation == models.RiskOpCryptoWithdraw {
+			add(10, "irreversible_payout")
+		}
+	}
+
+	switch {
+	case f.OperationsLastHour >= 10:
+		add(35, "high_velocity")
+	case f.OperationsLastHour >= 5:
+		add(20, "elevated_velocity")
+	}
+
+	switch volume := f.VolumeLastDayUSD + req.AmountUSD; {
+	case volume >= 50000:
+		add(20, "high_daily_volume")
+	case volume >= 10000:
+		add(10, "elevated_daily_volume")
+	}
+
+	score = math.Min(score, 100)
+
+	decision := models.RiskAllow
+	switch {
+	case score >= s.denyScore:
+		decision = models.RiskDeny
+	case score >= s.challengeScore:
+		decision = models.RiskChallenge
+	}
+
+	return &models.RiskAssessment{
+		Decision: decision,
+		Score:    score,
+		Reasons:  reasons,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package risk
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+// established has none of the risk signals
+var established = models.RiskFeatures{
+	AccountAgeHours: 1000,
+	KnownDevices:    2,
+}
+
+func TestNewRuleScorer(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge float64
+		deny      float64
+		wantErr   bool
+	}{
+		{"defaults", 40, 80, false},
+		{"deny at the maximum", 40, 100, false},
+		{"no challenge score", 0, 80, true},
+		{"deny equal to challenge", 40, 40, true},
+		{"deny below challenge", 80, 40, true},
+		{"deny above the maximum", 40, 101, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleScorer(tt.challenge, tt.deny)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRuleScorer(%v, %v) error = %v, want error %v", tt.challenge, tt.deny, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleScorer(t *testing.T) {
+	scorer, err := NewRuleScorer(40, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		operation models.RiskOperation
+		amountUSD float64
+		features  func(f *models.RiskFeatures)
+		score     float64
+		decision  models.RiskDecision
+		reasons   []string
+	}{
+		{
+			name:     "no signals",
+			decision: models.RiskAllow,
+			reasons:  []string{},
+		},
+		{
+			name:     "new account",
+			features: func(f *models.RiskFeatures) { f.AccountAgeHours = 2 },
+			score:    25,
+			decision: models.RiskAllow,
+			reasons:  []string{"new_account"},
+		},
+		{
+			name:     "young account",
+			features: func(f *models.RiskFeatures) { f.AccountAgeHours = 100 },
+			score:    10,
+			decision: models.RiskAllow,
+			reasons:  []string{"young_account"},
+		},
+		{
+			name:     "account a week old",
+			features: func(f *models.RiskFeatures) { f.AccountAgeHours = 7 * 24 },
+			decision: models.RiskAllow,
+			reasons:  []string{},
+		},
+		{
+			name:     "switch to a new device",
+			features: func(f *models.RiskFeatures) { f.NewDevice = true },
+			score:    20,
+			decision: models.RiskAllow,
+			reasons:  []string{"new_device"},
+		},
+		{
+			name: "first device",
+			features: func(f *models.RiskFeatures) {
+				f.NewDevice = true
+				f.KnownDevices = 0
+			},
+			score:    10,
+			decision: models.RiskAllow,
+			reasons:  []string{"first_device"},
+		},
+		{
+			name:     "new IP address",
+			features: func(f *models.RiskFeatures) { f.NewIP = true },
+			score:    10,
+			decision: models.RiskAllow,
+			reasons:  []string{"new_ip"},
+		},
+		{
+			name:      "transfer to a new beneficiary",
+			operation: models.RiskOpTransfer,
+			features:  func(f *models.RiskFeatures) { f.NewBeneficiary = true },
+			score:     20,
+			decision:  models.RiskAllow,
+			reasons:   []string{"new_beneficiary"},
+		},
+		{
+			name:      "crypto payout to a new address",
+			operation: models.RiskOpCryptoWithdraw,
+			features:  func(f *models.RiskFeatures) { f.NewBeneficiary = true },
+			score:     30,
+			decision:  models.RiskAllow,
+			reasons:   []string{"new_beneficiary", "irreversible_payout"},
+		},
+		{
+			name:     "elevated velocity",
+			features: func(f *models.RiskFeatures) { f.OperationsLastHour = 5 },
+			score:    20,
+			decision: models.RiskAllow,
+			reasons:  []string{"elevated_velocity"},
+		},
+		{
+			name:     "high velocity",
+			features: func(f *models.RiskFeatures) { f.OperationsLastHour = 10 },
+			score:    35,
+			decision: models.RiskAllow,
+			reasons:  []string{"high_velocity"},
+		},
+		{
+			name:      "daily volume reaching the elevated band with the amount",
+			amountUSD: 1000,
+			features:  func(f *models.RiskFeatures) { f.VolumeLastDayUSD = 9000 },
+			score:     10,
+			decision:  models.RiskAllow,
+			reasons:   []string{"elevated_daily_volume"},
+		},
+		{
+			name:      "daily volume just under the elevated band",
+			amountUSD: 999.99,
+			features:  func(f *models.RiskFeatures) { f.VolumeLastDayUSD = 9000 },
+			decision:  models.RiskAllow,
+			reasons:   []string{},
+		},
+		{
+			name:      "high daily volume",
+			amountUSD: 50000,
+			score:     20,
+			decision:  models.RiskAllow,
+			reasons:   []string{"high_daily_volume"},
+		},
+		{
+			name:      "challenge at the challenge score",
+			operation: models.RiskOpTransfer,
+			features: func(f *models.RiskFeatures) {
+				f.NewDevice = true
+				f.NewBeneficiary = true
+			},
+			score:    40,
+			decision: models.RiskChallenge,
+			reasons:  []string{"new_device", "new_beneficiary"},
+		},
+		{
+			name:      "challenge just under the deny score",
+			operation: models.RiskOpTransfer,
+			features: func(f *models.RiskFeatures) {
+				f.AccountAgeHours = 2
+				f.NewDevice = true
+				f.NewIP = true
+				f.NewBeneficiary = true
+			},
+			score:    75,
+			decision: models.RiskChallenge,
+			reasons:  []string{"new_account", "new_device", "new_ip", "new_beneficiary"},
+		},
+		{
+			name:      "deny at the deny score",
+			operation: models.RiskOpTransfer,
+			features: func(f *models.RiskFeatures) {
+				f.AccountAgeHours = 2
+				f.NewBeneficiary = true
+				f.OperationsLastHour = 10
+			},
+			score:    80,
+			decision: models.RiskDeny,
+			reasons:  []string{"new_account", "new_beneficiary", "high_velocity"},
+		},
+		{
+			name:      "score capped at 100",
+			operation: models.RiskOpCryptoWithdraw,
+			amountUSD: 50000,
+			features: func(f *models.RiskFeatures) {
+				f.AccountAgeHours = 2
+				f.NewDevice = true
+				f.NewIP = true
+				f.NewBeneficiary = true
+				f.OperationsLastHour = 10
+			},
+			score:    100,
+			decision: models.RiskDeny,
+			reasons: []string{
+				"new_account", "new_device", "new_ip", "new_beneficiary",
+				"irreversible_payout", "high_velocity", "high_daily_volume",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{
+				Operation: tt.operation,
+				AmountUSD: tt.amountUSD,
+				Features:  established,
+			}
+			if req.Operation == "" {
+				req.Operation = models.RiskOpWithdraw
+			}
+			if tt.features != nil {
+				tt.features(&req.Features)
+			}
+
+			got, err := scorer.Score(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Score != tt.score || got.Decision != tt.decision {
+				t.Errorf("assessment = %v %s, want %v %s", got.Score, got.Decision, tt.score, tt.decision)
+			}
+			if !reflect.DeepEqual(got.Reasons, tt.reasons) {
+				t.Errorf("reasons = %v, want %v", got.Reasons, tt.reasons)
+			}
+		})
+	}
+}
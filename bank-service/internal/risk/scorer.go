@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"context"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// Request carries an operation about to run and the features of its user.
+// AmountUSD is zero when the currency has no reference rate.
+type Request struct {
+	Operation models.RiskOperation
+	UserID    uuid.UUID
+	Amount    float64
+	Currency  string
+	AmountUSD float64
+	Features  models.RiskFeatures
+}
+
+// Scorer decides whether a money movement may run. Errors mean no decision
+// could be made; the caller then applies its fail mode.
+type Scorer interface {
+	// Name identifies the scorer in the decision log
+	Name() string
+	Score(ctx context.Context, req *Request) (*models.RiskAssessment, error)
+}
@@ -0,0 +1,41 @@
+package screening
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const sourceBlockedAddresses = "blocked addresses"
+
+// loadBlockedAddresses reads currency,address,reason rows; a header row is
+// optional
+func loadBlockedAddresses(list *Watchlist, path string) error {
+	line := 0
+	return readCSV(path, func(record []string) {
+		line++
+		if len(record) < 2 {
+			return
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "currency") {
+			return
+		}
+
+		address := &BlockedAddress{
+			Source:   sourceBlockedAddresses,
+			EntryID:  filepath.Base(path) + ":" + strconv.Itoa(line),
+			Currency: strings.ToUpper(strings.TrimSpace(record[0])),
+			Address:  strings.TrimSpace(record[1]),
+		}
+		if len(record) > 2 {
+			address.Name = strings.TrimSpace(record[2])
+		}
+		list.addAddress(address)
+	})
+}
+
+// normalizeAddress makes lookups case insensitive; hex addresses are commonly
+// written in mixed case
+func normalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
@@ -0,0 +1,158 @@
+package screening
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// foldRunes maps accented Latin letters to their ASCII spelling so that
+// "Müller" and "Muller" compare equal
+var foldRunes = func() map[rune]string {
+	groups := map[string]string{
+		"a":  "àáâãäåāăą",
+		"c":  "çćĉċč",
+		"d":  "ďđ",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"t":  "ţťŧț",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+		"ae": "æ",
+		"oe": "œ",
+		"ss": "ß",
+	}
+
+	fold := make(map[rune]string)
+	for ascii, accented := range groups {
+		for _, r := range accented {
+			fold[r] = ascii
+		}
+	}
+	return fold
+}()
+
+// normalize lowercases a name, folds accents, drops punctuation and returns
+// its tokens sorted so that word order does not matter
+func normalize(name string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'' || r == '’':
+			// O'Brien and OBrien are the same name
+		case foldRunes[r] != "":
+			b.WriteString(foldRunes[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	tokens := strings.Fields(b.String())
+	sort.Strings(tokens)
+	return tokens
+}
+
+// similarity scores two normalized names between 0 and 1. It takes the better
+// of comparing the names as a whole and aligning every token of the shorter
+// name with its closest token in the longer one. Aligned scores are scaled
+// down when the names differ in length, gently enough that a missing middle
+// name still matches but a single shared word does not.
+func similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	whole := jaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+
+	var sum float64
+	for _, token := range short {
+		var best float64
+		for _, candidate := range long {
+			best = math.Max(best, jaroWinkler(token, candidate))
+		}
+		sum += best
+	}
+	aligned := sum / float64(len(short)) * math.Pow(float64(len(short))/float64(len(long)), 0.25)
+
+	return math.Max(whole, aligned)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		lo := max(0, i-window)
+		hi := min(len(s2), i+window+1)
+		for j := lo; j < hi; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
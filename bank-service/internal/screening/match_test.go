@@ -0,0 +1,100 @@
+package screening
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"lowercases and sorts tokens", "Vladimir PUTIN", []string{"putin", "vladimir"}},
+		{"folds accents", "Hans Müller", []string{"hans", "muller"}},
+		{"folds ligatures and sharp s", "Æsop Strauß", []string{"aesop", "strauss"}},
+		{"drops apostrophes", "Seán O'Brien", []string{"obrien", "sean"}},
+		{"splits on punctuation", "AL-QAIDA, (a.k.a.)", []string{"a", "a", "al", "k", "qaida"}},
+		{"keeps digits", "Unit 731", []string{"731", "unit"}},
+		{"blank name has no tokens", " -- ", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalize(tt.in)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"same", "same", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+		{"müller", "muller", 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got := jaroWinkler(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("jaroWinkler(%q, %q) = %.4f, want %.3f", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := jaroWinkler(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("jaroWinkler is not symmetric: %.4f and %.4f", got, reverse)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	const threshold = 0.9
+
+	tests := []struct {
+		name  string
+		a, b  string
+		match bool
+	}{
+		{"identical", "Vladimir Putin", "Vladimir Putin", true},
+		{"word order", "Putin Vladimir", "Vladimir Putin", true},
+		{"accents", "Müller Hans", "Hans Muller", true},
+		{"missing middle name", "Vladimir Vladimirovich Putin", "Vladimir Putin", true},
+		{"transliteration", "Osama bin Laden", "Usama bin Ladin", true},
+		{"misspelling", "John Smith", "Jon Smyth", true},
+		{"single shared word", "John Smith", "Vladimir Smith", false},
+		{"different last token", "Kim Jong Un", "Kim Jong Il", false},
+		{"unrelated", "Maria Garcia", "Osama bin Laden", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := similarity(normalize(tt.a), normalize(tt.b))
+			if score < 0 || score > 1 {
+				t.Fatalf("similarity(%q, %q) = %.4f, out of [0, 1]", tt.a, tt.b, score)
+			}
+			if got := score >= threshold; got != tt.match {
+				t.Errorf("similarity(%q, %q) = %.4f, match = %v, want %v", tt.a, tt.b, score, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestSimilarityEmpty(t *testing.T) {
+	if got := similarity(nil, normalize("Vladimir Putin")); got != 0 {
+		t.Errorf("similarity with an empty name = %.4f, want 0", got)
+	}
+}
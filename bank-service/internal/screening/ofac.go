@@ -0,0 +1,127 @@
+package screening
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const sourceOFAC = "OFAC SDN"
+
+// OFAC publishes digital currency addresses in the remarks of an entry, e.g.
+// "Digital Currency Address - XBT 12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h;"
+var ofacAddressPattern = regexp.MustCompile(`Digital Currency Address - ([A-Za-z0-9]+) ([A-Za-z0-9]+)`)
+
+// loadOFACList reads the SDN file and, if present, the alias file. Both are
+// headerless CSV files where "-0-" marks an empty field.
+func loadOFACList(list *Watchlist, sdnPath, altPath string) error {
+	entries := make(map[string]*Entry)
+	var order []*Entry
+
+	err := readCSV(sdnPath, func(record []string) {
+		if len(record) < 4 {
+			return
+		}
+		entry := &Entry{
+			Source:   sourceOFAC,
+			ID:       ofacValue(record[0]),
+			Name:     ofacValue(record[1]),
+			Type:     ofacType(ofacValue(record[2])),
+			Programs: ofacPrograms(ofacValue(record[3])),
+		}
+		if entry.ID == "" || entry.Name == "" {
+			return
+		}
+		entries[entry.ID] = entry
+		order = append(order, entry)
+
+		if len(record) < 12 {
+			return
+		}
+		for _, match := range ofacAddressPattern.FindAllStringSubmatch(record[11], -1) {
+			list.addAddress(&BlockedAddress{
+				Source:   sourceOFAC,
+				EntryID:  entry.ID,
+				Name:     entry.Name,
+				Currency: match[1],
+				Address:  match[2],
+			})
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("sdn.csv: %w", err)
+	}
+
+	if altPath != "" {
+		err := readCSV(altPath, func(record []string) {
+			if len(record) < 4 {
+				return
+			}
+			if entry, ok := entries[ofacValue(record[0])]; ok {
+				if alias := ofacValue(record[3]); alias != "" {
+					entry.Aliases = append(entry.Aliases, alias)
+				}
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("alt.csv: %w", err)
+		}
+	}
+
+	for _, entry := range order {
+		list.addEntry(entry)
+	}
+	return nil
+}
+
+func readCSV(path string, handle func(record []string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		handle(record)
+	}
+}
+
+func ofacValue(field string) string {
+	field = strings.TrimSpace(field)
+	if field == "-0-" {
+		return ""
+	}
+	return field
+}
+
+// ofacType maps the SDN type column; entities have an empty type
+func ofacType(sdnType string) string {
+	if sdnType == "" {
+		return "entity"
+	}
+	return strings.ToLower(sdnType)
+}
+
+// ofacPrograms splits program tags such as "SDGT] [IRGC"
+func ofacPrograms(field string) []string {
+	var programs []string
+	for _, program := range strings.Split(field, "] [") {
+		if program = strings.Trim(program, "[] "); program != "" {
+			programs = append(programs, program)
+		}
+	}
+	return programs
+}
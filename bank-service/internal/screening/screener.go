@@ -0,0 +1,152 @@
+package screening
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+)
+
+// ErrNotLoaded is returned by screening calls before any watchlist has loaded.
+// Nothing can be screened then, so nothing may pass.
+var ErrNotLoaded = errors.New("watchlists not loaded")
+
+// Screener matches names and crypto addresses against the loaded watchlists.
+// Lists are swapped atomically on reload so screening never sees a partial load.
+type Screener struct {
+	dir       string
+	threshold float64
+
+	mu   sync.RWMutex
+	list *Watchlist
+}
+
+// NewScreener creates a screener over the lists in dir. Names scoring at least
+// threshold, between 0 and 1, are reported as hits. Nothing is loaded until
+// Reload is called.
+func NewScreener(dir string, threshold float64) (*Screener, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("screening threshold must be in (0, 1], got %f", threshold)
+	}
+	return &Screener{
+		dir:       dir,
+		threshold: threshold,
+	}, nil
+}
+
+// Reload reads the lists again; on error the previous lists stay in use
+func (s *Screener) Reload() error {
+	list, err := LoadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.list = list
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Screener) current() *Watchlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list
+}
+
+// ScreenName returns the entries a name matches, best first, with at most one
+// match per entry
+func (s *Screener) ScreenName(name string) ([]models.ScreeningMatch, error) {
+	list := s.current()
+	if list == nil {
+		return nil, ErrNotLoaded
+	}
+
+	tokens := normalize(name)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var matches []models.ScreeningMatch
+	for _, entry := range list.entries {
+		best, bestIndex := 0.0, 0
+		for i, candidate := range entry.names {
+			if score := similarity(tokens, candidate); score > best {
+				best, bestIndex = score, i
+			}
+		}
+		if best < s.threshold {
+			continue
+		}
+
+		matched := entry.Name
+		if bestIndex > 0 && bestIndex <= len(entry.Aliases) {
+			matched = entry.Aliases[bestIndex-1]
+		}
+		matches = append(matches, models.ScreeningMatch{
+			Source:      entry.Source,
+			EntryID:     entry.ID,
+			ListedName:  entry.Name,
+			MatchedName: matched,
+			Type:        entry.Type,
+			Programs:    entry.Programs,
+			Score:       math.Round(best*1000) / 1000,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+// ScreenAddress returns the listing of a blocked crypto address, or nil
+func (s *Screener) ScreenAddress(address string) (*models.ScreeningMatch, error) {
+	list := s.current()
+	if list == nil {
+		return nil, ErrNotLoaded
+	}
+
+	blocked, ok := list.addresses[normalizeAddress(address)]
+	if !ok {
+		return nil, nil
+	}
+
+	return &models.ScreeningMatch{
+		Source:     blocked.Source,
+		EntryID:    blocked.EntryID,
+		ListedName: blocked.Name,
+		Address:    blocked.Address,
+		Type:       "address",
+		Score:      1,
+	}, nil
+}
+
+// Status describes the loaded lists
+func (s *Screener) Status() *models.WatchlistStatus {
+	status := &models.WatchlistStatus{
+		Sources:   []models.WatchlistSource{},
+		Threshold: s.threshold,
+	}
+
+	list := s.current()
+	if list == nil {
+		return status
+	}
+
+	loadedAt := list.loadedAt
+	status.LoadedAt = &loadedAt
+	for name, stats := range list.sources {
+		status.Sources = append(status.Sources, models.WatchlistSource{
+			Name:      name,
+			Entries:   stats.Entries,
+			Addresses: stats.Addresses,
+		})
+	}
+	sort.Slice(status.Sources, func(i, j int) bool {
+		return status.Sources[i].Name < status.Sources[j].Name
+	})
+	return status
+}
@@ -0,0 +1,99 @@
+package screening
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func newTestScreener(t *testing.T, threshold float64) *Screener {
+	t.Helper()
+
+	screener, err := NewScreener(t.TempDir(), threshold)
+	if err != nil {
+		t.Fatalf("NewScreener: %v", err)
+	}
+
+	list := newWatchlist()
+	list.addEntry(&Entry{Source: "test", ID: "1", Name: "Vladimir Vladimirovich Putin", Aliases: []string{"Putin V.V."}})
+	list.addAddress(&BlockedAddress{Source: "test", EntryID: "2", Currency: "ETH", Address: "0xAbC0000000000000000000000000000000000001"})
+	screener.list = list
+	return screener
+}
+
+func TestScreenerNotLoaded(t *testing.T) {
+	screener, err := NewScreener(t.TempDir(), 0.9)
+	if err != nil {
+		t.Fatalf("NewScreener: %v", err)
+	}
+
+	if _, err := screener.ScreenName("Vladimir Putin"); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("ScreenName before load: err = %v, want ErrNotLoaded", err)
+	}
+	if _, err := screener.ScreenAddress("0xabc"); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("ScreenAddress before load: err = %v, want ErrNotLoaded", err)
+	}
+
+	if err := screener.Reload(); err != nil {
+		t.Fatalf("Reload of an empty directory: %v", err)
+	}
+	if matches, err := screener.ScreenName("Vladimir Putin"); err != nil || len(matches) != 0 {
+		t.Errorf("ScreenName on empty lists = %v, %v, want no matches", matches, err)
+	}
+}
+
+func TestScreenerThreshold(t *testing.T) {
+	const name = "Vladimir Putin"
+	score := similarity(normalize(name), normalize("Vladimir Vladimirovich Putin"))
+
+	tests := []struct {
+		name      string
+		threshold float64
+		match     bool
+	}{
+		{"score equals threshold", score, true},
+		{"score just below threshold", math.Nextafter(score, 2), false},
+		{"loose threshold", 0.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := newTestScreener(t, tt.threshold).ScreenName(name)
+			if err != nil {
+				t.Fatalf("ScreenName: %v", err)
+			}
+			if got := len(matches) > 0; got != tt.match {
+				t.Fatalf("ScreenName(%q) at threshold %.6f matched = %v, want %v", name, tt.threshold, got, tt.match)
+			}
+			if tt.match && matches[0].EntryID != "1" {
+				t.Errorf("matched entry %s, want 1", matches[0].EntryID)
+			}
+		})
+	}
+}
+
+func TestScreenerAddress(t *testing.T) {
+	screener := newTestScreener(t, 0.9)
+
+	tests := []struct {
+		name    string
+		address string
+		blocked bool
+	}{
+		{"listed", "0xAbC0000000000000000000000000000000000001", true},
+		{"case insensitive", "0xabc0000000000000000000000000000000000001", true},
+		{"not listed", "0xabc0000000000000000000000000000000000002", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := screener.ScreenAddress(tt.address)
+			if err != nil {
+				t.Fatalf("ScreenAddress: %v", err)
+			}
+			if got := match != nil; got != tt.blocked {
+				t.Errorf("ScreenAddress(%q) blocked = %v, want %v", tt.address, got, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package screening
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const sourceUN = "UN"
+
+type unList struct {
+	XMLName     xml.Name       `xml:"CONSOLIDATED_LIST"`
+	Individuals []unIndividual `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []unEntity     `xml:"ENTITIES>ENTITY"`
+}
+
+type unIndividual struct {
+	DataID     string    `xml:"DATAID"`
+	FirstName  string    `xml:"FIRST_NAME"`
+	SecondName string    `xml:"SECOND_NAME"`
+	ThirdName  string    `xml:"THIRD_NAME"`
+	FourthName string    `xml:"FOURTH_NAME"`
+	ListType   string    `xml:"UN_LIST_TYPE"`
+	Aliases    []unAlias `xml:"INDIVIDUAL_ALIAS"`
+}
+
+type unEntity struct {
+	DataID   string    `xml:"DATAID"`
+	Name     string    `xml:"FIRST_NAME"`
+	ListType string    `xml:"UN_LIST_TYPE"`
+	Aliases  []unAlias `xml:"ENTITY_ALIAS"`
+}
+
+type unAlias struct {
+	Quality string `xml:"QUALITY"`
+	Name    string `xml:"ALIAS_NAME"`
+}
+
+// loadUNList reads the UN Security Council consolidated list
+func loadUNList(list *Watchlist, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var consolidated unList
+	if err := xml.Unmarshal(content, &consolidated); err != nil {
+		return fmt.Errorf("failed to parse UN list: %w", err)
+	}
+
+	for _, individual := range consolidated.Individuals {
+		name := strings.Join(strings.Fields(strings.Join([]string{
+			individual.FirstName, individual.SecondName, individual.ThirdName, individual.FourthName,
+		}, " ")), " ")
+		list.addEntry(&Entry{
+			Source:   sourceUN,
+			ID:       individual.DataID,
+			Name:     name,
+			Aliases:  unAliases(individual.Aliases),
+			Type:     "individual",
+			Programs: []string{individual.ListType},
+		})
+	}
+
+	for _, entity := range consolidated.Entities {
+		list.addEntry(&Entry{
+			Source:   sourceUN,
+			ID:       entity.DataID,
+			Name:     strings.TrimSpace(entity.Name),
+			Aliases:  unAliases(entity.Aliases),
+			Type:     "entity",
+			Programs: []string{entity.ListType},
+		})
+	}
+
+	return nil
+}
+
+// unAliases drops aliases the UN marks as low quality, which are too vague to
+// screen against
+func unAliases(aliases []unAlias) []string {
+	var names []string
+	for _, alias := range aliases {
+		name := strings.TrimSpace(alias.Name)
+		if name == "" || strings.EqualFold(alias.Quality, "Low") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
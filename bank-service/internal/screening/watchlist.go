@@ -0,0 +1,135 @@
+package screening
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is a listed person, organisation, vessel or aircraft
+type Entry struct {
+	Source   string
+	ID       string
+	Name     string
+	Aliases  []string
+	Type     string
+	Programs []string
+
+	// names holds the normalized tokens of the name followed by the aliases
+	names [][]string
+}
+
+// BlockedAddress is a crypto address funds must not be sent to
+type BlockedAddress struct {
+	Source   string
+	EntryID  string
+	Name     string
+	Currency string
+	Address  string
+}
+
+// Watchlist is an immutable snapshot of all loaded lists
+type Watchlist struct {
+	entries   []*Entry
+	addresses map[string]*BlockedAddress
+	sources   map[string]*SourceStats
+	loadedAt  time.Time
+}
+
+// SourceStats counts what a list contributed
+type SourceStats struct {
+	Entries   int
+	Addresses int
+}
+
+func newWatchlist() *Watchlist {
+	return &Watchlist{
+		addresses: make(map[string]*BlockedAddress),
+		sources:   make(map[string]*SourceStats),
+		loadedAt:  time.Now(),
+	}
+}
+
+func (w *Watchlist) stats(source string) *SourceStats {
+	stats, ok := w.sources[source]
+	if !ok {
+		stats = &SourceStats{}
+		w.sources[source] = stats
+	}
+	return stats
+}
+
+func (w *Watchlist) addEntry(entry *Entry) {
+	for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+		if tokens := normalize(name); len(tokens) > 0 {
+			entry.names = append(entry.names, tokens)
+		}
+	}
+	if len(entry.names) == 0 {
+		return
+	}
+	w.entries = append(w.entries, entry)
+	w.stats(entry.Source).Entries++
+}
+
+func (w *Watchlist) addAddress(address *BlockedAddress) {
+	key := normalizeAddress(address.Address)
+	if key == "" {
+		return
+	}
+	if _, ok := w.addresses[key]; ok {
+		return
+	}
+	w.addresses[key] = address
+	w.stats(address.Source).Addresses++
+}
+
+// LoadDir reads every list in dir. Files are recognised by name:
+//
+//   - sdn.csv and alt.csv: OFAC SDN list and its aliases
+//   - *.xml: UN Security Council consolidated list
+//   - blocked_addresses.csv: currency,address,reason rows
+//
+// Other files are ignored.
+func LoadDir(dir string) (*Watchlist, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlist directory: %w", err)
+	}
+
+	list := newWatchlist()
+
+	// Aliases refer to entries by number, so the SDN file goes first
+	var sdnPath, altPath string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		switch name := strings.ToLower(file.Name()); {
+		case name == "sdn.csv":
+			sdnPath = path
+		case name == "alt.csv":
+			altPath = path
+		case name == "blocked_addresses.csv":
+			if err := loadBlockedAddresses(list, path); err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Name(), err)
+			}
+		case strings.HasSuffix(name, ".xml"):
+			if err := loadUNList(list, path); err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Name(), err)
+			}
+		}
+	}
+
+	if sdnPath != "" {
+		if err := loadOFACList(list, sdnPath, altPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
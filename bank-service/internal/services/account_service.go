@@ -1,12 +1,14 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/repositories"
 	"github.com/crypto-bank/bank-service/pkg/logger"
-	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/shared/events"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -14,28 +16,38 @@ import (
 type AccountService struct {
 	accountRepo *repositories.AccountRepository
 	userRepo    *repositories.UserRepository
-	rabbitMQ    *rabbitmq.Client
+	db          *sql.DB
+	outboxRepo  *repositories.OutboxRepository
+	assets      *AssetService
 }
 
 func NewAccountService(
 	accountRepo *repositories.AccountRepository,
 	userRepo *repositories.UserRepository,
-	rabbitMQ *rabbitmq.Client,
+	db *sql.DB,
+	outboxRepo *repositories.OutboxRepository,
+	assets *AssetService,
 ) *AccountService {
 	return &AccountService{
 		accountRepo: accountRepo,
 		userRepo:    userRepo,
-		rabbitMQ:    rabbitMQ,
+		db:          db,
+		outboxRepo:  outboxRepo,
+		assets:      assets,
 	}
 }
 
 // CreateAccount creates a new fiat account
-func (s *AccountService) CreateAccount(req *models.CreateAccountRequest) (*models.Account, error) {
+func (s *AccountService) CreateAccount(ctx context.Context, req *models.CreateAccountRequest) (*models.Account, error) {
 	logger.Info("Creating account",
 		zap.String("user_id", req.UserID.String()),
 		zap.String("currency", string(req.Currency)),
 	)
 
+	if _, err := s.assets.Require(string(req.Currency), models.AssetKindFiat); err != nil {
+		return nil, err
+	}
+
 	// Verify user exists
 	_, err := s.userRepo.GetByID(req.UserID)
 	if err != nil {
@@ -48,18 +60,33 @@ func (s *AccountService) CreateAccount(req *models.CreateAccountRequest) (*model
 		Balance:  0,
 	}
 
-	if err := s.accountRepo.Create(account); err != nil {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	if err := s.accountRepo.WithTx(dbTx).Create(account); err != nil {
 		logger.Error("Failed to create account", zap.Error(err))
 		return nil, err
 	}
 
-	// Publish event
-	event := rabbitmq.AccountEvent{
+	msg, err := newOutboxMessage(ctx, events.AccountCreated, account.ID.String(), events.AccountEvent{
 		AccountID: account.ID.String(),
 		UserID:    account.UserID.String(),
 		Currency:  string(account.Currency),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.outboxRepo.WithTx(dbTx).Add(msg); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	s.rabbitMQ.PublishEvent(rabbitmq.ExchangeEvents, rabbitmq.EventAccountCreated, event)
 
 	logger.Info("Account created", zap.String("account_id", account.ID.String()))
 	return account, nil
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	minNonceLength = 8
+	maxNonceLength = 64
+	usageDays      = 30
+)
+
+// ErrInvalidAPIKey is returned for every rejected API request so callers cannot
+// probe which check failed; the reason is logged
+var ErrInvalidAPIKey = errors.New("invalid API key signature")
+
+// APIKeySettings configures request signing and key limits
+type APIKeySettings struct {
+	// MaxClockSkew is how far the request timestamp may be from the server clock
+	MaxClockSkew   time.Duration
+	MaxKeysPerUser int
+}
+
+// APIKeyService manages API keys and authenticates signed API requests
+type APIKeyService struct {
+	apiKeyRepo *repositories.APIKeyRepository
+	secrets    *auth.SecretBox
+	settings   APIKeySettings
+}
+
+func NewAPIKeyService(apiKeyRepo *repositories.APIKeyRepository, secrets *auth.SecretBox, settings APIKeySettings) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		secrets:    secrets,
+		settings:   settings,
+	}
+}
+
+// CreateKey creates an API key for a user. The secret is only returned here.
+func (s *APIKeyService) CreateKey(userID uuid.UUID, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	allowedIPs, err := normalizeAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, key := range existing {
+		if key.RevokedAt == nil && !expired(key, time.Now()) {
+			active++
+		}
+	}
+	if active >= s.settings.MaxKeysPerUser {
+		return nil, fmt.Errorf("a user can have at most %d active API keys", s.settings.MaxKeysPerUser)
+	}
+
+	keyID, secret, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := s.secrets.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{
+		UserID:           userID,
+		Name:             req.Name,
+		KeyID:            keyID,
+		SecretCiphertext: ciphertext,
+		Scopes:           req.Scopes,
+		AllowedIPs:       allowedIPs,
+		ExpiresAt:        req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	logger.Info("API key created",
+		zap.String("user_id", userID.String()),
+		zap.String("key_id", key.KeyID),
+		zap.Any("scopes", key.Scopes),
+	)
+	return &models.CreatedAPIKey{APIKey: *key, Secret: secret}, nil
+}
+
+// GetUserKeys retrieves the API keys of a user
+func (s *APIKeyService) GetUserKeys(userID uuid.UUID) ([]*models.APIKey, error) {
+	return s.apiKeyRepo.GetByUserID(userID)
+}
+
+// RevokeKey revokes an API key of a user
+func (s *APIKeyService) RevokeKey(userID, id uuid.UUID) error {
+	revoked, err := s.apiKeyRepo.Revoke(userID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fmt.Errorf("API key not found")
+	}
+
+	logger.Info("API key revoked", zap.String("user_id", userID.String()), zap.String("api_key_id", id.String()))
+	return nil
+}
+
+// RevokeAll revokes every API key of a user
+func (s *APIKeyService) RevokeAll(userID uuid.UUID) error {
+	revoked, err := s.apiKeyRepo.RevokeAllForUser(userID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("All API keys revoked", zap.String("user_id", userID.String()), zap.Int64("keys", revoked))
+	return nil
+}
+
+// GetUsage retrieves the daily request counts of an API key of a user for the last 30 days
+func (s *APIKeyService) GetUsage(userID, id uuid.UUID) ([]*models.APIKeyUsage, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.ID == id {
+			return s.apiKeyRepo.GetUsage(id, time.Now().AddDate(0, 0, -usageDays))
+		}
+	}
+	return nil, fmt.Errorf("API key not found")
+}
+
+// AuthenticateAPIKey checks the key, IP allowlist, timestamp, signature and nonce
+// of a signed request and records its use
+func (s *APIKeyService) AuthenticateAPIKey(req *auth.SignedRequest) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByKeyID(req.KeyID)
+	if err != nil {
+		return nil, s.reject(req, "unknown key")
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, s.reject(req, "revoked key")
+	}
+	if expired(key, now) {
+		return nil, s.reject(req, "expired key")
+	}
+	if !ipAllowed(key.AllowedIPs, req.IP) {
+		return nil, s.reject(req, "address not allowed")
+	}
+
+	timestamp, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, s.reject(req, "malformed timestamp")
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > s.settings.MaxClockSkew || skew < -s.settings.MaxClockSkew {
+		return nil, s.reject(req, "timestamp outside window")
+	}
+
+	if len(req.Nonce) < minNonceLength || len(req.Nonce) > maxNonceLength {
+		return nil, s.reject(req, "malformed nonce")
+	}
+
+	secret, err := s.secrets.Open(key.SecretCiphertext)
+	if err != nil {
+		logger.Error("Failed to decrypt API key secret", zap.String("key_id", key.KeyID), zap.Error(err))
+		return nil, s.reject(req, "undecryptable secret")
+	}
+
+	if !auth.VerifyRequestSignature(secret, req.Signature, req.Timestamp, req.Nonce, req.Method, req.Path, req.Body) {
+		return nil, s.reject(req, "bad signature")
+	}
+
+	// Only signed requests reach the nonce store, so it cannot be filled by forged requests
+	fresh, err := s.apiKeyRepo.UseNonce(key.ID, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, s.reject(req, "replayed nonce")
+	}
+
+	if err := s.apiKeyRepo.RecordUsage(key.ID, req.IP); err != nil {
+		logger.Warn("Failed to record API key usage", zap.String("key_id", key.KeyID), zap.Error(err))
+	}
+
+	metrics.APIKeyRequestsTotal.WithLabelValues("accepted").Inc()
+	return key, nil
+}
+
+// PurgeNonces drops nonces that are past the timestamp window and can no longer be replayed
+func (s *APIKeyService) PurgeNonces() {
+	deleted, err := s.apiKeyRepo.DeleteNoncesBefore(time.Now().Add(-2 * s.settings.MaxClockSkew))
+	if err != nil {
+		logger.Error("Failed to purge API key nonces", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		logger.Debug("API key nonces purged", zap.Int64("count", deleted))
+	}
+}
+
+func (s *APIKeyService) reject(req *auth.SignedRequest, reason string) error {
+	logger.Warn("API request rejected",
+		zap.String("key_id", req.KeyID),
+		zap.String("ip", req.IP),
+		zap.String("reason", reason),
+	)
+	metrics.APIKeyRequestsTotal.WithLabelValues("rejected").Inc()
+	return ErrInvalidAPIKey
+}
+
+func expired(key *models.APIKey, now time.Time) bool {
+	return key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)
+}
+
+// normalizeAllowedIPs turns plain addresses into single-host CIDR ranges
+func normalizeAllowedIPs(entries []string) ([]string, error) {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			normalized = append(normalized, network.String())
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address or range: %s", entry)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		normalized = append(normalized, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+	}
+	return normalized, nil
+}
+
+// ipAllowed reports whether ip is in one of the allowed ranges. An empty
+// allowlist allows every address.
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ApprovalRequiredError is returned when an operation was parked for M-of-N approval
+// instead of being executed
+type ApprovalRequiredError struct {
+	Request *models.ApprovalRequest
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("operation requires %d approvals (request %s)", e.Request.RequiredApprovals, e.Request.ID)
+}
+
+// ApprovalExecutor executes a previously parked operation from its JSON payload and
+// returns the ID of the resulting record
+type ApprovalExecutor func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error)
+
+// ApprovalTarget identifies the wallet or account an operation draws funds from
+type ApprovalTarget struct {
+	WalletID  *uuid.UUID
+	AccountID *uuid.UUID
+}
+
+// approvalStore keeps policies, requests and the approval trail; it is
+// implemented by repositories.ApprovalRepository
+type approvalStore interface {
+	UpsertPolicy(policy *models.ApprovalPolicy) error
+	GetPolicyByWalletID(walletID uuid.UUID) (*models.ApprovalPolicy, error)
+	GetPolicyByAccountID(accountID uuid.UUID) (*models.ApprovalPolicy, error)
+	GetPolicyByID(id uuid.UUID) (*models.ApprovalPolicy, error)
+	CreateRequest(req *models.ApprovalRequest) error
+	GetRequestByID(id uuid.UUID) (*models.ApprovalRequest, error)
+	GetPendingForApprover(approverID uuid.UUID) ([]*models.ApprovalRequest, error)
+	GetByUserID(userID uuid.UUID) ([]*models.ApprovalRequest, error)
+	AddDecision(decision *models.ApprovalDecision) error
+	TransitionStatus(id uuid.UUID, from, to models.ApprovalStatus) error
+	MarkExecuted(id uuid.UUID, resultID *uuid.UUID, failure error) error
+	ExpirePending(now time.Time) (int64, error)
+}
+
+// auditRecorder appends changes to the audit trail; it is implemented by
+// AuditService
+type auditRecorder interface {
+	RecordContext(ctx context.Context, action models.AuditAction, entityType models.AuditEntityType, entityID string, before, after interface{})
+}
+
+type ApprovalService struct {
+	approvalRepo approvalStore
+	walletRepo   *repositories.CryptoWalletRepository
+	accountRepo  *repositories.AccountRepository
+	userRepo     *repositories.UserRepository
+	audit        auditRecorder
+	executors    map[models.ApprovalOperation]ApprovalExecutor
+}
+
+func NewApprovalService(
+	approvalRepo *repositories.ApprovalRepository,
+	walletRepo *repositories.CryptoWalletRepository,
+	accountRepo *repositories.AccountRepository,
+	userRepo *repositories.UserRepository,
+	audit *AuditService,
+) *ApprovalService {
+	s := &ApprovalService{
+		approvalRepo: approvalRepo,
+		walletRepo:   walletRepo,
+		accountRepo:  accountRepo,
+		userRepo:     userRepo,
+		audit:        audit,
+		executors:    make(map[models.ApprovalOperation]ApprovalExecutor),
+	}
+
+	s.RegisterExecutor(models.ApprovalOpPolicyChange, func(_ context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var change models.ApprovalPolicyChange
+		if err := json.Unmarshal(payload, &change); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid policy change payload: %w", err)
+		}
+		policy, err := s.setPolicy(&change)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return policy.ID, nil
+	})
+
+	return s
+}
+
+// RegisterExecutor registers the function used to run an operation once approved
+func (s *ApprovalService) RegisterExecutor(op models.ApprovalOperation, executor ApprovalExecutor) {
+	s.executors[op] = executor
+}
+
+// SetWalletPolicy attaches an approval policy to a crypto wallet of the user. A
+// policy in force is only replaced directly by staff: the owner's change waits
+// for the quorum of the current approvers and *ApprovalRequiredError is returned.
+func (s *ApprovalService) SetWalletPolicy(userID uuid.UUID, role models.Role, walletID uuid.UUID, req *models.SetApprovalPolicyRequest) (*models.ApprovalPolicy, error) {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.UserID != userID && !auth.IsStaff(role) {
+		return nil, ErrOwnershipMismatch
+	}
+
+	current, err := s.approvalRepo.GetPolicyByWalletID(walletID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	change := &models.ApprovalPolicyChange{WalletID: &walletID, Policy: *req}
+	return s.changePolicy(userID, role, current, change, string(wallet.CryptoType))
+}
+
+// SetAccountPolicy attaches an approval policy to a fiat account of the user. A
+// policy in force is only replaced directly by staff: the owner's change waits
+// for the quorum of the current approvers and *ApprovalRequiredError is returned.
+func (s *ApprovalService) SetAccountPolicy(userID uuid.UUID, role models.Role, accountID uuid.UUID, req *models.SetApprovalPolicyRequest) (*models.ApprovalPolicy, error) {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserID != userID && !auth.IsStaff(role) {
+		return nil, ErrOwnershipMismatch
+	}
+
+	current, err := s.approvalRepo.GetPolicyByAccountID(accountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	change := &models.ApprovalPolicyChange{AccountID: &accountID, Policy: *req}
+	return s.changePolicy(userID, role, current, change, string(account.Currency))
+}
+
+func (s *ApprovalService) checkWalletOwner(userID, walletID uuid.UUID) error {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return err
+	}
+	if wallet.UserID != userID {
+		return ErrOwnershipMismatch
+	}
+	return nil
+}
+
+func (s *ApprovalService) checkAccountOwner(userID, accountID uuid.UUID) error {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return err
+	}
+	if account.UserID != userID {
+		return ErrOwnershipMismatch
+	}
+	return nil
+}
+
+// changePolicy applies a policy change right away when no policy is in force or
+// staff make it, and parks it for the approvers of the current policy otherwise.
+// Otherwise a single owner could disable the policy or lower its threshold.
+func (s *ApprovalService) changePolicy(
+	userID uuid.UUID,
+	role models.Role,
+	current *models.ApprovalPolicy,
+	change *models.ApprovalPolicyChange,
+	currency string,
+) (*models.ApprovalPolicy, error) {
+	if err := s.validatePolicy(&change.Policy); err != nil {
+		return nil, err
+	}
+
+	if current == nil || !current.Enabled || auth.IsStaff(role) {
+		return s.setPolicy(change)
+	}
+
+	return nil, s.park(userID, current, models.ApprovalOpPolicyChange, change.Policy.Threshold, currency, change)
+}
+
+func (s *ApprovalService) validatePolicy(req *models.SetApprovalPolicyRequest) error {
+	if req.RequiredApprovals > len(req.Approvers) {
+		return fmt.Errorf("required approvals (%d) exceeds number of approvers (%d)",
+			req.RequiredApprovals, len(req.Approvers))
+	}
+
+	for _, approverID := range req.Approvers {
+		if _, err := s.userRepo.GetByID(approverID); err != nil {
+			return fmt.Errorf("approver %s: %w", approverID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ApprovalService) setPolicy(change *models.ApprovalPolicyChange) (*models.ApprovalPolicy, error) {
+	req := &change.Policy
+	policy := &models.ApprovalPolicy{
+		WalletID:          change.WalletID,
+		AccountID:         change.AccountID,
+		Threshold:         req.Threshold,
+		RequiredApprovals: req.RequiredApprovals,
+		TTLSeconds:        req.TTLSeconds,
+		Enabled:           true,
+		Approvers:         req.Approvers,
+	}
+	if policy.TTLSeconds == 0 {
+		policy.TTLSeconds = int((24 * time.Hour).Seconds())
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+
+	if err := s.approvalRepo.UpsertPolicy(policy); err != nil {
+		logger.Error("Failed to save approval policy", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Approval policy saved",
+		zap.String("policy_id", policy.ID.String()),
+		zap.Float64("threshold", policy.Threshold),
+		zap.Int("required_approvals", policy.RequiredApprovals),
+		zap.Bool("enabled", policy.Enabled),
+	)
+	return policy, nil
+}
+
+// GetWalletPolicy retrieves the approval policy of a crypto wallet of the user
+func (s *ApprovalService) GetWalletPolicy(userID, walletID uuid.UUID) (*models.ApprovalPolicy, error) {
+	if err := s.checkWalletOwner(userID, walletID); err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.GetPolicyByWalletID(walletID)
+}
+
+// GetAccountPolicy retrieves the approval policy of a fiat account of the user
+func (s *ApprovalService) GetAccountPolicy(userID, accountID uuid.UUID) (*models.ApprovalPolicy, error) {
+	if err := s.checkAccountOwner(userID, accountID); err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.GetPolicyByAccountID(accountID)
+}
+
+// Guard checks whether an operation needs approval. If the target has an enabled
+// policy and the amount reaches its threshold, a pending request is created and an
+// *ApprovalRequiredError is returned; otherwise nil is returned and the caller proceeds.
+func (s *ApprovalService) Guard(
+	userID uuid.UUID,
+	target ApprovalTarget,
+	op models.ApprovalOperation,
+	amount float64,
+	currency string,
+	payload interface{},
+) error {
+	var policy *models.ApprovalPolicy
+	var err error
+	switch {
+	case target.WalletID != nil:
+		policy, err = s.approvalRepo.GetPolicyByWalletID(*target.WalletID)
+	case target.AccountID != nil:
+		policy, err = s.approvalRepo.GetPolicyByAccountID(*target.AccountID)
+	default:
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get approval policy: %w", err)
+	}
+	if !policy.Enabled || amount < policy.Threshold {
+		return nil
+	}
+
+	return s.park(userID, policy, op, amount, currency, payload)
+}
+
+// park creates a pending request for the approvers of the policy and returns
+// the *ApprovalRequiredError carrying it
+func (s *ApprovalService) park(
+	userID uuid.UUID,
+	policy *models.ApprovalPolicy,
+	op models.ApprovalOperation,
+	amount float64,
+	currency string,
+	payload interface{},
+) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval payload: %w", err)
+	}
+
+	req := &models.ApprovalRequest{
+		PolicyID:          policy.ID,
+		UserID:            userID,
+		Operation:         op,
+		Status:            models.ApprovalStatusPending,
+		Amount:            amount,
+		Currency:          currency,
+		Payload:           body,
+		RequiredApprovals: policy.RequiredApprovals,
+		ExpiresAt:         time.Now().Add(time.Duration(policy.TTLSeconds) * time.Second),
+	}
+
+	if err := s.approvalRepo.CreateRequest(req); err != nil {
+		return err
+	}
+
+	logger.Info("Operation parked for approval",
+		zap.String("request_id", req.ID.String()),
+		zap.String("operation", string(op)),
+		zap.Float64("amount", amount),
+		zap.String("currency", currency),
+	)
+	return &ApprovalRequiredError{Request: req}
+}
+
+// GetRequest retrieves an approval request with its approval trail
+func (s *ApprovalService) GetRequest(id uuid.UUID) (*models.ApprovalRequest, error) {
+	return s.approvalRepo.GetRequestByID(id)
+}
+
+// GetRequestForUser retrieves an approval request visible to the user, who must
+// be its initiator or one of the approvers of its policy
+func (s *ApprovalService) GetRequestForUser(id, userID uuid.UUID) (*models.ApprovalRequest, error) {
+	approval, err := s.approvalRepo.GetRequestByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval.UserID == userID {
+		return approval, nil
+	}
+
+	policy, err := s.approvalRepo.GetPolicyByID(approval.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsUUID(policy.Approvers, userID) {
+		return nil, ErrOwnershipMismatch
+	}
+
+	return approval, nil
+}
+
+// GetPendingForApprover retrieves requests waiting for the user's decision
+func (s *ApprovalService) GetPendingForApprover(approverID uuid.UUID) ([]*models.ApprovalRequest, error) {
+	return s.approvalRepo.GetPendingForApprover(approverID)
+}
+
+// GetUserRequests retrieves approval requests initiated by the user
+func (s *ApprovalService) GetUserRequests(userID uuid.UUID) ([]*models.ApprovalRequest, error) {
+	return s.approvalRepo.GetByUserID(userID)
+}
+
+// Approve records an approval and executes the operation once the quorum is reached
+func (s *ApprovalService) Approve(ctx context.Context, requestID uuid.UUID, req *models.ApprovalDecisionRequest) (*models.ApprovalRequest, error) {
+	approval, err := s.decide(requestID, req, models.ApprovalDecisionApprove)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals := 0
+	for _, d := range approval.Decisions {
+		if d.Decision == models.ApprovalDecisionApprove {
+			approvals++
+		}
+	}
+	if approvals < approval.RequiredApprovals {
+		return approval, nil
+	}
+
+	// Only the decider that flips PENDING -> APPROVED runs the operation
+	if err := s.approvalRepo.TransitionStatus(approval.ID, models.ApprovalStatusPending, models.ApprovalStatusApproved); err != nil {
+		return s.approvalRepo.GetRequestByID(approval.ID)
+	}
+
+	s.execute(ctx, approval)
+	return s.approvalRepo.GetRequestByID(approval.ID)
+}
+
+// Reject records a rejection; a single rejection from an approver cancels the request
+func (s *ApprovalService) Reject(requestID uuid.UUID, req *models.ApprovalDecisionRequest) (*models.ApprovalRequest, error) {
+	approval, err := s.decide(requestID, req, models.ApprovalDecisionReject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.approvalRepo.TransitionStatus(approval.ID, models.ApprovalStatusPending, models.ApprovalStatusRejected); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Approval request rejected",
+		zap.String("request_id", approval.ID.String()),
+		zap.String("approver_id", req.ApproverID.String()),
+	)
+	return s.approvalRepo.GetRequestByID(approval.ID)
+}
+
+func (s *ApprovalService) decide(
+	requestID uuid.UUID,
+	req *models.ApprovalDecisionRequest,
+	decision models.ApprovalDecisionType,
+) (*models.ApprovalRequest, error) {
+	approval, err := s.approvalRepo.GetRequestByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval.Status != models.ApprovalStatusPending {
+		return nil, fmt.Errorf("approval request is %s", approval.Status)
+	}
+
+	if time.Now().After(approval.ExpiresAt) {
+		s.approvalRepo.TransitionStatus(approval.ID, models.ApprovalStatusPending, models.ApprovalStatusExpired)
+		return nil, fmt.Errorf("approval request has expired")
+	}
+
+	if req.ApproverID == approval.UserID {
+		return nil, fmt.Errorf("initiator cannot decide on their own request")
+	}
+
+	policy, err := s.approvalRepo.GetPolicyByID(approval.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !containsUUID(policy.Approvers, req.ApproverID) {
+		return nil, fmt.Errorf("user %s is not an approver for this policy", req.ApproverID)
+	}
+
+	if err := s.approvalRepo.AddDecision(&models.ApprovalDecision{
+		RequestID:  approval.ID,
+		ApproverID: req.ApproverID,
+		Decision:   decision,
+		Comment:    req.Comment,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Approval decision recorded",
+		zap.String("request_id", approval.ID.String()),
+		zap.String("approver_id", req.ApproverID.String()),
+		zap.String("decision", string(decision)),
+	)
+	return s.approvalRepo.GetRequestByID(approval.ID)
+}
+
+// execute runs an approved operation and records the outcome on the request
+// and in the audit trail
+func (s *ApprovalService) execute(ctx context.Context, approval *models.ApprovalRequest) {
+	defer s.recordExecution(ctx, approval)
+
+	executor, ok := s.executors[approval.Operation]
+	if !ok {
+		err := fmt.Errorf("no executor registered for %s", approval.Operation)
+		logger.Error("Failed to execute approved operation", zap.Error(err))
+		s.approvalRepo.MarkExecuted(approval.ID, nil, err)
+		return
+	}
+
+	resultID, err := executor(ctx, approval.Payload)
+	if err != nil {
+		logger.Error("Approved operation failed",
+			zap.String("request_id", approval.ID.String()),
+			zap.Error(err),
+		)
+		s.approvalRepo.MarkExecuted(approval.ID, nil, err)
+		return
+	}
+
+	s.approvalRepo.MarkExecuted(approval.ID, &resultID, nil)
+	logger.Info("Approved operation executed",
+		zap.String("request_id", approval.ID.String()),
+		zap.String("result_id", resultID.String()),
+	)
+}
+
+// recordExecution adds the outcome of an approved operation to the audit trail
+func (s *ApprovalService) recordExecution(ctx context.Context, approval *models.ApprovalRequest) {
+	executed, err := s.approvalRepo.GetRequestByID(approval.ID)
+	if err != nil {
+		logger.Error("Failed to get executed approval request",
+			zap.String("request_id", approval.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.audit.RecordContext(ctx, models.AuditApprovalExecuted, models.AuditEntityApprovalRequest, approval.ID.String(), approval, executed)
+}
+
+// ExpirePending expires all pending requests past their deadline
+func (s *ApprovalService) ExpirePending() {
+	expired, err := s.approvalRepo.ExpirePending(time.Now())
+	if err != nil {
+		logger.Error("Failed to expire approval requests", zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		logger.Info("Approval requests expired", zap.Int64("count", expired))
+	}
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryApprovals keeps the approval workflow in memory with the constraints
+// of the approval tables: one decision per approver and conditional status
+// transitions
+type memoryApprovals struct {
+	policies  map[uuid.UUID]*models.ApprovalPolicy
+	requests  map[uuid.UUID]*models.ApprovalRequest
+	decisions map[uuid.UUID][]models.ApprovalDecision
+}
+
+func newMemoryApprovals() *memoryApprovals {
+	return &memoryApprovals{
+		policies:  make(map[uuid.UUID]*models.ApprovalPolicy),
+		requests:  make(map[uuid.UUID]*models.ApprovalRequest),
+		decisions: make(map[uuid.UUID][]models.ApprovalDecision),
+	}
+}
+
+func (m *memoryApprovals) UpsertPolicy(policy *models.ApprovalPolicy) error {
+	policy.ID = uuid.New()
+	m.policies[policy.ID] = policy
+	return nil
+}
+
+func (m *memoryApprovals) policyWhere(match func(*models.ApprovalPolicy) bool) (*models.ApprovalPolicy, error) {
+	for _, policy := range m.policies {
+		if match(policy) {
+			return policy, nil
+		}
+	}
+	return nil, fmt.Errorf("approval policy not found")
+}
+
+func (m *memoryApprovals) GetPolicyByWalletID(walletID uuid.UUID) (*models.ApprovalPolicy, error) {
+	return m.policyWhere(func(p *models.ApprovalPolicy) bool { return p.WalletID != nil && *p.WalletID == walletID })
+}
+
+func (m *memoryApprovals) GetPolicyByAccountID(accountID uuid.UUID) (*models.ApprovalPolicy, error) {
+	return m.policyWhere(func(p *models.ApprovalPolicy) bool { return p.AccountID != nil && *p.AccountID == accountID })
+}
+
+func (m *memoryApprovals) GetPolicyByID(id uuid.UUID) (*models.ApprovalPolicy, error) {
+	return m.policyWhere(func(p *models.ApprovalPolicy) bool { return p.ID == id })
+}
+
+func (m *memoryApprovals) CreateRequest(req *models.ApprovalRequest) error {
+	req.ID = uuid.New()
+	stored := *req
+	m.requests[req.ID] = &stored
+	return nil
+}
+
+func (m *memoryApprovals) GetRequestByID(id uuid.UUID) (*models.ApprovalRequest, error) {
+	stored, ok := m.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("approval request not found")
+	}
+	req := *stored
+	req.Decisions = append([]models.ApprovalDecision(nil), m.decisions[id]...)
+	return &req, nil
+}
+
+func (m *memoryApprovals) GetPendingForApprover(uuid.UUID) ([]*models.ApprovalRequest, error) {
+	return nil, nil
+}
+
+func (m *memoryApprovals) GetByUserID(uuid.UUID) ([]*models.ApprovalRequest, error) {
+	return nil, nil
+}
+
+func (m *memoryApprovals) AddDecision(decision *models.ApprovalDecision) error {
+	for _, d := range m.decisions[decision.RequestID] {
+		if d.ApproverID == decision.ApproverID {
+			return fmt.Errorf("approver has already decided on this request")
+		}
+	}
+	decision.ID = uuid.New()
+	m.decisions[decision.RequestID] = append(m.decisions[decision.RequestID], *decision)
+	return nil
+}
+
+func (m *memoryApprovals) TransitionStatus(id uuid.UUID, from, to models.ApprovalStatus) error {
+	req, ok := m.requests[id]
+	if !ok || req.Status != from {
+		return fmt.Errorf("approval request is no longer %s", from)
+	}
+	req.Status = to
+	return nil
+}
+
+func (m *memoryApprovals) MarkExecuted(id uuid.UUID, resultID *uuid.UUID, failure error) error {
+	req := m.requests[id]
+	if failure != nil {
+		reason := failure.Error()
+		req.Status = models.ApprovalStatusFailed
+		req.FailureReason = &reason
+		return nil
+	}
+	req.Status = models.ApprovalStatusExecuted
+	req.ResultID = resultID
+	return nil
+}
+
+func (m *memoryApprovals) ExpirePending(now time.Time) (int64, error) {
+	var expired int64
+	for _, req := range m.requests {
+		if req.Status == models.ApprovalStatusPending && !req.ExpiresAt.After(now) {
+			req.Status = models.ApprovalStatusExpired
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+type auditRecords []models.AuditAction
+
+func (a *auditRecords) RecordContext(_ context.Context, action models.AuditAction, _ models.AuditEntityType, _ string, _, _ interface{}) {
+	*a = append(*a, action)
+}
+
+// approvalFixture is a wallet guarded by a 2-of-3 policy and a withdrawal
+// parked on it
+type approvalFixture struct {
+	service   *ApprovalService
+	store     *memoryApprovals
+	audit     *auditRecords
+	initiator uuid.UUID
+	approvers []uuid.UUID
+	walletID  uuid.UUID
+	request   *models.ApprovalRequest
+	executed  []json.RawMessage
+	result    uuid.UUID
+	failWith  error
+}
+
+func newApprovalFixture(t *testing.T) *approvalFixture {
+	t.Helper()
+	logger.Log = zap.NewNop()
+
+	f := &approvalFixture{
+		store:     newMemoryApprovals(),
+		audit:     &auditRecords{},
+		initiator: uuid.New(),
+		approvers: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()},
+		walletID:  uuid.New(),
+		result:    uuid.New(),
+	}
+	f.service = &ApprovalService{
+		approvalRepo: f.store,
+		audit:        f.audit,
+		executors:    make(map[models.ApprovalOperation]ApprovalExecutor),
+	}
+	f.service.RegisterExecutor(models.ApprovalOpCryptoWithdraw, func(_ context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		f.executed = append(f.executed, payload)
+		if f.failWith != nil {
+			return uuid.Nil, f.failWith
+		}
+		return f.result, nil
+	})
+
+	policy := &models.ApprovalPolicy{
+		WalletID:          &f.walletID,
+		Threshold:         1,
+		RequiredApprovals: 2,
+		TTLSeconds:        3600,
+		Enabled:           true,
+		// The initiator co-signs for others but never for themselves
+		Approvers: append([]uuid.UUID{f.initiator}, f.approvers...),
+	}
+	if err := f.store.UpsertPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+
+	err := f.service.Guard(f.initiator, ApprovalTarget{WalletID: &f.walletID}, models.ApprovalOpCryptoWithdraw, 2.5, "BTC",
+		map[string]interface{}{"wallet_id": f.walletID, "amount": 2.5})
+	var parked *ApprovalRequiredError
+	if !errors.As(err, &parked) {
+		t.Fatalf("Guard = %v, want *ApprovalRequiredError", err)
+	}
+	f.request = parked.Request
+	return f
+}
+
+func (f *approvalFixture) approve(approverID uuid.UUID) (*models.ApprovalRequest, error) {
+	return f.service.Approve(context.Background(), f.request.ID, &models.ApprovalDecisionRequest{ApproverID: approverID})
+}
+
+func (f *approvalFixture) reject(approverID uuid.UUID) (*models.ApprovalRequest, error) {
+	return f.service.Reject(f.request.ID, &models.ApprovalDecisionRequest{ApproverID: approverID})
+}
+
+func (f *approvalFixture) status(t *testing.T) models.ApprovalStatus {
+	t.Helper()
+	req, err := f.store.GetRequestByID(f.request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req.Status
+}
+
+func TestApprovalGuard(t *testing.T) {
+	f := newApprovalFixture(t)
+
+	if f.request.Status != models.ApprovalStatusPending || f.request.RequiredApprovals != 2 {
+		t.Errorf("parked request = %s needing %d, want PENDING needing 2", f.request.Status, f.request.RequiredApprovals)
+	}
+	if ttl := time.Until(f.request.ExpiresAt); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("request expires in %s, want the policy TTL of 1h", ttl)
+	}
+
+	f.store.policies[f.request.PolicyID].Threshold = 10
+	if err := f.service.Guard(f.initiator, ApprovalTarget{WalletID: &f.walletID}, models.ApprovalOpCryptoWithdraw, 9.99, "BTC", nil); err != nil {
+		t.Errorf("Guard below the threshold = %v, want nil", err)
+	}
+
+	f.store.policies[f.request.PolicyID].Enabled = false
+	if err := f.service.Guard(f.initiator, ApprovalTarget{WalletID: &f.walletID}, models.ApprovalOpCryptoWithdraw, 20, "BTC", nil); err != nil {
+		t.Errorf("Guard with the policy disabled = %v, want nil", err)
+	}
+}
+
+func TestApprovalQuorum(t *testing.T) {
+	tests := []struct {
+		name string
+		// run makes the decisions and returns the error of the last one
+		run      func(f *approvalFixture) error
+		wantErr  string
+		status   models.ApprovalStatus
+		executed int
+	}{
+		{
+			name: "one approval waits for the quorum",
+			run: func(f *approvalFixture) error {
+				_, err := f.approve(f.approvers[0])
+				return err
+			},
+			status: models.ApprovalStatusPending,
+		},
+		{
+			name: "quorum executes the operation",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				_, err := f.approve(f.approvers[1])
+				return err
+			},
+			status:   models.ApprovalStatusExecuted,
+			executed: 1,
+		},
+		{
+			name: "a duplicate approval does not count",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				_, err := f.approve(f.approvers[0])
+				return err
+			},
+			wantErr: "already decided",
+			status:  models.ApprovalStatusPending,
+		},
+		{
+			name: "the initiator cannot approve",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				_, err := f.approve(f.initiator)
+				return err
+			},
+			wantErr: "initiator cannot decide",
+			status:  models.ApprovalStatusPending,
+		},
+		{
+			name: "outsiders cannot approve",
+			run: func(f *approvalFixture) error {
+				_, err := f.approve(uuid.New())
+				return err
+			},
+			wantErr: "not an approver",
+			status:  models.ApprovalStatusPending,
+		},
+		{
+			name: "one rejection cancels the request",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				_, err := f.reject(f.approvers[1])
+				return err
+			},
+			status: models.ApprovalStatusRejected,
+		},
+		{
+			name: "approvals after a rejection are refused",
+			run: func(f *approvalFixture) error {
+				if _, err := f.reject(f.approvers[0]); err != nil {
+					return err
+				}
+				if _, err := f.approve(f.approvers[1]); err == nil {
+					return fmt.Errorf("approved a rejected request")
+				}
+				_, err := f.approve(f.approvers[2])
+				return err
+			},
+			wantErr: "REJECTED",
+			status:  models.ApprovalStatusRejected,
+		},
+		{
+			name: "the initiator cannot reject",
+			run: func(f *approvalFixture) error {
+				_, err := f.reject(f.initiator)
+				return err
+			},
+			wantErr: "initiator cannot decide",
+			status:  models.ApprovalStatusPending,
+		},
+		{
+			name: "approvals after execution are refused",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				if _, err := f.approve(f.approvers[1]); err != nil {
+					return err
+				}
+				_, err := f.approve(f.approvers[2])
+				return err
+			},
+			wantErr:  "EXECUTED",
+			status:   models.ApprovalStatusExecuted,
+			executed: 1,
+		},
+		{
+			name: "a failed operation fails the request",
+			run: func(f *approvalFixture) error {
+				f.failWith = errors.New("insufficient balance")
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				_, err := f.approve(f.approvers[1])
+				return err
+			},
+			status:   models.ApprovalStatusFailed,
+			executed: 1,
+		},
+		{
+			name: "an expired request cannot be approved",
+			run: func(f *approvalFixture) error {
+				if _, err := f.approve(f.approvers[0]); err != nil {
+					return err
+				}
+				f.store.requests[f.request.ID].ExpiresAt = time.Now().Add(-time.Second)
+				_, err := f.approve(f.approvers[1])
+				return err
+			},
+			wantErr: "expired",
+			status:  models.ApprovalStatusExpired,
+		},
+		{
+			name: "an expired request cannot be rejected",
+			run: func(f *approvalFixture) error {
+				f.store.requests[f.request.ID].ExpiresAt = time.Now().Add(-time.Second)
+				_, err := f.reject(f.approvers[0])
+				return err
+			},
+			wantErr: "expired",
+			status:  models.ApprovalStatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newApprovalFixture(t)
+
+			err := tt.run(f)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+
+			if got := f.status(t); got != tt.status {
+				t.Errorf("status = %s, want %s", got, tt.status)
+			}
+			if len(f.executed) != tt.executed {
+				t.Errorf("executed %d times, want %d", len(f.executed), tt.executed)
+			}
+			if len(*f.audit) != tt.executed {
+				t.Errorf("audited %d executions, want %d", len(*f.audit), tt.executed)
+			}
+		})
+	}
+}
+
+func TestApprovalExecutionRecordsOutcome(t *testing.T) {
+	f := newApprovalFixture(t)
+
+	if _, err := f.approve(f.approvers[0]); err != nil {
+		t.Fatal(err)
+	}
+	executed, err := f.approve(f.approvers[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if executed.ResultID == nil || *executed.ResultID != f.result {
+		t.Errorf("result = %v, want %s", executed.ResultID, f.result)
+	}
+	if len(executed.Decisions) != 2 {
+		t.Errorf("got %d decisions on the trail, want 2", len(executed.Decisions))
+	}
+	if string(f.executed[0]) != string(f.request.Payload) {
+		t.Errorf("executor got %s, want the parked payload %s", f.executed[0], f.request.Payload)
+	}
+	if (*f.audit)[0] != models.AuditApprovalExecuted {
+		t.Errorf("audited %s, want %s", (*f.audit)[0], models.AuditApprovalExecuted)
+	}
+}
+
+func TestApprovalExpirePending(t *testing.T) {
+	f := newApprovalFixture(t)
+
+	f.service.ExpirePending()
+	if got := f.status(t); got != models.ApprovalStatusPending {
+		t.Fatalf("status before the deadline = %s, want PENDING", got)
+	}
+
+	f.store.requests[f.request.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	f.service.ExpirePending()
+	if got := f.status(t); got != models.ApprovalStatusExpired {
+		t.Errorf("status after the deadline = %s, want EXPIRED", got)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AssetService keeps an in-memory copy of the asset registry so that request
+// validation does not hit the database
+type AssetService struct {
+	assetRepo    *repositories.AssetRepository
+	exchangeRepo *repositories.ExchangeRepository
+	treasury     *TreasuryService
+
+	mu     sync.RWMutex
+	assets map[string]*models.Asset
+}
+
+func NewAssetService(
+	assetRepo *repositories.AssetRepository,
+	exchangeRepo *repositories.ExchangeRepository,
+	treasury *TreasuryService,
+) *AssetService {
+	return &AssetService{
+		assetRepo:    assetRepo,
+		exchangeRepo: exchangeRepo,
+		treasury:     treasury,
+		assets:       make(map[string]*models.Asset),
+	}
+}
+
+// Reload refreshes the cached registry from the database
+func (s *AssetService) Reload() error {
+	assets, err := s.assetRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	registry := make(map[string]*models.Asset, len(assets))
+	for _, asset := range assets {
+		registry[asset.Code] = asset
+	}
+
+	s.mu.Lock()
+	s.assets = registry
+	s.mu.Unlock()
+
+	logger.Debug("Asset registry loaded", zap.Int("count", len(registry)))
+	return nil
+}
+
+// Require returns an enabled asset of the given kind or an error
+func (s *AssetService) Require(code string, kind models.AssetKind) (*models.Asset, error) {
+	s.mu.RLock()
+	asset, ok := s.assets[code]
+	s.mu.RUnlock()
+
+	if !ok || asset.Kind != kind {
+		return nil, fmt.Errorf("unsupported %s asset: %s", kind, code)
+	}
+	if !asset.Enabled {
+		return nil, fmt.Errorf("asset %s is disabled", code)
+	}
+
+	return asset, nil
+}
+
+// Has reports whether an asset is registered, enabled or not
+func (s *AssetService) Has(code string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.assets[code]
+	return ok
+}
+
+// USDValue converts an amount of any registered asset, enabled or not, to US
+// dollars. It reports false for unknown assets and assets without a reference rate.
+func (s *AssetService) USDValue(code string, amount float64) (float64, bool) {
+	s.mu.RLock()
+	asset, ok := s.assets[code]
+	s.mu.RUnlock()
+
+	if !ok {
+		return 0, false
+	}
+	return asset.USDValue(amount)
+}
+
+// GetAssets returns the registry, optionally including disabled assets
+func (s *AssetService) GetAssets(includeDisabled bool) ([]*models.Asset, error) {
+	assets, err := s.assetRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if includeDisabled {
+		return assets, nil
+	}
+
+	enabled := make([]*models.Asset, 0, len(assets))
+	for _, asset := range assets {
+		if asset.Enabled {
+			enabled = append(enabled, asset)
+		}
+	}
+	return enabled, nil
+}
+
+// GetAsset retrieves an asset by code
+func (s *AssetService) GetAsset(code string) (*models.Asset, error) {
+	return s.assetRepo.GetByCode(code)
+}
+
+// CreateAsset registers a new asset. Crypto assets get house wallets, and a
+// reference USD rate seeds the exchange rates of the asset.
+func (s *AssetService) CreateAsset(req *models.CreateAssetRequest) (*models.Asset, error) {
+	if req.AddressPattern != "" {
+		if _, err := regexp.Compile(req.AddressPattern); err != nil {
+			return nil, fmt.Errorf("invalid address pattern: %w", err)
+		}
+	}
+
+	asset := &models.Asset{
+		Code:             req.Code,
+		Name:             req.Name,
+		Kind:             req.Kind,
+		Decimals:         req.Decimals,
+		Chain:            req.Chain,
+		AddressPrefix:    req.AddressPrefix,
+		AddressPattern:   req.AddressPattern,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+		MinAmount:        req.MinAmount,
+		MinWithdrawal:    req.MinWithdrawal,
+		ReferenceUSDRate: req.ReferenceUSDRate,
+	}
+
+	if err := s.assetRepo.Create(asset); err != nil {
+		logger.Error("Failed to create asset", zap.Error(err))
+		return nil, err
+	}
+
+	if asset.Kind == models.AssetKindCrypto {
+		if err := s.treasury.ProvisionAsset(asset); err != nil {
+			logger.Error("Failed to provision treasury wallets", zap.String("code", asset.Code), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if err := s.seedRates(asset); err != nil {
+		return nil, err
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Asset registered",
+		zap.String("code", asset.Code),
+		zap.String("kind", string(asset.Kind)),
+		zap.Bool("enabled", asset.Enabled),
+	)
+	return asset, nil
+}
+
+// UpdateAsset updates the attributes of an asset
+func (s *AssetService) UpdateAsset(code string, req *models.UpdateAssetRequest) (*models.Asset, error) {
+	asset, err := s.assetRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		asset.Name = *req.Name
+	}
+	if req.Decimals != nil {
+		asset.Decimals = *req.Decimals
+	}
+	if req.Chain != nil {
+		asset.Chain = *req.Chain
+	}
+	if req.AddressPrefix != nil {
+		asset.AddressPrefix = *req.AddressPrefix
+	}
+	if req.AddressPattern != nil {
+		if _, err := regexp.Compile(*req.AddressPattern); err != nil {
+			return nil, fmt.Errorf("invalid address pattern: %w", err)
+		}
+		asset.AddressPattern = *req.AddressPattern
+	}
+	if req.MinAmount != nil {
+		asset.MinAmount = *req.MinAmount
+	}
+	if req.MinWithdrawal != nil {
+		asset.MinWithdrawal = *req.MinWithdrawal
+	}
+	if req.ReferenceUSDRate != nil {
+		asset.ReferenceUSDRate = req.ReferenceUSDRate
+	}
+
+	if err := s.assetRepo.Update(asset); err != nil {
+		return nil, err
+	}
+
+	if err := s.seedRates(asset); err != nil {
+		return nil, err
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Asset updated", zap.String("code", asset.Code))
+	return asset, nil
+}
+
+// SetEnabled enables or disables an asset. Disabled assets reject new accounts,
+// wallets and operations but existing balances are kept.
+func (s *AssetService) SetEnabled(code string, enabled bool) (*models.Asset, error) {
+	if err := s.assetRepo.SetEnabled(code, enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Asset availability changed", zap.String("code", code), zap.Bool("enabled", enabled))
+	return s.assetRepo.GetByCode(code)
+}
+
+// seedRates creates missing USD exchange rates from the reference rate of an asset
+func (s *AssetService) seedRates(asset *models.Asset) error {
+	if asset.ReferenceUSDRate == nil || asset.Code == "USD" {
+		return nil
+	}
+
+	rate := *asset.ReferenceUSDRate
+	if err := s.exchangeRepo.CreateExchangeRateIfMissing(asset.Code, "USD", rate); err != nil {
+		return err
+	}
+	return s.exchangeRepo.CreateExchangeRateIfMissing("USD", asset.Code, 1/rate)
+}
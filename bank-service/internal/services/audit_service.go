@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/auditchain"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// auditVerifyBatch is the number of entries read at a time when verifying
+const auditVerifyBatch = 1000
+
+// AuditService keeps the tamper-evident audit trail of state changes
+type AuditService struct {
+	auditRepo *repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo *repositories.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends a change to the audit trail. Before is nil for creations and
+// after is nil for deletions. The change itself already happened, so a failed
+// write is logged and counted rather than returned.
+func (s *AuditService) Record(origin models.AuditOrigin, action models.AuditAction, entityType models.AuditEntityType, entityID string, before, after interface{}) {
+	_ = s.record(origin, action, entityType, entityID, before, after)
+}
+
+// RecordRateChange appends an exchange rate set in the exchange service. The
+// exchange service reports its changes, so a failed write is returned to it.
+func (s *AuditService) RecordRateChange(origin models.AuditOrigin, change *models.RateChange) error {
+	var before interface{}
+	if change.PreviousRate != nil {
+		before = map[string]float64{"rate": *change.PreviousRate}
+	}
+	pair := strings.ToUpper(change.FromCurrency) + "/" + strings.ToUpper(change.ToCurrency)
+	return s.record(origin, models.AuditRateUpdated, models.AuditEntityRate, pair, before, map[string]float64{"rate": change.Rate})
+}
+
+func (s *AuditService) record(origin models.AuditOrigin, action models.AuditAction, entityType models.AuditEntityType, entityID string, before, after interface{}) error {
+	entry := &auditchain.Entry{
+		ID: uuid.New(),
+		// The database keeps microseconds; the hash must match what is read back
+		OccurredAt: time.Now().UTC().Truncate(time.Microsecond),
+		ActorType:  string(origin.ActorType),
+		ActorID:    origin.ActorID,
+		APIKeyID:   origin.APIKeyID,
+		ActorRole:  origin.ActorRole,
+		Action:     string(action),
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		RequestID:  origin.RequestID,
+		TraceID:    origin.TraceID,
+		IP:         origin.IP,
+	}
+
+	err := s.encode(entry, before, after)
+	if err == nil {
+		err = s.auditRepo.Append(entry)
+	}
+	if err != nil {
+		metrics.AuditWriteFailuresTotal.Inc()
+		logger.Error("Failed to write audit entry",
+			zap.String("action", string(action)),
+			zap.String("entity_type", string(entityType)),
+			zap.String("entity_id", entityID),
+			zap.String("request_id", origin.RequestID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	metrics.AuditEntriesTotal.WithLabelValues(string(entityType)).Inc()
+	return nil
+}
+
+// RecordContext appends a change made while serving the request the context
+// belongs to; see WithAuditOrigin
+func (s *AuditService) RecordContext(ctx context.Context, action models.AuditAction, entityType models.AuditEntityType, entityID string, before, after interface{}) {
+	s.Record(AuditOriginFrom(ctx), action, entityType, entityID, before, after)
+}
+
+type auditOriginKey struct{}
+
+// WithAuditOrigin returns a context telling the services who made the request
+func WithAuditOrigin(ctx context.Context, origin models.AuditOrigin) context.Context {
+	return context.WithValue(ctx, auditOriginKey{}, origin)
+}
+
+// AuditOriginFrom returns who made the request the context belongs to. Changes
+// made outside of a request, by background workers, are the system's.
+func AuditOriginFrom(ctx context.Context) models.AuditOrigin {
+	if origin, ok := ctx.Value(auditOriginKey{}).(models.AuditOrigin); ok {
+		return origin
+	}
+	return models.AuditOrigin{ActorType: models.AuditActorSystem}
+}
+
+// GetEntries lists audit entries in chain order
+func (s *AuditService) GetEntries(filter *models.AuditFilter) ([]*auditchain.Entry, error) {
+	return s.auditRepo.List(filter)
+}
+
+// Verify walks the whole chain from the genesis entry and reports the first
+// entry that was edited, removed or reordered
+func (s *AuditService) Verify() (*models.AuditVerification, error) {
+	verifier := auditchain.NewVerifier(true)
+	filter := &models.AuditFilter{Limit: auditVerifyBatch}
+
+	var broken *auditchain.BreakError
+	for broken == nil {
+		entries, err := s.auditRepo.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if err := verifier.Add(entry); err != nil {
+				errors.As(err, &broken)
+				break
+			}
+		}
+		if len(entries) < auditVerifyBatch {
+			break
+		}
+		filter.AfterSeq = entries[len(entries)-1].Seq
+	}
+
+	result := verifier.Result()
+	verification := &models.AuditVerification{
+		Valid:      broken == nil,
+		Entries:    result.Entries,
+		HeadSeq:    result.HeadSeq,
+		HeadHash:   result.HeadHash,
+		VerifiedAt: time.Now(),
+	}
+	if broken != nil {
+		verification.BrokenAtSeq = &broken.Seq
+		verification.Problem = broken.Reason
+		logger.Error("Audit chain verification failed",
+			zap.Int64("seq", broken.Seq),
+			zap.String("problem", broken.Reason),
+		)
+	}
+
+	return verification, nil
+}
+
+func (s *AuditService) encode(entry *auditchain.Entry, before, after interface{}) error {
+	var err error
+	if entry.Before, err = auditchain.Canonical(before); err != nil {
+		return err
+	}
+	entry.After, err = auditchain.Canonical(after)
+	return err
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired, revoked or reused refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrInvalidCredentials is returned for unknown emails and wrong passwords alike
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// AccountLockedError is returned while a user is locked out after repeated login failures
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked until %s", e.Until.Format(time.RFC3339))
+}
+
+// AuthSettings configures token lifetimes and step-up authentication
+type AuthSettings struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	StepUpWindow    time.Duration
+}
+
+// AuthService logs users in and issues access/refresh token pairs. Refresh
+// tokens are single use: every refresh rotates the token, and presenting a
+// rotated token again revokes all sessions of the user since the token has
+// most likely leaked.
+type AuthService struct {
+	tokens           *auth.TokenManager
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	credentialRepo   *repositories.CredentialRepository
+	userRepo         *repositories.UserRepository
+	twoFactor        *TwoFactorService
+	lockout          *Lockout
+	settings         AuthSettings
+}
+
+func NewAuthService(
+	tokens *auth.TokenManager,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	credentialRepo *repositories.CredentialRepository,
+	userRepo *repositories.UserRepository,
+	twoFactor *TwoFactorService,
+	lockout *Lockout,
+	settings AuthSettings,
+) *AuthService {
+	return &AuthService{
+		tokens:           tokens,
+		refreshTokenRepo: refreshTokenRepo,
+		credentialRepo:   credentialRepo,
+		userRepo:         userRepo,
+		twoFactor:        twoFactor,
+		lockout:          lockout,
+		settings:         settings,
+	}
+}
+
+// Login checks the password and, when enabled, the second factor of a user and
+// opens a session. Every failed attempt counts towards the lockout.
+func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenPair, error) {
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		auth.BurnPasswordCheck(req.Password)
+		return nil, ErrInvalidCredentials
+	}
+
+	credential, err := s.credentialRepo.GetCredential(user.ID)
+	if err != nil {
+		auth.BurnPasswordCheck(req.Password)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := lockedOut(credential); err != nil {
+		return nil, err
+	}
+
+	ok, err := auth.VerifyPassword(req.Password, credential.PasswordHash)
+	if err != nil {
+		logger.Error("Failed to verify password", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, ErrInvalidCredentials
+	}
+	if !ok {
+		return nil, s.lockout.Failed(user.ID, ErrInvalidCredentials)
+	}
+
+	if s.twoFactor.Enabled(user.ID) {
+		if req.TwoFactorCode == "" {
+			return nil, s.lockout.Failed(user.ID, ErrTwoFactorRequired)
+		}
+		if err := s.twoFactor.Verify(user.ID, req.TwoFactorCode); err != nil {
+			return nil, s.lockout.Failed(user.ID, err)
+		}
+	}
+
+	if credential.FailedAttempts > 0 || credential.LockedUntil != nil {
+		if err := s.credentialRepo.ResetFailedLogins(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if auth.NeedsRehash(credential.PasswordHash) {
+		if hash, err := auth.HashPassword(req.Password); err == nil {
+			if err := s.credentialRepo.SetPassword(user.ID, hash); err != nil {
+				logger.Warn("Failed to upgrade password hash", zap.String("user_id", user.ID.String()), zap.Error(err))
+			}
+		}
+	}
+
+	return s.IssueTokens(user)
+}
+
+// StepUp checks a second factor and returns an access token that allows
+// sensitive operations for the step-up window. Wrong codes count towards the
+// lockout like failed logins.
+func (s *AuthService) StepUp(userID uuid.UUID, code string) (*models.TokenPair, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactor.CheckCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	claims := s.tokens.NewClaims(userID, auth.TokenTypeAccess, s.settings.AccessTokenTTL)
+	claims.Role = user.Role
+	claims.StepUpUntil = time.Now().Add(s.settings.StepUpWindow).Unix()
+	if claims.StepUpUntil > claims.ExpiresAt {
+		claims.StepUpUntil = claims.ExpiresAt
+	}
+
+	accessToken, err := s.tokens.Sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	logger.Info("Step-up authentication completed", zap.String("user_id", userID.String()))
+	return &models.TokenPair{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Unix(claims.ExpiresAt, 0),
+		StepUpUntil: timePtr(time.Unix(claims.StepUpUntil, 0)),
+	}, nil
+}
+
+// ChangePassword replaces the password of a user and closes all sessions
+func (s *AuthService) ChangePassword(userID uuid.UUID, req *models.ChangePasswordRequest) error {
+	credential, err := s.credentialRepo.GetCredential(userID)
+	if err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := lockedOut(credential); err != nil {
+		return err
+	}
+
+	ok, err := auth.VerifyPassword(req.CurrentPassword, credential.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.lockout.Failed(userID, ErrInvalidCredentials)
+	}
+
+	hash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.credentialRepo.SetPassword(userID, hash); err != nil {
+		return err
+	}
+
+	logger.Info("Password changed", zap.String("user_id", userID.String()))
+	return s.LogoutAll(userID)
+}
+
+// IssueTokens opens a new session for a user
+func (s *AuthService) IssueTokens(user *models.User) (*models.TokenPair, error) {
+	pair, _, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Session opened", zap.String("user_id", user.ID.String()))
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (s *AuthService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	stored, err := s.storedRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		if stored.ReplacedBy != nil {
+			s.revokeAll(stored.UserID, "refresh token reuse detected")
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Deleted users cannot refresh their sessions
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	pair, replacementID, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.refreshTokenRepo.Rotate(stored.ID, replacementID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// A concurrent refresh won the race; the same token was used twice
+		s.revokeAll(stored.UserID, "concurrent refresh token use detected")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	logger.Debug("Session refreshed", zap.String("user_id", stored.UserID.String()))
+	return pair, nil
+}
+
+// Logout revokes the session of a refresh token
+func (s *AuthService) Logout(refreshToken string) error {
+	stored, err := s.storedRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.refreshTokenRepo.Revoke(stored.ID); err != nil {
+		return err
+	}
+
+	logger.Info("Session closed", zap.String("user_id", stored.UserID.String()))
+	return nil
+}
+
+// LogoutAll revokes every session of a user
+func (s *AuthService) LogoutAll(userID uuid.UUID) error {
+	revoked, err := s.refreshTokenRepo.RevokeAllForUser(userID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("All sessions closed", zap.String("user_id", userID.String()), zap.Int64("sessions", revoked))
+	return nil
+}
+
+// VerifyAccessToken validates an access token and returns its claims
+func (s *AuthService) VerifyAccessToken(token string) (*auth.Claims, error) {
+	return s.tokens.Verify(token, auth.TokenTypeAccess)
+}
+
+// PurgeExpired deletes refresh tokens that can no longer be used
+func (s *AuthService) PurgeExpired() {
+	deleted, err := s.refreshTokenRepo.DeleteExpired(time.Now())
+	if err != nil {
+		logger.Error("Failed to purge expired refresh tokens", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Expired refresh tokens purged", zap.Int64("count", deleted))
+	}
+}
+
+func (s *AuthService) issue(user *models.User) (*models.TokenPair, uuid.UUID, error) {
+	accessClaims := s.tokens.NewClaims(user.ID, auth.TokenTypeAccess, s.settings.AccessTokenTTL)
+	accessClaims.Role = user.Role
+
+	accessToken, err := s.tokens.Sign(accessClaims)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	refreshToken, refreshClaims, err := s.tokens.Issue(user.ID, auth.TokenTypeRefresh, s.settings.RefreshTokenTTL)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+
+	refreshID, err := uuid.Parse(refreshClaims.ID)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+
+	stored := &models.RefreshToken{
+		ID:        refreshID,
+		UserID:    user.ID,
+		ExpiresAt: time.Unix(refreshClaims.ExpiresAt, 0),
+	}
+	if err := s.refreshTokenRepo.Create(stored); err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             time.Unix(accessClaims.ExpiresAt, 0),
+		RefreshTokenExpiresAt: timePtr(stored.ExpiresAt),
+	}, refreshID, nil
+}
+
+// storedRefreshToken verifies a refresh token and loads its server-side record
+func (s *AuthService) storedRefreshToken(refreshToken string) (*models.RefreshToken, error) {
+	claims, err := s.tokens.Verify(refreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := s.refreshTokenRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UserID.String() != claims.Subject {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return stored, nil
+}
+
+func (s *AuthService) revokeAll(userID uuid.UUID, reason string) {
+	logger.Warn("Revoking all sessions", zap.String("user_id", userID.String()), zap.String("reason", reason))
+	if _, err := s.refreshTokenRepo.RevokeAllForUser(userID); err != nil {
+		logger.Error("Failed to revoke sessions", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -1,14 +1,18 @@
 package services
 
 import (
+	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/repositories"
 	"github.com/crypto-bank/bank-service/pkg/logger"
-	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/shared/events"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -16,28 +20,84 @@ import (
 type CryptoWalletService struct {
 	walletRepo *repositories.CryptoWalletRepository
 	userRepo   *repositories.UserRepository
-	rabbitMQ   *rabbitmq.Client
+	txRepo     *repositories.TransactionRepository
+	db         *sql.DB
+	outboxRepo *repositories.OutboxRepository
+	approvals  *ApprovalService
+	treasury   *TreasuryService
+	assets     *AssetService
+	limits     *LimitService
+	screening  *ScreeningService
+	risk       *RiskService
 }
 
 func NewCryptoWalletService(
 	walletRepo *repositories.CryptoWalletRepository,
 	userRepo *repositories.UserRepository,
-	rabbitMQ *rabbitmq.Client,
+	txRepo *repositories.TransactionRepository,
+	db *sql.DB,
+	outboxRepo *repositories.OutboxRepository,
+	approvals *ApprovalService,
+	treasury *TreasuryService,
+	assets *AssetService,
+	limits *LimitService,
+	screening *ScreeningService,
+	risk *RiskService,
 ) *CryptoWalletService {
-	return &CryptoWalletService{
+	s := &CryptoWalletService{
 		walletRepo: walletRepo,
 		userRepo:   userRepo,
-		rabbitMQ:   rabbitMQ,
+		txRepo:     txRepo,
+		db:         db,
+		outboxRepo: outboxRepo,
+		approvals:  approvals,
+		treasury:   treasury,
+		assets:     assets,
+		limits:     limits,
+		screening:  screening,
+		risk:       risk,
 	}
+
+	approvals.RegisterExecutor(models.ApprovalOpCryptoWithdraw, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.WithdrawCryptoRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid withdrawal payload: %w", err)
+		}
+		transaction, err := s.executeWithdraw(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	// Released withdrawals still go through the approval policy of the wallet
+	screening.RegisterExecutor(models.ScreeningOpCryptoWithdraw, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.WithdrawCryptoRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid withdrawal payload: %w", err)
+		}
+		transaction, err := s.guardWithdraw(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	return s
 }
 
 // CreateWallet creates a new crypto wallet
-func (s *CryptoWalletService) CreateWallet(req *models.CreateCryptoWalletRequest) (*models.CryptoWallet, error) {
+func (s *CryptoWalletService) CreateWallet(ctx context.Context, req *models.CreateCryptoWalletRequest) (*models.CryptoWallet, error) {
 	logger.Info("Creating crypto wallet",
 		zap.String("user_id", req.UserID.String()),
 		zap.String("crypto_type", string(req.CryptoType)),
 	)
 
+	asset, err := s.assets.Require(string(req.CryptoType), models.AssetKindCrypto)
+	if err != nil {
+		return nil, err
+	}
+
 	// Verify user exists
 	user, err := s.userRepo.GetByID(req.UserID)
 	if err != nil {
@@ -45,7 +105,7 @@ func (s *CryptoWalletService) CreateWallet(req *models.CreateCryptoWalletRequest
 	}
 
 	// Generate wallet address
-	address := s.generateWalletAddress(user.ID, req.CryptoType)
+	address := s.generateWalletAddress(user.ID, asset)
 
 	wallet := &models.CryptoWallet{
 		UserID:     req.UserID,
@@ -54,18 +114,33 @@ func (s *CryptoWalletService) CreateWallet(req *models.CreateCryptoWalletRequest
 		Address:    address,
 	}
 
-	if err := s.walletRepo.Create(wallet); err != nil {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	if err := s.walletRepo.WithTx(dbTx).Create(wallet); err != nil {
 		logger.Error("Failed to create crypto wallet", zap.Error(err))
 		return nil, err
 	}
 
-	// Publish event
-	event := rabbitmq.WalletEvent{
+	msg, err := newOutboxMessage(ctx, events.WalletCreated, wallet.ID.String(), events.WalletEvent{
 		WalletID:   wallet.ID.String(),
 		UserID:     wallet.UserID.String(),
 		CryptoType: string(wallet.CryptoType),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.outboxRepo.WithTx(dbTx).Add(msg); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	s.rabbitMQ.PublishEvent(rabbitmq.ExchangeEvents, rabbitmq.EventWalletCreated, event)
 
 	logger.Info("Crypto wallet created", zap.String("wallet_id", wallet.ID.String()))
 	return wallet, nil
@@ -86,28 +161,270 @@ func (s *CryptoWalletService) GetWalletBalance(id uuid.UUID) (float64, error) {
 	return s.walletRepo.GetBalance(id)
 }
 
+// WithdrawCrypto withdraws cryptocurrency from a wallet to an external address.
+// Withdrawals to a blocked address are held and return *ScreeningHeldError;
+// withdrawals covered by an approval policy are parked and return *ApprovalRequiredError.
+func (s *CryptoWalletService) WithdrawCrypto(ctx context.Context, req *models.WithdrawCryptoRequest) (*models.Transaction, error) {
+	logger.Info("Creating crypto withdrawal",
+		zap.String("wallet", req.WalletID.String()),
+		zap.String("to_address", req.ToAddress),
+		zap.Float64("amount", req.Amount),
+	)
+
+	wallet, err := s.walletRepo.GetByID(req.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if wallet.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	asset, err := s.assets.Require(string(wallet.CryptoType), models.AssetKindCrypto)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := asset.ValidateWithdrawal(req.ToAddress, req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkWhitelist(wallet.ID, req.ToAddress); err != nil {
+		return nil, err
+	}
+
+	if wallet.Balance < req.Amount {
+		return nil, fmt.Errorf("insufficient balance: have %f, need %f", wallet.Balance, req.Amount)
+	}
+
+	if err := s.limits.Check(req.UserID, models.LimitOpWithdrawal, string(wallet.CryptoType), req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.risk.Assess(ctx, &RiskCheck{
+		UserID:             req.UserID,
+		Operation:          models.RiskOpCryptoWithdraw,
+		Amount:             req.Amount,
+		Currency:           string(wallet.CryptoType),
+		Client:             req.Client,
+		SteppedUp:          req.SteppedUp,
+		BeneficiaryAddress: &req.ToAddress,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.screening.ScreenAddress(req.UserID, req.ToAddress, req.Amount, string(wallet.CryptoType), req); err != nil {
+		return nil, err
+	}
+
+	return s.guardWithdraw(ctx, req)
+}
+
+// guardWithdraw parks the withdrawal if the wallet policy asks for approvals
+// and executes it otherwise
+func (s *CryptoWalletService) guardWithdraw(ctx context.Context, req *models.WithdrawCryptoRequest) (*models.Transaction, error) {
+	wallet, err := s.walletRepo.GetByID(req.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	if err := s.approvals.Guard(wallet.UserID, ApprovalTarget{WalletID: &wallet.ID},
+		models.ApprovalOpCryptoWithdraw, req.Amount, string(wallet.CryptoType), req); err != nil {
+		return nil, err
+	}
+
+	return s.executeWithdraw(ctx, req)
+}
+
+// GetWhitelistedAddresses retrieves the withdrawal whitelist of a wallet of the user
+func (s *CryptoWalletService) GetWhitelistedAddresses(userID, walletID uuid.UUID) ([]*models.WhitelistedAddress, error) {
+	if _, err := s.ownedWallet(userID, walletID); err != nil {
+		return nil, err
+	}
+	return s.walletRepo.GetWhitelistedAddresses(walletID)
+}
+
+// AddWhitelistedAddress adds an external address to the withdrawal whitelist of a wallet of the user
+func (s *CryptoWalletService) AddWhitelistedAddress(
+	userID, walletID uuid.UUID,
+	req *models.AddWhitelistedAddressRequest,
+) (*models.WhitelistedAddress, error) {
+	wallet, err := s.ownedWallet(userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := s.assets.Require(string(wallet.CryptoType), models.AssetKindCrypto)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := asset.ValidateAddress(req.Address); err != nil {
+		return nil, err
+	}
+
+	entry := &models.WhitelistedAddress{
+		WalletID: walletID,
+		Address:  req.Address,
+		Label:    req.Label,
+	}
+	if err := s.walletRepo.AddWhitelistedAddress(entry); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Withdrawal address whitelisted",
+		zap.String("wallet_id", walletID.String()),
+		zap.String("address", entry.Address),
+	)
+	return entry, nil
+}
+
+// RemoveWhitelistedAddress removes an address from the withdrawal whitelist of a wallet of the user
+func (s *CryptoWalletService) RemoveWhitelistedAddress(userID, walletID, entryID uuid.UUID) error {
+	if _, err := s.ownedWallet(userID, walletID); err != nil {
+		return err
+	}
+
+	if err := s.walletRepo.DeleteWhitelistedAddress(walletID, entryID); err != nil {
+		return err
+	}
+
+	logger.Info("Withdrawal address removed from whitelist",
+		zap.String("wallet_id", walletID.String()),
+		zap.String("entry_id", entryID.String()),
+	)
+	return nil
+}
+
+func (s *CryptoWalletService) ownedWallet(userID, walletID uuid.UUID) (*models.CryptoWallet, error) {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+	if wallet.UserID != userID {
+		return nil, ErrOwnershipMismatch
+	}
+	return wallet, nil
+}
+
+// checkWhitelist only lets withdrawals through to whitelisted addresses once a
+// wallet has a whitelist
+func (s *CryptoWalletService) checkWhitelist(walletID uuid.UUID, address string) error {
+	entries, err := s.walletRepo.GetWhitelistedAddresses(walletID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.Address == address {
+			return nil
+		}
+	}
+	return fmt.Errorf("address %s is not on the withdrawal whitelist", address)
+}
+
+// executeWithdraw pays out a crypto withdrawal that passed all checks. A
+// withdrawal that fails on the way is recorded as failed.
+func (s *CryptoWalletService) executeWithdraw(ctx context.Context, req *models.WithdrawCryptoRequest) (*models.Transaction, error) {
+	transaction, err := s.withdraw(ctx, req)
+	if err != nil {
+		recordFailedTransaction(ctx, s.db, s.txRepo, s.outboxRepo, transaction, err)
+		return nil, err
+	}
+
+	// Update metrics
+	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
+
+	logger.Info("Crypto withdrawal completed", zap.String("transaction_id", transaction.ID.String()))
+	return transaction, nil
+}
+
+// withdraw debits a wallet and the hot wallet in one database transaction. On
+// failure it returns the transaction as far as it got, or nil if it failed
+// before there was one.
+func (s *CryptoWalletService) withdraw(ctx context.Context, req *models.WithdrawCryptoRequest) (*models.Transaction, error) {
+	// Start database transaction
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	walletRepo := s.walletRepo.WithTx(dbTx)
+	txRepo := s.txRepo.WithTx(dbTx)
+	outboxRepo := s.outboxRepo.WithTx(dbTx)
+
+	wallet, err := walletRepo.GetByID(req.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	toAddress := req.ToAddress
+	transaction := &models.Transaction{
+		UserID:       wallet.UserID,
+		Type:         models.TransactionTypeWithdraw,
+		Status:       models.TransactionStatusPending,
+		Amount:       req.Amount,
+		Currency:     string(wallet.CryptoType),
+		FromWalletID: &req.WalletID,
+		ToAddress:    &toAddress,
+		Description:  fmt.Sprintf("Withdrawal to %s", req.ToAddress),
+	}
+
+	if wallet.Balance < req.Amount {
+		return transaction, fmt.Errorf("insufficient balance: have %f, need %f", wallet.Balance, req.Amount)
+	}
+
+	if err := s.limits.CheckTx(dbTx, wallet.UserID, models.LimitOpWithdrawal, string(wallet.CryptoType), req.Amount); err != nil {
+		return transaction, err
+	}
+
+	if err := txRepo.Create(transaction); err != nil {
+		return transaction, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+		return transaction, err
+	}
+
+	// Withdrawals are paid out of the house hot wallet
+	if err := s.treasury.DebitHotWallet(dbTx, wallet.CryptoType, req.Amount); err != nil {
+		return transaction, err
+	}
+
+	if err := changeWalletBalance(ctx, walletRepo, outboxRepo, wallet, -req.Amount, transaction.ID); err != nil {
+		return transaction, err
+	}
+
+	if err := txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
+		return transaction, fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	transaction.Status = models.TransactionStatusCompleted
+
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCompleted, transaction, ""); err != nil {
+		return transaction, err
+	}
+
+	// Commit database transaction
+	if err := dbTx.Commit(); err != nil {
+		return transaction, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
 // generateWalletAddress generates a unique wallet address
-func (s *CryptoWalletService) generateWalletAddress(userID uuid.UUID, cryptoType models.CryptoType) string {
-	data := fmt.Sprintf("%s-%s-%s", userID.String(), cryptoType, uuid.New().String())
+func (s *CryptoWalletService) generateWalletAddress(userID uuid.UUID, asset *models.Asset) string {
+	data := fmt.Sprintf("%s-%s-%s", userID.String(), asset.Code, uuid.New().String())
+	return generateAddress(asset.AddressPrefix, data)
+}
+
+// generateAddress derives an address in the asset's format from seed data
+func generateAddress(prefix, data string) string {
 	hash := sha256.Sum256([]byte(data))
-	
-	// Add prefix based on crypto type
-	var prefix string
-	switch cryptoType {
-	case models.CryptoBTC:
-		prefix = "1"
-	case models.CryptoETH:
-		prefix = "0x"
-	case models.CryptoUSDT:
-		prefix = "0x"
-	case models.CryptoBNB:
-		prefix = "0x"
-	case models.CryptoSOL:
-		prefix = ""
-	default:
-		prefix = ""
-	}
-	
 	return prefix + hex.EncodeToString(hash[:20])
 }
-
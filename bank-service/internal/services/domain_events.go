@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/shared/events"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// addTransactionEvent stores an event about a transaction in the outbox. The
+// reason is only set on failures.
+func addTransactionEvent(ctx context.Context, outboxRepo *repositories.OutboxRepository, eventType string, transaction *models.Transaction, reason string) error {
+	msg, err := newOutboxMessage(ctx, eventType, transaction.ID.String(), events.TransactionEvent{
+		TransactionID: transaction.ID.String(),
+		UserID:        transaction.UserID.String(),
+		Type:          string(transaction.Type),
+		Amount:        transaction.Amount,
+		Currency:      transaction.Currency,
+		Status:        string(transaction.Status),
+		Reason:        reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.Add(msg)
+}
+
+// addExchangeEvent stores an event about an exchange in the outbox. The reason
+// is only set on failures.
+func addExchangeEvent(ctx context.Context, outboxRepo *repositories.OutboxRepository, eventType string, exchange *models.Exchange, reason string) error {
+	msg, err := newOutboxMessage(ctx, eventType, exchange.ID.String(), events.ExchangeEvent{
+		ExchangeID:   exchange.ID.String(),
+		UserID:       exchange.UserID.String(),
+		FromCurrency: exchange.FromCurrency,
+		ToCurrency:   exchange.ToCurrency,
+		FromAmount:   exchange.FromAmount,
+		ToAmount:     exchange.ToAmount,
+		Status:       string(exchange.Status),
+		Reason:       reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.Add(msg)
+}
+
+// changeAccountBalance moves the balance of an account by delta and announces
+// the new balance. Both repositories must run in the same database transaction.
+func changeAccountBalance(ctx context.Context, accountRepo *repositories.AccountRepository, outboxRepo *repositories.OutboxRepository,
+	account *models.Account, delta float64, transactionID uuid.UUID) error {
+	balance, err := accountRepo.UpdateBalance(account.ID, delta)
+	if err != nil {
+		return fmt.Errorf("failed to update account balance: %w", err)
+	}
+
+	msg, err := newOutboxMessage(ctx, events.AccountBalanceChanged, account.ID.String(), events.AccountBalanceEvent{
+		AccountID:     account.ID.String(),
+		UserID:        account.UserID.String(),
+		Currency:      string(account.Currency),
+		Delta:         delta,
+		Balance:       balance,
+		TransactionID: transactionID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.Add(msg)
+}
+
+// changeWalletBalance moves the balance of a crypto wallet by delta and
+// announces the new balance. Both repositories must run in the same database
+// transaction.
+func changeWalletBalance(ctx context.Context, walletRepo *repositories.CryptoWalletRepository, outboxRepo *repositories.OutboxRepository,
+	wallet *models.CryptoWallet, delta float64, transactionID uuid.UUID) error {
+	balance, err := walletRepo.UpdateBalance(wallet.ID, delta)
+	if err != nil {
+		return fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+
+	msg, err := newOutboxMessage(ctx, events.WalletBalanceChanged, wallet.ID.String(), events.WalletBalanceEvent{
+		WalletID:      wallet.ID.String(),
+		UserID:        wallet.UserID.String(),
+		CryptoType:    string(wallet.CryptoType),
+		Delta:         delta,
+		Balance:       balance,
+		TransactionID: transactionID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.Add(msg)
+}
+
+// recordFailedTransaction stores a transaction that failed once it was under
+// way, with its created and failed events. The attempt itself rolled back, so
+// this runs in a database transaction of its own. Errors are only logged since
+// the caller reports the original failure.
+func recordFailedTransaction(ctx context.Context, db *sql.DB, txRepo *repositories.TransactionRepository,
+	outboxRepo *repositories.OutboxRepository, transaction *models.Transaction, cause error) {
+	if transaction == nil {
+		return
+	}
+
+	err := func() error {
+		dbTx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %w", err)
+		}
+		defer dbTx.Rollback()
+
+		txRepo := txRepo.WithTx(dbTx)
+		outboxRepo := outboxRepo.WithTx(dbTx)
+
+		transaction.Status = models.TransactionStatusPending
+		if err := txRepo.Create(transaction); err != nil {
+			return err
+		}
+		if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+			return err
+		}
+
+		if err := txRepo.UpdateStatus(transaction.ID, models.TransactionStatusFailed); err != nil {
+			return err
+		}
+		transaction.Status = models.TransactionStatusFailed
+		if err := addTransactionEvent(ctx, outboxRepo, events.TransactionFailed, transaction, cause.Error()); err != nil {
+			return err
+		}
+
+		return dbTx.Commit()
+	}()
+	if err != nil {
+		logger.Error("Failed to record failed transaction",
+			zap.String("user_id", transaction.UserID.String()),
+			zap.String("type", string(transaction.Type)),
+			zap.NamedError("cause", cause),
+			zap.Error(err),
+		)
+		return
+	}
+
+	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
+	logger.Warn("Transaction failed",
+		zap.String("transaction_id", transaction.ID.String()),
+		zap.String("type", string(transaction.Type)),
+		zap.Error(cause),
+	)
+}
+
+// addFailedExchange stores an exchange that failed before any funds moved,
+// with its created and failed events. Both repositories must run in the same
+// database transaction.
+func addFailedExchange(ctx context.Context, exchangeRepo *repositories.ExchangeRepository, outboxRepo *repositories.OutboxRepository,
+	exchange *models.Exchange, cause error) error {
+	exchange.Status = models.ExchangeStatusPending
+	exchange.TransactionID = nil
+	if err := exchangeRepo.Create(exchange); err != nil {
+		return err
+	}
+	if err := addExchangeEvent(ctx, outboxRepo, events.ExchangeCreated, exchange, ""); err != nil {
+		return err
+	}
+
+	if err := exchangeRepo.UpdateStatus(exchange.ID, models.ExchangeStatusFailed); err != nil {
+		return err
+	}
+	exchange.Status = models.ExchangeStatusFailed
+	return addExchangeEvent(ctx, outboxRepo, events.ExchangeFailed, exchange, cause.Error())
+}
+
+// exchangeFailed counts and logs an exchange recorded as failed
+func exchangeFailed(exchange *models.Exchange, cause error) {
+	metrics.ExchangesTotal.WithLabelValues(string(exchange.Type), string(exchange.Status)).Inc()
+	logger.Warn("Exchange failed",
+		zap.String("exchange_id", exchange.ID.String()),
+		zap.String("type", string(exchange.Type)),
+		zap.Error(cause),
+	)
+}
@@ -0,0 +1,11 @@
+package services
+
+import "errors"
+
+var (
+	// ErrOwnershipMismatch is returned when a user operates on a record of another user
+	ErrOwnershipMismatch = errors.New("ownership mismatch")
+
+	// ErrStepUpRequired is returned when an operation needs a recent second factor
+	ErrStepUpRequired = errors.New("step-up authentication required")
+)
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/replay"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// EventStoreSettings configures replays from the event store
+type EventStoreSettings struct {
+	// ReplayBatchSize is the number of stored events read at a time
+	ReplayBatchSize int
+	PublishTimeout  time.Duration
+	// ContentMode is the CloudEvents content mode replayed events are published in
+	ContentMode events.ContentMode
+}
+
+// EventStoreService reads the event store and replays stored events, so that
+// consumers can rebuild state they lost. Replayed events go to their own
+// exchange under their original routing key and message ID; consumers tell
+// them from live events by the exchange and drop those they already applied.
+type EventStoreService struct {
+	eventStoreRepo *repositories.EventStoreRepository
+	rabbitMQ       *broker.Client
+	settings       EventStoreSettings
+}
+
+func NewEventStoreService(eventStoreRepo *repositories.EventStoreRepository, rabbitMQ *broker.Client, settings EventStoreSettings) *EventStoreService {
+	return &EventStoreService{
+		eventStoreRepo: eventStoreRepo,
+		rabbitMQ:       rabbitMQ,
+		settings:       settings,
+	}
+}
+
+// GetEvents lists stored events in the order they were added
+func (s *EventStoreService) GetEvents(filter *models.EventFilter) ([]*models.StoredEvent, error) {
+	return s.eventStoreRepo.List(filter)
+}
+
+// Replay re-publishes the selected events in the order they were added, up to
+// the limit of the request. Events the relay has not published yet are left
+// out. The first failed publish ends the replay; the result then tells how far
+// it got.
+func (s *EventStoreService) Replay(ctx context.Context, req *models.ReplayRequest) (*models.ReplayResult, error) {
+	result := &models.ReplayResult{
+		ReplayID:  uuid.New(),
+		StartedAt: time.Now().UTC(),
+	}
+
+	filter := &models.EventFilter{
+		From:          req.From,
+		To:            req.To,
+		Subject:       req.Subject,
+		UserID:        req.UserID,
+		Types:         req.Types,
+		AfterPosition: req.AfterPosition,
+		// An event replayed before its live copy arrives would make consumers
+		// drop the live one
+		Published: true,
+	}
+
+	err := func() error {
+		for remaining := req.Limit; remaining > 0; {
+			filter.Limit = uint64(s.settings.ReplayBatchSize)
+			if remaining < filter.Limit {
+				filter.Limit = remaining
+			}
+
+			stored, err := s.eventStoreRepo.List(filter)
+			if err != nil {
+				return err
+			}
+
+			for _, event := range stored {
+				routed, err := s.publish(ctx, result.ReplayID, event)
+				if err != nil {
+					return fmt.Errorf("failed to replay event %s at position %d: %w", event.EventID, event.Position, err)
+				}
+
+				if routed {
+					result.Published++
+					metrics.EventsReplayedTotal.WithLabelValues("published").Inc()
+				} else {
+					result.Unroutable++
+					metrics.EventsReplayedTotal.WithLabelValues("unroutable").Inc()
+				}
+				if result.FirstPosition == 0 {
+					result.FirstPosition = event.Position
+				}
+				result.LastPosition = event.Position
+			}
+
+			if uint64(len(stored)) < filter.Limit {
+				result.Complete = true
+				return nil
+			}
+			filter.AfterPosition = result.LastPosition
+			remaining -= uint64(len(stored))
+		}
+		return nil
+	}()
+	result.FinishedAt = time.Now().UTC()
+
+	fields := []zap.Field{
+		zap.String("replay_id", result.ReplayID.String()),
+		zap.Int64("published", result.Published),
+		zap.Int64("unroutable", result.Unroutable),
+		zap.Int64("first_position", result.FirstPosition),
+		zap.Int64("last_position", result.LastPosition),
+		zap.Bool("complete", result.Complete),
+	}
+	if err != nil {
+		logger.Error("Event replay failed", append(fields, zap.Error(err))...)
+		return result, err
+	}
+
+	logger.Info("Events replayed", fields...)
+	return result, nil
+}
+
+// publish sends a stored event to the replay exchange. It reports false for
+// events no replay queue is bound for.
+func (s *EventStoreService) publish(ctx context.Context, replayID uuid.UUID, event *models.StoredEvent) (bool, error) {
+	var envelope events.Envelope
+	if err := json.Unmarshal(event.Payload, &envelope); err != nil {
+		return false, fmt.Errorf("failed to parse stored event: %w", err)
+	}
+
+	publishing, err := events.ToAMQP(&envelope, s.settings.ContentMode)
+	if err != nil {
+		return false, err
+	}
+	if publishing.Headers == nil {
+		publishing.Headers = amqp.Table{}
+	}
+	publishing.Headers[replay.HeaderReplayID] = replayID.String()
+	publishing.DeliveryMode = amqp.Persistent
+
+	ctx, cancel := context.WithTimeout(ctx, s.settings.PublishTimeout)
+	defer cancel()
+
+	err = s.rabbitMQ.Publish(ctx, rabbitmq.ExchangeReplay, event.Type, true, publishing)
+	if errors.Is(err, broker.ErrUnroutable) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,914 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/quotes"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/shared/events"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ExchangeSagaSettings configures how exchange sagas run and recover
+type ExchangeSagaSettings struct {
+	// Lease is how long the instance running a saga owns it; the recovery worker
+	// resumes sagas whose lease ran out
+	Lease time.Duration
+	// StepTimeout bounds the calls to the quote locker
+	StepTimeout time.Duration
+	// RetryBaseDelay doubles with every failed attempt up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// RecoveryBatchSize is the number of sagas the recovery worker claims at a time
+	RecoveryBatchSize int
+}
+
+// ExchangeSagaService runs exchanges as sagas. The quote is held by the quote
+// locker while the funds move in the bank database, so an exchange cannot be
+// one database transaction. It runs in steps instead:
+//
+//   - LOCK_QUOTE locks the rate for the exchange
+//   - RESERVE_FUNDS creates the exchange and its transaction and debits the source
+//   - SETTLE credits the destination and completes the exchange
+//   - RELEASE frees the quote
+//
+// Every step stores its outcome in the saga row, the database steps in the
+// same transaction as their changes. A step failing before SETTLE undoes the
+// steps before it in reverse, refunding the source and marking the exchange
+// failed. Once settled the exchange stands; RELEASE is retried until it
+// succeeds. Sagas an instance stopped running, because it crashed or a
+// compensation failed, are resumed by the recovery worker.
+type ExchangeSagaService struct {
+	sagaRepo     sagaStore
+	exchangeRepo *repositories.ExchangeRepository
+	accountRepo  *repositories.AccountRepository
+	walletRepo   *repositories.CryptoWalletRepository
+	ledger       sagaLedger
+	assets       *AssetService
+	quotes       quotes.Locker
+	settings     ExchangeSagaSettings
+}
+
+// sagaStore keeps sagas and their history; it is implemented by
+// repositories.SagaRepository
+type sagaStore interface {
+	Create(saga *models.ExchangeSaga) error
+	GetByID(id uuid.UUID) (*models.ExchangeSaga, error)
+	Update(saga *models.ExchangeSaga, status models.SagaStatus, step models.SagaStep) error
+	Claim(limit int, now, until time.Time) ([]*models.ExchangeSaga, error)
+	AddStep(sagaID uuid.UUID, record *models.SagaStepRecord) error
+	GetSteps(sagaID uuid.UUID) ([]*models.SagaStepRecord, error)
+}
+
+// sagaLedger runs the steps of a saga that move funds in the bank database.
+// Each step commits together with the saga moving on.
+type sagaLedger interface {
+	reserveFunds(ctx context.Context, saga *models.ExchangeSaga) error
+	settle(ctx context.Context, saga *models.ExchangeSaga) error
+	refund(ctx context.Context, saga *models.ExchangeSaga) error
+}
+
+func NewExchangeSagaService(
+	sagaRepo *repositories.SagaRepository,
+	exchangeRepo *repositories.ExchangeRepository,
+	accountRepo *repositories.AccountRepository,
+	walletRepo *repositories.CryptoWalletRepository,
+	txRepo *repositories.TransactionRepository,
+	outboxRepo *repositories.OutboxRepository,
+	db *sql.DB,
+	assets *AssetService,
+	limits *LimitService,
+	quoteLocker quotes.Locker,
+	settings ExchangeSagaSettings,
+) *ExchangeSagaService {
+	return &ExchangeSagaService{
+		sagaRepo:     sagaRepo,
+		exchangeRepo: exchangeRepo,
+		accountRepo:  accountRepo,
+		walletRepo:   walletRepo,
+		ledger: &sagaDB{
+			db:           db,
+			sagaRepo:     sagaRepo,
+			exchangeRepo: exchangeRepo,
+			accountRepo:  accountRepo,
+			walletRepo:   walletRepo,
+			txRepo:       txRepo,
+			outboxRepo:   outboxRepo,
+			limits:       limits,
+			lease:        settings.Lease,
+		},
+		assets:   assets,
+		quotes:   quoteLocker,
+		settings: settings,
+	}
+}
+
+// CryptoToFiat runs a crypto to fiat exchange that passed all checks
+func (s *ExchangeSagaService) CryptoToFiat(ctx context.Context, req *models.ExchangeCryptoToFiatRequest) (*models.Exchange, error) {
+	wallet, err := s.walletRepo.GetByID(req.FromWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	account, err := s.accountRepo.GetByID(req.ToAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	// Verify ownership
+	if wallet.UserID != req.UserID || account.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	if err := s.validateAssets(wallet, account, models.AssetKindCrypto, req.CryptoAmount); err != nil {
+		return nil, err
+	}
+
+	return s.start(ctx, &models.ExchangeSaga{
+		UserID:       req.UserID,
+		Type:         models.ExchangeCryptoToFiat,
+		FromCurrency: string(wallet.CryptoType),
+		ToCurrency:   string(account.Currency),
+		FromAmount:   req.CryptoAmount,
+		FromWalletID: &req.FromWalletID,
+		ToAccountID:  &req.ToAccountID,
+	})
+}
+
+// FiatToCrypto runs a fiat to crypto exchange that passed all checks
+func (s *ExchangeSagaService) FiatToCrypto(ctx context.Context, req *models.ExchangeFiatToCryptoRequest) (*models.Exchange, error) {
+	account, err := s.accountRepo.GetByID(req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	wallet, err := s.walletRepo.GetByID(req.ToWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+
+	// Verify ownership
+	if account.UserID != req.UserID || wallet.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	if err := s.validateAssets(wallet, account, models.AssetKindFiat, req.FiatAmount); err != nil {
+		return nil, err
+	}
+
+	return s.start(ctx, &models.ExchangeSaga{
+		UserID:        req.UserID,
+		Type:          models.ExchangeFiatToCrypto,
+		FromCurrency:  string(account.Currency),
+		ToCurrency:    string(wallet.CryptoType),
+		FromAmount:    req.FiatAmount,
+		FromAccountID: &req.FromAccountID,
+		ToWalletID:    &req.ToWalletID,
+	})
+}
+
+// start stores a new saga and runs it. The exchange is returned once it
+// settled, even if the quote is not released yet.
+func (s *ExchangeSagaService) start(ctx context.Context, saga *models.ExchangeSaga) (*models.Exchange, error) {
+	saga.Status = models.SagaStatusRunning
+	saga.Step = models.SagaStepLockQuote
+	saga.NextAttemptAt = time.Now().Add(s.settings.Lease)
+	if err := s.sagaRepo.Create(saga); err != nil {
+		return nil, err
+	}
+
+	if err := s.run(ctx, saga); err != nil {
+		if !errors.Is(err, repositories.ErrSagaMoved) {
+			return nil, err
+		}
+
+		// A step committed although it seemed to fail, or another instance
+		// took the saga over
+		current, getErr := s.sagaRepo.GetByID(saga.ID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if !current.Settled() {
+			return nil, fmt.Errorf("exchange saga %s is still running", saga.ID)
+		}
+		saga = current
+	}
+
+	return s.exchangeRepo.GetByID(*saga.ExchangeID)
+}
+
+// GetSaga retrieves a saga with the steps it ran or undid
+func (s *ExchangeSagaService) GetSaga(id uuid.UUID) (*models.ExchangeSaga, error) {
+	saga, err := s.sagaRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if saga.History, err = s.sagaRepo.GetSteps(id); err != nil {
+		return nil, err
+	}
+
+	return saga, nil
+}
+
+// Recover resumes sagas whose instance stopped running them and sagas due for
+// another attempt
+func (s *ExchangeSagaService) Recover() {
+	for {
+		now := time.Now()
+		sagas, err := s.sagaRepo.Claim(s.settings.RecoveryBatchSize, now, now.Add(s.settings.Lease))
+		if err != nil {
+			logger.Error("Failed to claim exchange sagas", zap.Error(err))
+			return
+		}
+
+		for _, saga := range sagas {
+			logger.Info("Resuming exchange saga",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("status", string(saga.Status)),
+				zap.String("step", string(saga.Step)),
+			)
+			// A compensated saga was logged when it finished
+			err := s.run(context.Background(), saga)
+			if err != nil && saga.Status != models.SagaStatusCompensated {
+				logger.Warn("Exchange saga did not finish",
+					zap.String("saga_id", saga.ID.String()),
+					zap.String("status", string(saga.Status)),
+					zap.String("step", string(saga.Step)),
+					zap.Error(err),
+				)
+			}
+		}
+
+		if len(sagas) < s.settings.RecoveryBatchSize {
+			return
+		}
+	}
+}
+
+// run drives a saga as far as it goes now. It returns nil once the exchange
+// settled, the error that failed the exchange once it is being undone, and the
+// error that stopped it otherwise. A saga left running or compensating is
+// picked up again by the recovery worker.
+func (s *ExchangeSagaService) run(ctx context.Context, saga *models.ExchangeSaga) error {
+	var cause error
+	if saga.FailureReason != nil {
+		cause = errors.New(*saga.FailureReason)
+	}
+
+	for {
+		step := saga.Step
+		switch saga.Status {
+		case models.SagaStatusRunning:
+			err := s.execute(ctx, saga)
+			if err == nil {
+				continue
+			}
+			if errors.Is(err, repositories.ErrSagaMoved) {
+				return err
+			}
+			s.recordStep(saga, step, models.SagaActionExecute, err)
+
+			// Past the pivot the exchange stands; only the release is left
+			if step == models.SagaStepRelease {
+				return s.retryLater(saga, err)
+			}
+			if updateErr := s.beginCompensation(saga, err); updateErr != nil {
+				return updateErr
+			}
+			cause = err
+
+		case models.SagaStatusCompensating:
+			err := s.compensate(ctx, saga)
+			if err == nil {
+				continue
+			}
+			if errors.Is(err, repositories.ErrSagaMoved) {
+				return err
+			}
+			s.recordStep(saga, step, models.SagaActionCompensate, err)
+			if updateErr := s.retryLater(saga, err); updateErr != nil {
+				return updateErr
+			}
+			return cause
+
+		case models.SagaStatusCompleted:
+			return nil
+
+		case models.SagaStatusCompensated:
+			return cause
+
+		default:
+			return fmt.Errorf("saga in unknown status %s", saga.Status)
+		}
+	}
+}
+
+// execute runs the next step of a saga and moves it on
+func (s *ExchangeSagaService) execute(ctx context.Context, saga *models.ExchangeSaga) error {
+	switch saga.Step {
+	case models.SagaStepLockQuote:
+		return s.lockQuote(ctx, saga)
+	case models.SagaStepReserveFunds:
+		return s.ledger.reserveFunds(ctx, saga)
+	case models.SagaStepSettle:
+		return s.ledger.settle(ctx, saga)
+	case models.SagaStepRelease:
+		return s.release(ctx, saga)
+	}
+	return fmt.Errorf("saga at unknown step %s", saga.Step)
+}
+
+// compensate undoes the next step of a saga being compensated and moves it back
+func (s *ExchangeSagaService) compensate(ctx context.Context, saga *models.ExchangeSaga) error {
+	switch saga.Step {
+	case models.SagaStepReserveFunds:
+		return s.ledger.refund(ctx, saga)
+	case models.SagaStepLockQuote:
+		return s.unlockQuote(ctx, saga)
+	}
+	return fmt.Errorf("saga step %s cannot be undone", saga.Step)
+}
+
+// lockQuote locks the rate of the exchange. The saga ID is the key, so locking
+// again after a crash returns the quote locked before.
+func (s *ExchangeSagaService) lockQuote(ctx context.Context, saga *models.ExchangeSaga) error {
+	ctx, cancel := context.WithTimeout(ctx, s.settings.StepTimeout)
+	defer cancel()
+
+	quote, err := s.quotes.Lock(ctx, &quotes.Request{
+		Key:    saga.ID.String(),
+		From:   saga.FromCurrency,
+		To:     saga.ToCurrency,
+		Amount: saga.FromAmount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock quote: %w", err)
+	}
+
+	next := *saga
+	next.QuoteID = &quote.ID
+	next.ExchangeRate = &quote.Rate
+	next.QuoteExpiresAt = &quote.ExpiresAt
+	return s.advance(saga, &next, models.SagaStepReserveFunds)
+}
+
+// reserveFunds creates the exchange at the locked rate and its transaction and
+// debits the source, if the exchange is within the limits of the user
+func (d *sagaDB) reserveFunds(ctx context.Context, saga *models.ExchangeSaga) error {
+	return d.inTx(saga, func(tx *sagaTx, next *models.ExchangeSaga) error {
+		return d.reserveFundsTx(ctx, tx, next)
+	})
+}
+
+func (d *sagaDB) reserveFundsTx(ctx context.Context, tx *sagaTx, saga *models.ExchangeSaga) error {
+	if err := quoteValid(saga); err != nil {
+		return err
+	}
+
+	exchange := newSagaExchange(saga)
+	transaction := &models.Transaction{
+		UserID: saga.UserID,
+		Type:   models.TransactionTypeExchange,
+		Status: models.TransactionStatusPending,
+		Description: fmt.Sprintf("Exchange %f %s to %s", exchange.FromAmount, exchange.FromCurrency,
+			exchange.ToCurrency),
+	}
+
+	var debit func() error
+	switch saga.Type {
+	case models.ExchangeCryptoToFiat:
+		wallet, err := tx.walletRepo.GetByID(*saga.FromWalletID)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		if wallet.Balance < saga.FromAmount {
+			return fmt.Errorf("insufficient balance: have %f, need %f", wallet.Balance, saga.FromAmount)
+		}
+
+		transaction.Amount = exchange.ToAmount
+		transaction.Currency = exchange.ToCurrency
+		transaction.ToAccountID = saga.ToAccountID
+		debit = func() error {
+			return changeWalletBalance(ctx, tx.walletRepo, tx.outboxRepo, wallet, -saga.FromAmount, transaction.ID)
+		}
+
+	case models.ExchangeFiatToCrypto:
+		account, err := tx.accountRepo.GetByID(*saga.FromAccountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if account.Balance < saga.FromAmount {
+			return fmt.Errorf("insufficient balance: have %f, need %f", account.Balance, saga.FromAmount)
+		}
+
+		transaction.Amount = saga.FromAmount
+		transaction.Currency = exchange.FromCurrency
+		transaction.FromAccountID = saga.FromAccountID
+		debit = func() error {
+			return changeAccountBalance(ctx, tx.accountRepo, tx.outboxRepo, account, -saga.FromAmount, transaction.ID)
+		}
+
+	default:
+		return fmt.Errorf("unknown exchange type %s", saga.Type)
+	}
+
+	if err := d.limits.CheckTx(tx.db, saga.UserID, models.LimitOpExchange, saga.FromCurrency, saga.FromAmount); err != nil {
+		return err
+	}
+
+	// Create exchange record
+	if err := tx.exchangeRepo.Create(exchange); err != nil {
+		return fmt.Errorf("failed to create exchange: %w", err)
+	}
+
+	if err := addExchangeEvent(ctx, tx.outboxRepo, events.ExchangeCreated, exchange, ""); err != nil {
+		return err
+	}
+
+	// Create transaction record
+	transaction.ExchangeID = &exchange.ID
+	if err := tx.txRepo.Create(transaction); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := addTransactionEvent(ctx, tx.outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+		return err
+	}
+
+	if err := debit(); err != nil {
+		return err
+	}
+
+	saga.ExchangeID = &exchange.ID
+	saga.TransactionID = &transaction.ID
+	saga.Step = models.SagaStepSettle
+	return nil
+}
+
+// settle credits the destination and completes the exchange and its
+// transaction. The quote must still hold.
+func (d *sagaDB) settle(ctx context.Context, saga *models.ExchangeSaga) error {
+	return d.inTx(saga, func(tx *sagaTx, next *models.ExchangeSaga) error {
+		return d.settleTx(ctx, tx, next)
+	})
+}
+
+func (d *sagaDB) settleTx(ctx context.Context, tx *sagaTx, saga *models.ExchangeSaga) error {
+	if err := quoteValid(saga); err != nil {
+		return err
+	}
+
+	exchange, err := tx.exchangeRepo.GetByID(*saga.ExchangeID)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := tx.txRepo.GetByID(*saga.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	switch saga.Type {
+	case models.ExchangeCryptoToFiat:
+		account, err := tx.accountRepo.GetByID(*saga.ToAccountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if err := changeAccountBalance(ctx, tx.accountRepo, tx.outboxRepo, account, exchange.ToAmount, transaction.ID); err != nil {
+			return err
+		}
+
+	case models.ExchangeFiatToCrypto:
+		wallet, err := tx.walletRepo.GetByID(*saga.ToWalletID)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		if err := changeWalletBalance(ctx, tx.walletRepo, tx.outboxRepo, wallet, exchange.ToAmount, transaction.ID); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown exchange type %s", saga.Type)
+	}
+
+	if err := tx.txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	transaction.Status = models.TransactionStatusCompleted
+
+	if err := addTransactionEvent(ctx, tx.outboxRepo, events.TransactionCompleted, transaction, ""); err != nil {
+		return err
+	}
+
+	// Update exchange status
+	if err := tx.exchangeRepo.UpdateStatus(exchange.ID, models.ExchangeStatusCompleted); err != nil {
+		return fmt.Errorf("failed to update exchange status: %w", err)
+	}
+	exchange.Status = models.ExchangeStatusCompleted
+	exchange.TransactionID = &transaction.ID
+
+	if err := addExchangeEvent(ctx, tx.outboxRepo, events.ExchangeCompleted, exchange, ""); err != nil {
+		return err
+	}
+
+	saga.Step = models.SagaStepRelease
+	return nil
+}
+
+// release frees the quote the exchange settled on and completes the saga
+func (s *ExchangeSagaService) release(ctx context.Context, saga *models.ExchangeSaga) error {
+	if err := s.releaseQuote(ctx, saga); err != nil {
+		return err
+	}
+
+	next := *saga
+	next.Status = models.SagaStatusCompleted
+	if err := s.advance(saga, &next, models.SagaStepDone); err != nil {
+		return err
+	}
+
+	metrics.ExchangeSagasTotal.WithLabelValues("completed").Inc()
+	return nil
+}
+
+// refund undoes RESERVE_FUNDS. If the funds were reserved, the source gets them
+// back and the exchange and its transaction fail. Otherwise the reservation
+// was refused, and the exchange is recorded as failed if it got a rate.
+func (d *sagaDB) refund(ctx context.Context, saga *models.ExchangeSaga) error {
+	return d.inTx(saga, func(tx *sagaTx, next *models.ExchangeSaga) error {
+		return d.refundTx(ctx, tx, next)
+	})
+}
+
+func (d *sagaDB) refundTx(ctx context.Context, tx *sagaTx, saga *models.ExchangeSaga) error {
+	cause := errors.New(*saga.FailureReason)
+	saga.Step = models.SagaStepLockQuote
+
+	if saga.TransactionID == nil {
+		if saga.ExchangeRate == nil || saga.ExchangeID != nil {
+			return nil
+		}
+		exchange := newSagaExchange(saga)
+		if err := addFailedExchange(ctx, tx.exchangeRepo, tx.outboxRepo, exchange, cause); err != nil {
+			return err
+		}
+		saga.ExchangeID = &exchange.ID
+		tx.onCommit(func() { exchangeFailed(exchange, cause) })
+		return nil
+	}
+
+	exchange, err := tx.exchangeRepo.GetByID(*saga.ExchangeID)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := tx.txRepo.GetByID(*saga.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	switch saga.Type {
+	case models.ExchangeCryptoToFiat:
+		wallet, err := tx.walletRepo.GetByID(*saga.FromWalletID)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		if err := changeWalletBalance(ctx, tx.walletRepo, tx.outboxRepo, wallet, saga.FromAmount, transaction.ID); err != nil {
+			return err
+		}
+
+	case models.ExchangeFiatToCrypto:
+		account, err := tx.accountRepo.GetByID(*saga.FromAccountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if err := changeAccountBalance(ctx, tx.accountRepo, tx.outboxRepo, account, saga.FromAmount, transaction.ID); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown exchange type %s", saga.Type)
+	}
+
+	if err := tx.txRepo.UpdateStatus(transaction.ID, models.TransactionStatusFailed); err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	transaction.Status = models.TransactionStatusFailed
+
+	if err := addTransactionEvent(ctx, tx.outboxRepo, events.TransactionFailed, transaction, cause.Error()); err != nil {
+		return err
+	}
+
+	if err := tx.exchangeRepo.UpdateStatus(exchange.ID, models.ExchangeStatusFailed); err != nil {
+		return fmt.Errorf("failed to update exchange status: %w", err)
+	}
+	exchange.Status = models.ExchangeStatusFailed
+
+	if err := addExchangeEvent(ctx, tx.outboxRepo, events.ExchangeFailed, exchange, cause.Error()); err != nil {
+		return err
+	}
+
+	tx.onCommit(func() { exchangeFailed(exchange, cause) })
+	return nil
+}
+
+// unlockQuote undoes LOCK_QUOTE and ends the compensation. The lock may have
+// gone through even if its reply was lost, so the quote is always released.
+func (s *ExchangeSagaService) unlockQuote(ctx context.Context, saga *models.ExchangeSaga) error {
+	if err := s.releaseQuote(ctx, saga); err != nil {
+		return err
+	}
+
+	next := *saga
+	next.Status = models.SagaStatusCompensated
+	next.Step = models.SagaStepDone
+	next.Attempts = 0
+	next.LastError = nil
+	now := time.Now().UTC()
+	next.FinishedAt = &now
+	if err := s.sagaRepo.Update(&next, saga.Status, saga.Step); err != nil {
+		return err
+	}
+	*saga = next
+	s.recordStep(saga, models.SagaStepLockQuote, models.SagaActionCompensate, nil)
+
+	metrics.ExchangeSagasTotal.WithLabelValues("compensated").Inc()
+	logger.Info("Exchange saga compensated",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("reason", *saga.FailureReason),
+	)
+	return nil
+}
+
+func (s *ExchangeSagaService) releaseQuote(ctx context.Context, saga *models.ExchangeSaga) error {
+	ctx, cancel := context.WithTimeout(ctx, s.settings.StepTimeout)
+	defer cancel()
+
+	if err := s.quotes.Release(ctx, saga.ID.String()); err != nil {
+		return fmt.Errorf("failed to release quote: %w", err)
+	}
+	return nil
+}
+
+// advance stores a saga that ran its current step and moves on to the given
+// step, renewing the lease of this instance
+func (s *ExchangeSagaService) advance(saga, next *models.ExchangeSaga, step models.SagaStep) error {
+	next.Step = step
+	next.Attempts = 0
+	next.LastError = nil
+	next.NextAttemptAt = time.Now().Add(s.settings.Lease)
+	if step == models.SagaStepDone {
+		now := time.Now().UTC()
+		next.FinishedAt = &now
+	}
+
+	if err := s.sagaRepo.Update(next, saga.Status, saga.Step); err != nil {
+		return err
+	}
+
+	executed := saga.Step
+	*saga = *next
+	s.recordStep(saga, executed, models.SagaActionExecute, nil)
+	return nil
+}
+
+// beginCompensation turns a saga whose step failed around. A failed database
+// step rolled back, so undoing starts at the step before; the quote locker is
+// asked to release even a lock that seemed to fail.
+func (s *ExchangeSagaService) beginCompensation(saga *models.ExchangeSaga, cause error) error {
+	next := *saga
+	next.Status = models.SagaStatusCompensating
+	reason := cause.Error()
+	next.FailureReason = &reason
+	next.Attempts = 0
+	next.LastError = nil
+	next.NextAttemptAt = time.Now().Add(s.settings.Lease)
+
+	switch saga.Step {
+	case models.SagaStepLockQuote:
+		next.Step = models.SagaStepLockQuote
+	case models.SagaStepReserveFunds, models.SagaStepSettle:
+		// Undoing RESERVE_FUNDS records the exchange as failed even if the
+		// reservation itself failed
+		next.Step = models.SagaStepReserveFunds
+	}
+
+	if err := s.sagaRepo.Update(&next, saga.Status, saga.Step); err != nil {
+		return err
+	}
+	*saga = next
+
+	logger.Warn("Compensating exchange saga",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("step", string(saga.Step)),
+		zap.Error(cause),
+	)
+	return nil
+}
+
+// retryLater leaves a saga whose step failed to the recovery worker, backing
+// off with every attempt
+func (s *ExchangeSagaService) retryLater(saga *models.ExchangeSaga, err error) error {
+	next := *saga
+	next.Attempts++
+	reason := err.Error()
+	next.LastError = &reason
+	next.NextAttemptAt = time.Now().Add(s.retryDelay(saga.Attempts))
+
+	if updateErr := s.sagaRepo.Update(&next, saga.Status, saga.Step); updateErr != nil {
+		return updateErr
+	}
+	*saga = next
+
+	logger.Warn("Exchange saga step failed, retrying later",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("status", string(saga.Status)),
+		zap.String("step", string(saga.Step)),
+		zap.Int("attempts", saga.Attempts),
+		zap.Time("next_attempt_at", saga.NextAttemptAt),
+		zap.Error(err),
+	)
+	return nil
+}
+
+// retryDelay returns how long to wait after the given number of failed attempts
+func (s *ExchangeSagaService) retryDelay(attempts int) time.Duration {
+	delay := s.settings.RetryBaseDelay
+	for i := 0; i < attempts && delay < s.settings.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.settings.RetryMaxDelay {
+		delay = s.settings.RetryMaxDelay
+	}
+	return delay
+}
+
+// recordStep logs a step in the saga history. The history is informational,
+// so a failed write is only logged.
+func (s *ExchangeSagaService) recordStep(saga *models.ExchangeSaga, step models.SagaStep, action models.SagaAction, stepErr error) {
+	record := &models.SagaStepRecord{
+		Step:    step,
+		Action:  action,
+		Outcome: models.SagaOutcomeSucceeded,
+	}
+	if stepErr != nil {
+		record.Outcome = models.SagaOutcomeFailed
+		reason := stepErr.Error()
+		record.Error = &reason
+		metrics.ExchangeSagaStepFailuresTotal.WithLabelValues(string(step), string(action)).Inc()
+	}
+
+	if err := s.sagaRepo.AddStep(saga.ID, record); err != nil {
+		logger.Error("Failed to record saga step",
+			zap.String("saga_id", saga.ID.String()),
+			zap.String("step", string(step)),
+			zap.Error(err),
+		)
+	}
+}
+
+// sagaDB is the sagaLedger of the bank database
+type sagaDB struct {
+	db           *sql.DB
+	sagaRepo     *repositories.SagaRepository
+	exchangeRepo *repositories.ExchangeRepository
+	accountRepo  *repositories.AccountRepository
+	walletRepo   *repositories.CryptoWalletRepository
+	txRepo       *repositories.TransactionRepository
+	outboxRepo   *repositories.OutboxRepository
+	limits       *LimitService
+	lease        time.Duration
+}
+
+// sagaTx holds the transaction and repositories of a database step
+type sagaTx struct {
+	db           *sql.Tx
+	exchangeRepo *repositories.ExchangeRepository
+	accountRepo  *repositories.AccountRepository
+	walletRepo   *repositories.CryptoWalletRepository
+	txRepo       *repositories.TransactionRepository
+	outboxRepo   *repositories.OutboxRepository
+	committed    []func()
+}
+
+// onCommit runs fn once the step committed
+func (tx *sagaTx) onCommit(fn func()) {
+	tx.committed = append(tx.committed, fn)
+}
+
+// inTx runs a database step of a saga in one transaction with the update of
+// the saga, so the step and its outcome are stored together. The step changes
+// a copy of the saga locked for the transaction; the saga takes its state on
+// commit.
+func (d *sagaDB) inTx(saga *models.ExchangeSaga, step func(tx *sagaTx, next *models.ExchangeSaga) error) error {
+	dbTx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	sagaRepo := d.sagaRepo.WithTx(dbTx)
+	current, err := sagaRepo.GetByIDForUpdate(saga.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status != saga.Status || current.Step != saga.Step {
+		return repositories.ErrSagaMoved
+	}
+
+	tx := &sagaTx{
+		db:           dbTx,
+		exchangeRepo: d.exchangeRepo.WithTx(dbTx),
+		accountRepo:  d.accountRepo.WithTx(dbTx),
+		walletRepo:   d.walletRepo.WithTx(dbTx),
+		txRepo:       d.txRepo.WithTx(dbTx),
+		outboxRepo:   d.outboxRepo.WithTx(dbTx),
+	}
+	if err := step(tx, current); err != nil {
+		return err
+	}
+
+	action := models.SagaActionExecute
+	if saga.Status == models.SagaStatusCompensating {
+		action = models.SagaActionCompensate
+	}
+	if err := sagaRepo.AddStep(saga.ID, &models.SagaStepRecord{
+		Step:    saga.Step,
+		Action:  action,
+		Outcome: models.SagaOutcomeSucceeded,
+	}); err != nil {
+		return err
+	}
+
+	current.Attempts = 0
+	current.LastError = nil
+	current.NextAttemptAt = time.Now().Add(d.lease)
+	if err := sagaRepo.Update(current, saga.Status, saga.Step); err != nil {
+		return err
+	}
+
+	// Commit database transaction
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	*saga = *current
+	for _, fn := range tx.committed {
+		fn()
+	}
+	return nil
+}
+
+// validateAssets checks that both sides of an exchange are enabled registry assets
+// and that the source amount satisfies the source asset rules
+func (s *ExchangeSagaService) validateAssets(wallet *models.CryptoWallet, account *models.Account, source models.AssetKind, amount float64) error {
+	crypto, err := s.assets.Require(string(wallet.CryptoType), models.AssetKindCrypto)
+	if err != nil {
+		return err
+	}
+
+	fiat, err := s.assets.Require(string(account.Currency), models.AssetKindFiat)
+	if err != nil {
+		return err
+	}
+
+	if source == models.AssetKindCrypto {
+		return crypto.ValidateAmount(amount)
+	}
+	return fiat.ValidateAmount(amount)
+}
+
+// quoteValid checks that the locked quote still holds
+func quoteValid(saga *models.ExchangeSaga) error {
+	if !time.Now().Before(*saga.QuoteExpiresAt) {
+		return fmt.Errorf("%w at %s", quotes.ErrExpired, saga.QuoteExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// newSagaExchange builds the exchange of a saga at its locked rate
+func newSagaExchange(saga *models.ExchangeSaga) *models.Exchange {
+	return &models.Exchange{
+		UserID:        saga.UserID,
+		Type:          saga.Type,
+		Status:        models.ExchangeStatusPending,
+		FromCurrency:  saga.FromCurrency,
+		ToCurrency:    saga.ToCurrency,
+		FromAmount:    saga.FromAmount,
+		ToAmount:      saga.FromAmount * *saga.ExchangeRate,
+		ExchangeRate:  *saga.ExchangeRate,
+		FromAccountID: saga.FromAccountID,
+		ToAccountID:   saga.ToAccountID,
+		FromWalletID:  saga.FromWalletID,
+		ToWalletID:    saga.ToWalletID,
+	}
+}
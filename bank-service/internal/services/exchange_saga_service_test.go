@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/quotes"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memorySagas keeps sagas in memory. Like the saga table it only updates a
+// saga still at the expected status and step.
+type memorySagas struct {
+	sagas map[uuid.UUID]*models.ExchangeSaga
+	steps map[uuid.UUID][]*models.SagaStepRecord
+}
+
+func newMemorySagas() *memorySagas {
+	return &memorySagas{
+		sagas: make(map[uuid.UUID]*models.ExchangeSaga),
+		steps: make(map[uuid.UUID][]*models.SagaStepRecord),
+	}
+}
+
+func (m *memorySagas) Create(saga *models.ExchangeSaga) error {
+	saga.ID = uuid.New()
+	stored := *saga
+	m.sagas[saga.ID] = &stored
+	return nil
+}
+
+func (m *memorySagas) GetByID(id uuid.UUID) (*models.ExchangeSaga, error) {
+	stored, ok := m.sagas[id]
+	if !ok {
+		return nil, fmt.Errorf("saga not found")
+	}
+	saga := *stored
+	return &saga, nil
+}
+
+func (m *memorySagas) Update(saga *models.ExchangeSaga, status models.SagaStatus, step models.SagaStep) error {
+	stored, ok := m.sagas[saga.ID]
+	if !ok || stored.Status != status || stored.Step != step {
+		return repositories.ErrSagaMoved
+	}
+	*stored = *saga
+	return nil
+}
+
+func (m *memorySagas) Claim(limit int, now, until time.Time) ([]*models.ExchangeSaga, error) {
+	var claimed []*models.ExchangeSaga
+	for _, stored := range m.sagas {
+		running := stored.Status == models.SagaStatusRunning || stored.Status == models.SagaStatusCompensating
+		if running && !stored.NextAttemptAt.After(now) && len(claimed) < limit {
+			stored.NextAttemptAt = until
+			saga := *stored
+			claimed = append(claimed, &saga)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memorySagas) AddStep(sagaID uuid.UUID, record *models.SagaStepRecord) error {
+	m.steps[sagaID] = append(m.steps[sagaID], record)
+	return nil
+}
+
+func (m *memorySagas) GetSteps(sagaID uuid.UUID) ([]*models.SagaStepRecord, error) {
+	return m.steps[sagaID], nil
+}
+
+// sagaScript logs the calls a saga makes to the quote locker and the ledger
+// and fails the calls it is told to, as often as it is told to
+type sagaScript struct {
+	calls    []string
+	failures map[string]int
+}
+
+func (sc *sagaScript) call(name string) error {
+	sc.calls = append(sc.calls, name)
+	if sc.failures[name] > 0 {
+		sc.failures[name]--
+		return fmt.Errorf("%s failed", name)
+	}
+	return nil
+}
+
+type scriptedLocker struct {
+	script *sagaScript
+}
+
+func (l *scriptedLocker) Name() string { return "scripted" }
+
+func (l *scriptedLocker) Lock(_ context.Context, req *quotes.Request) (*quotes.Quote, error) {
+	if err := l.script.call("lock"); err != nil {
+		return nil, err
+	}
+	return &quotes.Quote{ID: "quote-" + req.Key, Rate: 0.000023, ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+func (l *scriptedLocker) Release(context.Context, string) error {
+	return l.script.call("release")
+}
+
+// scriptedLedger moves a saga through the database steps the way sagaDB
+// does, committing the step record and the saga together
+type scriptedLedger struct {
+	script *sagaScript
+	store  *memorySagas
+}
+
+func (l *scriptedLedger) reserveFunds(_ context.Context, saga *models.ExchangeSaga) error {
+	return l.commit("reserve", saga, func(next *models.ExchangeSaga) {
+		exchangeID, transactionID := uuid.New(), uuid.New()
+		next.ExchangeID = &exchangeID
+		next.TransactionID = &transactionID
+		next.Step = models.SagaStepSettle
+	})
+}
+
+func (l *scriptedLedger) settle(_ context.Context, saga *models.ExchangeSaga) error {
+	return l.commit("settle", saga, func(next *models.ExchangeSaga) {
+		next.Step = models.SagaStepRelease
+	})
+}
+
+func (l *scriptedLedger) refund(_ context.Context, saga *models.ExchangeSaga) error {
+	return l.commit("refund", saga, func(next *models.ExchangeSaga) {
+		next.Step = models.SagaStepLockQuote
+	})
+}
+
+func (l *scriptedLedger) commit(name string, saga *models.ExchangeSaga, step func(next *models.ExchangeSaga)) error {
+	if err := l.script.call(name); err != nil {
+		return err
+	}
+
+	next := *saga
+	step(&next)
+
+	action := models.SagaActionExecute
+	if saga.Status == models.SagaStatusCompensating {
+		action = models.SagaActionCompensate
+	}
+	if err := l.store.AddStep(saga.ID, &models.SagaStepRecord{Step: saga.Step, Action: action, Outcome: models.SagaOutcomeSucceeded}); err != nil {
+		return err
+	}
+	next.Attempts = 0
+	next.LastError = nil
+	if err := l.store.Update(&next, saga.Status, saga.Step); err != nil {
+		return err
+	}
+	*saga = next
+	return nil
+}
+
+func newTestSagaService(t *testing.T, failures map[string]int) (*ExchangeSagaService, *memorySagas, *sagaScript) {
+	t.Helper()
+	logger.Log = zap.NewNop()
+
+	store := newMemorySagas()
+	script := &sagaScript{failures: failures}
+	s := &ExchangeSagaService{
+		sagaRepo: store,
+		ledger:   &scriptedLedger{script: script, store: store},
+		quotes:   &scriptedLocker{script: script},
+		settings: ExchangeSagaSettings{
+			Lease:             time.Minute,
+			StepTimeout:       time.Second,
+			RetryBaseDelay:    time.Millisecond,
+			RetryMaxDelay:     time.Millisecond,
+			RecoveryBatchSize: 10,
+		},
+	}
+	return s, store, script
+}
+
+// newTestSaga stores a saga the way start does before running it
+func newTestSaga(t *testing.T, store *memorySagas) *models.ExchangeSaga {
+	t.Helper()
+
+	walletID, accountID := uuid.New(), uuid.New()
+	saga := &models.ExchangeSaga{
+		UserID:        uuid.New(),
+		Type:          models.ExchangeFiatToCrypto,
+		Status:        models.SagaStatusRunning,
+		Step:          models.SagaStepLockQuote,
+		FromCurrency:  "USD",
+		ToCurrency:    "BTC",
+		FromAmount:    1000,
+		FromAccountID: &accountID,
+		ToWalletID:    &walletID,
+		NextAttemptAt: time.Now().Add(time.Minute),
+	}
+	if err := store.Create(saga); err != nil {
+		t.Fatal(err)
+	}
+	return saga
+}
+
+func sagaHistory(store *memorySagas, id uuid.UUID) []string {
+	var history []string
+	for _, record := range store.steps[id] {
+		history = append(history, fmt.Sprintf("%s %s %s", record.Step, record.Action, record.Outcome))
+	}
+	return history
+}
+
+func TestExchangeSagaRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures map[string]int
+		// wantErr is the failure run reports, empty for none
+		wantErr string
+		calls   []string
+		status  models.SagaStatus
+		step    models.SagaStep
+		history []string
+	}{
+		{
+			name:   "every step succeeds",
+			calls:  []string{"lock", "reserve", "settle", "release"},
+			status: models.SagaStatusCompleted,
+			step:   models.SagaStepDone,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE SUCCEEDED",
+				"SETTLE EXECUTE SUCCEEDED",
+				"RELEASE EXECUTE SUCCEEDED",
+			},
+		},
+		{
+			name:     "quote lock fails",
+			failures: map[string]int{"lock": 1},
+			wantErr:  "failed to lock quote: lock failed",
+			calls:    []string{"lock", "release"},
+			status:   models.SagaStatusCompensated,
+			step:     models.SagaStepDone,
+			history: []string{
+				"LOCK_QUOTE EXECUTE FAILED",
+				"LOCK_QUOTE COMPENSATE SUCCEEDED",
+			},
+		},
+		{
+			name:     "reservation fails",
+			failures: map[string]int{"reserve": 1},
+			wantErr:  "reserve failed",
+			calls:    []string{"lock", "reserve", "refund", "release"},
+			status:   models.SagaStatusCompensated,
+			step:     models.SagaStepDone,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE FAILED",
+				"RESERVE_FUNDS COMPENSATE SUCCEEDED",
+				"LOCK_QUOTE COMPENSATE SUCCEEDED",
+			},
+		},
+		{
+			name:     "settlement fails",
+			failures: map[string]int{"settle": 1},
+			wantErr:  "settle failed",
+			calls:    []string{"lock", "reserve", "settle", "refund", "release"},
+			status:   models.SagaStatusCompensated,
+			step:     models.SagaStepDone,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE SUCCEEDED",
+				"SETTLE EXECUTE FAILED",
+				"RESERVE_FUNDS COMPENSATE SUCCEEDED",
+				"LOCK_QUOTE COMPENSATE SUCCEEDED",
+			},
+		},
+		{
+			name:     "release fails after settling",
+			failures: map[string]int{"release": 1},
+			calls:    []string{"lock", "reserve", "settle", "release"},
+			status:   models.SagaStatusRunning,
+			step:     models.SagaStepRelease,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE SUCCEEDED",
+				"SETTLE EXECUTE SUCCEEDED",
+				"RELEASE EXECUTE FAILED",
+			},
+		},
+		{
+			name:     "refund fails",
+			failures: map[string]int{"settle": 1, "refund": 1},
+			wantErr:  "settle failed",
+			calls:    []string{"lock", "reserve", "settle", "refund"},
+			status:   models.SagaStatusCompensating,
+			step:     models.SagaStepReserveFunds,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE SUCCEEDED",
+				"SETTLE EXECUTE FAILED",
+				"RESERVE_FUNDS COMPENSATE FAILED",
+			},
+		},
+		{
+			name:     "unlock fails while compensating",
+			failures: map[string]int{"reserve": 1, "release": 1},
+			wantErr:  "reserve failed",
+			calls:    []string{"lock", "reserve", "refund", "release"},
+			status:   models.SagaStatusCompensating,
+			step:     models.SagaStepLockQuote,
+			history: []string{
+				"LOCK_QUOTE EXECUTE SUCCEEDED",
+				"RESERVE_FUNDS EXECUTE FAILED",
+				"RESERVE_FUNDS COMPENSATE SUCCEEDED",
+				"LOCK_QUOTE COMPENSATE FAILED",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, script := newTestSagaService(t, tt.failures)
+			saga := newTestSaga(t, store)
+
+			err := s.run(context.Background(), saga)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("run = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("run = %v, want %q", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(script.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", script.calls, tt.calls)
+			}
+
+			stored, err := store.GetByID(saga.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.status || stored.Step != tt.step {
+				t.Errorf("saga = %s at %s, want %s at %s", stored.Status, stored.Step, tt.status, tt.step)
+			}
+			if done := stored.Step == models.SagaStepDone; done != (stored.FinishedAt != nil) {
+				t.Errorf("finished at %v with the saga at %s", stored.FinishedAt, stored.Step)
+			}
+			if tt.wantErr != "" && (stored.FailureReason == nil || *stored.FailureReason != tt.wantErr) {
+				t.Errorf("failure reason = %v, want %q", stored.FailureReason, tt.wantErr)
+			}
+
+			if history := sagaHistory(store, saga.ID); !reflect.DeepEqual(history, tt.history) {
+				t.Errorf("history = %v, want %v", history, tt.history)
+			}
+		})
+	}
+}
+
+func TestExchangeSagaRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures map[string]int
+		// calls are the calls made by the recovery worker
+		calls  []string
+		status models.SagaStatus
+	}{
+		{
+			name:     "release is retried until the saga completes",
+			failures: map[string]int{"release": 2},
+			calls:    []string{"release", "release"},
+			status:   models.SagaStatusCompleted,
+		},
+		{
+			name:     "refund is retried and the compensation finishes",
+			failures: map[string]int{"settle": 1, "refund": 1},
+			calls:    []string{"refund", "release"},
+			status:   models.SagaStatusCompensated,
+		},
+		{
+			name:     "unlock is retried and the compensation finishes",
+			failures: map[string]int{"lock": 1, "release": 1},
+			calls:    []string{"release"},
+			status:   models.SagaStatusCompensated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, script := newTestSagaService(t, tt.failures)
+			saga := newTestSaga(t, store)
+
+			_ = s.run(context.Background(), saga)
+			ran := len(script.calls)
+
+			for i := 0; i < 5; i++ {
+				stored, err := store.GetByID(saga.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stored.Step == models.SagaStepDone {
+					break
+				}
+				if stored.Attempts == 0 {
+					t.Fatalf("saga left at %s without a failed attempt", stored.Step)
+				}
+				time.Sleep(2 * s.settings.RetryMaxDelay)
+				s.Recover()
+			}
+
+			if calls := script.calls[ran:]; !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("recovery calls = %v, want %v", calls, tt.calls)
+			}
+			stored, err := store.GetByID(saga.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.status || stored.Step != models.SagaStepDone {
+				t.Errorf("saga = %s at %s, want %s at DONE", stored.Status, stored.Step, tt.status)
+			}
+			if stored.Attempts != 0 || stored.LastError != nil {
+				t.Errorf("saga kept %d attempts and error %v after finishing", stored.Attempts, stored.LastError)
+			}
+		})
+	}
+}
+
+func TestExchangeSagaStopsWhenMoved(t *testing.T) {
+	s, store, script := newTestSagaService(t, nil)
+	saga := newTestSaga(t, store)
+
+	// Another instance took the saga over and locked the quote
+	store.sagas[saga.ID].Step = models.SagaStepReserveFunds
+
+	if err := s.run(context.Background(), saga); !errors.Is(err, repositories.ErrSagaMoved) {
+		t.Fatalf("run = %v, want ErrSagaMoved", err)
+	}
+	if !reflect.DeepEqual(script.calls, []string{"lock"}) {
+		t.Errorf("calls = %v, want only the lock", script.calls)
+	}
+}
+
+func TestExchangeSagaRetryDelay(t *testing.T) {
+	s := &ExchangeSagaService{settings: ExchangeSagaSettings{
+		RetryBaseDelay: time.Second,
+		RetryMaxDelay:  10 * time.Second,
+	}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := s.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -1,14 +1,14 @@
 package services
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/repositories"
 	"github.com/crypto-bank/bank-service/pkg/logger"
 	"github.com/crypto-bank/bank-service/pkg/metrics"
-	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -17,31 +17,59 @@ type ExchangeService struct {
 	exchangeRepo *repositories.ExchangeRepository
 	accountRepo  *repositories.AccountRepository
 	walletRepo   *repositories.CryptoWalletRepository
-	txRepo       *repositories.TransactionRepository
-	db           *sql.DB
-	rabbitMQ     *rabbitmq.Client
+	sagas        *ExchangeSagaService
+	approvals    *ApprovalService
+	limits       *LimitService
+	risk         *RiskService
 }
 
 func NewExchangeService(
 	exchangeRepo *repositories.ExchangeRepository,
 	accountRepo *repositories.AccountRepository,
 	walletRepo *repositories.CryptoWalletRepository,
-	txRepo *repositories.TransactionRepository,
-	db *sql.DB,
-	rabbitMQ *rabbitmq.Client,
+	sagas *ExchangeSagaService,
+	approvals *ApprovalService,
+	limits *LimitService,
+	risk *RiskService,
 ) *ExchangeService {
-	return &ExchangeService{
+	s := &ExchangeService{
 		exchangeRepo: exchangeRepo,
 		accountRepo:  accountRepo,
 		walletRepo:   walletRepo,
-		txRepo:       txRepo,
-		db:           db,
-		rabbitMQ:     rabbitMQ,
-	}
+		sagas:        sagas,
+		approvals:    approvals,
+		limits:       limits,
+		risk:         risk,
+	}
+
+	approvals.RegisterExecutor(models.ApprovalOpCryptoToFiat, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.ExchangeCryptoToFiatRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid exchange payload: %w", err)
+		}
+		exchange, err := s.executeCryptoToFiat(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return exchange.ID, nil
+	})
+	approvals.RegisterExecutor(models.ApprovalOpFiatToCrypto, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.ExchangeFiatToCryptoRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid exchange payload: %w", err)
+		}
+		exchange, err := s.executeFiatToCrypto(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return exchange.ID, nil
+	})
+
+	return s
 }
 
 // ExchangeCryptoToFiat exchanges cryptocurrency to fiat currency
-func (s *ExchangeService) ExchangeCryptoToFiat(req *models.ExchangeCryptoToFiatRequest) (*models.Exchange, error) {
+func (s *ExchangeService) ExchangeCryptoToFiat(ctx context.Context, req *models.ExchangeCryptoToFiatRequest) (*models.Exchange, error) {
 	logger.Info("Exchanging crypto to fiat",
 		zap.String("user_id", req.UserID.String()),
 		zap.String("from_wallet", req.FromWalletID.String()),
@@ -49,122 +77,55 @@ func (s *ExchangeService) ExchangeCryptoToFiat(req *models.ExchangeCryptoToFiatR
 		zap.Float64("crypto_amount", req.CryptoAmount),
 	)
 
-	// Start database transaction
-	dbTx, err := s.db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer dbTx.Rollback()
-
-	// Get wallet and account
 	wallet, err := s.walletRepo.GetByID(req.FromWalletID)
 	if err != nil {
 		return nil, fmt.Errorf("wallet not found: %w", err)
 	}
 
-	account, err := s.accountRepo.GetByID(req.ToAccountID)
-	if err != nil {
-		return nil, fmt.Errorf("account not found: %w", err)
-	}
-
-	// Verify ownership
-	if wallet.UserID != req.UserID || account.UserID != req.UserID {
-		return nil, fmt.Errorf("ownership mismatch")
-	}
-
-	// Check balance
-	if wallet.Balance < req.CryptoAmount {
-		return nil, fmt.Errorf("insufficient balance: have %f, need %f", wallet.Balance, req.CryptoAmount)
-	}
-
-	// Get exchange rate
-	fromCurrency := string(wallet.CryptoType)
-	toCurrency := string(account.Currency)
-	rate, err := s.exchangeRepo.GetExchangeRate(fromCurrency, toCurrency)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
-	}
-
-	// Calculate fiat amount
-	fiatAmount := req.CryptoAmount * rate
-
-	// Create exchange record
-	exchange := &models.Exchange{
-		UserID:        req.UserID,
-		Type:          models.ExchangeCryptoToFiat,
-		Status:        models.ExchangeStatusPending,
-		FromCurrency:  fromCurrency,
-		ToCurrency:    toCurrency,
-		FromAmount:    req.CryptoAmount,
-		ToAmount:      fiatAmount,
-		ExchangeRate:  rate,
-		FromWalletID:  &req.FromWalletID,
-		ToAccountID:   &req.ToAccountID,
-	}
-
-	if err := s.exchangeRepo.Create(exchange); err != nil {
-		return nil, fmt.Errorf("failed to create exchange: %w", err)
-	}
-
-	// Update balances
-	if err := s.walletRepo.UpdateBalance(req.FromWalletID, -req.CryptoAmount); err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+	if wallet.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
 	}
 
-	if err := s.accountRepo.UpdateBalance(req.ToAccountID, fiatAmount); err != nil {
-		return nil, fmt.Errorf("failed to update account balance: %w", err)
+	if err := s.limits.Check(req.UserID, models.LimitOpExchange, string(wallet.CryptoType), req.CryptoAmount); err != nil {
+		return nil, err
 	}
 
-	// Create transaction record
-	transaction := &models.Transaction{
-		UserID:      req.UserID,
-		Type:        models.TransactionTypeExchange,
-		Status:      models.TransactionStatusCompleted,
-		Amount:      fiatAmount,
-		Currency:    toCurrency,
-		ToAccountID: &req.ToAccountID,
-		ExchangeID:  &exchange.ID,
-		Description: fmt.Sprintf("Exchange %f %s to %s", req.CryptoAmount, fromCurrency, toCurrency),
+	if err := s.risk.Assess(ctx, &RiskCheck{
+		UserID:    req.UserID,
+		Operation: models.RiskOpExchange,
+		Amount:    req.CryptoAmount,
+		Currency:  string(wallet.CryptoType),
+		Client:    req.Client,
+		SteppedUp: req.SteppedUp,
+	}); err != nil {
+		return nil, err
 	}
 
-	if err := s.txRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if err := s.approvals.Guard(req.UserID, ApprovalTarget{WalletID: &wallet.ID},
+		models.ApprovalOpCryptoToFiat, req.CryptoAmount, string(wallet.CryptoType), req); err != nil {
+		return nil, err
 	}
 
-	// Update exchange status
-	if err := s.exchangeRepo.UpdateStatus(exchange.ID, models.ExchangeStatusCompleted); err != nil {
-		return nil, fmt.Errorf("failed to update exchange status: %w", err)
-	}
+	return s.executeCryptoToFiat(ctx, req)
+}
 
-	// Commit database transaction
-	if err := dbTx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// executeCryptoToFiat carries out a crypto to fiat exchange that passed all
+// checks. An exchange that fails on the way is recorded as failed.
+func (s *ExchangeService) executeCryptoToFiat(ctx context.Context, req *models.ExchangeCryptoToFiatRequest) (*models.Exchange, error) {
+	exchange, err := s.sagas.CryptoToFiat(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	exchange.Status = models.ExchangeStatusCompleted
-	exchange.TransactionID = &transaction.ID
-
 	// Update metrics
 	metrics.ExchangesTotal.WithLabelValues(string(exchange.Type), string(exchange.Status)).Inc()
 
-	// Publish event
-	event := rabbitmq.ExchangeEvent{
-		ExchangeID:   exchange.ID.String(),
-		UserID:       exchange.UserID.String(),
-		FromCurrency: exchange.FromCurrency,
-		ToCurrency:   exchange.ToCurrency,
-		FromAmount:   exchange.FromAmount,
-		ToAmount:     exchange.ToAmount,
-		Status:       string(exchange.Status),
-	}
-	s.rabbitMQ.PublishEvent(rabbitmq.ExchangeEvents, rabbitmq.EventExchangeCompleted, event)
-
 	logger.Info("Crypto to fiat exchange completed", zap.String("exchange_id", exchange.ID.String()))
 	return exchange, nil
 }
 
 // ExchangeFiatToCrypto exchanges fiat currency to cryptocurrency
-func (s *ExchangeService) ExchangeFiatToCrypto(req *models.ExchangeFiatToCryptoRequest) (*models.Exchange, error) {
+func (s *ExchangeService) ExchangeFiatToCrypto(ctx context.Context, req *models.ExchangeFiatToCryptoRequest) (*models.Exchange, error) {
 	logger.Info("Exchanging fiat to crypto",
 		zap.String("user_id", req.UserID.String()),
 		zap.String("from_account", req.FromAccountID.String()),
@@ -172,116 +133,49 @@ func (s *ExchangeService) ExchangeFiatToCrypto(req *models.ExchangeFiatToCryptoR
 		zap.Float64("fiat_amount", req.FiatAmount),
 	)
 
-	// Start database transaction
-	dbTx, err := s.db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer dbTx.Rollback()
-
-	// Get account and wallet
 	account, err := s.accountRepo.GetByID(req.FromAccountID)
 	if err != nil {
 		return nil, fmt.Errorf("account not found: %w", err)
 	}
 
-	wallet, err := s.walletRepo.GetByID(req.ToWalletID)
-	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
-	}
-
-	// Verify ownership
-	if account.UserID != req.UserID || wallet.UserID != req.UserID {
-		return nil, fmt.Errorf("ownership mismatch")
-	}
-
-	// Check balance
-	if account.Balance < req.FiatAmount {
-		return nil, fmt.Errorf("insufficient balance: have %f, need %f", account.Balance, req.FiatAmount)
-	}
-
-	// Get exchange rate
-	fromCurrency := string(account.Currency)
-	toCurrency := string(wallet.CryptoType)
-	rate, err := s.exchangeRepo.GetExchangeRate(fromCurrency, toCurrency)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
-	}
-
-	// Calculate crypto amount
-	cryptoAmount := req.FiatAmount * rate
-
-	// Create exchange record
-	exchange := &models.Exchange{
-		UserID:        req.UserID,
-		Type:          models.ExchangeFiatToCrypto,
-		Status:        models.ExchangeStatusPending,
-		FromCurrency:  fromCurrency,
-		ToCurrency:    toCurrency,
-		FromAmount:    req.FiatAmount,
-		ToAmount:      cryptoAmount,
-		ExchangeRate:  rate,
-		FromAccountID: &req.FromAccountID,
-		ToWalletID:    &req.ToWalletID,
-	}
-
-	if err := s.exchangeRepo.Create(exchange); err != nil {
-		return nil, fmt.Errorf("failed to create exchange: %w", err)
+	if account.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
 	}
 
-	// Update balances
-	if err := s.accountRepo.UpdateBalance(req.FromAccountID, -req.FiatAmount); err != nil {
-		return nil, fmt.Errorf("failed to update account balance: %w", err)
+	if err := s.limits.Check(req.UserID, models.LimitOpExchange, string(account.Currency), req.FiatAmount); err != nil {
+		return nil, err
 	}
 
-	if err := s.walletRepo.UpdateBalance(req.ToWalletID, cryptoAmount); err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+	if err := s.risk.Assess(ctx, &RiskCheck{
+		UserID:    req.UserID,
+		Operation: models.RiskOpExchange,
+		Amount:    req.FiatAmount,
+		Currency:  string(account.Currency),
+		Client:    req.Client,
+		SteppedUp: req.SteppedUp,
+	}); err != nil {
+		return nil, err
 	}
 
-	// Create transaction record
-	transaction := &models.Transaction{
-		UserID:        req.UserID,
-		Type:          models.TransactionTypeExchange,
-		Status:        models.TransactionStatusCompleted,
-		Amount:        req.FiatAmount,
-		Currency:      fromCurrency,
-		FromAccountID: &req.FromAccountID,
-		ExchangeID:    &exchange.ID,
-		Description:   fmt.Sprintf("Exchange %f %s to %s", req.FiatAmount, fromCurrency, toCurrency),
+	if err := s.approvals.Guard(req.UserID, ApprovalTarget{AccountID: &account.ID},
+		models.ApprovalOpFiatToCrypto, req.FiatAmount, string(account.Currency), req); err != nil {
+		return nil, err
 	}
 
-	if err := s.txRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
-	}
-
-	// Update exchange status
-	if err := s.exchangeRepo.UpdateStatus(exchange.ID, models.ExchangeStatusCompleted); err != nil {
-		return nil, fmt.Errorf("failed to update exchange status: %w", err)
-	}
+	return s.executeFiatToCrypto(ctx, req)
+}
 
-	// Commit database transaction
-	if err := dbTx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// executeFiatToCrypto carries out a fiat to crypto exchange that passed all
+// checks. An exchange that fails on the way is recorded as failed.
+func (s *ExchangeService) executeFiatToCrypto(ctx context.Context, req *models.ExchangeFiatToCryptoRequest) (*models.Exchange, error) {
+	exchange, err := s.sagas.FiatToCrypto(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	exchange.Status = models.ExchangeStatusCompleted
-	exchange.TransactionID = &transaction.ID
-
 	// Update metrics
 	metrics.ExchangesTotal.WithLabelValues(string(exchange.Type), string(exchange.Status)).Inc()
 
-	// Publish event
-	event := rabbitmq.ExchangeEvent{
-		ExchangeID:   exchange.ID.String(),
-		UserID:       exchange.UserID.String(),
-		FromCurrency: exchange.FromCurrency,
-		ToCurrency:   exchange.ToCurrency,
-		FromAmount:   exchange.FromAmount,
-		ToAmount:     exchange.ToAmount,
-		Status:       string(exchange.Status),
-	}
-	s.rabbitMQ.PublishEvent(rabbitmq.ExchangeEvents, rabbitmq.EventExchangeCompleted, event)
-
 	logger.Info("Fiat to crypto exchange completed", zap.String("exchange_id", exchange.ID.String()))
 	return exchange, nil
 }
@@ -295,4 +189,3 @@ func (s *ExchangeService) GetExchange(id uuid.UUID) (*models.Exchange, error) {
 func (s *ExchangeService) GetUserExchanges(userID uuid.UUID) ([]*models.Exchange, error) {
 	return s.exchangeRepo.GetByUserID(userID)
 }
-
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/kyc"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/shared/events"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// KYCService runs identity verification and moves users up the tiers
+type KYCService struct {
+	kycRepo         *repositories.KYCRepository
+	userRepo        *repositories.UserRepository
+	provider        kyc.Provider
+	providerTimeout time.Duration
+	audit           *AuditService
+}
+
+func NewKYCService(
+	kycRepo *repositories.KYCRepository,
+	userRepo *repositories.UserRepository,
+	provider kyc.Provider,
+	providerTimeout time.Duration,
+	audit *AuditService,
+) *KYCService {
+	return &KYCService{
+		kycRepo:         kycRepo,
+		userRepo:        userRepo,
+		provider:        provider,
+		providerTimeout: providerTimeout,
+		audit:           audit,
+	}
+}
+
+// GetStatus returns the tier, limits and applications of a user
+func (s *KYCService) GetStatus(userID uuid.UUID) (*models.KYCStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.kycRepo.GetTierLimits(user.KYCTier)
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := s.kycRepo.GetApplicationsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.KYCStatus{
+		Tier:         user.KYCTier,
+		Limits:       limits,
+		Applications: apps,
+	}, nil
+}
+
+// Submit stores an application with its documents and has the provider check it.
+// The provider either decides right away or hands the application to compliance.
+func (s *KYCService) Submit(ctx context.Context, userID uuid.UUID, req *models.SubmitKYCRequest) (*models.KYCApplication, error) {
+	logger.Info("Submitting KYC application",
+		zap.String("user_id", userID.String()),
+		zap.String("requested_tier", string(req.RequestedTier)),
+	)
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RequestedTier.Rank() <= user.KYCTier.Rank() {
+		return nil, fmt.Errorf("user is already verified at the %s tier", user.KYCTier)
+	}
+
+	dateOfBirth, err := time.Parse("2006-01-02", req.DateOfBirth)
+	if err != nil || !dateOfBirth.Before(time.Now()) {
+		return nil, fmt.Errorf("invalid date of birth")
+	}
+
+	if err := requireDocuments(req.RequestedTier, req.Documents); err != nil {
+		return nil, err
+	}
+
+	open, err := s.hasOpenApplication(userID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, fmt.Errorf("a KYC application is already being processed")
+	}
+
+	docs := make([]*models.KYCDocument, len(req.Documents))
+	for i, upload := range req.Documents {
+		sum := sha256.Sum256(upload.Content)
+		docs[i] = &models.KYCDocument{
+			Type:        upload.Type,
+			FileName:    upload.FileName,
+			ContentType: upload.ContentType,
+			SizeBytes:   len(upload.Content),
+			SHA256:      hex.EncodeToString(sum[:]),
+			Content:     upload.Content,
+		}
+	}
+
+	app := &models.KYCApplication{
+		UserID:        userID,
+		RequestedTier: req.RequestedTier,
+		Status:        models.KYCStatusPending,
+		DateOfBirth:   dateOfBirth,
+		Country:       req.Country,
+		AddressLine:   req.AddressLine,
+		City:          req.City,
+		PostalCode:    req.PostalCode,
+		Provider:      s.provider.Name(),
+	}
+
+	if err := s.kycRepo.CreateApplication(app, docs); err != nil {
+		logger.Error("Failed to create KYC application", zap.Error(err))
+		return nil, err
+	}
+
+	status, reference, reason := s.verify(ctx, user, app, req.Documents)
+	event, err := tierUpgradedEvent(ctx, app, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.kycRepo.RecordProviderDecision(app.ID, status, reference, reason, event); err != nil {
+		logger.Error("Failed to record KYC provider decision", zap.Error(err))
+		return nil, err
+	}
+
+	// The provider decided, on behalf of the bank
+	s.decided(AuditOriginFrom(ctx).System(), app, status)
+	return s.kycRepo.GetApplication(app.ID)
+}
+
+// verify asks the provider for a decision. When the provider cannot be reached
+// the application goes to manual review rather than failing the submission.
+func (s *KYCService) verify(ctx context.Context, user *models.User, app *models.KYCApplication, uploads []models.KYCDocumentUpload) (models.KYCApplicationStatus, *string, *string) {
+	docs := make([]kyc.Document, len(uploads))
+	for i, upload := range uploads {
+		docs[i] = kyc.Document{Type: upload.Type, ContentType: upload.ContentType, Content: upload.Content}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.providerTimeout)
+	defer cancel()
+
+	decision, err := s.provider.Verify(ctx, &kyc.VerificationRequest{
+		ApplicationID: app.ID,
+		RequestedTier: app.RequestedTier,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Email:         user.Email,
+		DateOfBirth:   app.DateOfBirth,
+		Country:       app.Country,
+		Documents:     docs,
+	})
+	if err != nil {
+		logger.Error("KYC provider check failed",
+			zap.String("application_id", app.ID.String()),
+			zap.String("provider", s.provider.Name()),
+			zap.Error(err),
+		)
+		reason := "provider check failed, manual review required"
+		return models.KYCStatusInReview, nil, &reason
+	}
+
+	reference := &decision.Reference
+	var reason *string
+	if decision.Reason != "" {
+		reason = &decision.Reason
+	}
+
+	switch decision.Outcome {
+	case kyc.OutcomeApprove:
+		return models.KYCStatusApproved, reference, reason
+	case kyc.OutcomeReject:
+		return models.KYCStatusRejected, reference, reason
+	default:
+		return models.KYCStatusInReview, reference, reason
+	}
+}
+
+// GetApplication retrieves an application of a user
+func (s *KYCService) GetApplication(userID, id uuid.UUID) (*models.KYCApplication, error) {
+	app, err := s.kycRepo.GetApplication(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.UserID != userID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	return app, nil
+}
+
+// GetApplicationsByStatus retrieves the applications in a status for reviewers
+func (s *KYCService) GetApplicationsByStatus(status models.KYCApplicationStatus) ([]*models.KYCApplication, error) {
+	return s.kycRepo.GetApplicationsByStatus(status)
+}
+
+// GetApplicationForReview retrieves any application for reviewers
+func (s *KYCService) GetApplicationForReview(id uuid.UUID) (*models.KYCApplication, error) {
+	return s.kycRepo.GetApplication(id)
+}
+
+// GetDocument retrieves a submitted document including its content
+func (s *KYCService) GetDocument(applicationID, id uuid.UUID) (*models.KYCDocument, error) {
+	return s.kycRepo.GetDocument(applicationID, id)
+}
+
+// Approve grants the requested tier of an application waiting for manual review
+func (s *KYCService) Approve(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.KYCApplication, error) {
+	return s.review(ctx, reviewerID, id, models.KYCStatusApproved, note)
+}
+
+// Reject closes an application waiting for manual review without a tier change
+func (s *KYCService) Reject(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.KYCApplication, error) {
+	return s.review(ctx, reviewerID, id, models.KYCStatusRejected, note)
+}
+
+func (s *KYCService) review(ctx context.Context, reviewerID, id uuid.UUID, status models.KYCApplicationStatus, note string) (*models.KYCApplication, error) {
+	app, err := s.kycRepo.GetApplication(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.UserID == reviewerID {
+		return nil, fmt.Errorf("reviewers cannot decide their own application")
+	}
+
+	event, err := tierUpgradedEvent(ctx, app, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.kycRepo.Review(id, status, reviewerID, note, event); err != nil {
+		logger.Error("Failed to review KYC application", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("KYC application reviewed",
+		zap.String("application_id", id.String()),
+		zap.String("reviewer_id", reviewerID.String()),
+		zap.String("status", string(status)),
+	)
+
+	s.decided(AuditOriginFrom(ctx), app, status)
+	return s.kycRepo.GetApplication(id)
+}
+
+// decided records metrics and logs the decision. Approvals and rejections go
+// to the audit trail; the application data stays out of it.
+func (s *KYCService) decided(origin models.AuditOrigin, app *models.KYCApplication, status models.KYCApplicationStatus) {
+	metrics.KYCApplicationsTotal.WithLabelValues(string(app.RequestedTier), string(status)).Inc()
+
+	logger.Info("KYC application decided",
+		zap.String("application_id", app.ID.String()),
+		zap.String("user_id", app.UserID.String()),
+		zap.String("status", string(status)),
+	)
+
+	var action models.AuditAction
+	switch status {
+	case models.KYCStatusApproved:
+		action = models.AuditKYCApproved
+	case models.KYCStatusRejected:
+		action = models.AuditKYCRejected
+	default:
+		return
+	}
+	s.audit.Record(origin, action, models.AuditEntityKYCApplication, app.ID.String(),
+		map[string]interface{}{"user_id": app.UserID, "requested_tier": app.RequestedTier, "status": app.Status},
+		map[string]interface{}{"user_id": app.UserID, "requested_tier": app.RequestedTier, "status": status},
+	)
+}
+
+// tierUpgradedEvent announces the tier an approved application grants. It is
+// stored with the decision; other decisions announce nothing.
+func tierUpgradedEvent(ctx context.Context, app *models.KYCApplication, status models.KYCApplicationStatus) (*models.OutboxMessage, error) {
+	if status != models.KYCStatusApproved {
+		return nil, nil
+	}
+
+	return newOutboxMessage(ctx, events.KYCTierUpgraded, app.UserID.String(), events.KYCEvent{
+		UserID:        app.UserID.String(),
+		ApplicationID: app.ID.String(),
+		Tier:          string(app.RequestedTier),
+	})
+}
+
+func (s *KYCService) hasOpenApplication(userID uuid.UUID) (bool, error) {
+	apps, err := s.kycRepo.GetApplicationsByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, app := range apps {
+		if app.Status == models.KYCStatusPending || app.Status == models.KYCStatusInReview {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requireDocuments checks that the documents support the requested tier. Every
+// tier needs an identity document; the full tier also needs proof of address
+// and a selfie.
+func requireDocuments(tier models.KYCTier, uploads []models.KYCDocumentUpload) error {
+	var identity, address, selfie bool
+	for _, upload := range uploads {
+		switch {
+		case upload.Type.IsIdentity():
+			identity = true
+		case upload.Type == models.KYCDocProofOfAddress:
+			address = true
+		case upload.Type == models.KYCDocSelfie:
+			selfie = true
+		}
+	}
+
+	if !identity {
+		return fmt.Errorf("an identity document is required")
+	}
+	if tier == models.KYCTierFull && (!address || !selfie) {
+		return fmt.Errorf("the full tier requires proof of address and a selfie")
+	}
+	return nil
+}
@@ -0,0 +1,553 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LimitExceededError is returned when an operation would break a velocity limit
+type LimitExceededError struct {
+	Kind      models.LimitKind
+	Scope     models.LimitScope
+	Currency  string
+	Limit     float64
+	Used      float64
+	Requested float64
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Kind.IsCount() {
+		return fmt.Sprintf("%s limit exceeded: %.0f of %.0f used", e.Kind, e.Used, e.Limit)
+	}
+	return fmt.Sprintf("%s limit exceeded: %f %s used of %f %s, %f %s requested",
+		e.Kind, e.Used, e.Currency, e.Limit, e.Currency, e.Requested, e.Currency)
+}
+
+// kycLimitWindow is the rolling window tier limits apply to
+const kycLimitWindow = 24 * time.Hour
+
+// KYCLimitExceededError is returned when an operation would take a user past the
+// daily limit of their tier
+type KYCLimitExceededError struct {
+	Tier         models.KYCTier
+	Operation    models.LimitOperation
+	LimitUSD     float64
+	UsedUSD      float64
+	RequestedUSD float64
+}
+
+func (e *KYCLimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit of the %s tier exceeded: %.2f USD used of %.2f USD in 24h, %.2f USD requested",
+		e.Operation, e.Tier, e.UsedUSD, e.LimitUSD, e.RequestedUSD)
+}
+
+// limitStore keeps the limit rules and locks the usage of a user; it is
+// implemented by repositories.LimitRepository
+type limitStore interface {
+	GetApplicable(userID uuid.UUID, tier models.KYCTier) ([]*models.LimitRule, error)
+	GetAll(filter *models.LimitRuleFilter) ([]*models.LimitRule, error)
+	Upsert(rule *models.LimitRule) (*models.LimitRule, error)
+	Delete(id uuid.UUID) (*models.LimitRule, error)
+	LockUsage(tx *sql.Tx, userID uuid.UUID) error
+}
+
+// tierLimitStore reads the daily limits of KYC tiers; it is implemented by
+// repositories.KYCRepository
+type tierLimitStore interface {
+	GetTierLimits(tier models.KYCTier) (*models.KYCTierLimits, error)
+}
+
+// userLookup finds users; it is implemented by repositories.UserRepository
+type userLookup interface {
+	GetByID(id uuid.UUID) (*models.User, error)
+}
+
+// limitUsage totals the operations of a user that limits count. WithTx returns
+// a limitUsage reading inside the transaction.
+type limitUsage interface {
+	SumByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error)
+	CountByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error)
+	SumFromAmountsSince(userID uuid.UUID, since time.Time) (map[string]float64, error)
+	WithTx(dbTx *sql.Tx) limitUsage
+}
+
+// repoUsage reads usage from the transaction and exchange repositories
+type repoUsage struct {
+	txRepo       *repositories.TransactionRepository
+	exchangeRepo *repositories.ExchangeRepository
+}
+
+func (u repoUsage) SumByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error) {
+	return u.txRepo.SumByTypeSince(userID, txType, since)
+}
+
+func (u repoUsage) CountByTypeSince(userID uuid.UUID, txType models.TransactionType, since time.Time) (map[string]float64, error) {
+	return u.txRepo.CountByTypeSince(userID, txType, since)
+}
+
+func (u repoUsage) SumFromAmountsSince(userID uuid.UUID, since time.Time) (map[string]float64, error) {
+	return u.exchangeRepo.SumFromAmountsSince(userID, since)
+}
+
+func (u repoUsage) WithTx(dbTx *sql.Tx) limitUsage {
+	return repoUsage{txRepo: u.txRepo.WithTx(dbTx), exchangeRepo: u.exchangeRepo.WithTx(dbTx)}
+}
+
+// LimitService evaluates the daily limits of KYC tiers and velocity limits
+// defined globally, per KYC tier and per user before money moves
+type LimitService struct {
+	limitRepo limitStore
+	kycRepo   tierLimitStore
+	userRepo  userLookup
+	usage     limitUsage
+	assets    *AssetService
+	audit     auditRecorder
+}
+
+func NewLimitService(
+	limitRepo *repositories.LimitRepository,
+	kycRepo *repositories.KYCRepository,
+	userRepo *repositories.UserRepository,
+	txRepo *repositories.TransactionRepository,
+	exchangeRepo *repositories.ExchangeRepository,
+	assets *AssetService,
+	audit *AuditService,
+) *LimitService {
+	return &LimitService{
+		limitRepo: limitRepo,
+		kycRepo:   kycRepo,
+		userRepo:  userRepo,
+		usage:     repoUsage{txRepo: txRepo, exchangeRepo: exchangeRepo},
+		assets:    assets,
+		audit:     audit,
+	}
+}
+
+// Check returns a *KYCLimitExceededError when the operation would take the user
+// past the daily limit of their tier and a *LimitExceededError when it would
+// break one of their effective limits. It turns operations away before they are
+// assessed or held for approval; CheckTx decides.
+func (s *LimitService) Check(userID uuid.UUID, op models.LimitOperation, currency string, amount float64) error {
+	return s.check(s.usage, userID, op, currency, amount)
+}
+
+// CheckTx checks the limits inside the database transaction that debits the
+// user. The usage of the user stays locked until the transaction ends, so
+// concurrent operations of a user are checked one after the other, each
+// counting the ones committed before it.
+func (s *LimitService) CheckTx(dbTx *sql.Tx, userID uuid.UUID, op models.LimitOperation, currency string, amount float64) error {
+	if err := s.limitRepo.LockUsage(dbTx, userID); err != nil {
+		return err
+	}
+	return s.check(s.usage.WithTx(dbTx), userID, op, currency, amount)
+}
+
+// check compares the usage it reads against the tier limit and the
+// effective limits
+func (s *LimitService) check(
+	usage limitUsage,
+	userID uuid.UUID,
+	op models.LimitOperation,
+	currency string,
+	amount float64,
+) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.checkTier(usage, user, op, currency, amount, now); err != nil {
+		return err
+	}
+
+	rules, err := s.effectiveRules(user)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if !rule.Kind.AppliesTo(op) || (rule.Currency != nil && *rule.Currency != currency) {
+			continue
+		}
+
+		requested := amount
+		switch {
+		case rule.Kind.IsCount():
+			requested = 1
+		case rule.Currency == nil:
+			value, ok := s.assets.USDValue(currency, amount)
+			if !ok {
+				return fmt.Errorf("cannot check %s against the %s limit: no reference rate", currency, rule.Kind)
+			}
+			requested = value
+		}
+
+		used, err := s.used(usage, userID, rule, now)
+		if err != nil {
+			return err
+		}
+
+		if used+requested > rule.Value {
+			metrics.LimitRejectionsTotal.WithLabelValues(string(rule.Kind), string(rule.Scope)).Inc()
+			logger.Warn("Limit exceeded",
+				zap.String("user_id", userID.String()),
+				zap.String("kind", string(rule.Kind)),
+				zap.String("scope", string(rule.Scope)),
+				zap.String("currency", ruleCurrency(rule)),
+				zap.Float64("used", used),
+				zap.Float64("requested", requested),
+				zap.Float64("limit", rule.Value),
+			)
+			return &LimitExceededError{
+				Kind:      rule.Kind,
+				Scope:     rule.Scope,
+				Currency:  ruleCurrency(rule),
+				Limit:     rule.Value,
+				Used:      used,
+				Requested: requested,
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkTier returns a *KYCLimitExceededError when the amount would take the
+// user past the daily limit of their tier for the operation
+func (s *LimitService) checkTier(
+	usage limitUsage,
+	user *models.User,
+	op models.LimitOperation,
+	currency string,
+	amount float64,
+	now time.Time,
+) error {
+	limits, err := s.kycRepo.GetTierLimits(user.KYCTier)
+	if err != nil {
+		return err
+	}
+
+	requested, ok := s.assets.USDValue(currency, amount)
+	if !ok {
+		return fmt.Errorf("cannot check %s against the %s limit: no reference rate", currency, op)
+	}
+
+	used, err := s.tierUsed(usage, user.ID, op, now.Add(-kycLimitWindow))
+	if err != nil {
+		return err
+	}
+
+	limit := limits.Limit(op)
+	if used+requested > limit {
+		metrics.KYCLimitRejectionsTotal.WithLabelValues(string(user.KYCTier), string(op)).Inc()
+		logger.Warn("KYC limit exceeded",
+			zap.String("user_id", user.ID.String()),
+			zap.String("tier", string(user.KYCTier)),
+			zap.String("operation", string(op)),
+			zap.Float64("used_usd", used),
+			zap.Float64("requested_usd", requested),
+			zap.Float64("limit_usd", limit),
+		)
+		return &KYCLimitExceededError{
+			Tier:         user.KYCTier,
+			Operation:    op,
+			LimitUSD:     limit,
+			UsedUSD:      used,
+			RequestedUSD: requested,
+		}
+	}
+
+	return nil
+}
+
+// tierUsed returns the USD value of the operations of a user since the start
+// of the tier limit window
+func (s *LimitService) tierUsed(
+	usage limitUsage,
+	userID uuid.UUID,
+	op models.LimitOperation,
+	since time.Time,
+) (float64, error) {
+	var totals map[string]float64
+	var err error
+	switch op {
+	case models.LimitOpDeposit:
+		totals, err = usage.SumByTypeSince(userID, models.TransactionTypeDeposit, since)
+	case models.LimitOpWithdrawal:
+		totals, err = usage.SumByTypeSince(userID, models.TransactionTypeWithdraw, since)
+	case models.LimitOpTransfer:
+		totals, err = usage.SumByTypeSince(userID, models.TransactionTypeTransfer, since)
+	case models.LimitOpExchange:
+		totals, err = usage.SumFromAmountsSince(userID, since)
+	default:
+		return 0, fmt.Errorf("unknown KYC limit operation: %s", op)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var used float64
+	for currency, total := range totals {
+		value, ok := s.assets.USDValue(currency, total)
+		if !ok {
+			logger.Warn("Skipping usage without a reference rate",
+				zap.String("currency", currency),
+				zap.String("operation", string(op)),
+			)
+			continue
+		}
+		used += value
+	}
+
+	return used, nil
+}
+
+// GetUserLimits returns the effective limits of a user with the headroom left
+// in the current windows
+func (s *LimitService) GetUserLimits(userID uuid.UUID) (*models.UserLimits, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.effectiveRules(user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	headroom := make([]*models.LimitHeadroom, 0, len(rules))
+	for _, rule := range rules {
+		used, err := s.used(s.usage, userID, rule, now)
+		if err != nil {
+			return nil, err
+		}
+
+		limit := &models.LimitHeadroom{
+			Kind:      rule.Kind,
+			Scope:     rule.Scope,
+			Currency:  rule.Currency,
+			Limit:     rule.Value,
+			Used:      used,
+			Remaining: math.Max(0, rule.Value-used),
+		}
+		if _, end, windowed := limitWindow(rule.Kind, now); windowed {
+			limit.ResetsAt = &end
+		}
+		headroom = append(headroom, limit)
+	}
+
+	return &models.UserLimits{
+		UserID:  user.ID,
+		KYCTier: user.KYCTier,
+		Limits:  headroom,
+	}, nil
+}
+
+// GetRules lists rules for the admin API
+func (s *LimitService) GetRules(filter *models.LimitRuleFilter) ([]*models.LimitRule, error) {
+	return s.limitRepo.GetAll(filter)
+}
+
+// SetRule creates or replaces the rule for a scope, kind and currency
+func (s *LimitService) SetRule(ctx context.Context, req *models.SetLimitRuleRequest) (*models.LimitRule, error) {
+	rule := &models.LimitRule{
+		Scope:    req.Scope,
+		Kind:     req.Kind,
+		Currency: req.Currency,
+		Value:    req.Value,
+	}
+
+	if (req.KYCTier != nil && req.Scope != models.LimitScopeTier) || (req.UserID != nil && req.Scope != models.LimitScopeUser) {
+		return nil, fmt.Errorf("kyc_tier and user_id only apply to the tier and user scopes")
+	}
+
+	switch req.Scope {
+	case models.LimitScopeTier:
+		rule.KYCTier = req.KYCTier
+	case models.LimitScopeUser:
+		if _, err := s.userRepo.GetByID(*req.UserID); err != nil {
+			return nil, err
+		}
+		rule.UserID = req.UserID
+	}
+
+	if rule.Kind.IsCount() && rule.Value != math.Trunc(rule.Value) {
+		return nil, fmt.Errorf("%s must be a whole number", rule.Kind)
+	}
+
+	if rule.Currency != nil {
+		if !s.assets.Has(*rule.Currency) {
+			return nil, fmt.Errorf("unknown currency: %s", *rule.Currency)
+		}
+	}
+
+	replaced, err := s.limitRepo.Upsert(rule)
+	if err != nil {
+		logger.Error("Failed to save limit rule", zap.Error(err))
+		return nil, err
+	}
+	s.audit.RecordContext(ctx, models.AuditLimitRuleSet, models.AuditEntityLimitRule, rule.ID.String(), replaced, rule)
+
+	logger.Info("Limit rule saved",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("scope", string(rule.Scope)),
+		zap.String("kind", string(rule.Kind)),
+		zap.String("currency", ruleCurrency(rule)),
+		zap.Float64("value", rule.Value),
+	)
+	return rule, nil
+}
+
+// DeleteRule removes a rule; the next less specific rule takes over
+func (s *LimitService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	rule, err := s.limitRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	s.audit.RecordContext(ctx, models.AuditLimitRuleDeleted, models.AuditEntityLimitRule, id.String(), rule, nil)
+
+	logger.Info("Limit rule deleted", zap.String("rule_id", id.String()))
+	return nil
+}
+
+// effectiveRules keeps the most specific rule for every kind and currency
+func (s *LimitService) effectiveRules(user *models.User) ([]*models.LimitRule, error) {
+	rules, err := s.limitRepo.GetApplicable(user.ID, user.KYCTier)
+	if err != nil {
+		return nil, err
+	}
+
+	type ruleKey struct {
+		kind     models.LimitKind
+		currency string
+	}
+
+	effective := make(map[ruleKey]*models.LimitRule)
+	for _, rule := range rules {
+		// "*" keeps aggregate rules apart from rules on a single currency
+		key := ruleKey{kind: rule.Kind, currency: "*"}
+		if rule.Currency != nil {
+			key.currency = *rule.Currency
+		}
+		if current, ok := effective[key]; !ok || rule.Scope.Precedence() > current.Scope.Precedence() {
+			effective[key] = rule
+		}
+	}
+
+	result := make([]*models.LimitRule, 0, len(effective))
+	for _, rule := range effective {
+		result = append(result, rule)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return ruleCurrency(result[i]) < ruleCurrency(result[j])
+	})
+
+	return result, nil
+}
+
+// used returns the usage a rule counts in its current window, in the rule
+// currency, in US dollars for rules without one, or as a number of operations
+func (s *LimitService) used(
+	usage limitUsage,
+	userID uuid.UUID,
+	rule *models.LimitRule,
+	now time.Time,
+) (float64, error) {
+	since, _, windowed := limitWindow(rule.Kind, now)
+	if !windowed {
+		return 0, nil
+	}
+
+	totals := make(map[string]float64)
+	add := func(m map[string]float64, err error) error {
+		if err != nil {
+			return err
+		}
+		for currency, total := range m {
+			totals[currency] += total
+		}
+		return nil
+	}
+
+	var err error
+	switch rule.Kind {
+	case models.LimitDailyOutflow, models.LimitMonthlyOutflow:
+		err = add(usage.SumByTypeSince(userID, models.TransactionTypeTransfer, since))
+		if err == nil {
+			err = add(usage.SumByTypeSince(userID, models.TransactionTypeWithdraw, since))
+		}
+	case models.LimitDailyWithdrawalCount:
+		err = add(usage.CountByTypeSince(userID, models.TransactionTypeWithdraw, since))
+	case models.LimitDailyExchangeVolume, models.LimitMonthlyExchangeVolume:
+		err = add(usage.SumFromAmountsSince(userID, since))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if rule.Currency != nil {
+		return totals[*rule.Currency], nil
+	}
+
+	var used float64
+	for currency, total := range totals {
+		if rule.Kind.IsCount() {
+			used += total
+			continue
+		}
+		value, ok := s.assets.USDValue(currency, total)
+		if !ok {
+			logger.Warn("Skipping usage without a reference rate",
+				zap.String("currency", currency),
+				zap.String("kind", string(rule.Kind)),
+			)
+			continue
+		}
+		used += value
+	}
+
+	return used, nil
+}
+
+// limitWindow returns the UTC calendar window a limit counts usage in. Per
+// operation limits have no window.
+func limitWindow(kind models.LimitKind, now time.Time) (time.Time, time.Time, bool) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch kind {
+	case models.LimitDailyOutflow, models.LimitDailyWithdrawalCount, models.LimitDailyExchangeVolume:
+		return day, day.AddDate(0, 0, 1), true
+	case models.LimitMonthlyOutflow, models.LimitMonthlyExchangeVolume:
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return month, month.AddDate(0, 1, 0), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// ruleCurrency names the unit of a rule
+func ruleCurrency(rule *models.LimitRule) string {
+	if rule.Currency != nil {
+		return *rule.Currency
+	}
+	if rule.Kind.IsCount() {
+		return ""
+	}
+	return "USD"
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryLimits hands out a fixed set of rules and logs the usage locks taken
+type memoryLimits struct {
+	rules   []*models.LimitRule
+	lockErr error
+	log     *[]string
+}
+
+func (m *memoryLimits) GetApplicable(uuid.UUID, models.KYCTier) ([]*models.LimitRule, error) {
+	return m.rules, nil
+}
+
+func (m *memoryLimits) GetAll(*models.LimitRuleFilter) ([]*models.LimitRule, error) {
+	return m.rules, nil
+}
+
+func (m *memoryLimits) Upsert(rule *models.LimitRule) (*models.LimitRule, error) {
+	m.rules = append(m.rules, rule)
+	return nil, nil
+}
+
+func (m *memoryLimits) Delete(uuid.UUID) (*models.LimitRule, error) {
+	return nil, fmt.Errorf("limit rule not found")
+}
+
+func (m *memoryLimits) LockUsage(tx *sql.Tx, _ uuid.UUID) error {
+	if tx == nil {
+		return errors.New("usage locked outside a transaction")
+	}
+	*m.log = append(*m.log, "lock")
+	return m.lockErr
+}
+
+type tierLimits map[models.KYCTier]*models.KYCTierLimits
+
+func (l tierLimits) GetTierLimits(tier models.KYCTier) (*models.KYCTierLimits, error) {
+	limits, ok := l[tier]
+	if !ok {
+		return nil, fmt.Errorf("no limits for tier %s", tier)
+	}
+	return limits, nil
+}
+
+type oneUser struct {
+	user *models.User
+}
+
+func (u oneUser) GetByID(id uuid.UUID) (*models.User, error) {
+	if id != u.user.ID {
+		return nil, fmt.Errorf("user not found")
+	}
+	return u.user, nil
+}
+
+// recordedUsage answers usage reads from fixed totals and logs every read,
+// prefixed with "tx" when it runs inside a transaction
+type recordedUsage struct {
+	tx        *sql.Tx
+	sums      map[models.TransactionType]map[string]float64
+	counts    map[models.TransactionType]map[string]float64
+	exchanges map[string]float64
+	log       *[]string
+}
+
+func (u recordedUsage) record(read string) {
+	if u.tx != nil {
+		read = "tx " + read
+	}
+	*u.log = append(*u.log, read)
+}
+
+func (u recordedUsage) SumByTypeSince(_ uuid.UUID, txType models.TransactionType, _ time.Time) (map[string]float64, error) {
+	u.record("sum " + string(txType))
+	return u.sums[txType], nil
+}
+
+func (u recordedUsage) CountByTypeSince(_ uuid.UUID, txType models.TransactionType, _ time.Time) (map[string]float64, error) {
+	u.record("count " + string(txType))
+	return u.counts[txType], nil
+}
+
+func (u recordedUsage) SumFromAmountsSince(uuid.UUID, time.Time) (map[string]float64, error) {
+	u.record("sum exchange")
+	return u.exchanges, nil
+}
+
+func (u recordedUsage) WithTx(dbTx *sql.Tx) limitUsage {
+	u.tx = dbTx
+	return u
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// newLimitFixture builds a LimitService for a basic tier user with a daily
+// withdrawal cap of 1000 USD. EUR is worth 1.1 USD.
+func newLimitFixture(rules []*models.LimitRule, usage recordedUsage) (*LimitService, *memoryLimits, uuid.UUID) {
+	logger.Log = zap.NewNop()
+
+	user := &models.User{ID: uuid.New(), KYCTier: models.KYCTierBasic}
+	limits := &memoryLimits{rules: rules, log: usage.log}
+	s := &LimitService{
+		limitRepo: limits,
+		kycRepo: tierLimits{models.KYCTierBasic: {
+			Tier:               models.KYCTierBasic,
+			DepositDailyUSD:    5000,
+			WithdrawalDailyUSD: 1000,
+			TransferDailyUSD:   2000,
+			ExchangeDailyUSD:   3000,
+		}},
+		userRepo: oneUser{user: user},
+		usage:    usage,
+		assets: &AssetService{assets: map[string]*models.Asset{
+			"USD": {Code: "USD"},
+			"EUR": {Code: "EUR", ReferenceUSDRate: floatPtr(1.1)},
+		}},
+	}
+	return s, limits, user.ID
+}
+
+func TestLimitWindow(t *testing.T) {
+	plus2 := time.FixedZone("UTC+2", 2*60*60)
+	minus5 := time.FixedZone("UTC-5", -5*60*60)
+	utc := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		kind     models.LimitKind
+		now      time.Time
+		start    time.Time
+		end      time.Time
+		windowed bool
+	}{
+		{
+			name:     "daily at midnight",
+			kind:     models.LimitDailyOutflow,
+			now:      utc(2024, time.March, 10),
+			start:    utc(2024, time.March, 10),
+			end:      utc(2024, time.March, 11),
+			windowed: true,
+		},
+		{
+			name:     "daily just before midnight",
+			kind:     models.LimitDailyWithdrawalCount,
+			now:      time.Date(2024, time.March, 10, 23, 59, 59, 999999999, time.UTC),
+			start:    utc(2024, time.March, 10),
+			end:      utc(2024, time.March, 11),
+			windowed: true,
+		},
+		{
+			name:     "daily after local midnight but before UTC midnight",
+			kind:     models.LimitDailyExchangeVolume,
+			now:      time.Date(2024, time.March, 11, 1, 30, 0, 0, plus2),
+			start:    utc(2024, time.March, 10),
+			end:      utc(2024, time.March, 11),
+			windowed: true,
+		},
+		{
+			name:     "daily across the end of a month",
+			kind:     models.LimitDailyOutflow,
+			now:      time.Date(2024, time.April, 30, 12, 0, 0, 0, time.UTC),
+			start:    utc(2024, time.April, 30),
+			end:      utc(2024, time.May, 1),
+			windowed: true,
+		},
+		{
+			name:     "monthly on the last instant of the month",
+			kind:     models.LimitMonthlyOutflow,
+			now:      time.Date(2024, time.January, 31, 23, 59, 59, 999999999, time.UTC),
+			start:    utc(2024, time.January, 1),
+			end:      utc(2024, time.February, 1),
+			windowed: true,
+		},
+		{
+			name:     "monthly on the first instant of the month",
+			kind:     models.LimitMonthlyOutflow,
+			now:      utc(2024, time.February, 1),
+			start:    utc(2024, time.February, 1),
+			end:      utc(2024, time.March, 1),
+			windowed: true,
+		},
+		{
+			name:     "monthly in a leap February",
+			kind:     models.LimitMonthlyExchangeVolume,
+			now:      time.Date(2024, time.February, 29, 8, 0, 0, 0, time.UTC),
+			start:    utc(2024, time.February, 1),
+			end:      utc(2024, time.March, 1),
+			windowed: true,
+		},
+		{
+			name:     "monthly in December",
+			kind:     models.LimitMonthlyExchangeVolume,
+			now:      time.Date(2024, time.December, 31, 18, 0, 0, 0, time.UTC),
+			start:    utc(2024, time.December, 1),
+			end:      utc(2025, time.January, 1),
+			windowed: true,
+		},
+		{
+			name:     "monthly before local midnight but after UTC midnight",
+			kind:     models.LimitMonthlyOutflow,
+			now:      time.Date(2024, time.March, 31, 22, 0, 0, 0, minus5),
+			start:    utc(2024, time.April, 1),
+			end:      utc(2024, time.May, 1),
+			windowed: true,
+		},
+		{
+			name: "per operation",
+			kind: models.LimitMaxSingleTransfer,
+			now:  utc(2024, time.March, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, windowed := limitWindow(tt.kind, tt.now)
+			if windowed != tt.windowed || !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("limitWindow(%s, %s) = [%s, %s) %v, want [%s, %s) %v",
+					tt.kind, tt.now, start, end, windowed, tt.start, tt.end, tt.windowed)
+			}
+			if windowed && (start.Location() != time.UTC || end.Location() != time.UTC) {
+				t.Errorf("window [%s, %s) is not in UTC", start, end)
+			}
+		})
+	}
+}
+
+func TestLimitEffectiveRules(t *testing.T) {
+	tier := models.KYCTierBasic
+	userID := uuid.New()
+	rule := func(scope models.LimitScope, kind models.LimitKind, code string, value float64) *models.LimitRule {
+		r := &models.LimitRule{ID: uuid.New(), Scope: scope, Kind: kind, Value: value}
+		switch scope {
+		case models.LimitScopeTier:
+			r.KYCTier = &tier
+		case models.LimitScopeUser:
+			r.UserID = &userID
+		}
+		if code != "" {
+			r.Currency = strPtr(code)
+		}
+		return r
+	}
+
+	globalOutflow := rule(models.LimitScopeGlobal, models.LimitDailyOutflow, "", 10000)
+	tierOutflow := rule(models.LimitScopeTier, models.LimitDailyOutflow, "", 5000)
+	userOutflow := rule(models.LimitScopeUser, models.LimitDailyOutflow, "", 20000)
+	globalEUROutflow := rule(models.LimitScopeGlobal, models.LimitDailyOutflow, "EUR", 3000)
+	tierEUROutflow := rule(models.LimitScopeTier, models.LimitDailyOutflow, "EUR", 4000)
+	globalCount := rule(models.LimitScopeGlobal, models.LimitDailyWithdrawalCount, "", 5)
+	tierSingle := rule(models.LimitScopeTier, models.LimitMaxSingleTransfer, "", 1500)
+
+	tests := []struct {
+		name  string
+		rules []*models.LimitRule
+		want  []*models.LimitRule
+	}{
+		{
+			name: "no rules",
+			want: []*models.LimitRule{},
+		},
+		{
+			name:  "global rule alone",
+			rules: []*models.LimitRule{globalOutflow},
+			want:  []*models.LimitRule{globalOutflow},
+		},
+		{
+			name:  "tier rule over global rule",
+			rules: []*models.LimitRule{globalOutflow, tierOutflow},
+			want:  []*models.LimitRule{tierOutflow},
+		},
+		{
+			name:  "user rule over tier and global rules",
+			rules: []*models.LimitRule{globalOutflow, tierOutflow, userOutflow},
+			want:  []*models.LimitRule{userOutflow},
+		},
+		{
+			name:  "user rule listed before less specific rules",
+			rules: []*models.LimitRule{userOutflow, globalOutflow, tierOutflow},
+			want:  []*models.LimitRule{userOutflow},
+		},
+		{
+			name:  "a more specific rule may be looser",
+			rules: []*models.LimitRule{userOutflow, globalOutflow},
+			want:  []*models.LimitRule{userOutflow},
+		},
+		{
+			name:  "currency rules apart from aggregate rules, ordered by currency",
+			rules: []*models.LimitRule{tierEUROutflow, userOutflow, globalEUROutflow, tierOutflow},
+			want:  []*models.LimitRule{tierEUROutflow, userOutflow},
+		},
+		{
+			name:  "rules of other kinds kept, ordered by kind",
+			rules: []*models.LimitRule{tierSingle, globalCount, tierOutflow, globalOutflow},
+			want:  []*models.LimitRule{tierOutflow, globalCount, tierSingle},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newLimitFixture(tt.rules, recordedUsage{log: new([]string)})
+
+			got, err := s.effectiveRules(&models.User{ID: userID, KYCTier: tier})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("effective rules:\n got  %s\n want %s", describeRules(got), describeRules(tt.want))
+			}
+		})
+	}
+}
+
+func describeRules(rules []*models.LimitRule) []string {
+	described := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		described = append(described, fmt.Sprintf("%s %s %s %v", rule.Scope, rule.Kind, ruleCurrency(rule), rule.Value))
+	}
+	return described
+}
+
+func TestLimitCheckTx(t *testing.T) {
+	globalOutflow := &models.LimitRule{Scope: models.LimitScopeGlobal, Kind: models.LimitDailyOutflow, Value: 1450}
+	userEURCount := &models.LimitRule{Scope: models.LimitScopeUser, Kind: models.LimitDailyWithdrawalCount, Currency: strPtr("EUR"), Value: 3}
+
+	tests := []struct {
+		name     string
+		rules    []*models.LimitRule
+		sums     map[models.TransactionType]map[string]float64
+		counts   map[models.TransactionType]map[string]float64
+		amount   float64
+		lockErr  error
+		wantErr  error
+		wantRead []string
+	}{
+		{
+			name:   "within the limits",
+			rules:  []*models.LimitRule{globalOutflow, userEURCount},
+			sums:   map[models.TransactionType]map[string]float64{models.TransactionTypeWithdraw: {"EUR": 100}},
+			counts: map[models.TransactionType]map[string]float64{models.TransactionTypeWithdraw: {"EUR": 2}},
+			amount: 100,
+			wantRead: []string{
+				"lock",
+				"tx sum WITHDRAW",
+				"tx sum TRANSFER", "tx sum WITHDRAW",
+				"tx count WITHDRAW",
+			},
+		},
+		{
+			name:     "lock failure reads nothing",
+			rules:    []*models.LimitRule{globalOutflow},
+			amount:   100,
+			lockErr:  errors.New("failed to lock limit usage: deadlock detected"),
+			wantErr:  errors.New("failed to lock limit usage: deadlock detected"),
+			wantRead: []string{"lock"},
+		},
+		{
+			name:   "tier limit counts the usage in the transaction",
+			rules:  []*models.LimitRule{globalOutflow},
+			sums:   map[models.TransactionType]map[string]float64{models.TransactionTypeWithdraw: {"EUR": 800, "USD": 100}},
+			amount: 20,
+			wantErr: &KYCLimitExceededError{
+				Tier:         models.KYCTierBasic,
+				Operation:    models.LimitOpWithdrawal,
+				LimitUSD:     1000,
+				UsedUSD:      980,
+				RequestedUSD: 22,
+			},
+			wantRead: []string{"lock", "tx sum WITHDRAW"},
+		},
+		{
+			name:  "velocity limit counts the usage in the transaction",
+			rules: []*models.LimitRule{globalOutflow},
+			sums: map[models.TransactionType]map[string]float64{
+				models.TransactionTypeWithdraw: {"USD": 500},
+				models.TransactionTypeTransfer: {"EUR": 800},
+			},
+			amount: 100,
+			wantErr: &LimitExceededError{
+				Kind:      models.LimitDailyOutflow,
+				Scope:     models.LimitScopeGlobal,
+				Currency:  "USD",
+				Limit:     1450,
+				Used:      1380,
+				Requested: 110,
+			},
+			wantRead: []string{"lock", "tx sum WITHDRAW", "tx sum TRANSFER", "tx sum WITHDRAW"},
+		},
+		{
+			name:   "count limit on the currency",
+			rules:  []*models.LimitRule{userEURCount},
+			counts: map[models.TransactionType]map[string]float64{models.TransactionTypeWithdraw: {"EUR": 3, "USD": 4}},
+			amount: 1,
+			wantErr: &LimitExceededError{
+				Kind:      models.LimitDailyWithdrawalCount,
+				Scope:     models.LimitScopeUser,
+				Currency:  "EUR",
+				Limit:     3,
+				Used:      3,
+				Requested: 1,
+			},
+			wantRead: []string{"lock", "tx sum WITHDRAW", "tx count WITHDRAW"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := recordedUsage{sums: tt.sums, counts: tt.counts, log: new([]string)}
+			s, limits, userID := newLimitFixture(tt.rules, usage)
+			limits.lockErr = tt.lockErr
+
+			err := s.CheckTx(&sql.Tx{}, userID, models.LimitOpWithdrawal, "EUR", tt.amount)
+			if !sameError(err, tt.wantErr) {
+				t.Errorf("CheckTx error = %#v, want %#v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(*usage.log, tt.wantRead) {
+				t.Errorf("reads = %v, want %v", *usage.log, tt.wantRead)
+			}
+		})
+	}
+}
+
+// sameError compares typed limit errors field by field and other errors by
+// message
+func sameError(got, want error) bool {
+	var gotTier, wantTier *KYCLimitExceededError
+	if errors.As(want, &wantTier) {
+		return errors.As(got, &gotTier) && approxEqual(gotTier.UsedUSD, wantTier.UsedUSD) &&
+			approxEqual(gotTier.RequestedUSD, wantTier.RequestedUSD) &&
+			gotTier.Tier == wantTier.Tier && gotTier.Operation == wantTier.Operation && gotTier.LimitUSD == wantTier.LimitUSD
+	}
+	var gotLimit, wantLimit *LimitExceededError
+	if errors.As(want, &wantLimit) {
+		return errors.As(got, &gotLimit) && approxEqual(gotLimit.Used, wantLimit.Used) &&
+			approxEqual(gotLimit.Requested, wantLimit.Requested) &&
+			gotLimit.Kind == wantLimit.Kind && gotLimit.Scope == wantLimit.Scope &&
+			gotLimit.Currency == wantLimit.Currency && gotLimit.Limit == wantLimit.Limit
+	}
+	if got == nil || want == nil {
+		return got == want
+	}
+	return got.Error() == want.Error()
+}
+
+func approxEqual(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+func TestLimitCheckOutsideTransaction(t *testing.T) {
+	usage := recordedUsage{log: new([]string)}
+	s, _, userID := newLimitFixture([]*models.LimitRule{
+		{Scope: models.LimitScopeGlobal, Kind: models.LimitDailyOutflow, Value: 1500},
+	}, usage)
+
+	if err := s.Check(userID, models.LimitOpWithdrawal, "USD", 100); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"sum WITHDRAW", "sum TRANSFER", "sum WITHDRAW"}; !reflect.DeepEqual(*usage.log, want) {
+		t.Errorf("reads = %v, want %v without a lock", *usage.log, want)
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Lockout counts failed password and second factor checks of a user and locks
+// the user out once maxFailures pile up. Login, step-up and the two-factor
+// management endpoints share the counter, so none of them can be used to
+// guess a password or code.
+type Lockout struct {
+	credentialRepo *repositories.CredentialRepository
+	maxFailures    int
+	duration       time.Duration
+}
+
+func NewLockout(credentialRepo *repositories.CredentialRepository, maxFailures int, duration time.Duration) *Lockout {
+	return &Lockout{
+		credentialRepo: credentialRepo,
+		maxFailures:    maxFailures,
+		duration:       duration,
+	}
+}
+
+// Check returns an *AccountLockedError while the user is locked out
+func (l *Lockout) Check(userID uuid.UUID) error {
+	credential, err := l.credentialRepo.GetCredential(userID)
+	if err != nil {
+		return ErrInvalidCredentials
+	}
+	return lockedOut(credential)
+}
+
+// Failed records a failed check and returns err, or the lockout if the user
+// just reached the limit
+func (l *Lockout) Failed(userID uuid.UUID, err error) error {
+	lockedUntil, recordErr := l.credentialRepo.RecordFailedLogin(userID, l.maxFailures, l.duration)
+	if recordErr != nil {
+		logger.Error("Failed to record failed login", zap.String("user_id", userID.String()), zap.Error(recordErr))
+		return err
+	}
+
+	if lockedUntil != nil {
+		logger.Warn("User locked out after repeated login failures",
+			zap.String("user_id", userID.String()),
+			zap.Time("locked_until", *lockedUntil),
+		)
+		return &AccountLockedError{Until: *lockedUntil}
+	}
+	return err
+}
+
+func lockedOut(credential *models.UserCredential) error {
+	if credential.LockedUntil != nil && time.Now().Before(*credential.LockedUntil) {
+		return &AccountLockedError{Until: *credential.LockedUntil}
+	}
+	return nil
+}
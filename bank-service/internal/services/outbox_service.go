@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+// OutboxSettings configures the outbox relay
+type OutboxSettings struct {
+	BatchSize int
+	// ClaimTimeout is how long a claimed batch is kept from other relays
+	ClaimTimeout   time.Duration
+	PublishTimeout time.Duration
+	// RetryBaseDelay doubles with every failed attempt up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Retention      time.Duration
+	// ContentMode is the CloudEvents content mode messages are published in
+	ContentMode events.ContentMode
+}
+
+// OutboxService relays events stored in the outbox to RabbitMQ. Services add
+// events in the transaction of the change they announce, so an event exists
+// exactly when its change was committed, and the relay retries until the
+// broker confirms it.
+type OutboxService struct {
+	outboxRepo *repositories.OutboxRepository
+	rabbitMQ   *broker.Client
+	settings   OutboxSettings
+}
+
+func NewOutboxService(outboxRepo *repositories.OutboxRepository, rabbitMQ *broker.Client, settings OutboxSettings) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		rabbitMQ:   rabbitMQ,
+		settings:   settings,
+	}
+}
+
+// eventProducer names the bank service in event envelopes
+const eventProducer = "bank-service"
+
+// newOutboxMessage wraps an event about the entity with the given ID in its
+// envelope for bank.events. The envelope ID doubles as the AMQP message ID.
+// The trace of ctx is kept with the message so that the publish, and the
+// consumers after it, continue the trace of the request that caused it.
+func newOutboxMessage(ctx context.Context, eventType, subject string, event interface{}) (*models.OutboxMessage, error) {
+	messageID := uuid.New()
+	envelope, err := events.New(messageID.String(), eventType, eventProducer, "", time.Now(), event)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Subject = subject
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+
+	return &models.OutboxMessage{
+		MessageID:    messageID,
+		Exchange:     rabbitmq.ExchangeEvents,
+		RoutingKey:   eventType,
+		Payload:      payload,
+		TraceContext: traceContext,
+	}, nil
+}
+
+// Relay publishes due messages batch by batch until the outbox is drained or
+// a publish fails, then refreshes the lag metrics
+func (s *OutboxService) Relay() {
+	for {
+		claimed, err := s.relayBatch()
+		if err != nil {
+			logger.Error("Failed to relay outbox messages", zap.Error(err))
+			break
+		}
+		if claimed < s.settings.BatchSize {
+			break
+		}
+	}
+
+	s.updateLagMetrics()
+}
+
+// relayBatch publishes one claimed batch in order. The first failure ends the
+// batch, since the broker is most likely unavailable, and hands the remaining
+// messages back for the next run.
+func (s *OutboxService) relayBatch() (int, error) {
+	now := time.Now()
+	messages, err := s.outboxRepo.Claim(s.settings.BatchSize, now, now.Add(s.settings.ClaimTimeout))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, msg := range messages {
+		if err := s.publish(msg); err != nil {
+			metrics.OutboxPublishFailuresTotal.WithLabelValues(msg.RoutingKey).Inc()
+
+			retryAt := time.Now().Add(s.retryDelay(msg.Attempts))
+			if markErr := s.outboxRepo.MarkFailed(msg.ID, err.Error(), retryAt); markErr != nil {
+				logger.Error("Failed to record outbox publish failure", zap.Int64("id", msg.ID), zap.Error(markErr))
+			}
+
+			rest := make([]int64, 0, len(messages)-i-1)
+			for _, pending := range messages[i+1:] {
+				rest = append(rest, pending.ID)
+			}
+			if releaseErr := s.outboxRepo.Release(rest, time.Now()); releaseErr != nil {
+				logger.Error("Failed to release outbox messages", zap.Error(releaseErr))
+			}
+
+			return i, fmt.Errorf("failed to publish %s message %s (attempt %d): %w",
+				msg.RoutingKey, msg.MessageID, msg.Attempts+1, err)
+		}
+
+		sentAt := time.Now()
+		// The broker has the message; if marking fails it is published again later
+		if err := s.outboxRepo.MarkSent(msg.ID, sentAt); err != nil {
+			return i, err
+		}
+
+		metrics.OutboxPublishedTotal.WithLabelValues(msg.RoutingKey).Inc()
+		metrics.OutboxPublishLag.Observe(sentAt.Sub(msg.CreatedAt).Seconds())
+	}
+
+	return len(messages), nil
+}
+
+// publish sends a message as a CloudEvent, mandatory so that events no queue
+// is bound for are noticed. The broker drops those; retrying would not change
+// that, so they count as sent.
+func (s *OutboxService) publish(msg *models.OutboxMessage) error {
+	publishing, err := s.cloudEvent(msg)
+	if err != nil {
+		return err
+	}
+	publishing.DeliveryMode = amqp.Persistent
+
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(msg.TraceContext))
+	ctx, cancel := context.WithTimeout(ctx, s.settings.PublishTimeout)
+	defer cancel()
+
+	err = s.rabbitMQ.Publish(ctx, msg.Exchange, msg.RoutingKey, true, publishing)
+	if errors.Is(err, broker.ErrUnroutable) {
+		logger.Warn("No queue is bound for outbox event",
+			zap.String("routing_key", msg.RoutingKey),
+			zap.String("message_id", msg.MessageID.String()),
+		)
+		return nil
+	}
+	return err
+}
+
+// cloudEvent lays a stored envelope out in the configured content mode.
+// Messages stored before the envelope hold bare data and get their ID and
+// time from the outbox row.
+func (s *OutboxService) cloudEvent(msg *models.OutboxMessage) (amqp.Publishing, error) {
+	envelope, err := events.Parse(msg.RoutingKey, msg.Payload)
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+	if envelope.ID == "" {
+		envelope.ID = msg.MessageID.String()
+		envelope.CorrelationID = envelope.ID
+		envelope.OccurredAt = msg.CreatedAt
+		envelope.Producer = eventProducer
+	}
+
+	return events.ToAMQP(envelope, s.settings.ContentMode)
+}
+
+// retryDelay backs off exponentially with the number of failed attempts
+func (s *OutboxService) retryDelay(attempts int) time.Duration {
+	delay := s.settings.RetryBaseDelay
+	for i := 0; i < attempts && delay < s.settings.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.settings.RetryMaxDelay {
+		delay = s.settings.RetryMaxDelay
+	}
+	return delay
+}
+
+func (s *OutboxService) updateLagMetrics() {
+	stats, err := s.outboxRepo.Stats()
+	if err != nil {
+		logger.Error("Failed to get outbox stats", zap.Error(err))
+		return
+	}
+
+	metrics.OutboxPendingMessages.Set(float64(stats.Pending))
+	if stats.OldestPending == nil {
+		metrics.OutboxOldestPendingAge.Set(0)
+		return
+	}
+	metrics.OutboxOldestPendingAge.Set(time.Since(*stats.OldestPending).Seconds())
+}
+
+// PurgeSent drops published messages past the retention period
+func (s *OutboxService) PurgeSent() {
+	purged, err := s.outboxRepo.PurgeSent(time.Now().Add(-s.settings.Retention))
+	if err != nil {
+		logger.Error("Failed to purge outbox", zap.Error(err))
+		return
+	}
+
+	if purged > 0 {
+		logger.Info("Purged published outbox messages", zap.Int64("count", purged))
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/merkle"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReservesService struct {
+	reservesRepo *repositories.ReservesRepository
+	treasuryRepo *repositories.TreasuryRepository
+}
+
+func NewReservesService(
+	reservesRepo *repositories.ReservesRepository,
+	treasuryRepo *repositories.TreasuryRepository,
+) *ReservesService {
+	return &ReservesService{
+		reservesRepo: reservesRepo,
+		treasuryRepo: treasuryRepo,
+	}
+}
+
+// CreateSnapshot snapshots customer crypto balances and builds one Merkle sum tree
+// per crypto type with a fresh random salt for every leaf
+func (s *ReservesService) CreateSnapshot() (*models.ReserveSnapshot, error) {
+	balances, err := s.reservesRepo.GetUserBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.treasuryRepo.GetWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := make(map[models.CryptoType]float64)
+	for _, wallet := range wallets {
+		holdings[wallet.CryptoType] += wallet.Balance
+	}
+
+	type pendingLeaf struct {
+		leaf models.ReserveLeaf
+		node merkle.Node
+	}
+	byType := make(map[models.CryptoType][]pendingLeaf)
+	for _, balance := range balances {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		units := merkle.ToUnits(balance.Balance)
+		node := merkle.Leaf(balance.UserID.String(), salt, units)
+		byType[balance.CryptoType] = append(byType[balance.CryptoType], pendingLeaf{
+			leaf: models.ReserveLeaf{
+				CryptoType: balance.CryptoType,
+				UserID:     balance.UserID,
+				Salt:       hex.EncodeToString(salt),
+				Balance:    units,
+				LeafHash:   hex.EncodeToString(node.Hash[:]),
+			},
+			node: node,
+		})
+	}
+
+	snapshot := &models.ReserveSnapshot{}
+	var leaves []models.ReserveLeaf
+	for cryptoType, pending := range byType {
+		// Order leaves by hash so tree position reveals nothing about the user
+		sort.Slice(pending, func(i, j int) bool {
+			return bytes.Compare(pending[i].node.Hash[:], pending[j].node.Hash[:]) < 0
+		})
+
+		nodes := make([]merkle.Node, len(pending))
+		for i := range pending {
+			pending[i].leaf.LeafIndex = i
+			nodes[i] = pending[i].node
+			leaves = append(leaves, pending[i].leaf)
+		}
+
+		tree, err := merkle.Build(nodes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s tree: %w", cryptoType, err)
+		}
+
+		root := tree.Root()
+		snapshot.Roots = append(snapshot.Roots, models.ReserveRoot{
+			CryptoType:       cryptoType,
+			RootHash:         hex.EncodeToString(root.Hash[:]),
+			RootSum:          root.Sum,
+			TotalLiabilities: merkle.FromUnits(root.Sum),
+			HouseHoldings:    holdings[cryptoType],
+			FullyCovered:     holdings[cryptoType] >= merkle.FromUnits(root.Sum),
+			LeafCount:        len(nodes),
+		})
+	}
+
+	sort.Slice(snapshot.Roots, func(i, j int) bool {
+		return snapshot.Roots[i].CryptoType < snapshot.Roots[j].CryptoType
+	})
+
+	if err := s.reservesRepo.CreateSnapshot(snapshot, leaves); err != nil {
+		logger.Error("Failed to store reserve snapshot", zap.Error(err))
+		return nil, err
+	}
+
+	for _, root := range snapshot.Roots {
+		if !root.FullyCovered {
+			logger.Warn("Reserves do not cover customer liabilities",
+				zap.String("snapshot_id", snapshot.ID.String()),
+				zap.String("crypto_type", string(root.CryptoType)),
+				zap.Float64("liabilities", root.TotalLiabilities),
+				zap.Float64("holdings", root.HouseHoldings),
+			)
+		}
+	}
+
+	logger.Info("Proof-of-reserves snapshot created",
+		zap.String("snapshot_id", snapshot.ID.String()),
+		zap.Int("leaves", len(leaves)),
+	)
+	return snapshot, nil
+}
+
+// GetLatestSnapshot retrieves the most recent published roots and totals
+func (s *ReservesService) GetLatestSnapshot() (*models.ReserveSnapshot, error) {
+	return s.reservesRepo.GetLatestSnapshot()
+}
+
+// GetSnapshot retrieves the published roots and totals of a snapshot
+func (s *ReservesService) GetSnapshot(id uuid.UUID) (*models.ReserveSnapshot, error) {
+	return s.reservesRepo.GetSnapshotByID(id)
+}
+
+// GetSnapshots retrieves recent snapshots
+func (s *ReservesService) GetSnapshots(limit uint64) ([]*models.ReserveSnapshot, error) {
+	return s.reservesRepo.GetSnapshots(limit)
+}
+
+// GetUserProofs builds the user's inclusion proofs from the latest snapshot
+func (s *ReservesService) GetUserProofs(userID uuid.UUID) ([]*models.ReservesProof, error) {
+	snapshot, err := s.reservesRepo.GetLatestSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	userLeaves, err := s.reservesRepo.GetUserLeaves(snapshot.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*models.ReservesProof, 0, len(userLeaves))
+	for _, userLeaf := range userLeaves {
+		leaves, err := s.reservesRepo.GetLeaves(snapshot.ID, userLeaf.CryptoType)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes := make([]merkle.Node, len(leaves))
+		for i, leaf := range leaves {
+			salt, err := hex.DecodeString(leaf.Salt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid salt in snapshot: %w", err)
+			}
+			nodes[i] = merkle.Leaf(leaf.UserID.String(), salt, leaf.Balance)
+		}
+
+		tree, err := merkle.Build(nodes)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := tree.Path(userLeaf.LeafIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		root := tree.Root()
+		proofs = append(proofs, &models.ReservesProof{
+			SnapshotID: snapshot.ID,
+			SnapshotAt: snapshot.CreatedAt,
+			CryptoType: userLeaf.CryptoType,
+			Decimals:   merkle.Decimals,
+			Proof: merkle.Proof{
+				UserID:   userLeaf.UserID.String(),
+				Salt:     userLeaf.Salt,
+				Balance:  userLeaf.Balance,
+				LeafHash: userLeaf.LeafHash,
+				Path:     path,
+				RootHash: hex.EncodeToString(root.Hash[:]),
+				RootSum:  root.Sum,
+			},
+		})
+	}
+
+	return proofs, nil
+}
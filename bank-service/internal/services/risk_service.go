@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/internal/risk"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RiskDeniedError is returned when fraud scoring declines an operation. The
+// customer only gets the reference of the logged decision, not the reasons.
+type RiskDeniedError struct {
+	DecisionID uuid.UUID
+}
+
+func (e *RiskDeniedError) Error() string {
+	return fmt.Sprintf("operation declined by risk checks (reference %s)", e.DecisionID)
+}
+
+// RiskCheck describes a money movement about to run. A beneficiary account or
+// address is set for payments to someone else.
+type RiskCheck struct {
+	UserID               uuid.UUID
+	Operation            models.RiskOperation
+	Amount               float64
+	Currency             string
+	Client               models.ClientInfo
+	SteppedUp            bool
+	BeneficiaryAccountID *uuid.UUID
+	BeneficiaryAddress   *string
+}
+
+// RiskService scores transfers, withdrawals and exchanges for fraud before
+// they run and logs every decision
+type RiskService struct {
+	riskRepo       *repositories.RiskRepository
+	userRepo       *repositories.UserRepository
+	credentialRepo *repositories.CredentialRepository
+	txRepo         *repositories.TransactionRepository
+	exchangeRepo   *repositories.ExchangeRepository
+	assets         *AssetService
+	scorer         risk.Scorer
+	timeout        time.Duration
+	failClosed     map[models.RiskOperation]bool
+}
+
+// NewRiskService creates the service. Operations in failClosed are denied when
+// the scorer fails or times out; all others are allowed.
+func NewRiskService(
+	riskRepo *repositories.RiskRepository,
+	userRepo *repositories.UserRepository,
+	credentialRepo *repositories.CredentialRepository,
+	txRepo *repositories.TransactionRepository,
+	exchangeRepo *repositories.ExchangeRepository,
+	assets *AssetService,
+	scorer risk.Scorer,
+	timeout time.Duration,
+	failClosed []models.RiskOperation,
+) *RiskService {
+	closed := make(map[models.RiskOperation]bool, len(failClosed))
+	for _, op := range failClosed {
+		closed[op] = true
+	}
+	return &RiskService{
+		riskRepo:       riskRepo,
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		txRepo:         txRepo,
+		exchangeRepo:   exchangeRepo,
+		assets:         assets,
+		scorer:         scorer,
+		timeout:        timeout,
+		failClosed:     closed,
+	}
+}
+
+// Assess scores an operation. It returns nil when the operation may run,
+// ErrStepUpRequired when it needs a second factor first and *RiskDeniedError
+// when it is declined. The device and IP address become known once an
+// operation from them is let through.
+func (s *RiskService) Assess(ctx context.Context, check *RiskCheck) error {
+	device := deviceHash(check.Client)
+	features, err := s.features(check, device)
+	if err != nil {
+		return err
+	}
+
+	amountUSD, valued := s.assets.USDValue(check.Currency, check.Amount)
+	req := &risk.Request{
+		Operation: check.Operation,
+		UserID:    check.UserID,
+		Amount:    check.Amount,
+		Currency:  check.Currency,
+		AmountUSD: amountUSD,
+		Features:  *features,
+	}
+
+	start := time.Now()
+	assessment, scoreErr := s.score(ctx, req)
+	latency := time.Since(start)
+	metrics.RiskScoringDuration.WithLabelValues(s.scorer.Name()).Observe(latency.Seconds())
+
+	evaluation := &models.RiskEvaluation{
+		UserID:    check.UserID,
+		Operation: check.Operation,
+		Amount:    check.Amount,
+		Currency:  check.Currency,
+		Features:  *features,
+		Scorer:    s.scorer.Name(),
+		SteppedUp: check.SteppedUp,
+		IP:        check.Client.IP,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if valued {
+		evaluation.AmountUSD = &amountUSD
+	}
+	if scoreErr != nil {
+		logger.Error("Risk scorer failed, applying fail mode",
+			zap.String("operation", string(check.Operation)),
+			zap.Bool("fail_closed", s.failClosed[check.Operation]),
+			zap.Error(scoreErr),
+		)
+		message := scoreErr.Error()
+		evaluation.ScorerError = &message
+	}
+	evaluation.Decision = assessment.Decision
+	evaluation.Score = assessment.Score
+	evaluation.Reasons = assessment.Reasons
+
+	// A failed decision log must not block payments; the log line below still has it
+	if err := s.riskRepo.CreateDecision(evaluation); err != nil {
+		logger.Error("Failed to log risk decision", zap.Error(err))
+	}
+
+	metrics.RiskDecisionsTotal.WithLabelValues(string(check.Operation), string(evaluation.Decision)).Inc()
+	logger.Info("Risk decision",
+		zap.String("decision_id", evaluation.ID.String()),
+		zap.String("user_id", check.UserID.String()),
+		zap.String("operation", string(check.Operation)),
+		zap.String("decision", string(evaluation.Decision)),
+		zap.Float64("score", evaluation.Score),
+		zap.Strings("reasons", evaluation.Reasons),
+		zap.Bool("stepped_up", check.SteppedUp),
+	)
+
+	switch evaluation.Decision {
+	case models.RiskDeny:
+		return &RiskDeniedError{DecisionID: evaluation.ID}
+	case models.RiskChallenge:
+		if !check.SteppedUp {
+			return ErrStepUpRequired
+		}
+	}
+
+	if check.Client.IP != "" {
+		if err := s.riskRepo.TrustClient(check.UserID, device, check.Client.IP); err != nil {
+			logger.Error("Failed to record known client", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// GetDecisions lists logged decisions, newest first
+func (s *RiskService) GetDecisions(filter *models.RiskEvaluationFilter) ([]*models.RiskEvaluation, error) {
+	return s.riskRepo.GetDecisions(filter)
+}
+
+// score asks the scorer within the scoring timeout. When the scorer fails it
+// returns the fail mode assessment of the operation along with the error.
+func (s *RiskService) score(ctx context.Context, req *risk.Request) (*models.RiskAssessment, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	assessment, err := s.scorer.Score(ctx, req)
+	if err != nil {
+		return s.fallback(req.Operation), err
+	}
+	return assessment, nil
+}
+
+func (s *RiskService) fallback(op models.RiskOperation) *models.RiskAssessment {
+	if s.failClosed[op] {
+		return &models.RiskAssessment{Decision: models.RiskDeny, Score: 100, Reasons: []string{"scorer_unavailable"}}
+	}
+	return &models.RiskAssessment{Decision: models.RiskAllow, Score: 0, Reasons: []string{"scorer_unavailable"}}
+}
+
+func (s *RiskService) features(check *RiskCheck, device string) (*models.RiskFeatures, error) {
+	user, err := s.userRepo.GetByID(check.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	features := &models.RiskFeatures{
+		AccountAgeHours: now.Sub(user.CreatedAt).Hours(),
+	}
+
+	if features.OperationsLastHour, _, err = s.activity(check.UserID, now.Add(-time.Hour)); err != nil {
+		return nil, err
+	}
+	if features.OperationsLastDay, features.VolumeLastDayUSD, err = s.activity(check.UserID, now.Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+
+	deviceKnown, ipKnown, devices, err := s.riskRepo.KnownClient(check.UserID, device, check.Client.IP)
+	if err != nil {
+		return nil, err
+	}
+	features.NewDevice = !deviceKnown
+	features.NewIP = !ipKnown
+	features.KnownDevices = devices
+
+	if check.BeneficiaryAccountID != nil || check.BeneficiaryAddress != nil {
+		paid, err := s.txRepo.HasPaidTo(check.UserID, check.BeneficiaryAccountID, check.BeneficiaryAddress)
+		if err != nil {
+			return nil, err
+		}
+		features.HasBeneficiary = true
+		features.NewBeneficiary = !paid
+	}
+
+	if totp, err := s.credentialRepo.GetTOTP(check.UserID); err == nil {
+		features.TwoFactorEnabled = totp.Enabled
+	}
+
+	return features, nil
+}
+
+// activity counts the outgoing transfers, withdrawals and exchanges of a user
+// since the given time and totals their value in USD. Amounts in assets without
+// a reference rate are counted but not valued.
+func (s *RiskService) activity(userID uuid.UUID, since time.Time) (int, float64, error) {
+	var count int
+	var volume float64
+	add := func(counts, totals map[string]float64) {
+		for _, n := range counts {
+			count += int(n)
+		}
+		for currency, total := range totals {
+			if value, ok := s.assets.USDValue(currency, total); ok {
+				volume += value
+			}
+		}
+	}
+
+	for _, txType := range []models.TransactionType{models.TransactionTypeTransfer, models.TransactionTypeWithdraw} {
+		counts, err := s.txRepo.CountByTypeSince(userID, txType, since)
+		if err != nil {
+			return 0, 0, err
+		}
+		totals, err := s.txRepo.SumByTypeSince(userID, txType, since)
+		if err != nil {
+			return 0, 0, err
+		}
+		add(counts, totals)
+	}
+
+	exchanges, err := s.exchangeRepo.CountSince(userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	totals, err := s.exchangeRepo.SumFromAmountsSince(userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	count += exchanges
+	add(nil, totals)
+
+	return count, volume, nil
+}
+
+// deviceHash identifies the device of a client without storing its raw ID. Apps
+// send X-Device-ID; other clients are told apart by their user agent only.
+func deviceHash(client models.ClientInfo) string {
+	key := "id:" + client.DeviceID
+	if client.DeviceID == "" {
+		key = "ua:" + client.UserAgent
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/risk"
+)
+
+type traceKey struct{}
+
+// stubScorer stands in for the external model. With block set it waits until
+// the call is cancelled, like a model that does not answer.
+type stubScorer struct {
+	assessment *models.RiskAssessment
+	err        error
+	block      bool
+	trace      interface{}
+}
+
+func (s *stubScorer) Name() string { return "stub" }
+
+func (s *stubScorer) Score(ctx context.Context, _ *risk.Request) (*models.RiskAssessment, error) {
+	s.trace = ctx.Value(traceKey{})
+	if s.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.assessment, s.err
+}
+
+func TestRiskServiceScore(t *testing.T) {
+	challenge := &models.RiskAssessment{Decision: models.RiskChallenge, Score: 55, Reasons: []string{"new_ip"}}
+	unavailable := []string{"scorer_unavailable"}
+
+	tests := []struct {
+		name       string
+		scorer     *stubScorer
+		operation  models.RiskOperation
+		failClosed []models.RiskOperation
+		want       *models.RiskAssessment
+		wantErr    bool
+	}{
+		{
+			name:      "model decision",
+			scorer:    &stubScorer{assessment: challenge},
+			operation: models.RiskOpTransfer,
+			want:      challenge,
+		},
+		{
+			name:      "model error fails open",
+			scorer:    &stubScorer{err: errors.New("connection refused")},
+			operation: models.RiskOpTransfer,
+			want:      &models.RiskAssessment{Decision: models.RiskAllow, Score: 0, Reasons: unavailable},
+			wantErr:   true,
+		},
+		{
+			name:       "model error fails closed",
+			scorer:     &stubScorer{err: errors.New("connection refused")},
+			operation:  models.RiskOpCryptoWithdraw,
+			failClosed: []models.RiskOperation{models.RiskOpCryptoWithdraw},
+			want:       &models.RiskAssessment{Decision: models.RiskDeny, Score: 100, Reasons: unavailable},
+			wantErr:    true,
+		},
+		{
+			name:       "fail mode of another operation",
+			scorer:     &stubScorer{err: errors.New("connection refused")},
+			operation:  models.RiskOpExchange,
+			failClosed: []models.RiskOperation{models.RiskOpCryptoWithdraw},
+			want:       &models.RiskAssessment{Decision: models.RiskAllow, Score: 0, Reasons: unavailable},
+			wantErr:    true,
+		},
+		{
+			name:       "model timeout fails closed",
+			scorer:     &stubScorer{block: true},
+			operation:  models.RiskOpWithdraw,
+			failClosed: []models.RiskOperation{models.RiskOpWithdraw},
+			want:       &models.RiskAssessment{Decision: models.RiskDeny, Score: 100, Reasons: unavailable},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewRiskService(nil, nil, nil, nil, nil, nil, tt.scorer, 20*time.Millisecond, tt.failClosed)
+			ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+
+			got, err := s.score(ctx, &risk.Request{Operation: tt.operation})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("score error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("score = %+v, want %+v", got, tt.want)
+			}
+			if tt.scorer.trace != "trace-1" {
+				t.Errorf("scorer context carried %v, want the caller's trace", tt.scorer.trace)
+			}
+		})
+	}
+}
+
+func TestRiskServiceScoreStopsWithCaller(t *testing.T) {
+	s := NewRiskService(nil, nil, nil, nil, nil, nil, &stubScorer{block: true}, time.Hour, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.score(ctx, &risk.Request{Operation: models.RiskOpTransfer}); !errors.Is(err, context.Canceled) {
+		t.Errorf("score error = %v, want the caller's cancellation", err)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/internal/screening"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ScreeningHeldError is returned when a watchlist hit put an operation on hold
+// for compliance review instead of executing it
+type ScreeningHeldError struct {
+	Hold *models.ScreeningHold
+}
+
+func (e *ScreeningHeldError) Error() string {
+	return fmt.Sprintf("operation held for compliance review (hold %s)", e.Hold.ID)
+}
+
+// ScreeningExecutor executes a held operation from its JSON payload once
+// compliance releases it and returns the ID of the resulting record
+type ScreeningExecutor func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error)
+
+// ScreeningService screens users and payment counterparties against sanctions
+// and watchlists and keeps the review queue of operations held after a hit
+type ScreeningService struct {
+	screeningRepo *repositories.ScreeningRepository
+	userRepo      *repositories.UserRepository
+	screener      *screening.Screener
+	audit         *AuditService
+	executors     map[models.ScreeningOperation]ScreeningExecutor
+}
+
+func NewScreeningService(
+	screeningRepo *repositories.ScreeningRepository,
+	userRepo *repositories.UserRepository,
+	screener *screening.Screener,
+	audit *AuditService,
+) *ScreeningService {
+	return &ScreeningService{
+		screeningRepo: screeningRepo,
+		userRepo:      userRepo,
+		screener:      screener,
+		audit:         audit,
+		executors:     make(map[models.ScreeningOperation]ScreeningExecutor),
+	}
+}
+
+// RegisterExecutor registers the function used to run an operation once released
+func (s *ScreeningService) RegisterExecutor(op models.ScreeningOperation, executor ScreeningExecutor) {
+	s.executors[op] = executor
+}
+
+// ScreenName screens the name of a user about to be created or renamed. On a
+// hit the operation is held and a *ScreeningHeldError is returned; userID is nil
+// for users that do not exist yet.
+func (s *ScreeningService) ScreenName(userID *uuid.UUID, op models.ScreeningOperation, name string, payload interface{}) error {
+	matches, err := s.screener.ScreenName(name)
+	if err != nil {
+		return fmt.Errorf("failed to screen name: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return s.hold(&models.ScreeningHold{
+		Operation: op,
+		UserID:    userID,
+		Subject:   name,
+		Matches:   matches,
+	}, payload)
+}
+
+// ScreenTransfer screens the owner of the account a transfer goes to
+func (s *ScreeningService) ScreenTransfer(userID, recipientID uuid.UUID, amount float64, currency string, payload interface{}) error {
+	if recipientID == userID {
+		return nil
+	}
+
+	recipient, err := s.userRepo.GetByID(recipientID)
+	if err != nil {
+		return fmt.Errorf("recipient not found: %w", err)
+	}
+
+	name := recipient.FirstName + " " + recipient.LastName
+	matches, err := s.screener.ScreenName(name)
+	if err != nil {
+		return fmt.Errorf("failed to screen recipient: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return s.hold(&models.ScreeningHold{
+		Operation: models.ScreeningOpTransfer,
+		UserID:    &userID,
+		Subject:   name,
+		Amount:    &amount,
+		Currency:  &currency,
+		Matches:   matches,
+	}, payload)
+}
+
+// ScreenAddress screens the external address of a crypto withdrawal
+func (s *ScreeningService) ScreenAddress(userID uuid.UUID, address string, amount float64, currency string, payload interface{}) error {
+	match, err := s.screener.ScreenAddress(address)
+	if err != nil {
+		return fmt.Errorf("failed to screen address: %w", err)
+	}
+	if match == nil {
+		return nil
+	}
+	return s.hold(&models.ScreeningHold{
+		Operation: models.ScreeningOpCryptoWithdraw,
+		UserID:    &userID,
+		Subject:   address,
+		Amount:    &amount,
+		Currency:  &currency,
+		Matches:   []models.ScreeningMatch{*match},
+	}, payload)
+}
+
+func (s *ScreeningService) hold(hold *models.ScreeningHold, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal screening payload: %w", err)
+	}
+
+	hold.Status = models.ScreeningHoldHeld
+	hold.Payload = body
+
+	if err := s.screeningRepo.Create(hold); err != nil {
+		logger.Error("Failed to hold screened operation", zap.Error(err))
+		return err
+	}
+
+	metrics.ScreeningHoldsTotal.WithLabelValues(string(hold.Operation), string(hold.Status)).Inc()
+	logger.Warn("Operation held by watchlist screening",
+		zap.String("hold_id", hold.ID.String()),
+		zap.String("operation", string(hold.Operation)),
+		zap.String("source", hold.Matches[0].Source),
+		zap.String("entry_id", hold.Matches[0].EntryID),
+		zap.Float64("score", hold.Matches[0].Score),
+		zap.Int("matches", len(hold.Matches)),
+	)
+	return &ScreeningHeldError{Hold: hold}
+}
+
+// GetHolds lists held operations in a status, oldest first
+func (s *ScreeningService) GetHolds(status models.ScreeningHoldStatus) ([]*models.ScreeningHold, error) {
+	return s.screeningRepo.GetByStatus(status)
+}
+
+// GetHold retrieves a held operation with its matches
+func (s *ScreeningService) GetHold(id uuid.UUID) (*models.ScreeningHold, error) {
+	return s.screeningRepo.GetByID(id)
+}
+
+// Release clears a hit as a false positive and executes the held operation
+func (s *ScreeningService) Release(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.ScreeningHold, error) {
+	hold, err := s.review(reviewerID, id, models.ScreeningHoldReleased, note)
+	if err != nil {
+		return nil, err
+	}
+
+	s.execute(ctx, hold)
+	return s.reviewed(ctx, models.AuditScreeningReleased, hold)
+}
+
+// Reject confirms a hit; the held operation is never executed
+func (s *ScreeningService) Reject(ctx context.Context, reviewerID, id uuid.UUID, note string) (*models.ScreeningHold, error) {
+	hold, err := s.review(reviewerID, id, models.ScreeningHoldRejected, note)
+	if err != nil {
+		return nil, err
+	}
+	return s.reviewed(ctx, models.AuditScreeningRejected, hold)
+}
+
+func (s *ScreeningService) review(reviewerID, id uuid.UUID, status models.ScreeningHoldStatus, note string) (*models.ScreeningHold, error) {
+	hold, err := s.screeningRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.UserID != nil && *hold.UserID == reviewerID {
+		return nil, fmt.Errorf("reviewers cannot decide on their own operations")
+	}
+
+	// Only the reviewer that moves the hold out of held gets to execute it
+	if err := s.screeningRepo.Review(id, status, reviewerID, note); err != nil {
+		logger.Error("Failed to review screening hold", zap.Error(err))
+		return nil, err
+	}
+
+	metrics.ScreeningHoldsTotal.WithLabelValues(string(hold.Operation), string(status)).Inc()
+	logger.Info("Screening hold reviewed",
+		zap.String("hold_id", id.String()),
+		zap.String("reviewer_id", reviewerID.String()),
+		zap.String("status", string(status)),
+	)
+	return hold, nil
+}
+
+// reviewed records the decision on a hold in the audit trail and returns the
+// hold as it is now
+func (s *ScreeningService) reviewed(ctx context.Context, action models.AuditAction, before *models.ScreeningHold) (*models.ScreeningHold, error) {
+	hold, err := s.screeningRepo.GetByID(before.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.RecordContext(ctx, action, models.AuditEntityScreeningHold, hold.ID.String(), before, hold)
+	return hold, nil
+}
+
+func (s *ScreeningService) execute(ctx context.Context, hold *models.ScreeningHold) {
+	executor, ok := s.executors[hold.Operation]
+	if !ok {
+		err := fmt.Errorf("no executor registered for %s", hold.Operation)
+		logger.Error("Failed to execute released operation", zap.Error(err))
+		s.screeningRepo.MarkExecuted(hold.ID, nil, err)
+		return
+	}
+
+	resultID, err := executor(ctx, hold.Payload)
+
+	// A released withdrawal can still need the approval of co-signers; the
+	// approval request is then the result of the release
+	var approvalErr *ApprovalRequiredError
+	if errors.As(err, &approvalErr) {
+		resultID, err = approvalErr.Request.ID, nil
+	}
+
+	if err != nil {
+		logger.Error("Released operation failed",
+			zap.String("hold_id", hold.ID.String()),
+			zap.Error(err),
+		)
+		metrics.ScreeningHoldsTotal.WithLabelValues(string(hold.Operation), string(models.ScreeningHoldFailed)).Inc()
+		s.screeningRepo.MarkExecuted(hold.ID, nil, err)
+		return
+	}
+
+	s.screeningRepo.MarkExecuted(hold.ID, &resultID, nil)
+	logger.Info("Released operation executed",
+		zap.String("hold_id", hold.ID.String()),
+		zap.String("result_id", resultID.String()),
+	)
+}
+
+// ReloadWatchlists reads the watchlists again
+func (s *ScreeningService) ReloadWatchlists() (*models.WatchlistStatus, error) {
+	if err := s.screener.Reload(); err != nil {
+		return nil, err
+	}
+
+	status := s.screener.Status()
+	for _, source := range status.Sources {
+		logger.Info("Watchlist loaded",
+			zap.String("source", source.Name),
+			zap.Int("entries", source.Entries),
+			zap.Int("addresses", source.Addresses),
+		)
+	}
+	return status, nil
+}
+
+// GetWatchlistStatus describes the lists screening currently runs against
+func (s *ScreeningService) GetWatchlistStatus() *models.WatchlistStatus {
+	return s.screener.Status()
+}
@@ -1,14 +1,16 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/repositories"
 	"github.com/crypto-bank/bank-service/pkg/logger"
 	"github.com/crypto-bank/bank-service/pkg/metrics"
-	"github.com/crypto-bank/bank-service/pkg/rabbitmq"
+	"github.com/crypto-bank/shared/events"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -17,44 +19,87 @@ type TransactionService struct {
 	txRepo      *repositories.TransactionRepository
 	accountRepo *repositories.AccountRepository
 	db          *sql.DB
-	rabbitMQ    *rabbitmq.Client
+	outboxRepo  *repositories.OutboxRepository
+	approvals   *ApprovalService
+	assets      *AssetService
+	limits      *LimitService
+	screening   *ScreeningService
+	risk        *RiskService
+	// stepUpThresholdUSD is the transfer value from which a second factor is required
+	stepUpThresholdUSD float64
 }
 
 func NewTransactionService(
 	txRepo *repositories.TransactionRepository,
 	accountRepo *repositories.AccountRepository,
 	db *sql.DB,
-	rabbitMQ *rabbitmq.Client,
+	outboxRepo *repositories.OutboxRepository,
+	approvals *ApprovalService,
+	assets *AssetService,
+	limits *LimitService,
+	screening *ScreeningService,
+	risk *RiskService,
+	stepUpThresholdUSD float64,
 ) *TransactionService {
-	return &TransactionService{
-		txRepo:      txRepo,
-		accountRepo: accountRepo,
-		db:          db,
-		rabbitMQ:    rabbitMQ,
+	s := &TransactionService{
+		txRepo:             txRepo,
+		accountRepo:        accountRepo,
+		db:                 db,
+		outboxRepo:         outboxRepo,
+		approvals:          approvals,
+		assets:             assets,
+		limits:             limits,
+		screening:          screening,
+		risk:               risk,
+		stepUpThresholdUSD: stepUpThresholdUSD,
 	}
+
+	approvals.RegisterExecutor(models.ApprovalOpFiatWithdraw, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.WithdrawRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid withdrawal payload: %w", err)
+		}
+		transaction, err := s.executeWithdraw(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	screening.RegisterExecutor(models.ScreeningOpTransfer, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var req models.CreateTransactionRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid transfer payload: %w", err)
+		}
+		transaction, err := s.executeTransfer(ctx, &req)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	return s
 }
 
-// CreateTransfer creates a transfer transaction between accounts
-func (s *TransactionService) CreateTransfer(req *models.CreateTransactionRequest) (*models.Transaction, error) {
+// CreateTransfer creates a transfer transaction between accounts. Transfers to
+// a recipient matching a watchlist are held and return *ScreeningHeldError.
+func (s *TransactionService) CreateTransfer(ctx context.Context, req *models.CreateTransactionRequest) (*models.Transaction, error) {
 	logger.Info("Creating transfer",
 		zap.String("from_account", req.FromAccountID.String()),
 		zap.String("to_account", req.ToAccountID.String()),
 		zap.Float64("amount", req.Amount),
 	)
 
-	// Start database transaction
-	dbTx, err := s.db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer dbTx.Rollback()
-
 	// Get accounts
 	fromAccount, err := s.accountRepo.GetByID(req.FromAccountID)
 	if err != nil {
 		return nil, fmt.Errorf("from account not found: %w", err)
 	}
 
+	if fromAccount.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
 	toAccount, err := s.accountRepo.GetByID(req.ToAccountID)
 	if err != nil {
 		return nil, fmt.Errorf("to account not found: %w", err)
@@ -65,12 +110,90 @@ func (s *TransactionService) CreateTransfer(req *models.CreateTransactionRequest
 		return nil, fmt.Errorf("currency mismatch: from %s to %s", fromAccount.Currency, toAccount.Currency)
 	}
 
+	if err := s.validateAmount(fromAccount.Currency, req.Amount); err != nil {
+		return nil, err
+	}
+
+	// Large transfers need a recent second factor check
+	if !req.SteppedUp && s.requiresStepUp(fromAccount.Currency, req.Amount) {
+		return nil, ErrStepUpRequired
+	}
+
+	if err := s.limits.Check(req.UserID, models.LimitOpTransfer, string(fromAccount.Currency), req.Amount); err != nil {
+		return nil, err
+	}
+
 	// Check balance
 	if fromAccount.Balance < req.Amount {
 		return nil, fmt.Errorf("insufficient balance: have %f, need %f", fromAccount.Balance, req.Amount)
 	}
 
-	// Create transaction record
+	check := &RiskCheck{
+		UserID:    req.UserID,
+		Operation: models.RiskOpTransfer,
+		Amount:    req.Amount,
+		Currency:  string(fromAccount.Currency),
+		Client:    req.Client,
+		SteppedUp: req.SteppedUp,
+	}
+	// Moving money between own accounts has no beneficiary to judge
+	if toAccount.UserID != req.UserID {
+		check.BeneficiaryAccountID = &toAccount.ID
+	}
+	if err := s.risk.Assess(ctx, check); err != nil {
+		return nil, err
+	}
+
+	if err := s.screening.ScreenTransfer(req.UserID, toAccount.UserID, req.Amount, string(fromAccount.Currency), req); err != nil {
+		return nil, err
+	}
+
+	return s.executeTransfer(ctx, req)
+}
+
+// executeTransfer moves the funds of a transfer that passed all checks. A
+// transfer that fails on the way is recorded as failed.
+func (s *TransactionService) executeTransfer(ctx context.Context, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	transaction, err := s.transfer(ctx, req)
+	if err != nil {
+		recordFailedTransaction(ctx, s.db, s.txRepo, s.outboxRepo, transaction, err)
+		return nil, err
+	}
+
+	// Update metrics
+	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
+	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+
+	logger.Info("Transfer completed", zap.String("transaction_id", transaction.ID.String()))
+	return transaction, nil
+}
+
+// transfer runs a transfer in one database transaction. It reloads the
+// accounts since held transfers run long after the request. On failure it
+// returns the transaction as far as it got, or nil if it failed before there
+// was one.
+func (s *TransactionService) transfer(ctx context.Context, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	// Start database transaction
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	txRepo := s.txRepo.WithTx(dbTx)
+	accountRepo := s.accountRepo.WithTx(dbTx)
+	outboxRepo := s.outboxRepo.WithTx(dbTx)
+
+	fromAccount, err := accountRepo.GetByID(req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("from account not found: %w", err)
+	}
+
+	toAccount, err := accountRepo.GetByID(req.ToAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("to account not found: %w", err)
+	}
+
 	transaction := &models.Transaction{
 		UserID:        fromAccount.UserID,
 		Type:          models.TransactionTypeTransfer,
@@ -82,52 +205,53 @@ func (s *TransactionService) CreateTransfer(req *models.CreateTransactionRequest
 		Description:   req.Description,
 	}
 
-	if err := s.txRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if fromAccount.Balance < req.Amount {
+		return transaction, fmt.Errorf("insufficient balance: have %f, need %f", fromAccount.Balance, req.Amount)
 	}
 
-	// Update balances
-	if err := s.accountRepo.UpdateBalance(req.FromAccountID, -req.Amount); err != nil {
-		return nil, fmt.Errorf("failed to update from account balance: %w", err)
+	if err := s.limits.CheckTx(dbTx, fromAccount.UserID, models.LimitOpTransfer, string(fromAccount.Currency), req.Amount); err != nil {
+		return transaction, err
 	}
 
-	if err := s.accountRepo.UpdateBalance(req.ToAccountID, req.Amount); err != nil {
-		return nil, fmt.Errorf("failed to update to account balance: %w", err)
+	// Create transaction record
+	if err := txRepo.Create(transaction); err != nil {
+		return transaction, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
-	// Update transaction status
-	if err := s.txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
-		return nil, fmt.Errorf("failed to update transaction status: %w", err)
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+		return transaction, err
 	}
 
-	// Commit database transaction
-	if err := dbTx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	// Update balances
+	if err := changeAccountBalance(ctx, accountRepo, outboxRepo, fromAccount, -req.Amount, transaction.ID); err != nil {
+		return transaction, err
+	}
+
+	if err := changeAccountBalance(ctx, accountRepo, outboxRepo, toAccount, req.Amount, transaction.ID); err != nil {
+		return transaction, err
+	}
+
+	// Update transaction status
+	if err := txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
+		return transaction, fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
 	transaction.Status = models.TransactionStatusCompleted
 
-	// Update metrics
-	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
-	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCompleted, transaction, ""); err != nil {
+		return transaction, err
+	}
 
-	// Publish events
-	event := rabbitmq.TransactionEvent{
-		TransactionID: transaction.ID.String(),
-		UserID:        transaction.UserID.String(),
-		Type:          string(transaction.Type),
-		Amount:        transaction.Amount,
-		Currency:      transaction.Currency,
-		Status:        string(transaction.Status),
+	// Commit database transaction
+	if err := dbTx.Commit(); err != nil {
+		return transaction, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	s.rabbitMQ.PublishEvent(rabbitmq.ExchangeEvents, rabbitmq.EventTransactionCompleted, event)
 
-	logger.Info("Transfer completed", zap.String("transaction_id", transaction.ID.String()))
 	return transaction, nil
 }
 
 // Deposit deposits money to an account
-func (s *TransactionService) Deposit(req *models.DepositRequest) (*models.Transaction, error) {
+func (s *TransactionService) Deposit(ctx context.Context, req *models.DepositRequest) (*models.Transaction, error) {
 	logger.Info("Creating deposit",
 		zap.String("account", req.AccountID.String()),
 		zap.Float64("amount", req.Amount),
@@ -138,6 +262,35 @@ func (s *TransactionService) Deposit(req *models.DepositRequest) (*models.Transa
 		return nil, fmt.Errorf("account not found: %w", err)
 	}
 
+	if account.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	if err := s.validateAmount(account.Currency, req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.limits.Check(req.UserID, models.LimitOpDeposit, string(account.Currency), req.Amount); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.deposit(ctx, account, req)
+	if err != nil {
+		recordFailedTransaction(ctx, s.db, s.txRepo, s.outboxRepo, transaction, err)
+		return nil, err
+	}
+
+	// Update metrics
+	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
+	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+
+	logger.Info("Deposit completed", zap.String("transaction_id", transaction.ID.String()))
+	return transaction, nil
+}
+
+// deposit credits an account in one database transaction. On failure it
+// returns the transaction as far as it got.
+func (s *TransactionService) deposit(ctx context.Context, account *models.Account, req *models.DepositRequest) (*models.Transaction, error) {
 	transaction := &models.Transaction{
 		UserID:      account.UserID,
 		Type:        models.TransactionTypeDeposit,
@@ -148,30 +301,50 @@ func (s *TransactionService) Deposit(req *models.DepositRequest) (*models.Transa
 		Description: "Deposit",
 	}
 
-	if err := s.txRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return transaction, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer dbTx.Rollback()
 
-	if err := s.accountRepo.UpdateBalance(req.AccountID, req.Amount); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	if err := s.limits.CheckTx(dbTx, account.UserID, models.LimitOpDeposit, string(account.Currency), req.Amount); err != nil {
+		return transaction, err
 	}
 
-	if err := s.txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
-		return nil, fmt.Errorf("failed to update transaction status: %w", err)
+	txRepo := s.txRepo.WithTx(dbTx)
+	outboxRepo := s.outboxRepo.WithTx(dbTx)
+
+	if err := txRepo.Create(transaction); err != nil {
+		return transaction, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+		return transaction, err
+	}
+
+	if err := changeAccountBalance(ctx, s.accountRepo.WithTx(dbTx), outboxRepo, account, req.Amount, transaction.ID); err != nil {
+		return transaction, err
+	}
+
+	if err := txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
+		return transaction, fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
 	transaction.Status = models.TransactionStatusCompleted
 
-	// Update metrics
-	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
-	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCompleted, transaction, ""); err != nil {
+		return transaction, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return transaction, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	logger.Info("Deposit completed", zap.String("transaction_id", transaction.ID.String()))
 	return transaction, nil
 }
 
 // Withdraw withdraws money from an account
-func (s *TransactionService) Withdraw(req *models.WithdrawRequest) (*models.Transaction, error) {
+func (s *TransactionService) Withdraw(ctx context.Context, req *models.WithdrawRequest) (*models.Transaction, error) {
 	logger.Info("Creating withdrawal",
 		zap.String("account", req.AccountID.String()),
 		zap.Float64("amount", req.Amount),
@@ -182,10 +355,77 @@ func (s *TransactionService) Withdraw(req *models.WithdrawRequest) (*models.Tran
 		return nil, fmt.Errorf("account not found: %w", err)
 	}
 
+	if account.UserID != req.UserID {
+		return nil, ErrOwnershipMismatch
+	}
+
+	if err := s.validateAmount(account.Currency, req.Amount); err != nil {
+		return nil, err
+	}
+
 	if account.Balance < req.Amount {
 		return nil, fmt.Errorf("insufficient balance: have %f, need %f", account.Balance, req.Amount)
 	}
 
+	if err := s.limits.Check(req.UserID, models.LimitOpWithdrawal, string(account.Currency), req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.risk.Assess(ctx, &RiskCheck{
+		UserID:    req.UserID,
+		Operation: models.RiskOpWithdraw,
+		Amount:    req.Amount,
+		Currency:  string(account.Currency),
+		Client:    req.Client,
+		SteppedUp: req.SteppedUp,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.approvals.Guard(account.UserID, ApprovalTarget{AccountID: &account.ID},
+		models.ApprovalOpFiatWithdraw, req.Amount, string(account.Currency), req); err != nil {
+		return nil, err
+	}
+
+	return s.executeWithdraw(ctx, req)
+}
+
+// executeWithdraw pays out a withdrawal that passed all checks. A withdrawal
+// that fails on the way is recorded as failed.
+func (s *TransactionService) executeWithdraw(ctx context.Context, req *models.WithdrawRequest) (*models.Transaction, error) {
+	transaction, err := s.withdraw(ctx, req)
+	if err != nil {
+		recordFailedTransaction(ctx, s.db, s.txRepo, s.outboxRepo, transaction, err)
+		return nil, err
+	}
+
+	// Update metrics
+	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
+	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+
+	logger.Info("Withdrawal completed", zap.String("transaction_id", transaction.ID.String()))
+	return transaction, nil
+}
+
+// withdraw debits an account in one database transaction. On failure it
+// returns the transaction as far as it got, or nil if it failed before there
+// was one.
+func (s *TransactionService) withdraw(ctx context.Context, req *models.WithdrawRequest) (*models.Transaction, error) {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	txRepo := s.txRepo.WithTx(dbTx)
+	accountRepo := s.accountRepo.WithTx(dbTx)
+	outboxRepo := s.outboxRepo.WithTx(dbTx)
+
+	account, err := accountRepo.GetByID(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
 	transaction := &models.Transaction{
 		UserID:        account.UserID,
 		Type:          models.TransactionTypeWithdraw,
@@ -196,25 +436,40 @@ func (s *TransactionService) Withdraw(req *models.WithdrawRequest) (*models.Tran
 		Description:   "Withdrawal",
 	}
 
-	if err := s.txRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if account.Balance < req.Amount {
+		return transaction, fmt.Errorf("insufficient balance: have %f, need %f", account.Balance, req.Amount)
+	}
+
+	if err := s.limits.CheckTx(dbTx, account.UserID, models.LimitOpWithdrawal, string(account.Currency), req.Amount); err != nil {
+		return transaction, err
+	}
+
+	if err := txRepo.Create(transaction); err != nil {
+		return transaction, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCreated, transaction, ""); err != nil {
+		return transaction, err
 	}
 
-	if err := s.accountRepo.UpdateBalance(req.AccountID, -req.Amount); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	if err := changeAccountBalance(ctx, accountRepo, outboxRepo, account, -req.Amount, transaction.ID); err != nil {
+		return transaction, err
 	}
 
-	if err := s.txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
-		return nil, fmt.Errorf("failed to update transaction status: %w", err)
+	if err := txRepo.UpdateStatus(transaction.ID, models.TransactionStatusCompleted); err != nil {
+		return transaction, fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
 	transaction.Status = models.TransactionStatusCompleted
 
-	// Update metrics
-	metrics.TransactionsTotal.WithLabelValues(string(transaction.Type), string(transaction.Status)).Inc()
-	metrics.TransactionAmount.WithLabelValues(transaction.Currency).Observe(transaction.Amount)
+	if err := addTransactionEvent(ctx, outboxRepo, events.TransactionCompleted, transaction, ""); err != nil {
+		return transaction, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return transaction, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	logger.Info("Withdrawal completed", zap.String("transaction_id", transaction.ID.String()))
 	return transaction, nil
 }
 
@@ -228,3 +483,22 @@ func (s *TransactionService) GetUserTransactions(userID uuid.UUID) ([]*models.Tr
 	return s.txRepo.GetByUserID(userID)
 }
 
+// validateAmount checks the amount against the registry rules of the account currency
+func (s *TransactionService) validateAmount(currency models.CurrencyType, amount float64) error {
+	asset, err := s.assets.Require(string(currency), models.AssetKindFiat)
+	if err != nil {
+		return err
+	}
+	return asset.ValidateAmount(amount)
+}
+
+// requiresStepUp reports whether a transfer reaches the step-up threshold. Amounts
+// in assets without a reference rate cannot be valued and always need it.
+func (s *TransactionService) requiresStepUp(currency models.CurrencyType, amount float64) bool {
+	asset, err := s.assets.Require(string(currency), models.AssetKindFiat)
+	if err != nil {
+		return true
+	}
+	value, ok := asset.USDValue(amount)
+	return !ok || value >= s.stepUpThresholdUSD
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/bank-service/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TreasuryService struct {
+	treasuryRepo *repositories.TreasuryRepository
+	audit        *AuditService
+}
+
+func NewTreasuryService(treasuryRepo *repositories.TreasuryRepository, audit *AuditService) *TreasuryService {
+	return &TreasuryService{
+		treasuryRepo: treasuryRepo,
+		audit:        audit,
+	}
+}
+
+// GetWallets retrieves all house wallets
+func (s *TreasuryService) GetWallets() ([]*models.TreasuryWallet, error) {
+	return s.treasuryRepo.GetWallets()
+}
+
+// GetPolicies retrieves hot wallet policies
+func (s *TreasuryService) GetPolicies() ([]*models.TreasuryPolicy, error) {
+	return s.treasuryRepo.GetPolicies()
+}
+
+// UpdatePolicy updates the hot wallet targets of a crypto type
+func (s *TreasuryService) UpdatePolicy(cryptoType models.CryptoType, req *models.UpdateTreasuryPolicyRequest) (*models.TreasuryPolicy, error) {
+	policy := &models.TreasuryPolicy{
+		CryptoType:     cryptoType,
+		TargetHotRatio: req.TargetHotRatio,
+		MaxHotRatio:    req.MaxHotRatio,
+		MinHotBalance:  req.MinHotBalance,
+	}
+
+	if err := s.treasuryRepo.UpdatePolicy(policy); err != nil {
+		logger.Error("Failed to update treasury policy", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Treasury policy updated",
+		zap.String("crypto_type", string(cryptoType)),
+		zap.Float64("target_hot_ratio", policy.TargetHotRatio),
+		zap.Float64("max_hot_ratio", policy.MaxHotRatio),
+	)
+	return policy, nil
+}
+
+// Deposit records funds arriving in a house wallet (e.g. an on-chain top-up)
+func (s *TreasuryService) Deposit(cryptoType models.CryptoType, req *models.TreasuryDepositRequest) (*models.TreasuryWallet, error) {
+	if err := s.treasuryRepo.UpdateWalletBalance(cryptoType, req.Kind, req.Amount); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Treasury deposit recorded",
+		zap.String("crypto_type", string(cryptoType)),
+		zap.String("kind", string(req.Kind)),
+		zap.Float64("amount", req.Amount),
+	)
+	return s.treasuryRepo.GetWallet(cryptoType, req.Kind)
+}
+
+// ProvisionAsset creates house hot and cold wallets with a conservative default
+// policy for a newly registered crypto asset
+func (s *TreasuryService) ProvisionAsset(asset *models.Asset) error {
+	policy := &models.TreasuryPolicy{
+		CryptoType:     models.CryptoType(asset.Code),
+		TargetHotRatio: 0.10,
+		MaxHotRatio:    0.20,
+		MinHotBalance:  0,
+	}
+
+	hot := generateAddress(asset.AddressPrefix, "house-hot-"+asset.Code)
+	cold := generateAddress(asset.AddressPrefix, "house-cold-"+asset.Code)
+	return s.treasuryRepo.ProvisionAsset(policy, hot, cold)
+}
+
+// DebitHotWallet reserves liquidity for a customer withdrawal inside the
+// withdrawal transaction, so the debit commits or rolls back with it. The hot
+// wallet stays locked until then, so concurrent withdrawals cannot both spend
+// the same balance. It fails and requests a replenishment when the hot wallet
+// cannot cover the amount, and requests one proactively when the withdrawal
+// leaves the hot wallet under its minimum balance.
+func (s *TreasuryService) DebitHotWallet(dbTx *sql.Tx, cryptoType models.CryptoType, amount float64) error {
+	treasuryRepo := s.treasuryRepo.WithTx(dbTx)
+
+	hot, err := treasuryRepo.GetWalletForUpdate(cryptoType, models.TreasuryWalletHot)
+	if err != nil {
+		return err
+	}
+
+	policy, err := treasuryRepo.GetPolicy(cryptoType)
+	if err != nil {
+		return err
+	}
+
+	if hot.Balance < amount {
+		s.requestReplenishment(policy, hot.Balance, amount,
+			fmt.Sprintf("withdrawal of %f %s exceeds hot wallet balance %f", amount, cryptoType, hot.Balance))
+		return fmt.Errorf("insufficient hot wallet liquidity for %s, replenishment requested", cryptoType)
+	}
+
+	if err := treasuryRepo.UpdateWalletBalance(cryptoType, models.TreasuryWalletHot, -amount); err != nil {
+		return err
+	}
+
+	if remaining := hot.Balance - amount; remaining < policy.MinHotBalance {
+		s.requestReplenishment(policy, remaining, 0,
+			fmt.Sprintf("hot wallet balance %f below minimum %f", remaining, policy.MinHotBalance))
+	}
+
+	return nil
+}
+
+// requestReplenishment proposes moving enough from cold storage to bring the hot wallet
+// back to its target ratio after covering pending demand
+func (s *TreasuryService) requestReplenishment(policy *models.TreasuryPolicy, hotBalance, demand float64, reason string) {
+	cold, err := s.treasuryRepo.GetWallet(policy.CryptoType, models.TreasuryWalletCold)
+	if err != nil {
+		logger.Error("Failed to load cold wallet", zap.Error(err))
+		return
+	}
+
+	total := hotBalance + cold.Balance - demand
+	target := policy.TargetHotRatio * total
+	if target < policy.MinHotBalance {
+		target = policy.MinHotBalance
+	}
+
+	amount := target + demand - hotBalance
+	if amount > cold.Balance {
+		amount = cold.Balance
+	}
+	if amount <= 0 {
+		logger.Warn("Cold wallet cannot replenish hot wallet",
+			zap.String("crypto_type", string(policy.CryptoType)),
+			zap.String("reason", reason),
+		)
+		return
+	}
+
+	movement := &models.TreasuryMovement{
+		CryptoType: policy.CryptoType,
+		Type:       models.TreasuryMovementReplenish,
+		Amount:     amount,
+		Reason:     reason,
+	}
+	if err := s.treasuryRepo.UpsertOpenMovement(movement); err != nil {
+		logger.Error("Failed to request replenishment", zap.Error(err))
+		return
+	}
+
+	logger.Warn("Hot wallet replenishment requested",
+		zap.String("movement_id", movement.ID.String()),
+		zap.String("crypto_type", string(policy.CryptoType)),
+		zap.Float64("amount", amount),
+		zap.String("reason", reason),
+	)
+}
+
+// ProposeSweeps checks every crypto type and proposes a sweep to cold storage when the
+// hot wallet holds more than its maximum share of house holdings
+func (s *TreasuryService) ProposeSweeps() ([]*models.TreasuryMovement, error) {
+	policies, err := s.treasuryRepo.GetPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	var proposals []*models.TreasuryMovement
+	for _, policy := range policies {
+		hot, err := s.treasuryRepo.GetWallet(policy.CryptoType, models.TreasuryWalletHot)
+		if err != nil {
+			return nil, err
+		}
+		cold, err := s.treasuryRepo.GetWallet(policy.CryptoType, models.TreasuryWalletCold)
+		if err != nil {
+			return nil, err
+		}
+
+		total := hot.Balance + cold.Balance
+		if total == 0 || hot.Balance/total <= policy.MaxHotRatio {
+			continue
+		}
+
+		movement := &models.TreasuryMovement{
+			CryptoType: policy.CryptoType,
+			Type:       models.TreasuryMovementSweep,
+			Amount:     hot.Balance - policy.TargetHotRatio*total,
+			Reason: fmt.Sprintf("hot ratio %.4f exceeds maximum %.4f",
+				hot.Balance/total, policy.MaxHotRatio),
+		}
+		if err := s.treasuryRepo.UpsertOpenMovement(movement); err != nil {
+			return nil, err
+		}
+
+		logger.Info("Treasury sweep proposed",
+			zap.String("movement_id", movement.ID.String()),
+			zap.String("crypto_type", string(policy.CryptoType)),
+			zap.Float64("amount", movement.Amount),
+		)
+		proposals = append(proposals, movement)
+	}
+
+	return proposals, nil
+}
+
+// GetMovements retrieves treasury movements, optionally filtered by status
+func (s *TreasuryService) GetMovements(status models.TreasuryMovementStatus) ([]*models.TreasuryMovement, error) {
+	return s.treasuryRepo.GetMovements(status)
+}
+
+// ExecuteMovement confirms that an operator carried out a sweep or replenishment
+func (s *TreasuryService) ExecuteMovement(ctx context.Context, id uuid.UUID) (*models.TreasuryMovement, error) {
+	movement, err := s.treasuryRepo.GetMovementByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.treasuryRepo.ExecuteMovement(movement); err != nil {
+		logger.Error("Failed to execute treasury movement", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Treasury movement executed",
+		zap.String("movement_id", movement.ID.String()),
+		zap.String("type", string(movement.Type)),
+		zap.Float64("amount", movement.Amount),
+	)
+
+	executed, err := s.treasuryRepo.GetMovementByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.RecordContext(ctx, models.AuditTreasuryExecuted, models.AuditEntityTreasuryMovement, id.String(), movement, executed)
+	return executed, nil
+}
+
+// CancelMovement cancels an open proposal
+func (s *TreasuryService) CancelMovement(id uuid.UUID) (*models.TreasuryMovement, error) {
+	if err := s.treasuryRepo.CancelMovement(id); err != nil {
+		return nil, err
+	}
+	return s.treasuryRepo.GetMovementByID(id)
+}
+
+// Report compares total customer crypto balances with house holdings per crypto type
+func (s *TreasuryService) Report() (*models.TreasuryReport, error) {
+	policies, err := s.treasuryRepo.GetPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.treasuryRepo.GetWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	liabilities, err := s.treasuryRepo.GetCustomerLiabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.treasuryRepo.GetMovements(models.TreasuryMovementProposed)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TreasuryReport{GeneratedAt: time.Now()}
+	for _, policy := range policies {
+		line := models.TreasuryReportLine{
+			CryptoType:          policy.CryptoType,
+			CustomerLiabilities: liabilities[policy.CryptoType],
+			TargetHotRatio:      policy.TargetHotRatio,
+			MaxHotRatio:         policy.MaxHotRatio,
+		}
+
+		for _, wallet := range wallets {
+			if wallet.CryptoType != policy.CryptoType {
+				continue
+			}
+			switch wallet.Kind {
+			case models.TreasuryWalletHot:
+				line.HotBalance = wallet.Balance
+			case models.TreasuryWalletCold:
+				line.ColdBalance = wallet.Balance
+			}
+		}
+
+		for _, movement := range open {
+			if movement.CryptoType != policy.CryptoType {
+				continue
+			}
+			switch movement.Type {
+			case models.TreasuryMovementSweep:
+				line.OpenSweepAmount = movement.Amount
+			case models.TreasuryMovementReplenish:
+				line.OpenReplenishAmount = movement.Amount
+			}
+		}
+
+		line.TotalHoldings = line.HotBalance + line.ColdBalance
+		line.Surplus = line.TotalHoldings - line.CustomerLiabilities
+		line.FullyCovered = line.Surplus >= 0
+		if line.CustomerLiabilities > 0 {
+			line.CoverageRatio = line.TotalHoldings / line.CustomerLiabilities
+		}
+		if line.TotalHoldings > 0 {
+			line.HotRatio = line.HotBalance / line.TotalHoldings
+		}
+
+		metrics.TreasuryBalance.WithLabelValues(string(line.CryptoType), "hot").Set(line.HotBalance)
+		metrics.TreasuryBalance.WithLabelValues(string(line.CryptoType), "cold").Set(line.ColdBalance)
+		metrics.TreasuryBalance.WithLabelValues(string(line.CryptoType), "liabilities").Set(line.CustomerLiabilities)
+
+		if !line.FullyCovered {
+			logger.Warn("Customer liabilities exceed house holdings",
+				zap.String("crypto_type", string(line.CryptoType)),
+				zap.Float64("deficit", -line.Surplus),
+			)
+		}
+
+		report.Lines = append(report.Lines, line)
+	}
+
+	return report, nil
+}
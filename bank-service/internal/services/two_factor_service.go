@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crypto-bank/bank-service/internal/auth"
+	"github.com/crypto-bank/bank-service/internal/models"
+	"github.com/crypto-bank/bank-service/internal/repositories"
+	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorRequired    = errors.New("two-factor code required")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+)
+
+// TwoFactorService manages TOTP enrollment and recovery codes. Wrong passwords
+// and codes count towards the same lockout as failed logins.
+type TwoFactorService struct {
+	credentialRepo *repositories.CredentialRepository
+	userRepo       *repositories.UserRepository
+	lockout        *Lockout
+	issuer         string
+}
+
+func NewTwoFactorService(
+	credentialRepo *repositories.CredentialRepository,
+	userRepo *repositories.UserRepository,
+	lockout *Lockout,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		credentialRepo: credentialRepo,
+		userRepo:       userRepo,
+		lockout:        lockout,
+		issuer:         issuer,
+	}
+}
+
+// Enabled reports whether the user has a confirmed TOTP enrollment
+func (s *TwoFactorService) Enabled(userID uuid.UUID) bool {
+	totp, err := s.credentialRepo.GetTOTP(userID)
+	return err == nil && totp.Enabled
+}
+
+// Status describes the second factor of a user
+func (s *TwoFactorService) Status(userID uuid.UUID) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{Enabled: s.Enabled(userID)}
+	if !status.Enabled {
+		return status, nil
+	}
+
+	remaining, err := s.credentialRepo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	status.RemainingRecoveryCodes = remaining
+
+	return status, nil
+}
+
+// Enroll creates a TOTP secret for the user. It takes effect once confirmed
+// with a code from the authenticator app.
+func (s *TwoFactorService) Enroll(userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	if s.Enabled(userID) {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.UpsertTOTP(userID, secret); err != nil {
+		return nil, err
+	}
+
+	logger.Info("TOTP enrollment started", zap.String("user_id", userID.String()))
+	return &models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables a pending TOTP enrollment and returns the initial recovery codes
+func (s *TwoFactorService) Confirm(userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	totp, err := s.credentialRepo.GetTOTP(userID)
+	if err != nil {
+		return nil, fmt.Errorf("no pending TOTP enrollment")
+	}
+	if totp.Enabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	if err := s.lockout.Check(userID); err != nil {
+		return nil, err
+	}
+
+	step, ok := auth.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, s.lockout.Failed(userID, ErrInvalidTwoFactorCode)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.EnableTOTP(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Two-factor authentication enabled", zap.String("user_id", userID.String()))
+	return codes, nil
+}
+
+// Verify checks a TOTP or recovery code. Both are single use.
+func (s *TwoFactorService) Verify(userID uuid.UUID, code string) error {
+	totp, err := s.credentialRepo.GetTOTP(userID)
+	if err != nil || !totp.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if step, ok := auth.ValidateTOTP(totp.Secret, code, time.Now()); ok {
+		used, err := s.credentialRepo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.credentialRepo.UseRecoveryCode(userID, auth.HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	logger.Info("Recovery code used", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// CheckCode verifies a code of a user who is not locked out and records a
+// wrong code as a failed attempt
+func (s *TwoFactorService) CheckCode(userID uuid.UUID, code string) error {
+	if err := s.lockout.Check(userID); err != nil {
+		return err
+	}
+
+	err := s.Verify(userID, code)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		return s.lockout.Failed(userID, err)
+	}
+	return err
+}
+
+// Disable removes the second factor after checking the password and a code
+func (s *TwoFactorService) Disable(userID uuid.UUID, password, code string) error {
+	credential, err := s.credentialRepo.GetCredential(userID)
+	if err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := lockedOut(credential); err != nil {
+		return err
+	}
+
+	ok, err := auth.VerifyPassword(password, credential.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.lockout.Failed(userID, ErrInvalidCredentials)
+	}
+
+	if err := s.CheckCode(userID, code); err != nil {
+		return err
+	}
+
+	if err := s.credentialRepo.DeleteTOTP(userID); err != nil {
+		return err
+	}
+
+	logger.Info("Two-factor authentication disabled", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	if err := s.CheckCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Recovery codes regenerated", zap.String("user_id", userID.String()))
+	return codes, nil
+}
+
+func newRecoveryCodes() (*models.RecoveryCodes, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, hashes, nil
+}
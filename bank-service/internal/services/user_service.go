@@ -1,27 +1,86 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/crypto-bank/bank-service/internal/auth"
 	"github.com/crypto-bank/bank-service/internal/models"
 	"github.com/crypto-bank/bank-service/internal/repositories"
 	"github.com/crypto-bank/bank-service/pkg/logger"
+	"github.com/crypto-bank/shared/events"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type UserService struct {
-	userRepo *repositories.UserRepository
+	userRepo       *repositories.UserRepository
+	credentialRepo *repositories.CredentialRepository
+	screening      *ScreeningService
 }
 
-func NewUserService(userRepo *repositories.UserRepository) *UserService {
-	return &UserService{
-		userRepo: userRepo,
+// pendingUser is a user creation held by screening. It keeps the password hash
+// so the password itself is never stored.
+type pendingUser struct {
+	Email        string `json:"email"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Phone        string `json:"phone"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// pendingUserUpdate is a profile update held by screening
+type pendingUserUpdate struct {
+	UserID  uuid.UUID                `json:"user_id"`
+	Request models.UpdateUserRequest `json:"request"`
+}
+
+func NewUserService(
+	userRepo *repositories.UserRepository,
+	credentialRepo *repositories.CredentialRepository,
+	screening *ScreeningService,
+) *UserService {
+	s := &UserService{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		screening:      screening,
 	}
+
+	screening.RegisterExecutor(models.ScreeningOpCreateUser, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var pending pendingUser
+		if err := json.Unmarshal(payload, &pending); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid user payload: %w", err)
+		}
+		user, err := s.createUser(ctx, &models.User{
+			Email:     pending.Email,
+			FirstName: pending.FirstName,
+			LastName:  pending.LastName,
+			Phone:     pending.Phone,
+		}, pending.PasswordHash)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return user.ID, nil
+	})
+
+	screening.RegisterExecutor(models.ScreeningOpUpdateUser, func(ctx context.Context, payload json.RawMessage) (uuid.UUID, error) {
+		var pending pendingUserUpdate
+		if err := json.Unmarshal(payload, &pending); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid user update payload: %w", err)
+		}
+		if err := s.updateUser(ctx, pending.UserID, &pending.Request); err != nil {
+			return uuid.Nil, err
+		}
+		return pending.UserID, nil
+	})
+
+	return s
 }
 
-// CreateUser creates a new user
-func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+// CreateUser creates a new user. Applicants matching a watchlist are held for
+// compliance review and a *ScreeningHeldError is returned.
+func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
 	logger.Info("Creating user", zap.String("email", req.Email))
 
 	// Check if user with email already exists
@@ -30,14 +89,47 @@ func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, e
 		return nil, fmt.Errorf("user with email %s already exists", req.Email)
 	}
 
-	user := &models.User{
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		logger.Error("Failed to hash password", zap.Error(err))
+		return nil, err
+	}
+
+	pending := &pendingUser{
+		Email:        req.Email,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Phone:        req.Phone,
+		PasswordHash: passwordHash,
+	}
+	if err := s.screening.ScreenName(nil, models.ScreeningOpCreateUser, req.FirstName+" "+req.LastName, pending); err != nil {
+		return nil, err
+	}
+
+	return s.createUser(ctx, &models.User{
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Phone:     req.Phone,
+	}, passwordHash)
+}
+
+func (s *UserService) createUser(ctx context.Context, user *models.User, passwordHash string) (*models.User, error) {
+	// The email may have been taken while the creation was held
+	if existingUser, _ := s.userRepo.GetByEmail(user.Email); existingUser != nil {
+		return nil, fmt.Errorf("user with email %s already exists", user.Email)
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	user.ID = uuid.New()
+	user.Role = models.RoleCustomer
+	user.KYCTier = models.KYCTierUnverified
+
+	event, err := userEvent(ctx, events.UserCreated, user, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.CreateUser(user, passwordHash, event); err != nil {
 		logger.Error("Failed to create user", zap.Error(err))
 		return nil, err
 	}
@@ -61,11 +153,60 @@ func (s *UserService) GetAllUsers() ([]*models.User, error) {
 	return s.userRepo.GetAll()
 }
 
-// UpdateUser updates a user
-func (s *UserService) UpdateUser(id uuid.UUID, req *models.UpdateUserRequest) error {
+// UpdateUser updates a user. A new name matching a watchlist holds the update
+// for compliance review and returns a *ScreeningHeldError.
+func (s *UserService) UpdateUser(ctx context.Context, id uuid.UUID, req *models.UpdateUserRequest) error {
 	logger.Info("Updating user", zap.String("user_id", id.String()))
 
-	if err := s.userRepo.Update(id, req); err != nil {
+	if req.FirstName != "" || req.LastName != "" {
+		user, err := s.userRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		firstName, lastName := user.FirstName, user.LastName
+		if req.FirstName != "" {
+			firstName = req.FirstName
+		}
+		if req.LastName != "" {
+			lastName = req.LastName
+		}
+
+		pending := &pendingUserUpdate{UserID: id, Request: *req}
+		if err := s.screening.ScreenName(&id, models.ScreeningOpUpdateUser, firstName+" "+lastName, pending); err != nil {
+			return err
+		}
+	}
+
+	return s.updateUser(ctx, id, req)
+}
+
+func (s *UserService) updateUser(ctx context.Context, id uuid.UUID, req *models.UpdateUserRequest) error {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+	if req.FirstName != "" {
+		user.FirstName = req.FirstName
+		changed = append(changed, "first_name")
+	}
+	if req.LastName != "" {
+		user.LastName = req.LastName
+		changed = append(changed, "last_name")
+	}
+	if req.Phone != "" {
+		user.Phone = req.Phone
+		changed = append(changed, "phone")
+	}
+
+	event, err := userEvent(ctx, events.UserUpdated, user, changed)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(id, req, event); err != nil {
 		logger.Error("Failed to update user", zap.Error(err))
 		return err
 	}
@@ -74,11 +215,45 @@ func (s *UserService) UpdateUser(id uuid.UUID, req *models.UpdateUserRequest) er
 	return nil
 }
 
+// UpdateRole changes the role of a user
+func (s *UserService) UpdateRole(ctx context.Context, id uuid.UUID, role models.Role) error {
+	logger.Info("Updating user role", zap.String("user_id", id.String()), zap.String("role", string(role)))
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+
+	event, err := userEvent(ctx, events.UserUpdated, user, []string{"role"})
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateRole(id, role, event); err != nil {
+		logger.Error("Failed to update user role", zap.Error(err))
+		return err
+	}
+
+	logger.Info("User role updated", zap.String("user_id", id.String()), zap.String("role", string(role)))
+	return nil
+}
+
 // DeleteUser deletes a user
-func (s *UserService) DeleteUser(id uuid.UUID) error {
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	logger.Info("Deleting user", zap.String("user_id", id.String()))
 
-	if err := s.userRepo.Delete(id); err != nil {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	event, err := userEvent(ctx, events.UserDeleted, user, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(id, event); err != nil {
 		logger.Error("Failed to delete user", zap.Error(err))
 		return err
 	}
@@ -87,3 +262,11 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 	return nil
 }
 
+// userEvent announces a change of the user lifecycle. It names the fields that
+// changed but keeps the profile off the bus.
+func userEvent(ctx context.Context, eventType string, user *models.User, changed []string) (*models.OutboxMessage, error) {
+	return newOutboxMessage(ctx, eventType, user.ID.String(), events.UserEvent{
+		UserID:  user.ID.String(),
+		Changed: changed,
+	})
+}
@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Crypto withdrawals move funds out of a wallet to an external address
+ALTER TABLE transactions ADD COLUMN from_wallet_id UUID REFERENCES crypto_wallets(id);
+ALTER TABLE transactions ADD COLUMN to_address VARCHAR(255);
+
+-- Approval policies (M-of-N) attached to a crypto wallet or a fiat account
+CREATE TABLE IF NOT EXISTS approval_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID REFERENCES crypto_wallets(id) ON DELETE CASCADE,
+    account_id UUID REFERENCES accounts(id) ON DELETE CASCADE,
+    threshold DECIMAL(20, 8) NOT NULL CHECK (threshold > 0),
+    required_approvals INTEGER NOT NULL CHECK (required_approvals > 0),
+    ttl_seconds INTEGER NOT NULL DEFAULT 86400 CHECK (ttl_seconds > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((wallet_id IS NULL) <> (account_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_approval_policies_wallet_id ON approval_policies(wallet_id) WHERE wallet_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_approval_policies_account_id ON approval_policies(account_id) WHERE account_id IS NOT NULL;
+
+-- Configured approver set for a policy
+CREATE TABLE IF NOT EXISTS approval_policy_approvers (
+    policy_id UUID NOT NULL REFERENCES approval_policies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (policy_id, user_id)
+);
+
+-- Operations parked until enough approvers sign off
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id UUID NOT NULL REFERENCES approval_policies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    operation VARCHAR(30) NOT NULL CHECK (operation IN ('CRYPTO_WITHDRAW', 'FIAT_WITHDRAW', 'CRYPTO_TO_FIAT', 'FIAT_TO_CRYPTO')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'EXPIRED', 'EXECUTED', 'FAILED')),
+    amount DECIMAL(20, 8) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    payload JSONB NOT NULL,
+    required_approvals INTEGER NOT NULL,
+    result_id UUID,
+    failure_reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Approval trail: one decision per approver per request
+CREATE TABLE IF NOT EXISTS approval_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+    approver_id UUID NOT NULL REFERENCES users(id),
+    decision VARCHAR(10) NOT NULL CHECK (decision IN ('APPROVE', 'REJECT')),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(request_id, approver_id)
+);
+
+CREATE INDEX idx_approval_requests_status ON approval_requests(status);
+CREATE INDEX idx_approval_requests_expires_at ON approval_requests(expires_at);
+CREATE INDEX idx_approval_decisions_request_id ON approval_decisions(request_id);
+
+CREATE TRIGGER update_approval_policies_updated_at BEFORE UPDATE ON approval_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_approval_requests_updated_at BEFORE UPDATE ON approval_requests
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_approval_requests_updated_at ON approval_requests;
+DROP TRIGGER IF EXISTS update_approval_policies_updated_at ON approval_policies;
+
+DROP TABLE IF EXISTS approval_decisions;
+DROP TABLE IF EXISTS approval_requests;
+DROP TABLE IF EXISTS approval_policy_approvers;
+DROP TABLE IF EXISTS approval_policies;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS to_address;
+ALTER TABLE transactions DROP COLUMN IF EXISTS from_wallet_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- House-owned hot and cold wallets per crypto type
+CREATE TABLE IF NOT EXISTS treasury_wallets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    crypto_type VARCHAR(10) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('HOT', 'COLD')),
+    address VARCHAR(255) UNIQUE NOT NULL,
+    balance DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(crypto_type, kind)
+);
+
+-- Hot wallet targets per crypto type, expressed as share of total house holdings
+CREATE TABLE IF NOT EXISTS treasury_policies (
+    crypto_type VARCHAR(10) PRIMARY KEY,
+    target_hot_ratio DECIMAL(5, 4) NOT NULL CHECK (target_hot_ratio > 0 AND target_hot_ratio < 1),
+    max_hot_ratio DECIMAL(5, 4) NOT NULL CHECK (max_hot_ratio > 0 AND max_hot_ratio <= 1),
+    min_hot_balance DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (min_hot_balance >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (target_hot_ratio <= max_hot_ratio)
+);
+
+-- Sweeps (hot -> cold) and replenishments (cold -> hot) awaiting operator execution
+CREATE TABLE IF NOT EXISTS treasury_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    crypto_type VARCHAR(10) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('SWEEP', 'REPLENISH')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PROPOSED', 'EXECUTED', 'CANCELLED')),
+    amount DECIMAL(20, 8) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    executed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one open proposal of each type per crypto type
+CREATE UNIQUE INDEX idx_treasury_movements_open ON treasury_movements(crypto_type, type)
+    WHERE status = 'PROPOSED';
+CREATE INDEX idx_treasury_movements_status ON treasury_movements(status);
+
+CREATE TRIGGER update_treasury_wallets_updated_at BEFORE UPDATE ON treasury_wallets
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_treasury_policies_updated_at BEFORE UPDATE ON treasury_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_treasury_movements_updated_at BEFORE UPDATE ON treasury_movements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO treasury_policies (crypto_type, target_hot_ratio, max_hot_ratio, min_hot_balance) VALUES
+    ('BTC', 0.05, 0.10, 0.5),
+    ('ETH', 0.10, 0.20, 5.0),
+    ('USDT', 0.20, 0.30, 10000.0),
+    ('BNB', 0.10, 0.20, 10.0),
+    ('SOL', 0.10, 0.20, 100.0);
+
+INSERT INTO treasury_wallets (crypto_type, kind, address, balance) VALUES
+    ('BTC', 'HOT', 'bc1qhousehotbtc0000000000000000000000000', 2.0),
+    ('BTC', 'COLD', 'bc1qhousecoldbtc000000000000000000000000', 20.0),
+    ('ETH', 'HOT', '0xhousehoteth00000000000000000000000000000', 20.0),
+    ('ETH', 'COLD', '0xhousecoldeth0000000000000000000000000000', 200.0),
+    ('USDT', 'HOT', '0xhousehotusdt0000000000000000000000000000', 20000.0),
+    ('USDT', 'COLD', '0xhousecoldusdt000000000000000000000000000', 80000.0),
+    ('BNB', 'HOT', '0xhousehotbnb00000000000000000000000000000', 50.0),
+    ('BNB', 'COLD', '0xhousecoldbnb0000000000000000000000000000', 450.0),
+    ('SOL', 'HOT', 'HouseHotSoL1111111111111111111111111111111', 500.0),
+    ('SOL', 'COLD', 'HouseColdSoL111111111111111111111111111111', 4500.0);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS update_treasury_movements_updated_at ON treasury_movements;
+DROP TRIGGER IF EXISTS update_treasury_policies_updated_at ON treasury_policies;
+DROP TRIGGER IF EXISTS update_treasury_wallets_updated_at ON treasury_wallets;
+
+DROP TABLE IF EXISTS treasury_movements;
+DROP TABLE IF EXISTS treasury_policies;
+DROP TABLE IF EXISTS treasury_wallets;
+
+-- +goose StatementEnd
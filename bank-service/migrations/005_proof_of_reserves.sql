@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Point-in-time snapshots of customer crypto liabilities
+CREATE TABLE IF NOT EXISTS reserve_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Merkle sum tree root and totals per crypto type
+CREATE TABLE IF NOT EXISTS reserve_roots (
+    snapshot_id UUID NOT NULL REFERENCES reserve_snapshots(id) ON DELETE CASCADE,
+    crypto_type VARCHAR(10) NOT NULL,
+    root_hash VARCHAR(64) NOT NULL,
+    root_sum NUMERIC(20, 0) NOT NULL,
+    total_liabilities DECIMAL(20, 8) NOT NULL,
+    house_holdings DECIMAL(20, 8) NOT NULL,
+    leaf_count INTEGER NOT NULL,
+    PRIMARY KEY (snapshot_id, crypto_type)
+);
+
+-- Salted per-user leaves, kept so inclusion proofs can be rebuilt on request
+CREATE TABLE IF NOT EXISTS reserve_leaves (
+    snapshot_id UUID NOT NULL REFERENCES reserve_snapshots(id) ON DELETE CASCADE,
+    crypto_type VARCHAR(10) NOT NULL,
+    leaf_index INTEGER NOT NULL,
+    user_id UUID NOT NULL,
+    salt VARCHAR(64) NOT NULL,
+    balance NUMERIC(20, 0) NOT NULL,
+    leaf_hash VARCHAR(64) NOT NULL,
+    PRIMARY KEY (snapshot_id, crypto_type, leaf_index)
+);
+
+CREATE INDEX idx_reserve_snapshots_created_at ON reserve_snapshots(created_at);
+CREATE INDEX idx_reserve_leaves_user ON reserve_leaves(snapshot_id, user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS reserve_leaves;
+DROP TABLE IF EXISTS reserve_roots;
+DROP TABLE IF EXISTS reserve_snapshots;
+
+-- +goose StatementEnd
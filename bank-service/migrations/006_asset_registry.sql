@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Registry of supported fiat currencies and crypto assets
+CREATE TABLE IF NOT EXISTS assets (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('FIAT', 'CRYPTO')),
+    decimals INTEGER NOT NULL CHECK (decimals BETWEEN 0 AND 8),
+    chain VARCHAR(50) NOT NULL DEFAULT '',
+    address_prefix VARCHAR(20) NOT NULL DEFAULT '',
+    address_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    min_amount DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    min_withdrawal DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (min_withdrawal >= 0),
+    reference_usd_rate DECIMAL(20, 8) CHECK (reference_usd_rate > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_assets_kind ON assets(kind);
+
+CREATE TRIGGER update_assets_updated_at BEFORE UPDATE ON assets
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO assets (code, name, kind, decimals, chain, address_prefix, address_pattern, min_amount, min_withdrawal, reference_usd_rate) VALUES
+    ('USD', 'US Dollar', 'FIAT', 2, '', '', '', 0.01, 1, 1.00),
+    ('EUR', 'Euro', 'FIAT', 2, '', '', '', 0.01, 1, 1.09),
+    ('RUB', 'Russian Ruble', 'FIAT', 2, '', '', '', 1, 100, 0.0108),
+    ('GBP', 'Pound Sterling', 'FIAT', 2, '', '', '', 0.01, 1, 1.27),
+    ('BTC', 'Bitcoin', 'CRYPTO', 8, 'bitcoin', '1', '^(1|3|bc1)[a-zA-HJ-NP-Z0-9]{25,62}$', 0.00001, 0.0005, 43500.00),
+    ('ETH', 'Ethereum', 'CRYPTO', 8, 'ethereum', '0x', '^0x[a-fA-F0-9]{40}$', 0.0001, 0.005, 2280.50),
+    ('USDT', 'Tether USD', 'CRYPTO', 6, 'ethereum', '0x', '^0x[a-fA-F0-9]{40}$', 0.01, 10, 1.00),
+    ('BNB', 'BNB', 'CRYPTO', 8, 'bsc', '0x', '^0x[a-fA-F0-9]{40}$', 0.001, 0.01, 315.75),
+    ('SOL', 'Solana', 'CRYPTO', 8, 'solana', '', '^[1-9A-HJ-NP-Za-km-z]{32,44}$', 0.001, 0.05, 98.30);
+
+-- Supported currencies now come from the registry instead of CHECK constraints
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_currency_check;
+ALTER TABLE accounts ADD CONSTRAINT fk_accounts_asset
+    FOREIGN KEY (currency) REFERENCES assets(code);
+
+ALTER TABLE crypto_wallets DROP CONSTRAINT IF EXISTS crypto_wallets_crypto_type_check;
+ALTER TABLE crypto_wallets ADD CONSTRAINT fk_crypto_wallets_asset
+    FOREIGN KEY (crypto_type) REFERENCES assets(code);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE crypto_wallets DROP CONSTRAINT IF EXISTS fk_crypto_wallets_asset;
+ALTER TABLE crypto_wallets ADD CONSTRAINT crypto_wallets_crypto_type_check
+    CHECK (crypto_type IN ('BTC', 'ETH', 'USDT', 'BNB', 'SOL'));
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS fk_accounts_asset;
+ALTER TABLE accounts ADD CONSTRAINT accounts_currency_check
+    CHECK (currency IN ('USD', 'EUR', 'RUB', 'GBP'));
+
+DROP TRIGGER IF EXISTS update_assets_updated_at ON assets;
+DROP TABLE IF EXISTS assets;
+
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Identity verification level of a user. New users start unverified.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_tier VARCHAR(20) NOT NULL DEFAULT 'unverified'
+    CHECK (kyc_tier IN ('unverified', 'basic', 'full'));
+
+-- Requests to move up a tier, decided by the verification provider or by
+-- compliance staff when the provider asks for a manual review
+CREATE TABLE IF NOT EXISTS kyc_applications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_tier VARCHAR(20) NOT NULL CHECK (requested_tier IN ('basic', 'full')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'in_review', 'approved', 'rejected')),
+    date_of_birth DATE NOT NULL,
+    country CHAR(2) NOT NULL,
+    address_line VARCHAR(255) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(20) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_reference VARCHAR(100),
+    provider_reason TEXT,
+    reviewer_id UUID REFERENCES users(id),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_kyc_applications_user_id ON kyc_applications(user_id);
+CREATE INDEX idx_kyc_applications_status ON kyc_applications(status);
+
+-- A user has at most one application waiting for a decision
+CREATE UNIQUE INDEX idx_kyc_applications_open ON kyc_applications(user_id)
+    WHERE status IN ('pending', 'in_review');
+
+CREATE TRIGGER update_kyc_applications_updated_at BEFORE UPDATE ON kyc_applications
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id UUID PRIMARY KEY,
+    application_id UUID NOT NULL REFERENCES kyc_applications(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL
+        CHECK (type IN ('passport', 'national_id', 'drivers_license', 'proof_of_address', 'selfie')),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_kyc_documents_application_id ON kyc_documents(application_id);
+
+-- Rolling 24 hour limits per tier, in US dollars
+CREATE TABLE IF NOT EXISTS kyc_tier_limits (
+    tier VARCHAR(20) PRIMARY KEY CHECK (tier IN ('unverified', 'basic', 'full')),
+    deposit_daily_usd DECIMAL(20, 2) NOT NULL CHECK (deposit_daily_usd >= 0),
+    withdrawal_daily_usd DECIMAL(20, 2) NOT NULL CHECK (withdrawal_daily_usd >= 0),
+    transfer_daily_usd DECIMAL(20, 2) NOT NULL CHECK (transfer_daily_usd >= 0),
+    exchange_daily_usd DECIMAL(20, 2) NOT NULL CHECK (exchange_daily_usd >= 0)
+);
+
+INSERT INTO kyc_tier_limits (tier, deposit_daily_usd, withdrawal_daily_usd, transfer_daily_usd, exchange_daily_usd) VALUES
+    ('unverified', 1000, 0, 0, 0),
+    ('basic', 10000, 5000, 5000, 10000),
+    ('full', 1000000, 250000, 250000, 1000000);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS kyc_tier_limits;
+DROP TABLE IF EXISTS kyc_documents;
+DROP TABLE IF EXISTS kyc_applications;
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_tier;
+
+-- +goose StatementEnd
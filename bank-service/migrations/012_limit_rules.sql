@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Velocity limits defined globally, per KYC tier or per user. For each kind and
+-- currency the most specific rule applies. Rules without a currency cap the US
+-- dollar value across currencies, or for count rules the number of operations.
+CREATE TABLE IF NOT EXISTS limit_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('global', 'tier', 'user')),
+    kyc_tier VARCHAR(20) CHECK (kyc_tier IN ('unverified', 'basic', 'full')),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN (
+        'max_single_transfer', 'daily_outflow', 'monthly_outflow',
+        'daily_withdrawal_count', 'daily_exchange_volume', 'monthly_exchange_volume'
+    )),
+    currency VARCHAR(10),
+    value DECIMAL(20, 8) NOT NULL CHECK (value >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (scope = 'global' AND kyc_tier IS NULL AND user_id IS NULL) OR
+        (scope = 'tier' AND kyc_tier IS NOT NULL AND user_id IS NULL) OR
+        (scope = 'user' AND user_id IS NOT NULL AND kyc_tier IS NULL)
+    )
+);
+
+CREATE UNIQUE INDEX idx_limit_rules_unique ON limit_rules (
+    scope, COALESCE(kyc_tier, ''), COALESCE(user_id, '00000000-0000-0000-0000-000000000000'), kind, COALESCE(currency, '')
+);
+CREATE INDEX idx_limit_rules_user_id ON limit_rules(user_id) WHERE user_id IS NOT NULL;
+
+CREATE TRIGGER update_limit_rules_updated_at BEFORE UPDATE ON limit_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO limit_rules (scope, kyc_tier, kind, currency, value) VALUES
+    ('global', NULL, 'max_single_transfer', NULL, 100000),
+    ('global', NULL, 'daily_withdrawal_count', NULL, 10),
+    ('global', NULL, 'monthly_outflow', NULL, 1000000),
+    ('global', NULL, 'monthly_exchange_volume', NULL, 2000000),
+    ('tier', 'basic', 'monthly_outflow', NULL, 50000),
+    ('tier', 'basic', 'monthly_exchange_volume', NULL, 100000);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS limit_rules;
+
+-- +goose StatementEnd
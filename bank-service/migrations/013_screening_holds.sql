@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Operations held after a sanctions or watchlist hit. Compliance staff release
+-- them, which executes the stored payload, or reject them. User creations have
+-- no user yet, so user_id is only set for operations of existing users.
+CREATE TABLE IF NOT EXISTS screening_holds (
+    id UUID PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL
+        CHECK (operation IN ('create_user', 'update_user', 'transfer', 'crypto_withdraw')),
+    status VARCHAR(20) NOT NULL DEFAULT 'held'
+        CHECK (status IN ('held', 'released', 'rejected', 'failed')),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    amount DECIMAL(20, 8),
+    currency VARCHAR(10),
+    matches JSONB NOT NULL,
+    payload JSONB NOT NULL,
+    reviewer_id UUID REFERENCES users(id),
+    review_note TEXT,
+    result_id UUID,
+    failure_reason TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_screening_holds_status ON screening_holds(status, created_at);
+CREATE INDEX idx_screening_holds_user_id ON screening_holds(user_id) WHERE user_id IS NOT NULL;
+
+CREATE TRIGGER update_screening_holds_updated_at BEFORE UPDATE ON screening_holds
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS screening_holds;
+
+-- +goose StatementEnd
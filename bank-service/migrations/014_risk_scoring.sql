@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Devices and IP addresses a user moved money from after passing fraud scoring.
+-- Devices are stored as a hash of the device ID or user agent.
+CREATE TABLE IF NOT EXISTS risk_known_clients (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('device', 'ip')),
+    value VARCHAR(64) NOT NULL,
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, kind, value)
+);
+
+-- Decision log of the fraud scorer, one row per scored money movement
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    operation VARCHAR(20) NOT NULL
+        CHECK (operation IN ('transfer', 'withdraw', 'crypto_withdraw', 'exchange')),
+    amount DECIMAL(20, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    amount_usd DECIMAL(20, 2),
+    decision VARCHAR(10) NOT NULL CHECK (decision IN ('allow', 'challenge', 'deny')),
+    score DECIMAL(5, 2) NOT NULL,
+    reasons JSONB NOT NULL,
+    features JSONB NOT NULL,
+    scorer VARCHAR(20) NOT NULL,
+    scorer_error TEXT,
+    stepped_up BOOLEAN NOT NULL DEFAULT FALSE,
+    ip VARCHAR(45) NOT NULL,
+    latency_ms BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_risk_decisions_user_id ON risk_decisions(user_id, created_at);
+CREATE INDEX idx_risk_decisions_created_at ON risk_decisions(created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS risk_decisions;
+DROP TABLE IF EXISTS risk_known_clients;
+
+-- +goose StatementEnd
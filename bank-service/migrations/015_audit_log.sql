@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Append-only, hash-chained audit trail of state changes. Every row commits to
+-- the hash of the row before it, see pkg/auditchain. Actors and entities are
+-- plain IDs without foreign keys: the trail must outlive what it describes.
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    actor_type VARCHAR(10) NOT NULL CHECK (actor_type IN ('user', 'api_key', 'admin', 'system')),
+    actor_id UUID,
+    api_key_id UUID,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    trace_id VARCHAR(32) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id) WHERE actor_id IS NOT NULL;
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at);
+
+-- The application only ever inserts. Refusing edits here keeps honest mistakes
+-- out; the hash chain catches anyone able to drop the trigger.
+CREATE OR REPLACE FUNCTION reject_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS reject_audit_log_change();
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- W3C trace context of the request that caused the event, so the relay can
+-- continue the trace when it publishes the message
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS trace_context JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS trace_context;
+
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Every event the service published, kept for good unlike the outbox rows the
+-- relay purges. Events are appended in the transaction that adds them to the
+-- outbox and hold the whole envelope; position orders them as they were added.
+-- Replays re-publish them to bank.events.replay.
+CREATE TABLE IF NOT EXISTS event_store (
+    position BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(100) NOT NULL,
+    schema_version INTEGER NOT NULL,
+    subject VARCHAR(100) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(100) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    payload JSONB NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_event_store_occurred_at ON event_store(occurred_at);
+CREATE INDEX idx_event_store_subject ON event_store(subject) WHERE subject <> '';
+CREATE INDEX idx_event_store_user_id ON event_store((payload->'data'->>'user_id'));
+
+CREATE OR REPLACE FUNCTION reject_event_store_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'event_store is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER event_store_append_only BEFORE UPDATE OR DELETE ON event_store
+    FOR EACH ROW EXECUTE FUNCTION reject_event_store_change();
+
+CREATE TRIGGER event_store_no_truncate BEFORE TRUNCATE ON event_store
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_event_store_change();
+
+-- Start from what the outbox still holds. Messages stored before the envelope
+-- carry bare data and are wrapped the way the relay publishes them.
+INSERT INTO event_store (event_id, event_type, schema_version, subject, correlation_id, occurred_at, payload)
+SELECT
+    message_id,
+    routing_key,
+    COALESCE((payload->>'version')::INTEGER, 1),
+    COALESCE(payload->>'subject', ''),
+    COALESCE(payload->>'correlation_id', message_id::TEXT),
+    COALESCE((payload->>'occurred_at')::TIMESTAMP WITH TIME ZONE, created_at),
+    CASE WHEN payload ? 'version' AND payload ? 'data' THEN payload
+        ELSE jsonb_build_object(
+            'id', message_id,
+            'type', routing_key,
+            'version', 1,
+            'occurred_at', created_at,
+            'producer', 'bank-service',
+            'correlation_id', message_id,
+            'data', payload
+        )
+    END
+FROM outbox
+ORDER BY id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS event_store_no_truncate ON event_store;
+DROP TRIGGER IF EXISTS event_store_append_only ON event_store;
+DROP TABLE IF EXISTS event_store;
+DROP FUNCTION IF EXISTS reject_event_store_change();
+
+-- +goose StatementEnd
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Exchanges run as sagas: lock a quote, reserve the source funds, settle into
+-- the destination and release the quote. The quote lives outside the bank
+-- database, so the steps cannot share one transaction; the saga row records
+-- how far an exchange got. step is the next step to run, or while compensating
+-- the next step to undo. next_attempt_at doubles as the claim of the instance
+-- running the saga: the recovery worker resumes sagas whose claim lapsed.
+CREATE TABLE IF NOT EXISTS exchange_sagas (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    exchange_type VARCHAR(30) NOT NULL CHECK (exchange_type IN ('CRYPTO_TO_FIAT', 'FIAT_TO_CRYPTO')),
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('RUNNING', 'COMPENSATING', 'COMPLETED', 'COMPENSATED')),
+    step VARCHAR(20) NOT NULL
+        CHECK (step IN ('LOCK_QUOTE', 'RESERVE_FUNDS', 'SETTLE', 'RELEASE', 'DONE')),
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    from_amount DECIMAL(20, 8) NOT NULL CHECK (from_amount > 0),
+    from_account_id UUID REFERENCES accounts(id),
+    to_account_id UUID REFERENCES accounts(id),
+    from_wallet_id UUID REFERENCES crypto_wallets(id),
+    to_wallet_id UUID REFERENCES crypto_wallets(id),
+    quote_id VARCHAR(100),
+    exchange_rate DECIMAL(20, 8),
+    quote_expires_at TIMESTAMP WITH TIME ZONE,
+    exchange_id UUID REFERENCES exchanges(id),
+    transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_exchange_sagas_in_flight ON exchange_sagas(next_attempt_at)
+    WHERE status IN ('RUNNING', 'COMPENSATING');
+CREATE INDEX idx_exchange_sagas_exchange_id ON exchange_sagas(exchange_id) WHERE exchange_id IS NOT NULL;
+
+CREATE TRIGGER update_exchange_sagas_updated_at BEFORE UPDATE ON exchange_sagas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Every step a saga ran or undid, with failed attempts
+CREATE TABLE IF NOT EXISTS exchange_saga_steps (
+    id BIGSERIAL PRIMARY KEY,
+    saga_id UUID NOT NULL REFERENCES exchange_sagas(id) ON DELETE CASCADE,
+    step VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('EXECUTE', 'COMPENSATE')),
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('SUCCEEDED', 'FAILED')),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_exchange_saga_steps_saga_id ON exchange_saga_steps(saga_id, id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS exchange_saga_steps;
+DROP TABLE IF EXISTS exchange_sagas;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Changes to a policy in force wait for the quorum of its approvers like any
+-- other guarded operation
+ALTER TABLE approval_requests DROP CONSTRAINT IF EXISTS approval_requests_operation_check;
+ALTER TABLE approval_requests ADD CONSTRAINT approval_requests_operation_check
+    CHECK (operation IN ('CRYPTO_WITHDRAW', 'FIAT_WITHDRAW', 'CRYPTO_TO_FIAT', 'FIAT_TO_CRYPTO', 'POLICY_CHANGE'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM approval_requests WHERE operation = 'POLICY_CHANGE';
+
+ALTER TABLE approval_requests DROP CONSTRAINT IF EXISTS approval_requests_operation_check;
+ALTER TABLE approval_requests ADD CONSTRAINT approval_requests_operation_check
+    CHECK (operation IN ('CRYPTO_WITHDRAW', 'FIAT_WITHDRAW', 'CRYPTO_TO_FIAT', 'FIAT_TO_CRYPTO'));
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Quotes the bank locks on its own rates. A saga locks under its ID, so the
+-- rate it got survives a crash before the saga row stored it: locking again
+-- returns the same quote. A released quote stays for the record but cannot be
+-- locked again.
+CREATE TABLE IF NOT EXISTS quote_locks (
+    lock_key VARCHAR(100) PRIMARY KEY,
+    quote_id VARCHAR(100) NOT NULL UNIQUE,
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    amount DECIMAL(20, 8) NOT NULL,
+    rate DECIMAL(20, 8) NOT NULL CHECK (rate > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS quote_locks;
+
+-- +goose StatementEnd
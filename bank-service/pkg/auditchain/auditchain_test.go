@@ -0,0 +1,310 @@
+package auditchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// chain seals n entries in order
+func chain(t *testing.T, n int) []*Entry {
+	t.Helper()
+
+	actor := uuid.New()
+	entries := make([]*Entry, n)
+	headSeq, headHash := int64(0), GenesisHash
+	for i := range entries {
+		e := &Entry{
+			ID:         uuid.New(),
+			OccurredAt: time.Date(2025, 3, 14, 8, 0, i, 0, time.UTC),
+			ActorType:  "user",
+			ActorID:    &actor,
+			ActorRole:  "admin",
+			Action:     "limit_rule.set",
+			EntityType: "limit_rule",
+			EntityID:   fmt.Sprintf("rule-%d", i),
+			After:      json.RawMessage(fmt.Sprintf(`{"max_amount": %d, "currency": "USD"}`, (i+1)*100)),
+		}
+		if err := Seal(e, headSeq, headHash); err != nil {
+			t.Fatal(err)
+		}
+		headSeq, headHash = e.Seq, e.Hash
+		entries[i] = e
+	}
+	return entries
+}
+
+func verify(v *Verifier, entries []*Entry) error {
+	for _, e := range entries {
+		if err := v.Add(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSeal(t *testing.T) {
+	entries := chain(t, 3)
+
+	for i, e := range entries {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d has sequence %d", i, e.Seq)
+		}
+		want := GenesisHash
+		if i > 0 {
+			want = entries[i-1].Hash
+		}
+		if e.PrevHash != want {
+			t.Errorf("entry %d links to %s, want %s", e.Seq, e.PrevHash, want)
+		}
+	}
+}
+
+func TestComputeHashIsCanonical(t *testing.T) {
+	e := chain(t, 1)[0]
+
+	// The database returns JSON with its own key order and spacing
+	reordered := *e
+	reordered.After = json.RawMessage(`{"currency":"USD","max_amount":100}`)
+	reordered.OccurredAt = e.OccurredAt.In(time.FixedZone("CET", 3600))
+
+	hash, err := reordered.ComputeHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != e.Hash {
+		t.Error("the same content read back hashes differently")
+	}
+}
+
+func TestComputeHashCoversFields(t *testing.T) {
+	other := uuid.New()
+
+	tests := []struct {
+		name   string
+		change func(e *Entry)
+	}{
+		{"sequence", func(e *Entry) { e.Seq++ }},
+		{"ID", func(e *Entry) { e.ID = uuid.New() }},
+		{"time", func(e *Entry) { e.OccurredAt = e.OccurredAt.Add(time.Nanosecond) }},
+		{"actor type", func(e *Entry) { e.ActorType = "system" }},
+		{"actor", func(e *Entry) { e.ActorID = &other }},
+		{"actor removed", func(e *Entry) { e.ActorID = nil }},
+		{"API key", func(e *Entry) { e.APIKeyID = &other }},
+		{"role", func(e *Entry) { e.ActorRole = "compliance" }},
+		{"action", func(e *Entry) { e.Action = "limit_rule.deleted" }},
+		{"entity type", func(e *Entry) { e.EntityType = "account" }},
+		{"entity", func(e *Entry) { e.EntityID = "rule-9" }},
+		{"before", func(e *Entry) { e.Before = json.RawMessage(`{}`) }},
+		{"after", func(e *Entry) { e.After = json.RawMessage(`{"max_amount": 1000, "currency": "USD"}`) }},
+		{"after removed", func(e *Entry) { e.After = nil }},
+		{"request", func(e *Entry) { e.RequestID = "req-1" }},
+		{"trace", func(e *Entry) { e.TraceID = "trace-1" }},
+		{"IP", func(e *Entry) { e.IP = "10.0.0.1" }},
+		{"previous hash", func(e *Entry) { e.PrevHash = e.Hash }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := *chain(t, 1)[0]
+			tt.change(&e)
+
+			hash, err := e.ComputeHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hash == e.Hash {
+				t.Errorf("changing the %s keeps the hash", tt.name)
+			}
+		})
+	}
+}
+
+func TestComputeHashInvalidJSON(t *testing.T) {
+	e := chain(t, 1)[0]
+	e.Before = json.RawMessage(`{"truncated":`)
+
+	if _, err := e.ComputeHash(); err == nil {
+		t.Error("hashed invalid JSON")
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		complete bool
+		// tamper changes a chain of five entries and returns what is verified
+		tamper  func(entries []*Entry) []*Entry
+		wantSeq int64
+		wantErr bool
+	}{
+		{
+			name:     "intact chain",
+			complete: true,
+			tamper:   func(entries []*Entry) []*Entry { return entries },
+		},
+		{
+			name:     "edited entry",
+			complete: true,
+			tamper: func(entries []*Entry) []*Entry {
+				entries[2].EntityID = "rule-x"
+				return entries
+			},
+			wantSeq: 3,
+			wantErr: true,
+		},
+		{
+			name:     "edited and rehashed entry",
+			complete: true,
+			tamper: func(entries []*Entry) []*Entry {
+				entries[2].EntityID = "rule-x"
+				entries[2].Hash, _ = entries[2].ComputeHash()
+				return entries
+			},
+			wantSeq: 4,
+			wantErr: true,
+		},
+		{
+			name:     "deleted entry",
+			complete: true,
+			tamper: func(entries []*Entry) []*Entry {
+				return append(entries[:2:2], entries[3:]...)
+			},
+			wantSeq: 4,
+			wantErr: true,
+		},
+		{
+			name:     "deleted first entry",
+			complete: true,
+			tamper:   func(entries []*Entry) []*Entry { return entries[1:] },
+			wantSeq:  2,
+			wantErr:  true,
+		},
+		{
+			name:     "reordered entries",
+			complete: true,
+			tamper: func(entries []*Entry) []*Entry {
+				entries[1], entries[2] = entries[2], entries[1]
+				return entries
+			},
+			wantSeq: 3,
+			wantErr: true,
+		},
+		{
+			name:     "first entry not on the genesis hash",
+			complete: true,
+			tamper: func(entries []*Entry) []*Entry {
+				entries[0].PrevHash = entries[4].Hash
+				entries[0].Hash, _ = entries[0].ComputeHash()
+				return entries
+			},
+			wantSeq: 1,
+			wantErr: true,
+		},
+		{
+			name:   "partial export starting later",
+			tamper: func(entries []*Entry) []*Entry { return entries[2:] },
+		},
+		{
+			name: "partial export with a gap",
+			tamper: func(entries []*Entry) []*Entry {
+				return append(entries[:2:2], entries[3:]...)
+			},
+		},
+		{
+			name: "partial export with an edited entry",
+			tamper: func(entries []*Entry) []*Entry {
+				entries[3].Action = "limit_rule.deleted"
+				return entries[2:]
+			},
+			wantSeq: 4,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(NewVerifier(tt.complete), tt.tamper(chain(t, 5)))
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("chain did not verify: %v", err)
+				}
+				return
+			}
+
+			var breakErr *BreakError
+			if !errors.As(err, &breakErr) {
+				t.Fatalf("err = %v, want a *BreakError", err)
+			}
+			if breakErr.Seq != tt.wantSeq {
+				t.Errorf("break reported at entry %d, want %d: %v", breakErr.Seq, tt.wantSeq, err)
+			}
+		})
+	}
+}
+
+func TestVerifierResult(t *testing.T) {
+	entries := chain(t, 6)
+
+	tests := []struct {
+		name     string
+		complete bool
+		entries  []*Entry
+		want     Result
+	}{
+		{
+			name:     "complete chain",
+			complete: true,
+			entries:  entries,
+			want:     Result{Entries: 6, FirstSeq: 1, HeadSeq: 6, HeadHash: entries[5].Hash},
+		},
+		{
+			name:    "partial export with gaps",
+			entries: []*Entry{entries[1], entries[3], entries[4]},
+			want:    Result{Entries: 3, FirstSeq: 2, HeadSeq: 5, HeadHash: entries[4].Hash, Gaps: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(tt.complete)
+			if err := verify(v, tt.entries); err != nil {
+				t.Fatal(err)
+			}
+			if got := v.Result(); got != tt.want {
+				t.Errorf("Result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, ""},
+		{"sorted keys", map[string]interface{}{"b": 1, "a": 2}, `{"a":2,"b":1}`},
+		{"raw JSON", json.RawMessage(`{ "z": [1, 2], "a": {"y": true, "x": null} }`), `{"a":{"x":null,"y":true},"z":[1,2]}`},
+		{"struct", struct {
+			Rate float64 `json:"rate"`
+		}{1.5}, `{"rate":1.5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonical(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonical = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package auditchain
+
+import "fmt"
+
+// BreakError reports the first entry at which the chain does not hold
+type BreakError struct {
+	Seq    int64
+	Reason string
+}
+
+func (e *BreakError) Error() string {
+	return fmt.Sprintf("chain broken at entry %d: %s", e.Seq, e.Reason)
+}
+
+// Result summarises a verified range of entries
+type Result struct {
+	Entries  int64  `json:"entries"`
+	FirstSeq int64  `json:"first_seq"`
+	HeadSeq  int64  `json:"head_seq"`
+	HeadHash string `json:"head_hash"`
+	// Gaps counts missing ranges; only partial exports may have them
+	Gaps int64 `json:"gaps"`
+}
+
+// Verifier checks entries fed to it in sequence order: every hash must match
+// the content of its entry and consecutive entries must link. A complete
+// verifier also requires the chain to start at the genesis entry without gaps,
+// which is what catches deleted entries.
+type Verifier struct {
+	complete bool
+	last     *Entry
+	result   Result
+}
+
+func NewVerifier(complete bool) *Verifier {
+	return &Verifier{complete: complete}
+}
+
+// Add checks the next entry. It returns a *BreakError when the chain breaks.
+func (v *Verifier) Add(e *Entry) error {
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return &BreakError{Seq: e.Seq, Reason: err.Error()}
+	}
+	if hash != e.Hash {
+		return &BreakError{Seq: e.Seq, Reason: "hash does not match the entry content"}
+	}
+
+	if e.Seq == 1 && e.PrevHash != GenesisHash {
+		return &BreakError{Seq: e.Seq, Reason: "first entry does not start from the genesis hash"}
+	}
+
+	switch {
+	case v.last == nil:
+		if v.complete && e.Seq != 1 {
+			return &BreakError{Seq: e.Seq, Reason: missing(1, e.Seq-1)}
+		}
+		v.result.FirstSeq = e.Seq
+	case e.Seq <= v.last.Seq:
+		return &BreakError{Seq: e.Seq, Reason: fmt.Sprintf("entry follows entry %d out of order", v.last.Seq)}
+	case e.Seq == v.last.Seq+1:
+		if e.PrevHash != v.last.Hash {
+			return &BreakError{Seq: e.Seq, Reason: fmt.Sprintf("previous hash does not match entry %d", v.last.Seq)}
+		}
+	default:
+		if v.complete {
+			return &BreakError{Seq: e.Seq, Reason: missing(v.last.Seq+1, e.Seq-1)}
+		}
+		v.result.Gaps++
+	}
+
+	v.last = e
+	v.result.Entries++
+	v.result.HeadSeq = e.Seq
+	v.result.HeadHash = e.Hash
+	return nil
+}
+
+// Result returns the summary of the entries checked so far
+func (v *Verifier) Result() Result {
+	return v.result
+}
+
+func missing(from, to int64) string {
+	if from == to {
+		return fmt.Sprintf("entry %d is missing", from)
+	}
+	return fmt.Sprintf("entries %d to %d are missing", from, to)
+}
@@ -0,0 +1,218 @@
+// Package merkle implements the Merkle sum tree used for proof of reserves.
+//
+// Every node carries a hash and the sum of the balances below it, so the root
+// commits to both the set of customer balances and their total. A leaf hides the
+// user behind a per-snapshot salt; an inclusion proof lets a user recompute the
+// root from their own balance without learning anything about other users.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Decimals is the number of fractional digits kept in leaf balances,
+// matching DECIMAL(20, 8) used for wallet balances
+const Decimals = 8
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Sibling positions in a proof path
+const (
+	PositionLeft  = "left"
+	PositionRight = "right"
+)
+
+// Node is a hash together with the balance sum it commits to
+type Node struct {
+	Hash [32]byte
+	Sum  uint64
+}
+
+// ProofStep is the sibling needed to compute the next level of the path
+type ProofStep struct {
+	Hash     string `json:"hash"`
+	Sum      uint64 `json:"sum"`
+	Position string `json:"position"`
+}
+
+// Proof is a self-contained inclusion proof for a single leaf
+type Proof struct {
+	UserID   string      `json:"user_id"`
+	Salt     string      `json:"salt"`
+	Balance  uint64      `json:"balance"`
+	LeafHash string      `json:"leaf_hash"`
+	Path     []ProofStep `json:"path"`
+	RootHash string      `json:"root_hash"`
+	RootSum  uint64      `json:"root_sum"`
+}
+
+// Tree is a Merkle sum tree; levels[0] holds the leaves and the last level the root
+type Tree struct {
+	levels [][]Node
+}
+
+// ToUnits converts a decimal balance into integer base units
+func ToUnits(amount float64) uint64 {
+	if amount <= 0 {
+		return 0
+	}
+	return uint64(math.Round(amount * math.Pow10(Decimals)))
+}
+
+// FromUnits converts integer base units back into a decimal balance
+func FromUnits(units uint64) float64 {
+	return float64(units) / math.Pow10(Decimals)
+}
+
+// Leaf builds the leaf node of a user balance
+func Leaf(userID string, salt []byte, balance uint64) Node {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(salt)
+	h.Write([]byte(userID))
+	h.Write(uint64Bytes(balance))
+
+	var node Node
+	copy(node.Hash[:], h.Sum(nil))
+	node.Sum = balance
+	return node
+}
+
+// Parent combines two child nodes
+func Parent(left, right Node) (Node, error) {
+	sum := left.Sum + right.Sum
+	if sum < left.Sum {
+		return Node{}, errors.New("balance sum overflows")
+	}
+
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left.Hash[:])
+	h.Write(uint64Bytes(left.Sum))
+	h.Write(right.Hash[:])
+	h.Write(uint64Bytes(right.Sum))
+
+	var node Node
+	copy(node.Hash[:], h.Sum(nil))
+	node.Sum = sum
+	return node, nil
+}
+
+// Build builds a tree over the given leaves. An odd node at the end of a level
+// is carried up unchanged.
+func Build(leaves []Node) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("cannot build a tree without leaves")
+	}
+
+	level := make([]Node, len(leaves))
+	copy(level, leaves)
+	tree := &Tree{levels: [][]Node{level}}
+
+	for len(level) > 1 {
+		next := make([]Node, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			parent, err := Parent(level[i], level[i+1])
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, parent)
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+
+	return tree, nil
+}
+
+// Root returns the root node
+func (t *Tree) Root() Node {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Path returns the sibling path from the leaf at index up to the root
+func (t *Tree) Path(index int) ([]ProofStep, error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil, fmt.Errorf("leaf index %d out of range", index)
+	}
+
+	path := []ProofStep{}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			position := PositionRight
+			if sibling < index {
+				position = PositionLeft
+			}
+			path = append(path, ProofStep{
+				Hash:     hex.EncodeToString(level[sibling].Hash[:]),
+				Sum:      level[sibling].Sum,
+				Position: position,
+			})
+		}
+		index /= 2
+	}
+
+	return path, nil
+}
+
+// Verify recomputes the root from the proof and checks it against the claimed root
+func (p *Proof) Verify() error {
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return fmt.Errorf("invalid salt: %w", err)
+	}
+
+	node := Leaf(p.UserID, salt, p.Balance)
+	if p.LeafHash != "" && p.LeafHash != hex.EncodeToString(node.Hash[:]) {
+		return errors.New("leaf hash does not match user, salt and balance")
+	}
+
+	for i, step := range p.Path {
+		sibling := Node{Sum: step.Sum}
+		raw, err := hex.DecodeString(step.Hash)
+		if err != nil || len(raw) != len(sibling.Hash) {
+			return fmt.Errorf("invalid hash at path step %d", i)
+		}
+		copy(sibling.Hash[:], raw)
+
+		switch step.Position {
+		case PositionLeft:
+			node, err = Parent(sibling, node)
+		case PositionRight:
+			node, err = Parent(node, sibling)
+		default:
+			return fmt.Errorf("invalid position %q at path step %d", step.Position, i)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if hex.EncodeToString(node.Hash[:]) != p.RootHash {
+		return errors.New("computed root hash does not match")
+	}
+	if node.Sum != p.RootSum {
+		return errors.New("computed root sum does not match")
+	}
+
+	return nil
+}
+
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,161 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		amount float64
+		units  uint64
+	}{
+		{0, 0},
+		{-1, 0},
+		{1, 100000000},
+		{0.00000001, 1},
+		{0.1 + 0.2, 30000000},
+		{12345.6789, 1234567890000},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.amount), func(t *testing.T) {
+			if got := ToUnits(tt.amount); got != tt.units {
+				t.Errorf("ToUnits(%v) = %d, want %d", tt.amount, got, tt.units)
+			}
+			if tt.amount > 0 {
+				if got := FromUnits(tt.units); math.Abs(got-tt.amount) > 1e-9 {
+					t.Errorf("FromUnits(%d) = %v, want %v", tt.units, got, tt.amount)
+				}
+			}
+		})
+	}
+}
+
+// snapshot builds a tree over n users and returns it with their proofs
+func snapshot(t *testing.T, n int) (*Tree, []*Proof) {
+	t.Helper()
+
+	salt := []byte("snapshot-salt")
+	leaves := make([]Node, n)
+	proofs := make([]*Proof, n)
+	for i := range leaves {
+		userID := fmt.Sprintf("user-%d", i)
+		balance := uint64(i+1) * 100
+		leaves[i] = Leaf(userID, salt, balance)
+		proofs[i] = &Proof{
+			UserID:   userID,
+			Salt:     hex.EncodeToString(salt),
+			Balance:  balance,
+			LeafHash: hex.EncodeToString(leaves[i].Hash[:]),
+		}
+	}
+
+	tree, err := Build(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := tree.Root()
+	for i, proof := range proofs {
+		if proof.Path, err = tree.Path(i); err != nil {
+			t.Fatal(err)
+		}
+		proof.RootHash = hex.EncodeToString(root.Hash[:])
+		proof.RootSum = root.Sum
+	}
+	return tree, proofs
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		leaves     int
+		pathLength int
+	}{
+		{leaves: 1, pathLength: 0},
+		{leaves: 2, pathLength: 1},
+		{leaves: 3, pathLength: 2},
+		{leaves: 4, pathLength: 2},
+		{leaves: 5, pathLength: 3},
+		{leaves: 8, pathLength: 3},
+		{leaves: 13, pathLength: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d leaves", tt.leaves), func(t *testing.T) {
+			tree, proofs := snapshot(t, tt.leaves)
+
+			// The root commits to the total of all balances
+			want := uint64(tt.leaves*(tt.leaves+1)/2) * 100
+			if got := tree.Root().Sum; got != want {
+				t.Errorf("root sum = %d, want %d", got, want)
+			}
+
+			for i, proof := range proofs {
+				if err := proof.Verify(); err != nil {
+					t.Errorf("proof of leaf %d: %v", i, err)
+				}
+			}
+			// The first leaf has a sibling at every level
+			if got := len(proofs[0].Path); got != tt.pathLength {
+				t.Errorf("path length = %d, want %d", got, tt.pathLength)
+			}
+		})
+	}
+}
+
+func TestBuildWithoutLeaves(t *testing.T) {
+	if _, err := Build(nil); err == nil {
+		t.Error("built a tree without leaves")
+	}
+}
+
+func TestParentOverflow(t *testing.T) {
+	if _, err := Parent(Node{Sum: math.MaxUint64}, Node{Sum: 1}); err == nil {
+		t.Error("sum overflow was not refused")
+	}
+}
+
+func TestPathOutOfRange(t *testing.T) {
+	tree, _ := snapshot(t, 3)
+	for _, index := range []int{-1, 3} {
+		if _, err := tree.Path(index); err == nil {
+			t.Errorf("Path(%d) succeeded", index)
+		}
+	}
+}
+
+func TestProofTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(p *Proof)
+	}{
+		{"inflated balance", func(p *Proof) { p.Balance++ }},
+		{"other user", func(p *Proof) { p.UserID = "user-x" }},
+		{"other salt", func(p *Proof) { p.Salt = hex.EncodeToString([]byte("other-salt")) }},
+		{"salt not hex", func(p *Proof) { p.Salt = "zz" }},
+		{"leaf hash of another leaf", func(p *Proof) { p.LeafHash = p.Path[0].Hash }},
+		{"sibling sum lowered", func(p *Proof) { p.Path[0].Sum-- }},
+		{"sibling hash not hex", func(p *Proof) { p.Path[0].Hash = "zz" }},
+		{"sibling hash truncated", func(p *Proof) { p.Path[0].Hash = p.Path[0].Hash[:10] }},
+		{"sibling swapped side", func(p *Proof) { p.Path[0].Position = PositionLeft }},
+		{"unknown position", func(p *Proof) { p.Path[0].Position = "up" }},
+		{"sibling dropped", func(p *Proof) { p.Path = p.Path[1:] }},
+		{"root sum lowered", func(p *Proof) { p.RootSum-- }},
+		{"other root", func(p *Proof) { p.RootHash = p.LeafHash }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, proofs := snapshot(t, 5)
+			proof := proofs[0]
+			tt.tamper(proof)
+
+			if err := proof.Verify(); err == nil {
+				t.Error("tampered proof verified")
+			}
+		})
+	}
+}
@@ -70,6 +70,157 @@ var (
 		},
 		[]string{"crypto_type"},
 	)
+
+	// Treasury metrics
+	TreasuryBalance = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "treasury_balance",
+			Help: "House hot/cold wallet balances and customer liabilities",
+		},
+		[]string{"crypto_type", "kind"},
+	)
+
+	// API key metrics
+	APIKeyRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_key_requests_total",
+			Help: "Total number of API key authenticated requests by result",
+		},
+		[]string{"result"},
+	)
+
+	// KYC metrics
+	KYCApplicationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kyc_applications_total",
+			Help: "Total number of KYC application decisions by tier and status",
+		},
+		[]string{"tier", "status"},
+	)
+
+	KYCLimitRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kyc_limit_rejections_total",
+			Help: "Total number of operations rejected by KYC tier limits",
+		},
+		[]string{"tier", "operation"},
+	)
+
+	// Velocity limit metrics
+	LimitRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "limit_rejections_total",
+			Help: "Total number of operations rejected by velocity limits by kind and rule scope",
+		},
+		[]string{"kind", "scope"},
+	)
+
+	// Sanctions screening metrics
+	ScreeningHoldsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "screening_holds_total",
+			Help: "Total number of operations held by watchlist screening and their review outcomes",
+		},
+		[]string{"operation", "status"},
+	)
+
+	// Fraud scoring metrics
+	RiskDecisionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "risk_decisions_total",
+			Help: "Total number of fraud scoring decisions by operation and decision",
+		},
+		[]string{"operation", "decision"},
+	)
+
+	RiskScoringDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "risk_scoring_duration_seconds",
+			Help:    "Time taken by the fraud scorer to decide on an operation",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"scorer"},
+	)
+
+	// Audit trail metrics
+	AuditEntriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_entries_total",
+			Help: "Total number of audit entries written by entity type",
+		},
+		[]string{"entity_type"},
+	)
+
+	AuditWriteFailuresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_write_failures_total",
+			Help: "Total number of state changes whose audit entry could not be written",
+		},
+	)
+
+	// Event outbox metrics
+	OutboxPendingMessages = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_pending_messages",
+			Help: "Number of events stored in the outbox and not yet published",
+		},
+	)
+
+	OutboxOldestPendingAge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_oldest_pending_age_seconds",
+			Help: "Age of the oldest event waiting in the outbox, zero when it is empty",
+		},
+	)
+
+	OutboxPublishedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_published_total",
+			Help: "Total number of outbox events confirmed by the broker by routing key",
+		},
+		[]string{"routing_key"},
+	)
+
+	OutboxPublishFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Total number of failed outbox publish attempts by routing key",
+		},
+		[]string{"routing_key"},
+	)
+
+	OutboxPublishLag = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "outbox_publish_lag_seconds",
+			Help:    "Time from storing an event in the outbox to its confirmed publication",
+			Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900, 3600},
+		},
+	)
+
+	EventsReplayedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "events_replayed_total",
+			Help: "Total number of stored events re-published to the replay exchange by outcome: published or unroutable",
+		},
+		[]string{"outcome"},
+	)
+
+	// Exchange saga metrics
+	ExchangeSagasTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "exchange_sagas_total",
+			Help: "Total number of finished exchange sagas by outcome: completed or compensated",
+		},
+		[]string{"outcome"},
+	)
+
+	ExchangeSagaStepFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "exchange_saga_step_failures_total",
+			Help: "Total number of failed exchange saga steps by step and action: EXECUTE or COMPENSATE",
+		},
+		[]string{"step", "action"},
+	)
 )
 
 // InitMetrics initializes Prometheus metrics
@@ -81,6 +232,24 @@ func InitMetrics() {
 	prometheus.MustRegister(ExchangesTotal)
 	prometheus.MustRegister(AccountsTotal)
 	prometheus.MustRegister(WalletsTotal)
+	prometheus.MustRegister(TreasuryBalance)
+	prometheus.MustRegister(APIKeyRequestsTotal)
+	prometheus.MustRegister(KYCApplicationsTotal)
+	prometheus.MustRegister(KYCLimitRejectionsTotal)
+	prometheus.MustRegister(LimitRejectionsTotal)
+	prometheus.MustRegister(ScreeningHoldsTotal)
+	prometheus.MustRegister(RiskDecisionsTotal)
+	prometheus.MustRegister(RiskScoringDuration)
+	prometheus.MustRegister(AuditEntriesTotal)
+	prometheus.MustRegister(AuditWriteFailuresTotal)
+	prometheus.MustRegister(OutboxPendingMessages)
+	prometheus.MustRegister(OutboxOldestPendingAge)
+	prometheus.MustRegister(OutboxPublishedTotal)
+	prometheus.MustRegister(OutboxPublishFailuresTotal)
+	prometheus.MustRegister(OutboxPublishLag)
+	prometheus.MustRegister(EventsReplayedTotal)
+	prometheus.MustRegister(ExchangeSagasTotal)
+	prometheus.MustRegister(ExchangeSagaStepFailuresTotal)
 
 	// Initialize metrics with zero values to make them visible
 	TransactionsTotal.WithLabelValues("transfer", "success").Add(0)
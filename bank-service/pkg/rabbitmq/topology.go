@@ -0,0 +1,36 @@
+package rabbitmq
+
+import (
+	"fmt"
+
+	"github.com/crypto-bank/shared/replay"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ExchangeEvents is the exchange bank events are published to. The events
+// themselves are defined by github.com/crypto-bank/shared/events.
+const ExchangeEvents = "bank.events"
+
+// ExchangeReplay is the exchange stored events are re-published to, see
+// github.com/crypto-bank/shared/replay
+const ExchangeReplay = replay.Exchange
+
+// DeclareTopology declares the exchanges bank events are published and
+// replayed to. It runs on every connect of the broker client.
+func DeclareTopology(ch *amqp.Channel) error {
+	for _, exchange := range []string{ExchangeEvents, ExchangeReplay} {
+		err := ch.ExchangeDeclare(
+			exchange, // name
+			"topic",  // type
+			true,     // durable
+			false,    // auto-deleted
+			false,    // internal
+			false,    // no-wait
+			nil,      // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+	}
+	return nil
+}
@@ -29,6 +29,15 @@ func Created(c *fiber.Ctx, data interface{}, message string) error {
 	})
 }
 
+// Accepted sends an accepted response for operations that will complete later
+func Accepted(c *fiber.Ctx, data interface{}, message string) error {
+	return c.Status(fiber.StatusAccepted).JSON(Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // Error sends an error response
 func Error(c *fiber.Ctx, statusCode int, message string, err error) error {
 	errorMsg := ""
@@ -62,3 +71,12 @@ func Unauthorized(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusUnauthorized, message, nil)
 }
 
+// Forbidden sends a forbidden error
+func Forbidden(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusForbidden, message, nil)
+}
+
+// Conflict sends a conflict error
+func Conflict(c *fiber.Ctx, message string, err error) error {
+	return Error(c, fiber.StatusConflict, message, err)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/crypto-bank/exchange-service/internal/assets"
+	"github.com/crypto-bank/exchange-service/internal/audit"
+	"github.com/crypto-bank/exchange-service/internal/authz"
 	"github.com/crypto-bank/exchange-service/internal/config"
 	"github.com/crypto-bank/exchange-service/internal/service"
 	"github.com/crypto-bank/exchange-service/pkg/logger"
@@ -42,13 +47,77 @@ func main() {
 	}
 	defer tracerCloser.Close()
 
-	// Create gRPC server with OpenTelemetry interceptor
+	// Load the bank-service token verification keys for protected methods
+	keySet := authz.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.Timeout)
+	verifier := authz.NewVerifier(keySet, cfg.Auth.Issuer)
+	refreshKeys := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Auth.Timeout)
+		defer cancel()
+
+		if err := keySet.Refresh(ctx); err != nil {
+			logger.Warn("Failed to load token verification keys", zap.Error(err))
+			return
+		}
+		logger.Debug("Token verification keys loaded", zap.Int("count", keySet.Len()))
+	}
+
+	stopKeyRefresh := make(chan struct{})
+	go func() {
+		refreshKeys()
+		ticker := time.NewTicker(cfg.Auth.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refreshKeys()
+			case <-stopKeyRefresh:
+				return
+			}
+		}
+	}()
+	defer close(stopKeyRefresh)
+
+	// Create gRPC server with OpenTelemetry and authorization interceptors
 	grpcServer := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(authz.UnaryServerInterceptor(verifier, authz.ExchangePolicy, logger.Log)),
+		grpc.ChainStreamInterceptor(authz.StreamServerInterceptor(verifier, authz.ExchangePolicy, logger.Log)),
 	)
-	exchangeService := service.NewExchangeServer(logger.Log)
+	// Rate changes are recorded in the bank-service audit trail
+	auditClient := audit.NewClient(cfg.Audit.RatesURL, cfg.Audit.Timeout)
+	exchangeService := service.NewExchangeServer(auditClient, logger.Log)
 	pb.RegisterExchangeServiceServer(grpcServer, exchangeService)
 
+	// Load supported assets and seed rates from the bank-service registry
+	assetClient := assets.NewClient(cfg.Assets.RegistryURL, cfg.Assets.Timeout)
+	syncAssets := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Assets.Timeout)
+		defer cancel()
+
+		registry, err := assetClient.Fetch(ctx)
+		if err != nil {
+			logger.Warn("Failed to load asset registry", zap.Error(err))
+			return
+		}
+		exchangeService.SyncAssets(registry)
+	}
+
+	stopAssetSync := make(chan struct{})
+	go func() {
+		syncAssets()
+		ticker := time.NewTicker(cfg.Assets.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				syncAssets()
+			case <-stopAssetSync:
+				return
+			}
+		}
+	}()
+	defer close(stopAssetSync)
+
 	// Start gRPC server
 	go func() {
 		lis, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
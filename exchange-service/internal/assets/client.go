@@ -0,0 +1,64 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Asset is the part of a bank-service asset registry entry the exchange needs
+type Asset struct {
+	Code             string   `json:"code"`
+	Kind             string   `json:"kind"`
+	Decimals         int      `json:"decimals"`
+	Enabled          bool     `json:"enabled"`
+	ReferenceUSDRate *float64 `json:"reference_usd_rate"`
+}
+
+// Client loads the asset registry published by bank-service
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewClient(url string, timeout time.Duration) *Client {
+	return &Client{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Fetch retrieves the enabled assets
+func (c *Client) Fetch(ctx context.Context) ([]Asset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch asset registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("asset registry returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Success bool    `json:"success"`
+		Data    []Asset `json:"data"`
+		Error   string  `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode asset registry: %w", err)
+	}
+
+	if !body.Success {
+		return nil, fmt.Errorf("asset registry error: %s", body.Error)
+	}
+
+	return body.Data, nil
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RateChange is a rate set through UpdateRate. PreviousRate is nil when the
+// pair had no rate.
+type RateChange struct {
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+	PreviousRate *float64 `json:"previous_rate"`
+	Rate         float64  `json:"rate"`
+}
+
+// Client records rate changes in the bank-service audit trail
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewClient(url string, timeout time.Duration) *Client {
+	return &Client{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// RecordRateChange records a change on behalf of the caller who made it. The
+// token is the caller's bank-service access token, so the bank attributes the
+// entry to them.
+func (c *Client) RecordRateChange(ctx context.Context, token string, change *RateChange) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to encode rate change: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to record rate change: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		var result struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return fmt.Errorf("audit trail returned status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	pb "github.com/crypto-bank/exchange-service/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Roles assigned by bank-service
+const (
+	RoleCustomer   = "customer"
+	RoleSupport    = "support"
+	RoleCompliance = "compliance"
+	RoleAdmin      = "admin"
+)
+
+// Policy maps full gRPC method names to the roles allowed to call them. Methods
+// missing from the policy are public.
+type Policy map[string][]string
+
+// ExchangePolicy protects the rate administration of the exchange service
+var ExchangePolicy = Policy{
+	pb.ExchangeService_UpdateRate_FullMethodName: {RoleAdmin},
+}
+
+type claimsKey struct{}
+
+type tokenKey struct{}
+
+// ClaimsFromContext returns the verified caller of a protected method
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// TokenFromContext returns the verified access token of the caller of a
+// protected method, for calls made on their behalf
+func TokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenKey{}).(string)
+	return token, ok
+}
+
+// UnaryServerInterceptor enforces the policy on unary calls
+func UnaryServerInterceptor(verifier *Verifier, policy Policy, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, verifier, policy, info.FullMethod, logger)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor enforces the policy on streaming calls
+func StreamServerInterceptor(verifier *Verifier, policy Policy, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, err := authorize(ss.Context(), verifier, policy, info.FullMethod, logger); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize checks the bearer token in the "authorization" metadata against the
+// roles the policy allows for the method
+func authorize(ctx context.Context, verifier *Verifier, policy Policy, method string, logger *zap.Logger) (context.Context, error) {
+	roles, protected := policy[method]
+	if !protected {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, value, found := strings.Cut(values[0], " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+	}
+	if token == "" {
+		return ctx, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return ctx, status.Error(codes.Unauthenticated, "token has expired")
+		}
+		return ctx, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	for _, role := range roles {
+		if claims.Role == role {
+			ctx = context.WithValue(ctx, claimsKey{}, claims)
+			return context.WithValue(ctx, tokenKey{}, token), nil
+		}
+	}
+
+	logger.Warn("Permission denied",
+		zap.String("method", method),
+		zap.String("user_id", claims.Subject),
+		zap.String("role", claims.Role),
+	)
+	return ctx, status.Error(codes.PermissionDenied, "insufficient permissions")
+}
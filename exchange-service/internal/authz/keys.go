@@ -0,0 +1,96 @@
+package authz
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwk is the part of a bank-service JSON Web Key the exchange needs
+type jwk struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	KeyID   string `json:"kid"`
+}
+
+// KeySet caches the token verification keys published by bank-service
+type KeySet struct {
+	url        string
+	httpClient *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+func NewKeySet(url string, timeout time.Duration) *KeySet {
+	return &KeySet{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+		keys:       make(map[string]ed25519.PublicKey),
+	}
+}
+
+// Refresh fetches the key set again. Keys rotated out by bank-service disappear
+// from the set, so tokens they signed stop verifying.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key set returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(body.Keys))
+	for _, key := range body.Keys {
+		if key.KeyType != "OKP" || key.Curve != "Ed25519" || key.KeyID == "" {
+			continue
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			continue
+		}
+		keys[key.KeyID] = ed25519.PublicKey(raw)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Len returns the number of cached keys
+func (s *KeySet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+func (s *KeySet) key(kid string) (ed25519.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
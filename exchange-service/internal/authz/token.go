@@ -0,0 +1,99 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "EdDSA"
+	accessTokenType  = "access"
+	// clockSkew tolerates small clock differences between services
+	clockSkew = 30 * time.Second
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Claims is the part of a bank-service access token the exchange needs
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	TokenType string `json:"token_type"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Role      string `json:"role"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verifier checks bank-service access tokens against a KeySet
+type Verifier struct {
+	keys   *KeySet
+	issuer string
+}
+
+func NewVerifier(keys *KeySet, issuer string) *Verifier {
+	return &Verifier{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+// Verify checks the signature, issuer, type and lifetime of an access token
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil || h.Algorithm != signingAlgorithm {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := v.keys.key(h.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Issuer != v.issuer || claims.TokenType != accessTokenType || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) {
+		return nil, ErrExpiredToken
+	}
+	if now.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
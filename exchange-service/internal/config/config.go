@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 type Config struct {
@@ -10,6 +11,9 @@ type Config struct {
 	GRPC     GRPCConfig
 	RabbitMQ RabbitMQConfig
 	Zipkin   ZipkinConfig
+	Assets   AssetsConfig
+	Auth     AuthConfig
+	Audit    AuditConfig
 }
 
 type ServerConfig struct {
@@ -32,6 +36,24 @@ type ZipkinConfig struct {
 	Endpoint string
 }
 
+type AssetsConfig struct {
+	RegistryURL     string
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+}
+
+type AuthConfig struct {
+	JWKSURL         string
+	Issuer          string
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+}
+
+type AuditConfig struct {
+	RatesURL string
+	Timeout  time.Duration
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
@@ -51,6 +73,21 @@ func LoadConfig() *Config {
 		Zipkin: ZipkinConfig{
 			Endpoint: getEnv("ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
 		},
+		Assets: AssetsConfig{
+			RegistryURL:     getEnv("ASSET_REGISTRY_URL", "http://localhost:8080/api/v1/assets"),
+			RefreshInterval: getEnvDuration("ASSET_REFRESH_INTERVAL", time.Minute),
+			Timeout:         getEnvDuration("ASSET_REGISTRY_TIMEOUT", 5*time.Second),
+		},
+		Auth: AuthConfig{
+			JWKSURL:         getEnv("AUTH_JWKS_URL", "http://localhost:8080/.well-known/jwks.json"),
+			Issuer:          getEnv("AUTH_ISSUER", "crypto-bank"),
+			RefreshInterval: getEnvDuration("AUTH_JWKS_REFRESH_INTERVAL", time.Minute),
+			Timeout:         getEnvDuration("AUTH_JWKS_TIMEOUT", 5*time.Second),
+		},
+		Audit: AuditConfig{
+			RatesURL: getEnv("AUDIT_RATES_URL", "http://localhost:8080/admin/v1/audit/rates"),
+			Timeout:  getEnvDuration("AUDIT_TIMEOUT", 5*time.Second),
+		},
 	}
 }
 
@@ -70,3 +107,10 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -2,9 +2,19 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/crypto-bank/exchange-service/internal/assets"
+	"github.com/crypto-bank/exchange-service/internal/audit"
+	"github.com/crypto-bank/exchange-service/internal/authz"
 	"github.com/crypto-bank/exchange-service/pkg/metrics"
 	pb "github.com/crypto-bank/exchange-service/proto"
 	"go.uber.org/zap"
@@ -12,58 +22,112 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// maxPageSize caps the pages callers ask for. A request without a page size
+// gets every matching rate in one page: GetAllRates returned all rates before
+// it paginated, and callers written then do not follow page tokens.
+const maxPageSize = 500
+
+// RateAuditor records rate changes in the audit trail on behalf of the caller
+type RateAuditor interface {
+	RecordRateChange(ctx context.Context, token string, change *audit.RateChange) error
+}
+
 type ExchangeServer struct {
 	pb.UnimplementedExchangeServiceServer
-	rates  map[string]float64
-	mu     sync.RWMutex
-	logger *zap.Logger
+	rates   map[Pair]float64
+	assets  map[string]assets.Asset
+	mu      sync.RWMutex
+	auditor RateAuditor
+	logger  *zap.Logger
 }
 
-func NewExchangeServer(logger *zap.Logger) *ExchangeServer {
-	server := &ExchangeServer{
-		rates:  make(map[string]float64),
-		logger: logger,
+func NewExchangeServer(auditor RateAuditor, logger *zap.Logger) *ExchangeServer {
+	return &ExchangeServer{
+		rates:   make(map[Pair]float64),
+		assets:  make(map[string]assets.Asset),
+		auditor: auditor,
+		logger:  logger,
 	}
-
-	// Initialize with default rates
-	server.initializeRates()
-
-	return server
 }
 
-func (s *ExchangeServer) initializeRates() {
+// SyncAssets applies the asset registry: pairs of disabled or removed assets are
+// dropped and missing pairs are seeded from the assets' reference USD rates.
+// Rates set through UpdateRate are kept.
+func (s *ExchangeServer) SyncAssets(registry []assets.Asset) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Crypto to USD
-	s.rates["BTC-USD"] = 43500.00
-	s.rates["ETH-USD"] = 2280.50
-	s.rates["USDT-USD"] = 1.00
-	s.rates["BNB-USD"] = 315.75
-	s.rates["SOL-USD"] = 98.30
-
-	// USD to Crypto
-	s.rates["USD-BTC"] = 0.000023
-	s.rates["USD-ETH"] = 0.000438
-	s.rates["USD-USDT"] = 1.00
-	s.rates["USD-BNB"] = 0.003167
-	s.rates["USD-SOL"] = 0.010173
-
-	// Fiat conversions
-	s.rates["USD-EUR"] = 0.92
-	s.rates["USD-RUB"] = 92.50
-	s.rates["USD-GBP"] = 0.79
-	s.rates["EUR-USD"] = 1.09
-	s.rates["RUB-USD"] = 0.0108
-	s.rates["GBP-USD"] = 1.27
-
-	// Crypto to other fiat
-	s.rates["BTC-EUR"] = 40020.00
-	s.rates["ETH-EUR"] = 2097.66
-	s.rates["BTC-RUB"] = 4023750.00
-	s.rates["ETH-RUB"] = 210941.25
-
-	s.logger.Info("Exchange rates initialized", zap.Int("count", len(s.rates)))
+	enabled := make(map[string]assets.Asset, len(registry))
+	for _, asset := range registry {
+		if asset.Enabled {
+			enabled[asset.Code] = asset
+		}
+	}
+	s.assets = enabled
+
+	for pair := range s.rates {
+		if s.validatePair(pair) != nil {
+			delete(s.rates, pair)
+		}
+	}
+
+	seeded := 0
+	for _, base := range enabled {
+		for _, quote := range enabled {
+			if base.Code == quote.Code || base.ReferenceUSDRate == nil || quote.ReferenceUSDRate == nil {
+				continue
+			}
+			pair := Pair{Base: base.Code, Quote: quote.Code}
+			if _, exists := s.rates[pair]; exists {
+				continue
+			}
+			s.rates[pair] = *base.ReferenceUSDRate / *quote.ReferenceUSDRate
+			seeded++
+		}
+	}
+
+	s.logger.Info("Asset registry applied",
+		zap.Int("assets", len(enabled)),
+		zap.Int("seeded_rates", seeded),
+		zap.Int("rates", len(s.rates)),
+	)
+}
+
+// validatePair checks both currencies against the supported asset list.
+// Callers must hold s.mu.
+func (s *ExchangeServer) validatePair(pair Pair) error {
+	if _, ok := s.assets[pair.Base]; !ok {
+		return fmt.Errorf("unsupported asset %s", pair.Base)
+	}
+	if _, ok := s.assets[pair.Quote]; !ok {
+		return fmt.Errorf("unsupported asset %s", pair.Quote)
+	}
+	return nil
+}
+
+// assetKind maps a registry asset kind onto the proto enum. Callers must hold s.mu.
+func (s *ExchangeServer) assetKind(code string) pb.AssetKind {
+	switch s.assets[code].Kind {
+	case "FIAT":
+		return pb.AssetKind_ASSET_KIND_FIAT
+	case "CRYPTO":
+		return pb.AssetKind_ASSET_KIND_CRYPTO
+	default:
+		return pb.AssetKind_ASSET_KIND_UNSPECIFIED
+	}
+}
+
+// rateResponse builds the response of a pair. Callers must hold s.mu.
+func (s *ExchangeServer) rateResponse(pair Pair, rate float64, timestamp int64) *pb.ExchangeRateResponse {
+	return &pb.ExchangeRateResponse{
+		FromCurrency: pair.Base,
+		ToCurrency:   pair.Quote,
+		Rate:         rate,
+		Timestamp:    timestamp,
+		Pair:         &pb.Pair{Base: pair.Base, Quote: pair.Quote},
+		BaseKind:     s.assetKind(pair.Base),
+		QuoteKind:    s.assetKind(pair.Quote),
+	}
 }
 
 func (s *ExchangeServer) GetExchangeRate(ctx context.Context, req *pb.ExchangeRateRequest) (*pb.ExchangeRateResponse, error) {
@@ -72,58 +136,111 @@ func (s *ExchangeServer) GetExchangeRate(ctx context.Context, req *pb.ExchangeRa
 		zap.String("to", req.ToCurrency),
 	)
 
+	pair, err := NewPair(req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("GetExchangeRate", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	key := req.FromCurrency + "-" + req.ToCurrency
-	rate, exists := s.rates[key]
+	if err := s.validatePair(pair); err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("GetExchangeRate", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
+	rate, exists := s.rates[pair]
 	if !exists {
-		s.logger.Warn("Exchange rate not found",
-			zap.String("from", req.FromCurrency),
-			zap.String("to", req.ToCurrency),
-		)
+		s.logger.Warn("Exchange rate not found", zap.Stringer("pair", pair))
 		metrics.GrpcRequestsTotal.WithLabelValues("GetExchangeRate", "error").Inc()
-		return nil, status.Errorf(codes.NotFound, "exchange rate not found for %s to %s", req.FromCurrency, req.ToCurrency)
+		return nil, status.Errorf(codes.NotFound, "exchange rate not found for %s to %s", pair.Base, pair.Quote)
 	}
 
 	metrics.GrpcRequestsTotal.WithLabelValues("GetExchangeRate", "success").Inc()
-	metrics.ExchangesTotal.WithLabelValues(req.FromCurrency, req.ToCurrency, "success").Inc()
+	metrics.ExchangesTotal.WithLabelValues(pair.Base, pair.Quote, "success").Inc()
 
-	return &pb.ExchangeRateResponse{
-		FromCurrency: req.FromCurrency,
-		ToCurrency:   req.ToCurrency,
-		Rate:         rate,
-		Timestamp:    time.Now().Unix(),
-	}, nil
+	return s.rateResponse(pair, rate, time.Now().Unix()), nil
 }
 
-func (s *ExchangeServer) GetAllRates(ctx context.Context, req *pb.Empty) (*pb.AllRatesResponse, error) {
-	s.logger.Info("GetAllRates called")
+func (s *ExchangeServer) GetAllRates(ctx context.Context, req *pb.GetAllRatesRequest) (*pb.AllRatesResponse, error) {
+	s.logger.Info("GetAllRates called",
+		zap.String("base", req.Base),
+		zap.String("quote", req.Quote),
+		zap.String("base_kind", req.BaseKind.String()),
+		zap.String("quote_kind", req.QuoteKind.String()),
+		zap.Int32("page_size", req.PageSize),
+	)
+
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		metrics.GrpcRequestsTotal.WithLabelValues("GetAllRates", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("GetAllRates", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	base := strings.ToUpper(strings.TrimSpace(req.Base))
+	quote := strings.ToUpper(strings.TrimSpace(req.Quote))
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var rates []*pb.ExchangeRateResponse
-	timestamp := time.Now().Unix()
+	pairs := make([]Pair, 0, len(s.rates))
+	for pair := range s.rates {
+		if base != "" && pair.Base != base {
+			continue
+		}
+		if quote != "" && pair.Quote != quote {
+			continue
+		}
+		if req.BaseKind != pb.AssetKind_ASSET_KIND_UNSPECIFIED && s.assetKind(pair.Base) != req.BaseKind {
+			continue
+		}
+		if req.QuoteKind != pb.AssetKind_ASSET_KIND_UNSPECIFIED && s.assetKind(pair.Quote) != req.QuoteKind {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	// Stable ordering keeps page tokens meaningful across calls
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].less(pairs[j])
+	})
+
+	if offset > len(pairs) {
+		offset = len(pairs)
+	}
+	// Without a page size the page holds every rate
+	end := offset + pageSize
+	if pageSize == 0 || end > len(pairs) {
+		end = len(pairs)
+	}
 
-	for key, rate := range s.rates {
-		// Parse key (e.g., "BTC-USD" -> "BTC" and "USD")
-		fromCurrency := key[:len(key)-4]
-		toCurrency := key[len(key)-3:]
+	timestamp := time.Now().Unix()
+	rates := make([]*pb.ExchangeRateResponse, 0, end-offset)
+	for _, pair := range pairs[offset:end] {
+		rates = append(rates, s.rateResponse(pair, s.rates[pair], timestamp))
+	}
 
-		rates = append(rates, &pb.ExchangeRateResponse{
-			FromCurrency: fromCurrency,
-			ToCurrency:   toCurrency,
-			Rate:         rate,
-			Timestamp:    timestamp,
-		})
+	var nextPageToken string
+	if end < len(pairs) {
+		nextPageToken = encodePageToken(end)
 	}
 
 	metrics.GrpcRequestsTotal.WithLabelValues("GetAllRates", "success").Inc()
 
 	return &pb.AllRatesResponse{
-		Rates: rates,
+		Rates:         rates,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(len(pairs)),
 	}, nil
 }
 
@@ -134,20 +251,57 @@ func (s *ExchangeServer) UpdateRate(ctx context.Context, req *pb.UpdateRateReque
 		zap.Float64("rate", req.Rate),
 	)
 
+	pair, err := NewPair(req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.Rate <= 0 || math.IsInf(req.Rate, 0) || math.IsNaN(req.Rate) {
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Errorf(codes.InvalidArgument, "rate must be a positive number, got %v", req.Rate)
+	}
+
+	s.mu.RLock()
+	err = s.validatePair(pair)
+	change := &audit.RateChange{FromCurrency: pair.Base, ToCurrency: pair.Quote, Rate: req.Rate}
+	if previous, exists := s.rates[pair]; exists {
+		change.PreviousRate = &previous
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// A rate only changes once the audit trail holds the change
+	token, ok := authz.TokenFromContext(ctx)
+	if !ok {
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if err := s.auditor.RecordRateChange(ctx, token, change); err != nil {
+		s.logger.Error("Failed to record rate change", zap.Stringer("pair", pair), zap.Error(err))
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Error(codes.Unavailable, "rate change could not be recorded in the audit trail")
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	key := req.FromCurrency + "-" + req.ToCurrency
-	s.rates[key] = req.Rate
+	// The asset registry may have dropped the pair in the meantime
+	if err := s.validatePair(pair); err != nil {
+		metrics.GrpcRequestsTotal.WithLabelValues("UpdateRate", "error").Inc()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.rates[pair] = req.Rate
 
 	// Also update inverse rate
-	inverseKey := req.ToCurrency + "-" + req.FromCurrency
-	if req.Rate != 0 {
-		s.rates[inverseKey] = 1.0 / req.Rate
-	}
+	s.rates[pair.Inverse()] = 1.0 / req.Rate
 
 	s.logger.Info("Exchange rate updated",
-		zap.String("key", key),
+		zap.Stringer("pair", pair),
 		zap.Float64("rate", req.Rate),
 	)
 
@@ -158,3 +312,27 @@ func (s *ExchangeServer) UpdateRate(ctx context.Context, req *pb.UpdateRateReque
 		Message: "Rate updated successfully",
 	}, nil
 }
+
+// encodePageToken turns a result offset into an opaque page token
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodePageToken reverses encodePageToken; an empty token is the first page
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, errors.New("invalid page_token")
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page_token")
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/crypto-bank/exchange-service/internal/assets"
+	pb "github.com/crypto-bank/exchange-service/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAllRatesPages(t *testing.T) {
+	// More rates than a page held before callers had to ask for pages
+	server := NewExchangeServer(nil, zap.NewNop())
+	for i := 0; i < 60; i++ {
+		server.rates[Pair{Base: fmt.Sprintf("C%02d", i), Quote: "USD"}] = float64(i + 1)
+		server.rates[Pair{Base: "USD", Quote: fmt.Sprintf("C%02d", i)}] = 1 / float64(i+1)
+	}
+
+	tests := []struct {
+		name     string
+		pageSize int32
+		// pages are the sizes of the pages returned following the page tokens
+		pages []int
+	}{
+		{name: "no page size returns every rate", pageSize: 0, pages: []int{120}},
+		{name: "page size splits the rates", pageSize: 50, pages: []int{50, 50, 20}},
+		{name: "page size dividing the rates", pageSize: 60, pages: []int{60, 60}},
+		{name: "page size above the cap", pageSize: 1000, pages: []int{120}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []int
+			seen := make(map[string]bool)
+			req := &pb.GetAllRatesRequest{PageSize: tt.pageSize}
+			for {
+				resp, err := server.GetAllRates(context.Background(), req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.TotalCount != 120 {
+					t.Errorf("total count = %d, want 120", resp.TotalCount)
+				}
+				pages = append(pages, len(resp.Rates))
+				for _, rate := range resp.Rates {
+					pair := rate.FromCurrency + "/" + rate.ToCurrency
+					if seen[pair] {
+						t.Errorf("%s returned twice", pair)
+					}
+					seen[pair] = true
+				}
+				if resp.NextPageToken == "" {
+					break
+				}
+				req.PageToken = resp.NextPageToken
+			}
+
+			if fmt.Sprint(pages) != fmt.Sprint(tt.pages) {
+				t.Errorf("pages = %v, want %v", pages, tt.pages)
+			}
+		})
+	}
+}
+
+func TestGetAllRatesRejectsNegativePageSize(t *testing.T) {
+	server := NewExchangeServer(nil, zap.NewNop())
+
+	_, err := server.GetAllRates(context.Background(), &pb.GetAllRatesRequest{PageSize: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+}
+
+// newFilterServer holds rates between fiat and crypto assets, USDT among them
+func newFilterServer() *ExchangeServer {
+	server := NewExchangeServer(nil, zap.NewNop())
+	for _, asset := range []assets.Asset{
+		{Code: "USD", Kind: "FIAT", Enabled: true},
+		{Code: "EUR", Kind: "FIAT", Enabled: true},
+		{Code: "BTC", Kind: "CRYPTO", Enabled: true},
+		{Code: "ETH", Kind: "CRYPTO", Enabled: true},
+		{Code: "USDT", Kind: "CRYPTO", Enabled: true},
+	} {
+		server.assets[asset.Code] = asset
+	}
+	for pair, rate := range map[Pair]float64{
+		{Base: "BTC", Quote: "USD"}:  60000,
+		{Base: "BTC", Quote: "EUR"}:  55000,
+		{Base: "BTC", Quote: "USDT"}: 60010,
+		{Base: "ETH", Quote: "USD"}:  3000,
+		{Base: "ETH", Quote: "USDT"}: 3001,
+		{Base: "USD", Quote: "EUR"}:  0.92,
+		{Base: "EUR", Quote: "USD"}:  1.09,
+		{Base: "USDT", Quote: "USD"}: 0.999,
+		{Base: "USD", Quote: "BTC"}:  1.0 / 60000,
+	} {
+		server.rates[pair] = rate
+	}
+	return server
+}
+
+func TestGetAllRatesFilters(t *testing.T) {
+	server := newFilterServer()
+
+	tests := []struct {
+		name string
+		req  *pb.GetAllRatesRequest
+		want []string
+	}{
+		{
+			name: "no filter",
+			req:  &pb.GetAllRatesRequest{},
+			want: []string{"BTC/EUR", "BTC/USD", "BTC/USDT", "ETH/USD", "ETH/USDT", "EUR/USD", "USD/BTC", "USD/EUR", "USDT/USD"},
+		},
+		{
+			name: "base",
+			req:  &pb.GetAllRatesRequest{Base: "BTC"},
+			want: []string{"BTC/EUR", "BTC/USD", "BTC/USDT"},
+		},
+		{
+			name: "base in lower case with spaces",
+			req:  &pb.GetAllRatesRequest{Base: " btc "},
+			want: []string{"BTC/EUR", "BTC/USD", "BTC/USDT"},
+		},
+		{
+			name: "quote",
+			req:  &pb.GetAllRatesRequest{Quote: "USD"},
+			want: []string{"BTC/USD", "ETH/USD", "EUR/USD", "USDT/USD"},
+		},
+		{
+			name: "quote of four letters",
+			req:  &pb.GetAllRatesRequest{Quote: "usdt"},
+			want: []string{"BTC/USDT", "ETH/USDT"},
+		},
+		{
+			name: "base and quote",
+			req:  &pb.GetAllRatesRequest{Base: "ETH", Quote: "USDT"},
+			want: []string{"ETH/USDT"},
+		},
+		{
+			name: "base kind",
+			req:  &pb.GetAllRatesRequest{BaseKind: pb.AssetKind_ASSET_KIND_FIAT},
+			want: []string{"EUR/USD", "USD/BTC", "USD/EUR"},
+		},
+		{
+			name: "quote kind",
+			req:  &pb.GetAllRatesRequest{QuoteKind: pb.AssetKind_ASSET_KIND_CRYPTO},
+			want: []string{"BTC/USDT", "ETH/USDT", "USD/BTC"},
+		},
+		{
+			name: "base and quote kinds",
+			req:  &pb.GetAllRatesRequest{BaseKind: pb.AssetKind_ASSET_KIND_CRYPTO, QuoteKind: pb.AssetKind_ASSET_KIND_FIAT},
+			want: []string{"BTC/EUR", "BTC/USD", "ETH/USD", "USDT/USD"},
+		},
+		{
+			name: "base with quote kind",
+			req:  &pb.GetAllRatesRequest{Base: "BTC", QuoteKind: pb.AssetKind_ASSET_KIND_CRYPTO},
+			want: []string{"BTC/USDT"},
+		},
+		{
+			name: "unknown base",
+			req:  &pb.GetAllRatesRequest{Base: "DOGE"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.GetAllRates(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(resp.Rates))
+			for _, rate := range resp.Rates {
+				got = append(got, rate.FromCurrency+"/"+rate.ToCurrency)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("rates = %v, want %v", got, tt.want)
+			}
+			if int(resp.TotalCount) != len(tt.want) {
+				t.Errorf("total count = %d, want %d", resp.TotalCount, len(tt.want))
+			}
+		})
+	}
+}
+
+// TestGetAllRatesUSDTQuote guards against reading pairs back from their key by
+// offsets, which turned BTC-USDT into BTC- and SDT
+func TestGetAllRatesUSDTQuote(t *testing.T) {
+	server := newFilterServer()
+
+	resp, err := server.GetAllRates(context.Background(), &pb.GetAllRatesRequest{Base: "BTC", Quote: "USDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Rates) != 1 {
+		t.Fatalf("got %d rates, want 1", len(resp.Rates))
+	}
+
+	rate := resp.Rates[0]
+	if rate.FromCurrency != "BTC" || rate.ToCurrency != "USDT" || rate.Rate != 60010 {
+		t.Errorf("rate = %s/%s %v, want BTC/USDT 60010", rate.FromCurrency, rate.ToCurrency, rate.Rate)
+	}
+	if rate.BaseKind != pb.AssetKind_ASSET_KIND_CRYPTO || rate.QuoteKind != pb.AssetKind_ASSET_KIND_CRYPTO {
+		t.Errorf("kinds = %v/%v, want both crypto", rate.BaseKind, rate.QuoteKind)
+	}
+}
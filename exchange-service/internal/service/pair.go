@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Pair is a currency pair; a rate is the price of one Base unit in Quote units
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// NewPair builds a normalized pair and checks its shape
+func NewPair(base, quote string) (Pair, error) {
+	pair := Pair{
+		Base:  strings.ToUpper(strings.TrimSpace(base)),
+		Quote: strings.ToUpper(strings.TrimSpace(quote)),
+	}
+
+	if pair.Base == "" || pair.Quote == "" {
+		return Pair{}, errors.New("base and quote currencies are required")
+	}
+	if pair.Base == pair.Quote {
+		return Pair{}, fmt.Errorf("base and quote currencies must differ: %s", pair.Base)
+	}
+
+	return pair, nil
+}
+
+// Inverse returns the pair with base and quote swapped
+func (p Pair) Inverse() Pair {
+	return Pair{Base: p.Quote, Quote: p.Base}
+}
+
+func (p Pair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// less orders pairs by base, then quote
+func (p Pair) less(other Pair) bool {
+	if p.Base != other.Base {
+		return p.Base < other.Base
+	}
+	return p.Quote < other.Quote
+}
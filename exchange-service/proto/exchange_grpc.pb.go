@@ -32,9 +32,9 @@ const (
 type ExchangeServiceClient interface {
 	// GetExchangeRate returns the current exchange rate between two currencies
 	GetExchangeRate(ctx context.Context, in *ExchangeRateRequest, opts ...grpc.CallOption) (*ExchangeRateResponse, error)
-	// GetAllRates returns all available exchange rates
-	GetAllRates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AllRatesResponse, error)
-	// UpdateRate updates an exchange rate (admin only)
+	// GetAllRates returns available exchange rates, optionally filtered and paginated
+	GetAllRates(ctx context.Context, in *GetAllRatesRequest, opts ...grpc.CallOption) (*AllRatesResponse, error)
+	// UpdateRate updates an exchange rate (requires an admin access token)
 	UpdateRate(ctx context.Context, in *UpdateRateRequest, opts ...grpc.CallOption) (*UpdateRateResponse, error)
 }
 
@@ -56,7 +56,7 @@ func (c *exchangeServiceClient) GetExchangeRate(ctx context.Context, in *Exchang
 	return out, nil
 }
 
-func (c *exchangeServiceClient) GetAllRates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AllRatesResponse, error) {
+func (c *exchangeServiceClient) GetAllRates(ctx context.Context, in *GetAllRatesRequest, opts ...grpc.CallOption) (*AllRatesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AllRatesResponse)
 	err := c.cc.Invoke(ctx, ExchangeService_GetAllRates_FullMethodName, in, out, cOpts...)
@@ -84,9 +84,9 @@ func (c *exchangeServiceClient) UpdateRate(ctx context.Context, in *UpdateRateRe
 type ExchangeServiceServer interface {
 	// GetExchangeRate returns the current exchange rate between two currencies
 	GetExchangeRate(context.Context, *ExchangeRateRequest) (*ExchangeRateResponse, error)
-	// GetAllRates returns all available exchange rates
-	GetAllRates(context.Context, *Empty) (*AllRatesResponse, error)
-	// UpdateRate updates an exchange rate (admin only)
+	// GetAllRates returns available exchange rates, optionally filtered and paginated
+	GetAllRates(context.Context, *GetAllRatesRequest) (*AllRatesResponse, error)
+	// UpdateRate updates an exchange rate (requires an admin access token)
 	UpdateRate(context.Context, *UpdateRateRequest) (*UpdateRateResponse, error)
 	mustEmbedUnimplementedExchangeServiceServer()
 }
@@ -101,7 +101,7 @@ type UnimplementedExchangeServiceServer struct{}
 func (UnimplementedExchangeServiceServer) GetExchangeRate(context.Context, *ExchangeRateRequest) (*ExchangeRateResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetExchangeRate not implemented")
 }
-func (UnimplementedExchangeServiceServer) GetAllRates(context.Context, *Empty) (*AllRatesResponse, error) {
+func (UnimplementedExchangeServiceServer) GetAllRates(context.Context, *GetAllRatesRequest) (*AllRatesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetAllRates not implemented")
 }
 func (UnimplementedExchangeServiceServer) UpdateRate(context.Context, *UpdateRateRequest) (*UpdateRateResponse, error) {
@@ -147,7 +147,7 @@ func _ExchangeService_GetExchangeRate_Handler(srv interface{}, ctx context.Conte
 }
 
 func _ExchangeService_GetAllRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(GetAllRatesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -159,7 +159,7 @@ func _ExchangeService_GetAllRates_Handler(srv interface{}, ctx context.Context,
 		FullMethod: ExchangeService_GetAllRates_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServiceServer).GetAllRates(ctx, req.(*Empty))
+		return srv.(ExchangeServiceServer).GetAllRates(ctx, req.(*GetAllRatesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/crypto-bank/notification-service/internal/service"
+	"github.com/crypto-bank/notification-service/pkg/logger"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/replay"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// newHandler decodes bank events and notifies the users they concern. It
+// serves the live and the replay queue.
+func newHandler(notificationService *service.NotificationService, rebuild *replay.Rebuild) broker.Handler {
+	return func(ctx context.Context, msg amqp.Delivery) error {
+		replayed := replay.IsReplay(msg)
+		span := trace.SpanFromContext(ctx)
+		logger.Debug("Received message",
+			zap.String("routing_key", msg.RoutingKey),
+			zap.Bool("replayed", replayed),
+			zap.String("trace_id", span.SpanContext().TraceID().String()),
+		)
+
+		envelope, err := events.FromAMQP(msg)
+		if err != nil {
+			return broker.Permanent(err)
+		}
+		span.SetAttributes(
+			attribute.String("cloudevents.event_id", envelope.ID),
+			attribute.String("cloudevents.event_type", envelope.Type),
+			attribute.String("cloudevents.event_subject", envelope.Subject),
+		)
+		event, err := envelope.Event()
+		if errors.Is(err, events.ErrUnknownType) {
+			logger.Warn("Unknown event type", zap.String("type", envelope.Type))
+			return nil
+		}
+		if err != nil {
+			return broker.Permanent(err)
+		}
+
+		applied := rebuild.Apply(envelope.ID, replayed, func() {
+			switch event := event.(type) {
+			case *events.UserEvent:
+				notificationService.ProcessUserEvent(envelope.Type, event, replayed)
+			case *events.TransactionEvent:
+				notificationService.ProcessTransactionEvent(event, replayed)
+			case *events.ExchangeEvent:
+				notificationService.ProcessExchangeEvent(event, replayed)
+			case *events.AccountEvent:
+				notificationService.ProcessAccountEvent(event, replayed)
+			case *events.WalletEvent:
+				notificationService.ProcessWalletEvent(event, replayed)
+			default:
+				logger.Debug("Ignoring event", zap.String("type", envelope.Type))
+			}
+		})
+		if !applied {
+			logger.Debug("Skipping event already notified",
+				zap.String("event_id", envelope.ID),
+				zap.Bool("replayed", replayed),
+			)
+		}
+		return nil
+	}
+}
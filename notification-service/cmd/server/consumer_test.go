@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-bank/notification-service/internal/service"
+	"github.com/crypto-bank/notification-service/pkg/logger"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/events/eventstest"
+	"github.com/crypto-bank/shared/replay"
+	"go.uber.org/zap"
+)
+
+// sent is how many notifications the golden payload of each event type causes
+var sent = map[string]int{
+	events.UserCreated:          1,
+	events.UserUpdated:          1,
+	events.UserDeleted:          1,
+	events.TransactionCompleted: 2,
+	events.TransactionFailed:    2,
+	events.ExchangeCompleted:    2,
+	events.ExchangeFailed:       2,
+	events.AccountCreated:       1,
+	events.WalletCreated:        1,
+}
+
+func TestHandlerDecodesGoldenEvents(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	for name, msg := range eventstest.Deliveries(t, routingKeys...) {
+		t.Run(name, func(t *testing.T) {
+			notificationService := service.NewNotificationService(logger.Log)
+			rebuild := replay.NewRebuild("notification", notificationService.Reset)
+			handle := newHandler(notificationService, rebuild)
+
+			if err := handle(context.Background(), msg); err != nil {
+				t.Fatalf("handler rejected the golden payload: %v", err)
+			}
+
+			notifications := notificationService.GetNotifications()
+			if len(notifications) != sent[msg.RoutingKey] {
+				t.Fatalf("sent %d notifications, want %d", len(notifications), sent[msg.RoutingKey])
+			}
+			for _, notification := range notifications {
+				if notification.UserID == "" {
+					t.Errorf("notification %s has no user", notification.ID)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,11 +12,101 @@ import (
 	"github.com/crypto-bank/notification-service/pkg/logger"
 	"github.com/crypto-bank/notification-service/pkg/metrics"
 	"github.com/crypto-bank/notification-service/pkg/tracing"
+	"github.com/crypto-bank/shared/authz"
+	"github.com/crypto-bank/shared/broker"
+	"github.com/crypto-bank/shared/dedup"
+	"github.com/crypto-bank/shared/events"
+	"github.com/crypto-bank/shared/replay"
 	"github.com/gofiber/fiber/v2"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+const (
+	queueName          = "notification.queue"
+	replayQueueName    = "notification.replay"
+	deadLetterExchange = "notification.dlx"
+	deadLetterQueue    = "notification.dlq"
+	replayDeadLetters  = "notification.replay.dlq"
+
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// routingKeys are the bank events the service notifies users about
+var routingKeys = []string{
+	events.UserCreated,
+	events.UserUpdated,
+	events.UserDeleted,
+	events.TransactionCompleted,
+	events.TransactionFailed,
+	events.ExchangeCompleted,
+	events.ExchangeFailed,
+	events.AccountCreated,
+	events.WalletCreated,
+}
+
+// declareTopology declares the work queue bound to bank.events and the replay
+// queue bound to bank.events.replay, each with its retry and dead-letter
+// queues. The broker client runs it again after every reconnect.
+func declareTopology(ch *amqp.Channel, consumers ...broker.ConsumerConfig) error {
+	sources := map[string]string{
+		queueName:       "bank.events",
+		replayQueueName: replay.Exchange,
+	}
+
+	for _, consumer := range consumers {
+		exchange := sources[consumer.Queue]
+
+		// Declare exchange
+		err := ch.ExchangeDeclare(
+			exchange, // name
+			"topic",  // type
+			true,     // durable
+			false,    // auto-deleted
+			false,    // internal
+			false,    // no-wait
+			nil,      // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+
+		// Declare queue
+		q, err := ch.QueueDeclare(
+			consumer.Queue, // name
+			true,           // durable
+			false,          // delete when unused
+			false,          // exclusive
+			false,          // no-wait
+			nil,            // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", consumer.Queue, err)
+		}
+
+		// Bind queue to exchange with routing keys
+		for _, key := range routingKeys {
+			err = ch.QueueBind(
+				q.Name,   // queue name
+				key,      // routing key
+				exchange, // exchange
+				false,
+				nil,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to bind queue %s to %s: %w", q.Name, key, err)
+			}
+		}
+
+		if err := broker.DeclareConsumerTopology(ch, consumer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
@@ -41,101 +132,64 @@ func main() {
 	// Initialize notification service
 	notificationService := service.NewNotificationService(logger.Log)
 
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(cfg.RabbitMQ.GetRabbitMQURL())
-	if err != nil {
-		logger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
-	}
-	defer conn.Close()
-
-	ch, err := conn.Channel()
-	if err != nil {
-		logger.Fatal("Failed to open channel", zap.Error(err))
-	}
-	defer ch.Close()
-
-	// Declare exchange
-	err = ch.ExchangeDeclare(
-		"bank.events", // name
-		"topic",       // type
-		true,          // durable
-		false,         // auto-deleted
-		false,         // internal
-		false,         // no-wait
-		nil,           // arguments
-	)
-	if err != nil {
-		logger.Fatal("Failed to declare exchange", zap.Error(err))
-	}
+	// Load the bank-service token verification keys for the admin endpoints
+	keySet := authz.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.Timeout)
+	verifier := authz.NewVerifier(keySet, cfg.Auth.Issuer)
+	stopKeyRefresh := make(chan struct{})
+	go keySet.Watch(cfg.Auth.RefreshInterval, stopKeyRefresh, logger.Log)
+	defer close(stopKeyRefresh)
+	requireAdmin := authz.RequireRole(verifier, logger.Log, authz.RoleAdmin)
 
-	// Declare queue
-	q, err := ch.QueueDeclare(
-		"notification.queue", // name
-		true,                 // durable
-		false,                // delete when unused
-		false,                // exclusive
-		false,                // no-wait
-		nil,                  // arguments
-	)
-	if err != nil {
-		logger.Fatal("Failed to declare queue", zap.Error(err))
+	consumer := broker.ConsumerConfig{
+		Queue:              queueName,
+		DeadLetterExchange: deadLetterExchange,
+		DeadLetterQueue:    deadLetterQueue,
+		RetryDelays:        cfg.Consumer.RetryDelays,
+		Prefetch:           cfg.Consumer.Prefetch,
+		Concurrency:        cfg.Consumer.Concurrency,
 	}
+	// Replayed events that keep failing are parked apart from live ones;
+	// replaying them again from the event store takes the place of a redrive
+	replayConsumer := consumer
+	replayConsumer.Queue = replayQueueName
+	replayConsumer.DeadLetterQueue = replayDeadLetters
 
-	// Bind queue to exchange with routing keys
-	routingKeys := []string{
-		"transaction.completed",
-		"exchange.completed",
-		"account.created",
-		"wallet.created",
-	}
+	// The notification history is rebuilt from replayed events on demand.
+	// Replayed events are recorded, never sent again.
+	rebuild := replay.NewRebuild("notifications", notificationService.Reset)
 
-	for _, key := range routingKeys {
-		err = ch.QueueBind(
-			q.Name,        // queue name
-			key,           // routing key
-			"bank.events", // exchange
-			false,
-			nil,
-		)
-		if err != nil {
-			logger.Fatal("Failed to bind queue", zap.Error(err), zap.String("routing_key", key))
-		}
-		logger.Info("Queue bound", zap.String("routing_key", key))
+	// Remember processed events to drop redeliveries
+	processedEvents, err := dedup.Open(dedup.Config{
+		Path:       cfg.Consumer.DedupFile,
+		TTL:        cfg.Consumer.DedupTTL,
+		MaxEntries: cfg.Consumer.DedupMaxEntries,
+	}, logger.Log)
+	if err != nil {
+		logger.Fatal("Failed to open dedup store", zap.Error(err))
 	}
+	defer processedEvents.Close()
 
-	// Start consuming messages
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
+	// Connect to RabbitMQ
+	rabbitMQClient, err := broker.Dial(broker.Config{
+		URL:  cfg.RabbitMQ.GetRabbitMQURL(),
+		Name: "notification-service",
+		Topology: func(ch *amqp.Channel) error {
+			return declareTopology(ch, consumer, replayConsumer)
+		},
+		ReconnectMinDelay: cfg.RabbitMQ.ReconnectMinDelay,
+		ReconnectMaxDelay: cfg.RabbitMQ.ReconnectMaxDelay,
+	}, logger.Log)
 	if err != nil {
-		logger.Fatal("Failed to register consumer", zap.Error(err))
+		logger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
 	}
+	defer rabbitMQClient.Close()
 
-	// Process messages in goroutine
-	go func() {
-		for msg := range msgs {
-			logger.Debug("Received message", zap.String("routing_key", msg.RoutingKey))
-
-			switch msg.RoutingKey {
-			case "transaction.created", "transaction.completed":
-				notificationService.ProcessTransactionEvent(msg.Body)
-			case "exchange.created", "exchange.completed":
-				notificationService.ProcessExchangeEvent(msg.Body)
-			case "account.created":
-				notificationService.ProcessAccountEvent(msg.Body)
-			case "wallet.created":
-				notificationService.ProcessWalletEvent(msg.Body)
-			default:
-				logger.Warn("Unknown routing key", zap.String("routing_key", msg.RoutingKey))
-			}
-		}
-	}()
+	// Start consuming messages
+	handle := newHandler(notificationService, rebuild)
+	// Replayed events are deduplicated by the rebuild instead: they carry the
+	// IDs of events processed before
+	rabbitMQClient.Consume(consumer, dedup.Wrap(queueName, processedEvents, handle))
+	rabbitMQClient.Consume(replayConsumer, handle)
 
 	logger.Info("Notification service started, waiting for messages...")
 
@@ -170,6 +224,97 @@ func main() {
 		})
 	})
 
+	// Dead-lettered messages can be inspected and sent back for another round
+	app.Get("/admin/v1/dead-letters", requireAdmin, func(c *fiber.Ctx) error {
+		limit := c.QueryInt("limit", defaultDeadLetterLimit)
+		if limit <= 0 || limit > maxDeadLetterLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   fmt.Sprintf("limit must be between 1 and %d", maxDeadLetterLimit),
+			})
+		}
+
+		letters, err := rabbitMQClient.DeadLetters(c.Context(), consumer, limit)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    letters,
+			"count":   len(letters),
+		})
+	})
+
+	app.Post("/admin/v1/dead-letters/redrive", requireAdmin, func(c *fiber.Ctx) error {
+		var req struct {
+			MessageIDs []string `json:"message_ids"`
+			Limit      int      `json:"limit"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+		}
+		if req.Limit == 0 {
+			req.Limit = defaultDeadLetterLimit
+		}
+		if req.Limit < 0 || req.Limit > maxDeadLetterLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   fmt.Sprintf("limit must be between 1 and %d", maxDeadLetterLimit),
+			})
+		}
+
+		redriven, err := rabbitMQClient.Redrive(c.Context(), consumer, req.MessageIDs, req.Limit)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success":  false,
+				"error":    err.Error(),
+				"redriven": redriven,
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success":  true,
+			"redriven": redriven,
+		})
+	})
+
+	// The notification history is rebuilt by starting a rebuild, replaying the
+	// events in the bank service and finishing once the replay queue is drained
+	app.Get("/admin/v1/rebuild", requireAdmin, func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    rebuild.Status(),
+		})
+	})
+
+	app.Post("/admin/v1/rebuild", requireAdmin, func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    rebuild.Start(),
+		})
+	})
+
+	app.Post("/admin/v1/rebuild/finish", requireAdmin, func(c *fiber.Ctx) error {
+		status, err := rebuild.Finish()
+		if errors.Is(err, replay.ErrNotRebuilding) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    status,
+		})
+	})
+
 	// Start HTTP server
 	go func() {
 		logger.Info("HTTP server started", zap.String("port", cfg.Server.Port))
@@ -3,12 +3,17 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Server   ServerConfig
 	RabbitMQ RabbitMQConfig
+	Consumer ConsumerConfig
 	Zipkin   ZipkinConfig
+	Auth     AuthConfig
 }
 
 type ServerConfig struct {
@@ -17,16 +22,40 @@ type ServerConfig struct {
 }
 
 type RabbitMQConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
+	Host              string
+	Port              string
+	User              string
+	Password          string
+	ReconnectMinDelay time.Duration
+	ReconnectMaxDelay time.Duration
+}
+
+// ConsumerConfig configures how bank events are consumed. A failed message is
+// retried after each of the retry delays and then dead-lettered. Processed
+// event IDs are remembered for DedupTTL to drop redeliveries; without a dedup
+// file they are forgotten on restart.
+type ConsumerConfig struct {
+	Prefetch        int
+	Concurrency     int
+	RetryDelays     []time.Duration
+	DedupFile       string
+	DedupTTL        time.Duration
+	DedupMaxEntries int
 }
 
 type ZipkinConfig struct {
 	Endpoint string
 }
 
+// AuthConfig locates the bank-service keys verifying the access tokens of
+// operators calling the admin endpoints
+type AuthConfig struct {
+	JWKSURL         string
+	Issuer          string
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
@@ -35,14 +64,30 @@ func LoadConfig() *Config {
 			Environment: getEnv("ENVIRONMENT", "development"),
 		},
 		RabbitMQ: RabbitMQConfig{
-			Host:     getEnv("RABBITMQ_HOST", "localhost"),
-			Port:     getEnv("RABBITMQ_PORT", "5672"),
-			User:     getEnv("RABBITMQ_USER", "guest"),
-			Password: getEnv("RABBITMQ_PASS", "guest"),
+			Host:              getEnv("RABBITMQ_HOST", "localhost"),
+			Port:              getEnv("RABBITMQ_PORT", "5672"),
+			User:              getEnv("RABBITMQ_USER", "guest"),
+			Password:          getEnv("RABBITMQ_PASS", "guest"),
+			ReconnectMinDelay: getEnvDuration("RABBITMQ_RECONNECT_MIN_DELAY", time.Second),
+			ReconnectMaxDelay: getEnvDuration("RABBITMQ_RECONNECT_MAX_DELAY", 30*time.Second),
+		},
+		Consumer: ConsumerConfig{
+			Prefetch:        getEnvInt("CONSUMER_PREFETCH", 20),
+			Concurrency:     getEnvInt("CONSUMER_CONCURRENCY", 4),
+			RetryDelays:     getEnvDurationList("CONSUMER_RETRY_DELAYS", []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}),
+			DedupFile:       getEnv("CONSUMER_DEDUP_FILE", ""),
+			DedupTTL:        getEnvDuration("CONSUMER_DEDUP_TTL", 24*time.Hour),
+			DedupMaxEntries: getEnvInt("CONSUMER_DEDUP_MAX_ENTRIES", 200000),
 		},
 		Zipkin: ZipkinConfig{
 			Endpoint: getEnv("ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
 		},
+		Auth: AuthConfig{
+			JWKSURL:         getEnv("AUTH_JWKS_URL", "http://localhost:8080/.well-known/jwks.json"),
+			Issuer:          getEnv("AUTH_ISSUER", "crypto-bank"),
+			RefreshInterval: getEnvDuration("AUTH_JWKS_REFRESH_INTERVAL", time.Minute),
+			Timeout:         getEnvDuration("AUTH_JWKS_TIMEOUT", 5*time.Second),
+		},
 	}
 }
 
@@ -62,3 +107,37 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDurationList(key string, defaultValue []time.Duration) []time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	items := []time.Duration{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(item)
+		if err != nil {
+			return defaultValue
+		}
+		items = append(items, duration)
+	}
+	return items
+}
@@ -1,10 +1,11 @@
 package service
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/crypto-bank/shared/events"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -37,6 +38,7 @@ func init() {
 	NotificationsSent.WithLabelValues("exchange", "push").Add(0)
 	NotificationsSent.WithLabelValues("account", "email").Add(0)
 	NotificationsSent.WithLabelValues("wallet", "email").Add(0)
+	NotificationsSent.WithLabelValues("user", "email").Add(0)
 }
 
 type NotificationService struct {
@@ -62,20 +64,35 @@ func NewNotificationService(logger *zap.Logger) *NotificationService {
 	}
 }
 
-// ProcessTransactionEvent processes transaction events and sends notifications
-func (s *NotificationService) ProcessTransactionEvent(body []byte) error {
-	var event struct {
-		TransactionID string  `json:"transaction_id"`
-		UserID        string  `json:"user_id"`
-		Type          string  `json:"type"`
-		Amount        float64 `json:"amount"`
-		Currency      string  `json:"currency"`
-		Status        string  `json:"status"`
+// Reset forgets the notifications sent before they are rebuilt from replayed
+// events
+func (s *NotificationService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications = make([]Notification, 0)
+	s.logger.Info("Notifications reset")
+}
+
+// ProcessTransactionEvent processes transaction events and sends notifications.
+// Users hear about completed and failed transactions; exchanges are announced
+// by their own events.
+func (s *NotificationService) ProcessTransactionEvent(event *events.TransactionEvent, replayed bool) {
+	if event.Type == "EXCHANGE" {
+		return
 	}
 
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal transaction event", zap.Error(err))
-		return err
+	if event.Status == "FAILED" {
+		title := "Transaction Failed"
+		message := fmt.Sprintf("Your %s of %.2f %s could not be completed: %s",
+			strings.ToLower(event.Type), event.Amount, event.Currency, event.Reason)
+
+		s.sendNotification(event.UserID, "transaction", title, message, "email", replayed)
+		s.sendNotification(event.UserID, "transaction", title, message, "push", replayed)
+		return
+	}
+	if event.Status != "COMPLETED" {
+		return
 	}
 
 	// Create notification message
@@ -90,92 +107,80 @@ func (s *NotificationService) ProcessTransactionEvent(body []byte) error {
 	case "WITHDRAW":
 		title = "Withdrawal Processed"
 		message = fmt.Sprintf("Withdrawal of %.2f %s has been processed", event.Amount, event.Currency)
-	case "EXCHANGE":
-		title = "Exchange Completed"
-		message = fmt.Sprintf("Exchange transaction of %.2f %s has been completed", event.Amount, event.Currency)
 	default:
 		title = "Transaction Update"
 		message = fmt.Sprintf("Transaction of %.2f %s status: %s", event.Amount, event.Currency, event.Status)
 	}
 
 	// Send notifications via different channels
-	s.sendNotification(event.UserID, "transaction", title, message, "email")
-	s.sendNotification(event.UserID, "transaction", title, message, "push")
-
-	return nil
+	s.sendNotification(event.UserID, "transaction", title, message, "email", replayed)
+	s.sendNotification(event.UserID, "transaction", title, message, "push", replayed)
 }
 
-// ProcessExchangeEvent processes exchange events and sends notifications
-func (s *NotificationService) ProcessExchangeEvent(body []byte) error {
-	var event struct {
-		ExchangeID   string  `json:"exchange_id"`
-		UserID       string  `json:"user_id"`
-		FromCurrency string  `json:"from_currency"`
-		ToCurrency   string  `json:"to_currency"`
-		FromAmount   float64 `json:"from_amount"`
-		ToAmount     float64 `json:"to_amount"`
-		Status       string  `json:"status"`
+// ProcessExchangeEvent processes completed and failed exchanges and sends
+// notifications
+func (s *NotificationService) ProcessExchangeEvent(event *events.ExchangeEvent, replayed bool) {
+	if event.Status == "FAILED" {
+		title := "Exchange Failed"
+		message := fmt.Sprintf("Your exchange of %.6f %s to %s could not be completed: %s",
+			event.FromAmount, event.FromCurrency, event.ToCurrency, event.Reason)
+
+		s.sendNotification(event.UserID, "exchange", title, message, "email", replayed)
+		s.sendNotification(event.UserID, "exchange", title, message, "push", replayed)
+		return
 	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal exchange event", zap.Error(err))
-		return err
+	if event.Status != "COMPLETED" {
+		return
 	}
 
 	title := "Exchange Completed"
 	message := fmt.Sprintf("Successfully exchanged %.6f %s to %.6f %s",
 		event.FromAmount, event.FromCurrency, event.ToAmount, event.ToCurrency)
 
-	s.sendNotification(event.UserID, "exchange", title, message, "email")
-	s.sendNotification(event.UserID, "exchange", title, message, "push")
-
-	return nil
+	s.sendNotification(event.UserID, "exchange", title, message, "email", replayed)
+	s.sendNotification(event.UserID, "exchange", title, message, "push", replayed)
 }
 
 // ProcessAccountEvent processes account creation events
-func (s *NotificationService) ProcessAccountEvent(body []byte) error {
-	var event struct {
-		AccountID string `json:"account_id"`
-		UserID    string `json:"user_id"`
-		Currency  string `json:"currency"`
-	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal account event", zap.Error(err))
-		return err
-	}
-
+func (s *NotificationService) ProcessAccountEvent(event *events.AccountEvent, replayed bool) {
 	title := "New Account Created"
 	message := fmt.Sprintf("Your new %s account has been created successfully", event.Currency)
 
-	s.sendNotification(event.UserID, "account", title, message, "email")
-
-	return nil
+	s.sendNotification(event.UserID, "account", title, message, "email", replayed)
 }
 
 // ProcessWalletEvent processes wallet creation events
-func (s *NotificationService) ProcessWalletEvent(body []byte) error {
-	var event struct {
-		WalletID   string `json:"wallet_id"`
-		UserID     string `json:"user_id"`
-		CryptoType string `json:"crypto_type"`
-	}
-
-	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("Failed to unmarshal wallet event", zap.Error(err))
-		return err
-	}
-
+func (s *NotificationService) ProcessWalletEvent(event *events.WalletEvent, replayed bool) {
 	title := "New Crypto Wallet Created"
 	message := fmt.Sprintf("Your new %s wallet has been created successfully", event.CryptoType)
 
-	s.sendNotification(event.UserID, "wallet", title, message, "email")
+	s.sendNotification(event.UserID, "wallet", title, message, "email", replayed)
+}
+
+// ProcessUserEvent welcomes new users, confirms profile changes and says
+// goodbye to deleted users
+func (s *NotificationService) ProcessUserEvent(eventType string, event *events.UserEvent, replayed bool) {
+	var title, message string
+	switch eventType {
+	case events.UserCreated:
+		title = "Welcome to Crypto Bank"
+		message = "Hello, your account has been created"
+	case events.UserUpdated:
+		title = "Profile Updated"
+		message = fmt.Sprintf("The following details of your profile were changed: %s", strings.Join(event.Changed, ", "))
+	case events.UserDeleted:
+		title = "Account Closed"
+		message = "Goodbye, your account has been closed"
+	default:
+		return
+	}
 
-	return nil
+	s.sendNotification(event.UserID, "user", title, message, "email", replayed)
 }
 
-// sendNotification simulates sending a notification
-func (s *NotificationService) sendNotification(userID, notificationType, title, message, channel string) {
+// sendNotification simulates sending a notification. Notifications of
+// replayed events were sent before; they are only recorded again.
+func (s *NotificationService) sendNotification(userID, notificationType, title, message, channel string, replayed bool) {
 	notification := Notification{
 		ID:        fmt.Sprintf("%s-%d", notificationType, len(s.notifications)+1),
 		Type:      notificationType,
@@ -190,6 +195,15 @@ func (s *NotificationService) sendNotification(userID, notificationType, title,
 	s.notifications = append(s.notifications, notification)
 	s.mu.Unlock()
 
+	if replayed {
+		s.logger.Debug("Notification restored",
+			zap.String("user_id", userID),
+			zap.String("type", notificationType),
+			zap.String("channel", channel),
+		)
+		return
+	}
+
 	// In production, this would send actual emails, push notifications, SMS, etc.
 	s.logger.Info("Notification sent",
 		zap.String("user_id", userID),
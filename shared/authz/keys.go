@@ -0,0 +1,130 @@
+package authz
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// jwk is the part of a bank-service JSON Web Key a verifier needs
+type jwk struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	KeyID   string `json:"kid"`
+}
+
+// KeySet caches the token verification keys published by bank-service
+type KeySet struct {
+	url        string
+	httpClient *http.Client
+	timeout    time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+func NewKeySet(url string, timeout time.Duration) *KeySet {
+	return &KeySet{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+		timeout:    timeout,
+		keys:       make(map[string]ed25519.PublicKey),
+	}
+}
+
+// Refresh fetches the key set again. Keys rotated out by bank-service disappear
+// from the set, so tokens they signed stop verifying.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key set returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	s.set(body.Keys)
+	return nil
+}
+
+// Watch refreshes the key set right away and then every interval until stop
+// is closed. Failed refreshes keep the keys loaded before.
+func (s *KeySet) Watch(interval time.Duration, stop <-chan struct{}, logger *zap.Logger) {
+	refresh := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+
+		if err := s.Refresh(ctx); err != nil {
+			logger.Warn("Failed to load token verification keys", zap.Error(err))
+			return
+		}
+		logger.Debug("Token verification keys loaded", zap.Int("count", s.Len()))
+	}
+
+	refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Len returns the number of cached keys
+func (s *KeySet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+func (s *KeySet) set(set []jwk) {
+	keys := make(map[string]ed25519.PublicKey, len(set))
+	for _, key := range set {
+		if key.KeyType != "OKP" || key.Curve != "Ed25519" || key.KeyID == "" {
+			continue
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			continue
+		}
+		keys[key.KeyID] = ed25519.PublicKey(raw)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func (s *KeySet) key(kid string) (ed25519.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const claimsLocal = "authz.claims"
+
+// RequireRole returns a Fiber middleware that only lets requests with a valid
+// bank-service access token of one of the roles through
+func RequireRole(verifier *Verifier, logger *zap.Logger, roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+		token = strings.TrimSpace(token)
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return deny(c, fiber.StatusUnauthorized, "missing bearer token")
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			if errors.Is(err, ErrExpiredToken) {
+				return deny(c, fiber.StatusUnauthorized, "token has expired")
+			}
+			return deny(c, fiber.StatusUnauthorized, "invalid token")
+		}
+
+		if !claims.HasRole(roles...) {
+			logger.Warn("Permission denied",
+				zap.String("path", c.Path()),
+				zap.String("user_id", claims.Subject),
+				zap.String("role", claims.Role),
+			)
+			return deny(c, fiber.StatusForbidden, "insufficient permissions")
+		}
+
+		c.Locals(claimsLocal, claims)
+		return c.Next()
+	}
+}
+
+// Caller returns the verified token holder of a request RequireRole let through
+func Caller(c *fiber.Ctx) (*Claims, bool) {
+	claims, ok := c.Locals(claimsLocal).(*Claims)
+	return claims, ok
+}
+
+func deny(c *fiber.Ctx, status int, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   message,
+	})
+}
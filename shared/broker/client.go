@@ -0,0 +1,277 @@
+// Package broker is the RabbitMQ client shared by the services. A client keeps
+// one connection alive: when the broker goes away it reconnects with backoff,
+// declares the topology of the service again and resumes its consumers.
+// Publishers share a pool of confirm mode channels and learn for every message
+// whether the broker took responsibility for it.
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrClosed is returned once the client has been closed
+	ErrClosed = errors.New("broker client is closed")
+	// ErrNacked is returned when the broker negatively acknowledges a message
+	ErrNacked = errors.New("broker did not confirm the message")
+	// ErrUnroutable is returned for mandatory messages no queue is bound for
+	ErrUnroutable = errors.New("message was not routed to any queue")
+)
+
+// Topology declares the exchanges, queues and bindings a service relies on. It
+// runs on every connect, so it must be idempotent.
+type Topology func(ch *amqp.Channel) error
+
+type Config struct {
+	URL string
+	// Name identifies the connection in the management UI and in metrics
+	Name     string
+	Topology Topology
+	// PublisherChannels is the number of confirm mode channels publishers share
+	PublisherChannels int
+	// ReconnectMinDelay doubles after every failed attempt up to ReconnectMaxDelay
+	ReconnectMinDelay time.Duration
+	ReconnectMaxDelay time.Duration
+}
+
+type Client struct {
+	cfg Config
+	log *zap.Logger
+
+	mu     sync.Mutex
+	conn   *amqp.Connection
+	lost   chan *amqp.Error
+	pool   chan *publisher
+	ready  chan struct{} // closed while connected
+	closed bool
+
+	// openPublisher opens the channels of the publisher pool
+	openPublisher func(conn *amqp.Connection) (*publisher, error)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Dial connects to the broker and declares the topology. The first connect
+// must succeed; later connection losses are repaired in the background.
+func Dial(cfg Config, log *zap.Logger) (*Client, error) {
+	if cfg.PublisherChannels <= 0 {
+		cfg.PublisherChannels = 1
+	}
+	if cfg.ReconnectMinDelay <= 0 {
+		cfg.ReconnectMinDelay = time.Second
+	}
+	if cfg.ReconnectMaxDelay < cfg.ReconnectMinDelay {
+		cfg.ReconnectMaxDelay = cfg.ReconnectMinDelay
+	}
+
+	c := &Client{
+		cfg:           cfg,
+		log:           log.With(zap.String("connection", cfg.Name)),
+		openPublisher: newPublisher,
+		ready:         make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	if err := c.connect(); err != nil {
+		connectionUp.WithLabelValues(cfg.Name).Set(0)
+		return nil, err
+	}
+
+	c.wg.Add(1)
+	go c.supervise()
+
+	return c, nil
+}
+
+// connect opens a connection, declares the topology and fills a fresh
+// publisher pool before marking the client ready
+func (c *Client) connect() error {
+	conn, err := amqp.DialConfig(c.cfg.URL, amqp.Config{
+		Heartbeat:  10 * time.Second,
+		Locale:     "en_US",
+		Properties: amqp.Table{"connection_name": c.cfg.Name},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	if c.cfg.Topology != nil {
+		ch, err := conn.Channel()
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("failed to open channel: %w", err)
+		}
+		err = c.cfg.Topology(ch)
+		ch.Close()
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("failed to declare topology: %w", err)
+		}
+	}
+
+	pool := make(chan *publisher, c.cfg.PublisherChannels)
+	for i := 0; i < c.cfg.PublisherChannels; i++ {
+		p, err := c.openPublisher(conn)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		pool <- p
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return ErrClosed
+	}
+	c.conn = conn
+	c.lost = conn.NotifyClose(make(chan *amqp.Error, 1))
+	c.pool = pool
+	close(c.ready)
+	c.mu.Unlock()
+
+	connectionUp.WithLabelValues(c.cfg.Name).Set(1)
+	return nil
+}
+
+// supervise waits for the connection to drop and reconnects until Close
+func (c *Client) supervise() {
+	defer c.wg.Done()
+
+	for {
+		c.mu.Lock()
+		lost := c.lost
+		c.mu.Unlock()
+
+		var reason *amqp.Error
+		select {
+		case reason = <-lost:
+		case <-c.done:
+			return
+		}
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		c.mu.Lock()
+		c.ready = make(chan struct{})
+		c.mu.Unlock()
+
+		connectionUp.WithLabelValues(c.cfg.Name).Set(0)
+		disconnectsTotal.WithLabelValues(c.cfg.Name).Inc()
+		c.log.Warn("Lost connection to RabbitMQ, reconnecting", zap.Any("reason", reason))
+
+		if !c.reconnect() {
+			return
+		}
+	}
+}
+
+// reconnect retries with exponential backoff. It gives up only when the
+// client is closed.
+func (c *Client) reconnect() bool {
+	delay := c.cfg.ReconnectMinDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(delay):
+		case <-c.done:
+			return false
+		}
+
+		err := c.connect()
+		if err == nil {
+			reconnectAttemptsTotal.WithLabelValues(c.cfg.Name, "success").Inc()
+			c.log.Info("Reconnected to RabbitMQ", zap.Int("attempt", attempt))
+			return true
+		}
+
+		reconnectAttemptsTotal.WithLabelValues(c.cfg.Name, "failure").Inc()
+		c.log.Warn("Failed to reconnect to RabbitMQ",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		delay *= 2
+		if delay > c.cfg.ReconnectMaxDelay {
+			delay = c.cfg.ReconnectMaxDelay
+		}
+	}
+}
+
+// current waits until the client is connected and returns the connection
+// with its publisher pool
+func (c *Client) current(ctx context.Context) (*amqp.Connection, chan *publisher, error) {
+	for {
+		c.mu.Lock()
+		ready, closed := c.ready, c.closed
+		c.mu.Unlock()
+
+		if closed {
+			return nil, nil, ErrClosed
+		}
+
+		select {
+		case <-ready:
+			// The connection may have dropped again while waiting
+			c.mu.Lock()
+			if ready == c.ready {
+				conn, pool := c.conn, c.pool
+				c.mu.Unlock()
+				return conn, pool, nil
+			}
+			c.mu.Unlock()
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-c.done:
+			return nil, nil, ErrClosed
+		}
+	}
+}
+
+// Connected reports whether the connection is currently up
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.ready:
+		return !c.closed
+	default:
+		return false
+	}
+}
+
+// Close stops reconnecting, ends the consumers and closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	conn := c.conn
+	c.mu.Unlock()
+
+	err := conn.Close()
+	if errors.Is(err, amqp.ErrClosed) {
+		err = nil
+	}
+
+	c.wg.Wait()
+	connectionUp.WithLabelValues(c.cfg.Name).Set(0)
+	return err
+}
@@ -0,0 +1,318 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// Headers the consumer keeps on retried and dead-lettered messages
+const (
+	HeaderRetryCount         = "x-retry-count"
+	HeaderOriginalRoutingKey = "x-original-routing-key"
+	HeaderOriginalExchange   = "x-original-exchange"
+	HeaderLastError          = "x-last-error"
+	HeaderDeadLetteredAt     = "x-dead-lettered-at"
+)
+
+// Handler processes one delivery. The context carries the consumer span, a
+// child of the trace the publisher injected. The message is acknowledged when
+// the handler returns nil; on an error it is retried and finally dead-lettered.
+type Handler func(ctx context.Context, msg amqp.Delivery) error
+
+// ConsumerConfig describes a work queue and how failed messages are treated.
+// The queue itself is declared by the service topology, the retry and
+// dead-letter queues by DeclareConsumerTopology.
+type ConsumerConfig struct {
+	Queue string
+	// DeadLetterExchange receives messages that exhausted their retries
+	DeadLetterExchange string
+	DeadLetterQueue    string
+	// RetryDelays are the waits before each retry; a message is dead-lettered
+	// once it failed after all of them
+	RetryDelays []time.Duration
+	// Prefetch limits the unacknowledged deliveries; zero means Concurrency
+	Prefetch    int
+	Concurrency int
+}
+
+func (cfg ConsumerConfig) retryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", cfg.Queue, delay)
+}
+
+// DeclareConsumerTopology declares the dead-letter exchange and queue and one
+// retry queue per delay. A retry queue holds messages for its delay and then
+// dead-letters them back onto the work queue through the default exchange.
+// Retry queues are named after their delay, so changing the delays declares
+// new queues instead of clashing with the arguments of existing ones.
+func DeclareConsumerTopology(ch *amqp.Channel, cfg ConsumerConfig) error {
+	err := ch.ExchangeDeclare(
+		cfg.DeadLetterExchange, // name
+		"direct",               // type
+		true,                   // durable
+		false,                  // auto-deleted
+		false,                  // internal
+		false,                  // no-wait
+		nil,                    // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(cfg.DeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+	if err := ch.QueueBind(cfg.DeadLetterQueue, cfg.Queue, cfg.DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	for _, delay := range cfg.RetryDelays {
+		_, err := ch.QueueDeclare(cfg.retryQueue(delay), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": cfg.Queue,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue for %s: %w", delay, err)
+		}
+	}
+
+	return nil
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error retrying cannot fix, so the message is
+// dead-lettered at once
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent also treats payloads that cannot be decoded as permanent
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &permanent) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// Consume runs Concurrency workers that hand deliveries from the queue to the
+// handler until the client is closed. The subscription is renewed whenever the
+// channel or the connection is lost; unacknowledged deliveries then go back to
+// the queue and are delivered again.
+func (c *Client) Consume(cfg ConsumerConfig, handler Handler) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Prefetch <= 0 {
+		cfg.Prefetch = cfg.Concurrency
+	}
+
+	c.wg.Add(1)
+	go c.consume(cfg, handler)
+}
+
+func (c *Client) consume(cfg ConsumerConfig, handler Handler) {
+	defer c.wg.Done()
+
+	log := c.log.With(zap.String("queue", cfg.Queue))
+	for {
+		conn, _, err := c.current(context.Background())
+		if err != nil {
+			return
+		}
+
+		ch, deliveries, err := subscribe(conn, cfg)
+		if err != nil {
+			log.Warn("Failed to subscribe, retrying", zap.Error(err))
+			select {
+			case <-time.After(c.cfg.ReconnectMinDelay):
+				continue
+			case <-c.done:
+				return
+			}
+		}
+
+		log.Info("Consuming messages", zap.Int("concurrency", cfg.Concurrency))
+		var workers sync.WaitGroup
+		for i := 0; i < cfg.Concurrency; i++ {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for msg := range deliveries {
+					c.handle(log, cfg, handler, msg)
+				}
+			}()
+		}
+		workers.Wait()
+		ch.Close()
+
+		select {
+		case <-c.done:
+			return
+		default:
+			log.Warn("Subscription ended, resubscribing")
+		}
+	}
+}
+
+func subscribe(conn *amqp.Connection, cfg ConsumerConfig) (*amqp.Channel, <-chan amqp.Delivery, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open consumer channel: %w", err)
+	}
+
+	if err := ch.Qos(cfg.Prefetch, 0, false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	deliveries, err := ch.Consume(
+		cfg.Queue, // queue
+		"",        // consumer
+		false,     // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	return ch, deliveries, nil
+}
+
+// handle runs the handler and settles the delivery. A failed message is
+// republished to the next retry queue or the dead-letter exchange before the
+// original is acknowledged; if that publish fails the original is requeued, so
+// a message is never dropped.
+func (c *Client) handle(log *zap.Logger, cfg ConsumerConfig, handler Handler, msg amqp.Delivery) {
+	attempt := retryCount(msg.Headers)
+	restoreOrigin(&msg)
+
+	ctx, span := startProcessSpan(cfg.Queue, msg)
+
+	start := time.Now()
+	err := handler(ctx, msg)
+	handlerDuration.WithLabelValues(cfg.Queue).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		c.settle(log, cfg, msg, "acked", msg.Ack(false))
+		endSpan(span, "acked", nil)
+		return
+	}
+
+	target, routingKey, outcome, headers := route(cfg, msg, attempt, err, time.Now())
+
+	log.Warn("Failed to handle message",
+		zap.String("routing_key", msg.RoutingKey),
+		zap.String("message_id", msg.MessageId),
+		zap.Int("attempt", attempt+1),
+		zap.String("outcome", outcome),
+		zap.Error(err),
+	)
+
+	// The republished message continues the trace, so retries show up under
+	// the attempt that failed
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	pubErr := c.Publish(ctx, target, routingKey, false, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		Body:            msg.Body,
+	})
+	if pubErr != nil {
+		log.Error("Failed to park failed message, requeueing it",
+			zap.String("message_id", msg.MessageId),
+			zap.Error(pubErr),
+		)
+		c.settle(log, cfg, msg, "requeued", msg.Nack(false, true))
+		endSpan(span, "requeued", err)
+		return
+	}
+
+	c.settle(log, cfg, msg, outcome, msg.Ack(false))
+	endSpan(span, outcome, err)
+}
+
+// restoreOrigin gives a retried message back the routing key and exchange it
+// was first published with. Retries come back through the default exchange
+// under the queue name; the handler sees them as they were first published.
+func restoreOrigin(msg *amqp.Delivery) {
+	if key, ok := msg.Headers[HeaderOriginalRoutingKey].(string); ok {
+		msg.RoutingKey = key
+	}
+	if exchange, ok := msg.Headers[HeaderOriginalExchange].(string); ok {
+		msg.Exchange = exchange
+	}
+}
+
+// route picks where a message whose attempt failed is republished: the retry
+// queue of the next delay, or the dead-letter exchange once the delays are used
+// up or the error is permanent. It returns the headers to republish it with.
+func route(cfg ConsumerConfig, msg amqp.Delivery, attempt int, err error, now time.Time) (target, routingKey, outcome string, headers amqp.Table) {
+	headers = failureHeaders(msg, err)
+	if attempt < len(cfg.RetryDelays) && !isPermanent(err) {
+		headers[HeaderRetryCount] = int32(attempt + 1)
+		return "", cfg.retryQueue(cfg.RetryDelays[attempt]), "retried", headers
+	}
+
+	headers[HeaderDeadLetteredAt] = now.UTC().Format(time.RFC3339)
+	return cfg.DeadLetterExchange, cfg.Queue, "dead_lettered", headers
+}
+
+func (c *Client) settle(log *zap.Logger, cfg ConsumerConfig, msg amqp.Delivery, outcome string, err error) {
+	if err != nil {
+		// The channel is gone and the broker delivers the message again
+		log.Warn("Failed to settle message", zap.String("message_id", msg.MessageId), zap.Error(err))
+		return
+	}
+	consumedTotal.WithLabelValues(cfg.Queue, outcome).Inc()
+}
+
+// failureHeaders copies the headers of a failed message and records where it
+// was first published and why it failed
+func failureHeaders(msg amqp.Delivery, err error) amqp.Table {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderOriginalRoutingKey] = msg.RoutingKey
+	headers[HeaderOriginalExchange] = msg.Exchange
+	headers[HeaderLastError] = err.Error()
+	return headers
+}
+
+func retryCount(headers amqp.Table) int {
+	switch count := headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	case int:
+		return count
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var testConsumer = ConsumerConfig{
+	Queue:              "notifications.queue",
+	DeadLetterExchange: "notifications.dlx",
+	DeadLetterQueue:    "notifications.dlq",
+	RetryDelays:        []time.Duration{time.Second, 10 * time.Second, time.Minute},
+}
+
+func TestRoute(t *testing.T) {
+	now := time.Date(2025, 3, 14, 8, 0, 0, 0, time.UTC)
+	transient := errors.New("database unavailable")
+	var syntaxErr *json.SyntaxError
+	badJSON := json.Unmarshal([]byte("{"), &struct{}{})
+	if !errors.As(badJSON, &syntaxErr) {
+		t.Fatalf("expected a syntax error, got %v", badJSON)
+	}
+	badType := json.Unmarshal([]byte(`{"amount":"ten"}`), &struct {
+		Amount float64 `json:"amount"`
+	}{})
+
+	tests := []struct {
+		name           string
+		cfg            ConsumerConfig
+		attempt        int
+		err            error
+		wantTarget     string
+		wantRoutingKey string
+		wantOutcome    string
+		wantRetryCount int32
+	}{
+		{
+			name: "first failure waits the first delay", cfg: testConsumer, attempt: 0, err: transient,
+			wantRoutingKey: "notifications.queue.retry.1s", wantOutcome: "retried", wantRetryCount: 1,
+		},
+		{
+			name: "second failure waits the second delay", cfg: testConsumer, attempt: 1, err: transient,
+			wantRoutingKey: "notifications.queue.retry.10s", wantOutcome: "retried", wantRetryCount: 2,
+		},
+		{
+			name: "last delay", cfg: testConsumer, attempt: 2, err: transient,
+			wantRoutingKey: "notifications.queue.retry.1m0s", wantOutcome: "retried", wantRetryCount: 3,
+		},
+		{
+			name: "delays used up", cfg: testConsumer, attempt: 3, err: transient,
+			wantTarget: "notifications.dlx", wantRoutingKey: "notifications.queue", wantOutcome: "dead_lettered",
+		},
+		{
+			name: "permanent error", cfg: testConsumer, attempt: 0, err: Permanent(transient),
+			wantTarget: "notifications.dlx", wantRoutingKey: "notifications.queue", wantOutcome: "dead_lettered",
+		},
+		{
+			name: "wrapped permanent error", cfg: testConsumer, attempt: 0, err: fmt.Errorf("handler: %w", Permanent(transient)),
+			wantTarget: "notifications.dlx", wantRoutingKey: "notifications.queue", wantOutcome: "dead_lettered",
+		},
+		{
+			name: "malformed payload", cfg: testConsumer, attempt: 0, err: fmt.Errorf("decode: %w", badJSON),
+			wantTarget: "notifications.dlx", wantRoutingKey: "notifications.queue", wantOutcome: "dead_lettered",
+		},
+		{
+			name: "payload of the wrong type", cfg: testConsumer, attempt: 0, err: badType,
+			wantTarget: "notifications.dlx", wantRoutingKey: "notifications.queue", wantOutcome: "dead_lettered",
+		},
+		{
+			name: "no retry delays", cfg: ConsumerConfig{Queue: "q", DeadLetterExchange: "q.dlx"}, attempt: 0, err: transient,
+			wantTarget: "q.dlx", wantRoutingKey: "q", wantOutcome: "dead_lettered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := amqp.Delivery{
+				Exchange:   "bank.events",
+				RoutingKey: "transaction.completed",
+				Headers:    amqp.Table{"traceparent": "00-abc-def-01"},
+			}
+
+			target, routingKey, outcome, headers := route(tt.cfg, msg, tt.attempt, tt.err, now)
+			if target != tt.wantTarget || routingKey != tt.wantRoutingKey || outcome != tt.wantOutcome {
+				t.Errorf("route = (%q, %q, %q), want (%q, %q, %q)",
+					target, routingKey, outcome, tt.wantTarget, tt.wantRoutingKey, tt.wantOutcome)
+			}
+
+			if headers[HeaderOriginalRoutingKey] != "transaction.completed" || headers[HeaderOriginalExchange] != "bank.events" {
+				t.Errorf("origin not recorded: %v", headers)
+			}
+			if headers[HeaderLastError] != tt.err.Error() {
+				t.Errorf("last error = %v, want %q", headers[HeaderLastError], tt.err.Error())
+			}
+			if headers["traceparent"] != "00-abc-def-01" {
+				t.Error("headers of the message were dropped")
+			}
+			if _, ok := msg.Headers[HeaderLastError]; ok {
+				t.Error("the headers of the delivery were changed")
+			}
+
+			if tt.wantOutcome == "retried" {
+				if got := headers[HeaderRetryCount]; got != tt.wantRetryCount {
+					t.Errorf("retry count = %v, want %d", got, tt.wantRetryCount)
+				}
+				if _, ok := headers[HeaderDeadLetteredAt]; ok {
+					t.Error("retried message marked dead-lettered")
+				}
+			} else if got := headers[HeaderDeadLetteredAt]; got != "2025-03-14T08:00:00Z" {
+				t.Errorf("dead-lettered at = %v", got)
+			}
+		})
+	}
+}
+
+// TestRouteLifecycle follows a message that keeps failing through the retry
+// queues, as the broker delivers it back, until it is dead-lettered
+func TestRouteLifecycle(t *testing.T) {
+	msg := amqp.Delivery{Exchange: "bank.events", RoutingKey: "transaction.completed"}
+	failure := errors.New("downstream unavailable")
+
+	var hops []string
+	for attempt := 0; ; attempt++ {
+		if got := retryCount(msg.Headers); got != attempt {
+			t.Fatalf("delivery %d counts %d retries", attempt+1, got)
+		}
+		restoreOrigin(&msg)
+		if msg.RoutingKey != "transaction.completed" || msg.Exchange != "bank.events" {
+			t.Fatalf("delivery %d reached the handler as %s on %q", attempt+1, msg.RoutingKey, msg.Exchange)
+		}
+
+		target, routingKey, outcome, headers := route(testConsumer, msg, attempt, failure, time.Now())
+		hops = append(hops, routingKey)
+		if outcome == "dead_lettered" {
+			if target != testConsumer.DeadLetterExchange {
+				t.Errorf("dead-lettered to %q", target)
+			}
+			if dl := newDeadLetter(amqp.Delivery{Exchange: target, RoutingKey: routingKey, Headers: headers}); dl.RoutingKey != "transaction.completed" || dl.Attempts != 4 {
+				t.Errorf("dead letter = %+v", dl)
+			}
+			break
+		}
+
+		// The retry queue dead-letters the message back onto the work queue
+		// through the default exchange
+		msg = amqp.Delivery{Exchange: "", RoutingKey: testConsumer.Queue, Headers: headers}
+	}
+
+	want := fmt.Sprint([]string{
+		"notifications.queue.retry.1s",
+		"notifications.queue.retry.10s",
+		"notifications.queue.retry.1m0s",
+		"notifications.queue",
+	})
+	if got := fmt.Sprint(hops); got != want {
+		t.Errorf("hops = %s, want %s", got, want)
+	}
+}
+
+func TestRetryCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int
+	}{
+		{"no headers", nil, 0},
+		{"int32", amqp.Table{HeaderRetryCount: int32(2)}, 2},
+		{"int64", amqp.Table{HeaderRetryCount: int64(3)}, 3},
+		{"int", amqp.Table{HeaderRetryCount: 1}, 1},
+		{"unexpected type", amqp.Table{HeaderRetryCount: "2"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryCount(tt.headers); got != tt.want {
+				t.Errorf("retryCount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
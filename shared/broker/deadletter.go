@@ -0,0 +1,160 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter is a message parked in a dead-letter queue
+type DeadLetter struct {
+	MessageID      string     `json:"message_id"`
+	RoutingKey     string     `json:"routing_key"`
+	Exchange       string     `json:"exchange"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error"`
+	DeadLetteredAt *time.Time `json:"dead_lettered_at,omitempty"`
+	PublishedAt    time.Time  `json:"published_at"`
+	ContentType    string     `json:"content_type"`
+	Body           string     `json:"body"`
+}
+
+func newDeadLetter(msg amqp.Delivery) DeadLetter {
+	dl := DeadLetter{
+		MessageID:   msg.MessageId,
+		RoutingKey:  msg.RoutingKey,
+		Exchange:    msg.Exchange,
+		Attempts:    retryCount(msg.Headers) + 1,
+		PublishedAt: msg.Timestamp,
+		ContentType: msg.ContentType,
+		Body:        string(msg.Body),
+	}
+	if key, ok := msg.Headers[HeaderOriginalRoutingKey].(string); ok {
+		dl.RoutingKey = key
+	}
+	if exchange, ok := msg.Headers[HeaderOriginalExchange].(string); ok {
+		dl.Exchange = exchange
+	}
+	if reason, ok := msg.Headers[HeaderLastError].(string); ok {
+		dl.LastError = reason
+	}
+	if at, ok := msg.Headers[HeaderDeadLetteredAt].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, at); err == nil {
+			dl.DeadLetteredAt = &parsed
+		}
+	}
+	return dl
+}
+
+// DeadLetters returns up to limit messages from the head of the dead-letter
+// queue without removing them. The messages are fetched unacknowledged and go
+// back to the queue when the channel closes.
+func (c *Client) DeadLetters(ctx context.Context, cfg ConsumerConfig, limit int) ([]DeadLetter, error) {
+	ch, err := c.adminChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	letters := make([]DeadLetter, 0)
+	for len(letters) < limit {
+		msg, ok, err := ch.Get(cfg.DeadLetterQueue, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, newDeadLetter(msg))
+	}
+
+	return letters, nil
+}
+
+// Redrive sends dead-lettered messages back to the work queue with a fresh
+// retry budget. With message IDs only those messages are sent, otherwise up to
+// limit messages from the head of the queue. It returns how many were sent.
+func (c *Client) Redrive(ctx context.Context, cfg ConsumerConfig, messageIDs []string, limit int) (int, error) {
+	ch, err := c.adminChannel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	wanted := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		wanted[id] = true
+	}
+
+	redriven := 0
+	for len(wanted) > 0 || redriven < limit {
+		msg, ok, err := ch.Get(cfg.DeadLetterQueue, false)
+		if err != nil {
+			return redriven, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		// Messages that are not picked stay unacknowledged until the channel
+		// closes, so every message is looked at once
+		if len(messageIDs) > 0 && !wanted[msg.MessageId] {
+			continue
+		}
+		delete(wanted, msg.MessageId)
+
+		if err := c.redrive(ctx, cfg, msg); err != nil {
+			return redriven, err
+		}
+		if err := msg.Ack(false); err != nil {
+			return redriven, fmt.Errorf("failed to remove redriven message %s: %w", msg.MessageId, err)
+		}
+		redriven++
+		redrivenTotal.WithLabelValues(cfg.Queue).Inc()
+
+		if len(messageIDs) > 0 && len(wanted) == 0 {
+			break
+		}
+	}
+
+	return redriven, nil
+}
+
+func (c *Client) redrive(ctx context.Context, cfg ConsumerConfig, msg amqp.Delivery) error {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	delete(headers, HeaderRetryCount)
+	delete(headers, HeaderDeadLetteredAt)
+
+	err := c.Publish(ctx, "", cfg.Queue, true, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		Body:            msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to redrive message %s: %w", msg.MessageId, err)
+	}
+	return nil
+}
+
+func (c *Client) adminChannel(ctx context.Context) (*amqp.Channel, error) {
+	conn, _, err := c.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	return ch, nil
+}
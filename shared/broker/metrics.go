@@ -0,0 +1,72 @@
+package broker
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	connectionUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rabbitmq_connection_up",
+			Help: "Whether the connection to RabbitMQ is up (1) or down (0)",
+		},
+		[]string{"connection"},
+	)
+
+	disconnectsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_disconnects_total",
+			Help: "Total number of unexpected connection losses",
+		},
+		[]string{"connection"},
+	)
+
+	reconnectAttemptsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_reconnect_attempts_total",
+			Help: "Total number of reconnect attempts by result",
+		},
+		[]string{"connection", "result"},
+	)
+
+	publishedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_published_total",
+			Help: "Total number of published messages by outcome: confirmed, nacked, returned or failed",
+		},
+		[]string{"connection", "outcome"},
+	)
+
+	consumedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_consumed_total",
+			Help: "Total number of consumed messages by outcome: acked, retried, dead_lettered or requeued",
+		},
+		[]string{"queue", "outcome"},
+	)
+
+	handlerDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rabbitmq_handler_duration_seconds",
+			Help:    "Time spent handling one message",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"queue"},
+	)
+
+	redrivenTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_redriven_total",
+			Help: "Total number of dead-lettered messages sent back to their queue",
+		},
+		[]string{"queue"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(connectionUp)
+	prometheus.MustRegister(disconnectsTotal)
+	prometheus.MustRegister(reconnectAttemptsTotal)
+	prometheus.MustRegister(publishedTotal)
+	prometheus.MustRegister(consumedTotal)
+	prometheus.MustRegister(handlerDuration)
+	prometheus.MustRegister(redrivenTotal)
+}
@@ -0,0 +1,135 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// publishChannel is the part of *amqp.Channel a publisher uses
+type publishChannel interface {
+	PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (*amqp.DeferredConfirmation, error)
+	IsClosed() bool
+	Close() error
+}
+
+// publisher is a confirm mode channel borrowed by one publish at a time. A
+// publisher without a channel holds the place of one that could not be
+// opened; the publish that borrows it next opens the channel.
+type publisher struct {
+	ch      publishChannel
+	returns chan amqp.Return
+}
+
+func newPublisher(conn *amqp.Connection) (*publisher, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open publisher channel: %w", err)
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	return &publisher{
+		ch:      ch,
+		returns: ch.NotifyReturn(make(chan amqp.Return, 1)),
+	}, nil
+}
+
+// publish sends one message and waits for its confirm. The broker returns an
+// unroutable mandatory message before it confirms it, so a return is waiting
+// by the time the confirm arrives.
+func (p *publisher) publish(ctx context.Context, exchange, routingKey string, mandatory bool, msg amqp.Publishing) error {
+	confirmation, err := p.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, mandatory, false, msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for publisher confirm: %w", err)
+	}
+
+	select {
+	case ret := <-p.returns:
+		return fmt.Errorf("%w: %d %s", ErrUnroutable, ret.ReplyCode, ret.ReplyText)
+	default:
+	}
+
+	if !acked {
+		return ErrNacked
+	}
+	return nil
+}
+
+// Publish sends a message and waits until the broker confirms it. It waits
+// for a lost connection to come back as long as the context allows. Mandatory
+// messages no queue is bound for fail with ErrUnroutable.
+// The trace of the context is carried to consumers in the message headers.
+func (c *Client) Publish(ctx context.Context, exchange, routingKey string, mandatory bool, msg amqp.Publishing) error {
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, &msg)
+	err := c.publish(ctx, exchange, routingKey, mandatory, msg)
+
+	outcome := "confirmed"
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNacked):
+		outcome = "nacked"
+	case errors.Is(err, ErrUnroutable):
+		outcome = "returned"
+	default:
+		outcome = "failed"
+	}
+	publishedTotal.WithLabelValues(c.cfg.Name, outcome).Inc()
+	endSpan(span, outcome, err)
+
+	return err
+}
+
+func (c *Client) publish(ctx context.Context, exchange, routingKey string, mandatory bool, msg amqp.Publishing) error {
+	conn, pool, err := c.current(ctx)
+	if err != nil {
+		return err
+	}
+
+	var p *publisher
+	select {
+	case p = <-pool:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return ErrClosed
+	}
+
+	if p.ch == nil {
+		fresh, err := c.openPublisher(conn)
+		if err != nil {
+			pool <- p
+			return err
+		}
+		p = fresh
+	}
+
+	err = p.publish(ctx, exchange, routingKey, mandatory, msg)
+
+	// A channel that failed may be closed or still owe a confirm to the
+	// abandoned message, so it is replaced rather than reused
+	if p.ch.IsClosed() || (err != nil && !errors.Is(err, ErrNacked) && !errors.Is(err, ErrUnroutable)) {
+		p.ch.Close()
+		fresh, freshErr := c.openPublisher(conn)
+		if freshErr != nil {
+			// Hold the place of the channel so the pool keeps its size
+			c.log.Warn("Failed to replace publisher channel", zap.Error(freshErr))
+			fresh = &publisher{}
+		}
+		p = fresh
+	}
+	pool <- p
+
+	return err
+}
@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// brokenChannel fails every publish, like a channel the broker closed under it
+type brokenChannel struct {
+	published int
+	closed    bool
+}
+
+func (ch *brokenChannel) PublishWithDeferredConfirmWithContext(context.Context, string, string, bool, bool, amqp.Publishing) (*amqp.DeferredConfirmation, error) {
+	ch.published++
+	return nil, errors.New("channel/connection is not open")
+}
+
+func (ch *brokenChannel) IsClosed() bool { return ch.closed }
+
+func (ch *brokenChannel) Close() error {
+	ch.closed = true
+	return nil
+}
+
+// newPoolClient returns a connected client whose pool holds size broken
+// channels and whose new channels come from open
+func newPoolClient(size int, open func(*amqp.Connection) (*publisher, error)) *Client {
+	c := &Client{
+		cfg:           Config{Name: "test", PublisherChannels: size},
+		log:           zap.NewNop(),
+		pool:          make(chan *publisher, size),
+		openPublisher: open,
+		ready:         make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	close(c.ready)
+	for i := 0; i < size; i++ {
+		c.pool <- &publisher{ch: &brokenChannel{}}
+	}
+	return c
+}
+
+func TestPublishKeepsPoolSizeWhenChannelsCannotOpen(t *testing.T) {
+	openErr := errors.New("failed to open publisher channel: connection blocked")
+	var opened []*brokenChannel
+	failOpen := true
+	c := newPoolClient(2, func(*amqp.Connection) (*publisher, error) {
+		if failOpen {
+			return nil, openErr
+		}
+		ch := &brokenChannel{}
+		opened = append(opened, ch)
+		return &publisher{ch: ch}, nil
+	})
+
+	// More publishes than channels: a pool losing a channel per failed
+	// replacement would be empty by the third and block until the deadline
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := c.publish(ctx, "bank.events", "user.created", false, amqp.Publishing{})
+		cancel()
+
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("publish %d error = %v, want the channel failure", i+1, err)
+		}
+		if len(c.pool) != 2 {
+			t.Fatalf("pool holds %d publishers after publish %d, want 2", len(c.pool), i+1)
+		}
+	}
+
+	// Once channels open again each placeholder gets one, which fails the
+	// publish in turn and is replaced
+	failOpen = false
+	for i := 0; i < 2; i++ {
+		if err := c.publish(context.Background(), "bank.events", "user.created", false, amqp.Publishing{}); errors.Is(err, openErr) {
+			t.Fatalf("publish error = %v, want the placeholder opened", err)
+		}
+	}
+	if len(opened) != 4 || opened[0].published != 1 || opened[2].published != 1 {
+		t.Errorf("opened %d channels, want a channel for each placeholder and each replacement", len(opened))
+	}
+	if len(c.pool) != 2 {
+		t.Errorf("pool holds %d publishers, want 2", len(c.pool))
+	}
+}
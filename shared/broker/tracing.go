@@ -0,0 +1,99 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/crypto-bank/shared/broker"
+
+// headerCarrier carries W3C trace context in AMQP message headers, so a trace
+// started by an HTTP request continues in the services consuming its events
+type headerCarrier amqp.Table
+
+func (hc headerCarrier) Get(key string) string {
+	value, _ := hc[key].(string)
+	return value
+}
+
+func (hc headerCarrier) Set(key, value string) {
+	hc[key] = value
+}
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for key := range hc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// startPublishSpan starts a producer span for a message and injects it into a
+// copy of the message headers
+func startPublishSpan(ctx context.Context, exchange, routingKey string, msg *amqp.Publishing) (context.Context, trace.Span) {
+	destination := exchange
+	if destination == "" {
+		destination = routingKey
+	}
+
+	ctx, span := tracer().Start(ctx, destination+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation", "publish"),
+			attribute.String("messaging.destination.name", exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+			attribute.String("messaging.message.id", msg.MessageId),
+			attribute.Int("messaging.message.body.size", len(msg.Body)),
+		),
+	)
+
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	msg.Headers = headers
+
+	return ctx, span
+}
+
+// startProcessSpan starts a consumer span for a delivery as a child of the
+// trace its publisher injected
+func startProcessSpan(queue string, msg amqp.Delivery) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(msg.Headers))
+
+	return tracer().Start(ctx, queue+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation", "process"),
+			attribute.String("messaging.destination.name", msg.Exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", msg.RoutingKey),
+			attribute.String("messaging.source.name", queue),
+			attribute.String("messaging.message.id", msg.MessageId),
+			attribute.Int("messaging.message.body.size", len(msg.Body)),
+			attribute.Int("messaging.rabbitmq.retry_count", retryCount(msg.Headers)),
+		),
+	)
+}
+
+// endSpan records the outcome of a span and ends it
+func endSpan(span trace.Span, outcome string, err error) {
+	span.SetAttributes(attribute.String("messaging.outcome", outcome))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprintf("%s: %v", outcome, err))
+	}
+	span.End()
+}
@@ -0,0 +1,41 @@
+package dedup
+
+import (
+	"context"
+
+	"github.com/crypto-bank/shared/broker"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Wrap makes the handler of a queue idempotent. Events are told apart by the
+// message ID, which publishers set to the event ID. A delivery whose event was
+// processed within the TTL, or is being processed by another worker, is
+// acknowledged without calling the handler; if the other worker fails, its
+// retry is processed. Messages without an ID are always handled.
+func Wrap(queue string, store *Store, handler broker.Handler) broker.Handler {
+	duplicatesTotal.WithLabelValues(queue).Add(0)
+
+	return func(ctx context.Context, msg amqp.Delivery) error {
+		id := msg.MessageId
+		if id == "" {
+			return handler(ctx, msg)
+		}
+
+		if !store.Claim(id) {
+			duplicatesTotal.WithLabelValues(queue).Inc()
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("messaging.duplicate", true))
+			return nil
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			store.Release(id)
+			return err
+		}
+
+		store.Complete(id)
+		entries.WithLabelValues(queue).Set(float64(store.Len()))
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package dedup
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	duplicatesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "consumer_duplicates_dropped_total",
+			Help: "Total number of redelivered events acknowledged without processing them again",
+		},
+		[]string{"queue"},
+	)
+
+	entries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "consumer_dedup_entries",
+			Help: "Number of processed event IDs remembered to drop redeliveries",
+		},
+		[]string{"queue"},
+	)
+
+	journalFailuresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "consumer_dedup_journal_failures_total",
+			Help: "Total number of failed writes of processed event IDs to the dedup journal",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(duplicatesTotal)
+	prometheus.MustRegister(entries)
+	prometheus.MustRegister(journalFailuresTotal)
+}
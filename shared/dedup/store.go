@@ -0,0 +1,260 @@
+// Package dedup makes consumers idempotent. RabbitMQ delivers every message at
+// least once: after a lost ack, a reconnect or a redrive the same event comes
+// again. A Store remembers the IDs of the events a consumer processed for a
+// while, and Wrap drops the copies that come again.
+package dedup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Config bounds a store and says where it is kept
+type Config struct {
+	// Path is the journal that keeps the IDs across restarts; without one they
+	// only live in memory
+	Path string
+	// TTL is how long a processed ID is remembered
+	TTL time.Duration
+	// MaxEntries bounds the store; beyond it the oldest IDs are forgotten first
+	MaxEntries int
+}
+
+type entry struct {
+	id string
+	at time.Time
+}
+
+// Store remembers processed event IDs. IDs are appended to the journal as they
+// are processed and the journal is rewritten once it holds mostly forgotten
+// IDs. The journal is not synced: an ID written just before the machine goes
+// down may be lost and its event processed once more.
+type Store struct {
+	cfg Config
+	log *zap.Logger
+	now func() time.Time
+
+	mu        sync.Mutex
+	processed map[string]time.Time
+	// order holds the processed IDs oldest first from head on
+	order    []entry
+	head     int
+	inFlight map[string]struct{}
+
+	journal *os.File
+	// journaled counts the lines in the journal, forgotten IDs included
+	journaled int
+}
+
+// Open loads the IDs journaled at the configured path that are still within
+// the TTL
+func Open(cfg Config, log *zap.Logger) (*Store, error) {
+	s := &Store{
+		cfg:       cfg,
+		log:       log,
+		now:       time.Now,
+		processed: make(map[string]time.Time),
+		inFlight:  make(map[string]struct{}),
+	}
+	if cfg.Path == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dedup store directory: %w", err)
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.expire(s.now())
+
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read dedup store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// A line torn by a crash is skipped
+		at, id, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || id == "" {
+			continue
+		}
+		nanos, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			continue
+		}
+		s.add(id, time.Unix(0, nanos))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dedup store: %w", err)
+	}
+	return nil
+}
+
+// Claim reserves an event for processing. It reports false when the event was
+// processed within the TTL or another worker is processing it right now.
+func (s *Store) Claim(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	if _, ok := s.processed[id]; ok {
+		return false
+	}
+	if _, ok := s.inFlight[id]; ok {
+		return false
+	}
+	s.inFlight[id] = struct{}{}
+	return true
+}
+
+// Release gives up a claim after processing failed, so that the retry is
+// processed
+func (s *Store) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inFlight, id)
+}
+
+// Complete records a claimed event as processed. The event was processed
+// either way, so a failed journal write is only logged: the ID is remembered
+// until the next restart.
+func (s *Store) Complete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	delete(s.inFlight, id)
+	s.add(id, now)
+	s.expire(now)
+
+	if s.journal == nil {
+		return
+	}
+	if _, err := fmt.Fprintf(s.journal, "%d %s\n", now.UnixNano(), id); err != nil {
+		journalFailuresTotal.Inc()
+		s.log.Error("Failed to journal processed event", zap.String("event_id", id), zap.Error(err))
+		return
+	}
+	s.journaled++
+
+	// Rewrite the journal once it is mostly forgotten IDs
+	if s.journaled > 2*len(s.processed)+1000 {
+		if err := s.compact(); err != nil {
+			journalFailuresTotal.Inc()
+			s.log.Error("Failed to compact dedup store", zap.Error(err))
+		}
+	}
+}
+
+// Len returns the number of IDs remembered
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.processed)
+}
+
+// Close closes the journal
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal == nil {
+		return nil
+	}
+	err := s.journal.Close()
+	s.journal = nil
+	return err
+}
+
+func (s *Store) add(id string, at time.Time) {
+	if _, ok := s.processed[id]; ok {
+		return
+	}
+	s.processed[id] = at
+	s.order = append(s.order, entry{id: id, at: at})
+}
+
+// expire forgets IDs past the TTL and the oldest ones beyond MaxEntries
+func (s *Store) expire(now time.Time) {
+	cutoff := now.Add(-s.cfg.TTL)
+	for s.head < len(s.order) {
+		oldest := s.order[s.head]
+		if !oldest.at.Before(cutoff) && (s.cfg.MaxEntries <= 0 || len(s.processed) <= s.cfg.MaxEntries) {
+			break
+		}
+		delete(s.processed, oldest.id)
+		s.order[s.head] = entry{}
+		s.head++
+	}
+
+	// Drop the forgotten head once it makes up half of the queue
+	if s.head > 0 && s.head >= len(s.order)/2 {
+		s.order = append([]entry(nil), s.order[s.head:]...)
+		s.head = 0
+	}
+}
+
+// compact rewrites the journal with the IDs still remembered and reopens it
+// for appending
+func (s *Store) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.cfg.Path), filepath.Base(s.cfg.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, e := range s.order[s.head:] {
+		if _, err := fmt.Fprintf(writer, "%d %s\n", e.at.UnixNano(), e.id); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write dedup store: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+
+	// The old journal stays in use until the new one is in place
+	if err := os.Rename(tmp.Name(), s.cfg.Path); err != nil {
+		return fmt.Errorf("failed to replace dedup store: %w", err)
+	}
+
+	journal, err := os.OpenFile(s.cfg.Path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dedup store: %w", err)
+	}
+	if s.journal != nil {
+		s.journal.Close()
+	}
+	s.journal = journal
+	s.journaled = len(s.order) - s.head
+	return nil
+}
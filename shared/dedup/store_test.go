@@ -0,0 +1,313 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// clock is a settable time for the store
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func openStore(t *testing.T, cfg Config) (*Store, *clock) {
+	t.Helper()
+
+	store, err := Open(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	c := &clock{now: time.Now()}
+	store.now = c.Now
+	return store, c
+}
+
+// counter is a handler that counts its calls per message ID
+type counter struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+}
+
+func (h *counter) handle(ctx context.Context, msg amqp.Delivery) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.calls == nil {
+		h.calls = make(map[string]int)
+	}
+	h.calls[msg.MessageId]++
+	return h.err
+}
+
+func (h *counter) count(id string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls[id]
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name       string
+		deliveries []string
+		want       map[string]int
+	}{
+		{
+			name:       "single delivery",
+			deliveries: []string{"a"},
+			want:       map[string]int{"a": 1},
+		},
+		{
+			name:       "redelivered several times",
+			deliveries: []string{"a", "a", "a", "a", "a"},
+			want:       map[string]int{"a": 1},
+		},
+		{
+			name:       "interleaved redeliveries",
+			deliveries: []string{"a", "b", "a", "c", "b", "a"},
+			want:       map[string]int{"a": 1, "b": 1, "c": 1},
+		},
+		{
+			name:       "messages without an ID",
+			deliveries: []string{"", "", ""},
+			want:       map[string]int{"": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := openStore(t, Config{TTL: time.Hour})
+			handler := &counter{}
+			handle := Wrap("test", store, handler.handle)
+
+			for _, id := range tt.deliveries {
+				if err := handle(context.Background(), amqp.Delivery{MessageId: id}); err != nil {
+					t.Fatalf("delivery %q: %v", id, err)
+				}
+			}
+			for id, want := range tt.want {
+				if got := handler.count(id); got != want {
+					t.Errorf("handler ran %d times for %q, want %d", got, id, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapConcurrentRedeliveries(t *testing.T) {
+	store, _ := openStore(t, Config{TTL: time.Hour})
+	handler := &counter{}
+	handle := Wrap("test", store, handler.handle)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := handle(context.Background(), amqp.Delivery{MessageId: "a"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := handler.count("a"); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestWrapRetriesFailedHandler(t *testing.T) {
+	store, _ := openStore(t, Config{TTL: time.Hour})
+	failure := errors.New("downstream unavailable")
+	handler := &counter{err: failure}
+	handle := Wrap("test", store, handler.handle)
+	msg := amqp.Delivery{MessageId: "a"}
+
+	if err := handle(context.Background(), msg); !errors.Is(err, failure) {
+		t.Fatalf("error = %v, want %v", err, failure)
+	}
+
+	// The retry is processed and its redeliveries are dropped
+	handler.err = nil
+	for i := 0; i < 3; i++ {
+		if err := handle(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := handler.count("a"); got != 2 {
+		t.Errorf("handler ran %d times, want 2", got)
+	}
+}
+
+func TestWrapInFlight(t *testing.T) {
+	store, _ := openStore(t, Config{TTL: time.Hour})
+	var calls atomic.Int32
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handle := Wrap("test", store, func(ctx context.Context, msg amqp.Delivery) error {
+		calls.Add(1)
+		close(started)
+		<-finish
+		return nil
+	})
+	msg := amqp.Delivery{MessageId: "a"}
+
+	done := make(chan error)
+	go func() { done <- handle(context.Background(), msg) }()
+	<-started
+
+	// A copy arriving while the first is processed is acknowledged
+	if err := handle(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	close(finish)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestStoreTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		claimed bool
+	}{
+		{name: "within the TTL", elapsed: 59 * time.Minute, claimed: false},
+		{name: "at the TTL", elapsed: time.Hour, claimed: false},
+		{name: "past the TTL", elapsed: time.Hour + time.Nanosecond, claimed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := openStore(t, Config{TTL: time.Hour})
+			if !store.Claim("a") {
+				t.Fatal("first claim refused")
+			}
+			store.Complete("a")
+
+			clock.Advance(tt.elapsed)
+			if got := store.Claim("a"); got != tt.claimed {
+				t.Errorf("Claim = %v, want %v", got, tt.claimed)
+			}
+		})
+	}
+}
+
+func TestStoreMaxEntries(t *testing.T) {
+	store, _ := openStore(t, Config{TTL: time.Hour, MaxEntries: 3})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		store.Claim(id)
+		store.Complete(id)
+	}
+
+	if got := store.Len(); got != 3 {
+		t.Errorf("Len = %d, want 3", got)
+	}
+	if !store.Claim("a") {
+		t.Error("oldest ID is still remembered")
+	}
+	if store.Claim("d") {
+		t.Error("newest ID was forgotten")
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup", "processed.log")
+	cfg := Config{Path: path, TTL: time.Hour}
+
+	store, err := Open(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := &counter{}
+	handle := Wrap("test", store, handler.handle)
+	for _, id := range []string{"a", "b"} {
+		if err := handle(context.Background(), amqp.Delivery{MessageId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart the redeliveries of processed events are still dropped
+	store, err = Open(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	handle = Wrap("test", store, handler.handle)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := handle(context.Background(), amqp.Delivery{MessageId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, want := range map[string]int{"a": 1, "b": 1, "c": 1} {
+		if got := handler.count(id); got != want {
+			t.Errorf("handler ran %d times for %q, want %d", got, id, want)
+		}
+	}
+}
+
+func TestStoreReloadSkipsExpiredAndTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "processed.log")
+	now := time.Now()
+	journal := fmt.Sprintf("%d expired\n%d kept\nnot-a-timestamp torn\n%d",
+		now.Add(-2*time.Hour).UnixNano(), now.Add(-time.Minute).UnixNano(), now.UnixNano())
+	if err := os.WriteFile(path, []byte(journal), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(Config{Path: path, TTL: time.Hour}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if got := store.Len(); got != 1 {
+		t.Errorf("Len = %d, want 1", got)
+	}
+	if store.Claim("kept") {
+		t.Error("ID within the TTL was not reloaded")
+	}
+	if !store.Claim("expired") {
+		t.Error("ID past the TTL was reloaded")
+	}
+
+	// Loading compacts the journal to the remembered IDs
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("%d kept\n", now.Add(-time.Minute).UnixNano()); string(data) != want {
+		t.Errorf("journal = %q, want %q", data, want)
+	}
+}
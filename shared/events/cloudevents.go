@@ -0,0 +1,223 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ContentMode selects how an event is laid out in an AMQP message under the
+// CloudEvents 1.0 AMQP binding
+type ContentMode string
+
+const (
+	// ModeBinary keeps the data as the message body and carries the event
+	// attributes as application properties. Consumers that read the bare data
+	// keep working.
+	ModeBinary ContentMode = "binary"
+	// ModeStructured sends the whole event as an application/cloudevents+json body
+	ModeStructured ContentMode = "structured"
+)
+
+func (m ContentMode) Valid() bool {
+	return m == ModeBinary || m == ModeStructured
+}
+
+const (
+	specVersion = "1.0"
+
+	// TypePrefix namespaces event types in the CloudEvents type attribute,
+	// transaction.completed becomes com.cryptobank.transaction.completed
+	TypePrefix = "com.cryptobank."
+
+	contentTypeJSON        = "application/json"
+	contentTypeCloudEvents = "application/cloudevents+json"
+
+	// The AMQP binding prefixes attributes with "cloudEvents:"; some clients
+	// cannot use colons and send "cloudEvents_" instead, which is accepted too
+	headerPrefix    = "cloudEvents:"
+	altHeaderPrefix = "cloudEvents_"
+)
+
+// cloudEvent is the structured JSON form of an event. schemaversion and
+// correlationid are extension attributes.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   int             `json:"schemaversion"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// ToAMQP lays an envelope out as a CloudEvent in the given content mode. The
+// caller sets delivery mode and other message properties.
+func ToAMQP(env *Envelope, mode ContentMode) (amqp.Publishing, error) {
+	switch mode {
+	case ModeBinary:
+		headers := amqp.Table{
+			headerPrefix + "specversion":   specVersion,
+			headerPrefix + "id":            env.ID,
+			headerPrefix + "source":        "/" + env.Producer,
+			headerPrefix + "type":          TypePrefix + env.Type,
+			headerPrefix + "time":          env.OccurredAt.UTC().Format(time.RFC3339Nano),
+			headerPrefix + "schemaversion": int32(env.Version),
+		}
+		if env.Subject != "" {
+			headers[headerPrefix+"subject"] = env.Subject
+		}
+		if env.CorrelationID != "" {
+			headers[headerPrefix+"correlationid"] = env.CorrelationID
+		}
+
+		return amqp.Publishing{
+			Headers:     headers,
+			ContentType: contentTypeJSON,
+			MessageId:   env.ID,
+			Timestamp:   env.OccurredAt,
+			Type:        env.Type,
+			Body:        env.Data,
+		}, nil
+
+	case ModeStructured:
+		body, err := json.Marshal(cloudEvent{
+			SpecVersion:     specVersion,
+			ID:              env.ID,
+			Source:          "/" + env.Producer,
+			Type:            TypePrefix + env.Type,
+			Subject:         env.Subject,
+			Time:            env.OccurredAt.UTC(),
+			DataContentType: contentTypeJSON,
+			SchemaVersion:   env.Version,
+			CorrelationID:   env.CorrelationID,
+			Data:            env.Data,
+		})
+		if err != nil {
+			return amqp.Publishing{}, fmt.Errorf("failed to marshal cloud event: %w", err)
+		}
+
+		return amqp.Publishing{
+			ContentType: contentTypeCloudEvents,
+			MessageId:   env.ID,
+			Timestamp:   env.OccurredAt,
+			Type:        env.Type,
+			Body:        body,
+		}, nil
+	}
+
+	return amqp.Publishing{}, fmt.Errorf("unknown content mode: %s", mode)
+}
+
+// FromAMQP reads an event in any format published to bank.events: a CloudEvent
+// in binary or structured mode, an envelope, or the bare data of the first
+// producers
+func FromAMQP(msg amqp.Delivery) (*Envelope, error) {
+	if _, ok := header(msg.Headers, "specversion"); ok {
+		return fromBinary(msg)
+	}
+
+	if strings.HasPrefix(msg.ContentType, contentTypeCloudEvents) || hasSpecVersion(msg.Body) {
+		var ce cloudEvent
+		if err := json.Unmarshal(msg.Body, &ce); err != nil {
+			return nil, fmt.Errorf("failed to parse cloud event: %w", err)
+		}
+		return fromCloudEvent(ce)
+	}
+
+	return Parse(msg.RoutingKey, msg.Body)
+}
+
+func fromBinary(msg amqp.Delivery) (*Envelope, error) {
+	ce := cloudEvent{
+		DataContentType: msg.ContentType,
+		Data:            json.RawMessage(msg.Body),
+	}
+	ce.SpecVersion, _ = header(msg.Headers, "specversion")
+	ce.ID, _ = header(msg.Headers, "id")
+	ce.Source, _ = header(msg.Headers, "source")
+	ce.Type, _ = header(msg.Headers, "type")
+	ce.Subject, _ = header(msg.Headers, "subject")
+	ce.CorrelationID, _ = header(msg.Headers, "correlationid")
+
+	if at, ok := header(msg.Headers, "time"); ok {
+		parsed, err := time.Parse(time.RFC3339Nano, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cloud event time: %w", err)
+		}
+		ce.Time = parsed
+	}
+
+	ce.SchemaVersion = 1
+	if version, ok := rawHeader(msg.Headers, "schemaversion"); ok {
+		switch v := version.(type) {
+		case int32:
+			ce.SchemaVersion = int(v)
+		case int64:
+			ce.SchemaVersion = int(v)
+		case string:
+			if _, err := fmt.Sscanf(v, "%d", &ce.SchemaVersion); err != nil {
+				return nil, fmt.Errorf("failed to parse cloud event schema version: %w", err)
+			}
+		}
+	}
+
+	return fromCloudEvent(ce)
+}
+
+func fromCloudEvent(ce cloudEvent) (*Envelope, error) {
+	if ce.SpecVersion != specVersion {
+		return nil, fmt.Errorf("unsupported cloud events spec version: %q", ce.SpecVersion)
+	}
+	if ce.ID == "" || ce.Source == "" || ce.Type == "" {
+		return nil, fmt.Errorf("cloud event lacks id, source or type")
+	}
+	if ce.DataContentType != "" && !strings.HasPrefix(ce.DataContentType, contentTypeJSON) {
+		return nil, fmt.Errorf("unsupported cloud event data content type: %s", ce.DataContentType)
+	}
+	if ce.SchemaVersion == 0 {
+		ce.SchemaVersion = 1
+	}
+
+	return &Envelope{
+		ID:            ce.ID,
+		Type:          strings.TrimPrefix(ce.Type, TypePrefix),
+		Version:       ce.SchemaVersion,
+		OccurredAt:    ce.Time,
+		Producer:      strings.TrimPrefix(ce.Source, "/"),
+		Subject:       ce.Subject,
+		CorrelationID: ce.CorrelationID,
+		Data:          ce.Data,
+	}, nil
+}
+
+// hasSpecVersion spots structured events sent without their content type
+func hasSpecVersion(body []byte) bool {
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+	return json.Unmarshal(body, &probe) == nil && probe.SpecVersion != ""
+}
+
+func rawHeader(headers amqp.Table, attribute string) (interface{}, bool) {
+	if value, ok := headers[headerPrefix+attribute]; ok {
+		return value, true
+	}
+	value, ok := headers[altHeaderPrefix+attribute]
+	return value, ok
+}
+
+func header(headers amqp.Table, attribute string) (string, bool) {
+	value, ok := rawHeader(headers, attribute)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
@@ -0,0 +1,152 @@
+// Package events is the contract for messages on the bank.events exchange.
+// Every message is an Envelope whose Data holds one of the event types below;
+// producers and consumers share these types instead of declaring their own.
+// On the wire envelopes travel as CloudEvents 1.0, see ToAMQP and FromAMQP.
+//
+// Each event type has an integer schema version. Evolving a schema follows
+// these rules:
+//
+//   - Adding an optional field is compatible and keeps the version. Consumers
+//     ignore fields they do not know and must accept a new field being absent.
+//   - Removing or renaming a field, changing its type, unit or meaning is a
+//     breaking change and bumps the version. The old struct is kept with a
+//     version suffix and an upgrade to the new shape is registered, so
+//     consumers keep reading messages still in flight.
+//   - Consumers are deployed before producers. A consumer rejects versions
+//     newer than it knows with ErrUnsupportedVersion; such messages are
+//     dead-lettered and can be redriven once the consumer is upgraded.
+//   - Every version of every type has a golden payload in golden/. Golden
+//     payloads are never edited once released; the contract tests check
+//     the types against them.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnknownType is returned for event types the contract does not define
+	ErrUnknownType = errors.New("unknown event type")
+	// ErrUnsupportedVersion is returned for schema versions this build does not know
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+)
+
+// Envelope carries an event with the metadata every consumer relies on
+type Envelope struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	// OccurredAt is when the change the event announces was made
+	OccurredAt time.Time `json:"occurred_at"`
+	Producer   string    `json:"producer"`
+	// Subject is the ID of the business entity the event is about
+	Subject string `json:"subject,omitempty"`
+	// CorrelationID ties together the events caused by one request or
+	// workflow; the first event of a chain uses its own ID
+	CorrelationID string          `json:"correlation_id"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// New wraps event data of the given type at its current schema version
+func New(id, eventType, producer, correlationID string, occurredAt time.Time, data interface{}) (*Envelope, error) {
+	s, ok := schemas[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+	}
+
+	if correlationID == "" {
+		correlationID = id
+	}
+
+	return &Envelope{
+		ID:            id,
+		Type:          eventType,
+		Version:       s.version,
+		OccurredAt:    occurredAt.UTC(),
+		Producer:      producer,
+		CorrelationID: correlationID,
+		Data:          payload,
+	}, nil
+}
+
+// Parse reads an envelope. Messages published before the envelope was
+// introduced carry the bare data; they are read as version 1 of the type
+// named by the routing key.
+func Parse(routingKey string, body []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+
+	if env.Type == "" || env.Version == 0 || len(env.Data) == 0 {
+		return &Envelope{
+			Type:    routingKey,
+			Version: 1,
+			Data:    json.RawMessage(body),
+		}, nil
+	}
+
+	return &env, nil
+}
+
+// Decode unmarshals the data into the struct of the current schema version,
+// upgrading older versions first
+func (e *Envelope) Decode(v interface{}) error {
+	data, err := e.upgradedData()
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s v%d data: %w", e.Type, e.Version, err)
+	}
+	return nil
+}
+
+// Event decodes the data into a new value of the type registered for the
+// event, for example *TransactionEvent for TransactionCompleted
+func (e *Envelope) Event() (interface{}, error) {
+	s, ok := schemas[e.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, e.Type)
+	}
+
+	event := s.new()
+	if err := e.Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (e *Envelope) upgradedData() (json.RawMessage, error) {
+	s, ok := schemas[e.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, e.Type)
+	}
+	if e.Version < 1 || e.Version > s.version {
+		return nil, fmt.Errorf("%w: %s v%d, this build knows up to v%d",
+			ErrUnsupportedVersion, e.Type, e.Version, s.version)
+	}
+
+	data := e.Data
+	for version := e.Version; version < s.version; version++ {
+		upgrade, ok := s.upgrades[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no upgrade from %s v%d", ErrUnsupportedVersion, e.Type, version)
+		}
+
+		var err error
+		if data, err = upgrade(data); err != nil {
+			return nil, fmt.Errorf("failed to upgrade %s v%d data: %w", e.Type, version, err)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,62 @@
+// Package eventstest delivers the golden payloads of the event contract to
+// consumer handlers in tests
+package eventstest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/crypto-bank/shared/events"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Delivery returns the golden payload of a version of an event type as a
+// message from bank.events laid out in the given content mode
+func Delivery(t testing.TB, eventType string, version int, mode events.ContentMode) amqp.Delivery {
+	t.Helper()
+
+	body, err := events.Golden(eventType, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := events.Parse(eventType, body)
+	if err != nil {
+		t.Fatalf("failed to parse golden payload for %s v%d: %v", eventType, version, err)
+	}
+
+	msg, err := events.ToAMQP(env, mode)
+	if err != nil {
+		t.Fatalf("failed to lay out %s v%d: %v", eventType, version, err)
+	}
+
+	return amqp.Delivery{
+		Headers:     msg.Headers,
+		ContentType: msg.ContentType,
+		MessageId:   msg.MessageId,
+		Timestamp:   msg.Timestamp,
+		Type:        msg.Type,
+		Exchange:    "bank.events",
+		RoutingKey:  eventType,
+		Body:        msg.Body,
+	}
+}
+
+// Deliveries returns every version of the event types, in both content modes
+func Deliveries(t testing.TB, eventTypes ...string) map[string]amqp.Delivery {
+	t.Helper()
+
+	deliveries := make(map[string]amqp.Delivery)
+	for _, eventType := range eventTypes {
+		if events.Version(eventType) == 0 {
+			t.Fatalf("unknown event type %s", eventType)
+		}
+		for version := 1; version <= events.Version(eventType); version++ {
+			for _, mode := range []events.ContentMode{events.ModeBinary, events.ModeStructured} {
+				name := fmt.Sprintf("%s.v%d/%s", eventType, version, mode)
+				deliveries[name] = Delivery(t, eventType, version, mode)
+			}
+		}
+	}
+	return deliveries
+}
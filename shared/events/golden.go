@@ -0,0 +1,19 @@
+package events
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed golden/*.json
+var golden embed.FS
+
+// Golden returns the golden payload of a version of an event type. The contract
+// tests of this package and of the consuming services decode them.
+func Golden(eventType string, version int) ([]byte, error) {
+	body, err := golden.ReadFile(fmt.Sprintf("golden/%s.v%d.json", eventType, version))
+	if err != nil {
+		return nil, fmt.Errorf("no golden payload for %s v%d: %w", eventType, version, err)
+	}
+	return body, nil
+}
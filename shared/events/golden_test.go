@@ -0,0 +1,138 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// TestGolden checks the contract of every event type against its golden
+// payloads. Every version must have a golden payload that decodes into the
+// current struct, and the current version must match its struct field by
+// field, so a field that is renamed, removed or added without a golden payload
+// is caught.
+func TestGolden(t *testing.T) {
+	for _, eventType := range Types() {
+		s := schemas[eventType]
+		for version := 1; version <= s.version; version++ {
+			t.Run(fmt.Sprintf("%s.v%d", eventType, version), func(t *testing.T) {
+				verifyGolden(t, eventType, version, s)
+			})
+		}
+	}
+}
+
+func verifyGolden(t *testing.T, eventType string, version int, s schema) {
+	body, err := Golden(eventType, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := Parse(eventType, body)
+	if err != nil {
+		t.Fatalf("failed to parse golden payload: %v", err)
+	}
+	if env.Type != eventType || env.Version != version {
+		t.Fatalf("golden payload is labelled %s v%d", env.Type, env.Version)
+	}
+	if env.ID == "" || env.Producer == "" || env.OccurredAt.IsZero() {
+		t.Fatal("golden payload lacks envelope fields")
+	}
+
+	if version < s.version {
+		if _, err := env.Event(); err != nil {
+			t.Fatalf("golden payload no longer decodes: %v", err)
+		}
+		return
+	}
+
+	event := s.new()
+	decoder := json.NewDecoder(bytes.NewReader(env.Data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(event); err != nil {
+		t.Fatalf("golden payload does not match its struct: %v", err)
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var want, got interface{}
+	if err := json.Unmarshal(env.Data, &want); err != nil {
+		t.Fatalf("failed to read golden payload: %v", err)
+	}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("failed to read encoded event: %v", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("encodes as %s, golden payload is %s", encoded, env.Data)
+	}
+}
+
+// TestGoldenCoversTypes catches golden payloads left without an event type
+// and versions ahead of the registered schema
+func TestGoldenCoversTypes(t *testing.T) {
+	entries, err := golden.ReadDir("golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		found := false
+		for _, eventType := range Types() {
+			for version := 1; version <= Version(eventType); version++ {
+				if name == fmt.Sprintf("%s.v%d.json", eventType, version) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("golden payload %s matches no registered event version", name)
+		}
+	}
+}
+
+func TestGoldenRoundTrip(t *testing.T) {
+	for _, mode := range []ContentMode{ModeBinary, ModeStructured} {
+		for _, eventType := range Types() {
+			t.Run(fmt.Sprintf("%s/%s", mode, eventType), func(t *testing.T) {
+				body, err := Golden(eventType, Version(eventType))
+				if err != nil {
+					t.Fatal(err)
+				}
+				env, err := Parse(eventType, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				msg, err := ToAMQP(env, mode)
+				if err != nil {
+					t.Fatalf("ToAMQP: %v", err)
+				}
+				got, err := FromAMQP(amqp.Delivery{
+					Headers:     msg.Headers,
+					ContentType: msg.ContentType,
+					MessageId:   msg.MessageId,
+					RoutingKey:  eventType,
+					Body:        msg.Body,
+				})
+				if err != nil {
+					t.Fatalf("FromAMQP: %v", err)
+				}
+				if got.ID != env.ID || got.Type != env.Type || got.Version != env.Version ||
+					got.Producer != env.Producer || !got.OccurredAt.Equal(env.OccurredAt) {
+					t.Errorf("envelope changed on the wire: got %+v, want %+v", got, env)
+				}
+				if _, err := got.Event(); err != nil {
+					t.Errorf("event does not decode after the round trip: %v", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Event types, also used as routing keys on bank.events
+const (
+	UserCreated = "user.created"
+	UserUpdated = "user.updated"
+	UserDeleted = "user.deleted"
+
+	TransactionCreated   = "transaction.created"
+	TransactionCompleted = "transaction.completed"
+	TransactionFailed    = "transaction.failed"
+
+	ExchangeCreated   = "exchange.created"
+	ExchangeCompleted = "exchange.completed"
+	ExchangeFailed    = "exchange.failed"
+
+	AccountCreated        = "account.created"
+	AccountBalanceChanged = "account.balance.changed"
+	WalletCreated         = "wallet.created"
+	WalletBalanceChanged  = "wallet.balance.changed"
+
+	KYCTierUpgraded = "kyc.tier_upgraded"
+)
+
+// UserEvent is the data of UserCreated, UserUpdated and UserDeleted, version 1.
+// It names the user but carries no personal data: consumers that need the
+// profile ask the bank service for it.
+type UserEvent struct {
+	UserID string `json:"user_id"`
+	// Changed names the fields a UserUpdated changed
+	Changed []string `json:"changed,omitempty"`
+}
+
+// TransactionEvent is the data of TransactionCreated, TransactionCompleted and
+// TransactionFailed, version 1. Status tells them apart as well.
+type TransactionEvent struct {
+	TransactionID string  `json:"transaction_id"`
+	UserID        string  `json:"user_id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	Status        string  `json:"status"`
+	// Reason says why a TransactionFailed failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// ExchangeEvent is the data of ExchangeCreated, ExchangeCompleted and
+// ExchangeFailed, version 1. Status tells them apart as well.
+type ExchangeEvent struct {
+	ExchangeID   string  `json:"exchange_id"`
+	UserID       string  `json:"user_id"`
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	FromAmount   float64 `json:"from_amount"`
+	ToAmount     float64 `json:"to_amount"`
+	Status       string  `json:"status"`
+	// Reason says why an ExchangeFailed failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccountEvent is the data of AccountCreated, version 1
+type AccountEvent struct {
+	AccountID string `json:"account_id"`
+	UserID    string `json:"user_id"`
+	Currency  string `json:"currency"`
+}
+
+// WalletEvent is the data of WalletCreated, version 1
+type WalletEvent struct {
+	WalletID   string `json:"wallet_id"`
+	UserID     string `json:"user_id"`
+	CryptoType string `json:"crypto_type"`
+}
+
+// AccountBalanceEvent is the data of AccountBalanceChanged, version 1. Delta
+// is signed, Balance is the balance after the change.
+type AccountBalanceEvent struct {
+	AccountID     string  `json:"account_id"`
+	UserID        string  `json:"user_id"`
+	Currency      string  `json:"currency"`
+	Delta         float64 `json:"delta"`
+	Balance       float64 `json:"balance"`
+	TransactionID string  `json:"transaction_id"`
+}
+
+// WalletBalanceEvent is the data of WalletBalanceChanged, version 1. Delta is
+// signed, Balance is the balance after the change.
+type WalletBalanceEvent struct {
+	WalletID      string  `json:"wallet_id"`
+	UserID        string  `json:"user_id"`
+	CryptoType    string  `json:"crypto_type"`
+	Delta         float64 `json:"delta"`
+	Balance       float64 `json:"balance"`
+	TransactionID string  `json:"transaction_id"`
+}
+
+// KYCEvent is the data of KYCTierUpgraded, version 1
+type KYCEvent struct {
+	UserID        string `json:"user_id"`
+	ApplicationID string `json:"application_id"`
+	Tier          string `json:"tier"`
+}
+
+// schema is the current version of an event type. upgrades[v] turns data of
+// version v into version v+1.
+type schema struct {
+	version  int
+	new      func() interface{}
+	upgrades map[int]func(json.RawMessage) (json.RawMessage, error)
+}
+
+var schemas = map[string]schema{
+	UserCreated:           {version: 1, new: func() interface{} { return &UserEvent{} }},
+	UserUpdated:           {version: 1, new: func() interface{} { return &UserEvent{} }},
+	UserDeleted:           {version: 1, new: func() interface{} { return &UserEvent{} }},
+	TransactionCreated:    {version: 1, new: func() interface{} { return &TransactionEvent{} }},
+	TransactionCompleted:  {version: 1, new: func() interface{} { return &TransactionEvent{} }},
+	TransactionFailed:     {version: 1, new: func() interface{} { return &TransactionEvent{} }},
+	ExchangeCreated:       {version: 1, new: func() interface{} { return &ExchangeEvent{} }},
+	ExchangeCompleted:     {version: 1, new: func() interface{} { return &ExchangeEvent{} }},
+	ExchangeFailed:        {version: 1, new: func() interface{} { return &ExchangeEvent{} }},
+	AccountCreated:        {version: 1, new: func() interface{} { return &AccountEvent{} }},
+	AccountBalanceChanged: {version: 1, new: func() interface{} { return &AccountBalanceEvent{} }},
+	WalletCreated:         {version: 1, new: func() interface{} { return &WalletEvent{} }},
+	WalletBalanceChanged:  {version: 1, new: func() interface{} { return &WalletBalanceEvent{} }},
+	KYCTierUpgraded:       {version: 1, new: func() interface{} { return &KYCEvent{} }},
+}
+
+// Types lists the event types of the contract
+func Types() []string {
+	types := make([]string, 0, len(schemas))
+	for eventType := range schemas {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Version returns the current schema version of an event type, 0 if unknown
+func Version(eventType string) int {
+	return schemas[eventType].version
+}
@@ -0,0 +1,26 @@
+package replay
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	rebuildActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "projection_rebuild_active",
+			Help: "Whether a projection is being rebuilt from replayed events (1) or not (0)",
+		},
+		[]string{"projection"},
+	)
+
+	rebuildEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "projection_rebuild_events_total",
+			Help: "Total number of events seen by a rebuild by outcome: applied, duplicate or ignored",
+		},
+		[]string{"projection", "outcome"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(rebuildActive)
+	prometheus.MustRegister(rebuildEventsTotal)
+}
@@ -0,0 +1,165 @@
+// Package replay connects the bank event store to the services that project
+// bank events. bank-service re-publishes stored events to Exchange under their
+// original routing keys and message IDs; consumers bind a queue of their own
+// to it next to their bank.events queue and guard their projections with a
+// Rebuild.
+package replay
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// Exchange receives replayed bank events
+	Exchange = "bank.events.replay"
+	// HeaderReplayID identifies the replay run that re-published a message
+	HeaderReplayID = "x-replay-id"
+)
+
+// ErrNotRebuilding is returned when finishing a rebuild that was not started
+var ErrNotRebuilding = errors.New("no rebuild in progress")
+
+// IsReplay reports whether a delivery was re-published by a replay. Retried
+// and redriven messages keep the exchange they were first published to.
+func IsReplay(msg amqp.Delivery) bool {
+	return msg.Exchange == Exchange
+}
+
+// Status describes the current or last rebuild of a projection
+type Status struct {
+	Active     bool       `json:"active"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Applied counts the events applied since the start, Replayed the
+	// replayed ones among them
+	Applied  int64 `json:"applied"`
+	Replayed int64 `json:"replayed"`
+	// Duplicates were dropped because an event with their ID was applied
+	Duplicates int64 `json:"duplicates"`
+	// Ignored counts replayed events dropped outside a rebuild
+	Ignored int64 `json:"ignored"`
+}
+
+// Rebuild guards a projection that can be rebuilt from replayed events.
+//
+// Start resets the projection and records the IDs of the events applied from
+// then on, live and replayed alike, so that an event arriving on both queues
+// or replayed twice is applied once. Finish ends the rebuild and forgets the
+// IDs. Outside a rebuild live events are applied as they come and replayed
+// ones are dropped, since the projection already holds them.
+type Rebuild struct {
+	projection string
+	reset      func()
+
+	// applying is held shared while an event is applied and exclusively to
+	// start and finish, so a reset never races with an event half applied
+	applying sync.RWMutex
+
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	status Status
+}
+
+// NewRebuild guards the named projection; reset empties it
+func NewRebuild(projection string, reset func()) *Rebuild {
+	rebuildActive.WithLabelValues(projection).Set(0)
+	return &Rebuild{
+		projection: projection,
+		reset:      reset,
+	}
+}
+
+// Apply runs apply for an event unless the rules above drop it and reports
+// whether it ran. Events without an ID cannot be told apart and always run.
+func (r *Rebuild) Apply(eventID string, replayed bool, apply func()) bool {
+	r.applying.RLock()
+	defer r.applying.RUnlock()
+
+	if !r.admit(eventID, replayed) {
+		return false
+	}
+	apply()
+	return true
+}
+
+func (r *Rebuild) admit(eventID string, replayed bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.status.Active {
+		if replayed {
+			r.status.Ignored++
+			rebuildEventsTotal.WithLabelValues(r.projection, "ignored").Inc()
+			return false
+		}
+		return true
+	}
+
+	if eventID != "" {
+		if _, ok := r.seen[eventID]; ok {
+			r.status.Duplicates++
+			rebuildEventsTotal.WithLabelValues(r.projection, "duplicate").Inc()
+			return false
+		}
+		r.seen[eventID] = struct{}{}
+	}
+
+	r.status.Applied++
+	if replayed {
+		r.status.Replayed++
+	}
+	rebuildEventsTotal.WithLabelValues(r.projection, "applied").Inc()
+	return true
+}
+
+// Start resets the projection and begins a rebuild. Starting again while a
+// rebuild is under way starts over.
+func (r *Rebuild) Start() Status {
+	r.applying.Lock()
+	defer r.applying.Unlock()
+
+	r.reset()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	r.seen = make(map[string]struct{})
+	r.status = Status{Active: true, StartedAt: &now}
+	rebuildActive.WithLabelValues(r.projection).Set(1)
+
+	return r.status
+}
+
+// Finish ends the rebuild once the replay was consumed
+func (r *Rebuild) Finish() (Status, error) {
+	r.applying.Lock()
+	defer r.applying.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.status.Active {
+		return r.status, ErrNotRebuilding
+	}
+
+	now := time.Now().UTC()
+	r.seen = nil
+	r.status.Active = false
+	r.status.FinishedAt = &now
+	rebuildActive.WithLabelValues(r.projection).Set(0)
+
+	return r.status, nil
+}
+
+// Status returns the state of the current or last rebuild
+func (r *Rebuild) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.status
+}
@@ -0,0 +1,194 @@
+package replay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestIsReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange string
+		want     bool
+	}{
+		{"replayed", Exchange, true},
+		{"live", "bank.events", false},
+		{"retried through the default exchange", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsReplay(amqp.Delivery{Exchange: tt.exchange}); got != tt.want {
+				t.Errorf("IsReplay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// event is a delivery fed to a rebuild and whether it should be applied
+type event struct {
+	id       string
+	replayed bool
+	applied  bool
+}
+
+func TestRebuildApply(t *testing.T) {
+	tests := []struct {
+		name string
+		// rebuilding starts a rebuild before the events
+		rebuilding bool
+		events     []event
+		want       Status
+	}{
+		{
+			name: "live events outside a rebuild",
+			events: []event{
+				{id: "a", applied: true},
+				{id: "a", applied: true},
+				{id: "", applied: true},
+			},
+			want: Status{},
+		},
+		{
+			name: "replayed events outside a rebuild",
+			events: []event{
+				{id: "a", replayed: true},
+				{id: "b", replayed: true},
+				{id: "c", applied: true},
+			},
+			want: Status{Ignored: 2},
+		},
+		{
+			name:       "replayed and live copies of an event",
+			rebuilding: true,
+			events: []event{
+				{id: "a", replayed: true, applied: true},
+				{id: "a"},
+				{id: "b", applied: true},
+				{id: "b", replayed: true},
+			},
+			want: Status{Active: true, Applied: 2, Replayed: 1, Duplicates: 2},
+		},
+		{
+			name:       "event replayed twice",
+			rebuilding: true,
+			events: []event{
+				{id: "a", replayed: true, applied: true},
+				{id: "b", replayed: true, applied: true},
+				{id: "a", replayed: true},
+				{id: "b", replayed: true},
+			},
+			want: Status{Active: true, Applied: 2, Replayed: 2, Duplicates: 2},
+		},
+		{
+			name:       "events without an ID",
+			rebuilding: true,
+			events: []event{
+				{replayed: true, applied: true},
+				{replayed: true, applied: true},
+				{applied: true},
+			},
+			want: Status{Active: true, Applied: 3, Replayed: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rebuild := NewRebuild("test", func() {})
+			if tt.rebuilding {
+				rebuild.Start()
+			}
+
+			for i, e := range tt.events {
+				ran := false
+				got := rebuild.Apply(e.id, e.replayed, func() { ran = true })
+				if got != e.applied || ran != e.applied {
+					t.Errorf("event %d (%q, replayed %v): Apply = %v, ran %v, want %v",
+						i, e.id, e.replayed, got, ran, e.applied)
+				}
+			}
+
+			status := rebuild.Status()
+			status.StartedAt = nil
+			if status != tt.want {
+				t.Errorf("Status = %+v, want %+v", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebuildLifecycle(t *testing.T) {
+	resets := 0
+	rebuild := NewRebuild("test", func() { resets++ })
+
+	if _, err := rebuild.Finish(); !errors.Is(err, ErrNotRebuilding) {
+		t.Fatalf("Finish before Start: err = %v, want %v", err, ErrNotRebuilding)
+	}
+
+	status := rebuild.Start()
+	if !status.Active || status.StartedAt == nil || resets != 1 {
+		t.Fatalf("Start = %+v after %d resets", status, resets)
+	}
+	rebuild.Apply("a", true, func() {})
+
+	// Starting over resets the projection again and forgets the applied IDs
+	rebuild.Start()
+	if resets != 2 {
+		t.Errorf("projection reset %d times, want 2", resets)
+	}
+	if !rebuild.Apply("a", true, func() {}) {
+		t.Error("event of the abandoned rebuild was dropped")
+	}
+
+	status, err := rebuild.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Active || status.FinishedAt == nil || status.Applied != 1 {
+		t.Errorf("Finish = %+v", status)
+	}
+
+	// The projection holds the replayed events once the rebuild finished
+	if rebuild.Apply("b", true, func() {}) {
+		t.Error("replayed event applied after the rebuild")
+	}
+	if _, err := rebuild.Finish(); !errors.Is(err, ErrNotRebuilding) {
+		t.Errorf("Finish twice: err = %v, want %v", err, ErrNotRebuilding)
+	}
+}
+
+func TestRebuildStartWaitsForApply(t *testing.T) {
+	reset := make(chan struct{}, 1)
+	rebuild := NewRebuild("test", func() { reset <- struct{}{} })
+
+	applying := make(chan struct{})
+	release := make(chan struct{})
+	go rebuild.Apply("a", false, func() {
+		close(applying)
+		<-release
+	})
+	<-applying
+
+	started := make(chan struct{})
+	go func() {
+		rebuild.Start()
+		close(started)
+	}()
+
+	select {
+	case <-reset:
+		t.Fatal("projection reset while an event was half applied")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-started
+	select {
+	case <-reset:
+	default:
+		t.Error("projection was not reset")
+	}
+}